@@ -0,0 +1,245 @@
+package blockchain
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/types"
+	lru "github.com/hashicorp/golang-lru"
+	"go.uber.org/atomic"
+)
+
+const (
+	// cacheTuneInterval is the interval of the cache resize decisions
+	cacheTuneInterval = 30 * time.Second
+	// cacheMinLookups is the number of the lookups within an interval needed to judge
+	// the hit rate of a cache
+	cacheMinLookups = 1000
+	// cacheSizeSamples is the number of the entries sampled to estimate the entry size
+	cacheSizeSamples = 64
+)
+
+// AdaptiveCacheConfig holds the sizing of the header and receipt caches. A full cache
+// grows while its hit rate is below the threshold, as long as the estimated memory of
+// the caches fits the budget. The caches shrink back, never below the default size,
+// while the allocated heap exceeds its limit.
+type AdaptiveCacheConfig struct {
+	BudgetBytes  uint64  // memory the caches could take together, 0 keeps the default sizes
+	MinHitRate   float64 // hit rate below which a full cache grows
+	MaxHeapBytes uint64  // allocated heap above which the caches shrink, 0 to never shrink
+}
+
+// adaptiveCache is the lru cache counting its hits and misses, so that it could be
+// resized by the hit rate
+type adaptiveCache struct {
+	*lru.Cache
+
+	name    string
+	minSize int
+	size    int                      // max entries, only accessed by the tuner
+	sizer   func(interface{}) uint64 // estimated bytes of an entry
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func newAdaptiveCache(name string, size int, sizer func(interface{}) uint64) (*adaptiveCache, error) {
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &adaptiveCache{
+		Cache:   cache,
+		name:    name,
+		minSize: size,
+		size:    size,
+		sizer:   sizer,
+	}, nil
+}
+
+// Get looks up the key, and counts the hit or miss
+func (c *adaptiveCache) Get(key interface{}) (interface{}, bool) {
+	value, ok := c.Cache.Get(key)
+	if ok {
+		c.hits.Inc()
+	} else {
+		c.misses.Inc()
+	}
+
+	return value, ok
+}
+
+// hitRate returns the hit rate since the last call, and whether there were enough
+// lookups to judge it
+func (c *adaptiveCache) hitRate() (float64, bool) {
+	hits, misses := c.hits.Swap(0), c.misses.Swap(0)
+	if hits+misses < cacheMinLookups {
+		return 0, false
+	}
+
+	return float64(hits) / float64(hits+misses), true
+}
+
+// entryBytes estimates the average bytes of the entries by sampling, 0 if empty
+func (c *adaptiveCache) entryBytes() uint64 {
+	var total, sampled uint64
+
+	for _, key := range c.Keys() {
+		if sampled == cacheSizeSamples {
+			break
+		}
+
+		if value, ok := c.Peek(key); ok {
+			total += c.sizer(value)
+			sampled++
+		}
+	}
+
+	if sampled == 0 {
+		return 0
+	}
+
+	return total / sampled
+}
+
+func (c *adaptiveCache) resize(size int) {
+	c.size = size
+	c.Resize(size)
+}
+
+// headerBytes estimates the memory of a cached header
+func headerBytes(value interface{}) uint64 {
+	header, ok := value.(*types.Header)
+	if !ok {
+		return 0
+	}
+
+	// fixed size fields, bloom included
+	return 640 + uint64(len(header.ExtraData))
+}
+
+// receiptsBytes estimates the memory of the cached receipts of a block
+func receiptsBytes(value interface{}) uint64 {
+	receipts, ok := value.([]*types.Receipt)
+	if !ok {
+		return 0
+	}
+
+	var size uint64
+
+	for _, receipt := range receipts {
+		// fixed size fields, bloom included
+		size += 400
+
+		for _, log := range receipt.Logs {
+			size += 80 + uint64(len(log.Data)) + uint64(len(log.Topics))*types.HashLength
+		}
+	}
+
+	return size
+}
+
+// EnableAdaptiveCaches resizes the header and receipt caches by their hit rates within
+// the memory budget in background
+func (b *Blockchain) EnableAdaptiveCaches(config AdaptiveCacheConfig) {
+	if config.BudgetBytes == 0 || b.cacheTunerCloseCh != nil {
+		return
+	}
+
+	b.cacheTunerCloseCh = make(chan struct{})
+
+	b.wg.Add(1)
+
+	go b.runCacheTuner(config)
+}
+
+func (b *Blockchain) runCacheTuner(config AdaptiveCacheConfig) {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(cacheTuneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.cacheTunerCloseCh:
+			return
+		case <-ticker.C:
+			b.tuneCaches(config)
+		}
+	}
+}
+
+// tuneCaches shrinks the caches by half under memory pressure, or doubles the full
+// caches missing too often if the budget allows
+func (b *Blockchain) tuneCaches(config AdaptiveCacheConfig) {
+	caches := []*adaptiveCache{b.headersCache, b.receiptsCache}
+
+	defer func() {
+		for _, c := range caches {
+			b.metrics.CacheSizeSet(c.name, float64(c.size))
+		}
+	}()
+
+	if config.MaxHeapBytes > 0 {
+		var stats runtime.MemStats
+
+		runtime.ReadMemStats(&stats)
+
+		if stats.HeapAlloc >= config.MaxHeapBytes {
+			for _, c := range caches {
+				size := c.size / 2
+				if size < c.minSize {
+					size = c.minSize
+				}
+
+				if size < c.size {
+					b.logger.Info("shrink cache under memory pressure",
+						"cache", c.name, "from", c.size, "to", size, "heap", stats.HeapAlloc)
+					c.resize(size)
+				}
+
+				// the hit rates of the shrunk caches are judged afresh
+				c.hitRate()
+			}
+
+			return
+		}
+	}
+
+	// estimated memory of the caches at their max sizes
+	entryBytes := make([]uint64, len(caches))
+	used := uint64(0)
+
+	for i, c := range caches {
+		entryBytes[i] = c.entryBytes()
+		used += entryBytes[i] * uint64(c.size)
+	}
+
+	for i, c := range caches {
+		rate, ok := c.hitRate()
+		if !ok || rate >= config.MinHitRate || c.Len() < c.size || entryBytes[i] == 0 {
+			continue
+		}
+
+		// grow up to the remaining budget
+		size := c.size * 2
+		if used+entryBytes[i]*uint64(size-c.size) > config.BudgetBytes {
+			if used >= config.BudgetBytes {
+				continue
+			}
+
+			size = c.size + int((config.BudgetBytes-used)/entryBytes[i])
+		}
+
+		if size <= c.size {
+			continue
+		}
+
+		b.logger.Info("grow cache for the low hit rate",
+			"cache", c.name, "from", c.size, "to", size, "hit_rate", rate, "entry_bytes", entryBytes[i])
+
+		used += entryBytes[i] * uint64(size-c.size)
+		c.resize(size)
+	}
+}
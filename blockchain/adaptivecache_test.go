@@ -0,0 +1,106 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAdaptiveCacheTestChain(t *testing.T, size int) *Blockchain {
+	t.Helper()
+
+	b := &Blockchain{
+		logger:  hclog.NewNullLogger(),
+		metrics: NilMetrics(),
+	}
+
+	assert.NoError(t, b.initCaches(size))
+
+	return b
+}
+
+// lookupHeaders fills up the header cache, then looks up the headers missing
+// every other time
+func lookupHeaders(c *adaptiveCache, lookups int) {
+	for i := 0; i < lookups; i++ {
+		key := types.Hash{byte(i), byte(i >> 8), byte(i >> 16)}
+
+		if _, ok := c.Get(key); !ok {
+			c.Add(key, &types.Header{Number: uint64(i)})
+		}
+	}
+}
+
+func TestAdaptiveCache_Grow(t *testing.T) {
+	b := newAdaptiveCacheTestChain(t, 10)
+
+	config := AdaptiveCacheConfig{
+		BudgetBytes: 40 * headerBytes(&types.Header{}),
+		MinHitRate:  0.9,
+	}
+
+	// all missed
+	lookupHeaders(b.headersCache, cacheMinLookups)
+	b.tuneCaches(config)
+	assert.Equal(t, 20, b.headersCache.size)
+
+	// the receipt cache is not looked up, and kept
+	assert.Equal(t, 10, b.receiptsCache.size)
+
+	// within the budget
+	lookupHeaders(b.headersCache, cacheMinLookups)
+	b.tuneCaches(config)
+	assert.Equal(t, 40, b.headersCache.size)
+
+	lookupHeaders(b.headersCache, cacheMinLookups)
+	b.tuneCaches(config)
+	assert.Equal(t, 40, b.headersCache.size)
+}
+
+func TestAdaptiveCache_KeepHit(t *testing.T) {
+	b := newAdaptiveCacheTestChain(t, 10)
+
+	config := AdaptiveCacheConfig{
+		BudgetBytes: 1 << 20,
+		MinHitRate:  0.9,
+	}
+
+	// the working set fits
+	for i := 0; i < cacheMinLookups; i++ {
+		lookupHeaders(b.headersCache, 1)
+	}
+
+	b.tuneCaches(config)
+	assert.Equal(t, 10, b.headersCache.size)
+
+	// too few lookups to judge
+	lookupHeaders(b.headersCache, cacheMinLookups/2)
+	b.tuneCaches(config)
+	assert.Equal(t, 10, b.headersCache.size)
+}
+
+func TestAdaptiveCache_ShrinkUnderPressure(t *testing.T) {
+	b := newAdaptiveCacheTestChain(t, 10)
+
+	b.headersCache.resize(80)
+
+	// always under pressure
+	config := AdaptiveCacheConfig{
+		BudgetBytes:  1 << 20,
+		MinHitRate:   0.9,
+		MaxHeapBytes: 1,
+	}
+
+	b.tuneCaches(config)
+	assert.Equal(t, 40, b.headersCache.size)
+
+	b.tuneCaches(config)
+	b.tuneCaches(config)
+	b.tuneCaches(config)
+
+	// never below the default size
+	assert.Equal(t, 10, b.headersCache.size)
+	assert.Equal(t, 10, b.receiptsCache.size)
+}
@@ -0,0 +1,128 @@
+package blockchain
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
+)
+
+const (
+	// admissionRetryInterval is the interval of the pressure checks while an import is delayed
+	admissionRetryInterval = 100 * time.Millisecond
+	// admissionMaxWait is the max delay of an import, the block is admitted after it,
+	// so that the sync is slowed down rather than stalled
+	admissionMaxWait = time.Minute
+)
+
+// Resource pressure reasons of the delayed imports
+const (
+	pressureWriteQueue = "write_queue"
+	pressureMemory     = "memory"
+	pressureCompaction = "compaction"
+)
+
+// AdmissionConfig holds the resource thresholds of the block import admission control.
+// A zero threshold disables its check.
+type AdmissionConfig struct {
+	MaxWriteQueue int    // blocks queued by the background writer
+	MaxHeapBytes  uint64 // allocated heap bytes
+	MaxL0Tables   int    // level 0 tables of the database waiting for compaction
+}
+
+func (c AdmissionConfig) enabled() bool {
+	return c.MaxWriteQueue > 0 || c.MaxHeapBytes > 0 || c.MaxL0Tables > 0
+}
+
+// SetImportAdmission sets the thresholds of the block import admission control
+func (b *Blockchain) SetImportAdmission(config AdmissionConfig) {
+	b.admission = config
+}
+
+// AwaitImportAdmission delays the import of a block synced from the peers, while the
+// write queue, memory or database compaction backlog exceeds its threshold. It should
+// not be called for the blocks of the consensus, which are never delayed. The import
+// is held while the chain is halted as well, without bound.
+// It returns the context error if canceled, or ErrClosed if the chain is closed.
+func (b *Blockchain) AwaitImportAdmission(ctx context.Context) error {
+	if err := b.awaitResume(ctx); err != nil {
+		return err
+	}
+
+	if !b.admission.enabled() {
+		return nil
+	}
+
+	reason := b.importPressure()
+	if reason == "" {
+		return nil
+	}
+
+	if reason == pressureMemory {
+		// the heap might be garbage only, collect it before waiting
+		runtime.GC()
+	}
+
+	b.logger.Info("delay block import under resource pressure", "reason", reason)
+	b.metrics.ImportDelayedInc()
+
+	start := time.Now()
+	defer func() {
+		b.metrics.ImportDelaySecondsObserve(time.Since(start).Seconds())
+	}()
+
+	ticker := time.NewTicker(admissionRetryInterval)
+	defer ticker.Stop()
+
+	timer := time.NewTimer(admissionMaxWait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			b.logger.Warn("admit block import after max delay", "reason", reason, "delay", admissionMaxWait)
+
+			return nil
+		case <-ticker.C:
+			if b.isStopped() {
+				return ErrClosed
+			}
+
+			if reason = b.importPressure(); reason == "" {
+				return nil
+			}
+		}
+	}
+}
+
+// importPressure returns the reason of the resource pressure, empty if none
+func (b *Blockchain) importPressure() string {
+	config := b.admission
+
+	if config.MaxWriteQueue > 0 && b.writer.queued() >= config.MaxWriteQueue {
+		return pressureWriteQueue
+	}
+
+	if config.MaxHeapBytes > 0 {
+		var stats runtime.MemStats
+
+		runtime.ReadMemStats(&stats)
+
+		if stats.HeapAlloc >= config.MaxHeapBytes {
+			return pressureMemory
+		}
+	}
+
+	if config.MaxL0Tables > 0 {
+		if stats, ok := b.db.(kvdb.KVCompactionStats); ok {
+			if tables, paused := stats.CompactionBacklog(); paused || tables >= config.MaxL0Tables {
+				return pressureCompaction
+			}
+		}
+	}
+
+	return ""
+}
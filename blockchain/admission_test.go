@@ -0,0 +1,61 @@
+package blockchain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// compactingStorage reports a fixed compaction backlog
+type compactingStorage struct {
+	storage.Storage
+
+	tables int
+	paused bool
+}
+
+func (s *compactingStorage) CompactionBacklog() (int, bool) {
+	return s.tables, s.paused
+}
+
+func TestAwaitImportAdmission(t *testing.T) {
+	b := NewTestBlockchain(t, NewTestHeaders(3))
+
+	// disabled
+	assert.NoError(t, b.AwaitImportAdmission(context.Background()))
+
+	// the synchronous writer queues nothing
+	b.SetImportAdmission(AdmissionConfig{MaxWriteQueue: 1})
+	assert.NoError(t, b.AwaitImportAdmission(context.Background()))
+
+	// the level 0 tables are under the threshold, unless the writes are paused
+	db := &compactingStorage{Storage: b.db, tables: 2}
+	b.db = db
+
+	b.SetImportAdmission(AdmissionConfig{MaxL0Tables: 4})
+	assert.Equal(t, "", b.importPressure())
+
+	db.paused = true
+	assert.Equal(t, pressureCompaction, b.importPressure())
+
+	db.tables, db.paused = 4, false
+	assert.Equal(t, pressureCompaction, b.importPressure())
+
+	// the heap is always over the threshold, the import is delayed until canceled
+	b.SetImportAdmission(AdmissionConfig{MaxHeapBytes: 1})
+	assert.Equal(t, pressureMemory, b.importPressure())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*admissionRetryInterval)
+	defer cancel()
+
+	start := time.Now()
+	assert.ErrorIs(t, b.AwaitImportAdmission(ctx), context.DeadlineExceeded)
+	assert.GreaterOrEqual(t, time.Since(start), 2*admissionRetryInterval)
+
+	// the closed chain aborts the delay
+	b.stop()
+	assert.ErrorIs(t, b.AwaitImportAdmission(context.Background()), ErrClosed)
+}
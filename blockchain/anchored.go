@@ -0,0 +1,145 @@
+package blockchain
+
+import (
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/common"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/dogechain-lab/dogechain/types/buildroot"
+)
+
+// VerifyAnchoredBlock verifies a block of the history before the trusted checkpoint,
+// along with the receipts served by the peer. The caller must have linked the block
+// to the checkpoint hash by the parent hashes, so neither the seal is verified nor the
+// transactions are executed. The receipts are checked against the header roots, and
+// cached for the following WriteBlock. The state before the checkpoint is never
+// built, the syncer downloads the whole state of the checkpoint before the blocks
+// above it are executed. The partial state is neither executed on nor served.
+func (b *Blockchain) VerifyAnchoredBlock(block *types.Block, receipts []*types.Receipt) error {
+	if b.isStopped() {
+		return ErrClosed
+	}
+
+	b.wg.Add(1)
+	defer b.wg.Done()
+
+	if block == nil {
+		return ErrNoBlock
+	}
+
+	if block.Header == nil {
+		return ErrNoBlockHeader
+	}
+
+	checkpoint := b.config.Params.Checkpoint
+	if checkpoint == nil || block.Number() > checkpoint.Number {
+		return newVerifyError(VerifyStageCheckpoint, block.Header, ErrAboveCheckpoint)
+	}
+
+	// Make sure the block at the checkpoint height is the checkpoint
+	if err := b.verifyCheckpoint(block.Header); err != nil {
+		return err
+	}
+
+	// Make sure the block is in line with the parent block
+	if err := b.verifyBlockParent(block); err != nil {
+		return err
+	}
+
+	// Make sure the body is the one of the header
+	if err := b.verifyBlockRoots(block); err != nil {
+		return err
+	}
+
+	if err := b.verifyAnchoredReceipts(block, receipts); err != nil {
+		return err
+	}
+
+	b.receiptsCache.Add(block.Hash(), receipts)
+
+	return nil
+}
+
+// verifyAnchoredReceipts makes sure the receipts served by the peer are the ones of
+// the block header, and fills in the context fields left out of the consensus encoding
+func (b *Blockchain) verifyAnchoredReceipts(block *types.Block, receipts []*types.Receipt) error {
+	header := block.Header
+
+	if len(receipts) != len(block.Transactions) {
+		return newVerifyError(VerifyStageResult, header, ErrInvalidReceiptsSize).
+			withValues(len(block.Transactions), len(receipts))
+	}
+
+	if root := buildroot.CalculateReceiptsRoot(receipts); root != header.ReceiptsRoot {
+		return newVerifyError(VerifyStageResult, header, ErrInvalidReceiptsRoot).
+			withValues(header.ReceiptsRoot, root)
+	}
+
+	var gasUsed uint64
+	if len(receipts) > 0 {
+		gasUsed = receipts[len(receipts)-1].CumulativeGasUsed
+	}
+
+	if gasUsed != header.GasUsed {
+		return newVerifyError(VerifyStageResult, header, ErrInvalidGasUsed).
+			withValues(header.GasUsed, gasUsed)
+	}
+
+	if b.Config().Forks.IsLogsBloom(block.Number()) {
+		if bloom := types.CreateBloom(receipts); bloom != header.LogsBloom {
+			return newVerifyError(VerifyStageResult, header, ErrInvalidLogsBloom).
+				withValues(header.LogsBloom, bloom)
+		}
+	}
+
+	signer := crypto.NewSigner(b.ForksInTime(block.Number()), b.ChainID())
+
+	var cumulativeGasUsed uint64
+
+	for i, receipt := range receipts {
+		tx := block.Transactions[i]
+
+		receipt.TxHash = tx.Hash()
+		receipt.GasUsed = common.MaxUint64(receipt.CumulativeGasUsed, cumulativeGasUsed) - cumulativeGasUsed
+		cumulativeGasUsed = receipt.CumulativeGasUsed
+
+		if tx.To != nil {
+			continue
+		}
+
+		from := tx.From
+		if from == types.ZeroAddress {
+			sender, err := signer.Sender(tx)
+			if err != nil {
+				return newVerifyError(VerifyStageBody, header, err)
+			}
+
+			from = sender
+		}
+
+		receipt.SetContractAddress(crypto.CreateAddress(from, tx.Nonce))
+	}
+
+	return nil
+}
+
+// ReadCheckpointAnchor returns the hash of the block at the height linked to the trusted
+// checkpoint, if the syncer has anchored it
+func (b *Blockchain) ReadCheckpointAnchor(n uint64) (types.Hash, bool) {
+	checkpoint := b.config.Params.Checkpoint
+	if checkpoint == nil {
+		return types.Hash{}, false
+	}
+
+	return b.db.ReadCheckpointAnchor(checkpoint.Hash, n)
+}
+
+// WriteCheckpointAnchor persists the hash of the block at the height linked to the
+// trusted checkpoint, so that the history is not linked again after a restart
+func (b *Blockchain) WriteCheckpointAnchor(n uint64, hash types.Hash) error {
+	checkpoint := b.config.Params.Checkpoint
+	if checkpoint == nil || n > checkpoint.Number {
+		return ErrAboveCheckpoint
+	}
+
+	return b.db.WriteCheckpointAnchor(checkpoint.Hash, n, hash)
+}
@@ -14,8 +14,8 @@ import (
 	"github.com/dogechain-lab/dogechain/chain"
 	"github.com/dogechain-lab/dogechain/contracts/upgrader"
 	"github.com/dogechain-lab/dogechain/contracts/validatorset"
-	"github.com/dogechain-lab/dogechain/crypto"
 	"github.com/dogechain-lab/dogechain/helper/common"
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
 	"github.com/dogechain-lab/dogechain/state"
 	"github.com/dogechain-lab/dogechain/types"
 	"github.com/dogechain-lab/dogechain/types/buildroot"
@@ -41,8 +41,15 @@ var (
 	ErrInvalidStateRoot     = errors.New("invalid block state root")
 	ErrInvalidGasUsed       = errors.New("invalid block gas used")
 	ErrInvalidReceiptsRoot  = errors.New("invalid block receipts root")
+	ErrInvalidLogsBloom     = errors.New("invalid block logs bloom")
 	ErrNilStorageBuilder    = errors.New("nil storage builder")
 	ErrClosed               = errors.New("blockchain is closed")
+	ErrReorgTooDeep         = errors.New("reorg exceeds max reorg depth")
+	ErrCheckpointMismatch   = errors.New("block does not match the trusted checkpoint")
+	ErrAboveCheckpoint      = errors.New("block is above the trusted checkpoint")
+	ErrCorruptedChain       = errors.New("no consistent block found in the chain storage")
+	ErrHeadTDNotFound       = errors.New("failed to get the total difficulty of the chain head")
+	ErrReadOnly             = storage.ErrReadOnly
 )
 
 // Blockchain is a blockchain reference
@@ -53,13 +60,15 @@ type Blockchain struct {
 	consensus Verifier
 	executor  Executor
 	stopped   atomic.Bool // used in executor halting
+	closed    atomic.Bool // closed once
+	readOnly  bool        // opened in read-only mode, all writes return ErrReadOnly
 
 	config           *chain.Chain // Config containing chain information
 	priceBottomLimit uint64       // bottom limit of gas price
 	genesis          types.Hash   // The hash of the genesis block
 
-	headersCache    *lru.Cache // LRU cache for the headers
-	difficultyCache *lru.Cache // LRU cache for the difficulty
+	headersCache    *adaptiveCache // LRU cache for the headers
+	difficultyCache *lru.Cache     // LRU cache for the difficulty
 
 	// We need to keep track of block receipts between the verification phase
 	// and the insertion phase of a new block coming in. To avoid having to
@@ -70,29 +79,44 @@ type Blockchain struct {
 	// that is currently not possible because it would break backwards compatibility due to
 	// insane conditionals in the RLP unmarshal methods for the Block structure, which prevent
 	// any new fields from being added
-	receiptsCache *lru.Cache // LRU cache for the block receipts
+	receiptsCache *adaptiveCache // LRU cache for the block receipts
+
+	cacheTunerCloseCh chan struct{} // stops resizing the caches, nil if not adaptive
 
 	currentHeader     atomic.Value // The current header
 	currentDifficulty atomic.Value // The current difficulty of the chain (total difficulty)
 
 	stream *eventStream // Event subscriptions
 
-	// average gas price of current block, only used for metrics.
-	gpAverage *gasPriceAverage // A reference to the average gas price
+	maxReorgDepth uint64 // Max canonical blocks a reorg could drop, 0 means unlimited
 
-	metrics *Metrics
+	logIndexer   *logIndexer   // Log address and topic indexer, nil if disabled
+	bloomIndexer *bloomIndexer // Bloom bits section indexer, nil if disabled
 
-	wg        sync.WaitGroup // for shutdown sync
-	writeLock sync.Mutex     // for disabling concurrent write
-}
+	chainStatsIndexer *chainStatsIndexer // Hourly chain usage aggregator, nil if disabled
+
+	systemTxIndexer *systemTxIndexer // System transaction type indexer, nil if disabled
+
+	txLookupLimit     uint64             // Number of recent blocks keeping tx lookups, 0 means all
+	txLookupUnindexer *txLookupUnindexer // Stale tx lookups remover, nil if no limit
+
+	receiptsBackfiller *receiptsBackfiller // Missing receipts regenerator, nil if disabled
+
+	writer *blockWriter // Persistence stage of the block writes
+
+	admission AdmissionConfig // Resource thresholds of the synced block imports
+
+	halt chainHalt // Emergency halt of the block writes
+
+	gpHistory *gasPriceHistory // Gas prices of the recent blocks, for metrics and price suggestion
 
-// gasPriceAverage keeps track of the average gas price (rolling average)
-type gasPriceAverage struct {
-	sync.RWMutex
+	executionStats *executionStatsRing // Execution stats of the recent imported blocks
 
-	max   *big.Int // The maximum gas price
-	price *big.Int // The average gas price that gets queried
-	count *big.Int // Param used in the avg. gas price calculation
+	metrics     *Metrics
+	importStats importStats // Import throughput of the written blocks, guarded by writeLock
+
+	wg        sync.WaitGroup // for shutdown sync
+	writeLock sync.Mutex     // for disabling concurrent write
 }
 
 type Verifier interface {
@@ -111,47 +135,13 @@ type Executor interface {
 }
 
 type BlockResult struct {
-	Root     types.Hash
-	Receipts []*types.Receipt
-	TotalGas uint64
-}
-
-// updateGasPriceAvg updates the current average value of the gas price
-func (b *Blockchain) updateGasPriceAvg(newValues []*big.Int) {
-	b.gpAverage.Lock()
-	defer b.gpAverage.Unlock()
-
-	// short circuit
-	if len(newValues) == 0 {
-		// no need to update zero value
-		if b.gpAverage.count.Sign() != 0 {
-			b.gpAverage.max = new(big.Int)
-			b.gpAverage.price = new(big.Int)
-			b.gpAverage.count = new(big.Int)
-		}
+	Root        types.Hash
+	Receipts    []*types.Receipt
+	TotalGas    uint64
+	AccessStats state.AccessStats // state accesses of the execution
 
-		return
-	}
-
-	sum := new(big.Int)
-	max := new(big.Int)
-
-	// Iterate the values for sum and max
-	for _, val := range newValues {
-		sum = sum.Add(sum, val)
-
-		if max.Cmp(val) < 0 {
-			max.Set(val)
-		}
-	}
-
-	// Calculate arithmetic average
-	newAverageCount := big.NewInt(int64(len(newValues)))
-	newAverage := sum.Div(sum, newAverageCount)
-
-	b.gpAverage.max = max
-	b.gpAverage.price = newAverage
-	b.gpAverage.count = newAverageCount
+	ExecutionTime time.Duration // time of the execution, excluding the commit
+	SystemTxCount int           // number of the executed system transactions
 }
 
 // NewBlockchain creates a new blockchain object
@@ -170,19 +160,18 @@ func NewBlockchain(
 
 	b := &Blockchain{
 		logger:           logger.Named("blockchain"),
+		readOnly:         storageBuilder.IsReadOnly(),
 		config:           config,
 		priceBottomLimit: priceBottomLimit,
 		consensus:        consensus,
 		executor:         executor,
-		stream:           newEventStream(context.Background()),
-		gpAverage: &gasPriceAverage{
-			max:   new(big.Int),
-			price: new(big.Int),
-			count: new(big.Int),
-		},
-		metrics: NewDummyMetrics(metrics),
+		gpHistory:        newGasPriceHistory(DefaultGasPriceHistorySize),
+		executionStats:   newExecutionStatsRing(executionStatsSize),
+		metrics:          NewDummyMetrics(metrics),
 	}
 
+	b.stream = newEventStream(context.Background(), b.logger, b.metrics)
+
 	var (
 		db  storage.Storage
 		err error
@@ -193,6 +182,9 @@ func NewBlockchain(
 	}
 
 	b.db = db
+	b.writer = newBlockWriter(b.logger, db, 0)
+
+	b.loadHaltStatus()
 
 	if err := b.initCaches(defaultCacheSize); err != nil {
 		return nil, err
@@ -210,7 +202,7 @@ func NewBlockchain(
 func (b *Blockchain) initCaches(size int) error {
 	var err error
 
-	b.headersCache, err = lru.New(size)
+	b.headersCache, err = newAdaptiveCache("headers", size, headerBytes)
 	if err != nil {
 		return fmt.Errorf("unable to create headers cache, %w", err)
 	}
@@ -220,7 +212,7 @@ func (b *Blockchain) initCaches(size int) error {
 		return fmt.Errorf("unable to create difficulty cache, %w", err)
 	}
 
-	b.receiptsCache, err = lru.New(size)
+	b.receiptsCache, err = newAdaptiveCache("receipts", size, receiptsBytes)
 	if err != nil {
 		return fmt.Errorf("unable to create receipts cache, %w", err)
 	}
@@ -245,12 +237,17 @@ func (b *Blockchain) ComputeGenesis() error {
 			return fmt.Errorf("genesis file does not match current genesis")
 		}
 
-		header, ok := b.GetHeaderByHash(head)
-		if !ok {
-			return fmt.Errorf("failed to get header with hash %s", head.String())
+		header, err := b.checkHead(head)
+		if err != nil {
+			return err
+		}
+
+		header, err = b.repairHead(header)
+		if err != nil {
+			return err
 		}
 
-		diff, ok := b.GetTD(head)
+		diff, ok := b.GetTD(header.Hash)
 		if !ok {
 			return fmt.Errorf("failed to read difficulty")
 		}
@@ -264,6 +261,9 @@ func (b *Blockchain) ComputeGenesis() error {
 		)
 
 		b.setCurrentHeader(header, diff)
+
+		// sample the gas prices of the recent blocks
+		b.loadGasPriceHistory()
 	} else {
 		// empty storage, write the genesis
 		if err := b.writeGenesis(b.config.Genesis); err != nil {
@@ -285,6 +285,98 @@ func (b *Blockchain) SetConsensus(c Verifier) {
 	b.consensus = c
 }
 
+// ReadOnly returns whether the blockchain is opened in read-only mode, which
+// never writes to the storage, and the background indexers are not enabled
+func (b *Blockchain) ReadOnly() bool {
+	return b.readOnly
+}
+
+// SetMaxReorgDepth sets the max number of canonical blocks a reorg could drop.
+// Deeper reorgs are rejected. 0 means unlimited
+func (b *Blockchain) SetMaxReorgDepth(depth uint64) {
+	b.maxReorgDepth = depth
+}
+
+// EnableAsyncWrite queues the bodies, receipts and transaction lookups of the written
+// blocks, and flushes them in background, so that they are off the critical path of
+// block production. The queued data of at most queueSize blocks is lost on a crash,
+// and the chain head is rewound on start. It should be called before the genesis is computed.
+func (b *Blockchain) EnableAsyncWrite(queueSize int) {
+	if queueSize <= 0 || b.writer.async() || b.readOnly {
+		return
+	}
+
+	b.writer = newBlockWriter(b.logger, b.db, queueSize)
+	b.writer.start()
+}
+
+// EnableLogIndex starts indexing the log addresses and topics of the canonical blocks
+// in background, it should be called after the genesis is computed
+func (b *Blockchain) EnableLogIndex() {
+	if b.logIndexer != nil || b.readOnly {
+		return
+	}
+
+	// the receipts of the queued blocks are not persisted yet
+	b.logIndexer = newLogIndexer(b.logger, b.db, b.persistedHeadNumber)
+	b.logIndexer.start()
+}
+
+// EnableBloomIndex starts indexing the header blooms of the canonical blocks into
+// bloom bits sections in background, it should be called after the genesis is computed
+func (b *Blockchain) EnableBloomIndex() {
+	if b.bloomIndexer != nil || b.readOnly {
+		return
+	}
+
+	// the receipts of the queued blocks before the LogsBloom fork are not persisted yet
+	b.bloomIndexer = newBloomIndexer(b.logger, b.db, b.persistedHeadNumber,
+		b.Config().Forks.IsLogsBloom, BloomBitsSectionSize, bloomBitsConfirms)
+	b.bloomIndexer.start()
+}
+
+// EnableChainStats starts aggregating the usage of the canonical blocks into hourly
+// stats in background, it should be called after the genesis is computed
+func (b *Blockchain) EnableChainStats() {
+	if b.chainStatsIndexer != nil || b.readOnly {
+		return
+	}
+
+	// the bodies of the queued blocks are not persisted yet
+	b.chainStatsIndexer = newChainStatsIndexer(b.logger, b.db, b.persistedHeadNumber)
+	b.chainStatsIndexer.start()
+}
+
+// SetTxLookupLimit keeps the transaction lookups of the most recent 'limit' blocks only,
+// and starts deleting the stale ones in background. 0 means keeping all of them.
+// It should be called after the genesis is computed. The lookups which are already
+// deleted would not be restored when the limit is raised.
+func (b *Blockchain) SetTxLookupLimit(limit uint64) {
+	if limit == 0 || b.txLookupUnindexer != nil || b.readOnly {
+		return
+	}
+
+	b.txLookupLimit = limit
+	b.txLookupUnindexer = newTxLookupUnindexer(b.logger, b.db, func() uint64 {
+		return b.Header().Number
+	}, limit)
+	b.txLookupUnindexer.start()
+}
+
+// EnableReceiptsBackfill starts scanning the canonical blocks for missing receipts,
+// and regenerates them by re-executing at most 'rate' blocks per second in background.
+// 0 means no rate limit. It should be called after the genesis is computed.
+func (b *Blockchain) EnableReceiptsBackfill(rate uint64) {
+	if b.receiptsBackfiller != nil || b.readOnly {
+		return
+	}
+
+	// the receipts of the queued blocks are not persisted yet
+	b.receiptsBackfiller = newReceiptsBackfiller(b.logger, b.db, b.metrics,
+		b.persistedHeadNumber, b.executeBlockTransactions, rate)
+	b.receiptsBackfiller.start()
+}
+
 // setCurrentHeader sets the current header
 func (b *Blockchain) setCurrentHeader(h *types.Header, diff *big.Int) {
 	// Update the header (atomic)
@@ -384,6 +476,10 @@ func (b *Blockchain) calculateGasLimit(parentGasLimit uint64) uint64 {
 
 // writeGenesis wrapper for the genesis write function
 func (b *Blockchain) writeGenesis(genesis *chain.Genesis) error {
+	if b.readOnly {
+		return ErrReadOnly
+	}
+
 	header := genesis.GenesisHeader()
 	header.ComputeHash()
 
@@ -399,13 +495,20 @@ func (b *Blockchain) writeGenesisImpl(header *types.Header) error {
 	// Update the reference
 	b.genesis = header.Hash
 
+	batch := b.db.NewAtomicBatch()
+
 	// Update the DB
-	if err := b.db.WriteHeader(header); err != nil {
+	if err := batch.WriteHeader(header); err != nil {
 		return err
 	}
 
 	// Advance the head
-	if _, err := b.advanceHead(header); err != nil {
+	td, err := b.writeHead(batch, header)
+	if err != nil {
+		return err
+	}
+
+	if err := b.commitHead(batch, header, td); err != nil {
 		return err
 	}
 
@@ -439,45 +542,74 @@ func (b *Blockchain) GetTD(hash types.Hash) (*big.Int, bool) {
 	return b.readTotalDifficulty(hash)
 }
 
-// writeCanonicalHeader writes the new header
-func (b *Blockchain) writeCanonicalHeader(event *Event, h *types.Header) error {
+// writeCanonicalHeader writes the new header into the batch, and returns its total difficulty
+func (b *Blockchain) writeCanonicalHeader(event *Event, h *types.Header, batch storage.Batch) (*big.Int, error) {
 	if b.isStopped() {
-		return ErrClosed
+		return nil, ErrClosed
 	}
 
 	parentTD, ok := b.readTotalDifficulty(h.ParentHash)
 	if !ok {
-		return fmt.Errorf("parent difficulty not found")
+		return nil, fmt.Errorf("parent difficulty not found")
 	}
 
 	newTD := big.NewInt(0).Add(parentTD, new(big.Int).SetUint64(h.Difficulty))
-	if err := b.db.WriteCanonicalHeader(h, newTD); err != nil {
-		return err
+	if err := batch.WriteCanonicalHeader(h, newTD); err != nil {
+		return nil, err
 	}
 
 	event.Type = EventHead
 	event.AddNewHeader(h)
 	event.SetDifficulty(newTD)
 
-	b.setCurrentHeader(h, newTD)
+	return newTD, nil
+}
+
+// commitHead commits the batch, and then sets the head written into it, if any. The
+// head is never ahead of the storage.
+func (b *Blockchain) commitHead(batch storage.Batch, head *types.Header, td *big.Int) error {
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	if head != nil {
+		b.setCurrentHeader(head, td)
+	}
 
 	return nil
 }
 
 // advanceHead Sets the passed in header as the new head of the chain
 func (b *Blockchain) advanceHead(newHeader *types.Header) (*big.Int, error) {
+	batch := b.db.NewAtomicBatch()
+
+	newTD, err := b.writeHead(batch, newHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.commitHead(batch, newHeader, newTD); err != nil {
+		return nil, err
+	}
+
+	return newTD, nil
+}
+
+// writeHead writes the passed in header as the new head into the batch, and returns
+// its total difficulty
+func (b *Blockchain) writeHead(batch storage.Batch, newHeader *types.Header) (*big.Int, error) {
 	// Write the current head hash into storage
-	if err := b.db.WriteHeadHash(newHeader.Hash); err != nil {
+	if err := batch.WriteHeadHash(newHeader.Hash); err != nil {
 		return nil, err
 	}
 
 	// Write the current head number into storage
-	if err := b.db.WriteHeadNumber(newHeader.Number); err != nil {
+	if err := batch.WriteHeadNumber(newHeader.Number); err != nil {
 		return nil, err
 	}
 
 	// Matches the current head number with the current hash
-	if err := b.db.WriteCanonicalHash(newHeader.Number, newHeader.Hash); err != nil {
+	if err := batch.WriteCanonicalHash(newHeader.Number, newHeader.Hash); err != nil {
 		return nil, err
 	}
 
@@ -495,13 +627,10 @@ func (b *Blockchain) advanceHead(newHeader *types.Header) (*big.Int, error) {
 
 	// Calculate the new total difficulty
 	newTD := big.NewInt(0).Add(parentTD, big.NewInt(0).SetUint64(newHeader.Difficulty))
-	if err := b.db.WriteTotalDifficulty(newHeader.Hash, newTD); err != nil {
+	if err := batch.WriteTotalDifficulty(newHeader.Number, newHeader.Hash, newTD); err != nil {
 		return nil, err
 	}
 
-	// Update the blockchain reference
-	b.setCurrentHeader(newHeader, newTD)
-
 	return newTD, nil
 }
 
@@ -517,6 +646,10 @@ func (b *Blockchain) GetHeaderNumber() (uint64, bool) {
 
 // GetReceiptsByHash returns the receipts by their hash
 func (b *Blockchain) GetReceiptsByHash(hash types.Hash) ([]*types.Receipt, error) {
+	if receipts, ok := b.writer.readReceipts(hash); ok {
+		return receipts, nil
+	}
+
 	return b.db.ReadReceipts(hash)
 }
 
@@ -559,6 +692,10 @@ func (b *Blockchain) readHeader(hash types.Hash) (*types.Header, bool) {
 
 // readBody reads the block's body, using the block hash
 func (b *Blockchain) readBody(hash types.Hash) (*types.Body, bool) {
+	if bb, ok := b.writer.readBody(hash); ok {
+		return bb, true
+	}
+
 	bb, err := b.db.ReadBody(hash)
 	if err != nil {
 		b.logger.Error("failed to read body", "err", err)
@@ -610,6 +747,55 @@ func (b *Blockchain) GetHeaderByNumber(n uint64) (*types.Header, bool) {
 	return h, true
 }
 
+// GetBlockNumbersByStateRoot returns the numbers of the canonical blocks with the state
+// root in ascending order. Consecutive blocks without state changes share the root, only
+// the first storage.MaxStateRootIndexPostings blocks of a root are recorded.
+func (b *Blockchain) GetBlockNumbersByStateRoot(root types.Hash) []uint64 {
+	var (
+		head    = b.Header()
+		numbers = b.db.ReadStateRootIndex(root)
+		res     = make([]uint64, 0, len(numbers))
+	)
+
+	for _, n := range numbers {
+		if head == nil || n > head.Number {
+			break
+		}
+
+		// skip the forks and the reorged blocks
+		if h, ok := b.GetHeaderByNumber(n); ok && h.StateRoot == root {
+			res = append(res, n)
+		}
+	}
+
+	return res
+}
+
+// GetCanonicalHashesInRange returns the canonical hashes from number 'from' to 'to' (inclusive).
+// The result stops at the first missing block.
+func (b *Blockchain) GetCanonicalHashesInRange(from, to uint64) []types.Hash {
+	return b.db.ReadCanonicalHashesInRange(from, to)
+}
+
+// GetHeadersInRange returns the canonical headers from number 'from' to 'to' (inclusive).
+// The result stops at the first missing header.
+func (b *Blockchain) GetHeadersInRange(from, to uint64) []*types.Header {
+	hashes := b.GetCanonicalHashesInRange(from, to)
+	headers := make([]*types.Header, 0, len(hashes))
+
+	for _, hash := range hashes {
+		// fill up the headers cache
+		h, ok := b.readHeader(hash)
+		if !ok {
+			break
+		}
+
+		headers = append(headers, h)
+	}
+
+	return headers
+}
+
 // WriteHeaders writes an array of headers
 func (b *Blockchain) WriteHeaders(headers []*types.Header) error {
 	return b.WriteHeadersWithBodies(headers)
@@ -621,6 +807,10 @@ func (b *Blockchain) WriteHeadersWithBodies(headers []*types.Header) error {
 		return ErrClosed
 	}
 
+	if b.readOnly {
+		return ErrReadOnly
+	}
+
 	// Check the size
 	if len(headers) == 0 {
 		return fmt.Errorf("passed in headers array is empty")
@@ -646,8 +836,17 @@ func (b *Blockchain) WriteHeadersWithBodies(headers []*types.Header) error {
 
 	// Write the actual headers
 	for _, h := range headers {
-		event := &Event{}
-		if err := b.writeHeaderImpl(event, h); err != nil {
+		var (
+			event = &Event{}
+			batch = b.db.NewAtomicBatch()
+		)
+
+		head, td, err := b.writeHeaderImpl(event, h, batch)
+		if err != nil {
+			return err
+		}
+
+		if err := b.commitHead(batch, head, td); err != nil {
 			return err
 		}
 
@@ -684,9 +883,16 @@ func (b *Blockchain) VerifyFinalizedBlock(block *types.Block) error {
 		return ErrNoBlockHeader
 	}
 
-	// Make sure the consensus layer verifies this block header
+	// Make sure the block is on the chain of the trusted checkpoint
+	if err := b.verifyCheckpoint(block.Header); err != nil {
+		return err
+	}
+
+	// Make sure the consensus layer verifies this block header. The history before the
+	// trusted checkpoint is linked to it by the syncer and goes through
+	// VerifyAnchoredBlock instead, any other block is fully verified
 	if err := b.consensus.VerifyHeader(block.Header); err != nil {
-		return fmt.Errorf("failed to verify the header: %w", err)
+		return newVerifyError(VerifyStageSeal, block.Header, err)
 	}
 
 	// Do the initial block verification
@@ -697,6 +903,30 @@ func (b *Blockchain) VerifyFinalizedBlock(block *types.Block) error {
 	return nil
 }
 
+// verifyCheckpoint makes sure the block at the trusted checkpoint height is the checkpoint
+func (b *Blockchain) verifyCheckpoint(header *types.Header) error {
+	checkpoint := b.config.Params.Checkpoint
+	if checkpoint == nil || header.Number != checkpoint.Number {
+		return nil
+	}
+
+	if header.Hash != checkpoint.Hash || header.StateRoot != checkpoint.StateRoot {
+		b.logger.Error(
+			"checkpoint mismatch",
+			"number", header.Number,
+			"hash", header.Hash,
+			"stateRoot", header.StateRoot,
+			"want hash", checkpoint.Hash,
+			"want stateRoot", checkpoint.StateRoot,
+		)
+
+		return newVerifyError(VerifyStageCheckpoint, header, ErrCheckpointMismatch).
+			withValues(checkpoint.Hash, header.Hash)
+	}
+
+	return nil
+}
+
 // verifyBlock does the base (common) block verification steps by
 // verifying the block body as well as the parent information
 func (b *Blockchain) verifyBlock(block *types.Block) error {
@@ -707,6 +937,11 @@ func (b *Blockchain) verifyBlock(block *types.Block) error {
 	b.wg.Add(1)
 	defer b.wg.Done()
 
+	begin := time.Now()
+	defer func() {
+		b.metrics.BlockVerificationSecondsObserve(time.Since(begin).Seconds())
+	}()
+
 	// Make sure the block is present
 	if block == nil {
 		return ErrNoBlock
@@ -744,17 +979,18 @@ func (b *Blockchain) verifyBlockParent(childBlock *types.Block) error {
 			parentHash,
 		))
 
-		return ErrParentNotFound
+		return newVerifyError(VerifyStageParent, childBlock.Header, ErrParentNotFound)
 	}
 
 	// Make sure the hash is valid
 	if parent.Hash == types.ZeroHash {
-		return ErrInvalidParentHash
+		return newVerifyError(VerifyStageParent, childBlock.Header, ErrInvalidParentHash)
 	}
 
 	// Make sure the hashes match up
 	if parentHash != parent.Hash {
-		return ErrParentHashMismatch
+		return newVerifyError(VerifyStageParent, childBlock.Header, ErrParentHashMismatch).
+			withValues(parentHash, parent.Hash)
 	}
 
 	// Make sure the block numbers are correct
@@ -765,12 +1001,13 @@ func (b *Blockchain) verifyBlockParent(childBlock *types.Block) error {
 			parent.Number,
 		))
 
-		return ErrInvalidBlockSequence
+		return newVerifyError(VerifyStageParent, childBlock.Header, ErrInvalidBlockSequence).
+			withValues(childBlock.Number()-1, parent.Number)
 	}
 
 	// Make sure the gas limit is within correct bounds
 	if gasLimitErr := b.verifyGasLimit(childBlock.Header, parent); gasLimitErr != nil {
-		return fmt.Errorf("invalid gas limit, %w", gasLimitErr)
+		return newVerifyError(VerifyStageParent, childBlock.Header, fmt.Errorf("invalid gas limit, %w", gasLimitErr))
 	}
 
 	return nil
@@ -788,6 +1025,34 @@ func (b *Blockchain) verifyBlockBody(block *types.Block) error {
 	b.wg.Add(1)
 	defer b.wg.Done()
 
+	if err := b.verifyBlockRoots(block); err != nil {
+		return err
+	}
+
+	// Execute the transactions in the block and grab the result
+	blockResult, executeErr := b.executeBlockTransactions(block)
+	if executeErr != nil {
+		if errors.Is(executeErr, ErrClosed) {
+			return executeErr
+		}
+
+		return newVerifyError(VerifyStageExecution, block.Header, executeErr)
+	}
+
+	// Verify the local execution result with the proposed block data
+	checkBloom := b.Config().Forks.IsLogsBloom(block.Number())
+
+	if err := blockResult.verifyBlockResult(block, checkBloom); err != nil {
+		return err
+	}
+
+	b.recordExecutionStats(block, blockResult)
+
+	return nil
+}
+
+// verifyBlockRoots makes sure the uncles and transactions roots match up the body
+func (b *Blockchain) verifyBlockRoots(block *types.Block) error {
 	// Make sure the Uncles root matches up
 	if hash := buildroot.CalculateUncleRoot(block.Uncles); hash != block.Header.Sha3Uncles {
 		b.logger.Error(fmt.Sprintf(
@@ -796,7 +1061,8 @@ func (b *Blockchain) verifyBlockBody(block *types.Block) error {
 			block.Header.Sha3Uncles,
 		))
 
-		return ErrInvalidSha3Uncles
+		return newVerifyError(VerifyStageBody, block.Header, ErrInvalidSha3Uncles).
+			withValues(block.Header.Sha3Uncles, hash)
 	}
 
 	// Make sure the transactions root matches up
@@ -807,45 +1073,50 @@ func (b *Blockchain) verifyBlockBody(block *types.Block) error {
 			block.Header.TxRoot,
 		))
 
-		return ErrInvalidTxRoot
-	}
-
-	// Execute the transactions in the block and grab the result
-	blockResult, executeErr := b.executeBlockTransactions(block)
-	if executeErr != nil {
-		return fmt.Errorf("unable to execute block transactions, %w", executeErr)
-	}
-
-	// Verify the local execution result with the proposed block data
-	if err := blockResult.verifyBlockResult(block); err != nil {
-		return fmt.Errorf("unable to verify block execution result, %w", err)
+		return newVerifyError(VerifyStageBody, block.Header, ErrInvalidTxRoot).
+			withValues(block.Header.TxRoot, hash)
 	}
 
 	return nil
 }
 
 // verifyBlockResult verifies that the block transaction execution result
-// matches up to the expected values
-func (br *BlockResult) verifyBlockResult(referenceBlock *types.Block) error {
+// matches up to the expected values. The logs bloom is checked only when
+// checkBloom is set, since the blocks before the fork have empty blooms.
+func (br *BlockResult) verifyBlockResult(referenceBlock *types.Block, checkBloom bool) error {
+	header := referenceBlock.Header
+
 	// Make sure the number of receipts matches the number of transactions
 	if len(br.Receipts) != len(referenceBlock.Transactions) {
-		return ErrInvalidReceiptsSize
+		return newVerifyError(VerifyStageResult, header, ErrInvalidReceiptsSize).
+			withValues(len(referenceBlock.Transactions), len(br.Receipts))
 	}
 
 	// Make sure the world state root matches up
-	if br.Root != referenceBlock.Header.StateRoot {
-		return ErrInvalidStateRoot
+	if br.Root != header.StateRoot {
+		return newVerifyError(VerifyStageResult, header, ErrInvalidStateRoot).
+			withValues(header.StateRoot, br.Root)
 	}
 
 	// Make sure the gas used is valid
-	if br.TotalGas != referenceBlock.Header.GasUsed {
-		return ErrInvalidGasUsed
+	if br.TotalGas != header.GasUsed {
+		return newVerifyError(VerifyStageResult, header, ErrInvalidGasUsed).
+			withValues(header.GasUsed, br.TotalGas)
 	}
 
 	// Make sure the receipts root matches up
 	receiptsRoot := buildroot.CalculateReceiptsRoot(br.Receipts)
-	if receiptsRoot != referenceBlock.Header.ReceiptsRoot {
-		return ErrInvalidReceiptsRoot
+	if receiptsRoot != header.ReceiptsRoot {
+		return newVerifyError(VerifyStageResult, header, ErrInvalidReceiptsRoot).
+			withValues(header.ReceiptsRoot, receiptsRoot)
+	}
+
+	// Make sure the logs bloom matches up, otherwise the log filters skip the block
+	if checkBloom {
+		if bloom := types.CreateBloom(br.Receipts); bloom != header.LogsBloom {
+			return newVerifyError(VerifyStageResult, header, ErrInvalidLogsBloom).
+				withValues(header.LogsBloom, bloom)
+		}
 	}
 
 	return nil
@@ -854,6 +1125,13 @@ func (br *BlockResult) verifyBlockResult(referenceBlock *types.Block) error {
 // executeBlockTransactions executes the transactions in the block locally,
 // and reports back the block execution result
 func (b *Blockchain) executeBlockTransactions(block *types.Block) (*BlockResult, error) {
+	return b.executeBlock(block, true)
+}
+
+// executeBlock executes the transactions in the block on the state of the parent.
+// The state is committed only if required, otherwise the root is hashed without
+// writing the trie nodes.
+func (b *Blockchain) executeBlock(block *types.Block, commit bool) (*BlockResult, error) {
 	if b.isStopped() {
 		return nil, ErrClosed
 	}
@@ -886,6 +1164,12 @@ func (b *Blockchain) executeBlockTransactions(block *types.Block) (*BlockResult,
 		return nil, err
 	}
 
+	// the imported blocks wait for the missing trie nodes of the parent state to be
+	// re-fetched from the peers, the dry runs fail at once
+	if commit {
+		txn.FetchMissingNodes()
+	}
+
 	// upgrade system contract first if needed
 	upgrader.UpgradeSystem(
 		b.Config().ChainID,
@@ -925,6 +1209,26 @@ func (b *Blockchain) executeBlockTransactions(block *types.Block) (*BlockResult,
 		return nil, ErrClosed
 	}
 
+	executionTime := time.Since(begin)
+	stats := txn.AccessStats()
+
+	if !commit {
+		root, err := txn.Root()
+		if err != nil {
+			return nil, err
+		}
+
+		return &BlockResult{
+			Root:        root,
+			Receipts:    txn.Receipts(),
+			TotalGas:    txn.TotalGas(),
+			AccessStats: stats,
+
+			ExecutionTime: executionTime,
+			SystemTxCount: len(systemTxs),
+		}, nil
+	}
+
 	_, root, err := txn.Commit()
 	if err != nil {
 		return nil, err
@@ -933,10 +1237,16 @@ func (b *Blockchain) executeBlockTransactions(block *types.Block) (*BlockResult,
 	// Append the receipts to the receipts cache
 	b.receiptsCache.Add(header.Hash, txn.Receipts())
 
+	b.metrics.StateAccessObserve(stats)
+
 	return &BlockResult{
-		Root:     root,
-		Receipts: txn.Receipts(),
-		TotalGas: txn.TotalGas(),
+		Root:        root,
+		Receipts:    txn.Receipts(),
+		TotalGas:    txn.TotalGas(),
+		AccessStats: stats,
+
+		ExecutionTime: executionTime,
+		SystemTxCount: len(systemTxs),
 	}, nil
 }
 
@@ -946,12 +1256,22 @@ func (b *Blockchain) WriteBlock(block *types.Block, source string) error {
 		return ErrClosed
 	}
 
+	if b.readOnly {
+		return ErrReadOnly
+	}
+
 	b.writeLock.Lock()
 	defer b.writeLock.Unlock()
 
+	if b.IsHalted() {
+		return ErrChainHalted
+	}
+
 	b.wg.Add(1)
 	defer b.wg.Done()
 
+	begin := time.Now()
+
 	if block.Number() <= b.Header().Number {
 		b.logger.Info("block already inserted", "block", block.Number(), "source", source)
 
@@ -970,23 +1290,25 @@ func (b *Blockchain) WriteBlock(block *types.Block, source string) error {
 	// nil checked by verify functions
 	header := block.Header
 
-	if err := b.writeBody(block); err != nil {
-		return err
-	}
-
 	// Fetch the block receipts
 	blockReceipts, receiptsErr := b.extractBlockReceipts(block)
 	if receiptsErr != nil {
 		return receiptsErr
 	}
 
-	// write the receipts, do it only after the header has been written.
-	// Otherwise, a client might ask for a header once the receipt is valid,
-	// but before it is written into the storage
-	if err := b.db.WriteReceipts(block.Hash(), blockReceipts); err != nil {
+	// the header, body, receipts, difficulty, canonical hash and transaction lookups
+	// of the block are committed at once, so a crash never leaves a part of them.
+	// The chain head is set after the commit, so a client never asks for the body
+	// and receipts of a valid header before they are written.
+	batch := b.db.NewAtomicBatch()
+
+	// The queued block data is served from memory until it is flushed
+	if err := b.writeBlockData(batch, block, blockReceipts); err != nil {
 		return err
 	}
 
+	commitBegin := time.Now()
+
 	//	update snapshot
 	if err := b.consensus.ProcessHeaders([]*types.Header{header}); err != nil {
 		return err
@@ -994,15 +1316,53 @@ func (b *Blockchain) WriteBlock(block *types.Block, source string) error {
 
 	// Write the header to the chain
 	evnt := &Event{Source: source}
-	if err := b.writeHeaderImpl(evnt, header); err != nil {
+
+	head, td, err := b.writeHeaderImpl(evnt, header, batch)
+	if err != nil {
 		return err
 	}
 
+	if err := b.commitHead(batch, head, td); err != nil {
+		return err
+	}
+
+	b.metrics.BlockCommitSecondsObserve(time.Since(commitBegin).Seconds())
+
+	b.executionStats.markImported(block.Number(), block.Hash())
+
 	// Send new head after written
 	b.dispatchEvent(evnt)
 
-	// Update the average gas price
-	b.updateGasPriceAvgWithBlock(block)
+	// Index the logs of the new canonical blocks
+	if b.logIndexer != nil && evnt.Type != EventFork {
+		b.logIndexer.notify(lowestHeaderNumber(evnt.NewChain))
+	}
+
+	if b.bloomIndexer != nil && evnt.Type != EventFork {
+		b.bloomIndexer.notify(lowestHeaderNumber(evnt.NewChain))
+	}
+
+	if b.chainStatsIndexer != nil && evnt.Type != EventFork {
+		b.chainStatsIndexer.notify(lowestHeaderNumber(evnt.NewChain))
+	}
+
+	if b.systemTxIndexer != nil && evnt.Type != EventFork {
+		b.systemTxIndexer.notify(lowestHeaderNumber(evnt.NewChain))
+	}
+
+	// Delete the tx lookups out of the retention window
+	if b.txLookupUnindexer != nil && evnt.Type != EventFork {
+		b.txLookupUnindexer.notify()
+	}
+
+	if b.receiptsBackfiller != nil && evnt.Type != EventFork {
+		b.receiptsBackfiller.notify()
+	}
+
+	// Sample the gas prices of the new head
+	if evnt.Type != EventFork {
+		b.updateGasPriceHistory(block)
+	}
 
 	logArgs := []interface{}{
 		"number", header.Number,
@@ -1019,8 +1379,11 @@ func (b *Blockchain) WriteBlock(block *types.Block, source string) error {
 
 	if header != nil {
 		b.collectMetrics(header.Number, header.GasUsed)
+		b.collectImportMetrics(len(block.Transactions), header.GasUsed)
 	}
 
+	b.metrics.BlockWrittenSecondsObserve(time.Since(begin).Seconds())
+
 	return nil
 }
 
@@ -1028,16 +1391,25 @@ func (b *Blockchain) collectMetrics(number, gasused uint64) {
 	b.metrics.GasUsedObserve(float64(gasused))
 	b.metrics.SetBlockHeight(float64(number))
 
-	b.gpAverage.RLock()
-	defer b.gpAverage.RUnlock()
+	var prices []*big.Int
+	if latest := b.gpHistory.latest(); latest != nil && latest.number == number {
+		prices = latest.prices
+	}
 
 	// collect non-miner transaction count
-	b.metrics.TransactionNumObserve(float64(b.gpAverage.count.Uint64()))
+	b.metrics.TransactionNumObserve(float64(len(prices)))
 
 	// only collect price value with value
-	if b.gpAverage.max.Sign() > 0 {
-		b.metrics.MaxGasPriceObserve(float64(b.gpAverage.max.Uint64()))
-		b.metrics.GasPriceAverageObserve(float64(b.gpAverage.price.Uint64()))
+	if len(prices) > 0 {
+		sum := new(big.Int)
+		for _, price := range prices {
+			sum.Add(sum, price)
+		}
+
+		avg := sum.Div(sum, big.NewInt(int64(len(prices))))
+
+		b.metrics.MaxGasPriceObserve(float64(prices[len(prices)-1].Uint64()))
+		b.metrics.GasPriceAverageObserve(float64(avg.Uint64()))
 	} else {
 		// use price bottom limit
 		b.metrics.MaxGasPriceObserve(float64(b.priceBottomLimit))
@@ -1057,6 +1429,8 @@ func (b *Blockchain) extractBlockReceipts(block *types.Block) ([]*types.Receipt,
 			return nil, err
 		}
 
+		b.recordExecutionStats(block, blockResult)
+
 		return blockResult.Receipts, nil
 	}
 
@@ -1068,54 +1442,28 @@ func (b *Blockchain) extractBlockReceipts(block *types.Block) ([]*types.Receipt,
 	return extractedReceipts, nil
 }
 
-// updateGasPriceAvgWithBlock extracts the gas price information from the
-// block, and updates the average gas price for the chain accordingly
-func (b *Blockchain) updateGasPriceAvgWithBlock(block *types.Block) {
-	if len(block.Transactions) < 1 {
-		// No transactions in the block,
-		// so no gas price average to update
-		return
-	}
-
-	signer := crypto.NewSigner(b.ForksInTime(block.Number()), b.ChainID())
-	gasPrices := make([]*big.Int, 0, len(block.Transactions))
-
-	for _, tx := range block.Transactions {
-		// Ignore transactions from miner, since they will always be included
-		if from, _ := signer.Sender(tx); from == block.Header.Miner {
-			continue
-		}
-
-		gasPrices = append(gasPrices, tx.GasPrice)
-	}
-
-	b.updateGasPriceAvg(gasPrices)
-}
-
-// writeBody writes the block body to the DB.
-// Additionally, it also updates the txn lookup, for txnHash -> block lookups
-func (b *Blockchain) writeBody(block *types.Block) error {
+// writeBlockData writes the body, receipts and transaction lookups of the block into
+// the batch, or queues them when the asynchronous write is enabled
+func (b *Blockchain) writeBlockData(batch storage.Batch, block *types.Block, receipts []*types.Receipt) error {
 	begin := time.Now()
 	defer func() {
-		b.metrics.BlockWrittenSecondsObserve(time.Since(begin).Seconds())
+		b.metrics.BlockPersistSecondsObserve(time.Since(begin).Seconds())
 	}()
 
-	body := block.Body()
-
-	// Write the full body (txns + receipts)
-	if err := b.db.WriteBody(block.Header.Hash, body); err != nil {
-		return err
+	job := &blockWriteJob{
+		block:    block,
+		receipts: receipts,
+		lookups:  true,
 	}
 
-	// Write txn lookups (txHash -> block)
-	for _, tx := range block.Transactions {
-		// write hash lookup
-		if err := b.db.WriteTxLookup(tx.Hash(), block.Hash()); err != nil {
-			return err
+	// Skip the txn lookups out of the retention window, they would be deleted anyway
+	if b.txLookupLimit > 0 {
+		if head := b.Header(); head != nil && block.Number()+b.txLookupLimit <= head.Number {
+			job.lookups = false
 		}
 	}
 
-	return nil
+	return b.writer.enqueue(batch, job)
 }
 
 // ReadTxLookup returns the block hash using the transaction hash
@@ -1124,6 +1472,10 @@ func (b *Blockchain) ReadTxLookup(hash types.Hash) (types.Hash, bool) {
 		return types.ZeroHash, false
 	}
 
+	if v, ok := b.writer.readTxLookup(hash); ok {
+		return v, true
+	}
+
 	v, ok := b.db.ReadTxLookup(hash)
 
 	return v, ok
@@ -1219,23 +1571,34 @@ func (b *Blockchain) dispatchEvent(evnt *Event) {
 	b.stream.push(evnt)
 }
 
-// writeHeaderImpl writes a block and the data, assumes the genesis is already set
-func (b *Blockchain) writeHeaderImpl(evnt *Event, header *types.Header) error {
+// writeHeaderImpl writes a block and the data into the batch, assumes the genesis is
+// already set. It returns the new chain head and its total difficulty, which are set
+// once the batch is committed, or nil if the chain head is not changed.
+func (b *Blockchain) writeHeaderImpl(
+	evnt *Event,
+	header *types.Header,
+	batch storage.Batch,
+) (*types.Header, *big.Int, error) {
 	if b.isStopped() {
-		return ErrClosed
+		return nil, nil, ErrClosed
 	}
 
 	currentHeader := b.Header()
 
 	currentTD, ok := b.readTotalDifficulty(currentHeader.Hash)
 	if !ok {
-		panic("failed to get header difficulty")
+		td, err := b.reconstructTotalDifficulty(currentHeader, batch)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		currentTD = td
 	}
 
 	// parent total difficulty of incoming header
 	parentTD, ok := b.readTotalDifficulty(header.ParentHash)
 	if !ok {
-		return fmt.Errorf(
+		return nil, nil, fmt.Errorf(
 			"parent of %s (%d) not found",
 			header.Hash.String(),
 			header.Number,
@@ -1243,25 +1606,31 @@ func (b *Blockchain) writeHeaderImpl(evnt *Event, header *types.Header) error {
 	}
 
 	// Write the difficulty
-	if err := b.db.WriteTotalDifficulty(
+	if err := batch.WriteTotalDifficulty(
+		header.Number,
 		header.Hash,
 		big.NewInt(0).Add(
 			parentTD,
 			big.NewInt(0).SetUint64(header.Difficulty),
 		),
 	); err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// Write header
-	if err := b.db.WriteHeader(header); err != nil {
-		return err
+	if err := batch.WriteHeader(header); err != nil {
+		return nil, nil, err
 	}
 
 	// Write canonical header
 	if header.ParentHash == currentHeader.Hash {
 		// Fast path to save the new canonical header
-		return b.writeCanonicalHeader(evnt, header)
+		td, err := b.writeCanonicalHeader(evnt, header, batch)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return header, td, nil
 	}
 
 	// Update the headers cache
@@ -1270,24 +1639,27 @@ func (b *Blockchain) writeHeaderImpl(evnt *Event, header *types.Header) error {
 	incomingTD := big.NewInt(0).Add(parentTD, big.NewInt(0).SetUint64(header.Difficulty))
 	if incomingTD.Cmp(currentTD) > 0 {
 		// new block has higher difficulty, reorg the chain
-		if err := b.handleReorg(evnt, currentHeader, header); err != nil {
-			return err
+		td, err := b.handleReorg(evnt, currentHeader, header, batch)
+		if err != nil {
+			return nil, nil, err
 		}
-	} else {
-		// new block has lower difficulty, create a new fork
-		evnt.AddOldHeader(header)
-		evnt.Type = EventFork
 
-		if err := b.writeFork(header); err != nil {
-			return err
-		}
+		return header, td, nil
 	}
 
-	return nil
+	// new block has lower difficulty, create a new fork
+	evnt.AddOldHeader(header)
+	evnt.Type = EventFork
+
+	if err := b.writeFork(batch, header); err != nil {
+		return nil, nil, err
+	}
+
+	return nil, nil, nil
 }
 
-// writeFork writes the new header forks to the DB
-func (b *Blockchain) writeFork(header *types.Header) error {
+// writeFork writes the new header forks into the batch
+func (b *Blockchain) writeFork(batch storage.Batch, header *types.Header) error {
 	forks, err := b.db.ReadForks()
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
@@ -1306,19 +1678,21 @@ func (b *Blockchain) writeFork(header *types.Header) error {
 	}
 
 	newForks = append(newForks, header.Hash)
-	if err := b.db.WriteForks(newForks); err != nil {
+	if err := batch.WriteForks(newForks); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// handleReorg handles a reorganization event
+// handleReorg writes a reorganization into the batch, and returns the total difficulty
+// of the new chain head
 func (b *Blockchain) handleReorg(
 	evnt *Event,
 	oldHeader *types.Header,
 	newHeader *types.Header,
-) error {
+	batch storage.Batch,
+) (*big.Int, error) {
 	newChainHead := newHeader
 	oldChainHead := oldHeader
 
@@ -1331,7 +1705,7 @@ func (b *Blockchain) handleReorg(
 	for oldHeader.Number > newHeader.Number {
 		oldHeader, ok = b.readHeader(oldHeader.ParentHash)
 		if !ok {
-			return fmt.Errorf("header '%s' not found", oldHeader.ParentHash.String())
+			return nil, fmt.Errorf("header '%s' not found", oldHeader.ParentHash.String())
 		}
 
 		oldChain = append(oldChain, oldHeader)
@@ -1341,7 +1715,7 @@ func (b *Blockchain) handleReorg(
 	for newHeader.Number > oldHeader.Number {
 		newHeader, ok = b.readHeader(newHeader.ParentHash)
 		if !ok {
-			return fmt.Errorf("header '%s' not found", newHeader.ParentHash.String())
+			return nil, fmt.Errorf("header '%s' not found", newHeader.ParentHash.String())
 		}
 
 		newChain = append(newChain, newHeader)
@@ -1350,17 +1724,32 @@ func (b *Blockchain) handleReorg(
 	for oldHeader.Hash != newHeader.Hash {
 		oldHeader, ok = b.readHeader(oldHeader.ParentHash)
 		if !ok {
-			return fmt.Errorf("header '%s' not found", oldHeader.ParentHash.String())
+			return nil, fmt.Errorf("header '%s' not found", oldHeader.ParentHash.String())
 		}
 
 		newHeader, ok = b.readHeader(newHeader.ParentHash)
 		if !ok {
-			return fmt.Errorf("header '%s' not found", newHeader.ParentHash.String())
+			return nil, fmt.Errorf("header '%s' not found", newHeader.ParentHash.String())
 		}
 
 		oldChain = append(oldChain, oldHeader)
 	}
 
+	// the common ancestor is excluded, but the old chain head is dropped too
+	if depth := uint64(len(oldChain)); b.maxReorgDepth > 0 && depth > b.maxReorgDepth {
+		b.metrics.ReorgRejectedInc()
+		b.logger.Error("CRITICAL: reject deep reorg, chain might be attacked",
+			"depth", depth,
+			"max", b.maxReorgDepth,
+			"old_head", oldChainHead.Number,
+			"old_hash", oldChainHead.Hash,
+			"new_head", newChainHead.Number,
+			"new_hash", newChainHead.Hash,
+		)
+
+		return nil, fmt.Errorf("%w: depth %d, max %d", ErrReorgTooDeep, depth, b.maxReorgDepth)
+	}
+
 	for _, b := range oldChain[:len(oldChain)-1] {
 		evnt.AddOldHeader(b)
 	}
@@ -1372,27 +1761,27 @@ func (b *Blockchain) handleReorg(
 		evnt.AddNewHeader(b)
 	}
 
-	if err := b.writeFork(oldChainHead); err != nil {
-		return fmt.Errorf("failed to write the old header as fork: %w", err)
+	if err := b.writeFork(batch, oldChainHead); err != nil {
+		return nil, fmt.Errorf("failed to write the old header as fork: %w", err)
 	}
 
 	// Update canonical chain numbers
 	for _, h := range newChain {
-		if err := b.db.WriteCanonicalHash(h.Number, h.Hash); err != nil {
-			return err
+		if err := batch.WriteCanonicalHash(h.Number, h.Hash); err != nil {
+			return nil, err
 		}
 	}
 
-	diff, err := b.advanceHead(newChainHead)
+	diff, err := b.writeHead(batch, newChainHead)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Set the event type and difficulty
 	evnt.Type = EventReorg
 	evnt.SetDifficulty(diff)
 
-	return nil
+	return diff, nil
 }
 
 // GetForks returns the forks
@@ -1457,18 +1846,159 @@ func (b *Blockchain) GetBlockByNumber(blockNumber uint64, full bool) (*types.Blo
 	return b.GetBlockByHash(blockHash, full)
 }
 
+// FilterLogBlocks returns the block numbers within [from, to] which might contain logs
+// of the addresses and topics. The log index is preferred as it is exact, the bloom bits
+// are used otherwise. Blocks not indexed yet are always included.
+// It returns false when both indexes are disabled or there is no criteria at all.
+func (b *Blockchain) FilterLogBlocks(
+	addresses []types.Address,
+	topics [][]types.Hash,
+	from, to uint64,
+) ([]uint64, bool) {
+	if !hasLogCriteria(addresses, topics) {
+		return nil, false
+	}
+
+	var (
+		indexed     uint64 // number of the indexed blocks from the genesis
+		filterBlock func([]types.Address, [][]types.Hash, uint64, uint64) []uint64
+	)
+
+	switch {
+	case b.logIndexer != nil:
+		indexed = b.logIndexer.indexedBlocks()
+		filterBlock = b.logIndexer.filterBlocks
+	case b.bloomIndexer != nil:
+		indexed = b.bloomIndexer.indexedBlocks()
+		filterBlock = b.bloomIndexer.filterBlocks
+	default:
+		return nil, false
+	}
+
+	if from > to {
+		return []uint64{}, true
+	}
+
+	if indexed <= from {
+		return nil, false
+	}
+
+	end := to
+	if indexed-1 < end {
+		end = indexed - 1
+	}
+
+	numbers := filterBlock(addresses, topics, from, end)
+
+	// blocks not indexed yet
+	for n := end + 1; n > end && n <= to; n++ {
+		numbers = append(numbers, n)
+	}
+
+	return numbers, true
+}
+
+func hasLogCriteria(addresses []types.Address, topics [][]types.Hash) bool {
+	if len(addresses) > 0 {
+		return true
+	}
+
+	for _, set := range topics {
+		if len(set) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func lowestHeaderNumber(headers []*types.Header) uint64 {
+	lowest := uint64(0)
+
+	for i, h := range headers {
+		if i == 0 || h.Number < lowest {
+			lowest = h.Number
+		}
+	}
+
+	return lowest
+}
+
 // SubscribeEvents returns a blockchain event subscription
 func (b *Blockchain) SubscribeEvents() Subscription {
-	return b.stream.subscribe()
+	return b.stream.subscribe(nil)
+}
+
+// SubscribeEventsWithFilter returns a blockchain event subscription
+// which only receives events matching the filter
+func (b *Blockchain) SubscribeEventsWithFilter(filter *EventFilter) Subscription {
+	return b.stream.subscribe(filter)
+}
+
+// Compact compacts the keys within [start, limit) of the blockchain database, nil
+// start and limit stand for the first and the last key
+func (b *Blockchain) Compact(start, limit []byte) error {
+	compacter, ok := b.db.(kvdb.KVCompacter)
+	if !ok {
+		return kvdb.ErrCompactNotSupported
+	}
+
+	return compacter.Compact(start, limit)
+}
+
+// DBProperty returns the named property of the blockchain database, like "leveldb.stats"
+func (b *Blockchain) DBProperty(name string) (string, error) {
+	props, ok := b.db.(kvdb.KVProperties)
+	if !ok {
+		return "", kvdb.ErrPropertyNotSupported
+	}
+
+	return props.Property(name)
 }
 
 // Close closes the DB connection
 func (b *Blockchain) Close() error {
+	if !b.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	// stop the re-executions before the executor
+	if b.receiptsBackfiller != nil {
+		b.receiptsBackfiller.close()
+	}
+
 	b.executor.Stop()
 	b.stop()
 
+	if b.cacheTunerCloseCh != nil {
+		close(b.cacheTunerCloseCh)
+	}
+
 	b.wg.Wait()
 
+	// flush the queued blocks
+	b.writer.close()
+
+	if b.logIndexer != nil {
+		b.logIndexer.close()
+	}
+
+	if b.bloomIndexer != nil {
+		b.bloomIndexer.close()
+	}
+
+	if b.chainStatsIndexer != nil {
+		b.chainStatsIndexer.close()
+	}
+
+	if b.systemTxIndexer != nil {
+		b.systemTxIndexer.close()
+	}
+
+	if b.txLookupUnindexer != nil {
+		b.txLookupUnindexer.close()
+	}
+
 	// close db at last
 	return b.db.Close()
 }
@@ -1,17 +1,23 @@
 package blockchain
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/dogechain-lab/dogechain/blockchain/storage"
 	"github.com/dogechain-lab/dogechain/blockchain/storage/kvstorage"
 	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/contracts/abis"
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
 	"github.com/dogechain-lab/dogechain/state"
 	"github.com/dogechain-lab/dogechain/types"
+	"github.com/dogechain-lab/dogechain/types/buildroot"
 	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
 )
@@ -544,12 +550,142 @@ func TestForkUnknownParents(t *testing.T) {
 	assert.Error(t, b.WriteHeadersWithBodies([]*types.Header{h1[12]}))
 }
 
+func TestGetHeadersInRange(t *testing.T) {
+	headers := NewTestHeaders(10)
+	b := NewTestBlockchain(t, headers)
+
+	hashes := b.GetCanonicalHashesInRange(2, 5)
+	assert.Len(t, hashes, 4)
+
+	for i, hash := range hashes {
+		assert.Equal(t, headers[i+2].Hash, hash)
+	}
+
+	result := b.GetHeadersInRange(2, 5)
+	assert.Len(t, result, 4)
+
+	for i, h := range result {
+		assert.Equal(t, headers[i+2].Hash, h.Hash)
+	}
+
+	// stop at the head
+	result = b.GetHeadersInRange(7, 20)
+	assert.Len(t, result, 3)
+
+	for i, h := range result {
+		assert.Equal(t, headers[i+7].Hash, h.Hash)
+	}
+
+	assert.Empty(t, b.GetHeadersInRange(5, 4))
+}
+
+func TestWarmup(t *testing.T) {
+	headers := NewTestHeaders(10)
+	b := NewTestBlockchain(t, headers)
+
+	// a cold cache of a restarted node
+	b.headersCache.Purge()
+
+	stats, err := b.Warmup(context.Background(), 3, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, WarmupStats{Headers: 3}, stats)
+
+	assert.True(t, b.headersCache.Contains(headers[9].Hash))
+	assert.True(t, b.headersCache.Contains(headers[7].Hash))
+	assert.False(t, b.headersCache.Contains(headers[6].Hash))
+
+	// bounded by the chain
+	stats, err = b.Warmup(context.Background(), 100, 0)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, stats.Headers, len(headers))
+	assert.True(t, b.headersCache.Contains(headers[1].Hash))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = b.Warmup(ctx, 3, 3)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGetBlockNumbersByStateRoot(t *testing.T) {
+	var (
+		root1 = types.StringToHash("1")
+		root2 = types.StringToHash("2")
+	)
+
+	headers := NewTestHeaders(6)
+
+	// blocks 3 and 4 change no state
+	for i, root := range []types.Hash{root1, root1, root2, root2, root1} {
+		headers[i+1].StateRoot = root
+		headers[i+1].ParentHash = headers[i].Hash
+		headers[i+1].ComputeHash()
+	}
+
+	b := NewTestBlockchain(t, headers)
+
+	assert.Equal(t, []uint64{1, 2, 5}, b.GetBlockNumbersByStateRoot(root1))
+	assert.Equal(t, []uint64{3, 4}, b.GetBlockNumbersByStateRoot(root2))
+	assert.Empty(t, b.GetBlockNumbersByStateRoot(types.StringToHash("3")))
+
+	// a fork with less difficulty is not canonical
+	fork := &types.Header{
+		Number:       3,
+		ParentHash:   headers[2].Hash,
+		StateRoot:    root1,
+		TxRoot:       types.EmptyRootHash,
+		Sha3Uncles:   types.EmptyUncleHash,
+		ReceiptsRoot: types.EmptyRootHash,
+		ExtraData:    []byte{},
+	}
+	fork.ComputeHash()
+
+	assert.NoError(t, b.WriteHeaders([]*types.Header{fork}))
+	assert.Equal(t, headers[5].Hash, b.Header().Hash)
+
+	assert.Equal(t, []uint64{1, 2, 5}, b.GetBlockNumbersByStateRoot(root1))
+}
+
+func TestRejectDeepReorg(t *testing.T) {
+	b := NewTestBlockchain(t, nil)
+	b.SetMaxReorgDepth(1)
+
+	chain := dummyChain{
+		headers: map[byte]*types.Header{},
+	}
+
+	for _, h := range []*header{
+		mock(0x0),
+		mock(0x1),
+		mock(0x2),
+		mock(0x3),
+		mock(0x4).Parent(0x1).Diff(10).Number(2),
+	} {
+		assert.NoError(t, chain.add(h))
+	}
+
+	assert.NoError(t, b.writeGenesisImpl(chain.headers[0x0]))
+	assert.NoError(t, b.WriteHeaders([]*types.Header{
+		chain.headers[0x1],
+		chain.headers[0x2],
+		chain.headers[0x3],
+	}))
+
+	// reorg drops block 2 and 3
+	err := b.WriteHeaders([]*types.Header{chain.headers[0x4]})
+	assert.ErrorIs(t, err, ErrReorgTooDeep)
+
+	// head not changed
+	assert.Equal(t, chain.headers[0x3].Hash, b.Header().Hash)
+}
+
 func TestBlockchainWriteBody(t *testing.T) {
 	storage, err := kvstorage.NewMemoryStorageBuilder(hclog.NewNullLogger()).Build()
 	assert.NoError(t, err)
 
 	b := &Blockchain{
 		db:      storage,
+		writer:  newBlockWriter(hclog.NewNullLogger(), storage, 0),
 		metrics: NilMetrics(),
 	}
 
@@ -564,7 +700,13 @@ func TestBlockchainWriteBody(t *testing.T) {
 	}
 	block.Header.ComputeHash()
 
-	if err := b.writeBody(block); err != nil {
+	batch := storage.NewAtomicBatch()
+
+	if err := b.writeBlockData(batch, block, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := batch.Write(); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -637,74 +779,124 @@ func TestCalculateGasLimit(t *testing.T) {
 	}
 }
 
-// TestGasPriceAverage tests the average gas price of the
-// blockchain
-func TestGasPriceAverage(t *testing.T) {
-	testTable := []struct {
-		name               string
-		previousMax        *big.Int
-		previousAverage    *big.Int
-		previousCount      *big.Int
-		newValues          []*big.Int
-		expectedNewMax     *big.Int
-		expectedNewAverage *big.Int
-	}{
-		{
-			name:            "no previous average data",
-			previousMax:     big.NewInt(0),
-			previousAverage: big.NewInt(0),
-			previousCount:   big.NewInt(0),
-			newValues: []*big.Int{
-				big.NewInt(1),
-				big.NewInt(2),
-				big.NewInt(3),
-				big.NewInt(4),
-				big.NewInt(5),
-			},
-			expectedNewMax:     big.NewInt(5),
-			expectedNewAverage: big.NewInt(3),
-		},
-		{
-			name: "previous average data",
-			// For example (5 + 5 + 5 + 5 + 5) / 5
-			previousMax:     big.NewInt(5),
-			previousAverage: big.NewInt(5),
-			previousCount:   big.NewInt(5),
-			newValues: []*big.Int{
-				big.NewInt(1),
-				big.NewInt(2),
-				big.NewInt(3),
-			},
-			expectedNewMax: big.NewInt(3),
-			// (1 + 2 + 3) / 3
-			expectedNewAverage: big.NewInt(2),
-		},
+// TestGasPriceHistory tests the gas price history of the recent blocks
+func TestGasPriceHistory(t *testing.T) {
+	t.Parallel()
+
+	prices := func(values ...int64) []*big.Int {
+		res := make([]*big.Int, len(values))
+		for i, v := range values {
+			res[i] = big.NewInt(v)
+		}
+
+		return res
 	}
 
-	for _, testCase := range testTable {
-		t.Run(testCase.name, func(t *testing.T) {
-			// Setup the mock data
-			blockchain := NewTestBlockchain(t, nil)
-			blockchain.gpAverage.price = testCase.previousAverage
-			blockchain.gpAverage.count = testCase.previousCount
+	t.Run("ring buffer keeps the last blocks", func(t *testing.T) {
+		t.Parallel()
+
+		history := newGasPriceHistory(2)
+
+		for n := uint64(1); n <= 3; n++ {
+			history.push(&blockGasPrices{number: n, prices: prices(int64(n))})
+		}
+
+		numbers := []uint64{}
+		history.each(func(block *blockGasPrices) {
+			numbers = append(numbers, block.number)
+		})
+
+		assert.Equal(t, []uint64{3, 2}, numbers)
+		assert.Equal(t, uint64(3), history.latest().number)
+	})
+
+	t.Run("reorged blocks are dropped", func(t *testing.T) {
+		t.Parallel()
+
+		history := newGasPriceHistory(4)
+
+		for n := uint64(1); n <= 4; n++ {
+			history.push(&blockGasPrices{number: n})
+		}
+
+		history.push(&blockGasPrices{number: 3, gasRatio: 50})
+
+		numbers := []uint64{}
+		history.each(func(block *blockGasPrices) {
+			numbers = append(numbers, block.number)
+		})
+
+		assert.Equal(t, []uint64{3, 2, 1}, numbers)
+		assert.Equal(t, uint64(50), history.latest().gasRatio)
+	})
+
+	t.Run("percentile of the lowest prices of every block", func(t *testing.T) {
+		t.Parallel()
+
+		history := newGasPriceHistory(4)
+		assert.Nil(t, history.percentile(50, nil))
+
+		history.ignoreUnder = big.NewInt(2)
+
+		// only the lowest 3 prices not below 2 are sampled
+		history.push(&blockGasPrices{number: 1, prices: prices(1, 2, 3, 4, 100)})
+		// empty blocks use the fallback price
+		history.push(&blockGasPrices{number: 2})
+
+		assert.Equal(t, "2", history.percentile(0, big.NewInt(5)).String())
+		assert.Equal(t, "3", history.percentile(60, big.NewInt(5)).String())
+		assert.Equal(t, "5", history.percentile(100, big.NewInt(5)).String())
+		assert.Equal(t, "4", history.percentile(100, nil).String())
+	})
+
+	t.Run("blockchain samples the non-miner transactions", func(t *testing.T) {
+		t.Parallel()
+
+		blockchain := NewTestBlockchain(t, nil)
+
+		key, err := crypto.GenerateKey()
+		assert.NoError(t, err)
+
+		addr := crypto.PubKeyToAddress(&key.PublicKey)
+		signer := crypto.NewEIP155Signer(uint64(blockchain.Config().ChainID))
 
-			// Update the average gas price
-			blockchain.updateGasPriceAvg(testCase.newValues)
+		txs := make([]*types.Transaction, 0, 3)
 
-			// Make sure the average gas price count is correct
-			assert.Equal(
-				t,
-				int64(len(testCase.newValues)),
-				blockchain.gpAverage.count.Int64(),
-			)
+		for i, price := range []int64{30, 10, 20} {
+			tx, err := signer.SignTx(&types.Transaction{
+				Nonce:    uint64(i),
+				GasPrice: big.NewInt(price),
+				Value:    big.NewInt(0),
+			}, key)
+			assert.NoError(t, err)
+
+			txs = append(txs, tx)
+		}
 
-			// Make sure the max gas price is correct
-			assert.Equal(t, testCase.expectedNewMax.String(), blockchain.gpAverage.max.String())
+		blockchain.updateGasPriceHistory(&types.Block{
+			Header: &types.Header{
+				Number:   1,
+				GasLimit: 100,
+				GasUsed:  90,
+			},
+			Transactions: txs,
+		})
 
-			// Make sure the average gas price is correct
-			assert.Equal(t, testCase.expectedNewAverage.String(), blockchain.gpAverage.price.String())
+		// the miner transactions are ignored
+		blockchain.updateGasPriceHistory(&types.Block{
+			Header: &types.Header{
+				Number:   2,
+				Miner:    addr,
+				GasLimit: 100,
+				GasUsed:  10,
+			},
+			Transactions: txs,
 		})
-	}
+
+		assert.Equal(t, "10", blockchain.SuggestedPrice(0).String())
+		assert.Equal(t, "30", blockchain.SuggestedPrice(100).String())
+		assert.Equal(t, []uint64{10, 90}, blockchain.GasUsedRatios())
+	})
 }
 
 // TestBlockchain_VerifyBlockParent verifies that parent block verification
@@ -1010,3 +1202,1268 @@ func TestBlockchain_VerifyBlockBody(t *testing.T) {
 		assert.ErrorIs(t, blockchain.verifyBlockBody(block), errUnableToExecute)
 	})
 }
+
+func TestBlockResult_VerifyLogsBloom(t *testing.T) {
+	t.Parallel()
+
+	receipts := []*types.Receipt{
+		{
+			Logs: []*types.Log{
+				{
+					Address: types.StringToAddress("1"),
+					Topics:  []types.Hash{types.StringToHash("1")},
+				},
+			},
+		},
+	}
+
+	result := &BlockResult{
+		Root:     types.StringToHash("1"),
+		Receipts: receipts,
+		TotalGas: 21000,
+	}
+
+	newBlock := func(bloom types.Bloom) *types.Block {
+		return &types.Block{
+			Header: &types.Header{
+				StateRoot:    result.Root,
+				GasUsed:      result.TotalGas,
+				ReceiptsRoot: buildroot.CalculateReceiptsRoot(receipts),
+				LogsBloom:    bloom,
+			},
+			Transactions: []*types.Transaction{{}},
+		}
+	}
+
+	// empty bloom is accepted before the fork
+	assert.NoError(t, result.verifyBlockResult(newBlock(types.Bloom{}), false))
+	assert.ErrorIs(t, result.verifyBlockResult(newBlock(types.Bloom{}), true), ErrInvalidLogsBloom)
+	assert.NoError(t, result.verifyBlockResult(newBlock(types.CreateBloom(receipts)), true))
+}
+
+func TestLogIndex(t *testing.T) {
+	var (
+		addr1  = types.StringToAddress("1")
+		addr2  = types.StringToAddress("2")
+		topic1 = types.StringToHash("1")
+		topic2 = types.StringToHash("2")
+	)
+
+	headers := NewTestHeaders(10)
+	b := NewTestBlockchain(t, headers)
+
+	writeLogs := func(number int, logs ...*types.Log) {
+		assert.NoError(t, b.db.WriteReceipts(headers[number].Number, headers[number].Hash, []*types.Receipt{{Logs: logs}}))
+	}
+
+	writeLogs(2, &types.Log{Address: addr1, Topics: []types.Hash{topic1}})
+	writeLogs(4, &types.Log{Address: addr2, Topics: []types.Hash{topic1, topic2}})
+	writeLogs(7, &types.Log{Address: addr1, Topics: []types.Hash{topic2}})
+
+	// disabled
+	_, ok := b.FilterLogBlocks([]types.Address{addr1}, nil, 0, 9)
+	assert.False(t, ok)
+
+	b.EnableLogIndex()
+
+	defer b.Close()
+
+	assert.Eventually(t, func() bool {
+		return b.logIndexer.indexedBlocks() == 10
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cases := []struct {
+		addresses []types.Address
+		topics    [][]types.Hash
+		expected  []uint64
+	}{
+		{[]types.Address{addr1}, nil, []uint64{2, 7}},
+		{[]types.Address{addr1, addr2}, nil, []uint64{2, 4, 7}},
+		{nil, [][]types.Hash{{topic1}}, []uint64{2, 4}},
+		{nil, [][]types.Hash{{}, {topic2}}, []uint64{4, 7}},
+		{[]types.Address{addr1}, [][]types.Hash{{topic2}}, []uint64{7}},
+		{[]types.Address{addr2}, [][]types.Hash{{topic1, topic2}}, []uint64{4}},
+	}
+
+	for _, c := range cases {
+		numbers, ok := b.FilterLogBlocks(c.addresses, c.topics, 1, 9)
+		assert.True(t, ok)
+		assert.Equal(t, c.expected, numbers)
+	}
+
+	// no criteria
+	_, ok = b.FilterLogBlocks(nil, [][]types.Hash{{}}, 1, 9)
+	assert.False(t, ok)
+
+	// rewinds and re-indexes the updated block
+	writeLogs(5, &types.Log{Address: addr2})
+	b.logIndexer.notify(5)
+
+	assert.Eventually(t, func() bool {
+		numbers, _ := b.FilterLogBlocks([]types.Address{addr2}, nil, 1, 9)
+
+		return b.logIndexer.indexedBlocks() == 10 && len(numbers) == 2 && numbers[1] == 5
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSystemTxIndex(t *testing.T) {
+	headers := NewTestHeaders(6)
+	b := NewTestBlockchain(t, headers)
+
+	// the system transactions are sent by the block creator
+	creator := types.StringToAddress("1")
+
+	verifier, ok := b.consensus.(*MockVerifier)
+	assert.True(t, ok)
+
+	verifier.HookGetBlockCreator(func(h *types.Header) (types.Address, error) {
+		return creator, nil
+	})
+	verifier.HookIsSystemTransaction(func(height uint64, coinbase types.Address, tx *types.Transaction) bool {
+		return tx.From == coinbase
+	})
+
+	var (
+		deposit = abis.ValidatorSetABI.Methods["deposit"].ID()
+		slash   = append(abis.ValidatorSetABI.Methods["slash"].ID(), make([]byte, 32)...) // method id + address
+	)
+
+	writeBody := func(number int, txs ...*types.Transaction) {
+		assert.NoError(t, b.db.WriteBody(headers[number].Number, headers[number].Hash, &types.Body{Transactions: txs}))
+	}
+
+	writeBody(1,
+		&types.Transaction{Nonce: 1, From: types.StringToAddress("2")},
+		&types.Transaction{Nonce: 2, From: creator, Input: deposit},
+	)
+	writeBody(3,
+		&types.Transaction{Nonce: 3, From: creator, Input: deposit},
+		&types.Transaction{Nonce: 4, From: creator, Input: slash},
+	)
+	// the calls of the other senders are user transactions
+	writeBody(4, &types.Transaction{Nonce: 5, From: types.StringToAddress("2"), Input: slash})
+
+	body, err := b.db.ReadBody(headers[3].Hash)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		[]SystemTxType{SystemTxDeposit, SystemTxSlash},
+		b.GetSystemTxTypes(headers[3], body.Transactions),
+	)
+
+	// disabled
+	_, ok = b.FilterSystemTxBlocks(SystemTxSlash, 0, 5)
+	assert.False(t, ok)
+
+	b.EnableSystemTxIndex()
+
+	defer b.Close()
+
+	assert.Eventually(t, func() bool {
+		return b.systemTxIndexer.indexedBlocks() == 6
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the deposits are in every block, they are not indexed
+	_, ok = b.FilterSystemTxBlocks(SystemTxDeposit, 0, 5)
+	assert.False(t, ok)
+
+	numbers, ok := b.FilterSystemTxBlocks(SystemTxSlash, 0, 5)
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{3}, numbers)
+}
+
+func TestBloomIndex(t *testing.T) {
+	var (
+		addr1  = types.StringToAddress("1")
+		addr2  = types.StringToAddress("2")
+		topic1 = types.StringToHash("1")
+		topic2 = types.StringToHash("2")
+	)
+
+	headers := NewTestHeaders(20)
+
+	setBloom := func(number int, logs ...*types.Log) {
+		headers[number].LogsBloom = types.CreateBloom([]*types.Receipt{{Logs: logs}})
+	}
+
+	setBloom(3, &types.Log{Address: addr1, Topics: []types.Hash{topic1}})
+	setBloom(9, &types.Log{Address: addr2, Topics: []types.Hash{topic1, topic2}})
+	setBloom(14, &types.Log{Address: addr1, Topics: []types.Hash{topic2}})
+
+	// rebuild the chain with the blooms
+	for i := 1; i < len(headers); i++ {
+		headers[i].ParentHash = headers[i-1].Hash
+		headers[i].ComputeHash()
+	}
+
+	b := NewTestBlockchain(t, headers)
+
+	// the test chain does not store the genesis header
+	assert.NoError(t, b.db.WriteHeader(headers[0]))
+
+	// small sections, so that blocks 0 to 15 are indexed
+	b.bloomIndexer = newBloomIndexer(hclog.NewNullLogger(), b.db, func() uint64 {
+		return b.Header().Number
+	}, func(uint64) bool {
+		return true
+	}, 8, 2)
+	b.bloomIndexer.start()
+
+	defer b.Close()
+
+	assert.Eventually(t, func() bool {
+		return b.bloomIndexer.indexedSections() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// blocks not indexed yet
+	tail := []uint64{16, 17, 18, 19}
+
+	cases := []struct {
+		addresses []types.Address
+		topics    [][]types.Hash
+		expected  []uint64
+	}{
+		{[]types.Address{addr1}, nil, []uint64{3, 14}},
+		{[]types.Address{addr1, addr2}, nil, []uint64{3, 9, 14}},
+		{nil, [][]types.Hash{{topic1}}, []uint64{3, 9}},
+		{nil, [][]types.Hash{{}, {topic2}}, []uint64{9, 14}},
+		{[]types.Address{addr1}, [][]types.Hash{{topic2}}, []uint64{14}},
+		{[]types.Address{addr2}, [][]types.Hash{{topic1, topic2}}, []uint64{9}},
+		{[]types.Address{types.StringToAddress("3")}, nil, []uint64{}},
+	}
+
+	for _, c := range cases {
+		numbers, ok := b.FilterLogBlocks(c.addresses, c.topics, 1, 19)
+		assert.True(t, ok)
+		assert.Equal(t, append(c.expected, tail...), numbers)
+	}
+
+	// range within one section
+	numbers, ok := b.FilterLogBlocks([]types.Address{addr1}, nil, 4, 15)
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{14}, numbers)
+
+	// drops the sections covering the updated blocks
+	b.bloomIndexer.notify(10)
+
+	b.bloomIndexer.lock.RLock()
+	assert.Equal(t, uint64(1), b.bloomIndexer.version)
+	b.bloomIndexer.lock.RUnlock()
+
+	assert.Eventually(t, func() bool {
+		return b.bloomIndexer.indexedSections() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestBloomIndex_BeforeLogsBloomFork(t *testing.T) {
+	var (
+		addr1  = types.StringToAddress("1")
+		topic1 = types.StringToHash("1")
+		fork   = uint64(10)
+	)
+
+	headers := NewTestHeaders(20)
+	b := NewTestBlockchain(t, headers)
+
+	// the test chain does not store the genesis header
+	assert.NoError(t, b.db.WriteHeader(headers[0]))
+
+	// blocks 3 and 12 have logs, but only the header of block 12 has the bloom
+	receipts := []*types.Receipt{{
+		Logs: []*types.Log{{Address: addr1, Topics: []types.Hash{topic1}}},
+	}}
+
+	for _, number := range []uint64{3, 12} {
+		header, ok := b.GetHeaderByNumber(number)
+		assert.True(t, ok)
+		assert.Equal(t, types.Bloom{}, header.LogsBloom)
+		assert.NoError(t, b.db.WriteReceipts(number, header.Hash, receipts))
+	}
+
+	b.bloomIndexer = newBloomIndexer(hclog.NewNullLogger(), b.db, func() uint64 {
+		return b.Header().Number
+	}, func(number uint64) bool {
+		return number >= fork
+	}, 8, 2)
+	b.bloomIndexer.start()
+
+	defer b.Close()
+
+	assert.Eventually(t, func() bool {
+		return b.bloomIndexer.indexedSections() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the pre-fork block is found by its receipts, the header bloom is trusted after
+	numbers, ok := b.FilterLogBlocks([]types.Address{addr1}, nil, 1, 15)
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{3}, numbers)
+
+	numbers, ok = b.FilterLogBlocks(nil, [][]types.Hash{{topic1}}, 1, 15)
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{3}, numbers)
+}
+
+func TestChainStats(t *testing.T) {
+	headers := NewTestHeaders(10)
+
+	// two blocks per hour, block 1 is within the first hour with the genesis
+	for i := 1; i < len(headers); i++ {
+		headers[i].Timestamp = uint64(i) * ChainStatsHour / 2
+		headers[i].GasLimit = 100
+		headers[i].GasUsed = uint64(i) * 10
+		headers[i].ParentHash = headers[i-1].Hash
+		headers[i].ComputeHash()
+	}
+
+	b := NewTestBlockchain(t, headers)
+
+	writeTxs := func(number int, prices ...int64) {
+		txs := make([]*types.Transaction, len(prices))
+		for i, price := range prices {
+			txs[i] = &types.Transaction{Nonce: uint64(i), GasPrice: big.NewInt(price), Value: big.NewInt(0)}
+		}
+
+		assert.NoError(t, b.db.WriteBody(headers[number].Number, headers[number].Hash, &types.Body{Transactions: txs}))
+	}
+
+	writeTxs(4, 10)
+	writeTxs(5, 20, 30)
+
+	// disabled
+	_, err := b.GetChainStats(0, 5*ChainStatsHour, ChainStatsHour)
+	assert.ErrorIs(t, err, ErrChainStatsDisabled)
+
+	b.EnableChainStats()
+
+	defer b.Close()
+
+	assert.Eventually(t, func() bool {
+		return b.chainStatsIndexer.indexedBlocks() == 10
+	}, 5*time.Second, 10*time.Millisecond)
+
+	hourly, err := b.GetChainStats(ChainStatsHour, 3*ChainStatsHour-1, ChainStatsHour)
+	assert.NoError(t, err)
+	assert.Len(t, hourly, 2)
+
+	assert.Equal(t, uint64(ChainStatsHour), hourly[0].Time)
+	assert.Equal(t, uint64(2), hourly[0].Blocks)
+	assert.Equal(t, uint64(0), hourly[0].TxCount)
+	assert.Equal(t, 0.25, hourly[0].GasUsedRatio())
+	assert.Equal(t, big.NewInt(0), hourly[0].AvgGasPrice())
+
+	assert.Equal(t, uint64(2*ChainStatsHour), hourly[1].Time)
+	assert.Equal(t, uint64(2), hourly[1].Blocks)
+	assert.Equal(t, uint64(3), hourly[1].TxCount)
+	assert.Equal(t, uint64(90), hourly[1].GasUsed)
+	assert.Equal(t, uint64(200), hourly[1].GasLimit)
+	assert.Equal(t, big.NewInt(20), hourly[1].AvgGasPrice())
+
+	daily, err := b.GetChainStats(0, 5*ChainStatsHour, ChainStatsDay)
+	assert.NoError(t, err)
+	assert.Len(t, daily, 1)
+	assert.Equal(t, uint64(0), daily[0].Time)
+	assert.Equal(t, uint64(9), daily[0].Blocks)
+	assert.Equal(t, uint64(3), daily[0].TxCount)
+	assert.Equal(t, uint64(450), daily[0].GasUsed)
+
+	_, err = b.GetChainStats(0, 1, 1800)
+	assert.ErrorIs(t, err, ErrInvalidChainStatsPeriod)
+
+	_, err = b.GetChainStats(0, 400*ChainStatsDay, ChainStatsDay)
+	assert.ErrorIs(t, err, ErrChainStatsRangeTooLarge)
+
+	// rewinds and aggregates the updated hour again
+	writeTxs(5, 40)
+	b.chainStatsIndexer.notify(5)
+
+	assert.Eventually(t, func() bool {
+		hourly, err := b.GetChainStats(2*ChainStatsHour, 2*ChainStatsHour, ChainStatsHour)
+
+		return b.chainStatsIndexer.indexedBlocks() == 10 && err == nil && len(hourly) == 1 &&
+			hourly[0].Blocks == 2 && hourly[0].TxCount == 2 && hourly[0].AvgGasPrice().Int64() == 25
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTxLookupLimit(t *testing.T) {
+	headers := NewTestHeaders(10)
+	b := NewTestBlockchain(t, headers)
+
+	txs := make([]*types.Transaction, len(headers))
+
+	for i, header := range headers {
+		txs[i] = &types.Transaction{
+			Nonce:    uint64(i),
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+		}
+
+		assert.NoError(t, b.db.WriteBody(header.Number, header.Hash, &types.Body{Transactions: txs[i : i+1]}))
+		assert.NoError(t, b.db.WriteTxLookup(txs[i].Hash(), header.Hash))
+	}
+
+	unindexer := newTxLookupUnindexer(hclog.NewNullLogger(), b.db, func() uint64 {
+		return b.Header().Number
+	}, 3)
+	unindexer.unindex()
+
+	tail, ok := b.db.ReadTxLookupTail()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(7), tail)
+
+	for i, tx := range txs {
+		_, ok := b.ReadTxLookup(tx.Hash())
+		assert.Equal(t, i >= 7, ok, "block %d", i)
+	}
+
+	// blocks out of the retention window do not write lookups
+	b.txLookupLimit = 3
+
+	tx := &types.Transaction{Nonce: 100, GasPrice: big.NewInt(1), Value: big.NewInt(0)}
+	batch := b.db.NewAtomicBatch()
+	assert.NoError(t, b.writeBlockData(batch, &types.Block{Header: headers[5], Transactions: []*types.Transaction{tx}}, nil))
+	assert.NoError(t, batch.Write())
+
+	_, ok = b.ReadTxLookup(tx.Hash())
+	assert.False(t, ok)
+}
+
+func TestReceiptsBackfill(t *testing.T) {
+	headers := NewTestHeaders(8)
+	b := NewTestBlockchain(t, headers)
+
+	receipts := []*types.Receipt{{CumulativeGasUsed: 21000, TxHash: types.StringToHash("1")}}
+
+	for i, header := range headers[1:] {
+		tx := &types.Transaction{Nonce: uint64(i), GasPrice: big.NewInt(1), Value: big.NewInt(0)}
+		assert.NoError(t, b.db.WriteBody(header.Number, header.Hash, &types.Body{Transactions: []*types.Transaction{tx}}))
+
+		// only block 4 could regenerate the same receipts
+		if header.Number == 4 {
+			header.ReceiptsRoot = buildroot.CalculateReceiptsRoot(receipts)
+			assert.NoError(t, b.db.WriteHeader(header))
+		}
+
+		// blocks 4 and 6 miss the receipts
+		if header.Number != 4 && header.Number != 6 {
+			assert.NoError(t, b.db.WriteReceipts(header.Number, header.Hash, receipts))
+		}
+	}
+
+	executed := []uint64{}
+
+	backfiller := newReceiptsBackfiller(hclog.NewNullLogger(), b.db, NilMetrics(), func() uint64 {
+		return b.Header().Number
+	}, func(block *types.Block) (*BlockResult, error) {
+		executed = append(executed, block.Number())
+
+		return &BlockResult{Receipts: receipts}, nil
+	}, 0)
+	backfiller.backfill()
+
+	assert.Equal(t, []uint64{4, 6}, executed)
+
+	found, err := b.db.ReadReceipts(headers[4].Hash)
+	assert.NoError(t, err)
+	assert.Len(t, found, 1)
+
+	// the mismatched receipts are not written
+	_, err = b.db.ReadReceipts(headers[6].Hash)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	head, ok := b.db.ReadReceiptsBackfillHead()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(7), head)
+
+	// the checked blocks are not scanned again
+	executed = executed[:0]
+	backfiller.backfill()
+	assert.Empty(t, executed)
+}
+
+func TestBlockchain_Checkpoint_ForgedSeal(t *testing.T) {
+	t.Parallel()
+
+	config := &chain.Chain{
+		Genesis: &chain.Genesis{},
+		Params: &chain.Params{
+			Forks:          chain.AllForksEnabled,
+			BlockGasTarget: defaultBlockGasTarget,
+			Checkpoint: &chain.Checkpoint{
+				Number: 1000,
+				Hash:   types.StringToHash("1"),
+			},
+		},
+	}
+
+	b, err := newBlockChain(config, nil)
+	assert.NoError(t, err)
+
+	errInvalidSeal := errors.New("invalid seal")
+
+	verifier := &MockVerifier{}
+	verifier.HookVerifyHeader(func(header *types.Header) error {
+		return errInvalidSeal
+	})
+
+	b.SetConsensus(verifier)
+
+	genesis := b.Header()
+
+	// a block far below the checkpoint, linked to the local chain but with a forged seal
+	block := &types.Block{
+		Header: &types.Header{
+			Number:     1,
+			ParentHash: genesis.Hash,
+			Sha3Uncles: types.EmptyUncleHash,
+			TxRoot:     types.EmptyRootHash,
+			StateRoot:  types.EmptyRootHash,
+			GasLimit:   genesis.GasLimit,
+			Timestamp:  genesis.Timestamp + 1,
+		},
+	}
+	block.Header.ComputeHash()
+
+	// the syncer only writes the verified blocks
+	err = b.VerifyFinalizedBlock(block)
+	assert.ErrorIs(t, err, errInvalidSeal)
+
+	var verifyErr *VerifyError
+
+	assert.ErrorAs(t, err, &verifyErr)
+	assert.Equal(t, VerifyStageSeal, verifyErr.Stage)
+
+	// rejected before anything is written
+	assert.Equal(t, genesis.Hash, b.Header().Hash)
+
+	_, ok := b.GetHeaderByNumber(1)
+	assert.False(t, ok)
+}
+
+func TestBlockchain_VerifyAnchoredBlock(t *testing.T) {
+	t.Parallel()
+
+	config := &chain.Chain{
+		Genesis: &chain.Genesis{},
+		Params: &chain.Params{
+			Forks:          chain.AllForksEnabled,
+			BlockGasTarget: defaultBlockGasTarget,
+			Checkpoint: &chain.Checkpoint{
+				Number: 1000,
+				Hash:   types.StringToHash("1"),
+			},
+		},
+	}
+
+	// no executor, the blocks before the checkpoint are never executed
+	b, err := newBlockChain(config, nil)
+	assert.NoError(t, err)
+
+	verifier := &MockVerifier{}
+	verifier.HookVerifyHeader(func(header *types.Header) error {
+		return errors.New("invalid seal")
+	})
+
+	b.SetConsensus(verifier)
+
+	genesis := b.Header()
+	sender := types.StringToAddress("1")
+	to := types.StringToAddress("2")
+
+	txs := []*types.Transaction{
+		{Nonce: 0, To: &to, Gas: 21000, GasPrice: big.NewInt(1), Value: big.NewInt(0), From: sender},
+		{Nonce: 1, Gas: 50000, GasPrice: big.NewInt(1), Value: big.NewInt(0), From: sender},
+	}
+
+	newReceipts := func() []*types.Receipt {
+		receipts := []*types.Receipt{
+			{CumulativeGasUsed: 21000},
+			{CumulativeGasUsed: 61000},
+		}
+
+		for _, receipt := range receipts {
+			receipt.SetStatus(types.ReceiptSuccess)
+		}
+
+		return receipts
+	}
+
+	newBlock := func(number uint64) *types.Block {
+		receipts := newReceipts()
+
+		block := &types.Block{
+			Header: &types.Header{
+				Number:       number,
+				ParentHash:   genesis.Hash,
+				Sha3Uncles:   types.EmptyUncleHash,
+				TxRoot:       buildroot.CalculateTransactionsRoot(txs),
+				ReceiptsRoot: buildroot.CalculateReceiptsRoot(receipts),
+				LogsBloom:    types.CreateBloom(receipts),
+				StateRoot:    types.StringToHash("3"),
+				GasLimit:     genesis.GasLimit,
+				GasUsed:      61000,
+				Timestamp:    genesis.Timestamp + 1,
+			},
+			Transactions: txs,
+		}
+		block.Header.ComputeHash()
+
+		return block
+	}
+
+	// only the history before the checkpoint is anchored
+	err = b.VerifyAnchoredBlock(newBlock(1001), newReceipts())
+	assert.ErrorIs(t, err, ErrAboveCheckpoint)
+
+	block := newBlock(1)
+
+	// the receipts must be the ones of the header
+	forged := newReceipts()
+	forged[1].CumulativeGasUsed = 71000
+
+	err = b.VerifyAnchoredBlock(block, forged)
+	assert.ErrorIs(t, err, ErrInvalidReceiptsRoot)
+
+	// neither the seal is verified nor the block is executed
+	assert.NoError(t, b.VerifyAnchoredBlock(block, newReceipts()))
+	assert.NoError(t, b.WriteBlock(block, "test"))
+	assert.Equal(t, block.Hash(), b.Header().Hash)
+
+	receipts, err := b.GetReceiptsByHash(block.Hash())
+	assert.NoError(t, err)
+	assert.Len(t, receipts, 2)
+
+	assert.Equal(t, txs[0].Hash(), receipts[0].TxHash)
+	assert.Equal(t, uint64(21000), receipts[0].GasUsed)
+	assert.Nil(t, receipts[0].ContractAddress)
+
+	assert.Equal(t, txs[1].Hash(), receipts[1].TxHash)
+	assert.Equal(t, uint64(40000), receipts[1].GasUsed)
+	assert.Equal(t, crypto.CreateAddress(sender, 1).Ptr(), receipts[1].ContractAddress)
+}
+
+func TestBlockchain_CheckpointAnchor(t *testing.T) {
+	t.Parallel()
+
+	checkpoint := &chain.Checkpoint{
+		Number: 1000,
+		Hash:   types.StringToHash("1"),
+	}
+
+	config := &chain.Chain{
+		Genesis: &chain.Genesis{},
+		Params: &chain.Params{
+			Forks:      chain.AllForksEnabled,
+			Checkpoint: checkpoint,
+		},
+	}
+
+	b, err := newBlockChain(config, nil)
+	assert.NoError(t, err)
+
+	_, ok := b.ReadCheckpointAnchor(900)
+	assert.False(t, ok)
+
+	anchor := types.StringToHash("900")
+	assert.NoError(t, b.WriteCheckpointAnchor(900, anchor))
+
+	found, ok := b.ReadCheckpointAnchor(900)
+	assert.True(t, ok)
+	assert.Equal(t, anchor, found)
+
+	// only the history before the checkpoint is anchored
+	assert.ErrorIs(t, b.WriteCheckpointAnchor(1001, anchor), ErrAboveCheckpoint)
+
+	// the anchors of another checkpoint are not read
+	config.Params.Checkpoint = &chain.Checkpoint{
+		Number: 1000,
+		Hash:   types.StringToHash("2"),
+	}
+
+	_, ok = b.ReadCheckpointAnchor(900)
+	assert.False(t, ok)
+}
+
+func TestBlockchain_VerifyFinalizedBlock_Checkpoint(t *testing.T) {
+	t.Parallel()
+
+	checkpoint := &chain.Checkpoint{
+		Number:    200,
+		Hash:      types.StringToHash("1"),
+		StateRoot: types.StringToHash("2"),
+	}
+
+	errInvalidSeal := errors.New("invalid seal")
+
+	blockchain, err := NewMockBlockchain(map[TestCallbackType]interface{}{
+		VerifierCallback: func(verifier *MockVerifier) {
+			verifier.HookVerifyHeader(func(header *types.Header) error {
+				return errInvalidSeal
+			})
+		},
+		ChainCallback: func(c *chain.Chain) {
+			c.Params.Checkpoint = checkpoint
+		},
+	})
+	if err != nil {
+		t.Fatalf("unable to instantiate new blockchain, %v", err)
+	}
+
+	t.Run("Seals around the checkpoint are verified", func(t *testing.T) {
+		t.Parallel()
+
+		for _, number := range []uint64{1, checkpoint.Number - 1, checkpoint.Number + 1} {
+			block := &types.Block{
+				Header: &types.Header{Number: number},
+			}
+
+			assert.ErrorIs(t, blockchain.VerifyFinalizedBlock(block), errInvalidSeal)
+		}
+	})
+
+	t.Run("Block at the checkpoint must match", func(t *testing.T) {
+		t.Parallel()
+
+		block := &types.Block{
+			Header: &types.Header{
+				Number:    checkpoint.Number,
+				Hash:      types.StringToHash("3"),
+				StateRoot: checkpoint.StateRoot,
+			},
+		}
+
+		assert.ErrorIs(t, blockchain.VerifyFinalizedBlock(block), ErrCheckpointMismatch)
+
+		block.Header.Hash = checkpoint.Hash
+		block.Header.StateRoot = types.StringToHash("3")
+
+		assert.ErrorIs(t, blockchain.VerifyFinalizedBlock(block), ErrCheckpointMismatch)
+
+		assert.NoError(t, blockchain.verifyCheckpoint(&types.Header{
+			Number:    checkpoint.Number,
+			Hash:      checkpoint.Hash,
+			StateRoot: checkpoint.StateRoot,
+		}))
+	})
+}
+
+func TestBlockchain_DryRunBlock(t *testing.T) {
+	t.Parallel()
+
+	b := NewTestBlockchain(t, nil)
+	b.executor.(*state.Executor).GetHash = b.GetHashHelper
+
+	genesis := b.Header()
+
+	header := &types.Header{
+		ParentHash:   genesis.Hash,
+		Number:       1,
+		GasLimit:     genesis.GasLimit,
+		Sha3Uncles:   types.EmptyUncleHash,
+		TxRoot:       types.EmptyRootHash,
+		ReceiptsRoot: types.EmptyRootHash,
+		StateRoot:    types.StringToHash("1"),
+	}
+	header.ComputeHash()
+
+	block := &types.Block{Header: header}
+
+	// the result is computed even if it does not match up
+	result, err := b.DryRunBlock(block)
+	assert.ErrorIs(t, err, ErrInvalidStateRoot)
+	assert.NotNil(t, result)
+	assert.Equal(t, genesis.StateRoot, result.Root)
+	assert.Empty(t, result.Receipts)
+
+	header.StateRoot = result.Root
+	header.ComputeHash()
+
+	result, err = b.DryRunBlock(block)
+	assert.NoError(t, err)
+	assert.Equal(t, genesis.StateRoot, result.Root)
+
+	// nothing is written
+	assert.Equal(t, genesis.Hash, b.Header().Hash)
+
+	receipts, _ := b.GetReceiptsByHash(header.Hash)
+	assert.Empty(t, receipts)
+
+	// the block is not executed without the parent
+	header.ParentHash = types.StringToHash("2")
+
+	result, err = b.DryRunBlock(block)
+	assert.ErrorIs(t, err, ErrParentNotFound)
+	assert.Nil(t, result)
+}
+
+func TestBlockchain_ReadOnly(t *testing.T) {
+	t.Parallel()
+
+	b, err := NewBlockchain(
+		hclog.NewNullLogger(),
+		&chain.Chain{
+			Genesis: &chain.Genesis{},
+			Params: &chain.Params{
+				BlockGasTarget: defaultBlockGasTarget,
+			},
+		},
+		0,
+		kvstorage.NewMemoryStorageBuilder(hclog.NewNullLogger()).SetReadOnly(true),
+		&MockVerifier{},
+		&mockExecutor{},
+		NilMetrics(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, b.ReadOnly())
+
+	// an empty storage could not be initialized
+	assert.ErrorIs(t, b.ComputeGenesis(), ErrReadOnly)
+
+	assert.ErrorIs(t, b.WriteBlock(&types.Block{Header: &types.Header{Number: 1}}, "test"), ErrReadOnly)
+	assert.ErrorIs(t, b.WriteHeadersWithBodies([]*types.Header{{Number: 1}}), ErrReadOnly)
+
+	// the background indexers are not started
+	b.EnableLogIndex()
+	b.EnableBloomIndex()
+	b.SetTxLookupLimit(1)
+
+	assert.Nil(t, b.logIndexer)
+	assert.Nil(t, b.bloomIndexer)
+	assert.Nil(t, b.txLookupUnindexer)
+}
+
+func TestBlockWriter_Async(t *testing.T) {
+	t.Parallel()
+
+	db, err := kvstorage.NewMemoryStorageBuilder(hclog.NewNullLogger()).Build()
+	assert.NoError(t, err)
+
+	writer := newBlockWriter(hclog.NewNullLogger(), db, 4)
+	assert.True(t, writer.async())
+
+	blocks := make([]*types.Block, 3)
+
+	for i := range blocks {
+		blocks[i] = &types.Block{
+			Header: &types.Header{Number: uint64(i + 1)},
+			Transactions: []*types.Transaction{
+				{Nonce: uint64(i), GasPrice: big.NewInt(1), Value: big.NewInt(0)},
+			},
+		}
+		blocks[i].Header.ComputeHash()
+
+		// the writer is not started, so the blocks stay in the queue
+		assert.NoError(t, writer.enqueue(db.NewAtomicBatch(), &blockWriteJob{
+			block:    blocks[i],
+			receipts: []*types.Receipt{{GasUsed: uint64(i)}},
+			lookups:  true,
+		}))
+	}
+
+	lowest, ok := writer.lowestPending()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), lowest)
+
+	// the queued blocks are served from memory
+	for _, block := range blocks {
+		_, err := db.ReadBody(block.Hash())
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+
+		_, ok := writer.readBody(block.Hash())
+		assert.True(t, ok)
+
+		_, ok = writer.readReceipts(block.Hash())
+		assert.True(t, ok)
+
+		hash, ok := writer.readTxLookup(block.Transactions[0].Hash())
+		assert.True(t, ok)
+		assert.Equal(t, block.Hash(), hash)
+	}
+
+	// the queued blocks are flushed on close
+	writer.start()
+	writer.close()
+
+	_, ok = writer.lowestPending()
+	assert.False(t, ok)
+
+	for _, block := range blocks {
+		_, err := db.ReadBody(block.Hash())
+		assert.NoError(t, err)
+
+		receipts, err := db.ReadReceipts(block.Hash())
+		assert.NoError(t, err)
+		assert.Len(t, receipts, 1)
+
+		hash, ok := db.ReadTxLookup(block.Transactions[0].Hash())
+		assert.True(t, ok)
+		assert.Equal(t, block.Hash(), hash)
+
+		_, ok = writer.readBody(block.Hash())
+		assert.False(t, ok)
+	}
+
+	persisted, ok := db.ReadPersistedHead()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), persisted)
+}
+
+func TestBlockchain_RepairHead(t *testing.T) {
+	t.Parallel()
+
+	headers := AppendNewTestHeaders(NewTestHeaders(1), 5)
+	b := NewTestBlockchain(t, headers)
+
+	head := b.Header()
+	assert.Equal(t, uint64(5), head.Number)
+
+	// the storage was never written by the block writer
+	repaired, err := b.repairHead(head)
+	assert.NoError(t, err)
+	assert.Equal(t, head.Hash, repaired.Hash)
+
+	// the data of the blocks up to 3 is persisted, but not of block 4
+	for _, header := range headers[1:4] {
+		assert.NoError(t, b.db.WriteBody(header.Number, header.Hash, &types.Body{}))
+		assert.NoError(t, b.db.WriteReceipts(header.Number, header.Hash, []*types.Receipt{}))
+	}
+
+	assert.NoError(t, b.db.WritePersistedHead(2))
+
+	// the body and the lookup of block 4 are flushed, but not its receipts
+	tx := &types.Transaction{Nonce: 1, GasPrice: big.NewInt(1), Value: big.NewInt(0)}
+
+	assert.NoError(t, b.db.WriteBody(headers[4].Number, headers[4].Hash, &types.Body{
+		Transactions: []*types.Transaction{tx},
+	}))
+	assert.NoError(t, b.db.WriteTxLookup(tx.Hash(), headers[4].Hash))
+
+	repaired, err = b.repairHead(head)
+	assert.NoError(t, err)
+	assert.Equal(t, headers[3].Hash, repaired.Hash)
+	assert.Equal(t, headers[3].Hash, b.Header().Hash)
+
+	hash, ok := b.db.ReadHeadHash()
+	assert.True(t, ok)
+	assert.Equal(t, headers[3].Hash, hash)
+
+	persisted, ok := b.db.ReadPersistedHead()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), persisted)
+
+	// the orphaned blocks are no longer served
+	_, ok = b.GetHeaderByNumber(4)
+	assert.False(t, ok)
+
+	assert.Len(t, b.GetHeadersInRange(1, 5), 3)
+	assert.Len(t, b.GetCanonicalHashesInRange(0, 5), 4)
+
+	_, ok = b.db.ReadTxLookup(tx.Hash())
+	assert.False(t, ok)
+}
+
+func TestBlockchain_RepairHead_Reorg(t *testing.T) {
+	t.Parallel()
+
+	headers := AppendNewTestHeaders(NewTestHeaders(1), 5)
+	b := NewTestBlockchain(t, headers)
+
+	// the data of the whole chain is persisted
+	for _, header := range headers[1:] {
+		assert.NoError(t, b.db.WriteBody(header.Number, header.Hash, &types.Body{}))
+		assert.NoError(t, b.db.WriteReceipts(header.Number, header.Hash, []*types.Receipt{}))
+	}
+
+	assert.NoError(t, b.db.WritePersistedHead(5))
+
+	// a reorg replaces the blocks from 3, the node crashes before their data is flushed
+	fork := AppendNewTestheadersWithSeed(headers[:3], 4, 1)
+	for _, header := range fork[3:] {
+		_, err := b.advanceHead(header)
+		assert.NoError(t, err)
+		assert.NoError(t, b.db.WriteHeader(header))
+	}
+
+	head := b.Header()
+	assert.Equal(t, fork[6].Hash, head.Hash)
+
+	// the blocks below the persisted head are checked too
+	repaired, err := b.repairHead(head)
+	assert.NoError(t, err)
+	assert.Equal(t, headers[2].Hash, repaired.Hash)
+	assert.Equal(t, headers[2].Hash, b.Header().Hash)
+
+	persisted, ok := b.db.ReadPersistedHead()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), persisted)
+
+	// the replaced block is flushed before the crash
+	assert.NoError(t, b.db.WriteBody(fork[3].Number, fork[3].Hash, &types.Body{}))
+	assert.NoError(t, b.db.WriteReceipts(fork[3].Number, fork[3].Hash, []*types.Receipt{}))
+
+	repaired, err = b.repairHead(head)
+	assert.NoError(t, err)
+	assert.Equal(t, fork[3].Hash, repaired.Hash)
+
+	// the orphaned fork blocks are not canonical, the replaced blocks neither
+	header, ok := b.GetHeaderByNumber(3)
+	assert.True(t, ok)
+	assert.Equal(t, fork[3].Hash, header.Hash)
+
+	_, ok = b.GetHeaderByNumber(4)
+	assert.False(t, ok)
+}
+
+func TestBlockWriter_StopOnError(t *testing.T) {
+	t.Parallel()
+
+	db, err := kvstorage.NewMemoryStorageBuilder(hclog.NewNullLogger()).Build()
+	assert.NoError(t, err)
+
+	writer := newBlockWriter(hclog.NewNullLogger(), db, 4)
+
+	errFlush := errors.New("flush failed")
+	writer.err = errFlush
+
+	block := &types.Block{Header: &types.Header{Number: 1}}
+	block.Header.ComputeHash()
+
+	// the queued blocks are kept in memory, not flushed after the error
+	writer.pending[block.Hash()] = &blockWriteJob{block: block}
+	writer.flush(writer.pending[block.Hash()])
+
+	_, err = db.ReadBody(block.Hash())
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	_, ok := writer.readBody(block.Hash())
+	assert.True(t, ok)
+
+	assert.ErrorIs(t, writer.enqueue(db.NewAtomicBatch(), &blockWriteJob{block: block}), errFlush)
+}
+
+func TestBlockchain_CheckHead(t *testing.T) {
+	t.Parallel()
+
+	headers := AppendNewTestHeaders(NewTestHeaders(1), 5)
+	b := NewTestBlockchain(t, headers)
+
+	head := b.Header()
+	assert.Equal(t, uint64(5), head.Number)
+
+	// consistent
+	checked, err := b.checkHead(head.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, head.Hash, checked.Hash)
+
+	// the head number is ahead of the head hash
+	assert.NoError(t, b.db.WriteHeadNumber(6))
+	assert.NoError(t, b.db.WriteCanonicalHash(6, types.StringToHash("6")))
+
+	checked, err = b.checkHead(head.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, head.Hash, checked.Hash)
+
+	number, ok := b.db.ReadHeadNumber()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(5), number)
+
+	_, ok = b.db.ReadCanonicalHash(6)
+	assert.False(t, ok)
+
+	// the canonical index of the head and its parent is torn
+	assert.NoError(t, b.db.DeleteCanonicalHash(4))
+
+	checked, err = b.checkHead(head.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, headers[3].Hash, checked.Hash)
+
+	hash, ok := b.db.ReadHeadHash()
+	assert.True(t, ok)
+	assert.Equal(t, headers[3].Hash, hash)
+
+	_, ok = b.db.ReadCanonicalHash(5)
+	assert.False(t, ok)
+
+	// the head header is missing
+	checked, err = b.checkHead(types.StringToHash("missing"))
+	assert.NoError(t, err)
+	assert.Equal(t, headers[3].Hash, checked.Hash)
+
+	// nothing is consistent
+	for n := uint64(0); n <= 3; n++ {
+		assert.NoError(t, b.db.DeleteCanonicalHash(n))
+	}
+
+	_, err = b.checkHead(headers[3].Hash)
+	assert.ErrorIs(t, err, ErrCorruptedChain)
+}
+
+// lostTDStorage loses the total difficulties of the hashes
+type lostTDStorage struct {
+	storage.Storage
+	lost map[types.Hash]bool
+}
+
+func (s *lostTDStorage) ReadTotalDifficulty(hash types.Hash) (*big.Int, bool) {
+	if s.lost[hash] {
+		return nil, false
+	}
+
+	return s.Storage.ReadTotalDifficulty(hash)
+}
+
+func TestBlockchain_ReconstructHeadTD(t *testing.T) {
+	t.Parallel()
+
+	headers := AppendNewTestHeaders(NewTestHeaders(1), 5)
+	b := NewTestBlockchain(t, headers)
+
+	headTD, ok := b.GetChainTD()
+	assert.True(t, ok)
+
+	// the total difficulties of the head and its parent are lost
+	b.db = &lostTDStorage{
+		Storage: b.db,
+		lost:    map[types.Hash]bool{headers[4].Hash: true, headers[5].Hash: true},
+	}
+	b.difficultyCache.Purge()
+
+	headers = AppendNewTestHeaders(headers, 1)
+	assert.NoError(t, b.WriteHeaders(headers[6:]))
+
+	td, ok := b.GetChainTD()
+	assert.True(t, ok)
+	assert.Equal(t, new(big.Int).Add(headTD, new(big.Int).SetUint64(headers[6].Difficulty)), td)
+	assert.Equal(t, headers[6].Hash, b.Header().Hash)
+
+	// no ancestor with a total difficulty, the genesis header is not written by the test chain
+	assert.NoError(t, b.db.WriteHeader(headers[0]))
+
+	for _, h := range headers {
+		b.db.(*lostTDStorage).lost[h.Hash] = true //nolint:forcetypeassert
+	}
+
+	b.difficultyCache.Purge()
+
+	headers = AppendNewTestHeaders(headers, 1)
+	assert.NoError(t, b.WriteHeaders(headers[7:]))
+
+	// rebuilt from the genesis
+	expected := big.NewInt(0)
+	for _, h := range headers {
+		expected.Add(expected, new(big.Int).SetUint64(h.Difficulty))
+	}
+
+	td, ok = b.GetChainTD()
+	assert.True(t, ok)
+	assert.Equal(t, expected, td)
+}
+
+func TestBlockchain_Halt(t *testing.T) {
+	t.Parallel()
+
+	headers := AppendNewTestHeaders(NewTestHeaders(1), 2)
+	b := NewTestBlockchain(t, headers)
+
+	status, ok, err := b.Halt("alice", "exploit")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, status.Halted)
+	assert.Equal(t, "alice", status.Operator)
+	assert.True(t, b.IsHalted())
+
+	// halted once
+	status, ok, err = b.Halt("bob", "again")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "alice", status.Operator)
+
+	block := &types.Block{Header: AppendNewTestHeaders(headers, 1)[3]}
+	assert.ErrorIs(t, b.WriteBlock(block, "test"), ErrChainHalted)
+
+	// the import waits until resumed
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, b.AwaitImportAdmission(ctx), context.DeadlineExceeded)
+
+	admittedCh := make(chan error, 1)
+
+	go func() {
+		admittedCh <- b.AwaitImportAdmission(context.Background())
+	}()
+
+	status, ok, err = b.Resume("bob", "fixed")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, status.Halted)
+	assert.Equal(t, "bob", status.Operator)
+	assert.NoError(t, <-admittedCh)
+	assert.False(t, b.IsHalted())
+
+	_, ok, err = b.Resume("bob", "fixed")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestBlockchain_Halt_Restart(t *testing.T) {
+	t.Parallel()
+
+	path := t.TempDir()
+
+	newChain := func() *Blockchain {
+		t.Helper()
+
+		logger := hclog.NewNullLogger()
+
+		b, err := NewBlockchain(
+			logger,
+			&chain.Chain{
+				Genesis: &chain.Genesis{},
+				Params: &chain.Params{
+					Forks: chain.AllForksEnabled,
+				},
+			},
+			0,
+			kvstorage.NewLevelDBStorageBuilder(logger, kvdb.NewLevelDBBuilder(logger, path)),
+			&MockVerifier{},
+			&mockExecutor{},
+			NilMetrics(),
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, b.ComputeGenesis())
+
+		return b
+	}
+
+	b := newChain()
+
+	_, ok, err := b.Halt("admin token from 127.0.0.1:1234", "exploit")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, b.Close())
+
+	// still halted after the restart, until resumed
+	b = newChain()
+
+	status := b.HaltStatus()
+	assert.True(t, status.Halted)
+	assert.Equal(t, "admin token from 127.0.0.1:1234", status.Operator)
+	assert.Equal(t, "exploit", status.Reason)
+
+	block := &types.Block{Header: &types.Header{Number: 1, ParentHash: b.Header().Hash}}
+	block.Header.ComputeHash()
+	assert.ErrorIs(t, b.WriteBlock(block, "test"), ErrChainHalted)
+
+	_, ok, err = b.Resume("admin token from 127.0.0.1:1234", "fixed")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, b.Close())
+
+	b = newChain()
+	defer b.Close()
+
+	assert.False(t, b.IsHalted())
+	assert.Equal(t, "fixed", b.HaltStatus().Reason)
+}
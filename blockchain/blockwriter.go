@@ -0,0 +1,402 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+// blockWriteJob holds the block data persisted after the block is executed
+type blockWriteJob struct {
+	block    *types.Block
+	receipts []*types.Receipt
+	lookups  bool // whether the transaction lookups are written
+}
+
+// blockWriter is the persistence stage of the block write pipeline. The bodies,
+// receipts and transaction lookups of the written blocks are queued, and flushed
+// in background, so that they are off the critical path of block production.
+// The queued blocks are served from memory until they are flushed.
+// Without a queue, the block data is written synchronously.
+type blockWriter struct {
+	logger hclog.Logger
+	db     storage.Storage
+
+	lock      sync.RWMutex
+	pending   map[types.Hash]*blockWriteJob // queued blocks, not flushed yet
+	lookups   map[types.Hash]types.Hash     // transaction lookups of the queued blocks
+	persisted uint64                        // number of the latest flushed block
+	err       error                         // the first flush error, the pipeline stops on it
+
+	queue   chan *blockWriteJob // nil if synchronous
+	closeCh chan struct{}
+	doneCh  chan struct{}
+}
+
+func newBlockWriter(logger hclog.Logger, db storage.Storage, queueSize int) *blockWriter {
+	persisted, _ := db.ReadPersistedHead()
+
+	w := &blockWriter{
+		logger:    logger.Named("blockwriter"),
+		db:        db,
+		pending:   make(map[types.Hash]*blockWriteJob),
+		lookups:   make(map[types.Hash]types.Hash),
+		persisted: persisted,
+		closeCh:   make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+
+	if queueSize > 0 {
+		w.queue = make(chan *blockWriteJob, queueSize)
+	}
+
+	return w
+}
+
+// async returns whether the block data is flushed in background
+func (w *blockWriter) async() bool {
+	return w.queue != nil
+}
+
+func (w *blockWriter) start() {
+	if !w.async() {
+		return
+	}
+
+	go w.run()
+}
+
+func (w *blockWriter) run() {
+	defer close(w.doneCh)
+
+	for {
+		select {
+		case job := <-w.queue:
+			w.flush(job)
+		case <-w.closeCh:
+			// drain the queued blocks before the storage is closed
+			for {
+				select {
+				case job := <-w.queue:
+					w.flush(job)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// close flushes the queued blocks, and stops the writer
+func (w *blockWriter) close() {
+	if !w.async() {
+		return
+	}
+
+	close(w.closeCh)
+	<-w.doneCh
+}
+
+// queued returns the number of the blocks waiting in the queue
+func (w *blockWriter) queued() int {
+	return len(w.queue)
+}
+
+// enqueue queues the block data, it blocks when the queue is full. Without a queue,
+// the block data is written into the batch of the block instead.
+// It returns the flush error which stopped the pipeline, if any.
+func (w *blockWriter) enqueue(batch storage.Batch, job *blockWriteJob) error {
+	if !w.async() {
+		return w.writeTo(batch, job)
+	}
+
+	w.lock.Lock()
+
+	if w.err != nil {
+		w.lock.Unlock()
+
+		return w.err
+	}
+
+	hash := job.block.Hash()
+	w.pending[hash] = job
+
+	if job.lookups {
+		for _, tx := range job.block.Transactions {
+			w.lookups[tx.Hash()] = hash
+		}
+	}
+
+	w.lock.Unlock()
+
+	w.queue <- job
+
+	return nil
+}
+
+// flush persists the block data, and removes it from the pending blocks. Nothing is
+// flushed after the first error, so the persisted blocks stay contiguous, the blocks
+// left are served from memory until the chain head is repaired on restart.
+func (w *blockWriter) flush(job *blockWriteJob) {
+	w.lock.RLock()
+	failed := w.err != nil
+	w.lock.RUnlock()
+
+	if failed {
+		return
+	}
+
+	err := w.write(job)
+
+	w.lock.Lock()
+
+	hash := job.block.Hash()
+	delete(w.pending, hash)
+
+	for _, tx := range job.block.Transactions {
+		if w.lookups[tx.Hash()] == hash {
+			delete(w.lookups, tx.Hash())
+		}
+	}
+
+	if err != nil {
+		w.logger.Error("failed to persist block", "number", job.block.Number(), "hash", hash, "err", err)
+
+		if w.err == nil {
+			w.err = err
+		}
+	}
+
+	w.lock.Unlock()
+}
+
+// write writes the queued block data in its own batch. The batch is not flushed
+// automatically, so that the body is never persisted without the receipts.
+func (w *blockWriter) write(job *blockWriteJob) error {
+	batch := w.db.NewAtomicBatch()
+
+	if err := w.writeTo(batch, job); err != nil {
+		return err
+	}
+
+	return batch.Write()
+}
+
+// writeTo writes the block data into the batch, the persisted head is updated at last.
+// It is the latest flushed block, which is lower than the previous one after a reorg.
+func (w *blockWriter) writeTo(batch storage.Batch, job *blockWriteJob) error {
+	if err := writeBlockData(batch, job); err != nil {
+		return err
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.persisted = job.block.Number()
+
+	return batch.WritePersistedHead(w.persisted)
+}
+
+// resetPersisted sets the persisted head, after the chain head is rewound
+func (w *blockWriter) resetPersisted(n uint64) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.persisted = n
+}
+
+// lowestPending returns the lowest number of the pending blocks
+func (w *blockWriter) lowestPending() (uint64, bool) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	var (
+		lowest uint64
+		found  bool
+	)
+
+	for _, job := range w.pending {
+		if n := job.block.Number(); !found || n < lowest {
+			lowest, found = n, true
+		}
+	}
+
+	return lowest, found
+}
+
+func (w *blockWriter) readBody(hash types.Hash) (*types.Body, bool) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	job, ok := w.pending[hash]
+	if !ok {
+		return nil, false
+	}
+
+	return job.block.Body(), true
+}
+
+func (w *blockWriter) readReceipts(hash types.Hash) ([]*types.Receipt, bool) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	job, ok := w.pending[hash]
+	if !ok {
+		return nil, false
+	}
+
+	return job.receipts, true
+}
+
+func (w *blockWriter) readTxLookup(hash types.Hash) (types.Hash, bool) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	blockHash, ok := w.lookups[hash]
+
+	return blockHash, ok
+}
+
+// persistedHeadNumber returns the number of the latest canonical block whose data
+// is persisted, with all the blocks before it
+func (b *Blockchain) persistedHeadNumber() uint64 {
+	head := b.Header().Number
+
+	if lowest, ok := b.writer.lowestPending(); ok && lowest <= head {
+		if lowest == 0 {
+			return 0
+		}
+
+		return lowest - 1
+	}
+
+	return head
+}
+
+// repairHead rewinds the chain head to the latest block whose data is persisted.
+// The queued block data is lost if the node is not stopped gracefully.
+func (b *Blockchain) repairHead(head *types.Header) (*types.Header, error) {
+	// the chain was never written by the block writer
+	if _, ok := b.db.ReadPersistedHead(); !ok || b.readOnly {
+		return head, nil
+	}
+
+	// The blocks are flushed in the order they are written, so the canonical blocks
+	// whose data is lost are on the top of the chain. It is walked back by the parent
+	// hashes, since a reorg may have replaced the blocks below the persisted head.
+	var (
+		target   = head
+		orphaned = []*types.Header{}
+	)
+
+	for target.Number > 0 && !b.blockDataPersisted(target.Hash) {
+		parent, ok := b.readHeader(target.ParentHash)
+		if !ok {
+			return nil, fmt.Errorf("failed to get header %d", target.Number-1)
+		}
+
+		orphaned = append(orphaned, target)
+		target = parent
+	}
+
+	if target.Hash == head.Hash {
+		b.writer.resetPersisted(head.Number)
+
+		return head, b.db.WritePersistedHead(head.Number)
+	}
+
+	b.logger.Warn("rewind chain head to the latest persisted block",
+		"from", head.Number,
+		"to", target.Number,
+	)
+
+	// the orphaned blocks are no longer canonical, or they are served to the peers
+	// without their data, along with the new head
+	batch := b.db.NewAtomicBatch()
+
+	for _, header := range orphaned {
+		if err := b.writeOrphanedBlock(batch, header); err != nil {
+			return nil, err
+		}
+	}
+
+	td, err := b.writeHead(batch, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := batch.WritePersistedHead(target.Number); err != nil {
+		return nil, err
+	}
+
+	if err := b.commitHead(batch, target, td); err != nil {
+		return nil, err
+	}
+
+	b.writer.resetPersisted(target.Number)
+
+	return target, nil
+}
+
+// writeOrphanedBlock removes the canonical hash of the orphaned block, and the
+// transaction lookups of it which are flushed already, into the batch
+func (b *Blockchain) writeOrphanedBlock(batch storage.Batch, header *types.Header) error {
+	if hash, ok := b.db.ReadCanonicalHash(header.Number); ok && hash == header.Hash {
+		if err := batch.DeleteCanonicalHash(header.Number); err != nil {
+			return err
+		}
+	}
+
+	// the lookups are flushed atomically with the body, none is persisted without it
+	body, err := b.db.ReadBody(header.Hash)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, tx := range body.Transactions {
+		if blockHash, ok := b.db.ReadTxLookup(tx.Hash()); ok && blockHash == header.Hash {
+			if err := batch.DeleteTxLookup(tx.Hash()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// blockDataPersisted returns whether the body and receipts of the block are persisted
+func (b *Blockchain) blockDataPersisted(hash types.Hash) bool {
+	if _, err := b.db.ReadBody(hash); err != nil {
+		return false
+	}
+
+	_, err := b.db.ReadReceipts(hash)
+
+	return err == nil
+}
+
+// writeBlockData writes the receipts, transaction lookups and body of the block into
+// the batch. The body goes last, so a persisted body means the block data is complete.
+func writeBlockData(batch storage.Batch, job *blockWriteJob) error {
+	hash := job.block.Hash()
+
+	if err := batch.WriteReceipts(job.block.Number(), hash, job.receipts); err != nil {
+		return err
+	}
+
+	if job.lookups {
+		for _, tx := range job.block.Transactions {
+			if err := batch.WriteTxLookup(tx.Hash(), hash); err != nil {
+				return err
+			}
+		}
+	}
+
+	return batch.WriteBody(job.block.Number(), hash, job.block.Body())
+}
@@ -0,0 +1,242 @@
+package blockchain
+
+import (
+	"errors"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// BloomBitsSectionSize is the number of blocks of a bloom bits section
+	BloomBitsSectionSize = 4096
+
+	// bloomBitsConfirms is the number of blocks on top of a section before it is indexed,
+	// so that the indexed sections are unlikely to be reorged
+	bloomBitsConfirms = 256
+)
+
+var errBloomBitsNotFound = errors.New("bloom bits not found")
+
+// bloomIndexer rotates the header blooms of every canonical section into one bit vector
+// per bloom bit, so that a filter only needs to read a handful of vectors to know which
+// blocks of the section might match, and skips the whole section when none does.
+//
+// The header blooms before the LogsBloom fork are not validated and might not match
+// the receipts, the blooms of those blocks are created from their receipts instead.
+type bloomIndexer struct {
+	*chainIndexer
+
+	db          storage.Storage
+	isLogsBloom func(number uint64) bool // returns whether the header bloom is validated
+}
+
+func newBloomIndexer(
+	logger hclog.Logger,
+	db storage.Storage,
+	headFn func() uint64,
+	isLogsBloom func(number uint64) bool,
+	sectionSize uint64,
+	confirms uint64,
+) *bloomIndexer {
+	bi := &bloomIndexer{db: db, isLogsBloom: isLogsBloom}
+
+	sections, _ := db.ReadBloomBitsSections()
+
+	bi.chainIndexer = newChainIndexer(logger.Named("bloombits"), headFn, bi.indexSection,
+		sections, db.WriteBloomBitsSections, sectionSize, confirms)
+
+	return bi
+}
+
+func (bi *bloomIndexer) indexSection(section uint64) error {
+	vectors := make([][]byte, types.BloomBitLength)
+	for bit := range vectors {
+		vectors[bit] = make([]byte, bi.sectionSize/8)
+	}
+
+	for i := uint64(0); i < bi.sectionSize; i++ {
+		hash, ok := bi.db.ReadCanonicalHash(section*bi.sectionSize + i)
+		if !ok {
+			return storage.ErrNotFound
+		}
+
+		header, err := bi.db.ReadHeader(hash)
+		if err != nil {
+			return err
+		}
+
+		bloom, err := bi.blockBloom(header)
+		if err != nil {
+			return err
+		}
+
+		for bit := uint(0); bit < types.BloomBitLength; bit++ {
+			if bloom.IsBitSet(bit) {
+				vectors[bit][i/8] |= 1 << (7 - i%8)
+			}
+		}
+	}
+
+	for bit, vector := range vectors {
+		if isZeroBytes(vector) {
+			// most bits are never set in quiet sections, do not waste space
+			vector = []byte{}
+		}
+
+		if err := bi.db.WriteBloomBits(uint(bit), section, vector); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// blockBloom returns the bloom of the block logs
+func (bi *bloomIndexer) blockBloom(header *types.Header) (types.Bloom, error) {
+	if bi.isLogsBloom(header.Number) {
+		return header.LogsBloom, nil
+	}
+
+	receipts, err := bi.db.ReadReceipts(header.Hash)
+	if errors.Is(err, storage.ErrNotFound) {
+		// header only block, no logs
+		return types.Bloom{}, nil
+	} else if err != nil {
+		return types.Bloom{}, err
+	}
+
+	return types.CreateBloom(receipts), nil
+}
+
+// filterBlocks returns the block numbers within [from, to] whose blooms might contain
+// any of the addresses, and any topic of every topic set. Empty sets are skipped,
+// so at least one criterion is required. The range must be indexed.
+func (bi *bloomIndexer) filterBlocks(addresses []types.Address, topics [][]types.Hash, from, to uint64) []uint64 {
+	groups := make([][][3]uint, 0, len(topics)+1)
+
+	if len(addresses) > 0 {
+		group := make([][3]uint, len(addresses))
+		for i, addr := range addresses {
+			group[i] = types.BloomBitIndexes(addr.Bytes())
+		}
+
+		groups = append(groups, group)
+	}
+
+	for _, set := range topics {
+		if len(set) == 0 {
+			continue
+		}
+
+		group := make([][3]uint, len(set))
+		for i, topic := range set {
+			group[i] = types.BloomBitIndexes(topic.Bytes())
+		}
+
+		groups = append(groups, group)
+	}
+
+	numbers := []uint64{}
+
+	for section := from / bi.sectionSize; section <= to/bi.sectionSize; section++ {
+		match, err := bi.matchSection(section, groups)
+		if err != nil {
+			// the section could not be filtered, check all of its blocks
+			bi.logger.Warn("failed to match bloom bits", "section", section, "err", err)
+		}
+
+		start := section * bi.sectionSize
+
+		for i := uint64(0); i < bi.sectionSize; i++ {
+			if n := start + i; n < from || n > to {
+				continue
+			}
+
+			if match == nil || match[i/8]&(1<<(7-i%8)) != 0 {
+				numbers = append(numbers, start+i)
+			}
+		}
+	}
+
+	return numbers
+}
+
+// matchSection returns the bit vector of the blocks of the section which might match
+func (bi *bloomIndexer) matchSection(section uint64, groups [][][3]uint) ([]byte, error) {
+	cache := map[uint][]byte{}
+
+	readVector := func(bit uint) ([]byte, error) {
+		if vector, ok := cache[bit]; ok {
+			return vector, nil
+		}
+
+		vector, ok := bi.db.ReadBloomBits(bit, section)
+		if !ok {
+			return nil, errBloomBitsNotFound
+		}
+
+		if len(vector) == 0 {
+			vector = make([]byte, bi.sectionSize/8)
+		}
+
+		cache[bit] = vector
+
+		return vector, nil
+	}
+
+	var match []byte // nil means all blocks
+
+	for _, group := range groups {
+		groupMatch := make([]byte, bi.sectionSize/8)
+
+		for _, indexes := range group {
+			// the value might be present only if all of its bits are set
+			valueMatch := make([]byte, bi.sectionSize/8)
+			for i := range valueMatch {
+				valueMatch[i] = 0xff
+			}
+
+			for _, bit := range indexes {
+				vector, err := readVector(bit)
+				if err != nil {
+					return nil, err
+				}
+
+				for i := range valueMatch {
+					valueMatch[i] &= vector[i]
+				}
+			}
+
+			for i := range groupMatch {
+				groupMatch[i] |= valueMatch[i]
+			}
+		}
+
+		if match == nil {
+			match = groupMatch
+		} else {
+			for i := range match {
+				match[i] &= groupMatch[i]
+			}
+		}
+
+		// no block of the section could match
+		if isZeroBytes(match) {
+			break
+		}
+	}
+
+	return match, nil
+}
+
+func isZeroBytes(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,169 @@
+package blockchain
+
+import (
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// chainIndexer indexes the canonical chain in background, one section of blocks at a
+// time. It catches up from the last indexed section on start, and follows the chain
+// head afterwards. The per-block indexers have sections of a single block.
+type chainIndexer struct {
+	logger       hclog.Logger
+	headFn       func() uint64               // returns the current chain head number
+	indexSection func(section uint64) error  // indexes the blocks of the section
+	writeHead    func(sections uint64) error // persists the number of the indexed sections
+	sectionSize  uint64
+	confirms     uint64 // number of blocks on top of a section before it is indexed
+
+	lock     sync.RWMutex
+	sections uint64 // number of indexed sections
+	version  uint64 // increased on every rewind
+
+	notifyCh chan struct{}
+	closeCh  chan struct{}
+	doneCh   chan struct{}
+}
+
+func newChainIndexer(
+	logger hclog.Logger,
+	headFn func() uint64,
+	indexSection func(section uint64) error,
+	sections uint64,
+	writeHead func(sections uint64) error,
+	sectionSize uint64,
+	confirms uint64,
+) *chainIndexer {
+	return &chainIndexer{
+		logger:       logger,
+		headFn:       headFn,
+		indexSection: indexSection,
+		writeHead:    writeHead,
+		sectionSize:  sectionSize,
+		confirms:     confirms,
+		sections:     sections,
+		notifyCh:     make(chan struct{}, 1),
+		closeCh:      make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}
+}
+
+// newBlockIndexer returns the indexer of the canonical blocks one by one. The head is
+// the number of the last indexed block, the genesis is taken as indexed when there is
+// none, it has nothing to index anyway.
+func newBlockIndexer(
+	logger hclog.Logger,
+	headFn func() uint64,
+	indexBlock func(n uint64) error,
+	readHead func() (uint64, bool),
+	writeHead func(n uint64) error,
+) *chainIndexer {
+	head, _ := readHead()
+
+	return newChainIndexer(logger, headFn, indexBlock, head+1, func(sections uint64) error {
+		if sections == 0 {
+			return writeHead(0)
+		}
+
+		return writeHead(sections - 1)
+	}, 1, 0)
+}
+
+func (c *chainIndexer) start() {
+	go c.run()
+}
+
+func (c *chainIndexer) run() {
+	defer close(c.doneCh)
+
+	// catch up with the chain head first
+	c.index()
+
+	for {
+		select {
+		case <-c.closeCh:
+			return
+		case <-c.notifyCh:
+			c.index()
+		}
+	}
+}
+
+// notify wakes up the indexer once the canonical chain is updated from block number n,
+// the indexer drops the sections covering the updated blocks
+func (c *chainIndexer) notify(n uint64) {
+	c.lock.Lock()
+
+	if n < c.sections*c.sectionSize {
+		c.sections = n / c.sectionSize
+		c.version++
+
+		if err := c.writeHead(c.sections); err != nil {
+			c.logger.Error("failed to write index head", "sections", c.sections, "err", err)
+		}
+	}
+
+	c.lock.Unlock()
+
+	select {
+	case c.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// indexedSections returns the number of the indexed sections
+func (c *chainIndexer) indexedSections() uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.sections
+}
+
+// indexedBlocks returns the number of the indexed blocks from the genesis
+func (c *chainIndexer) indexedBlocks() uint64 {
+	return c.indexedSections() * c.sectionSize
+}
+
+func (c *chainIndexer) close() {
+	close(c.closeCh)
+	<-c.doneCh
+}
+
+// index indexes the confirmed sections up to the chain head
+func (c *chainIndexer) index() {
+	for {
+		select {
+		case <-c.closeCh:
+			return
+		default:
+		}
+
+		c.lock.RLock()
+		next, version := c.sections, c.version
+		c.lock.RUnlock()
+
+		if (next+1)*c.sectionSize+c.confirms > c.headFn()+1 {
+			return
+		}
+
+		if err := c.indexSection(next); err != nil {
+			c.logger.Error("failed to index section", "section", next, "err", err)
+
+			return
+		}
+
+		c.lock.Lock()
+
+		// skip if the indexer rewound in the meantime
+		if c.version == version {
+			c.sections = next + 1
+
+			if err := c.writeHead(c.sections); err != nil {
+				c.logger.Error("failed to write index head", "sections", c.sections, "err", err)
+			}
+		}
+
+		c.lock.Unlock()
+	}
+}
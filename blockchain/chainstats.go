@@ -0,0 +1,196 @@
+package blockchain
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// ChainStatsHour is the interval of the hourly aggregated chain usage, in seconds
+	ChainStatsHour = 3600
+	// ChainStatsDay is the interval of the daily aggregated chain usage, in seconds
+	ChainStatsDay = 24 * ChainStatsHour
+
+	// maxChainStatsHours is the max hours read by a chain usage query
+	maxChainStatsHours = 366 * 24
+)
+
+var (
+	ErrChainStatsDisabled      = errors.New("chain stats aggregation is disabled")
+	ErrInvalidChainStatsPeriod = errors.New("chain stats interval should be a multiple of an hour")
+	ErrChainStatsRangeTooLarge = errors.New("chain stats range is too large")
+)
+
+// ChainStats is the aggregated usage of the canonical blocks within an interval
+type ChainStats struct {
+	Time        uint64   // start timestamp of the interval
+	Blocks      uint64   // number of the blocks
+	TxCount     uint64   // number of the transactions
+	GasUsed     uint64   // total gas used
+	GasLimit    uint64   // total gas limit
+	GasPriceSum *big.Int // sum of the transaction gas prices
+}
+
+// GasUsedRatio returns the ratio of the gas used to the gas limit of the blocks
+func (s *ChainStats) GasUsedRatio() float64 {
+	if s.GasLimit == 0 {
+		return 0
+	}
+
+	return float64(s.GasUsed) / float64(s.GasLimit)
+}
+
+// AvgGasPrice returns the average gas price of the transactions
+func (s *ChainStats) AvgGasPrice() *big.Int {
+	if s.TxCount == 0 {
+		return new(big.Int)
+	}
+
+	return new(big.Int).Div(s.GasPriceSum, new(big.Int).SetUint64(s.TxCount))
+}
+
+// chainStatsIndexer aggregates the usage of the canonical blocks into hourly stats
+// in background
+type chainStatsIndexer struct {
+	*chainIndexer
+
+	db storage.Storage
+}
+
+func newChainStatsIndexer(logger hclog.Logger, db storage.Storage, headFn func() uint64) *chainStatsIndexer {
+	c := &chainStatsIndexer{db: db}
+
+	// start from genesis if not aggregated before, it has no transactions anyway
+	c.chainIndexer = newBlockIndexer(logger.Named("chainstats"), headFn, c.indexBlock,
+		db.ReadChainStatsHead, db.WriteChainStatsHead)
+
+	return c
+}
+
+func (c *chainStatsIndexer) indexBlock(n uint64) error {
+	header, body, err := c.readBlock(n)
+	if err != nil {
+		return err
+	}
+
+	hour := header.Timestamp / ChainStatsHour
+
+	stats, ok := c.db.ReadChainStats(hour)
+
+	switch {
+	case !ok || stats.FirstBlock >= n:
+		// a new hour, or a stale one of a reorged chain
+		stats = &storage.ChainStats{FirstBlock: n, GasPriceSum: new(big.Int)}
+	case stats.LastBlock >= n:
+		// the hour has blocks of a reorged chain, aggregate its canonical blocks again
+		stats = &storage.ChainStats{FirstBlock: stats.FirstBlock, GasPriceSum: new(big.Int)}
+
+		for m := stats.FirstBlock; m < n; m++ {
+			header, body, err := c.readBlock(m)
+			if err != nil {
+				return err
+			}
+
+			addChainStats(stats, header, body)
+		}
+	}
+
+	addChainStats(stats, header, body)
+
+	return c.db.WriteChainStats(hour, stats)
+}
+
+func (c *chainStatsIndexer) readBlock(n uint64) (*types.Header, *types.Body, error) {
+	hash, ok := c.db.ReadCanonicalHash(n)
+	if !ok {
+		return nil, nil, storage.ErrNotFound
+	}
+
+	header, err := c.db.ReadHeader(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := c.db.ReadBody(hash)
+	if errors.Is(err, storage.ErrNotFound) {
+		// header only block, no transactions
+		return header, &types.Body{}, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	return header, body, nil
+}
+
+func addChainStats(stats *storage.ChainStats, header *types.Header, body *types.Body) {
+	stats.LastBlock = header.Number
+	stats.Blocks++
+	stats.TxCount += uint64(len(body.Transactions))
+	stats.GasUsed += header.GasUsed
+	stats.GasLimit += header.GasLimit
+
+	for _, tx := range body.Transactions {
+		if tx.GasPrice != nil {
+			stats.GasPriceSum.Add(stats.GasPriceSum, tx.GasPrice)
+		}
+	}
+}
+
+// readStats returns the usage of the canonical blocks with timestamps within [from, to],
+// aggregated by the interval. The intervals without blocks are skipped.
+func (c *chainStatsIndexer) readStats(from, to, interval uint64) ([]*ChainStats, error) {
+	if interval == 0 || interval%ChainStatsHour != 0 {
+		return nil, ErrInvalidChainStatsPeriod
+	}
+
+	result := []*ChainStats{}
+
+	if from > to {
+		return result, nil
+	}
+
+	fromHour, toHour := from/ChainStatsHour, to/ChainStatsHour
+	if toHour-fromHour >= maxChainStatsHours {
+		return nil, ErrChainStatsRangeTooLarge
+	}
+
+	var current *ChainStats
+
+	for hour := fromHour; hour >= fromHour && hour <= toHour; hour++ {
+		stats, ok := c.db.ReadChainStats(hour)
+		if !ok {
+			continue
+		}
+
+		start := hour * ChainStatsHour / interval * interval
+
+		if current == nil || current.Time != start {
+			current = &ChainStats{Time: start, GasPriceSum: new(big.Int)}
+			result = append(result, current)
+		}
+
+		current.Blocks += stats.Blocks
+		current.TxCount += stats.TxCount
+		current.GasUsed += stats.GasUsed
+		current.GasLimit += stats.GasLimit
+		current.GasPriceSum.Add(current.GasPriceSum, stats.GasPriceSum)
+	}
+
+	return result, nil
+}
+
+// GetChainStats returns the usage of the canonical blocks with timestamps within
+// [from, to], aggregated by the interval in seconds, which is a multiple of an hour.
+// The intervals are aligned to the unix epoch, and the ones without blocks are skipped.
+// The first and last intervals cover the whole hours of the range bounds.
+func (b *Blockchain) GetChainStats(from, to, interval uint64) ([]*ChainStats, error) {
+	if b.chainStatsIndexer == nil {
+		return nil, ErrChainStatsDisabled
+	}
+
+	return b.chainStatsIndexer.readStats(from, to, interval)
+}
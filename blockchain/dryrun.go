@@ -0,0 +1,57 @@
+package blockchain
+
+import (
+	"errors"
+
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// DryRunBlock verifies the sealed block the way it is imported, and executes it on
+// the state of its parent, but nothing is written to the chain or the state. The
+// result is nil if the block could not be executed, otherwise it is returned along
+// with the verification error of the execution result, if any.
+func (b *Blockchain) DryRunBlock(block *types.Block) (*BlockResult, error) {
+	if b.isStopped() {
+		return nil, ErrClosed
+	}
+
+	b.wg.Add(1)
+	defer b.wg.Done()
+
+	if block == nil {
+		return nil, ErrNoBlock
+	}
+
+	if block.Header == nil {
+		return nil, ErrNoBlockHeader
+	}
+
+	// Make sure the block is on the chain of the trusted checkpoint
+	if err := b.verifyCheckpoint(block.Header); err != nil {
+		return nil, err
+	}
+
+	// Make sure the consensus layer verifies this block header
+	if err := b.consensus.VerifyHeader(block.Header); err != nil {
+		return nil, newVerifyError(VerifyStageSeal, block.Header, err)
+	}
+
+	if err := b.verifyBlockParent(block); err != nil {
+		return nil, err
+	}
+
+	if err := b.verifyBlockRoots(block); err != nil {
+		return nil, err
+	}
+
+	result, err := b.executeBlock(block, false)
+	if err != nil {
+		if errors.Is(err, ErrClosed) {
+			return nil, err
+		}
+
+		return nil, newVerifyError(VerifyStageExecution, block.Header, err)
+	}
+
+	return result, result.verifyBlockResult(block, b.Config().Forks.IsLogsBloom(block.Number()))
+}
@@ -0,0 +1,135 @@
+package blockchain
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/state"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// executionStatsSize is the number of the recent verified blocks whose execution stats are kept
+const executionStatsSize = 1024
+
+// BlockExecutionStats is the execution statistics of a verified block, either a
+// proposal or a block to import
+type BlockExecutionStats struct {
+	Number        uint64
+	Hash          types.Hash
+	ExecutionTime time.Duration
+	TxCount       int
+	SystemTxCount int
+	GasUsed       uint64
+	StateAccess   state.AccessStats
+	Imported      bool // whether the block is written to the chain
+}
+
+// executionStatsKey tells apart the blocks of the same number, like the proposals
+// of the rounds
+type executionStatsKey struct {
+	number uint64
+	hash   types.Hash
+}
+
+// executionStatsRing keeps the execution stats of the recent verified blocks
+type executionStatsRing struct {
+	lock  sync.RWMutex
+	stats []*BlockExecutionStats // ring buffer, the oldest one is overwritten
+	next  int                    // index of the next write
+}
+
+func newExecutionStatsRing(size int) *executionStatsRing {
+	return &executionStatsRing{
+		stats: make([]*BlockExecutionStats, size),
+	}
+}
+
+func (r *executionStatsRing) add(stats *BlockExecutionStats) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.stats[r.next] = stats
+	r.next = (r.next + 1) % len(r.stats)
+}
+
+// markImported marks the stats of the block written to the chain
+func (r *executionStatsRing) markImported(number uint64, hash types.Hash) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, stats := range r.stats {
+		if stats != nil && stats.Number == number && stats.Hash == hash {
+			stats.Imported = true
+		}
+	}
+}
+
+// rangeOf returns the copies of the stats of the blocks from number 'from' to 'to'
+// (inclusive), sorted by number with the imported block first. The block executed
+// at last wins if it is executed more than once.
+func (r *executionStatsRing) rangeOf(from, to uint64) []*BlockExecutionStats {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	found := make(map[executionStatsKey]*BlockExecutionStats)
+
+	// from the newest to the oldest
+	for i := 1; i <= len(r.stats); i++ {
+		stats := r.stats[(r.next-i+len(r.stats))%len(r.stats)]
+		if stats == nil {
+			break
+		}
+
+		if stats.Number < from || stats.Number > to {
+			continue
+		}
+
+		key := executionStatsKey{number: stats.Number, hash: stats.Hash}
+		if _, ok := found[key]; !ok {
+			copied := *stats
+			found[key] = &copied
+		}
+	}
+
+	result := make([]*BlockExecutionStats, 0, len(found))
+	for _, stats := range found {
+		result = append(result, stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Number != result[j].Number {
+			return result[i].Number < result[j].Number
+		}
+
+		if result[i].Imported != result[j].Imported {
+			return result[i].Imported
+		}
+
+		return bytes.Compare(result[i].Hash.Bytes(), result[j].Hash.Bytes()) < 0
+	})
+
+	return result
+}
+
+// recordExecutionStats keeps the execution stats of the verified block, marked once
+// it is imported
+func (b *Blockchain) recordExecutionStats(block *types.Block, result *BlockResult) {
+	b.executionStats.add(&BlockExecutionStats{
+		Number:        block.Number(),
+		Hash:          block.Hash(),
+		ExecutionTime: result.ExecutionTime,
+		TxCount:       len(block.Transactions),
+		SystemTxCount: result.SystemTxCount,
+		GasUsed:       result.TotalGas,
+		StateAccess:   result.AccessStats,
+	})
+}
+
+// GetBlockExecutionStats returns the execution stats of the recent verified blocks
+// from number 'from' to 'to' (inclusive), including the proposals never imported.
+// The blocks not executed by the node, or executed too long ago, are missing.
+func (b *Blockchain) GetBlockExecutionStats(from, to uint64) []*BlockExecutionStats {
+	return b.executionStats.rangeOf(from, to)
+}
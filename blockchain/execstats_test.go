@@ -0,0 +1,65 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutionStatsRing(t *testing.T) {
+	ring := newExecutionStatsRing(4)
+
+	assert.Empty(t, ring.rangeOf(0, 10))
+
+	for n := uint64(1); n <= 5; n++ {
+		ring.add(&BlockExecutionStats{Number: n})
+	}
+
+	// the oldest one is overwritten
+	numbers := func(list []*BlockExecutionStats) []uint64 {
+		res := make([]uint64, len(list))
+		for i, stats := range list {
+			res[i] = stats.Number
+		}
+
+		return res
+	}
+
+	assert.Equal(t, []uint64{2, 3, 4, 5}, numbers(ring.rangeOf(0, 10)))
+	assert.Equal(t, []uint64{3, 4}, numbers(ring.rangeOf(3, 4)))
+
+	// the block executed at last wins
+	again := &BlockExecutionStats{Number: 4, ExecutionTime: 1}
+	ring.add(again)
+
+	list := ring.rangeOf(4, 4)
+	assert.Len(t, list, 1)
+	assert.Equal(t, again, list[0])
+}
+
+func TestExecutionStatsRing_Proposals(t *testing.T) {
+	ring := newExecutionStatsRing(8)
+
+	var (
+		proposal = types.StringToHash("1")
+		imported = types.StringToHash("2")
+	)
+
+	// the proposals of two rounds are verified, the second one is imported
+	ring.add(&BlockExecutionStats{Number: 1, Hash: proposal})
+	ring.add(&BlockExecutionStats{Number: 1, Hash: imported})
+	ring.markImported(1, imported)
+
+	list := ring.rangeOf(1, 1)
+	assert.Len(t, list, 2)
+
+	assert.Equal(t, imported, list[0].Hash)
+	assert.True(t, list[0].Imported)
+	assert.Equal(t, proposal, list[1].Hash)
+	assert.False(t, list[1].Imported)
+
+	// the copies are returned
+	list[1].Imported = true
+	assert.False(t, ring.rangeOf(1, 1)[1].Imported)
+}
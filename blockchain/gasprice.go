@@ -0,0 +1,232 @@
+package blockchain
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+const (
+	// DefaultGasPriceHistorySize is the default number of recent blocks sampled for gas prices
+	DefaultGasPriceHistorySize = 30
+
+	// gasPriceSampleNumber is the number of the lowest gas prices sampled in a block
+	gasPriceSampleNumber = 3
+)
+
+// blockGasPrices holds the gas price information of a block
+type blockGasPrices struct {
+	number   uint64
+	prices   []*big.Int // gas prices of the non-miner transactions, ascending
+	gasRatio uint64     // gas used percentage of the block
+}
+
+// gasPriceHistory is a ring buffer of the gas prices of the last N canonical blocks
+type gasPriceHistory struct {
+	lock   sync.RWMutex
+	blocks []*blockGasPrices
+	next   int // index of the next write
+	count  int // number of the filled slots
+
+	ignoreUnder *big.Int // prices below it are not sampled, nil for none
+}
+
+func newGasPriceHistory(size int) *gasPriceHistory {
+	if size < 1 {
+		size = DefaultGasPriceHistorySize
+	}
+
+	return &gasPriceHistory{
+		blocks: make([]*blockGasPrices, size),
+	}
+}
+
+// push adds the block to the history. The blocks with the same or higher number are
+// dropped first, since they are reorged out.
+func (h *gasPriceHistory) push(block *blockGasPrices) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for h.count > 0 {
+		last := (h.next - 1 + len(h.blocks)) % len(h.blocks)
+		if h.blocks[last].number < block.number {
+			break
+		}
+
+		h.blocks[last] = nil
+		h.next = last
+		h.count--
+	}
+
+	h.blocks[h.next] = block
+	h.next = (h.next + 1) % len(h.blocks)
+
+	if h.count < len(h.blocks) {
+		h.count++
+	}
+}
+
+// latest returns the latest block, nil if the history is empty
+func (h *gasPriceHistory) latest() *blockGasPrices {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if h.count == 0 {
+		return nil
+	}
+
+	return h.blocks[(h.next-1+len(h.blocks))%len(h.blocks)]
+}
+
+// each iterates the blocks from the newest to the oldest
+func (h *gasPriceHistory) each(fn func(block *blockGasPrices)) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	for i := 1; i <= h.count; i++ {
+		fn(h.blocks[(h.next-i+len(h.blocks))%len(h.blocks)])
+	}
+}
+
+// percentile returns the percentile of the lowest prices of every block, the blocks
+// without sampled prices use the fallback price. nil if no price is sampled.
+func (h *gasPriceHistory) percentile(percentile int, fallback *big.Int) *big.Int {
+	samples := make([]*big.Int, 0, h.size()*gasPriceSampleNumber)
+
+	h.each(func(block *blockGasPrices) {
+		sampled := 0
+
+		for _, price := range block.prices {
+			if h.ignoreUnder != nil && price.Cmp(h.ignoreUnder) < 0 {
+				continue
+			}
+
+			samples = append(samples, price)
+
+			if sampled++; sampled >= gasPriceSampleNumber {
+				break
+			}
+		}
+
+		if sampled == 0 && fallback != nil {
+			samples = append(samples, fallback)
+		}
+	})
+
+	if len(samples) == 0 {
+		return nil
+	}
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Cmp(samples[j]) < 0
+	})
+
+	return new(big.Int).Set(samples[(len(samples)-1)*percentile/100])
+}
+
+func (h *gasPriceHistory) size() int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return h.count
+}
+
+// SetGasPriceHistory sets the number of recent blocks sampled for gas prices, and the
+// price below which transactions are not sampled. It should be called before the
+// genesis is computed.
+func (b *Blockchain) SetGasPriceHistory(blocks int, ignoreUnder *big.Int) {
+	b.gpHistory = newGasPriceHistory(blocks)
+	b.gpHistory.ignoreUnder = ignoreUnder
+}
+
+// SuggestedPrice returns the percentile of the lowest gas prices sampled from each of
+// the recent blocks, the blocks without sampled prices count as the price bottom limit
+// if it is set. It returns nil if no price is sampled.
+func (b *Blockchain) SuggestedPrice(percentile int) *big.Int {
+	if percentile < 0 {
+		percentile = 0
+	} else if percentile > 100 {
+		percentile = 100
+	}
+
+	var fallback *big.Int
+	if b.priceBottomLimit > 0 {
+		fallback = new(big.Int).SetUint64(b.priceBottomLimit)
+	}
+
+	return b.gpHistory.percentile(percentile, fallback)
+}
+
+// GasUsedRatios returns the gas used percentages of the recent blocks, the newest first
+func (b *Blockchain) GasUsedRatios() []uint64 {
+	ratios := make([]uint64, 0, b.gpHistory.size())
+
+	b.gpHistory.each(func(block *blockGasPrices) {
+		ratios = append(ratios, block.gasRatio)
+	})
+
+	return ratios
+}
+
+// loadGasPriceHistory samples the recent canonical blocks, so that the suggested price
+// is available right after start
+func (b *Blockchain) loadGasPriceHistory() {
+	head := b.Header()
+	if head == nil {
+		return
+	}
+
+	size := uint64(len(b.gpHistory.blocks))
+
+	from := uint64(1)
+	if head.Number > size {
+		from = head.Number - size + 1
+	}
+
+	for n := from; n <= head.Number; n++ {
+		block, ok := b.GetBlockByNumber(n, true)
+		if !ok {
+			continue
+		}
+
+		b.updateGasPriceHistory(block)
+	}
+}
+
+// updateGasPriceHistory extracts the gas price information from the block,
+// and adds it to the gas price history
+func (b *Blockchain) updateGasPriceHistory(block *types.Block) {
+	header := block.Header
+	prices := make([]*big.Int, 0, len(block.Transactions))
+
+	if len(block.Transactions) > 0 {
+		signer := crypto.NewSigner(b.ForksInTime(header.Number), b.ChainID())
+
+		for _, tx := range block.Transactions {
+			// Ignore transactions from miner, since they will always be included
+			if from, err := signer.Sender(tx); err != nil || from == header.Miner {
+				continue
+			}
+
+			prices = append(prices, tx.GasPrice)
+		}
+
+		sort.Slice(prices, func(i, j int) bool {
+			return prices[i].Cmp(prices[j]) < 0
+		})
+	}
+
+	var gasRatio uint64
+	if header.GasLimit > 0 {
+		gasRatio = header.GasUsed * 100 / header.GasLimit
+	}
+
+	b.gpHistory.push(&blockGasPrices{
+		number:   header.Number,
+		prices:   prices,
+		gasRatio: gasRatio,
+	})
+}
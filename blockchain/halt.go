@@ -0,0 +1,190 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+)
+
+var ErrChainHalted = errors.New("chain is halted")
+
+// HaltStatus is the last change of the emergency halt of the chain
+type HaltStatus struct {
+	Halted   bool
+	Operator string    // the authenticated caller changing the halt state
+	Reason   string    // why the halt state is changed
+	Since    time.Time // when the halt state is changed
+}
+
+// chainHalt keeps the emergency halt state, the block writes are refused while halted.
+// The state is persisted, so that the chain stays halted across restarts.
+type chainHalt struct {
+	lock     sync.RWMutex
+	status   HaltStatus
+	resumeCh chan struct{} // closed once resumed, nil if not halted
+}
+
+// Halt stops the block imports and sealing of the chain at once, for coordinated
+// emergency halts. The chain data is served still. The block being written is
+// finished first, none is written once it returns. The halt is persisted, and kept
+// across restarts until resumed. It returns the halt in effect, and whether it is
+// a new one.
+func (b *Blockchain) Halt(operator, reason string) (HaltStatus, bool, error) {
+	b.writeLock.Lock()
+	defer b.writeLock.Unlock()
+
+	b.halt.lock.Lock()
+	defer b.halt.lock.Unlock()
+
+	if b.halt.status.Halted {
+		return b.halt.status, false, nil
+	}
+
+	status := HaltStatus{
+		Halted:   true,
+		Operator: operator,
+		Reason:   reason,
+		Since:    time.Now(),
+	}
+
+	if err := b.writeHaltStatus(status); err != nil {
+		return b.halt.status, false, err
+	}
+
+	b.halt.status = status
+	b.halt.resumeCh = make(chan struct{})
+
+	b.logger.Warn("chain halted",
+		"operator", operator,
+		"reason", reason,
+		"head", b.Header().Number,
+	)
+
+	return b.halt.status, true, nil
+}
+
+// Resume resumes the block imports and sealing of the halted chain. It returns the
+// halt state in effect, and whether the chain is resumed by the call.
+func (b *Blockchain) Resume(operator, reason string) (HaltStatus, bool, error) {
+	b.halt.lock.Lock()
+	defer b.halt.lock.Unlock()
+
+	if !b.halt.status.Halted {
+		return b.halt.status, false, nil
+	}
+
+	halted := b.halt.status
+
+	status := HaltStatus{
+		Halted:   false,
+		Operator: operator,
+		Reason:   reason,
+		Since:    time.Now(),
+	}
+
+	if err := b.writeHaltStatus(status); err != nil {
+		return b.halt.status, false, err
+	}
+
+	b.halt.status = status
+
+	close(b.halt.resumeCh)
+	b.halt.resumeCh = nil
+
+	b.logger.Warn("chain resumed",
+		"operator", operator,
+		"reason", reason,
+		"halted_by", halted.Operator,
+		"halted_for", time.Since(halted.Since),
+	)
+
+	return b.halt.status, true, nil
+}
+
+// writeHaltStatus persists the halt state, so that it is kept across restarts
+func (b *Blockchain) writeHaltStatus(status HaltStatus) error {
+	return b.db.WriteHaltState(&storage.HaltState{
+		Halted:   status.Halted,
+		Operator: status.Operator,
+		Reason:   status.Reason,
+		Since:    uint64(status.Since.Unix()),
+	})
+}
+
+// loadHaltStatus restores the persisted halt state, the chain halted before the
+// restart refuses the block imports and sealing until resumed
+func (b *Blockchain) loadHaltStatus() {
+	state, ok := b.db.ReadHaltState()
+	if !ok {
+		return
+	}
+
+	b.halt.lock.Lock()
+	defer b.halt.lock.Unlock()
+
+	b.halt.status = HaltStatus{
+		Halted:   state.Halted,
+		Operator: state.Operator,
+		Reason:   state.Reason,
+		Since:    time.Unix(int64(state.Since), 0),
+	}
+
+	if state.Halted {
+		b.halt.resumeCh = make(chan struct{})
+
+		b.logger.Warn("chain is halted, resume it to import and seal the blocks",
+			"operator", state.Operator,
+			"reason", state.Reason,
+			"since", b.halt.status.Since.UTC(),
+		)
+	}
+}
+
+// HaltStatus returns the last change of the halt state
+func (b *Blockchain) HaltStatus() HaltStatus {
+	b.halt.lock.RLock()
+	defer b.halt.lock.RUnlock()
+
+	return b.halt.status
+}
+
+// IsHalted returns whether the chain is halted
+func (b *Blockchain) IsHalted() bool {
+	b.halt.lock.RLock()
+	defer b.halt.lock.RUnlock()
+
+	return b.halt.status.Halted
+}
+
+// awaitResume blocks while the chain is halted. It returns the context error if
+// canceled, or ErrClosed if the chain is closed.
+func (b *Blockchain) awaitResume(ctx context.Context) error {
+	b.halt.lock.RLock()
+	resumeCh := b.halt.resumeCh
+	b.halt.lock.RUnlock()
+
+	if resumeCh == nil {
+		return nil
+	}
+
+	b.logger.Info("delay block import while the chain is halted")
+
+	ticker := time.NewTicker(admissionRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-resumeCh:
+			return nil
+		case <-ticker.C:
+			if b.isStopped() {
+				return ErrClosed
+			}
+		}
+	}
+}
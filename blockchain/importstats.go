@@ -0,0 +1,66 @@
+package blockchain
+
+import (
+	"time"
+)
+
+// importStatsWindow is the minimum duration over which the import throughput is measured
+const importStatsWindow = 10 * time.Second
+
+// importRates holds the import throughput of a measurement window
+type importRates struct {
+	blocks float64 // blocks per second
+	txs    float64 // transactions per second
+	mgas   float64 // million gas per second
+}
+
+// importStats accumulates the written blocks, and measures the import throughput
+// once the window elapses. The first block only opens the window.
+type importStats struct {
+	start  time.Time
+	blocks uint64
+	txs    uint64
+	gas    uint64
+}
+
+// add adds the block to the window. It returns the rates and resets the window
+// when the window has elapsed.
+func (s *importStats) add(now time.Time, txs int, gas uint64) (importRates, bool) {
+	if s.start.IsZero() {
+		s.start = now
+
+		return importRates{}, false
+	}
+
+	s.blocks++
+	s.txs += uint64(txs)
+	s.gas += gas
+
+	elapsed := now.Sub(s.start)
+	if elapsed < importStatsWindow {
+		return importRates{}, false
+	}
+
+	seconds := elapsed.Seconds()
+	rates := importRates{
+		blocks: float64(s.blocks) / seconds,
+		txs:    float64(s.txs) / seconds,
+		mgas:   float64(s.gas) / 1e6 / seconds,
+	}
+
+	*s = importStats{start: now}
+
+	return rates, true
+}
+
+// collectImportMetrics updates the import throughput gauges with the written block
+func (b *Blockchain) collectImportMetrics(txs int, gasUsed uint64) {
+	rates, ok := b.importStats.add(time.Now(), txs, gasUsed)
+	if !ok {
+		return
+	}
+
+	b.metrics.SetBlocksPerSecond(rates.blocks)
+	b.metrics.SetTransactionsPerSecond(rates.txs)
+	b.metrics.SetMgasPerSecond(rates.mgas)
+}
@@ -0,0 +1,35 @@
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportStats(t *testing.T) {
+	var (
+		stats importStats
+		now   = time.Unix(1000, 0)
+	)
+
+	// the first block opens the window
+	_, ok := stats.add(now, 100, 1e6)
+	assert.False(t, ok)
+
+	for i := 1; i < 5; i++ {
+		_, ok = stats.add(now.Add(time.Duration(i)*time.Second), 10, 2e6)
+		assert.False(t, ok)
+	}
+
+	rates, ok := stats.add(now.Add(importStatsWindow), 10, 2e6)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.5, rates.blocks, 1e-9)
+	assert.InDelta(t, 5, rates.txs, 1e-9)
+	assert.InDelta(t, 1, rates.mgas, 1e-9)
+
+	// the window is reset
+	_, ok = stats.add(now.Add(importStatsWindow+time.Second), 10, 2e6)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(1), stats.blocks)
+}
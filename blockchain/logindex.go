@@ -0,0 +1,133 @@
+package blockchain
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+// logIndexer records the (address, topic) -> block number postings of the
+// canonical blocks in background
+type logIndexer struct {
+	*chainIndexer
+
+	db storage.Storage
+}
+
+func newLogIndexer(logger hclog.Logger, db storage.Storage, headFn func() uint64) *logIndexer {
+	l := &logIndexer{db: db}
+
+	// start from genesis if not indexed before, it has no logs anyway
+	l.chainIndexer = newBlockIndexer(logger.Named("logindex"), headFn, l.indexBlock,
+		db.ReadLogIndexHead, db.WriteLogIndexHead)
+
+	return l
+}
+
+func (l *logIndexer) indexBlock(n uint64) error {
+	hash, ok := l.db.ReadCanonicalHash(n)
+	if !ok {
+		return storage.ErrNotFound
+	}
+
+	receipts, err := l.db.ReadReceipts(hash)
+	if errors.Is(err, storage.ErrNotFound) {
+		// header only block, no logs
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var (
+		addresses = []types.Address{}
+		topics    = []types.Hash{}
+
+		seenAddresses = map[types.Address]struct{}{}
+		seenTopics    = map[types.Hash]struct{}{}
+	)
+
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			if _, ok := seenAddresses[log.Address]; !ok {
+				seenAddresses[log.Address] = struct{}{}
+				addresses = append(addresses, log.Address)
+			}
+
+			for _, topic := range log.Topics {
+				if _, ok := seenTopics[topic]; !ok {
+					seenTopics[topic] = struct{}{}
+					topics = append(topics, topic)
+				}
+			}
+		}
+	}
+
+	if len(addresses) == 0 && len(topics) == 0 {
+		return nil
+	}
+
+	return l.db.WriteLogIndex(n, addresses, topics)
+}
+
+// filterBlocks returns the indexed block numbers within [from, to] which might contain
+// logs of any of the addresses, and any topic of every topic set. Empty sets are skipped,
+// so at least one criterion is required.
+func (l *logIndexer) filterBlocks(addresses []types.Address, topics [][]types.Hash, from, to uint64) []uint64 {
+	var candidates map[uint64]struct{} // nil means all blocks
+
+	intersect := func(numbers map[uint64]struct{}) {
+		if candidates == nil {
+			candidates = numbers
+
+			return
+		}
+
+		for n := range candidates {
+			if _, ok := numbers[n]; !ok {
+				delete(candidates, n)
+			}
+		}
+	}
+
+	if len(addresses) > 0 {
+		numbers := map[uint64]struct{}{}
+
+		for _, addr := range addresses {
+			for _, n := range l.db.ReadLogIndexByAddress(addr, from, to) {
+				numbers[n] = struct{}{}
+			}
+		}
+
+		intersect(numbers)
+	}
+
+	for _, set := range topics {
+		if len(set) == 0 {
+			continue
+		}
+
+		numbers := map[uint64]struct{}{}
+
+		for _, topic := range set {
+			for _, n := range l.db.ReadLogIndexByTopic(topic, from, to) {
+				numbers[n] = struct{}{}
+			}
+		}
+
+		intersect(numbers)
+	}
+
+	res := make([]uint64, 0, len(candidates))
+	for n := range candidates {
+		res = append(res, n)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i] < res[j]
+	})
+
+	return res
+}
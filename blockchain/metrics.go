@@ -2,6 +2,7 @@ package blockchain
 
 import (
 	"github.com/dogechain-lab/dogechain/helper/metrics"
+	"github.com/dogechain-lab/dogechain/state"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -21,8 +22,44 @@ type Metrics struct {
 	blockWrittenSeconds prometheus.Histogram
 	// Block execution duration
 	blockExecutionSeconds prometheus.Histogram
+	// Block verification duration
+	blockVerificationSeconds prometheus.Histogram
+	// Block commit duration
+	blockCommitSeconds prometheus.Histogram
+	// Block persistence duration
+	blockPersistSeconds prometheus.Histogram
+	// Imported blocks per second
+	blocksPerSecond prometheus.Gauge
+	// Imported transactions per second
+	transactionsPerSecond prometheus.Gauge
+	// Imported mgas per second
+	mgasPerSecond prometheus.Gauge
 	// Non-miner transaction number
 	transactionNum prometheus.Histogram
+	// Events dropped by slow subscribers
+	subscriptionEventsDropped prometheus.Counter
+	// Reorgs rejected for exceeding max depth
+	reorgRejected prometheus.Counter
+	// Blocks whose missing receipts are regenerated
+	receiptsBackfilled prometheus.Counter
+	// Last block checked for missing receipts
+	receiptsBackfillHead prometheus.Gauge
+	// Block imports delayed under resource pressure
+	importDelayed prometheus.Counter
+	// Delay of the block imports under resource pressure
+	importDelaySeconds prometheus.Histogram
+	// Max entries of the adaptive caches, by cache
+	cacheSize *prometheus.GaugeVec
+	// Accounts read from the state per block
+	stateAccountReads prometheus.Histogram
+	// Storage slots read from the state per block
+	stateStorageReads prometheus.Histogram
+	// Storage slots written to the state per block
+	stateStorageWrites prometheus.Histogram
+	// Trie nodes resolved per block
+	stateNodeLoads prometheus.Histogram
+	// Ratio of the trie nodes resolved from cache per block
+	stateNodeCacheHitRatio prometheus.Histogram
 }
 
 func (m *Metrics) MaxGasPriceObserve(v float64) {
@@ -49,10 +86,76 @@ func (m *Metrics) BlockExecutionSecondsObserve(v float64) {
 	metrics.HistogramObserve(m.blockExecutionSeconds, v)
 }
 
+func (m *Metrics) BlockVerificationSecondsObserve(v float64) {
+	metrics.HistogramObserve(m.blockVerificationSeconds, v)
+}
+
+func (m *Metrics) BlockCommitSecondsObserve(v float64) {
+	metrics.HistogramObserve(m.blockCommitSeconds, v)
+}
+
+func (m *Metrics) BlockPersistSecondsObserve(v float64) {
+	metrics.HistogramObserve(m.blockPersistSeconds, v)
+}
+
+func (m *Metrics) SetBlocksPerSecond(v float64) {
+	metrics.SetGauge(m.blocksPerSecond, v)
+}
+
+func (m *Metrics) SetTransactionsPerSecond(v float64) {
+	metrics.SetGauge(m.transactionsPerSecond, v)
+}
+
+func (m *Metrics) SetMgasPerSecond(v float64) {
+	metrics.SetGauge(m.mgasPerSecond, v)
+}
+
 func (m *Metrics) TransactionNumObserve(v float64) {
 	metrics.HistogramObserve(m.transactionNum, v)
 }
 
+func (m *Metrics) SubscriptionEventsDroppedInc() {
+	metrics.CounterInc(m.subscriptionEventsDropped)
+}
+
+func (m *Metrics) ReorgRejectedInc() {
+	metrics.CounterInc(m.reorgRejected)
+}
+
+func (m *Metrics) ReceiptsBackfilledInc() {
+	metrics.CounterInc(m.receiptsBackfilled)
+}
+
+func (m *Metrics) SetReceiptsBackfillHead(v float64) {
+	metrics.SetGauge(m.receiptsBackfillHead, v)
+}
+
+func (m *Metrics) ImportDelayedInc() {
+	metrics.CounterInc(m.importDelayed)
+}
+
+func (m *Metrics) ImportDelaySecondsObserve(v float64) {
+	metrics.HistogramObserve(m.importDelaySeconds, v)
+}
+
+func (m *Metrics) CacheSizeSet(cache string, v float64) {
+	if m.cacheSize != nil {
+		m.cacheSize.WithLabelValues(cache).Set(v)
+	}
+}
+
+// StateAccessObserve observes the state accesses of a block execution
+func (m *Metrics) StateAccessObserve(stats state.AccessStats) {
+	metrics.HistogramObserve(m.stateAccountReads, float64(stats.AccountReads))
+	metrics.HistogramObserve(m.stateStorageReads, float64(stats.StorageReads))
+	metrics.HistogramObserve(m.stateStorageWrites, float64(stats.StorageWrites))
+	metrics.HistogramObserve(m.stateNodeLoads, float64(stats.NodeLoads))
+
+	if stats.NodeLoads > 0 {
+		metrics.HistogramObserve(m.stateNodeCacheHitRatio, stats.NodeCacheHitRatio())
+	}
+}
+
 // GetPrometheusMetrics return the blockchain metrics instance
 func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics {
 	constLabels := metrics.ParseLables(labelsWithValues...)
@@ -90,7 +193,7 @@ func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics
 			Namespace:   namespace,
 			Subsystem:   subsystem,
 			Name:        "block_write_seconds",
-			Help:        "block write time (seconds)",
+			Help:        "block write time, from the receipts to the new head (seconds)",
 			ConstLabels: constLabels,
 		}),
 		blockExecutionSeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
@@ -100,6 +203,48 @@ func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics
 			Help:        "block execution time (seconds)",
 			ConstLabels: constLabels,
 		}),
+		blockVerificationSeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "block_verification_seconds",
+			Help:        "block verification time, including the execution (seconds)",
+			ConstLabels: constLabels,
+		}),
+		blockCommitSeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "block_commit_seconds",
+			Help:        "block header and chain head commit time (seconds)",
+			ConstLabels: constLabels,
+		}),
+		blockPersistSeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "block_persist_seconds",
+			Help:        "block body, receipts and txn lookups persistence time (seconds)",
+			ConstLabels: constLabels,
+		}),
+		blocksPerSecond: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "blocks_per_second",
+			Help:        "imported blocks per second",
+			ConstLabels: constLabels,
+		}),
+		transactionsPerSecond: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "transactions_per_second",
+			Help:        "imported transactions per second",
+			ConstLabels: constLabels,
+		}),
+		mgasPerSecond: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "mgas_per_second",
+			Help:        "imported million gas per second",
+			ConstLabels: constLabels,
+		}),
 		transactionNum: prometheus.NewHistogram(prometheus.HistogramOpts{
 			Namespace:   namespace,
 			Subsystem:   subsystem,
@@ -107,6 +252,95 @@ func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics
 			Help:        "Non-miner transaction number",
 			ConstLabels: constLabels,
 		}),
+		subscriptionEventsDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "subscription_events_dropped",
+			Help:        "events dropped because subscriber buffer is full",
+			ConstLabels: constLabels,
+		}),
+		reorgRejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "reorg_rejected",
+			Help:        "reorgs rejected for exceeding max reorg depth",
+			ConstLabels: constLabels,
+		}),
+		receiptsBackfilled: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "receipts_backfilled",
+			Help:        "blocks whose missing receipts are regenerated",
+			ConstLabels: constLabels,
+		}),
+		receiptsBackfillHead: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "receipts_backfill_head",
+			Help:        "last block checked for missing receipts",
+			ConstLabels: constLabels,
+		}),
+		importDelayed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "import_delayed",
+			Help:        "block imports delayed under resource pressure",
+			ConstLabels: constLabels,
+		}),
+		importDelaySeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "import_delay_seconds",
+			Help:        "delay of the block imports under resource pressure (seconds)",
+			ConstLabels: constLabels,
+		}),
+		cacheSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "cache_size",
+			Help:        "max entries of the adaptive caches",
+			ConstLabels: constLabels,
+		}, []string{"cache"}),
+		stateAccountReads: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "state_account_reads",
+			Help:        "accounts read from the state per block",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(1, 4, 10),
+		}),
+		stateStorageReads: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "state_storage_reads",
+			Help:        "storage slots read from the state per block",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(1, 4, 10),
+		}),
+		stateStorageWrites: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "state_storage_writes",
+			Help:        "storage slots written to the state per block",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(1, 4, 10),
+		}),
+		stateNodeLoads: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "state_node_loads",
+			Help:        "trie nodes resolved per block",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(1, 4, 12),
+		}),
+		stateNodeCacheHitRatio: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "state_node_cache_hit_ratio",
+			Help:        "ratio of the trie nodes resolved from cache per block",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.LinearBuckets(0.1, 0.1, 10),
+		}),
 	}
 
 	prometheus.MustRegister(
@@ -116,7 +350,25 @@ func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics
 		m.blockHeight,
 		m.blockWrittenSeconds,
 		m.blockExecutionSeconds,
+		m.blockVerificationSeconds,
+		m.blockCommitSeconds,
+		m.blockPersistSeconds,
+		m.blocksPerSecond,
+		m.transactionsPerSecond,
+		m.mgasPerSecond,
 		m.transactionNum,
+		m.subscriptionEventsDropped,
+		m.reorgRejected,
+		m.receiptsBackfilled,
+		m.receiptsBackfillHead,
+		m.importDelayed,
+		m.importDelaySeconds,
+		m.cacheSize,
+		m.stateAccountReads,
+		m.stateStorageReads,
+		m.stateStorageWrites,
+		m.stateNodeLoads,
+		m.stateNodeCacheHitRatio,
 	)
 
 	return m
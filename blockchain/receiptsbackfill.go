@@ -0,0 +1,221 @@
+package blockchain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/dogechain-lab/dogechain/types/buildroot"
+	"github.com/hashicorp/go-hclog"
+)
+
+// receiptsBackfillHeadFlushInterval is the number of checked blocks between the head writes
+const receiptsBackfillHeadFlushInterval = 1024
+
+var errReceiptsBackfillClosed = errors.New("receipts backfill closed")
+
+// receiptsBackfiller scans the canonical blocks for missing receipts, which might be
+// lost by the crashes of the older versions, and regenerates them by re-executing the
+// blocks in background. The re-executions are rate limited.
+type receiptsBackfiller struct {
+	logger    hclog.Logger
+	db        storage.Storage
+	metrics   *Metrics
+	headFn    func() uint64                                  // returns the number of the latest persisted block
+	executeFn func(block *types.Block) (*BlockResult, error) // re-executes the block
+	interval  time.Duration                                  // minimum interval between the re-executions
+
+	head uint64 // number of the last checked block
+
+	notifyCh chan struct{}
+	closeCh  chan struct{}
+	doneCh   chan struct{}
+}
+
+func newReceiptsBackfiller(
+	logger hclog.Logger,
+	db storage.Storage,
+	metrics *Metrics,
+	headFn func() uint64,
+	executeFn func(block *types.Block) (*BlockResult, error),
+	rate uint64,
+) *receiptsBackfiller {
+	// the genesis has no receipts, start from it if not checked before
+	head, _ := db.ReadReceiptsBackfillHead()
+
+	var interval time.Duration
+	if rate > 0 {
+		interval = time.Second / time.Duration(rate)
+	}
+
+	return &receiptsBackfiller{
+		logger:    logger.Named("receiptsbackfill"),
+		db:        db,
+		metrics:   metrics,
+		headFn:    headFn,
+		executeFn: executeFn,
+		interval:  interval,
+		head:      head,
+		notifyCh:  make(chan struct{}, 1),
+		closeCh:   make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+}
+
+func (f *receiptsBackfiller) start() {
+	go f.run()
+}
+
+func (f *receiptsBackfiller) run() {
+	defer close(f.doneCh)
+
+	// catch up with the chain head first
+	f.backfill()
+
+	for {
+		select {
+		case <-f.closeCh:
+			return
+		case <-f.notifyCh:
+			f.backfill()
+		}
+	}
+}
+
+// notify wakes up the backfiller once the chain head is updated
+func (f *receiptsBackfiller) notify() {
+	select {
+	case f.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (f *receiptsBackfiller) close() {
+	close(f.closeCh)
+	<-f.doneCh
+}
+
+// backfill checks the blocks up to the chain head
+func (f *receiptsBackfiller) backfill() {
+	head := f.headFn()
+
+	defer f.writeHead()
+
+	for f.head < head {
+		select {
+		case <-f.closeCh:
+			return
+		default:
+		}
+
+		n := f.head + 1
+
+		if err := f.backfillBlock(n); errors.Is(err, errReceiptsBackfillClosed) {
+			return
+		} else if err != nil {
+			f.logger.Error("failed to check block receipts", "number", n, "err", err)
+
+			return
+		}
+
+		f.head = n
+		f.metrics.SetReceiptsBackfillHead(float64(n))
+
+		if n%receiptsBackfillHeadFlushInterval == 0 {
+			f.writeHead()
+		}
+	}
+}
+
+func (f *receiptsBackfiller) writeHead() {
+	if err := f.db.WriteReceiptsBackfillHead(f.head); err != nil {
+		f.logger.Error("failed to write receipts backfill head", "number", f.head, "err", err)
+	}
+}
+
+// backfillBlock regenerates the receipts of the block if they are missing
+func (f *receiptsBackfiller) backfillBlock(n uint64) error {
+	hash, ok := f.db.ReadCanonicalHash(n)
+	if !ok {
+		return storage.ErrNotFound
+	}
+
+	body, err := f.db.ReadBody(hash)
+	if errors.Is(err, storage.ErrNotFound) {
+		// header only block, nothing to execute
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if len(body.Transactions) == 0 {
+		return nil
+	}
+
+	receipts, err := f.db.ReadReceipts(hash)
+	if err == nil && len(receipts) == len(body.Transactions) {
+		return nil
+	} else if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+
+	header, err := f.db.ReadHeader(hash)
+	if err != nil {
+		return err
+	}
+
+	if !f.wait() {
+		return errReceiptsBackfillClosed
+	}
+
+	block := &types.Block{
+		Header:       header,
+		Transactions: body.Transactions,
+		Uncles:       body.Uncles,
+	}
+
+	result, err := f.executeFn(block)
+	if errors.Is(err, ErrClosed) {
+		return errReceiptsBackfillClosed
+	} else if err != nil {
+		// the state might be missing as well, skip it rather than stalling the scan
+		f.logger.Warn("failed to re-execute block for the missing receipts",
+			"number", n, "hash", hash, "err", err)
+
+		return nil
+	}
+
+	if root := buildroot.CalculateReceiptsRoot(result.Receipts); root != header.ReceiptsRoot {
+		f.logger.Warn("regenerated receipts mismatch the header", "number", n, "hash", hash,
+			"expected", header.ReceiptsRoot, "actual", root)
+
+		return nil
+	}
+
+	if err := f.db.WriteReceipts(n, hash, result.Receipts); err != nil {
+		return err
+	}
+
+	f.metrics.ReceiptsBackfilledInc()
+	f.logger.Info("regenerated missing receipts", "number", n, "hash", hash, "txns", len(result.Receipts))
+
+	return nil
+}
+
+// wait waits for the rate limit before a re-execution, it returns false if closed
+func (f *receiptsBackfiller) wait() bool {
+	if f.interval <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(f.interval)
+	defer timer.Stop()
+
+	select {
+	case <-f.closeCh:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
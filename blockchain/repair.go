@@ -0,0 +1,171 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// maxTDReconstructDepth bounds the ancestors walked to reconstruct a missing total difficulty
+const maxTDReconstructDepth = 1024
+
+// checkHead verifies that the head hash, head number, canonical index and total
+// difficulty of the chain head agree with each other. They might be torn by an
+// unclean shutdown, then the head is rewound to the latest consistent block, and
+// the canonical index above it is truncated.
+func (b *Blockchain) checkHead(headHash types.Hash) (*types.Header, error) {
+	headNumber, hasNumber := b.db.ReadHeadNumber()
+	head, hasHeader := b.readHeader(headHash)
+
+	if hasHeader && hasNumber && headNumber == head.Number {
+		if header, ok := b.consistentHeaderAt(head.Number); ok && header.Hash == head.Hash {
+			return head, nil
+		}
+	}
+
+	// walk back from the highest number the storage claims
+	var top uint64
+
+	if hasHeader {
+		top = head.Number
+	}
+
+	if hasNumber && headNumber > top {
+		top = headNumber
+	}
+
+	target, ok := b.latestConsistentHeader(top)
+	if !ok {
+		return nil, fmt.Errorf("%w: head %s", ErrCorruptedChain, headHash)
+	}
+
+	b.logger.Warn("chain head is inconsistent, rewind to the latest consistent block",
+		"head", headHash,
+		"head_found", hasHeader,
+		"number", headNumber,
+		"number_found", hasNumber,
+		"to", target.Number,
+		"hash", target.Hash,
+		"truncated", top-target.Number,
+	)
+
+	if b.readOnly {
+		return target, nil
+	}
+
+	batch := b.db.NewAtomicBatch()
+
+	if _, err := b.writeHead(batch, target); err != nil {
+		return nil, err
+	}
+
+	for n := target.Number + 1; n <= top; n++ {
+		if hash, ok := b.db.ReadCanonicalHash(n); ok {
+			b.logger.Warn("truncate canonical block", "number", n, "hash", hash)
+		}
+
+		if err := batch.DeleteCanonicalHash(n); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// latestConsistentHeader returns the highest consistent canonical block, from the number down
+func (b *Blockchain) latestConsistentHeader(from uint64) (*types.Header, bool) {
+	for n := from; ; n-- {
+		if header, ok := b.consistentHeaderAt(n); ok {
+			return header, true
+		}
+
+		if n == 0 {
+			return nil, false
+		}
+	}
+}
+
+// consistentHeaderAt returns the canonical header of the number, if its header and
+// total difficulty are stored, and its parent is the canonical one
+func (b *Blockchain) consistentHeaderAt(n uint64) (*types.Header, bool) {
+	hash, ok := b.db.ReadCanonicalHash(n)
+	if !ok {
+		return nil, false
+	}
+
+	header, ok := b.readHeader(hash)
+	if !ok || header.Number != n || header.Hash != hash {
+		return nil, false
+	}
+
+	if _, ok := b.readTotalDifficulty(hash); !ok {
+		return nil, false
+	}
+
+	if n == 0 {
+		return header, true
+	}
+
+	parent, ok := b.db.ReadCanonicalHash(n - 1)
+	if !ok || parent != header.ParentHash {
+		return nil, false
+	}
+
+	return header, true
+}
+
+// reconstructTotalDifficulty rebuilds the missing total difficulty of the header from
+// the nearest ancestor with one, or from the genesis. The rebuilt total difficulties
+// are queued into the batch, and cached for the blocks of the batch built on them, so
+// that a single lost record does not halt the chain.
+func (b *Blockchain) reconstructTotalDifficulty(header *types.Header, batch storage.Batch) (*big.Int, error) {
+	missing := []*types.Header{header}
+	td := big.NewInt(0)
+
+	for current := header; current.Number > 0; {
+		if parentTD, ok := b.readTotalDifficulty(current.ParentHash); ok {
+			td.Set(parentTD)
+
+			break
+		}
+
+		if len(missing) >= maxTDReconstructDepth {
+			return nil, fmt.Errorf("%w: %s, no ancestor with one in %d blocks",
+				ErrHeadTDNotFound, header.Hash, maxTDReconstructDepth)
+		}
+
+		parent, ok := b.readHeader(current.ParentHash)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s, ancestor %s not found", ErrHeadTDNotFound, header.Hash, current.ParentHash)
+		}
+
+		missing = append(missing, parent)
+		current = parent
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		h := missing[i]
+
+		td = new(big.Int).Add(td, new(big.Int).SetUint64(h.Difficulty))
+		if err := batch.WriteTotalDifficulty(h.Number, h.Hash, td); err != nil {
+			return nil, err
+		}
+
+		b.difficultyCache.Add(h.Hash, td)
+	}
+
+	b.logger.Warn("reconstructed missing total difficulty",
+		"number", header.Number,
+		"hash", header.Hash,
+		"td", td,
+		"blocks", len(missing),
+	)
+
+	return td, nil
+}
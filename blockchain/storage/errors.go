@@ -2,4 +2,10 @@ package storage
 
 import "fmt"
 
-var ErrNotFound = fmt.Errorf("not found")
+var (
+	ErrNotFound = fmt.Errorf("not found")
+	ErrReadOnly = fmt.Errorf("storage is read-only")
+
+	ErrUnknownKeyLayout  = fmt.Errorf("unknown key layout")
+	ErrKeyLayoutMismatch = fmt.Errorf("key layout differs from the one of the storage")
+)
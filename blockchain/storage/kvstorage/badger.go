@@ -0,0 +1,59 @@
+package kvstorage
+
+import (
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
+	"github.com/hashicorp/go-hclog"
+)
+
+type badgerStorageBuilder struct {
+	logger         hclog.Logger
+	badgerBuilder  kvdb.BadgerDBBuilder
+	readOnly       bool
+	idealBatchSize int
+	keyLayout      storage.KeyLayout
+}
+
+func (builder *badgerStorageBuilder) SetReadOnly(readOnly bool) storage.StorageBuilder {
+	builder.readOnly = readOnly
+	builder.badgerBuilder.SetReadOnly(readOnly)
+
+	return builder
+}
+
+func (builder *badgerStorageBuilder) IsReadOnly() bool {
+	return builder.readOnly
+}
+
+func (builder *badgerStorageBuilder) SetIdealBatchSize(size int) storage.StorageBuilder {
+	builder.idealBatchSize = size
+
+	return builder
+}
+
+func (builder *badgerStorageBuilder) SetKeyLayout(layout storage.KeyLayout) storage.StorageBuilder {
+	builder.keyLayout = layout
+
+	return builder
+}
+
+func (builder *badgerStorageBuilder) Build() (storage.Storage, error) {
+	db, err := builder.badgerBuilder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return openKeyValueStorage(builder.logger.Named("badgerdb"), db, kvStorageOptions{
+		readOnly:       builder.readOnly,
+		idealBatchSize: builder.idealBatchSize,
+		layout:         builder.keyLayout,
+	})
+}
+
+// NewBadgerDBStorageBuilder creates the new blockchain storage builder backed by badger
+func NewBadgerDBStorageBuilder(logger hclog.Logger, badgerBuilder kvdb.BadgerDBBuilder) storage.StorageBuilder {
+	return &badgerStorageBuilder{
+		logger:        logger,
+		badgerBuilder: badgerBuilder,
+	}
+}
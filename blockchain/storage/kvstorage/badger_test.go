@@ -0,0 +1,46 @@
+//go:build badgerdb
+// +build badgerdb
+
+package kvstorage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
+	"github.com/hashicorp/go-hclog"
+)
+
+func newBadgerDBStorage(t *testing.T) (storage.Storage, func()) {
+	t.Helper()
+
+	path, err := os.MkdirTemp("", "minimal_storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := hclog.NewNullLogger()
+
+	s, err := NewBadgerDBStorageBuilder(
+		logger, kvdb.NewBadgerDBBuilder(logger, path)).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closeFn := func() {
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.RemoveAll(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return s, closeFn
+}
+
+func TestBadgerDBStorage(t *testing.T) {
+	storage.TestStorage(t, newBadgerDBStorage)
+}
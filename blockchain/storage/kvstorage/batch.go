@@ -0,0 +1,104 @@
+package kvstorage
+
+import (
+	"math/big"
+
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// batchWriter queues the writes into the kv batch
+type batchWriter struct {
+	batch kvdb.KVBatch
+}
+
+func (w *batchWriter) Set(p []byte, v []byte) error {
+	w.batch.Set(p, v)
+
+	return nil
+}
+
+func (w *batchWriter) Delete(p []byte) error {
+	w.batch.Delete(p)
+
+	return nil
+}
+
+// keyValueBatch encodes the writes as the storage does, and queues them into the batch
+type keyValueBatch struct {
+	writes        *KeyValueStorage // the storage writing to the batch
+	batch         kvdb.KVBatch
+	receiptsBatch kvdb.KVBatch // the batch of the separate receipts database, if any
+}
+
+func (b *keyValueBatch) WriteCanonicalHash(n uint64, hash types.Hash) error {
+	return b.writes.WriteCanonicalHash(n, hash)
+}
+
+func (b *keyValueBatch) DeleteCanonicalHash(n uint64) error {
+	return b.writes.DeleteCanonicalHash(n)
+}
+
+func (b *keyValueBatch) WriteHeadHash(h types.Hash) error {
+	return b.writes.WriteHeadHash(h)
+}
+
+func (b *keyValueBatch) WriteHeadNumber(n uint64) error {
+	return b.writes.WriteHeadNumber(n)
+}
+
+func (b *keyValueBatch) WritePersistedHead(n uint64) error {
+	return b.writes.WritePersistedHead(n)
+}
+
+func (b *keyValueBatch) WriteForks(forks []types.Hash) error {
+	return b.writes.WriteForks(forks)
+}
+
+func (b *keyValueBatch) WriteTotalDifficulty(n uint64, hash types.Hash, diff *big.Int) error {
+	return b.writes.WriteTotalDifficulty(n, hash, diff)
+}
+
+func (b *keyValueBatch) WriteHeader(h *types.Header) error {
+	return b.writes.WriteHeader(h)
+}
+
+func (b *keyValueBatch) WriteCanonicalHeader(h *types.Header, diff *big.Int) error {
+	return b.writes.WriteCanonicalHeader(h, diff)
+}
+
+func (b *keyValueBatch) WriteBody(n uint64, hash types.Hash, body *types.Body) error {
+	return b.writes.WriteBody(n, hash, body)
+}
+
+func (b *keyValueBatch) WriteReceipts(n uint64, hash types.Hash, receipts []*types.Receipt) error {
+	return b.writes.WriteReceipts(n, hash, receipts)
+}
+
+func (b *keyValueBatch) WriteTxLookup(hash types.Hash, blockHash types.Hash) error {
+	return b.writes.WriteTxLookup(hash, blockHash)
+}
+
+func (b *keyValueBatch) DeleteTxLookup(hash types.Hash) error {
+	return b.writes.DeleteTxLookup(hash)
+}
+
+// Write writes the queued pairs, and resets the batch for reuse. The receipts are
+// written first, as the chain head is moved by the writes of the main database.
+func (b *keyValueBatch) Write() error {
+	if b.receiptsBatch != nil {
+		if err := b.receiptsBatch.Write(); err != nil {
+			return err
+		}
+
+		b.receiptsBatch.Reset()
+	}
+
+	if err := b.batch.Write(); err != nil {
+		return err
+	}
+
+	b.batch.Reset()
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package kvstorage
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/common"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/dogechain-lab/fastrlp"
+)
+
+// The consensus encodings of the layouts keeping the values like go-ethereum rawdb
+
+var (
+	errSendersMismatch  = errors.New("transaction senders mismatch the body")
+	errReceiptsMismatch = errors.New("receipts mismatch the body")
+)
+
+func encodeConsensusDifficulty(diff *big.Int) []byte {
+	ar := &fastrlp.Arena{}
+
+	return ar.NewBigInt(diff).MarshalTo(nil)
+}
+
+func decodeConsensusDifficulty(data []byte) (*big.Int, error) {
+	v, err := (&fastrlp.Parser{}).Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := new(big.Int)
+	if err := v.GetBigInt(diff); err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+// encodeConsensusBody encodes the body with the consensus transactions, the
+// senders are left out
+func encodeConsensusBody(body *types.Body) []byte {
+	ar := &fastrlp.Arena{}
+
+	vv := ar.NewArray()
+
+	txs := ar.NewArray()
+	for _, tx := range body.Transactions {
+		txs.Set(tx.MarshalRLPWith(ar))
+	}
+
+	uncles := ar.NewArray()
+	for _, uncle := range body.Uncles {
+		uncles.Set(uncle.MarshalRLPWith(ar))
+	}
+
+	vv.Set(txs)
+	vv.Set(uncles)
+
+	return vv.MarshalTo(nil)
+}
+
+func decodeConsensusBody(data []byte) (*types.Body, error) {
+	body := &types.Body{}
+
+	err := types.UnmarshalRlp(func(p *fastrlp.Parser, v *fastrlp.Value) error {
+		tuple, err := v.GetElems()
+		if err != nil {
+			return err
+		}
+
+		if len(tuple) < 2 {
+			return fmt.Errorf("incorrect number of elements to decode body, expected at least 2 but found %d",
+				len(tuple))
+		}
+
+		txs, err := tuple[0].GetElems()
+		if err != nil {
+			return err
+		}
+
+		for _, elem := range txs {
+			tx := &types.Transaction{}
+			if err := tx.UnmarshalRLPFrom(p, elem); err != nil {
+				return err
+			}
+
+			body.Transactions = append(body.Transactions, tx)
+		}
+
+		uncles, err := tuple[1].GetElems()
+		if err != nil {
+			return err
+		}
+
+		for _, elem := range uncles {
+			uncle := &types.Header{}
+			if err := uncle.UnmarshalRLPFrom(p, elem); err != nil {
+				return err
+			}
+
+			body.Uncles = append(body.Uncles, uncle)
+		}
+
+		return nil
+	}, data)
+
+	return body, err
+}
+
+func encodeSenders(txs []*types.Transaction) []byte {
+	ar := &fastrlp.Arena{}
+
+	vv := ar.NewArray()
+	for _, tx := range txs {
+		vv.Set(ar.NewBytes(tx.From.Bytes()))
+	}
+
+	return vv.MarshalTo(nil)
+}
+
+// decodeSenders sets the senders of the body transactions
+func decodeSenders(data []byte, txs []*types.Transaction) error {
+	return types.UnmarshalRlp(func(p *fastrlp.Parser, v *fastrlp.Value) error {
+		elems, err := v.GetElems()
+		if err != nil {
+			return err
+		}
+
+		if len(elems) != len(txs) {
+			return fmt.Errorf("%w: %d senders, %d transactions", errSendersMismatch, len(elems), len(txs))
+		}
+
+		for i, elem := range elems {
+			if err := elem.GetAddr(txs[i].From[:]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, data)
+}
+
+// encodeConsensusReceipts encodes the receipts like the go-ethereum stored receipts,
+// the status or the root, the cumulative gas used and the logs
+func encodeConsensusReceipts(receipts []*types.Receipt) []byte {
+	ar := &fastrlp.Arena{}
+
+	vv := ar.NewArray()
+
+	for _, r := range receipts {
+		rv := ar.NewArray()
+
+		if r.Status != nil {
+			rv.Set(ar.NewUint(uint64(*r.Status)))
+		} else {
+			rv.Set(ar.NewBytes(r.Root[:]))
+		}
+
+		rv.Set(ar.NewUint(r.CumulativeGasUsed))
+		rv.Set(r.MarshalLogsWith(ar))
+
+		vv.Set(rv)
+	}
+
+	return vv.MarshalTo(nil)
+}
+
+func decodeConsensusReceipts(data []byte) ([]*types.Receipt, error) {
+	receipts := []*types.Receipt{}
+
+	err := types.UnmarshalRlp(func(p *fastrlp.Parser, v *fastrlp.Value) error {
+		elems, err := v.GetElems()
+		if err != nil {
+			return err
+		}
+
+		for _, elem := range elems {
+			fields, err := elem.GetElems()
+			if err != nil {
+				return err
+			}
+
+			if len(fields) < 3 {
+				return fmt.Errorf("incorrect number of elements to decode receipt, expected at least 3 but found %d",
+					len(fields))
+			}
+
+			r := &types.Receipt{}
+
+			// root or status
+			buf, err := fields[0].Bytes()
+			if err != nil {
+				return err
+			}
+
+			switch len(buf) {
+			case 32:
+				copy(r.Root[:], buf)
+			case 1:
+				r.SetStatus(types.ReceiptStatus(buf[0]))
+			default:
+				r.SetStatus(types.ReceiptFailed)
+			}
+
+			if r.CumulativeGasUsed, err = fields[1].GetUint64(); err != nil {
+				return err
+			}
+
+			logs, err := fields[2].GetElems()
+			if err != nil {
+				return err
+			}
+
+			for _, logElem := range logs {
+				log := &types.Log{}
+				if err := log.UnmarshalRLPFrom(p, logElem); err != nil {
+					return err
+				}
+
+				r.Logs = append(r.Logs, log)
+			}
+
+			receipts = append(receipts, r)
+		}
+
+		return nil
+	}, data)
+
+	return receipts, err
+}
+
+// deriveReceiptFields fills in the receipt fields left out of the consensus encoding
+// from the body, the senders included
+func deriveReceiptFields(receipts []*types.Receipt, body *types.Body) error {
+	if len(receipts) != len(body.Transactions) {
+		return fmt.Errorf("%w: %d receipts, %d transactions",
+			errReceiptsMismatch, len(receipts), len(body.Transactions))
+	}
+
+	var cumulativeGasUsed uint64
+
+	for i, receipt := range receipts {
+		tx := body.Transactions[i]
+
+		receipt.TxHash = tx.Hash()
+		receipt.GasUsed = common.MaxUint64(receipt.CumulativeGasUsed, cumulativeGasUsed) - cumulativeGasUsed
+		receipt.LogsBloom = types.CreateBloom([]*types.Receipt{receipt})
+		cumulativeGasUsed = receipt.CumulativeGasUsed
+
+		if tx.To == nil {
+			receipt.SetContractAddress(crypto.CreateAddress(tx.From, tx.Nonce))
+		}
+	}
+
+	return nil
+}
@@ -3,9 +3,12 @@ package kvstorage
 
 import (
 	"encoding/binary"
+	"errors"
+	"math"
 	"math/big"
 
 	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
 	"github.com/dogechain-lab/dogechain/types"
 	"github.com/dogechain-lab/fastrlp"
 	"github.com/hashicorp/go-hclog"
@@ -39,13 +42,41 @@ var (
 
 	// TX_LOOKUP_PREFIX is the prefix for transaction lookups
 	TX_LOOKUP_PREFIX = []byte("l")
+
+	// LOG_INDEX_PREFIX is the prefix for log address and topic postings
+	LOG_INDEX_PREFIX = []byte("i")
+
+	// BLOOM_BITS_PREFIX is the prefix for rotated bloom bit vectors of sections
+	BLOOM_BITS_PREFIX = []byte("m")
+
+	// CHAIN_STATS_PREFIX is the prefix for hourly aggregated chain usage
+	CHAIN_STATS_PREFIX = []byte("g")
+
+	// STATE_ROOT_PREFIX is the prefix for state root to block number postings
+	STATE_ROOT_PREFIX = []byte("t")
+
+	// SYSTEM_TX_INDEX_PREFIX is the prefix for system transaction type postings
+	SYSTEM_TX_INDEX_PREFIX = []byte("y")
+
+	// ANCHOR_PREFIX is the prefix for block hashes linked to the trusted checkpoint
+	ANCHOR_PREFIX = []byte("k")
 )
 
 // Sub-prefixes
 var (
-	HASH   = []byte("hash")
-	NUMBER = []byte("number")
-	EMPTY  = []byte("empty")
+	HASH      = []byte("hash")
+	NUMBER    = []byte("number")
+	EMPTY     = []byte("empty")
+	PERSISTED = []byte("persisted")
+	HALT      = []byte("halt")
+
+	// SECTIONS is the number of the indexed bloom bits sections. It replaces the former
+	// number key, so that the sections indexed from the unvalidated header blooms
+	// before the LogsBloom fork are indexed again.
+	SECTIONS = []byte("sections")
+
+	ADDRESS = []byte("a")
+	TOPIC   = []byte("t")
 )
 
 // KV is a generic key-value store, need close it
@@ -54,16 +85,109 @@ type KV interface {
 
 	Set(p []byte, v []byte) error
 	Get(p []byte) ([]byte, bool, error)
+	Delete(p []byte) error
+
+	Batch() kvdb.KVBatch
+}
+
+// kvWriter is the destination of the writes, the database or a batch
+type kvWriter interface {
+	Set(p []byte, v []byte) error
+	Delete(p []byte) error
+}
+
+// iterableKV is a KV which supports range iteration
+type iterableKV interface {
+	KV
+
+	Iterator(*kvdb.KVIteratorRange) kvdb.KVIterator
 }
 
 // KeyValueStorage is a generic storage for kv databases
 type KeyValueStorage struct {
-	logger hclog.Logger
-	db     KV
+	logger         hclog.Logger
+	db             KV
+	writer         kvWriter
+	layout         keyLayout
+	readOnly       bool // all writes return storage.ErrReadOnly
+	idealBatchSize int  // size of the writes queued in a batch before flushed automatically
+
+	// receipts is the storage of the receipts and transaction lookups, the storage
+	// itself unless they are kept in a separate database
+	receipts *KeyValueStorage
 }
 
-func newKeyValueStorage(logger hclog.Logger, db KV) storage.Storage {
-	return &KeyValueStorage{logger: logger, db: db}
+// newKeyValueStorage creates the storage of the database, the receipts and transaction
+// lookups are kept in the receipts database if it is not nil
+func newKeyValueStorage(
+	logger hclog.Logger,
+	db KV,
+	receiptsDB KV,
+	layout keyLayout,
+	readOnly bool,
+	idealBatchSize int,
+) storage.Storage {
+	s := &KeyValueStorage{
+		logger:         logger,
+		db:             db,
+		writer:         db,
+		layout:         layout,
+		readOnly:       readOnly,
+		idealBatchSize: idealBatchSize,
+	}
+	s.receipts = s
+
+	if receiptsDB != nil {
+		s.receipts = &KeyValueStorage{
+			logger:         logger.Named("receipts"),
+			db:             receiptsDB,
+			writer:         receiptsDB,
+			layout:         layout,
+			readOnly:       readOnly,
+			idealBatchSize: idealBatchSize,
+		}
+		s.receipts.receipts = s.receipts
+	}
+
+	return s
+}
+
+// kvStorageOptions are the options of the kv storage builders
+type kvStorageOptions struct {
+	readOnly       bool
+	idealBatchSize int
+	layout         storage.KeyLayout // empty for the layout of the storage
+	receiptsDB     KV                // separate database of the receipts and transaction lookups, if any
+}
+
+// openKeyValueStorage resolves the key layout and migrates the schema of the database
+// before it is used
+func openKeyValueStorage(logger hclog.Logger, db KV, opts kvStorageOptions) (storage.Storage, error) {
+	layout, err := resolveKeyLayout(db, opts.layout, opts.readOnly)
+	if err == nil {
+		err = migrateSchema(logger, db, opts.readOnly, schemaMigrations)
+	}
+
+	if err == nil {
+		err = checkReceiptsDB(db, opts.receiptsDB != nil, opts.readOnly)
+	}
+
+	if err != nil {
+		db.Close()
+
+		if opts.receiptsDB != nil {
+			opts.receiptsDB.Close()
+		}
+
+		return nil, err
+	}
+
+	return newKeyValueStorage(logger, db, opts.receiptsDB, layout, opts.readOnly, opts.idealBatchSize), nil
+}
+
+// separated returns whether the receipts are kept in a separate database
+func (s *KeyValueStorage) separated() bool {
+	return s.receipts != s
 }
 
 func (s *KeyValueStorage) encodeUint(n uint64) []byte {
@@ -81,7 +205,7 @@ func (s *KeyValueStorage) decodeUint(b []byte) uint64 {
 
 // ReadCanonicalHash gets the hash from the number of the canonical chain
 func (s *KeyValueStorage) ReadCanonicalHash(n uint64) (types.Hash, bool) {
-	data, ok := s.get(CANONICAL, s.encodeUint(n))
+	data, ok := s.get(s.layout.canonicalHashKey(n), nil)
 	if !ok {
 		return types.Hash{}, false
 	}
@@ -91,14 +215,67 @@ func (s *KeyValueStorage) ReadCanonicalHash(n uint64) (types.Hash, bool) {
 
 // WriteCanonicalHash writes a hash for a number block in the canonical chain
 func (s *KeyValueStorage) WriteCanonicalHash(n uint64, hash types.Hash) error {
-	return s.set(CANONICAL, s.encodeUint(n), hash.Bytes())
+	return s.set(s.layout.canonicalHashKey(n), nil, hash.Bytes())
+}
+
+// DeleteCanonicalHash removes the number block from the canonical chain
+func (s *KeyValueStorage) DeleteCanonicalHash(n uint64) error {
+	return s.delete(s.layout.canonicalHashKey(n), nil)
+}
+
+// ReadCanonicalHashesInRange returns the canonical hashes from number 'from' to 'to' (inclusive)
+func (s *KeyValueStorage) ReadCanonicalHashesInRange(from, to uint64) []types.Hash {
+	hashes := []types.Hash{}
+
+	if from > to {
+		return hashes
+	}
+
+	db, ok := s.db.(iterableKV)
+	if !ok || s.layout.canonicalPrefix() == nil {
+		// fallback to point lookups
+		for n := from; n >= from && n <= to; n++ {
+			hash, ok := s.ReadCanonicalHash(n)
+			if !ok {
+				break
+			}
+
+			hashes = append(hashes, hash)
+		}
+
+		return hashes
+	}
+
+	// the limit is exclusive, use the next prefix when it overflows
+	limit := append([]byte{}, CANONICAL[0]+1)
+	if to+1 > to {
+		limit = append(append([]byte{}, CANONICAL...), s.encodeUint(to+1)...)
+	}
+
+	iter := db.Iterator(&kvdb.KVIteratorRange{
+		Start: append(append([]byte{}, CANONICAL...), s.encodeUint(from)...),
+		Limit: limit,
+	})
+	defer iter.Release()
+
+	for expected := from; iter.Next(); expected++ {
+		key := iter.Key()
+		if len(key) != len(CANONICAL)+8 || s.decodeUint(key[len(CANONICAL):]) != expected {
+			// gap in the canonical chain
+			break
+		}
+
+		hashes = append(hashes, types.BytesToHash(iter.Value()))
+	}
+
+	return hashes
 }
 
 // HEAD //
 
 // ReadHeadHash returns the hash of the head
 func (s *KeyValueStorage) ReadHeadHash() (types.Hash, bool) {
-	data, ok := s.get(HEAD, HASH)
+	data, ok := s.get(s.layout.headHashKeys()[0], nil)
 	if !ok {
 		return types.Hash{}, false
 	}
@@ -122,7 +299,13 @@ func (s *KeyValueStorage) ReadHeadNumber() (uint64, bool) {
 
 // WriteHeadHash writes the hash of the head
 func (s *KeyValueStorage) WriteHeadHash(h types.Hash) error {
-	return s.set(HEAD, HASH, h.Bytes())
+	for _, key := range s.layout.headHashKeys() {
+		if err := s.set(key, nil, h.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // WriteHeadNumber writes the number of the head
@@ -130,6 +313,21 @@ func (s *KeyValueStorage) WriteHeadNumber(n uint64) error {
 	return s.set(HEAD, NUMBER, s.encodeUint(n))
 }
 
+// WritePersistedHead writes the number of the latest block whose data is persisted
+func (s *KeyValueStorage) WritePersistedHead(n uint64) error {
+	return s.set(HEAD, PERSISTED, s.encodeUint(n))
+}
+
+// ReadPersistedHead returns the number of the latest block whose data is persisted
+func (s *KeyValueStorage) ReadPersistedHead() (uint64, bool) {
+	data, ok := s.get(HEAD, PERSISTED)
+	if !ok || len(data) != 8 {
+		return 0, false
+	}
+
+	return s.decodeUint(data), true
+}
+
 // FORK //
 
 // WriteForks writes the current forks
@@ -147,34 +345,164 @@ func (s *KeyValueStorage) ReadForks() ([]types.Hash, error) {
 	return *forks, err
 }
 
+// HALT //
+
+// WriteHaltState writes the emergency halt state of the chain
+func (s *KeyValueStorage) WriteHaltState(state *storage.HaltState) error {
+	return s.writeRLP(HEAD, HALT, state)
+}
+
+// ReadHaltState reads the emergency halt state of the chain
+func (s *KeyValueStorage) ReadHaltState() (*storage.HaltState, bool) {
+	state := &storage.HaltState{}
+	if err := s.readRLP(HEAD, HALT, state); err != nil {
+		return nil, false
+	}
+
+	return state, true
+}
+
+// ANCHOR //
+
+// WriteCheckpointAnchor writes the hash of the block linked to the trusted checkpoint
+func (s *KeyValueStorage) WriteCheckpointAnchor(checkpoint types.Hash, n uint64, hash types.Hash) error {
+	return s.set(ANCHOR_PREFIX, s.anchorKey(checkpoint, n), hash.Bytes())
+}
+
+// ReadCheckpointAnchor reads the hash of the block linked to the trusted checkpoint
+func (s *KeyValueStorage) ReadCheckpointAnchor(checkpoint types.Hash, n uint64) (types.Hash, bool) {
+	data, ok := s.get(ANCHOR_PREFIX, s.anchorKey(checkpoint, n))
+	if !ok || len(data) != types.HashLength {
+		return types.Hash{}, false
+	}
+
+	return types.BytesToHash(data), true
+}
+
+func (s *KeyValueStorage) anchorKey(checkpoint types.Hash, n uint64) []byte {
+	return append(checkpoint.Bytes(), s.encodeUint(n)...)
+}
+
 // DIFFICULTY //
 
 // WriteTotalDifficulty writes the difficulty
-func (s *KeyValueStorage) WriteTotalDifficulty(hash types.Hash, diff *big.Int) error {
-	return s.set(DIFFICULTY, hash.Bytes(), diff.Bytes())
+func (s *KeyValueStorage) WriteTotalDifficulty(n uint64, hash types.Hash, diff *big.Int) error {
+	if s.layout.consensusEncoded() {
+		return s.set(s.layout.difficultyKey(n, hash), nil, encodeConsensusDifficulty(diff))
+	}
+
+	return s.set(s.layout.difficultyKey(n, hash), nil, diff.Bytes())
 }
 
 // ReadTotalDifficulty reads the difficulty
 func (s *KeyValueStorage) ReadTotalDifficulty(hash types.Hash) (*big.Int, bool) {
-	v, ok := s.get(DIFFICULTY, hash.Bytes())
+	n, ok := s.readBlockNumber(hash)
 	if !ok {
 		return nil, false
 	}
 
+	v, ok := s.get(s.layout.difficultyKey(n, hash), nil)
+	if !ok {
+		return nil, false
+	}
+
+	if s.layout.consensusEncoded() {
+		diff, err := decodeConsensusDifficulty(v)
+		if err != nil {
+			return nil, false
+		}
+
+		return diff, true
+	}
+
 	return big.NewInt(0).SetBytes(v), true
 }
 
 // HEADER //
 
+// readBlockNumber returns the number of the block hash, if the layout keys the
+// block data by number
+func (s *KeyValueStorage) readBlockNumber(hash types.Hash) (uint64, bool) {
+	if !s.layout.numbered() {
+		return 0, true
+	}
+
+	data, ok := s.get(s.layout.numberKey(hash), nil)
+	if !ok || len(data) != 8 {
+		return 0, false
+	}
+
+	return s.decodeUint(data), true
+}
+
 // WriteHeader writes the header
 func (s *KeyValueStorage) WriteHeader(h *types.Header) error {
-	return s.writeRLP(HEADER, h.Hash.Bytes(), h)
+	if s.layout.numbered() {
+		if err := s.set(s.layout.numberKey(h.Hash), nil, s.encodeUint(h.Number)); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writeStateRootIndex(h.StateRoot, h.Number); err != nil {
+		return err
+	}
+
+	return s.writeRLP(s.layout.headerKey(h.Number, h.Hash), nil, h)
+}
+
+// writeStateRootIndex records the number under the state root, and counts the recorded
+// numbers under the bare key. The count is read from the database, so the headers of
+// the root written within a batch are counted once, and might exceed the cap slightly.
+func (s *KeyValueStorage) writeStateRootIndex(root types.Hash, n uint64) error {
+	key := s.stateRootIndexKey(root)
+
+	if _, ok := s.get(key, s.encodeUint(n)); ok {
+		return nil
+	}
+
+	count := uint64(0)
+	if data, ok := s.get(key, nil); ok {
+		count = s.decodeUint(data)
+	}
+
+	if count >= storage.MaxStateRootIndexPostings {
+		return nil
+	}
+
+	if err := s.set(key, s.encodeUint(n), []byte{}); err != nil {
+		return err
+	}
+
+	return s.set(key, nil, s.encodeUint(count+1))
+}
+
+// ReadStateRootIndex returns the numbers of the headers written with the state root,
+// forks included
+func (s *KeyValueStorage) ReadStateRootIndex(root types.Hash) []uint64 {
+	to := uint64(math.MaxUint64)
+
+	if _, ok := s.db.(iterableKV); !ok {
+		// bound the point lookups by the chain head
+		to, _ = s.ReadHeadNumber()
+	}
+
+	return s.readPostings(s.stateRootIndexKey(root), 0, to)
+}
+
+func (s *KeyValueStorage) stateRootIndexKey(root types.Hash) []byte {
+	return concatKey(STATE_ROOT_PREFIX, root.Bytes())
 }
 
 // ReadHeader reads the header
 func (s *KeyValueStorage) ReadHeader(hash types.Hash) (*types.Header, error) {
 	header := &types.Header{}
-	err := s.readRLP(HEADER, hash.Bytes(), header)
+
+	n, ok := s.readBlockNumber(hash)
+	if !ok {
+		return header, storage.ErrNotFound
+	}
+
+	err := s.readRLP(s.layout.headerKey(n, hash), nil, header)
 
 	return header, err
 }
@@ -197,7 +525,7 @@ func (s *KeyValueStorage) WriteCanonicalHeader(h *types.Header, diff *big.Int) e
 		return err
 	}
 
-	if err := s.WriteTotalDifficulty(h.Hash, diff); err != nil {
+	if err := s.WriteTotalDifficulty(h.Number, h.Hash, diff); err != nil {
 		return err
 	}
 
@@ -207,35 +535,137 @@ func (s *KeyValueStorage) WriteCanonicalHeader(h *types.Header, diff *big.Int) e
 // BODY //
 
 // WriteBody writes the body
-func (s *KeyValueStorage) WriteBody(hash types.Hash, body *types.Body) error {
-	return s.writeRLP(BODY, hash.Bytes(), body)
+func (s *KeyValueStorage) WriteBody(n uint64, hash types.Hash, body *types.Body) error {
+	if s.layout.consensusEncoded() {
+		if err := s.set(s.layout.sendersKey(n, hash), nil, encodeSenders(body.Transactions)); err != nil {
+			return err
+		}
+
+		return s.set(s.layout.bodyKey(n, hash), nil, encodeConsensusBody(body))
+	}
+
+	return s.writeRLP(s.layout.bodyKey(n, hash), nil, body)
 }
 
 // ReadBody reads the body
 func (s *KeyValueStorage) ReadBody(hash types.Hash) (*types.Body, error) {
 	body := &types.Body{}
-	err := s.readRLP(BODY, hash.Bytes(), body)
+
+	n, ok := s.readBlockNumber(hash)
+	if !ok {
+		return body, storage.ErrNotFound
+	}
+
+	if s.layout.consensusEncoded() {
+		return s.readConsensusBody(n, hash)
+	}
+
+	err := s.readRLP(s.layout.bodyKey(n, hash), nil, body)
 
 	return body, err
 }
 
+// readConsensusBody reads the consensus encoded body, along with the senders of
+// its transactions
+func (s *KeyValueStorage) readConsensusBody(n uint64, hash types.Hash) (*types.Body, error) {
+	data, ok := s.get(s.layout.bodyKey(n, hash), nil)
+	if !ok {
+		return &types.Body{}, storage.ErrNotFound
+	}
+
+	body, err := decodeConsensusBody(data)
+	if err != nil {
+		return body, err
+	}
+
+	senders, ok := s.get(s.layout.sendersKey(n, hash), nil)
+	if !ok {
+		return body, storage.ErrNotFound
+	}
+
+	return body, decodeSenders(senders, body.Transactions)
+}
+
 // RECEIPTS //
 
 // WriteReceipts writes the receipts
-func (s *KeyValueStorage) WriteReceipts(hash types.Hash, receipts []*types.Receipt) error {
+func (s *KeyValueStorage) WriteReceipts(n uint64, hash types.Hash, receipts []*types.Receipt) error {
+	if s.layout.consensusEncoded() {
+		return s.receipts.set(s.layout.receiptsKey(n, hash), nil, encodeConsensusReceipts(receipts))
+	}
+
 	rr := types.Receipts(receipts)
 
-	return s.writeRLP(RECEIPTS, hash.Bytes(), &rr)
+	return s.receipts.writeRLP(s.layout.receiptsKey(n, hash), nil, &rr)
 }
 
 // ReadReceipts reads the receipts
 func (s *KeyValueStorage) ReadReceipts(hash types.Hash) ([]*types.Receipt, error) {
 	receipts := &types.Receipts{}
-	err := s.readRLP(RECEIPTS, hash.Bytes(), receipts)
+
+	n, ok := s.readBlockNumber(hash)
+	if !ok {
+		return *receipts, storage.ErrNotFound
+	}
+
+	key := s.layout.receiptsKey(n, hash)
+
+	if s.layout.consensusEncoded() {
+		return s.readConsensusReceipts(n, hash)
+	}
+
+	err := s.receipts.readRLP(key, nil, receipts)
+	if errors.Is(err, storage.ErrNotFound) && s.separated() {
+		// written before the receipts database was separated
+		err = s.readRLP(key, nil, receipts)
+	}
 
 	return *receipts, err
 }
 
+// readConsensusReceipts reads the consensus encoded receipts, and derives the other
+// fields from the body
+func (s *KeyValueStorage) readConsensusReceipts(n uint64, hash types.Hash) ([]*types.Receipt, error) {
+	key := s.layout.receiptsKey(n, hash)
+
+	data, ok := s.receipts.get(key, nil)
+	if !ok && s.separated() {
+		// written before the receipts database was separated
+		data, ok = s.get(key, nil)
+	}
+
+	if !ok {
+		return []*types.Receipt{}, storage.ErrNotFound
+	}
+
+	receipts, err := decodeConsensusReceipts(data)
+	if err != nil {
+		return receipts, err
+	}
+
+	body, err := s.readConsensusBody(n, hash)
+	if err != nil {
+		return receipts, err
+	}
+
+	return receipts, deriveReceiptFields(receipts, body)
+}
+
+// WriteReceiptsBackfillHead writes the number of the last block checked for missing receipts
+func (s *KeyValueStorage) WriteReceiptsBackfillHead(n uint64) error {
+	return s.receipts.set(RECEIPTS, NUMBER, s.encodeUint(n))
+}
+
+// ReadReceiptsBackfillHead returns the number of the last block checked for missing receipts
+func (s *KeyValueStorage) ReadReceiptsBackfillHead() (uint64, bool) {
+	data, ok := s.getReceipts(RECEIPTS, NUMBER)
+	if !ok || len(data) != 8 {
+		return 0, false
+	}
+
+	return s.decodeUint(data), true
+}
+
 // TX LOOKUP //
 
 // WriteTxLookup maps the transaction hash to the block hash
@@ -243,14 +673,18 @@ func (s *KeyValueStorage) WriteTxLookup(hash types.Hash, blockHash types.Hash) e
 	ar := &fastrlp.Arena{}
 	vr := ar.NewBytes(blockHash.Bytes())
 
-	return s.write2(TX_LOOKUP_PREFIX, hash.Bytes(), vr)
+	return s.receipts.write2(TX_LOOKUP_PREFIX, hash.Bytes(), vr)
 }
 
 // ReadTxLookup reads the block hash using the transaction hash
 func (s *KeyValueStorage) ReadTxLookup(hash types.Hash) (types.Hash, bool) {
 	parser := &fastrlp.Parser{}
 
-	v := s.read2(TX_LOOKUP_PREFIX, hash.Bytes(), parser)
+	v := s.receipts.read2(TX_LOOKUP_PREFIX, hash.Bytes(), parser)
+	if v == nil && s.separated() {
+		v = s.read2(TX_LOOKUP_PREFIX, hash.Bytes(), parser)
+	}
+
 	if v == nil {
 		return types.Hash{}, false
 	}
@@ -265,6 +699,239 @@ func (s *KeyValueStorage) ReadTxLookup(hash types.Hash) (types.Hash, bool) {
 	return types.BytesToHash(blockHash), true
 }
 
+// DeleteTxLookup removes the transaction lookup
+func (s *KeyValueStorage) DeleteTxLookup(hash types.Hash) error {
+	if s.separated() {
+		// the lookup might be written before the receipts database was separated
+		if err := s.delete(TX_LOOKUP_PREFIX, hash.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return s.receipts.delete(TX_LOOKUP_PREFIX, hash.Bytes())
+}
+
+// WriteTxLookupTail writes the number of the oldest block with transaction lookups
+func (s *KeyValueStorage) WriteTxLookupTail(n uint64) error {
+	return s.receipts.set(TX_LOOKUP_PREFIX, NUMBER, s.encodeUint(n))
+}
+
+// ReadTxLookupTail returns the number of the oldest block with transaction lookups
+func (s *KeyValueStorage) ReadTxLookupTail() (uint64, bool) {
+	data, ok := s.getReceipts(TX_LOOKUP_PREFIX, NUMBER)
+	if !ok || len(data) != 8 {
+		return 0, false
+	}
+
+	return s.decodeUint(data), true
+}
+
+// LOG INDEX //
+
+// WriteLogIndex records the block number postings of the log addresses and topics
+func (s *KeyValueStorage) WriteLogIndex(n uint64, addresses []types.Address, topics []types.Hash) error {
+	for _, addr := range addresses {
+		if err := s.set(s.logIndexKey(ADDRESS, addr.Bytes()), s.encodeUint(n), []byte{}); err != nil {
+			return err
+		}
+	}
+
+	for _, topic := range topics {
+		if err := s.set(s.logIndexKey(TOPIC, topic.Bytes()), s.encodeUint(n), []byte{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadLogIndexByAddress returns the block numbers within [from, to] having logs of the address
+func (s *KeyValueStorage) ReadLogIndexByAddress(addr types.Address, from, to uint64) []uint64 {
+	return s.readPostings(s.logIndexKey(ADDRESS, addr.Bytes()), from, to)
+}
+
+// ReadLogIndexByTopic returns the block numbers within [from, to] having logs of the topic
+func (s *KeyValueStorage) ReadLogIndexByTopic(topic types.Hash, from, to uint64) []uint64 {
+	return s.readPostings(s.logIndexKey(TOPIC, topic.Bytes()), from, to)
+}
+
+// WriteLogIndexHead writes the number of the last indexed block
+func (s *KeyValueStorage) WriteLogIndexHead(n uint64) error {
+	return s.set(LOG_INDEX_PREFIX, NUMBER, s.encodeUint(n))
+}
+
+// ReadLogIndexHead returns the number of the last indexed block
+func (s *KeyValueStorage) ReadLogIndexHead() (uint64, bool) {
+	data, ok := s.get(LOG_INDEX_PREFIX, NUMBER)
+	if !ok || len(data) != 8 {
+		return 0, false
+	}
+
+	return s.decodeUint(data), true
+}
+
+func (s *KeyValueStorage) logIndexKey(subPrefix []byte, k []byte) []byte {
+	key := make([]byte, 0, len(LOG_INDEX_PREFIX)+len(subPrefix)+len(k))
+	key = append(key, LOG_INDEX_PREFIX...)
+	key = append(key, subPrefix...)
+
+	return append(key, k...)
+}
+
+// readPostings returns the block numbers within [from, to] recorded under the key
+func (s *KeyValueStorage) readPostings(key []byte, from, to uint64) []uint64 {
+	numbers := []uint64{}
+
+	if from > to {
+		return numbers
+	}
+
+	db, ok := s.db.(iterableKV)
+	if !ok {
+		// fallback to point lookups
+		for n := from; n >= from && n <= to; n++ {
+			if _, ok := s.get(key, s.encodeUint(n)); ok {
+				numbers = append(numbers, n)
+			}
+		}
+
+		return numbers
+	}
+
+	// the limit is exclusive, use the next key when it overflows
+	limit := append(append([]byte{}, key...), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00)
+	if to+1 > to {
+		limit = append(append([]byte{}, key...), s.encodeUint(to+1)...)
+	}
+
+	iter := db.Iterator(&kvdb.KVIteratorRange{
+		Start: append(append([]byte{}, key...), s.encodeUint(from)...),
+		Limit: limit,
+	})
+	defer iter.Release()
+
+	for iter.Next() {
+		k := iter.Key()
+		if len(k) != len(key)+8 {
+			continue
+		}
+
+		numbers = append(numbers, s.decodeUint(k[len(key):]))
+	}
+
+	return numbers
+}
+
+// BLOOM BITS //
+
+// WriteBloomBits writes the rotated bloom bit vector of the section
+func (s *KeyValueStorage) WriteBloomBits(bit uint, section uint64, bits []byte) error {
+	return s.set(BLOOM_BITS_PREFIX, s.bloomBitsKey(bit, section), bits)
+}
+
+// ReadBloomBits returns the rotated bloom bit vector of the section
+func (s *KeyValueStorage) ReadBloomBits(bit uint, section uint64) ([]byte, bool) {
+	return s.get(BLOOM_BITS_PREFIX, s.bloomBitsKey(bit, section))
+}
+
+// WriteBloomBitsSections writes the number of the indexed sections
+func (s *KeyValueStorage) WriteBloomBitsSections(sections uint64) error {
+	return s.set(BLOOM_BITS_PREFIX, SECTIONS, s.encodeUint(sections))
+}
+
+// ReadBloomBitsSections returns the number of the indexed sections
+func (s *KeyValueStorage) ReadBloomBitsSections() (uint64, bool) {
+	data, ok := s.get(BLOOM_BITS_PREFIX, SECTIONS)
+	if !ok || len(data) != 8 {
+		return 0, false
+	}
+
+	return s.decodeUint(data), true
+}
+
+func (s *KeyValueStorage) bloomBitsKey(bit uint, section uint64) []byte {
+	key := make([]byte, 10)
+	binary.BigEndian.PutUint16(key[:2], uint16(bit))
+	binary.BigEndian.PutUint64(key[2:], section)
+
+	return key
+}
+
+// CHAIN STATS //
+
+// WriteChainStats writes the aggregated usage of the canonical blocks within the hour
+func (s *KeyValueStorage) WriteChainStats(hour uint64, stats *storage.ChainStats) error {
+	return s.writeRLP(CHAIN_STATS_PREFIX, s.encodeUint(hour), stats)
+}
+
+// ReadChainStats returns the aggregated usage of the canonical blocks within the hour
+func (s *KeyValueStorage) ReadChainStats(hour uint64) (*storage.ChainStats, bool) {
+	stats := &storage.ChainStats{}
+	if err := s.readRLP(CHAIN_STATS_PREFIX, s.encodeUint(hour), stats); err != nil {
+		return nil, false
+	}
+
+	return stats, true
+}
+
+// WriteChainStatsHead writes the number of the last aggregated block
+func (s *KeyValueStorage) WriteChainStatsHead(n uint64) error {
+	return s.set(CHAIN_STATS_PREFIX, NUMBER, s.encodeUint(n))
+}
+
+// ReadChainStatsHead returns the number of the last aggregated block
+func (s *KeyValueStorage) ReadChainStatsHead() (uint64, bool) {
+	data, ok := s.get(CHAIN_STATS_PREFIX, NUMBER)
+	if !ok || len(data) != 8 {
+		return 0, false
+	}
+
+	return s.decodeUint(data), true
+}
+
+// SYSTEM TX INDEX //
+
+// WriteSystemTxIndex records the block number postings of the system transaction types
+func (s *KeyValueStorage) WriteSystemTxIndex(n uint64, txTypes []string) error {
+	for _, txType := range txTypes {
+		if err := s.set(s.systemTxIndexKey(txType), s.encodeUint(n), []byte{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadSystemTxIndex returns the block numbers within [from, to] having system transactions of the type
+func (s *KeyValueStorage) ReadSystemTxIndex(txType string, from, to uint64) []uint64 {
+	return s.readPostings(s.systemTxIndexKey(txType), from, to)
+}
+
+// WriteSystemTxIndexHead writes the number of the last indexed block
+func (s *KeyValueStorage) WriteSystemTxIndexHead(n uint64) error {
+	return s.set(SYSTEM_TX_INDEX_PREFIX, NUMBER, s.encodeUint(n))
+}
+
+// ReadSystemTxIndexHead returns the number of the last indexed block
+func (s *KeyValueStorage) ReadSystemTxIndexHead() (uint64, bool) {
+	data, ok := s.get(SYSTEM_TX_INDEX_PREFIX, NUMBER)
+	if !ok || len(data) != 8 {
+		return 0, false
+	}
+
+	return s.decodeUint(data), true
+}
+
+// systemTxIndexKey returns the postings key of the type, which is separated from the
+// number key of the index head
+func (s *KeyValueStorage) systemTxIndexKey(txType string) []byte {
+	key := make([]byte, 0, len(SYSTEM_TX_INDEX_PREFIX)+1+len(txType))
+	key = append(key, SYSTEM_TX_INDEX_PREFIX...)
+	key = append(key, byte(len(txType)))
+
+	return append(key, txType...)
+}
+
 // WRITE OPERATIONS //
 
 func (s *KeyValueStorage) writeRLP(p, k []byte, raw types.RLPMarshaler) error {
@@ -326,9 +993,23 @@ func (s *KeyValueStorage) write2(p, k []byte, v *fastrlp.Value) error {
 }
 
 func (s *KeyValueStorage) set(p []byte, k []byte, v []byte) error {
+	if s.readOnly {
+		return storage.ErrReadOnly
+	}
+
+	p = append(p, k...)
+
+	return s.writer.Set(p, v)
+}
+
+func (s *KeyValueStorage) delete(p []byte, k []byte) error {
+	if s.readOnly {
+		return storage.ErrReadOnly
+	}
+
 	p = append(p, k...)
 
-	return s.db.Set(p, v)
+	return s.writer.Delete(p)
 }
 
 func (s *KeyValueStorage) get(p []byte, k []byte) ([]byte, bool) {
@@ -342,7 +1023,135 @@ func (s *KeyValueStorage) get(p []byte, k []byte) ([]byte, bool) {
 	return data, ok
 }
 
+// getReceipts reads the key from the receipts database, or from the storage if it is
+// written before the receipts database was separated
+func (s *KeyValueStorage) getReceipts(p []byte, k []byte) ([]byte, bool) {
+	if data, ok := s.receipts.get(p, k); ok || !s.separated() {
+		return data, ok
+	}
+
+	return s.get(p, k)
+}
+
+// NewBatch returns the batch of the writes, which flushes itself once the queued
+// size reaches the ideal batch size
+func (s *KeyValueStorage) NewBatch() storage.Batch {
+	return s.newBatch(func(db KV) kvdb.KVBatch {
+		return kvdb.NewAutoFlushBatch(db.Batch(), s.idealBatchSize)
+	})
+}
+
+// NewAtomicBatch returns the batch of the writes committed at once. With a separate
+// receipts database, the writes are atomic within each database only.
+func (s *KeyValueStorage) NewAtomicBatch() storage.Batch {
+	return s.newBatch(func(db KV) kvdb.KVBatch {
+		return db.Batch()
+	})
+}
+
+func (s *KeyValueStorage) newBatch(newKVBatch func(db KV) kvdb.KVBatch) storage.Batch {
+	batch := newKVBatch(s.db)
+	writes := s.batchWrites(batch)
+
+	b := &keyValueBatch{
+		writes: writes,
+		batch:  batch,
+	}
+
+	if s.separated() {
+		b.receiptsBatch = newKVBatch(s.receipts.db)
+		writes.receipts = s.receipts.batchWrites(b.receiptsBatch)
+	}
+
+	return b
+}
+
+// batchWrites returns the storage writing to the batch
+func (s *KeyValueStorage) batchWrites(batch kvdb.KVBatch) *KeyValueStorage {
+	writes := &KeyValueStorage{
+		logger:         s.logger,
+		db:             s.db,
+		writer:         &batchWriter{batch: batch},
+		layout:         s.layout,
+		readOnly:       s.readOnly,
+		idealBatchSize: s.idealBatchSize,
+	}
+	writes.receipts = writes
+
+	return writes
+}
+
+// CompactionBacklog returns the number of the level 0 tables waiting for compaction,
+// and whether the writes are paused by the compaction. With a separate receipts
+// database, the larger backlog is returned, and the writes are paused if either
+// database pauses them.
+func (s *KeyValueStorage) CompactionBacklog() (int, bool) {
+	dbs := []KV{s.db}
+	if s.separated() {
+		dbs = append(dbs, s.receipts.db)
+	}
+
+	var (
+		backlog int
+		paused  bool
+	)
+
+	for _, db := range dbs {
+		stats, ok := db.(kvdb.KVCompactionStats)
+		if !ok {
+			continue
+		}
+
+		tables, writePaused := stats.CompactionBacklog()
+		if tables > backlog {
+			backlog = tables
+		}
+
+		paused = paused || writePaused
+	}
+
+	return backlog, paused
+}
+
+// Compact compacts the keys within [start, limit) of the database, if it compacts on
+// demand. The separate receipts database is compacted as well.
+func (s *KeyValueStorage) Compact(start, limit []byte) error {
+	compacter, ok := s.db.(kvdb.KVCompacter)
+	if !ok {
+		return kvdb.ErrCompactNotSupported
+	}
+
+	if err := compacter.Compact(start, limit); err != nil {
+		return err
+	}
+
+	if s.separated() {
+		if compacter, ok := s.receipts.db.(kvdb.KVCompacter); ok {
+			return compacter.Compact(start, limit)
+		}
+	}
+
+	return nil
+}
+
+// Property returns the named property of the database, if it reports any. The
+// separate receipts database is not reported.
+func (s *KeyValueStorage) Property(name string) (string, error) {
+	props, ok := s.db.(kvdb.KVProperties)
+	if !ok {
+		return "", kvdb.ErrPropertyNotSupported
+	}
+
+	return props.Property(name)
+}
+
 // Close closes the connection with the db
 func (s *KeyValueStorage) Close() error {
+	if s.separated() {
+		if err := s.receipts.db.Close(); err != nil {
+			s.logger.Error("failed to close receipts database", "err", err)
+		}
+	}
+
 	return s.db.Close()
 }
@@ -0,0 +1,269 @@
+//nolint:stylecheck
+package kvstorage
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// Keys of the geth rawdb schema
+var (
+	// GETH_HEADER_PREFIX is the prefix of the headers, keyed by number and hash
+	GETH_HEADER_PREFIX = []byte("h")
+
+	// GETH_TD_SUFFIX is the suffix of the total difficulties after the header key
+	GETH_TD_SUFFIX = []byte("t")
+
+	// GETH_HASH_SUFFIX is the suffix of the canonical hashes after the number
+	GETH_HASH_SUFFIX = []byte("n")
+
+	// GETH_NUMBER_PREFIX is the prefix of the block numbers, keyed by hash
+	GETH_NUMBER_PREFIX = []byte("H")
+
+	// GETH_BODY_PREFIX is the prefix of the bodies, keyed by number and hash
+	GETH_BODY_PREFIX = []byte("b")
+
+	// GETH_RECEIPTS_PREFIX is the prefix of the receipts, keyed by number and hash
+	GETH_RECEIPTS_PREFIX = []byte("r")
+
+	// GETH_HEAD_HEADER_KEY is the key of the head header hash
+	GETH_HEAD_HEADER_KEY = []byte("LastHeader")
+
+	// GETH_HEAD_BLOCK_KEY is the key of the head block hash
+	GETH_HEAD_BLOCK_KEY = []byte("LastBlock")
+
+	// GETH_SENDERS_PREFIX is the prefix of the transaction senders of the bodies, keyed
+	// by number and hash. It is not a geth key, the geth bodies leave the senders out.
+	GETH_SENDERS_PREFIX = []byte("dogechain-senders")
+
+	// LAYOUT is the sub-prefix of the key layout name
+	LAYOUT = []byte("layout")
+)
+
+// keyLayout maps the chain data to the database keys
+type keyLayout interface {
+	// numbered returns whether the block data is keyed by the block number, which is
+	// then looked up by hash for reading
+	numbered() bool
+
+	numberKey(hash types.Hash) []byte
+	headerKey(n uint64, hash types.Hash) []byte
+	difficultyKey(n uint64, hash types.Hash) []byte
+	bodyKey(n uint64, hash types.Hash) []byte
+	receiptsKey(n uint64, hash types.Hash) []byte
+	canonicalHashKey(n uint64) []byte
+
+	// consensusEncoded returns whether the values are encoded without the fields
+	// derived from the chain, like the go-ethereum rawdb does. The senders of the body
+	// transactions are then kept under the senders key, and the other receipt fields
+	// are derived from the body.
+	consensusEncoded() bool
+	sendersKey(n uint64, hash types.Hash) []byte
+
+	// canonicalPrefix returns the prefix of the canonical hashes if they are
+	// contiguous, or nil
+	canonicalPrefix() []byte
+
+	// headHashKeys returns the keys of the chain head hash, the first one is read
+	headHashKeys() [][]byte
+}
+
+func newKeyLayout(layout storage.KeyLayout) (keyLayout, error) {
+	switch layout {
+	case storage.DogechainKeyLayout:
+		return dogechainLayout{}, nil
+	case storage.GethKeyLayout:
+		return gethLayout{}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", storage.ErrUnknownKeyLayout, layout)
+	}
+}
+
+func concatKey(parts ...[]byte) []byte {
+	size := 0
+	for _, part := range parts {
+		size += len(part)
+	}
+
+	key := make([]byte, 0, size)
+	for _, part := range parts {
+		key = append(key, part...)
+	}
+
+	return key
+}
+
+func encodeBlockNumber(n uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, n)
+
+	return b
+}
+
+// dogechainLayout keys the block data by hash
+type dogechainLayout struct{}
+
+func (dogechainLayout) numbered() bool {
+	return false
+}
+
+func (dogechainLayout) numberKey(hash types.Hash) []byte {
+	return nil
+}
+
+func (dogechainLayout) headerKey(_ uint64, hash types.Hash) []byte {
+	return concatKey(HEADER, hash.Bytes())
+}
+
+func (dogechainLayout) difficultyKey(_ uint64, hash types.Hash) []byte {
+	return concatKey(DIFFICULTY, hash.Bytes())
+}
+
+func (dogechainLayout) bodyKey(_ uint64, hash types.Hash) []byte {
+	return concatKey(BODY, hash.Bytes())
+}
+
+func (dogechainLayout) receiptsKey(_ uint64, hash types.Hash) []byte {
+	return concatKey(RECEIPTS, hash.Bytes())
+}
+
+func (dogechainLayout) canonicalHashKey(n uint64) []byte {
+	return concatKey(CANONICAL, encodeBlockNumber(n))
+}
+
+func (dogechainLayout) consensusEncoded() bool {
+	return false
+}
+
+func (dogechainLayout) sendersKey(uint64, types.Hash) []byte {
+	// kept in the bodies
+	return nil
+}
+
+func (dogechainLayout) canonicalPrefix() []byte {
+	return CANONICAL
+}
+
+func (dogechainLayout) headHashKeys() [][]byte {
+	return [][]byte{concatKey(HEAD, HASH)}
+}
+
+// gethLayout keys and encodes the block data like the go-ethereum rawdb schema, so
+// that its database tools work on the data directory. The total difficulties are
+// rlp-encoded, the bodies leave the transaction senders out and the receipts are the
+// geth stored receipts, whose other fields are derived from the bodies on reading.
+type gethLayout struct{}
+
+func (gethLayout) numbered() bool {
+	return true
+}
+
+func (gethLayout) numberKey(hash types.Hash) []byte {
+	return concatKey(GETH_NUMBER_PREFIX, hash.Bytes())
+}
+
+func (gethLayout) headerKey(n uint64, hash types.Hash) []byte {
+	return concatKey(GETH_HEADER_PREFIX, encodeBlockNumber(n), hash.Bytes())
+}
+
+func (gethLayout) difficultyKey(n uint64, hash types.Hash) []byte {
+	return concatKey(GETH_HEADER_PREFIX, encodeBlockNumber(n), hash.Bytes(), GETH_TD_SUFFIX)
+}
+
+func (gethLayout) bodyKey(n uint64, hash types.Hash) []byte {
+	return concatKey(GETH_BODY_PREFIX, encodeBlockNumber(n), hash.Bytes())
+}
+
+func (gethLayout) receiptsKey(n uint64, hash types.Hash) []byte {
+	return concatKey(GETH_RECEIPTS_PREFIX, encodeBlockNumber(n), hash.Bytes())
+}
+
+func (gethLayout) canonicalHashKey(n uint64) []byte {
+	return concatKey(GETH_HEADER_PREFIX, encodeBlockNumber(n), GETH_HASH_SUFFIX)
+}
+
+func (gethLayout) consensusEncoded() bool {
+	return true
+}
+
+func (gethLayout) sendersKey(n uint64, hash types.Hash) []byte {
+	return concatKey(GETH_SENDERS_PREFIX, encodeBlockNumber(n), hash.Bytes())
+}
+
+func (gethLayout) canonicalPrefix() []byte {
+	// interleaved with the headers
+	return nil
+}
+
+func (gethLayout) headHashKeys() [][]byte {
+	return [][]byte{GETH_HEAD_BLOCK_KEY, GETH_HEAD_HEADER_KEY}
+}
+
+func layoutNameKey() []byte {
+	return concatKey(VERSION, LAYOUT)
+}
+
+// resolveKeyLayout returns the key layout of the storage. The layout is recorded
+// when the storage is created, a storage written before it uses the dogechain
+// layout. An empty layout opens the storage with the recorded one.
+func resolveKeyLayout(db KV, layout storage.KeyLayout, readOnly bool) (keyLayout, error) {
+	if layout != "" {
+		if _, err := newKeyLayout(layout); err != nil {
+			return nil, err
+		}
+	}
+
+	data, recorded, err := db.Get(layoutNameKey())
+	if err != nil {
+		return nil, err
+	}
+
+	current := storage.KeyLayout(data)
+
+	if !recorded {
+		fresh, err := isFreshStorage(db)
+		if err != nil {
+			return nil, err
+		}
+
+		current = storage.DogechainKeyLayout
+		if fresh && layout != "" {
+			current = layout
+		}
+	}
+
+	if layout != "" && layout != current {
+		return nil, fmt.Errorf("%w: requested %s, storage %s", storage.ErrKeyLayoutMismatch, layout, current)
+	}
+
+	l, err := newKeyLayout(current)
+	if err != nil {
+		return nil, err
+	}
+
+	if !recorded && !readOnly {
+		if err := db.Set(layoutNameKey(), []byte(current)); err != nil {
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// isFreshStorage returns whether no chain head is written in any layout
+func isFreshStorage(db KV) (bool, error) {
+	for _, l := range []keyLayout{dogechainLayout{}, gethLayout{}} {
+		for _, key := range l.headHashKeys() {
+			if _, ok, err := db.Get(key); err != nil {
+				return false, err
+			} else if ok {
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,150 @@
+package kvstorage
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/dogechain-lab/fastrlp"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyLayout_Geth(t *testing.T) {
+	db := newMemoryKV(t)
+
+	s, err := openKeyValueStorage(hclog.NewNullLogger(), db, kvStorageOptions{layout: storage.GethKeyLayout})
+	assert.NoError(t, err)
+
+	header := &types.Header{Number: 3, ExtraData: []byte{}}
+	header.ComputeHash()
+
+	to := types.StringToAddress("2")
+
+	tx := &types.Transaction{
+		Nonce:    1,
+		GasPrice: big.NewInt(1),
+		Gas:      21000,
+		To:       &to,
+		Value:    big.NewInt(0),
+		V:        big.NewInt(27),
+		R:        big.NewInt(1),
+		S:        big.NewInt(1),
+		From:     types.StringToAddress("1"),
+	}
+
+	receipt := &types.Receipt{
+		CumulativeGasUsed: 21000,
+		Logs:              []*types.Log{{Address: types.StringToAddress("2"), Topics: []types.Hash{{0x1}}}},
+	}
+	receipt.SetStatus(types.ReceiptSuccess)
+
+	assert.NoError(t, s.WriteCanonicalHeader(header, big.NewInt(10)))
+	assert.NoError(t, s.WriteBody(header.Number, header.Hash, &types.Body{Transactions: []*types.Transaction{tx}}))
+	assert.NoError(t, s.WriteReceipts(header.Number, header.Hash, []*types.Receipt{receipt}))
+
+	number := encodeBlockNumber(header.Number)
+
+	for _, key := range [][]byte{
+		concatKey([]byte("h"), number, header.Hash.Bytes()),
+		concatKey([]byte("h"), number, header.Hash.Bytes(), []byte("t")),
+		concatKey([]byte("h"), number, []byte("n")),
+		concatKey([]byte("H"), header.Hash.Bytes()),
+		concatKey([]byte("b"), number, header.Hash.Bytes()),
+		concatKey([]byte("r"), number, header.Hash.Bytes()),
+		[]byte("LastBlock"),
+		[]byte("LastHeader"),
+	} {
+		_, ok, _ := db.Get(key)
+		assert.True(t, ok, "key %q", key)
+	}
+
+	_, ok, _ := db.Get(concatKey(HEADER, header.Hash.Bytes()))
+	assert.False(t, ok)
+
+	// the values are encoded like go-ethereum
+	parse := func(key []byte) *fastrlp.Value {
+		data, _, _ := db.Get(key)
+
+		v, err := (&fastrlp.Parser{}).Parse(data)
+		assert.NoError(t, err)
+
+		return v
+	}
+
+	diff := new(big.Int)
+	assert.NoError(t, parse(concatKey([]byte("h"), number, header.Hash.Bytes(), []byte("t"))).GetBigInt(diff))
+	assert.Equal(t, big.NewInt(10), diff)
+
+	// the transactions without the senders, and no uncles
+	body := parse(concatKey([]byte("b"), number, header.Hash.Bytes()))
+	assert.Equal(t, 2, body.Elems())
+	assert.Equal(t, tx.MarshalRLP(), body.Get(0).Get(0).MarshalTo(nil))
+	assert.Equal(t, 0, body.Get(1).Elems())
+
+	// the status, the cumulative gas used and the logs
+	receipts := parse(concatKey([]byte("r"), number, header.Hash.Bytes()))
+	assert.Equal(t, 1, receipts.Elems())
+	assert.Equal(t, 3, receipts.Get(0).Elems())
+
+	found, err := s.ReadHeader(header.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, header.Hash, found.Hash)
+
+	_, err = s.ReadHeader(types.StringToHash("1"))
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	td, ok := s.ReadTotalDifficulty(header.Hash)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(10), td)
+
+	foundBody, err := s.ReadBody(header.Hash)
+	assert.NoError(t, err)
+	assert.Len(t, foundBody.Transactions, 1)
+	assert.Equal(t, tx.Hash(), foundBody.Transactions[0].Hash())
+	assert.Equal(t, tx.From, foundBody.Transactions[0].From)
+
+	// the context fields are derived from the body
+	foundReceipts, err := s.ReadReceipts(header.Hash)
+	assert.NoError(t, err)
+	assert.Len(t, foundReceipts, 1)
+	assert.Equal(t, tx.Hash(), foundReceipts[0].TxHash)
+	assert.Equal(t, uint64(21000), foundReceipts[0].GasUsed)
+	assert.Equal(t, types.CreateBloom([]*types.Receipt{receipt}), foundReceipts[0].LogsBloom)
+	assert.Nil(t, foundReceipts[0].ContractAddress)
+}
+
+func TestKeyLayout_Recorded(t *testing.T) {
+	logger := hclog.NewNullLogger()
+	db := newMemoryKV(t)
+
+	_, err := openKeyValueStorage(logger, db, kvStorageOptions{layout: storage.GethKeyLayout})
+	assert.NoError(t, err)
+
+	// reopened with the recorded layout
+	s, err := openKeyValueStorage(logger, db, kvStorageOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, gethLayout{}, s.(*KeyValueStorage).layout)
+
+	_, err = openKeyValueStorage(logger, db, kvStorageOptions{layout: storage.DogechainKeyLayout})
+	assert.ErrorIs(t, err, storage.ErrKeyLayoutMismatch)
+}
+
+func TestKeyLayout_Legacy(t *testing.T) {
+	logger := hclog.NewNullLogger()
+
+	// a storage written before the layout is recorded
+	db := newMemoryKV(t)
+	assert.NoError(t, db.Set(concatKey(HEAD, HASH), types.StringToHash("1").Bytes()))
+
+	_, err := openKeyValueStorage(logger, db, kvStorageOptions{layout: storage.GethKeyLayout})
+	assert.ErrorIs(t, err, storage.ErrKeyLayoutMismatch)
+
+	s, err := openKeyValueStorage(logger, db, kvStorageOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, dogechainLayout{}, s.(*KeyValueStorage).layout)
+
+	_, err = openKeyValueStorage(logger, db, kvStorageOptions{layout: "rocks"})
+	assert.ErrorIs(t, err, storage.ErrUnknownKeyLayout)
+}
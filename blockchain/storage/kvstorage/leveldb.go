@@ -9,15 +9,72 @@ import (
 type leveldbStorageBuilder struct {
 	logger         hclog.Logger
 	leveldbBuilder kvdb.LevelDBBuilder
+	receiptsDB     kvdb.LevelDBBuilder // separate database of the receipts and transaction lookups, if any
+	readOnly       bool
+	idealBatchSize int
+	keyLayout      storage.KeyLayout
+}
+
+func (builder *leveldbStorageBuilder) SetReadOnly(readOnly bool) storage.StorageBuilder {
+	builder.readOnly = readOnly
+	// the database is attached without the file lock on building, see open, so that
+	// it could be read while a running node holds it
+	builder.leveldbBuilder.SetReadOnly(readOnly)
+
+	if builder.receiptsDB != nil {
+		builder.receiptsDB.SetReadOnly(readOnly)
+	}
+
+	return builder
+}
+
+func (builder *leveldbStorageBuilder) IsReadOnly() bool {
+	return builder.readOnly
+}
+
+func (builder *leveldbStorageBuilder) SetIdealBatchSize(size int) storage.StorageBuilder {
+	builder.idealBatchSize = size
+
+	return builder
+}
+
+func (builder *leveldbStorageBuilder) SetKeyLayout(layout storage.KeyLayout) storage.StorageBuilder {
+	builder.keyLayout = layout
+
+	return builder
 }
 
 func (builder *leveldbStorageBuilder) Build() (storage.Storage, error) {
-	db, err := builder.leveldbBuilder.Build()
+	db, err := builder.open(builder.leveldbBuilder)
 	if err != nil {
 		return nil, err
 	}
 
-	return newKeyValueStorage(builder.logger.Named("leveldb"), db), nil
+	var receiptsDB KV
+
+	if builder.receiptsDB != nil {
+		if receiptsDB, err = builder.open(builder.receiptsDB); err != nil {
+			db.Close()
+
+			return nil, err
+		}
+	}
+
+	return openKeyValueStorage(builder.logger.Named("leveldb"), db, kvStorageOptions{
+		readOnly:       builder.readOnly,
+		idealBatchSize: builder.idealBatchSize,
+		layout:         builder.keyLayout,
+		receiptsDB:     receiptsDB,
+	})
+}
+
+func (builder *leveldbStorageBuilder) open(leveldbBuilder kvdb.LevelDBBuilder) (KV, error) {
+	if builder.readOnly {
+		// attach without the file lock, the database might be in use by a live node
+		return leveldbBuilder.OpenReadOnly()
+	}
+
+	return leveldbBuilder.Build()
 }
 
 // NewLevelDBStorageBuilder creates the new blockchain storage builder
@@ -27,3 +84,19 @@ func NewLevelDBStorageBuilder(logger hclog.Logger, leveldbBuilder kvdb.LevelDBBu
 		leveldbBuilder: leveldbBuilder,
 	}
 }
+
+// NewLevelDBStorageBuilderWithReceipts creates the new blockchain storage builder, which
+// keeps the receipts and transaction lookups in a separate database, so that their
+// compactions do not stall the reads of the chain data. The receipts written before
+// are still read from the blockchain database.
+func NewLevelDBStorageBuilderWithReceipts(
+	logger hclog.Logger,
+	leveldbBuilder kvdb.LevelDBBuilder,
+	receiptsBuilder kvdb.LevelDBBuilder,
+) storage.StorageBuilder {
+	return &leveldbStorageBuilder{
+		logger:         logger,
+		leveldbBuilder: leveldbBuilder,
+		receiptsDB:     receiptsBuilder,
+	}
+}
@@ -1,18 +1,20 @@
 package kvstorage
 
 import (
+	"errors"
 	"os"
 	"testing"
 
 	"github.com/dogechain-lab/dogechain/blockchain/storage"
 	"github.com/dogechain-lab/dogechain/helper/kvdb"
+	"github.com/dogechain-lab/dogechain/types"
 	"github.com/hashicorp/go-hclog"
 )
 
 func newLevelDBStorage(t *testing.T) (storage.Storage, func()) {
 	t.Helper()
 
-	path, err := os.MkdirTemp("/tmp", "minimal_storage")
+	path, err := os.MkdirTemp("", "minimal_storage")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -41,3 +43,92 @@ func newLevelDBStorage(t *testing.T) (storage.Storage, func()) {
 func TestLevelDBStorage(t *testing.T) {
 	storage.TestStorage(t, newLevelDBStorage)
 }
+
+func TestLevelDBStorageReadOnly(t *testing.T) {
+	path, err := os.MkdirTemp("", "minimal_storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(path)
+
+	logger := hclog.NewNullLogger()
+
+	s, err := NewLevelDBStorageBuilder(logger, kvdb.NewLevelDBBuilder(logger, path)).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := types.StringToHash("1")
+
+	if err := s.WriteCanonicalHash(1, hash); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	builder := NewLevelDBStorageBuilder(logger, kvdb.NewLevelDBBuilder(logger, path)).SetReadOnly(true)
+	if !builder.IsReadOnly() {
+		t.Fatal("expected read-only builder")
+	}
+
+	// read-only databases could be opened by multiple readers, without the file lock
+	readers := make([]storage.Storage, 2)
+
+	for i := range readers {
+		readers[i], err = builder.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer readers[i].Close()
+	}
+
+	for _, r := range readers {
+		if got, ok := r.ReadCanonicalHash(1); !ok || got != hash {
+			t.Fatalf("expected hash %s, but got %s", hash, got)
+		}
+
+		if err := r.WriteCanonicalHash(2, hash); !errors.Is(err, storage.ErrReadOnly) {
+			t.Fatalf("expected %v, but got %v", storage.ErrReadOnly, err)
+		}
+	}
+}
+
+func TestLevelDBStorageReadOnly_LiveNode(t *testing.T) {
+	path, err := os.MkdirTemp("", "minimal_storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(path)
+
+	logger := hclog.NewNullLogger()
+
+	// the running node holds the file lock
+	s, err := NewLevelDBStorageBuilder(logger, kvdb.NewLevelDBBuilder(logger, path)).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer s.Close()
+
+	hash := types.StringToHash("1")
+
+	if err := s.WriteCanonicalHash(1, hash); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewLevelDBStorageBuilder(logger, kvdb.NewLevelDBBuilder(logger, path)).SetReadOnly(true).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer r.Close()
+
+	if got, ok := r.ReadCanonicalHash(1); !ok || got != hash {
+		t.Fatalf("expected hash %s, but got %s", hash, got)
+	}
+}
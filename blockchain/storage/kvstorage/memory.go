@@ -2,47 +2,56 @@ package kvstorage
 
 import (
 	"github.com/dogechain-lab/dogechain/blockchain/storage"
-	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
 	"github.com/hashicorp/go-hclog"
 )
 
 type memoryStorageBuilder struct {
-	logger hclog.Logger
+	logger         hclog.Logger
+	readOnly       bool
+	idealBatchSize int
+	keyLayout      storage.KeyLayout
 }
 
-func (builder *memoryStorageBuilder) Build() (storage.Storage, error) {
-	db := &memoryKV{map[string][]byte{}}
+func (builder *memoryStorageBuilder) SetReadOnly(readOnly bool) storage.StorageBuilder {
+	builder.readOnly = readOnly
 
-	return newKeyValueStorage(builder.logger, db), nil
+	return builder
 }
 
-// NewMemoryStorageBuilder creates the new blockchain storage builder
-func NewMemoryStorageBuilder(logger hclog.Logger) storage.StorageBuilder {
-	return &memoryStorageBuilder{
-		logger: logger,
-	}
+func (builder *memoryStorageBuilder) IsReadOnly() bool {
+	return builder.readOnly
 }
 
-// memoryKV is an in memory implementation of the kv storage
-type memoryKV struct {
-	db map[string][]byte
+func (builder *memoryStorageBuilder) SetIdealBatchSize(size int) storage.StorageBuilder {
+	builder.idealBatchSize = size
+
+	return builder
 }
 
-func (m *memoryKV) Set(p []byte, v []byte) error {
-	m.db[hex.EncodeToHex(p)] = v
+func (builder *memoryStorageBuilder) SetKeyLayout(layout storage.KeyLayout) storage.StorageBuilder {
+	builder.keyLayout = layout
 
-	return nil
+	return builder
 }
 
-func (m *memoryKV) Get(p []byte) ([]byte, bool, error) {
-	v, ok := m.db[hex.EncodeToHex(p)]
-	if !ok {
-		return nil, false, nil
+func (builder *memoryStorageBuilder) Build() (storage.Storage, error) {
+	db, err := kvdb.NewMemoryDB()
+	if err != nil {
+		return nil, err
 	}
 
-	return v, true, nil
+	return openKeyValueStorage(builder.logger, db, kvStorageOptions{
+		readOnly:       builder.readOnly,
+		idealBatchSize: builder.idealBatchSize,
+		layout:         builder.keyLayout,
+	})
 }
 
-func (m *memoryKV) Close() error {
-	return nil
+// NewMemoryStorageBuilder creates the blockchain storage builder of a new in memory
+// database on every build, so that a blockchain runs without touching the filesystem
+func NewMemoryStorageBuilder(logger hclog.Logger) storage.StorageBuilder {
+	return &memoryStorageBuilder{
+		logger: logger,
+	}
 }
@@ -4,9 +4,34 @@ import (
 	"testing"
 
 	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
 	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
 )
 
+// reopenableKV is kept open when a storage of it is closed, so that it is opened again
+type reopenableKV struct {
+	KV
+}
+
+func (reopenableKV) Close() error {
+	return nil
+}
+
+// newMemoryKV returns a new in memory database, which is closed once the test ends
+func newMemoryKV(t *testing.T) KV {
+	t.Helper()
+
+	db, err := kvdb.NewMemoryDB()
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return reopenableKV{db}
+}
+
 func TestMemoryStorage(t *testing.T) {
 	t.Helper()
 
@@ -15,7 +40,26 @@ func TestMemoryStorage(t *testing.T) {
 
 		s, _ := NewMemoryStorageBuilder(hclog.NewNullLogger()).Build()
 
-		return s, func() {}
+		return s, func() {
+			s.Close()
+		}
+	}
+	storage.TestStorage(t, f)
+}
+
+func TestMemoryStorage_GethKeyLayout(t *testing.T) {
+	t.Helper()
+
+	f := func(t *testing.T) (storage.Storage, func()) {
+		t.Helper()
+
+		s, _ := NewMemoryStorageBuilder(hclog.NewNullLogger()).
+			SetKeyLayout(storage.GethKeyLayout).
+			Build()
+
+		return s, func() {
+			s.Close()
+		}
 	}
 	storage.TestStorage(t, f)
 }
@@ -0,0 +1,33 @@
+package kvstorage
+
+import (
+	"errors"
+)
+
+// SEPARATED is the marker value of a storage with a separate receipts database
+var SEPARATED = []byte("separated")
+
+var ErrReceiptsDBRequired = errors.New("receipts are kept in a separate database, open the storage with it")
+
+func receiptsDBKey() []byte {
+	return concatKey(VERSION, RECEIPTS)
+}
+
+// checkReceiptsDB marks the storage once it is opened with a separate receipts database,
+// and refuses to open a marked storage without it, as the receipts and transaction
+// lookups written since would be missing
+func checkReceiptsDB(db KV, separated bool, readOnly bool) error {
+	_, marked, err := db.Get(receiptsDBKey())
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case marked && !separated:
+		return ErrReceiptsDBRequired
+	case !marked && separated && !readOnly:
+		return db.Set(receiptsDBKey(), SEPARATED)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package kvstorage
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceiptsDBStorage(t *testing.T) {
+	t.Helper()
+
+	f := func(t *testing.T) (storage.Storage, func()) {
+		t.Helper()
+
+		s, err := openKeyValueStorage(hclog.NewNullLogger(), newMemoryKV(t), kvStorageOptions{
+			receiptsDB: newMemoryKV(t),
+		})
+		assert.NoError(t, err)
+
+		return s, func() {
+			s.Close()
+		}
+	}
+	storage.TestStorage(t, f)
+}
+
+func TestReceiptsDB_Separated(t *testing.T) {
+	logger := hclog.NewNullLogger()
+	db, receiptsDB := newMemoryKV(t), newMemoryKV(t)
+
+	writeBlock := func(s storage.Storage, n uint64) (types.Hash, types.Hash) {
+		header := &types.Header{Number: n, ExtraData: []byte{}}
+		header.ComputeHash()
+
+		txHash := types.BytesToHash(big.NewInt(int64(n)).Bytes())
+		receipts := []*types.Receipt{{TxHash: txHash, CumulativeGasUsed: n, Logs: []*types.Log{}}}
+
+		assert.NoError(t, s.WriteCanonicalHeader(header, big.NewInt(int64(n))))
+		assert.NoError(t, s.WriteReceipts(n, header.Hash, receipts))
+		assert.NoError(t, s.WriteTxLookup(txHash, header.Hash))
+
+		return header.Hash, txHash
+	}
+
+	assertBlock := func(s storage.Storage, hash, txHash types.Hash) {
+		receipts, err := s.ReadReceipts(hash)
+		assert.NoError(t, err)
+		assert.Len(t, receipts, 1)
+		assert.Equal(t, txHash, receipts[0].TxHash)
+
+		found, ok := s.ReadTxLookup(txHash)
+		assert.True(t, ok)
+		assert.Equal(t, hash, found)
+	}
+
+	// written before the receipts database is separated
+	s, err := openKeyValueStorage(logger, db, kvStorageOptions{})
+	assert.NoError(t, err)
+
+	oldHash, oldTxHash := writeBlock(s, 1)
+
+	s, err = openKeyValueStorage(logger, db, kvStorageOptions{receiptsDB: receiptsDB})
+	assert.NoError(t, err)
+
+	assertBlock(s, oldHash, oldTxHash)
+
+	newHash, newTxHash := writeBlock(s, 2)
+	assertBlock(s, newHash, newTxHash)
+
+	_, ok, _ := db.Get(concatKey(TX_LOOKUP_PREFIX, newTxHash.Bytes()))
+	assert.False(t, ok)
+
+	_, ok, _ = receiptsDB.Get(concatKey(TX_LOOKUP_PREFIX, newTxHash.Bytes()))
+	assert.True(t, ok)
+
+	// the batch writes to both databases
+	batch := s.NewAtomicBatch()
+
+	batchTxHash := types.StringToHash("3")
+	assert.NoError(t, batch.WriteTxLookup(batchTxHash, newHash))
+	assert.NoError(t, batch.DeleteTxLookup(oldTxHash))
+	assert.NoError(t, batch.Write())
+
+	_, ok, _ = receiptsDB.Get(concatKey(TX_LOOKUP_PREFIX, batchTxHash.Bytes()))
+	assert.True(t, ok)
+
+	_, ok = s.ReadTxLookup(oldTxHash)
+	assert.False(t, ok)
+
+	// the receipts written since are missing without the receipts database
+	_, err = openKeyValueStorage(logger, db, kvStorageOptions{})
+	assert.ErrorIs(t, err, ErrReceiptsDBRequired)
+}
+
+// compactingKV reports a fixed compaction backlog
+type compactingKV struct {
+	KV
+
+	tables int
+	paused bool
+}
+
+func (kv *compactingKV) CompactionBacklog() (int, bool) {
+	return kv.tables, kv.paused
+}
+
+func TestReceiptsDB_CompactionBacklog(t *testing.T) {
+	logger := hclog.NewNullLogger()
+
+	db := &compactingKV{KV: newMemoryKV(t), tables: 4}
+	receiptsDB := &compactingKV{KV: newMemoryKV(t), tables: 12}
+
+	s, err := openKeyValueStorage(logger, db, kvStorageOptions{receiptsDB: receiptsDB})
+	assert.NoError(t, err)
+
+	stats, ok := s.(*KeyValueStorage)
+	assert.True(t, ok)
+
+	// the larger backlog of the databases, the writes are not paused
+	tables, paused := stats.CompactionBacklog()
+	assert.Equal(t, 12, tables)
+	assert.False(t, paused)
+
+	// paused by either database
+	db.paused = true
+
+	tables, paused = stats.CompactionBacklog()
+	assert.Equal(t, 12, tables)
+	assert.True(t, paused)
+}
@@ -0,0 +1,292 @@
+//nolint:stylecheck
+package kvstorage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+var (
+	// VERSION is the prefix of the versions of the storage
+	VERSION = []byte("v")
+
+	// MIGRATION_BACKUP_PREFIX is the prefix of the previous values of the keys touched
+	// by the running schema migration
+	MIGRATION_BACKUP_PREFIX = []byte("x")
+
+	// SCHEMA is the sub-prefix of the key layout version
+	SCHEMA = []byte("schema")
+)
+
+// baseSchemaVersion is the version of the databases written before the schema version
+const baseSchemaVersion uint64 = 1
+
+var (
+	ErrSchemaTooNew    = errors.New("database schema is newer than supported, upgrade the node")
+	ErrSchemaOutdated  = errors.New("database schema is outdated, open it writable to migrate")
+	ErrInvalidBackup   = errors.New("invalid schema migration backup")
+	errInvalidRegistry = errors.New("schema migrations out of order")
+)
+
+// schemaMigration upgrades the key layout of the storage to its version, from the
+// version before it
+type schemaMigration struct {
+	version uint64
+	name    string
+	migrate func(db *migrationWriter) error
+}
+
+// schemaMigrations are the registered migrations in the order of their versions. A
+// key layout change appends its migration here, which bumps the schema version.
+var schemaMigrations = []*schemaMigration{}
+
+// SchemaVersion returns the key layout version written by the node
+func SchemaVersion() uint64 {
+	return latestSchemaVersion(schemaMigrations)
+}
+
+func latestSchemaVersion(migrations []*schemaMigration) uint64 {
+	if len(migrations) == 0 {
+		return baseSchemaVersion
+	}
+
+	return migrations[len(migrations)-1].version
+}
+
+// migrateSchema upgrades the storage to the latest version of the migrations. The
+// keys touched by a migration are backed up first, so an interrupted migration is
+// rolled back and run again on the next startup.
+func migrateSchema(logger hclog.Logger, db KV, readOnly bool, migrations []*schemaMigration) error {
+	for i, m := range migrations {
+		if (i == 0 && m.version <= baseSchemaVersion) || (i > 0 && m.version != migrations[i-1].version+1) {
+			return fmt.Errorf("%w: %s at version %d", errInvalidRegistry, m.name, m.version)
+		}
+	}
+
+	latest := latestSchemaVersion(migrations)
+
+	version, stamped, err := readSchemaVersion(db, latest)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case version > latest:
+		return fmt.Errorf("%w: version %d, supported %d", ErrSchemaTooNew, version, latest)
+	case version == latest:
+		if stamped || readOnly {
+			return nil
+		}
+
+		return db.Set(schemaVersionKey(), encodeSchemaVersion(latest))
+	case readOnly:
+		return fmt.Errorf("%w: version %d, latest %d", ErrSchemaOutdated, version, latest)
+	}
+
+	if err := restoreMigrationBackup(logger, db); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+
+		logger.Info("migrate database schema", "version", m.version, "migration", m.name)
+
+		w := &migrationWriter{db: db, touched: map[string]struct{}{}}
+		if err := m.migrate(w); err != nil {
+			return fmt.Errorf("failed to migrate database schema to version %d: %w", m.version, err)
+		}
+
+		// the version is bumped with the backup dropped, never one without the other
+		batch := db.Batch()
+		batch.Set(schemaVersionKey(), encodeSchemaVersion(m.version))
+
+		for i := uint64(0); i < w.backups; i++ {
+			batch.Delete(migrationBackupKey(i))
+		}
+
+		if err := batch.Write(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readSchemaVersion returns the schema version of the storage, and whether it is
+// written. A new storage is at the latest version, as there is nothing to migrate.
+func readSchemaVersion(db KV, latest uint64) (uint64, bool, error) {
+	data, ok, err := db.Get(schemaVersionKey())
+	if err != nil {
+		return 0, false, err
+	}
+
+	if ok {
+		if len(data) != 8 {
+			return 0, false, fmt.Errorf("invalid schema version %x", data)
+		}
+
+		return binary.BigEndian.Uint64(data), true, nil
+	}
+
+	headKey := make([]byte, 0, len(HEAD)+len(HASH))
+	headKey = append(append(headKey, HEAD...), HASH...)
+
+	if _, ok, err := db.Get(headKey); err != nil {
+		return 0, false, err
+	} else if ok {
+		return baseSchemaVersion, false, nil
+	}
+
+	return latest, false, nil
+}
+
+func schemaVersionKey() []byte {
+	key := make([]byte, 0, len(VERSION)+len(SCHEMA))
+
+	return append(append(key, VERSION...), SCHEMA...)
+}
+
+func encodeSchemaVersion(version uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, version)
+
+	return b
+}
+
+func migrationBackupKey(i uint64) []byte {
+	key := make([]byte, len(MIGRATION_BACKUP_PREFIX)+8)
+	copy(key, MIGRATION_BACKUP_PREFIX)
+	binary.BigEndian.PutUint64(key[len(MIGRATION_BACKUP_PREFIX):], i)
+
+	return key
+}
+
+// restoreMigrationBackup rolls back the keys touched by an interrupted migration.
+// Restoring is idempotent, so the backup is only dropped once all are restored.
+func restoreMigrationBackup(logger hclog.Logger, db KV) error {
+	var count uint64
+
+	for ; ; count++ {
+		data, ok, err := db.Get(migrationBackupKey(count))
+		if err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+
+		key, value, existed, err := decodeMigrationBackup(data)
+		if err != nil {
+			return err
+		}
+
+		if existed {
+			err = db.Set(key, value)
+		} else {
+			err = db.Delete(key)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	logger.Warn("rolled back interrupted database schema migration", "keys", count)
+
+	batch := db.Batch()
+	for i := uint64(0); i < count; i++ {
+		batch.Delete(migrationBackupKey(i))
+	}
+
+	return batch.Write()
+}
+
+// encodeMigrationBackup encodes the previous value of the key, in the form of
+// key length (4 bytes) | key | existed (1 byte) | value
+func encodeMigrationBackup(key, value []byte, existed bool) []byte {
+	data := make([]byte, 4, 4+len(key)+1+len(value))
+	binary.BigEndian.PutUint32(data, uint32(len(key)))
+
+	data = append(data, key...)
+
+	if existed {
+		data = append(data, 1)
+	} else {
+		data = append(data, 0)
+	}
+
+	return append(data, value...)
+}
+
+func decodeMigrationBackup(data []byte) ([]byte, []byte, bool, error) {
+	if len(data) < 5 {
+		return nil, nil, false, ErrInvalidBackup
+	}
+
+	size := binary.BigEndian.Uint32(data)
+	if uint64(len(data)) < 4+uint64(size)+1 {
+		return nil, nil, false, ErrInvalidBackup
+	}
+
+	key := data[4 : 4+size]
+	existed := data[4+size] == 1
+
+	return key, data[4+size+1:], existed, nil
+}
+
+// migrationWriter is the storage seen by a migration. The previous value of a key
+// is backed up before it is first changed.
+type migrationWriter struct {
+	db      KV
+	touched map[string]struct{}
+	backups uint64
+}
+
+func (w *migrationWriter) Get(key []byte) ([]byte, bool, error) {
+	return w.db.Get(key)
+}
+
+func (w *migrationWriter) Set(key []byte, value []byte) error {
+	if err := w.backup(key); err != nil {
+		return err
+	}
+
+	return w.db.Set(key, value)
+}
+
+func (w *migrationWriter) Delete(key []byte) error {
+	if err := w.backup(key); err != nil {
+		return err
+	}
+
+	return w.db.Delete(key)
+}
+
+func (w *migrationWriter) backup(key []byte) error {
+	if _, ok := w.touched[string(key)]; ok {
+		return nil
+	}
+
+	value, existed, err := w.db.Get(key)
+	if err != nil {
+		return err
+	}
+
+	if err := w.db.Set(migrationBackupKey(w.backups), encodeMigrationBackup(key, value, existed)); err != nil {
+		return err
+	}
+
+	w.touched[string(key)] = struct{}{}
+	w.backups++
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package kvstorage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateSchema_NewStorage(t *testing.T) {
+	db := newMemoryKV(t)
+
+	assert.NoError(t, migrateSchema(hclog.NewNullLogger(), db, false, schemaMigrations))
+
+	version, stamped, err := readSchemaVersion(db, 0)
+	assert.NoError(t, err)
+	assert.True(t, stamped)
+	assert.Equal(t, SchemaVersion(), version)
+}
+
+func TestMigrateSchema(t *testing.T) {
+	var (
+		logger = hclog.NewNullLogger()
+		ran    = []uint64{}
+	)
+
+	migrations := []*schemaMigration{
+		{
+			version: 2,
+			name:    "rename",
+			migrate: func(db *migrationWriter) error {
+				ran = append(ran, 2)
+
+				value, _, err := db.Get([]byte("old"))
+				if err != nil {
+					return err
+				}
+
+				if err := db.Set([]byte("new"), value); err != nil {
+					return err
+				}
+
+				return db.Delete([]byte("old"))
+			},
+		},
+		{
+			version: 3,
+			name:    "overwrite",
+			migrate: func(db *migrationWriter) error {
+				ran = append(ran, 3)
+
+				return db.Set([]byte("new"), []byte("3"))
+			},
+		},
+	}
+
+	// a storage written before the schema version
+	db := newMemoryKV(t)
+	assert.NoError(t, db.Set(append(HEAD, HASH...), []byte{1}))
+	assert.NoError(t, db.Set([]byte("old"), []byte("1")))
+
+	assert.ErrorIs(t, migrateSchema(logger, db, true, migrations), ErrSchemaOutdated)
+	assert.Empty(t, ran)
+
+	assert.NoError(t, migrateSchema(logger, db, false, migrations))
+	assert.Equal(t, []uint64{2, 3}, ran)
+
+	value, ok, _ := db.Get([]byte("new"))
+	assert.True(t, ok)
+	assert.Equal(t, []byte("3"), value)
+
+	_, ok, _ = db.Get([]byte("old"))
+	assert.False(t, ok)
+
+	// the backup is dropped
+	_, ok, _ = db.Get(migrationBackupKey(0))
+	assert.False(t, ok)
+
+	// migrated once
+	assert.NoError(t, migrateSchema(logger, db, false, migrations))
+	assert.Equal(t, []uint64{2, 3}, ran)
+
+	// the node is older than the storage
+	assert.ErrorIs(t, migrateSchema(logger, db, false, migrations[:1]), ErrSchemaTooNew)
+}
+
+func TestMigrateSchema_Interrupted(t *testing.T) {
+	var (
+		logger  = hclog.NewNullLogger()
+		errStop = errors.New("stop")
+		fail    = true
+	)
+
+	migrations := []*schemaMigration{
+		{
+			version: 2,
+			name:    "interrupted",
+			migrate: func(db *migrationWriter) error {
+				if err := db.Set([]byte("a"), []byte("2")); err != nil {
+					return err
+				}
+
+				if err := db.Set([]byte("a"), []byte("3")); err != nil {
+					return err
+				}
+
+				if err := db.Delete([]byte("b")); err != nil {
+					return err
+				}
+
+				if err := db.Set([]byte("c"), []byte("2")); err != nil {
+					return err
+				}
+
+				if fail {
+					return errStop
+				}
+
+				return nil
+			},
+		},
+	}
+
+	db := newMemoryKV(t)
+	assert.NoError(t, db.Set(append(HEAD, HASH...), []byte{1}))
+	assert.NoError(t, db.Set([]byte("a"), []byte("1")))
+	assert.NoError(t, db.Set([]byte("b"), []byte("1")))
+
+	assert.ErrorIs(t, migrateSchema(logger, db, false, migrations), errStop)
+
+	// the touched keys are backed up once
+	_, ok, _ := db.Get(migrationBackupKey(2))
+	assert.True(t, ok)
+
+	_, ok, _ = db.Get(migrationBackupKey(3))
+	assert.False(t, ok)
+
+	// rolled back before migrated again
+	assert.NoError(t, restoreMigrationBackup(logger, db))
+
+	value, _, _ := db.Get([]byte("a"))
+	assert.Equal(t, []byte("1"), value)
+
+	value, _, _ = db.Get([]byte("b"))
+	assert.Equal(t, []byte("1"), value)
+
+	_, ok, _ = db.Get([]byte("c"))
+	assert.False(t, ok)
+
+	_, ok, _ = db.Get(migrationBackupKey(0))
+	assert.False(t, ok)
+
+	fail = false
+
+	assert.NoError(t, migrateSchema(logger, db, false, migrations))
+
+	version, _, err := readSchemaVersion(db, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), version)
+
+	value, _, _ = db.Get([]byte("a"))
+	assert.Equal(t, []byte("3"), value)
+}
+
+func TestMigrateSchema_InvalidRegistry(t *testing.T) {
+	db := newMemoryKV(t)
+	noop := func(db *migrationWriter) error { return nil }
+
+	assert.ErrorIs(t, migrateSchema(hclog.NewNullLogger(), db, false, []*schemaMigration{
+		{version: 2, name: "two", migrate: noop},
+		{version: 4, name: "four", migrate: noop},
+	}), errInvalidRegistry)
+}
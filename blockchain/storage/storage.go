@@ -7,7 +7,32 @@ import (
 	"github.com/hashicorp/go-hclog"
 )
 
+// KeyLayout is the name of the database key layout of the chain data
+type KeyLayout string
+
+const (
+	// DogechainKeyLayout keys the block data by hash
+	DogechainKeyLayout KeyLayout = "dogechain"
+	// GethKeyLayout keys and encodes the block data like the go-ethereum rawdb schema
+	GethKeyLayout KeyLayout = "geth"
+)
+
+// MaxStateRootIndexPostings caps the numbers of the headers recorded under a state root.
+// The root is kept by the blocks without state changes, only the first ones are recorded.
+const MaxStateRootIndexPostings = 64
+
 type StorageBuilder interface {
+	// SetReadOnly sets the read-only mode, all writes of the built storage return ErrReadOnly
+	SetReadOnly(bool) StorageBuilder
+	// IsReadOnly returns whether the storage is built in read-only mode
+	IsReadOnly() bool
+	// SetIdealBatchSize sets the size of the writes queued in a batch before it is
+	// flushed automatically, kvdb.IdealBatchSize is used if not positive
+	SetIdealBatchSize(int) StorageBuilder
+	// SetKeyLayout sets the key layout of a new storage. An existing storage keeps its
+	// own layout, and fails to build if another one is set.
+	SetKeyLayout(KeyLayout) StorageBuilder
+
 	Build() (Storage, error)
 }
 
@@ -15,34 +40,121 @@ type StorageBuilder interface {
 type Storage interface {
 	ReadCanonicalHash(n uint64) (types.Hash, bool)
 	WriteCanonicalHash(n uint64, hash types.Hash) error
+	DeleteCanonicalHash(n uint64) error
+	// ReadCanonicalHashesInRange returns the canonical hashes from number 'from' to 'to' (inclusive).
+	// It stops at the first missing number, so the result might be shorter than the range.
+	ReadCanonicalHashesInRange(from, to uint64) []types.Hash
 
 	ReadHeadHash() (types.Hash, bool)
 	ReadHeadNumber() (uint64, bool)
 	WriteHeadHash(h types.Hash) error
 	WriteHeadNumber(uint64) error
+	// WritePersistedHead writes the number of the latest block whose body, receipts
+	// and transaction lookups are flushed by the block writer
+	WritePersistedHead(n uint64) error
+	ReadPersistedHead() (uint64, bool)
 
 	WriteForks(forks []types.Hash) error
 	ReadForks() ([]types.Hash, error)
 
-	WriteTotalDifficulty(hash types.Hash, diff *big.Int) error
+	// WriteHaltState writes the emergency halt state of the chain
+	WriteHaltState(state *HaltState) error
+	ReadHaltState() (*HaltState, bool)
+
+	// WriteCheckpointAnchor writes the hash of the block linked to the trusted checkpoint
+	// by the parent hashes, the anchors of each checkpoint are kept apart
+	WriteCheckpointAnchor(checkpoint types.Hash, n uint64, hash types.Hash) error
+	ReadCheckpointAnchor(checkpoint types.Hash, n uint64) (types.Hash, bool)
+
+	WriteTotalDifficulty(n uint64, hash types.Hash, diff *big.Int) error
 	ReadTotalDifficulty(hash types.Hash) (*big.Int, bool)
 
+	// WriteHeader writes the header, and records its number under its state root unless
+	// MaxStateRootIndexPostings numbers are recorded already
 	WriteHeader(h *types.Header) error
 	ReadHeader(hash types.Hash) (*types.Header, error)
+	// ReadStateRootIndex returns the numbers of the headers written with the state root,
+	// in ascending order. The forks are included, so the numbers are not all canonical.
+	ReadStateRootIndex(root types.Hash) []uint64
 
 	WriteCanonicalHeader(h *types.Header, diff *big.Int) error
 
-	WriteBody(hash types.Hash, body *types.Body) error
+	WriteBody(n uint64, hash types.Hash, body *types.Body) error
 	ReadBody(hash types.Hash) (*types.Body, error)
 
-	WriteReceipts(hash types.Hash, receipts []*types.Receipt) error
+	WriteReceipts(n uint64, hash types.Hash, receipts []*types.Receipt) error
 	ReadReceipts(hash types.Hash) ([]*types.Receipt, error)
+	// WriteReceiptsBackfillHead writes the number of the last block checked for missing receipts
+	WriteReceiptsBackfillHead(n uint64) error
+	ReadReceiptsBackfillHead() (uint64, bool)
 
 	WriteTxLookup(hash types.Hash, blockHash types.Hash) error
 	ReadTxLookup(hash types.Hash) (types.Hash, bool)
+	DeleteTxLookup(hash types.Hash) error
+	// WriteTxLookupTail writes the number of the oldest block with transaction lookups
+	WriteTxLookupTail(n uint64) error
+	ReadTxLookupTail() (uint64, bool)
+
+	// WriteLogIndex records the block number postings of the log addresses and topics
+	WriteLogIndex(n uint64, addresses []types.Address, topics []types.Hash) error
+	// ReadLogIndexByAddress returns the block numbers within [from, to] having logs of the address
+	ReadLogIndexByAddress(addr types.Address, from, to uint64) []uint64
+	// ReadLogIndexByTopic returns the block numbers within [from, to] having logs of the topic
+	ReadLogIndexByTopic(topic types.Hash, from, to uint64) []uint64
+	WriteLogIndexHead(n uint64) error
+	ReadLogIndexHead() (uint64, bool)
+
+	// WriteBloomBits writes the rotated bloom bit vector of the section,
+	// an empty vector means no block in the section has the bit set
+	WriteBloomBits(bit uint, section uint64, bits []byte) error
+	// ReadBloomBits returns the rotated bloom bit vector of the section
+	ReadBloomBits(bit uint, section uint64) ([]byte, bool)
+	WriteBloomBitsSections(sections uint64) error
+	ReadBloomBitsSections() (uint64, bool)
+
+	// WriteChainStats writes the aggregated usage of the canonical blocks within the hour
+	WriteChainStats(hour uint64, stats *ChainStats) error
+	// ReadChainStats returns the aggregated usage of the canonical blocks within the hour
+	ReadChainStats(hour uint64) (*ChainStats, bool)
+	WriteChainStatsHead(n uint64) error
+	ReadChainStatsHead() (uint64, bool)
+
+	// WriteSystemTxIndex records the block number postings of the system transaction types
+	WriteSystemTxIndex(n uint64, txTypes []string) error
+	// ReadSystemTxIndex returns the block numbers within [from, to] having system transactions of the type
+	ReadSystemTxIndex(txType string, from, to uint64) []uint64
+	WriteSystemTxIndexHead(n uint64) error
+	ReadSystemTxIndexHead() (uint64, bool)
+
+	// NewBatch returns the batch of the bulk writes, which are written on Write or earlier
+	// once the queued size reaches the ideal batch size of the storage
+	NewBatch() Batch
+	// NewAtomicBatch returns the batch whose writes are all committed at once on Write,
+	// so that a crash never leaves a part of them
+	NewAtomicBatch() Batch
 
 	Close() error
 }
 
+// Batch queues the writes of the storage
+type Batch interface {
+	WriteCanonicalHash(n uint64, hash types.Hash) error
+	DeleteCanonicalHash(n uint64) error
+	WriteHeadHash(h types.Hash) error
+	WriteHeadNumber(n uint64) error
+	WritePersistedHead(n uint64) error
+	WriteForks(forks []types.Hash) error
+	WriteTotalDifficulty(n uint64, hash types.Hash, diff *big.Int) error
+	WriteHeader(h *types.Header) error
+	WriteCanonicalHeader(h *types.Header, diff *big.Int) error
+	WriteBody(n uint64, hash types.Hash, body *types.Body) error
+	WriteReceipts(n uint64, hash types.Hash, receipts []*types.Receipt) error
+	WriteTxLookup(hash types.Hash, blockHash types.Hash) error
+	DeleteTxLookup(hash types.Hash) error
+
+	// Write writes the queued writes, the batch is reusable afterwards
+	Write() error
+}
+
 // Factory is a factory method to create a blockchain storage
 type Factory func(config map[string]interface{}, logger hclog.Logger) (Storage, error)
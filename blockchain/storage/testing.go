@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/dogechain-lab/dogechain/crypto"
 	"github.com/dogechain-lab/dogechain/helper/hex"
 	"github.com/dogechain-lab/dogechain/types"
 	"github.com/stretchr/testify/assert"
@@ -28,6 +29,9 @@ func TestStorage(t *testing.T, m PlaceholderStorage) {
 	t.Run("", func(t *testing.T) {
 		testCanonicalChain(t, m)
 	})
+	t.Run("", func(t *testing.T) {
+		testCanonicalHashesInRange(t, m)
+	})
 	t.Run("", func(t *testing.T) {
 		testDifficulty(t, m)
 	})
@@ -37,9 +41,18 @@ func TestStorage(t *testing.T, m PlaceholderStorage) {
 	t.Run("", func(t *testing.T) {
 		testForks(t, m)
 	})
+	t.Run("", func(t *testing.T) {
+		testHaltState(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testCheckpointAnchor(t, m)
+	})
 	t.Run("", func(t *testing.T) {
 		testHeader(t, m)
 	})
+	t.Run("", func(t *testing.T) {
+		testStateRootIndex(t, m)
+	})
 	t.Run("", func(t *testing.T) {
 		testBody(t, m)
 	})
@@ -49,6 +62,27 @@ func TestStorage(t *testing.T, m PlaceholderStorage) {
 	t.Run("", func(t *testing.T) {
 		testReceipts(t, m)
 	})
+	t.Run("", func(t *testing.T) {
+		testLogIndex(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testBloomBits(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testChainStats(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testSystemTxIndex(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testTxLookup(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testBatch(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testAtomicBatch(t, m)
+	})
 }
 
 func testCanonicalChain(t *testing.T, m PlaceholderStorage) {
@@ -101,6 +135,46 @@ func testCanonicalChain(t *testing.T, m PlaceholderStorage) {
 		if !reflect.DeepEqual(data, hash) {
 			t.Fatal("not match")
 		}
+
+		assert.NoError(t, s.DeleteCanonicalHash(cc.Number))
+
+		_, ok = s.ReadCanonicalHash(cc.Number)
+		assert.False(t, ok)
+	}
+}
+
+func testCanonicalHashesInRange(t *testing.T, m PlaceholderStorage) {
+	t.Helper()
+
+	s, closeFn := m(t)
+	defer closeFn()
+
+	hashes := []types.Hash{}
+
+	// canonical chain 0 - 9, and a detached block 12
+	for i := uint64(0); i < 10; i++ {
+		hash := types.BytesToHash(big.NewInt(int64(i + 1)).Bytes())
+		hashes = append(hashes, hash)
+
+		assert.NoError(t, s.WriteCanonicalHash(i, hash))
+	}
+
+	assert.NoError(t, s.WriteCanonicalHash(12, types.StringToHash("12")))
+
+	var cases = []struct {
+		From     uint64
+		To       uint64
+		Expected []types.Hash
+	}{
+		{0, 9, hashes},
+		{3, 5, hashes[3:6]},
+		{8, 20, hashes[8:]},
+		{11, 12, []types.Hash{}},
+		{5, 4, []types.Hash{}},
+	}
+
+	for _, cc := range cases {
+		assert.Equal(t, cc.Expected, s.ReadCanonicalHashesInRange(cc.From, cc.To))
 	}
 }
 
@@ -136,7 +210,7 @@ func testDifficulty(t *testing.T, m PlaceholderStorage) {
 			t.Fatal(err)
 		}
 
-		if err := s.WriteTotalDifficulty(hash, cc.Diff); err != nil {
+		if err := s.WriteTotalDifficulty(h.Number, hash, cc.Diff); err != nil {
 			t.Fatal(err)
 		}
 
@@ -194,6 +268,15 @@ func testHead(t *testing.T, m PlaceholderStorage) {
 			t.Fatal("bad")
 		}
 	}
+
+	_, ok := s.ReadPersistedHead()
+	assert.False(t, ok)
+
+	assert.NoError(t, s.WritePersistedHead(3))
+
+	persisted, ok := s.ReadPersistedHead()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), persisted)
 }
 
 func testForks(t *testing.T, m PlaceholderStorage) {
@@ -252,6 +335,71 @@ func testHeader(t *testing.T, m PlaceholderStorage) {
 	}
 }
 
+func testStateRootIndex(t *testing.T, m PlaceholderStorage) {
+	t.Helper()
+
+	s, closeFn := m(t)
+	defer closeFn()
+
+	var (
+		root1 = types.StringToHash("1")
+		root2 = types.StringToHash("2")
+	)
+
+	assert.Equal(t, []uint64{}, s.ReadStateRootIndex(root1))
+
+	for i, root := range []types.Hash{root1, root2, root2, root1} {
+		header := &types.Header{
+			Number:     uint64(i + 1),
+			StateRoot:  root,
+			ExtraData:  []byte{},
+			ParentHash: types.StringToHash("parent"),
+		}
+		header.ComputeHash()
+
+		assert.NoError(t, s.WriteCanonicalHeader(header, big.NewInt(int64(i+1))))
+	}
+
+	// a fork with the same root
+	fork := &types.Header{
+		Number:     3,
+		StateRoot:  root1,
+		ExtraData:  []byte{},
+		ParentHash: types.StringToHash("fork"),
+	}
+	fork.ComputeHash()
+
+	assert.NoError(t, s.WriteHeader(fork))
+
+	assert.Equal(t, []uint64{1, 3, 4}, s.ReadStateRootIndex(root1))
+	assert.Equal(t, []uint64{2, 3}, s.ReadStateRootIndex(root2))
+	assert.Equal(t, []uint64{}, s.ReadStateRootIndex(types.StringToHash("3")))
+
+	// the numbers of a root are capped, the headers written again are not counted
+	root3 := types.StringToHash("3")
+	expected := []uint64{}
+
+	for i := uint64(0); i < MaxStateRootIndexPostings+2; i++ {
+		header := &types.Header{
+			Number:     10 + i,
+			StateRoot:  root3,
+			ExtraData:  []byte{},
+			ParentHash: types.StringToHash("parent"),
+		}
+		header.ComputeHash()
+
+		assert.NoError(t, s.WriteHeader(header))
+		assert.NoError(t, s.WriteHeader(header))
+
+		if i < MaxStateRootIndexPostings {
+			expected = append(expected, header.Number)
+		}
+	}
+
+	assert.NoError(t, s.WriteHeadNumber(100))
+	assert.Equal(t, expected, s.ReadStateRootIndex(root3))
+}
+
 func testBody(t *testing.T, m PlaceholderStorage) {
 	t.Helper()
 
@@ -299,7 +447,7 @@ func testBody(t *testing.T, m PlaceholderStorage) {
 	}
 
 	body0 := block.Body()
-	if err := s.WriteBody(header.Hash, body0); err != nil {
+	if err := s.WriteBody(header.Number, header.Hash, body0); err != nil {
 		panic(err)
 	}
 
@@ -338,21 +486,32 @@ func testReceipts(t *testing.T, m PlaceholderStorage) {
 		Nonce:    1000,
 		Gas:      50,
 		GasPrice: new(big.Int).SetUint64(100),
+		To:       &addr1,
+		V:        big.NewInt(11),
+		From:     addr2,
+	}
+	create := &types.Transaction{
+		Nonce:    1001,
+		Gas:      50,
+		GasPrice: new(big.Int).SetUint64(100),
 		V:        big.NewInt(11),
+		From:     addr2,
 	}
 	body := &types.Body{
-		Transactions: []*types.Transaction{txn},
+		Transactions: []*types.Transaction{txn, create},
 	}
 
-	if err := s.WriteBody(h.Hash, body); err != nil {
+	if err := s.WriteBody(h.Number, h.Hash, body); err != nil {
 		t.Fatal(err)
 	}
 
+	// the context fields are consistent with the body, as the layouts encoding the
+	// receipts like go-ethereum derive them
 	r0 := &types.Receipt{
 		Root:              types.StringToHash("1"),
 		CumulativeGasUsed: 10,
 		TxHash:            txn.Hash(),
-		LogsBloom:         types.Bloom{0x1},
+		GasUsed:           10,
 		Logs: []*types.Log{
 			{
 				Address: addr1,
@@ -365,13 +524,13 @@ func testReceipts(t *testing.T, m PlaceholderStorage) {
 			},
 		},
 	}
+	r0.LogsBloom = types.CreateBloom([]*types.Receipt{r0})
+
 	r1 := &types.Receipt{
 		Root:              types.StringToHash("1"),
-		CumulativeGasUsed: 10,
-		TxHash:            txn.Hash(),
-		LogsBloom:         types.Bloom{0x1},
-		GasUsed:           10,
-		ContractAddress:   &types.Address{0x1},
+		CumulativeGasUsed: 30,
+		TxHash:            create.Hash(),
+		GasUsed:           20,
 		Logs: []*types.Log{
 			{
 				Address: addr2,
@@ -379,10 +538,12 @@ func testReceipts(t *testing.T, m PlaceholderStorage) {
 			},
 		},
 	}
+	r1.LogsBloom = types.CreateBloom([]*types.Receipt{r1})
+	r1.SetContractAddress(crypto.CreateAddress(create.From, create.Nonce))
 
 	receipts := []*types.Receipt{r0, r1}
 
-	if err := s.WriteReceipts(h.Hash, receipts); err != nil {
+	if err := s.WriteReceipts(h.Number, h.Hash, receipts); err != nil {
 		t.Fatal(err)
 	}
 
@@ -393,6 +554,15 @@ func testReceipts(t *testing.T, m PlaceholderStorage) {
 	}
 
 	assert.True(t, reflect.DeepEqual(receipts, found))
+
+	_, ok := s.ReadReceiptsBackfillHead()
+	assert.False(t, ok)
+
+	assert.NoError(t, s.WriteReceiptsBackfillHead(5))
+
+	backfillHead, ok := s.ReadReceiptsBackfillHead()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(5), backfillHead)
 }
 
 func testWriteCanonicalHeader(t *testing.T, m PlaceholderStorage) {
@@ -453,50 +623,449 @@ func testWriteCanonicalHeader(t *testing.T, m PlaceholderStorage) {
 	}
 }
 
+func testLogIndex(t *testing.T, m PlaceholderStorage) {
+	t.Helper()
+
+	s, closeFn := m(t)
+	defer closeFn()
+
+	var (
+		addr1  = types.StringToAddress("1")
+		addr2  = types.StringToAddress("2")
+		topic1 = types.StringToHash("1")
+		topic2 = types.StringToHash("2")
+	)
+
+	_, ok := s.ReadLogIndexHead()
+	assert.False(t, ok)
+
+	assert.NoError(t, s.WriteLogIndex(1, []types.Address{addr1}, []types.Hash{topic1}))
+	assert.NoError(t, s.WriteLogIndex(3, []types.Address{addr1, addr2}, []types.Hash{topic2}))
+	assert.NoError(t, s.WriteLogIndex(7, []types.Address{addr2}, []types.Hash{topic1, topic2}))
+	// rewriting is idempotent
+	assert.NoError(t, s.WriteLogIndex(7, []types.Address{addr2}, nil))
+	assert.NoError(t, s.WriteLogIndexHead(7))
+
+	head, ok := s.ReadLogIndexHead()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(7), head)
+
+	assert.Equal(t, []uint64{1, 3}, s.ReadLogIndexByAddress(addr1, 0, 10))
+	assert.Equal(t, []uint64{3, 7}, s.ReadLogIndexByAddress(addr2, 0, 10))
+	assert.Equal(t, []uint64{3}, s.ReadLogIndexByAddress(addr2, 2, 6))
+	assert.Equal(t, []uint64{1, 7}, s.ReadLogIndexByTopic(topic1, 1, 7))
+	assert.Equal(t, []uint64{7}, s.ReadLogIndexByTopic(topic2, 4, 8))
+	assert.Equal(t, []uint64{}, s.ReadLogIndexByTopic(types.StringToHash("3"), 0, 10))
+	assert.Equal(t, []uint64{}, s.ReadLogIndexByAddress(addr1, 5, 4))
+}
+
+func testTxLookup(t *testing.T, m PlaceholderStorage) {
+	t.Helper()
+
+	s, closeFn := m(t)
+	defer closeFn()
+
+	var (
+		txHash    = types.StringToHash("1")
+		blockHash = types.StringToHash("2")
+	)
+
+	_, ok := s.ReadTxLookupTail()
+	assert.False(t, ok)
+
+	assert.NoError(t, s.WriteTxLookup(txHash, blockHash))
+
+	hash, ok := s.ReadTxLookup(txHash)
+	assert.True(t, ok)
+	assert.Equal(t, blockHash, hash)
+
+	assert.NoError(t, s.DeleteTxLookup(txHash))
+
+	_, ok = s.ReadTxLookup(txHash)
+	assert.False(t, ok)
+
+	// deleting twice is fine
+	assert.NoError(t, s.DeleteTxLookup(txHash))
+
+	assert.NoError(t, s.WriteTxLookupTail(10))
+
+	tail, ok := s.ReadTxLookupTail()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(10), tail)
+}
+
+func testBatch(t *testing.T, m PlaceholderStorage) {
+	t.Helper()
+
+	s, closeFn := m(t)
+	defer closeFn()
+
+	header := &types.Header{
+		Number:    1,
+		ExtraData: []byte{},
+	}
+	header.ComputeHash()
+
+	var (
+		txHash1 = types.StringToHash("1")
+		txHash2 = types.StringToHash("2")
+	)
+
+	assert.NoError(t, s.WriteTxLookup(txHash1, header.Hash))
+
+	batch := s.NewBatch()
+
+	assert.NoError(t, batch.WriteHeader(header))
+	assert.NoError(t, batch.WriteCanonicalHash(header.Number, header.Hash))
+	assert.NoError(t, batch.WriteTotalDifficulty(header.Number, header.Hash, big.NewInt(10)))
+	assert.NoError(t, batch.WriteTxLookup(txHash2, header.Hash))
+	assert.NoError(t, batch.DeleteTxLookup(txHash1))
+
+	assert.NoError(t, batch.Write())
+
+	found, err := s.ReadHeader(header.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, header.Hash, found.Hash)
+
+	hash, ok := s.ReadCanonicalHash(header.Number)
+	assert.True(t, ok)
+	assert.Equal(t, header.Hash, hash)
+
+	diff, ok := s.ReadTotalDifficulty(header.Hash)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(10), diff)
+
+	_, ok = s.ReadTxLookup(txHash1)
+	assert.False(t, ok)
+
+	hash, ok = s.ReadTxLookup(txHash2)
+	assert.True(t, ok)
+	assert.Equal(t, header.Hash, hash)
+
+	// reused after written
+	assert.NoError(t, batch.DeleteTxLookup(txHash2))
+	assert.NoError(t, batch.Write())
+
+	_, ok = s.ReadTxLookup(txHash2)
+	assert.False(t, ok)
+}
+
+func testAtomicBatch(t *testing.T, m PlaceholderStorage) {
+	t.Helper()
+
+	s, closeFn := m(t)
+	defer closeFn()
+
+	header := &types.Header{
+		Number:    1,
+		ExtraData: []byte{},
+	}
+	header.ComputeHash()
+
+	batch := s.NewAtomicBatch()
+
+	assert.NoError(t, batch.WriteCanonicalHeader(header, big.NewInt(10)))
+	assert.NoError(t, batch.WriteBody(header.Number, header.Hash, &types.Body{}))
+	assert.NoError(t, batch.WriteForks([]types.Hash{header.Hash}))
+	assert.NoError(t, batch.WritePersistedHead(header.Number))
+
+	// nothing is written before committed
+	_, ok := s.ReadHeadHash()
+	assert.False(t, ok)
+
+	_, err := s.ReadHeader(header.Hash)
+	assert.Error(t, err)
+
+	assert.NoError(t, batch.Write())
+
+	hash, ok := s.ReadHeadHash()
+	assert.True(t, ok)
+	assert.Equal(t, header.Hash, hash)
+
+	number, ok := s.ReadHeadNumber()
+	assert.True(t, ok)
+	assert.Equal(t, header.Number, number)
+
+	hash, ok = s.ReadCanonicalHash(header.Number)
+	assert.True(t, ok)
+	assert.Equal(t, header.Hash, hash)
+
+	diff, ok := s.ReadTotalDifficulty(header.Hash)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(10), diff)
+
+	_, err = s.ReadBody(header.Hash)
+	assert.NoError(t, err)
+
+	forks, err := s.ReadForks()
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Hash{header.Hash}, forks)
+
+	persisted, ok := s.ReadPersistedHead()
+	assert.True(t, ok)
+	assert.Equal(t, header.Number, persisted)
+}
+
+func testBloomBits(t *testing.T, m PlaceholderStorage) {
+	t.Helper()
+
+	s, closeFn := m(t)
+	defer closeFn()
+
+	_, ok := s.ReadBloomBitsSections()
+	assert.False(t, ok)
+
+	_, ok = s.ReadBloomBits(1, 0)
+	assert.False(t, ok)
+
+	assert.NoError(t, s.WriteBloomBits(1, 0, []byte{0x80, 0x01}))
+	assert.NoError(t, s.WriteBloomBits(2047, 0, []byte{}))
+	assert.NoError(t, s.WriteBloomBits(1, 1, []byte{0x02}))
+	assert.NoError(t, s.WriteBloomBitsSections(2))
+
+	sections, ok := s.ReadBloomBitsSections()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), sections)
+
+	bits, ok := s.ReadBloomBits(1, 0)
+	assert.True(t, ok)
+	assert.Equal(t, []byte{0x80, 0x01}, bits)
+
+	bits, ok = s.ReadBloomBits(1, 1)
+	assert.True(t, ok)
+	assert.Equal(t, []byte{0x02}, bits)
+
+	bits, ok = s.ReadBloomBits(2047, 0)
+	assert.True(t, ok)
+	assert.Empty(t, bits)
+}
+
+func testHaltState(t *testing.T, m PlaceholderStorage) {
+	t.Helper()
+
+	s, closeFn := m(t)
+	defer closeFn()
+
+	_, ok := s.ReadHaltState()
+	assert.False(t, ok)
+
+	halted := &HaltState{
+		Halted:   true,
+		Operator: "admin token from 127.0.0.1:1234",
+		Reason:   "exploit",
+		Since:    1700000000,
+	}
+
+	assert.NoError(t, s.WriteHaltState(halted))
+
+	found, ok := s.ReadHaltState()
+	assert.True(t, ok)
+	assert.Equal(t, halted, found)
+
+	resumed := &HaltState{
+		Operator: "admin token from 127.0.0.1:1234",
+		Reason:   "fixed",
+		Since:    1700000100,
+	}
+
+	assert.NoError(t, s.WriteHaltState(resumed))
+
+	found, ok = s.ReadHaltState()
+	assert.True(t, ok)
+	assert.Equal(t, resumed, found)
+}
+
+func testCheckpointAnchor(t *testing.T, m PlaceholderStorage) {
+	t.Helper()
+
+	s, closeFn := m(t)
+	defer closeFn()
+
+	_, ok := s.ReadCheckpointAnchor(hash1, 100)
+	assert.False(t, ok)
+
+	anchor := types.StringToHash("100")
+	assert.NoError(t, s.WriteCheckpointAnchor(hash1, 100, anchor))
+
+	found, ok := s.ReadCheckpointAnchor(hash1, 100)
+	assert.True(t, ok)
+	assert.Equal(t, anchor, found)
+
+	// the anchors of another checkpoint are apart
+	_, ok = s.ReadCheckpointAnchor(hash2, 100)
+	assert.False(t, ok)
+
+	_, ok = s.ReadCheckpointAnchor(hash1, 99)
+	assert.False(t, ok)
+}
+
+func testChainStats(t *testing.T, m PlaceholderStorage) {
+	t.Helper()
+
+	s, closeFn := m(t)
+	defer closeFn()
+
+	_, ok := s.ReadChainStatsHead()
+	assert.False(t, ok)
+
+	_, ok = s.ReadChainStats(100)
+	assert.False(t, ok)
+
+	stats := &ChainStats{
+		FirstBlock:  10,
+		LastBlock:   12,
+		Blocks:      3,
+		TxCount:     5,
+		GasUsed:     100000,
+		GasLimit:    30000000,
+		GasPriceSum: big.NewInt(5000000000),
+	}
+
+	assert.NoError(t, s.WriteChainStats(100, stats))
+	assert.NoError(t, s.WriteChainStatsHead(12))
+
+	found, ok := s.ReadChainStats(100)
+	assert.True(t, ok)
+	assert.Equal(t, stats, found)
+
+	_, ok = s.ReadChainStats(101)
+	assert.False(t, ok)
+
+	head, ok := s.ReadChainStatsHead()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(12), head)
+}
+
+func testSystemTxIndex(t *testing.T, m PlaceholderStorage) {
+	t.Helper()
+
+	s, closeFn := m(t)
+	defer closeFn()
+
+	_, ok := s.ReadSystemTxIndexHead()
+	assert.False(t, ok)
+
+	assert.NoError(t, s.WriteSystemTxIndex(1, []string{"deposit"}))
+	assert.NoError(t, s.WriteSystemTxIndex(2, []string{"deposit", "slash"}))
+	assert.NoError(t, s.WriteSystemTxIndex(5, []string{"slash"}))
+	assert.NoError(t, s.WriteSystemTxIndexHead(5))
+
+	head, ok := s.ReadSystemTxIndexHead()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(5), head)
+
+	assert.Equal(t, []uint64{1, 2}, s.ReadSystemTxIndex("deposit", 0, 10))
+	assert.Equal(t, []uint64{2, 5}, s.ReadSystemTxIndex("slash", 0, 10))
+	assert.Equal(t, []uint64{5}, s.ReadSystemTxIndex("slash", 3, 5))
+	assert.Equal(t, []uint64{}, s.ReadSystemTxIndex("unknown", 0, 10))
+}
+
 // Storage delegators
 
 type readCanonicalHashDelegate func(uint64) (types.Hash, bool)
 type writeCanonicalHashDelegate func(uint64, types.Hash) error
+type deleteCanonicalHashDelegate func(uint64) error
+type readCanonicalHashesInRangeDelegate func(uint64, uint64) []types.Hash
 type readHeadHashDelegate func() (types.Hash, bool)
 type readHeadNumberDelegate func() (uint64, bool)
 type writeHeadHashDelegate func(types.Hash) error
 type writeHeadNumberDelegate func(uint64) error
+type writePersistedHeadDelegate func(uint64) error
+type readPersistedHeadDelegate func() (uint64, bool)
 type writeForksDelegate func([]types.Hash) error
 type readForksDelegate func() ([]types.Hash, error)
-type writeTotalDifficultyDelegate func(types.Hash, *big.Int) error
+type writeHaltStateDelegate func(*HaltState) error
+type readHaltStateDelegate func() (*HaltState, bool)
+type writeCheckpointAnchorDelegate func(types.Hash, uint64, types.Hash) error
+type readCheckpointAnchorDelegate func(types.Hash, uint64) (types.Hash, bool)
+type writeTotalDifficultyDelegate func(uint64, types.Hash, *big.Int) error
 type readTotalDifficultyDelegate func(types.Hash) (*big.Int, bool)
 type writeHeaderDelegate func(*types.Header) error
 type readHeaderDelegate func(types.Hash) (*types.Header, error)
 type writeCanonicalHeaderDelegate func(*types.Header, *big.Int) error
-type writeBodyDelegate func(types.Hash, *types.Body) error
+type writeBodyDelegate func(uint64, types.Hash, *types.Body) error
 type readBodyDelegate func(types.Hash) (*types.Body, error)
-type writeReceiptsDelegate func(types.Hash, []*types.Receipt) error
+type writeReceiptsDelegate func(uint64, types.Hash, []*types.Receipt) error
 type readReceiptsDelegate func(types.Hash) ([]*types.Receipt, error)
+type writeReceiptsBackfillHeadDelegate func(uint64) error
+type readReceiptsBackfillHeadDelegate func() (uint64, bool)
 type writeTxLookupDelegate func(types.Hash, types.Hash) error
 type readTxLookupDelegate func(types.Hash) (types.Hash, bool)
+type deleteTxLookupDelegate func(types.Hash) error
+type writeTxLookupTailDelegate func(uint64) error
+type readTxLookupTailDelegate func() (uint64, bool)
+type writeLogIndexDelegate func(uint64, []types.Address, []types.Hash) error
+type readLogIndexByAddressDelegate func(types.Address, uint64, uint64) []uint64
+type readLogIndexByTopicDelegate func(types.Hash, uint64, uint64) []uint64
+type writeLogIndexHeadDelegate func(uint64) error
+type readLogIndexHeadDelegate func() (uint64, bool)
+type writeBloomBitsDelegate func(uint, uint64, []byte) error
+type readBloomBitsDelegate func(uint, uint64) ([]byte, bool)
+type writeBloomBitsSectionsDelegate func(uint64) error
+type readBloomBitsSectionsDelegate func() (uint64, bool)
+type writeChainStatsDelegate func(uint64, *ChainStats) error
+type readChainStatsDelegate func(uint64) (*ChainStats, bool)
+type writeChainStatsHeadDelegate func(uint64) error
+type readChainStatsHeadDelegate func() (uint64, bool)
+type writeSystemTxIndexDelegate func(uint64, []string) error
+type readSystemTxIndexDelegate func(string, uint64, uint64) []uint64
+type writeSystemTxIndexHeadDelegate func(uint64) error
+type readSystemTxIndexHeadDelegate func() (uint64, bool)
+type readStateRootIndexDelegate func(types.Hash) []uint64
 type closeDelegate func() error
 
 type MockStorage struct {
-	readCanonicalHashFn    readCanonicalHashDelegate
-	writeCanonicalHashFn   writeCanonicalHashDelegate
-	readHeadHashFn         readHeadHashDelegate
-	readHeadNumberFn       readHeadNumberDelegate
-	writeHeadHashFn        writeHeadHashDelegate
-	writeHeadNumberFn      writeHeadNumberDelegate
-	writeForksFn           writeForksDelegate
-	readForksFn            readForksDelegate
-	writeTotalDifficultyFn writeTotalDifficultyDelegate
-	readTotalDifficultyFn  readTotalDifficultyDelegate
-	writeHeaderFn          writeHeaderDelegate
-	readHeaderFn           readHeaderDelegate
-	writeCanonicalHeaderFn writeCanonicalHeaderDelegate
-	writeBodyFn            writeBodyDelegate
-	readBodyFn             readBodyDelegate
-	writeReceiptsFn        writeReceiptsDelegate
-	readReceiptsFn         readReceiptsDelegate
-	writeTxLookupFn        writeTxLookupDelegate
-	readTxLookupFn         readTxLookupDelegate
-	closeFn                closeDelegate
+	readCanonicalHashFn     readCanonicalHashDelegate
+	writeCanonicalHashFn    writeCanonicalHashDelegate
+	deleteCanonicalHashFn   deleteCanonicalHashDelegate
+	readCanonicalRangeFn    readCanonicalHashesInRangeDelegate
+	readHeadHashFn          readHeadHashDelegate
+	readHeadNumberFn        readHeadNumberDelegate
+	writeHeadHashFn         writeHeadHashDelegate
+	writeHeadNumberFn       writeHeadNumberDelegate
+	writePersistedHeadFn    writePersistedHeadDelegate
+	readPersistedHeadFn     readPersistedHeadDelegate
+	writeForksFn            writeForksDelegate
+	readForksFn             readForksDelegate
+	writeHaltStateFn        writeHaltStateDelegate
+	readHaltStateFn         readHaltStateDelegate
+	writeCheckpointAnchorFn writeCheckpointAnchorDelegate
+	readCheckpointAnchorFn  readCheckpointAnchorDelegate
+	writeTotalDifficultyFn  writeTotalDifficultyDelegate
+	readTotalDifficultyFn   readTotalDifficultyDelegate
+	writeHeaderFn           writeHeaderDelegate
+	readHeaderFn            readHeaderDelegate
+	writeCanonicalHeaderFn  writeCanonicalHeaderDelegate
+	writeBodyFn             writeBodyDelegate
+	readBodyFn              readBodyDelegate
+	writeReceiptsFn         writeReceiptsDelegate
+	readReceiptsFn          readReceiptsDelegate
+	writeBackfillHeadFn     writeReceiptsBackfillHeadDelegate
+	readBackfillHeadFn      readReceiptsBackfillHeadDelegate
+	writeTxLookupFn         writeTxLookupDelegate
+	readTxLookupFn          readTxLookupDelegate
+	deleteTxLookupFn        deleteTxLookupDelegate
+	writeTxLookupTailFn     writeTxLookupTailDelegate
+	readTxLookupTailFn      readTxLookupTailDelegate
+	writeLogIndexFn         writeLogIndexDelegate
+	readLogIndexByAddrFn    readLogIndexByAddressDelegate
+	readLogIndexByTopicFn   readLogIndexByTopicDelegate
+	writeLogIndexHeadFn     writeLogIndexHeadDelegate
+	readLogIndexHeadFn      readLogIndexHeadDelegate
+	writeBloomBitsFn        writeBloomBitsDelegate
+	readBloomBitsFn         readBloomBitsDelegate
+	writeBloomSectionsFn    writeBloomBitsSectionsDelegate
+	readBloomSectionsFn     readBloomBitsSectionsDelegate
+	writeChainStatsFn       writeChainStatsDelegate
+	readChainStatsFn        readChainStatsDelegate
+	writeChainStatsHeadFn   writeChainStatsHeadDelegate
+	readChainStatsHeadFn    readChainStatsHeadDelegate
+	writeSystemTxIndexFn    writeSystemTxIndexDelegate
+	readSystemTxIndexFn     readSystemTxIndexDelegate
+	writeSystemTxHeadFn     writeSystemTxIndexHeadDelegate
+	readSystemTxHeadFn      readSystemTxIndexHeadDelegate
+	readStateRootIndexFn    readStateRootIndexDelegate
+	closeFn                 closeDelegate
 }
 
 func NewMockStorage() *MockStorage {
@@ -527,6 +1096,41 @@ func (m *MockStorage) HookWriteCanonicalHash(fn writeCanonicalHashDelegate) {
 	m.writeCanonicalHashFn = fn
 }
 
+func (m *MockStorage) DeleteCanonicalHash(n uint64) error {
+	if m.deleteCanonicalHashFn != nil {
+		return m.deleteCanonicalHashFn(n)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookDeleteCanonicalHash(fn deleteCanonicalHashDelegate) {
+	m.deleteCanonicalHashFn = fn
+}
+
+func (m *MockStorage) ReadCanonicalHashesInRange(from, to uint64) []types.Hash {
+	if m.readCanonicalRangeFn != nil {
+		return m.readCanonicalRangeFn(from, to)
+	}
+
+	hashes := []types.Hash{}
+
+	for n := from; n >= from && n <= to; n++ {
+		hash, ok := m.ReadCanonicalHash(n)
+		if !ok {
+			break
+		}
+
+		hashes = append(hashes, hash)
+	}
+
+	return hashes
+}
+
+func (m *MockStorage) HookReadCanonicalHashesInRange(fn readCanonicalHashesInRangeDelegate) {
+	m.readCanonicalRangeFn = fn
+}
+
 func (m *MockStorage) ReadHeadHash() (types.Hash, bool) {
 	if m.readHeadHashFn != nil {
 		return m.readHeadHashFn()
@@ -575,6 +1179,30 @@ func (m *MockStorage) HookWriteHeadNumber(fn writeHeadNumberDelegate) {
 	m.writeHeadNumberFn = fn
 }
 
+func (m *MockStorage) WritePersistedHead(n uint64) error {
+	if m.writePersistedHeadFn != nil {
+		return m.writePersistedHeadFn(n)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWritePersistedHead(fn writePersistedHeadDelegate) {
+	m.writePersistedHeadFn = fn
+}
+
+func (m *MockStorage) ReadPersistedHead() (uint64, bool) {
+	if m.readPersistedHeadFn != nil {
+		return m.readPersistedHeadFn()
+	}
+
+	return 0, false
+}
+
+func (m *MockStorage) HookReadPersistedHead(fn readPersistedHeadDelegate) {
+	m.readPersistedHeadFn = fn
+}
+
 func (m *MockStorage) WriteForks(forks []types.Hash) error {
 	if m.writeForksFn != nil {
 		return m.writeForksFn(forks)
@@ -599,9 +1227,57 @@ func (m *MockStorage) HookReadForks(fn readForksDelegate) {
 	m.readForksFn = fn
 }
 
-func (m *MockStorage) WriteTotalDifficulty(hash types.Hash, diff *big.Int) error {
+func (m *MockStorage) WriteHaltState(state *HaltState) error {
+	if m.writeHaltStateFn != nil {
+		return m.writeHaltStateFn(state)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWriteHaltState(fn writeHaltStateDelegate) {
+	m.writeHaltStateFn = fn
+}
+
+func (m *MockStorage) ReadHaltState() (*HaltState, bool) {
+	if m.readHaltStateFn != nil {
+		return m.readHaltStateFn()
+	}
+
+	return nil, false
+}
+
+func (m *MockStorage) HookReadHaltState(fn readHaltStateDelegate) {
+	m.readHaltStateFn = fn
+}
+
+func (m *MockStorage) WriteCheckpointAnchor(checkpoint types.Hash, n uint64, hash types.Hash) error {
+	if m.writeCheckpointAnchorFn != nil {
+		return m.writeCheckpointAnchorFn(checkpoint, n, hash)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWriteCheckpointAnchor(fn writeCheckpointAnchorDelegate) {
+	m.writeCheckpointAnchorFn = fn
+}
+
+func (m *MockStorage) ReadCheckpointAnchor(checkpoint types.Hash, n uint64) (types.Hash, bool) {
+	if m.readCheckpointAnchorFn != nil {
+		return m.readCheckpointAnchorFn(checkpoint, n)
+	}
+
+	return types.Hash{}, false
+}
+
+func (m *MockStorage) HookReadCheckpointAnchor(fn readCheckpointAnchorDelegate) {
+	m.readCheckpointAnchorFn = fn
+}
+
+func (m *MockStorage) WriteTotalDifficulty(n uint64, hash types.Hash, diff *big.Int) error {
 	if m.writeTotalDifficultyFn != nil {
-		return m.writeTotalDifficultyFn(hash, diff)
+		return m.writeTotalDifficultyFn(n, hash, diff)
 	}
 
 	return nil
@@ -659,9 +1335,9 @@ func (m *MockStorage) HookWriteCanonicalHeader(fn writeCanonicalHeaderDelegate)
 	m.writeCanonicalHeaderFn = fn
 }
 
-func (m *MockStorage) WriteBody(hash types.Hash, body *types.Body) error {
+func (m *MockStorage) WriteBody(n uint64, hash types.Hash, body *types.Body) error {
 	if m.writeBodyFn != nil {
-		return m.writeBodyFn(hash, body)
+		return m.writeBodyFn(n, hash, body)
 	}
 
 	return nil
@@ -683,9 +1359,9 @@ func (m *MockStorage) HookReadBody(fn readBodyDelegate) {
 	m.readBodyFn = fn
 }
 
-func (m *MockStorage) WriteReceipts(hash types.Hash, receipts []*types.Receipt) error {
+func (m *MockStorage) WriteReceipts(n uint64, hash types.Hash, receipts []*types.Receipt) error {
 	if m.writeReceiptsFn != nil {
-		return m.writeReceiptsFn(hash, receipts)
+		return m.writeReceiptsFn(n, hash, receipts)
 	}
 
 	return nil
@@ -707,6 +1383,30 @@ func (m *MockStorage) HookReadReceipts(fn readReceiptsDelegate) {
 	m.readReceiptsFn = fn
 }
 
+func (m *MockStorage) WriteReceiptsBackfillHead(n uint64) error {
+	if m.writeBackfillHeadFn != nil {
+		return m.writeBackfillHeadFn(n)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWriteReceiptsBackfillHead(fn writeReceiptsBackfillHeadDelegate) {
+	m.writeBackfillHeadFn = fn
+}
+
+func (m *MockStorage) ReadReceiptsBackfillHead() (uint64, bool) {
+	if m.readBackfillHeadFn != nil {
+		return m.readBackfillHeadFn()
+	}
+
+	return 0, false
+}
+
+func (m *MockStorage) HookReadReceiptsBackfillHead(fn readReceiptsBackfillHeadDelegate) {
+	m.readBackfillHeadFn = fn
+}
+
 func (m *MockStorage) WriteTxLookup(hash types.Hash, blockHash types.Hash) error {
 	if m.writeTxLookupFn != nil {
 		return m.writeTxLookupFn(hash, blockHash)
@@ -731,6 +1431,268 @@ func (m *MockStorage) HookReadTxLookup(fn readTxLookupDelegate) {
 	m.readTxLookupFn = fn
 }
 
+func (m *MockStorage) DeleteTxLookup(hash types.Hash) error {
+	if m.deleteTxLookupFn != nil {
+		return m.deleteTxLookupFn(hash)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookDeleteTxLookup(fn deleteTxLookupDelegate) {
+	m.deleteTxLookupFn = fn
+}
+
+func (m *MockStorage) WriteTxLookupTail(n uint64) error {
+	if m.writeTxLookupTailFn != nil {
+		return m.writeTxLookupTailFn(n)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWriteTxLookupTail(fn writeTxLookupTailDelegate) {
+	m.writeTxLookupTailFn = fn
+}
+
+func (m *MockStorage) ReadTxLookupTail() (uint64, bool) {
+	if m.readTxLookupTailFn != nil {
+		return m.readTxLookupTailFn()
+	}
+
+	return 0, false
+}
+
+func (m *MockStorage) HookReadTxLookupTail(fn readTxLookupTailDelegate) {
+	m.readTxLookupTailFn = fn
+}
+
+func (m *MockStorage) WriteLogIndex(n uint64, addresses []types.Address, topics []types.Hash) error {
+	if m.writeLogIndexFn != nil {
+		return m.writeLogIndexFn(n, addresses, topics)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWriteLogIndex(fn writeLogIndexDelegate) {
+	m.writeLogIndexFn = fn
+}
+
+func (m *MockStorage) ReadLogIndexByAddress(addr types.Address, from, to uint64) []uint64 {
+	if m.readLogIndexByAddrFn != nil {
+		return m.readLogIndexByAddrFn(addr, from, to)
+	}
+
+	return []uint64{}
+}
+
+func (m *MockStorage) HookReadLogIndexByAddress(fn readLogIndexByAddressDelegate) {
+	m.readLogIndexByAddrFn = fn
+}
+
+func (m *MockStorage) ReadLogIndexByTopic(topic types.Hash, from, to uint64) []uint64 {
+	if m.readLogIndexByTopicFn != nil {
+		return m.readLogIndexByTopicFn(topic, from, to)
+	}
+
+	return []uint64{}
+}
+
+func (m *MockStorage) HookReadLogIndexByTopic(fn readLogIndexByTopicDelegate) {
+	m.readLogIndexByTopicFn = fn
+}
+
+func (m *MockStorage) ReadStateRootIndex(root types.Hash) []uint64 {
+	if m.readStateRootIndexFn != nil {
+		return m.readStateRootIndexFn(root)
+	}
+
+	return []uint64{}
+}
+
+func (m *MockStorage) HookReadStateRootIndex(fn readStateRootIndexDelegate) {
+	m.readStateRootIndexFn = fn
+}
+
+func (m *MockStorage) WriteLogIndexHead(n uint64) error {
+	if m.writeLogIndexHeadFn != nil {
+		return m.writeLogIndexHeadFn(n)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWriteLogIndexHead(fn writeLogIndexHeadDelegate) {
+	m.writeLogIndexHeadFn = fn
+}
+
+func (m *MockStorage) ReadLogIndexHead() (uint64, bool) {
+	if m.readLogIndexHeadFn != nil {
+		return m.readLogIndexHeadFn()
+	}
+
+	return 0, false
+}
+
+func (m *MockStorage) HookReadLogIndexHead(fn readLogIndexHeadDelegate) {
+	m.readLogIndexHeadFn = fn
+}
+
+func (m *MockStorage) WriteBloomBits(bit uint, section uint64, bits []byte) error {
+	if m.writeBloomBitsFn != nil {
+		return m.writeBloomBitsFn(bit, section, bits)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWriteBloomBits(fn writeBloomBitsDelegate) {
+	m.writeBloomBitsFn = fn
+}
+
+func (m *MockStorage) ReadBloomBits(bit uint, section uint64) ([]byte, bool) {
+	if m.readBloomBitsFn != nil {
+		return m.readBloomBitsFn(bit, section)
+	}
+
+	return nil, false
+}
+
+func (m *MockStorage) HookReadBloomBits(fn readBloomBitsDelegate) {
+	m.readBloomBitsFn = fn
+}
+
+func (m *MockStorage) WriteBloomBitsSections(sections uint64) error {
+	if m.writeBloomSectionsFn != nil {
+		return m.writeBloomSectionsFn(sections)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWriteBloomBitsSections(fn writeBloomBitsSectionsDelegate) {
+	m.writeBloomSectionsFn = fn
+}
+
+func (m *MockStorage) ReadBloomBitsSections() (uint64, bool) {
+	if m.readBloomSectionsFn != nil {
+		return m.readBloomSectionsFn()
+	}
+
+	return 0, false
+}
+
+func (m *MockStorage) HookReadBloomBitsSections(fn readBloomBitsSectionsDelegate) {
+	m.readBloomSectionsFn = fn
+}
+
+func (m *MockStorage) WriteChainStats(hour uint64, stats *ChainStats) error {
+	if m.writeChainStatsFn != nil {
+		return m.writeChainStatsFn(hour, stats)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWriteChainStats(fn writeChainStatsDelegate) {
+	m.writeChainStatsFn = fn
+}
+
+func (m *MockStorage) ReadChainStats(hour uint64) (*ChainStats, bool) {
+	if m.readChainStatsFn != nil {
+		return m.readChainStatsFn(hour)
+	}
+
+	return nil, false
+}
+
+func (m *MockStorage) HookReadChainStats(fn readChainStatsDelegate) {
+	m.readChainStatsFn = fn
+}
+
+func (m *MockStorage) WriteChainStatsHead(n uint64) error {
+	if m.writeChainStatsHeadFn != nil {
+		return m.writeChainStatsHeadFn(n)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWriteChainStatsHead(fn writeChainStatsHeadDelegate) {
+	m.writeChainStatsHeadFn = fn
+}
+
+func (m *MockStorage) ReadChainStatsHead() (uint64, bool) {
+	if m.readChainStatsHeadFn != nil {
+		return m.readChainStatsHeadFn()
+	}
+
+	return 0, false
+}
+
+func (m *MockStorage) HookReadChainStatsHead(fn readChainStatsHeadDelegate) {
+	m.readChainStatsHeadFn = fn
+}
+
+func (m *MockStorage) WriteSystemTxIndex(n uint64, txTypes []string) error {
+	if m.writeSystemTxIndexFn != nil {
+		return m.writeSystemTxIndexFn(n, txTypes)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWriteSystemTxIndex(fn writeSystemTxIndexDelegate) {
+	m.writeSystemTxIndexFn = fn
+}
+
+func (m *MockStorage) ReadSystemTxIndex(txType string, from, to uint64) []uint64 {
+	if m.readSystemTxIndexFn != nil {
+		return m.readSystemTxIndexFn(txType, from, to)
+	}
+
+	return []uint64{}
+}
+
+func (m *MockStorage) HookReadSystemTxIndex(fn readSystemTxIndexDelegate) {
+	m.readSystemTxIndexFn = fn
+}
+
+func (m *MockStorage) WriteSystemTxIndexHead(n uint64) error {
+	if m.writeSystemTxHeadFn != nil {
+		return m.writeSystemTxHeadFn(n)
+	}
+
+	return nil
+}
+
+func (m *MockStorage) HookWriteSystemTxIndexHead(fn writeSystemTxIndexHeadDelegate) {
+	m.writeSystemTxHeadFn = fn
+}
+
+func (m *MockStorage) ReadSystemTxIndexHead() (uint64, bool) {
+	if m.readSystemTxHeadFn != nil {
+		return m.readSystemTxHeadFn()
+	}
+
+	return 0, false
+}
+
+func (m *MockStorage) HookReadSystemTxIndexHead(fn readSystemTxIndexHeadDelegate) {
+	m.readSystemTxHeadFn = fn
+}
+
+// NewBatch returns the batch writing to the hooks of the mock storage directly
+func (m *MockStorage) NewBatch() Batch {
+	return &mockBatch{m}
+}
+
+// NewAtomicBatch returns the batch writing to the hooks of the mock storage directly
+func (m *MockStorage) NewAtomicBatch() Batch {
+	return &mockBatch{m}
+}
+
 func (m *MockStorage) Close() error {
 	if m.closeFn != nil {
 		return m.closeFn()
@@ -742,3 +1704,12 @@ func (m *MockStorage) Close() error {
 func (m *MockStorage) HookClose(fn closeDelegate) {
 	m.closeFn = fn
 }
+
+// mockBatch writes to the mock storage directly
+type mockBatch struct {
+	*MockStorage
+}
+
+func (b *mockBatch) Write() error {
+	return nil
+}
@@ -1,6 +1,9 @@
 package storage
 
 import (
+	"fmt"
+	"math/big"
+
 	"github.com/dogechain-lab/dogechain/types"
 	"github.com/dogechain-lab/fastrlp"
 )
@@ -52,3 +55,127 @@ func (f *Forks) UnmarshalRLPFrom(p *fastrlp.Parser, v *fastrlp.Value) error {
 
 	return nil
 }
+
+// ChainStats is the aggregated usage of the canonical blocks within an hour
+type ChainStats struct {
+	FirstBlock  uint64   // number of the first aggregated block
+	LastBlock   uint64   // number of the last aggregated block
+	Blocks      uint64   // number of the aggregated blocks
+	TxCount     uint64   // number of the transactions
+	GasUsed     uint64   // total gas used
+	GasLimit    uint64   // total gas limit
+	GasPriceSum *big.Int // sum of the transaction gas prices
+}
+
+// MarshalRLPTo is a wrapper function for calling the type marshal implementation
+func (s *ChainStats) MarshalRLPTo(dst []byte) []byte {
+	return types.MarshalRLPTo(s.MarshalRLPWith, dst)
+}
+
+// MarshalRLPWith is the actual RLP marshal implementation for the type
+func (s *ChainStats) MarshalRLPWith(ar *fastrlp.Arena) *fastrlp.Value {
+	vv := ar.NewArray()
+
+	vv.Set(ar.NewUint(s.FirstBlock))
+	vv.Set(ar.NewUint(s.LastBlock))
+	vv.Set(ar.NewUint(s.Blocks))
+	vv.Set(ar.NewUint(s.TxCount))
+	vv.Set(ar.NewUint(s.GasUsed))
+	vv.Set(ar.NewUint(s.GasLimit))
+
+	if s.GasPriceSum == nil {
+		vv.Set(ar.NewBigInt(new(big.Int)))
+	} else {
+		vv.Set(ar.NewBigInt(s.GasPriceSum))
+	}
+
+	return vv
+}
+
+// UnmarshalRLP is a wrapper function for calling the type unmarshal implementation
+func (s *ChainStats) UnmarshalRLP(input []byte) error {
+	return types.UnmarshalRlp(s.UnmarshalRLPFrom, input)
+}
+
+// UnmarshalRLPFrom is the actual RLP unmarshal implementation for the type
+func (s *ChainStats) UnmarshalRLPFrom(p *fastrlp.Parser, v *fastrlp.Value) error {
+	elems, err := v.GetElems()
+	if err != nil {
+		return err
+	}
+
+	if len(elems) != 7 {
+		return fmt.Errorf("incorrect number of elements to decode chain stats, expected 7 but found %d", len(elems))
+	}
+
+	for i, field := range []*uint64{
+		&s.FirstBlock, &s.LastBlock, &s.Blocks, &s.TxCount, &s.GasUsed, &s.GasLimit,
+	} {
+		if *field, err = elems[i].GetUint64(); err != nil {
+			return err
+		}
+	}
+
+	s.GasPriceSum = new(big.Int)
+
+	return elems[6].GetBigInt(s.GasPriceSum)
+}
+
+// HaltState is the persisted emergency halt state of the chain, so that a halted node
+// stays halted across restarts until it is resumed
+type HaltState struct {
+	Halted   bool
+	Operator string // the authenticated caller changing the halt state
+	Reason   string // why the halt state is changed
+	Since    uint64 // unix time of the change
+}
+
+// MarshalRLPTo is a wrapper function for calling the type marshal implementation
+func (s *HaltState) MarshalRLPTo(dst []byte) []byte {
+	return types.MarshalRLPTo(s.MarshalRLPWith, dst)
+}
+
+// MarshalRLPWith is the actual RLP marshal implementation for the type
+func (s *HaltState) MarshalRLPWith(ar *fastrlp.Arena) *fastrlp.Value {
+	vv := ar.NewArray()
+
+	vv.Set(ar.NewBool(s.Halted))
+	vv.Set(ar.NewString(s.Operator))
+	vv.Set(ar.NewString(s.Reason))
+	vv.Set(ar.NewUint(s.Since))
+
+	return vv
+}
+
+// UnmarshalRLP is a wrapper function for calling the type unmarshal implementation
+func (s *HaltState) UnmarshalRLP(input []byte) error {
+	return types.UnmarshalRlp(s.UnmarshalRLPFrom, input)
+}
+
+// UnmarshalRLPFrom is the actual RLP unmarshal implementation for the type
+func (s *HaltState) UnmarshalRLPFrom(p *fastrlp.Parser, v *fastrlp.Value) error {
+	elems, err := v.GetElems()
+	if err != nil {
+		return err
+	}
+
+	if len(elems) != 4 {
+		return fmt.Errorf("incorrect number of elements to decode halt state, expected 4 but found %d", len(elems))
+	}
+
+	if s.Halted, err = elems[0].GetBool(); err != nil {
+		return err
+	}
+
+	if s.Operator, err = elems[1].GetString(); err != nil {
+		return err
+	}
+
+	if s.Reason, err = elems[2].GetString(); err != nil {
+		return err
+	}
+
+	s.Since, err = elems[3].GetUint64()
+
+	return err
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"sync"
 
+	"github.com/dogechain-lab/dogechain/helper/recovery"
+	"github.com/hashicorp/go-hclog"
 	"go.uber.org/atomic"
 )
 
@@ -52,6 +54,9 @@ type subscription struct {
 
 	// closed is a flag that indicates if the subscription is closed
 	closed *atomic.Bool
+
+	// filter restricts which events are delivered, nil means all
+	filter *EventFilter
 }
 
 // GetEvent returns the event from the subscription (BLOCKING)
@@ -78,6 +83,61 @@ const (
 	EventFork                   // Chain fork event
 )
 
+// defaultSubscriptionBufferSize is the event buffer size of a subscription
+const defaultSubscriptionBufferSize = 8
+
+// EventFilter restricts the events delivered to a subscription
+type EventFilter struct {
+	// Types are the event types to deliver, empty means all types
+	Types []EventType
+
+	// MinBlockNumber skips events whose latest header is below it
+	MinBlockNumber uint64
+
+	// BufferSize is the subscription channel size, events are dropped
+	// when it is full. Zero means defaultSubscriptionBufferSize
+	BufferSize int
+}
+
+// match returns true if the event should be delivered
+func (f *EventFilter) match(event *Event) bool {
+	if f == nil {
+		return true
+	}
+
+	if len(f.Types) > 0 {
+		found := false
+
+		for _, typ := range f.Types {
+			if typ == event.Type {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if f.MinBlockNumber > 0 {
+		if len(event.NewChain) == 0 || event.Header().Number < f.MinBlockNumber {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *EventFilter) bufferSize() int {
+	if f == nil || f.BufferSize <= 0 {
+		return defaultSubscriptionBufferSize
+	}
+
+	return f.BufferSize
+}
+
 // eventStream is the structure that contains the event list,
 // as well as the update channel which it uses to notify of updates
 type eventStream struct {
@@ -99,12 +159,16 @@ type eventStream struct {
 	eventCh chan *Event
 
 	isClosed *atomic.Bool
+
+	logger  hclog.Logger
+	metrics *Metrics
 }
 
-func newEventStream(ctx context.Context) *eventStream {
+func newEventStream(ctx context.Context, logger hclog.Logger, metrics *Metrics) *eventStream {
 	streamCtx, cancel := context.WithCancel(ctx)
 
 	stream := &eventStream{
+		logger:      logger,
 		ctx:         streamCtx,
 		ctxCancel:   cancel,
 		updateSubCh: make(map[*subscription]chan *Event),
@@ -112,6 +176,7 @@ func newEventStream(ctx context.Context) *eventStream {
 		subCh:    make(chan *subscription),
 		eventCh:  make(chan *Event),
 		isClosed: atomic.NewBool(false),
+		metrics:  NewDummyMetrics(metrics),
 	}
 
 	go stream.run()
@@ -142,25 +207,10 @@ func (e *eventStream) run() {
 
 			e.lock.Unlock()
 		case event := <-e.eventCh:
-			e.lock.RLock()
-
-			// Notify the listeners
-			for sub, updateCh := range e.updateSubCh {
-				if sub.IsClosed() {
-					closeSub = append(closeSub, sub)
-
-					continue
-				}
-
-				select {
-				case <-e.ctx.Done():
-					return
-				case updateCh <- event:
-				default:
-				}
-			}
-
-			e.lock.RUnlock()
+			// a panic of a filter drops the event only, the dispatcher goes on
+			recovery.Run(e.logger, "blockchain event dispatcher", func() {
+				closeSub = e.dispatch(event, closeSub[:0])
+			})
 
 			e.lock.Lock()
 
@@ -177,6 +227,35 @@ func (e *eventStream) run() {
 	}
 }
 
+// dispatch notifies the listeners of the event, and returns the closed subscriptions
+func (e *eventStream) dispatch(event *Event, closeSub []*subscription) []*subscription {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	for sub, updateCh := range e.updateSubCh {
+		if sub.IsClosed() {
+			closeSub = append(closeSub, sub)
+
+			continue
+		}
+
+		if !sub.filter.match(event) {
+			continue
+		}
+
+		select {
+		case <-e.ctx.Done():
+			return closeSub
+		case updateCh <- event:
+		default:
+			// subscriber is too slow, drop the event
+			e.metrics.SubscriptionEventsDroppedInc()
+		}
+	}
+
+	return closeSub
+}
+
 func (e *eventStream) Close() {
 	if !e.isClosed.CAS(false, true) {
 		return
@@ -193,8 +272,9 @@ func (e *eventStream) Close() {
 	}
 }
 
-// subscribe Creates a new blockchain event subscription
-func (e *eventStream) subscribe() *subscription {
+// subscribe Creates a new blockchain event subscription,
+// only events matching the filter are delivered
+func (e *eventStream) subscribe(filter *EventFilter) *subscription {
 	if e.isClosed.Load() {
 		return nil
 	}
@@ -207,8 +287,9 @@ func (e *eventStream) subscribe() *subscription {
 	}
 
 	sub := &subscription{
-		updateCh: make(chan *Event, 8),
+		updateCh: make(chan *Event, filter.bufferSize()),
 		closed:   atomic.NewBool(false),
+		filter:   filter,
 	}
 
 	select {
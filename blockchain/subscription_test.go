@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -13,8 +14,8 @@ func TestSubscription(t *testing.T) {
 	t.Parallel()
 
 	var (
-		e              = newEventStream(context.Background())
-		sub            = e.subscribe()
+		e              = newEventStream(context.Background(), hclog.NewNullLogger(), NilMetrics())
+		sub            = e.subscribe(nil)
 		caughtEventNum = uint64(0)
 		event          = &Event{
 			NewChain: []*types.Header{
@@ -50,3 +51,41 @@ func TestSubscription(t *testing.T) {
 
 	assert.Equal(t, event.NewChain[0].Number, caughtEventNum)
 }
+
+func TestSubscription_Filter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		e   = newEventStream(context.Background(), hclog.NewNullLogger(), NilMetrics())
+		sub = e.subscribe(&EventFilter{
+			Types:          []EventType{EventHead},
+			MinBlockNumber: 10,
+		})
+	)
+
+	t.Cleanup(func() {
+		e.Close()
+	})
+
+	newEvent := func(typ EventType, number uint64) *Event {
+		return &Event{
+			Type:     typ,
+			NewChain: []*types.Header{{Number: number}},
+		}
+	}
+
+	// filtered by type and block number
+	e.push(newEvent(EventFork, 20))
+	e.push(newEvent(EventHead, 5))
+	e.push(&Event{})
+	// delivered
+	e.push(newEvent(EventHead, 15))
+
+	select {
+	case evnt := <-sub.GetEvent():
+		assert.Equal(t, EventHead, evnt.Type)
+		assert.Equal(t, uint64(15), evnt.Header().Number)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not delivered")
+	}
+}
@@ -0,0 +1,163 @@
+package blockchain
+
+import (
+	"errors"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/dogechain-lab/dogechain/contracts/validatorset"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+// SystemTxType is the type of the consensus system transaction
+type SystemTxType string
+
+const (
+	// SystemTxDeposit deposits the block fees to the validator set contract
+	SystemTxDeposit SystemTxType = "deposit"
+	// SystemTxSlash slashes the validator missing its turn
+	SystemTxSlash SystemTxType = "slash"
+)
+
+// SystemTxTypes are all the system transaction types
+var SystemTxTypes = []SystemTxType{SystemTxDeposit, SystemTxSlash}
+
+// GetSystemTxTypes returns the system transaction type of every transaction of the
+// block, empty for the user transactions
+func (b *Blockchain) GetSystemTxTypes(header *types.Header, txs []*types.Transaction) []SystemTxType {
+	txTypes := make([]SystemTxType, len(txs))
+
+	var (
+		creator    types.Address
+		creatorErr error
+		recovered  bool
+	)
+
+	for i, tx := range txs {
+		// only the calls of the validator set contract are checked further, which
+		// recovers the block creator
+		if !validatorset.IsDepositTransactionSignture(tx.Input) &&
+			!validatorset.IsSlashTransactionSignture(tx.Input) {
+			continue
+		}
+
+		if !recovered {
+			creator, creatorErr = b.consensus.GetBlockCreator(header)
+			recovered = true
+		}
+
+		if creatorErr != nil || !b.consensus.IsSystemTransaction(header.Number, creator, tx) {
+			continue
+		}
+
+		if validatorset.IsDepositTransactionSignture(tx.Input) {
+			txTypes[i] = SystemTxDeposit
+		} else {
+			txTypes[i] = SystemTxSlash
+		}
+	}
+
+	return txTypes
+}
+
+// EnableSystemTxIndex starts indexing the slash transactions of the canonical blocks
+// in background, it should be called after the genesis is computed
+func (b *Blockchain) EnableSystemTxIndex() {
+	if b.systemTxIndexer != nil || b.readOnly {
+		return
+	}
+
+	// the bodies of the queued blocks are not persisted yet
+	b.systemTxIndexer = newSystemTxIndexer(b.logger, b.db, b.persistedHeadNumber, b.GetSystemTxTypes)
+	b.systemTxIndexer.start()
+}
+
+// FilterSystemTxBlocks returns the block numbers within [from, to] which might contain
+// system transactions of the type. Blocks not indexed yet are always included.
+// It returns false when the index is disabled, and for the deposits which are not
+// indexed.
+func (b *Blockchain) FilterSystemTxBlocks(txType SystemTxType, from, to uint64) ([]uint64, bool) {
+	if b.systemTxIndexer == nil || txType != SystemTxSlash {
+		return nil, false
+	}
+
+	if from > to {
+		return []uint64{}, true
+	}
+
+	indexed := b.systemTxIndexer.indexedBlocks()
+	if indexed <= from {
+		return nil, false
+	}
+
+	end := to
+	if indexed-1 < end {
+		end = indexed - 1
+	}
+
+	numbers := b.db.ReadSystemTxIndex(string(txType), from, end)
+
+	// blocks not indexed yet
+	for n := end + 1; n > end && n <= to; n++ {
+		numbers = append(numbers, n)
+	}
+
+	return numbers, true
+}
+
+// systemTxIndexer records the slash transaction -> block number postings of the
+// canonical blocks in background. The deposits are not indexed, they are in every
+// block since the Detroit fork.
+type systemTxIndexer struct {
+	*chainIndexer
+
+	db      storage.Storage
+	typesFn func(*types.Header, []*types.Transaction) []SystemTxType
+}
+
+func newSystemTxIndexer(
+	logger hclog.Logger,
+	db storage.Storage,
+	headFn func() uint64,
+	typesFn func(*types.Header, []*types.Transaction) []SystemTxType,
+) *systemTxIndexer {
+	s := &systemTxIndexer{db: db, typesFn: typesFn}
+
+	// start from genesis if not indexed before, it has no transactions anyway
+	s.chainIndexer = newBlockIndexer(logger.Named("systemtxindex"), headFn, s.indexBlock,
+		db.ReadSystemTxIndexHead, db.WriteSystemTxIndexHead)
+
+	return s
+}
+
+func (s *systemTxIndexer) indexBlock(n uint64) error {
+	hash, ok := s.db.ReadCanonicalHash(n)
+	if !ok {
+		return storage.ErrNotFound
+	}
+
+	body, err := s.db.ReadBody(hash)
+	if errors.Is(err, storage.ErrNotFound) {
+		// header only block, no transactions
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if len(body.Transactions) == 0 {
+		return nil
+	}
+
+	header, err := s.db.ReadHeader(hash)
+	if err != nil {
+		return err
+	}
+
+	for _, txType := range s.typesFn(header, body.Transactions) {
+		if txType == SystemTxSlash {
+			return s.db.WriteSystemTxIndex(n, []string{string(SystemTxSlash)})
+		}
+	}
+
+	return nil
+}
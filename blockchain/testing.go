@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/big"
 	"testing"
 
 	"github.com/dogechain-lab/dogechain/blockchain/storage"
@@ -205,13 +204,12 @@ func NewMockBlockchain(
 		consensus: mockVerifier,
 		executor:  executor,
 		config:    config,
-		stream:    newEventStream(context.Background()),
-		gpAverage: &gasPriceAverage{
-			max:   big.NewInt(0),
-			price: big.NewInt(0),
-			count: big.NewInt(0),
-		},
-		metrics: NilMetrics(),
+		stream:    newEventStream(context.Background(), hclog.NewNullLogger(), NilMetrics()),
+		gpHistory: newGasPriceHistory(DefaultGasPriceHistorySize),
+		writer:    newBlockWriter(hclog.NewNullLogger(), mockStorage, 0),
+		metrics:   NilMetrics(),
+
+		executionStats: newExecutionStatsRing(executionStatsSize),
 	}
 
 	if err := blockchain.initCaches(10); err != nil {
@@ -227,12 +225,14 @@ type verifyHeaderDelegate func(*types.Header) error
 type processHeadersDelegate func([]*types.Header) error
 type getBlockCreatorDelegate func(*types.Header) (types.Address, error)
 type preStateCommitDelegate func(*types.Header, *state.Transition) error
+type isSystemTransactionDelegate func(uint64, types.Address, *types.Transaction) bool
 
 type MockVerifier struct {
-	verifyHeaderFn    verifyHeaderDelegate
-	processHeadersFn  processHeadersDelegate
-	getBlockCreatorFn getBlockCreatorDelegate
-	preStateCommitFn  preStateCommitDelegate
+	verifyHeaderFn        verifyHeaderDelegate
+	processHeadersFn      processHeadersDelegate
+	getBlockCreatorFn     getBlockCreatorDelegate
+	preStateCommitFn      preStateCommitDelegate
+	isSystemTransactionFn isSystemTransactionDelegate
 }
 
 func (m *MockVerifier) VerifyHeader(header *types.Header) error {
@@ -280,9 +280,17 @@ func (m *MockVerifier) PreStateCommit(header *types.Header, txn *state.Transitio
 }
 
 func (m *MockVerifier) IsSystemTransaction(height uint64, coinbase types.Address, tx *types.Transaction) bool {
+	if m.isSystemTransactionFn != nil {
+		return m.isSystemTransactionFn(height, coinbase, tx)
+	}
+
 	return false
 }
 
+func (m *MockVerifier) HookIsSystemTransaction(fn isSystemTransactionDelegate) {
+	m.isSystemTransactionFn = fn
+}
+
 func (m *MockVerifier) HookPreStateCommit(fn preStateCommitDelegate) {
 	m.preStateCommitFn = fn
 }
@@ -0,0 +1,157 @@
+package blockchain
+
+import (
+	"errors"
+
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
+	"github.com/hashicorp/go-hclog"
+)
+
+// txLookupTailFlushInterval is the number of unindexed blocks between the tail writes
+const txLookupTailFlushInterval = 1024
+
+// txLookupUnindexer deletes the transaction lookups of the blocks older than
+// the most recent 'limit' blocks in background
+type txLookupUnindexer struct {
+	logger hclog.Logger
+	db     storage.Storage
+	headFn func() uint64 // returns the current chain head number
+	limit  uint64
+
+	tail uint64 // number of the oldest block with transaction lookups
+
+	notifyCh chan struct{}
+	closeCh  chan struct{}
+	doneCh   chan struct{}
+}
+
+func newTxLookupUnindexer(
+	logger hclog.Logger,
+	db storage.Storage,
+	headFn func() uint64,
+	limit uint64,
+) *txLookupUnindexer {
+	// start from genesis if not unindexed before
+	tail, _ := db.ReadTxLookupTail()
+
+	return &txLookupUnindexer{
+		logger:   logger.Named("txlookup"),
+		db:       db,
+		headFn:   headFn,
+		limit:    limit,
+		tail:     tail,
+		notifyCh: make(chan struct{}, 1),
+		closeCh:  make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+func (u *txLookupUnindexer) start() {
+	go u.run()
+}
+
+func (u *txLookupUnindexer) run() {
+	defer close(u.doneCh)
+
+	// catch up with the chain head first
+	u.unindex()
+
+	for {
+		select {
+		case <-u.closeCh:
+			return
+		case <-u.notifyCh:
+			u.unindex()
+		}
+	}
+}
+
+// notify wakes up the unindexer once the chain head is updated
+func (u *txLookupUnindexer) notify() {
+	select {
+	case u.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (u *txLookupUnindexer) close() {
+	close(u.closeCh)
+	<-u.doneCh
+}
+
+// unindex deletes the transaction lookups up to the retention window
+func (u *txLookupUnindexer) unindex() {
+	head := u.headFn()
+	if head < u.limit {
+		return
+	}
+
+	// the most recent 'limit' blocks keep their lookups
+	end := head - u.limit
+
+	// the deletions are flushed once the batch is large enough, and before the tail
+	// is written, so the tail never passes the lookups left
+	batch := u.db.NewBatch()
+
+	defer u.writeTail(batch)
+
+	for ; u.tail <= end; u.tail++ {
+		select {
+		case <-u.closeCh:
+			return
+		default:
+		}
+
+		if err := u.unindexBlock(batch, u.tail); err != nil {
+			u.logger.Error("failed to delete transaction lookups", "number", u.tail, "err", err)
+
+			return
+		}
+
+		if u.tail%txLookupTailFlushInterval == 0 {
+			u.writeTail(batch)
+		}
+	}
+}
+
+func (u *txLookupUnindexer) writeTail(batch storage.Batch) {
+	if err := batch.Write(); err != nil {
+		u.logger.Error("failed to delete transaction lookups", "number", u.tail, "err", err)
+
+		return
+	}
+
+	if err := u.db.WriteTxLookupTail(u.tail); err != nil {
+		u.logger.Error("failed to write transaction lookup tail", "number", u.tail, "err", err)
+	}
+}
+
+func (u *txLookupUnindexer) unindexBlock(batch storage.Batch, n uint64) error {
+	hash, ok := u.db.ReadCanonicalHash(n)
+	if !ok {
+		return storage.ErrNotFound
+	}
+
+	body, err := u.db.ReadBody(hash)
+	if errors.Is(err, storage.ErrNotFound) {
+		// header only block, no transactions
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, tx := range body.Transactions {
+		txHash := tx.Hash()
+
+		// the transaction might be included in another block after a reorg
+		if blockHash, ok := u.db.ReadTxLookup(txHash); !ok || blockHash != hash {
+			continue
+		}
+
+		if err := batch.DeleteTxLookup(txHash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// VerifyStage is the block verification stage where a block is rejected
+type VerifyStage string
+
+const (
+	VerifyStageCheckpoint VerifyStage = "checkpoint" // trusted checkpoint mismatch
+	VerifyStageSeal       VerifyStage = "seal"       // consensus header verification
+	VerifyStageParent     VerifyStage = "parent"     // parent link and gas limit
+	VerifyStageBody       VerifyStage = "body"       // uncles and transactions roots
+	VerifyStageExecution  VerifyStage = "execution"  // local transaction execution
+	VerifyStageResult     VerifyStage = "result"     // execution result against the header
+)
+
+// VerifyError is the structured reason of a block verification failure. The
+// expected values are the ones the block claims, and the actual values are the
+// ones computed locally. It unwraps to the failure class, such as ErrInvalidStateRoot.
+type VerifyError struct {
+	Stage    VerifyStage
+	Number   uint64
+	Hash     types.Hash
+	Expected string // empty if not a value mismatch
+	Actual   string // empty if not a value mismatch
+	Err      error
+}
+
+func newVerifyError(stage VerifyStage, header *types.Header, err error) *VerifyError {
+	return &VerifyError{
+		Stage:  stage,
+		Number: header.Number,
+		Hash:   header.Hash,
+		Err:    err,
+	}
+}
+
+// withValues sets the mismatched values
+func (e *VerifyError) withValues(expected, actual interface{}) *VerifyError {
+	e.Expected = fmt.Sprint(expected)
+	e.Actual = fmt.Sprint(actual)
+
+	return e
+}
+
+func (e *VerifyError) Error() string {
+	msg := fmt.Sprintf("block %d (%s) failed %s verification: %v", e.Number, e.Hash, e.Stage, e.Err)
+
+	if e.Expected != "" || e.Actual != "" {
+		msg += fmt.Sprintf(", expected %s, actual %s", e.Expected, e.Actual)
+	}
+
+	return msg
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON marshals the error with its reason, so that it could be stored or logged as is
+func (e *VerifyError) MarshalJSON() ([]byte, error) {
+	var reason string
+	if e.Err != nil {
+		reason = e.Err.Error()
+	}
+
+	return json.Marshal(&struct {
+		Stage    VerifyStage `json:"stage"`
+		Number   uint64      `json:"number"`
+		Hash     types.Hash  `json:"hash"`
+		Reason   string      `json:"reason"`
+		Expected string      `json:"expected,omitempty"`
+		Actual   string      `json:"actual,omitempty"`
+	}{
+		Stage:    e.Stage,
+		Number:   e.Number,
+		Hash:     e.Hash,
+		Reason:   reason,
+		Expected: e.Expected,
+		Actual:   e.Actual,
+	})
+}
+
+// LogArgs returns the error fields as the key value pairs of the logger
+func (e *VerifyError) LogArgs() []interface{} {
+	args := []interface{}{
+		"stage", e.Stage,
+		"number", e.Number,
+		"hash", e.Hash,
+		"reason", e.Err,
+	}
+
+	if e.Expected != "" || e.Actual != "" {
+		args = append(args, "expected", e.Expected, "actual", e.Actual)
+	}
+
+	return args
+}
+
+// VerifyErrorLogArgs returns the logger key value pairs of the verification error,
+// with the structured fields if it is a VerifyError
+func VerifyErrorLogArgs(err error) []interface{} {
+	var verifyErr *VerifyError
+	if errors.As(err, &verifyErr) {
+		return verifyErr.LogArgs()
+	}
+
+	return []interface{}{"err", err}
+}
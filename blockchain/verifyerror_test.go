@@ -0,0 +1,51 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyError(t *testing.T) {
+	header := &types.Header{
+		Number:    10,
+		Hash:      types.StringToHash("10"),
+		StateRoot: types.StringToHash("1"),
+	}
+	header.ReceiptsRoot = types.EmptyRootHash
+
+	result := &BlockResult{
+		Root: types.StringToHash("2"),
+	}
+
+	err := result.verifyBlockResult(&types.Block{Header: header}, false)
+	assert.ErrorIs(t, err, ErrInvalidStateRoot)
+
+	var verifyErr *VerifyError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("expected a verify error, got %v", err)
+	}
+
+	assert.Equal(t, VerifyStageResult, verifyErr.Stage)
+	assert.Equal(t, uint64(10), verifyErr.Number)
+	assert.Equal(t, header.Hash, verifyErr.Hash)
+	assert.Equal(t, header.StateRoot.String(), verifyErr.Expected)
+	assert.Equal(t, result.Root.String(), verifyErr.Actual)
+
+	data, err := json.Marshal(verifyErr)
+	assert.NoError(t, err)
+
+	var fields map[string]interface{}
+
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, "result", fields["stage"])
+	assert.Equal(t, ErrInvalidStateRoot.Error(), fields["reason"])
+	assert.Equal(t, verifyErr.Expected, fields["expected"])
+	assert.Equal(t, verifyErr.Actual, fields["actual"])
+
+	assert.Equal(t, []interface{}{"err", ErrClosed}, VerifyErrorLogArgs(ErrClosed))
+	assert.Contains(t, VerifyErrorLogArgs(verifyErr), VerifyStageResult)
+}
@@ -0,0 +1,64 @@
+package blockchain
+
+import (
+	"context"
+)
+
+// WarmupStats are the chain data loaded by the warmup
+type WarmupStats struct {
+	Headers  int // headers loaded into the header cache
+	Receipts int // blocks of which the bodies and receipts are read
+}
+
+// Warmup loads the recent chain data read by the RPCs, so that the first requests
+// after a restart are not slowed by the cold caches. The last headers are loaded
+// into the header cache, and the bodies and receipts of the last blocks are read
+// into the database cache. The older blocks are loaded first, so that the recent
+// ones are kept by the caches.
+func (b *Blockchain) Warmup(ctx context.Context, headers, receipts uint64) (WarmupStats, error) {
+	var stats WarmupStats
+
+	head := b.Header()
+	if head == nil {
+		return stats, nil
+	}
+
+	blocks := headers
+	if receipts > blocks {
+		blocks = receipts
+	}
+
+	if blocks > head.Number+1 {
+		blocks = head.Number + 1
+	}
+
+	for n := head.Number + 1 - blocks; n <= head.Number; n++ {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		if b.isStopped() {
+			return stats, ErrClosed
+		}
+
+		header, ok := b.GetHeaderByNumber(n)
+		if !ok {
+			continue
+		}
+
+		// the headers of the older blocks are read for the receipts only
+		if head.Number-n < headers {
+			stats.Headers++
+		}
+
+		if head.Number-n < receipts {
+			b.readBody(header.Hash)
+
+			if _, err := b.GetReceiptsByHash(header.Hash); err == nil {
+				stats.Receipts++
+			}
+		}
+	}
+
+	return stats, nil
+}
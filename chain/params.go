@@ -2,6 +2,8 @@ package chain
 
 import (
 	"math/big"
+
+	"github.com/dogechain-lab/dogechain/types"
 )
 
 // Params are all the set of params for the chain
@@ -13,6 +15,17 @@ type Params struct {
 	BlackList            []string               `json:"blackList,omitempty"`
 	DDOSProtection       bool                   `json:"ddosProtection,omitempty"`
 	DestructiveContracts []string               `json:"destructiveContracts,omitempty"`
+	Checkpoint           *Checkpoint            `json:"checkpoint,omitempty"`
+}
+
+// Checkpoint is a trusted block of the canonical chain. The syncer links the history
+// before it to the checkpoint hash by the parent hashes, so the seals of those blocks
+// are not verified and their transactions are not executed. The state of the
+// checkpoint is downloaded from the peers as a whole, and verified by its root.
+type Checkpoint struct {
+	Number    uint64     `json:"number"`
+	Hash      types.Hash `json:"hash"`
+	StateRoot types.Hash `json:"stateRoot"`
 }
 
 func (p *Params) GetEngine() string {
@@ -37,6 +50,7 @@ type Forks struct {
 	Preportland    *Fork `json:"pre-portland,omitempty"` // test hardfork only in some test networks
 	Portland       *Fork `json:"portland,omitempty"`     // bridge hardfork
 	Detroit        *Fork `json:"detroit,omitempty"`      // pos hardfork
+	LogsBloom      *Fork `json:"logsBloom,omitempty"`    // header logs bloom validation
 }
 
 func (f *Forks) on(ff *Fork, block uint64) bool {
@@ -91,6 +105,10 @@ func (f *Forks) IsDetroit(block uint64) bool {
 	return f.active(f.Detroit, block)
 }
 
+func (f *Forks) IsLogsBloom(block uint64) bool {
+	return f.active(f.LogsBloom, block)
+}
+
 func (f *Forks) At(block uint64) ForksInTime {
 	return ForksInTime{
 		Homestead:      f.active(f.Homestead, block),
@@ -104,6 +122,7 @@ func (f *Forks) At(block uint64) ForksInTime {
 		Preportland:    f.active(f.Preportland, block),
 		Portland:       f.active(f.Portland, block),
 		Detroit:        f.active(f.Detroit, block),
+		LogsBloom:      f.active(f.LogsBloom, block),
 	}
 }
 
@@ -150,7 +169,8 @@ type ForksInTime struct {
 	EIP155,
 	Preportland,
 	Portland,
-	Detroit bool
+	Detroit,
+	LogsBloom bool
 }
 
 var AllForksEnabled = &Forks{
@@ -0,0 +1,85 @@
+package chain
+
+import (
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	chainCmd := &cobra.Command{
+		Use:   "chain",
+		Short: "Top level command for the emergency operations of the chain. Only accepts subcommands.",
+	}
+
+	helper.RegisterGRPCAddressFlag(chainCmd)
+
+	registerSubcommands(chainCmd)
+
+	return chainCmd
+}
+
+func registerSubcommands(baseCmd *cobra.Command) {
+	baseCmd.AddCommand(
+		// chain halt
+		newHaltCommand(
+			"halt",
+			"Halts the chain at once, the block imports and sealing are stopped until resumed. "+
+				"The chain data is served still",
+			haltMethod,
+			haltChain,
+		),
+		// chain resume
+		newHaltCommand(
+			"resume",
+			"Resumes the block imports and sealing of the halted chain",
+			resumeMethod,
+			resumeChain,
+		),
+	)
+}
+
+func newHaltCommand(use, short, method string, operation haltOperation) *cobra.Command {
+	params := &haltParams{method: method}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Run: func(cmd *cobra.Command, _ []string) {
+			outputter := command.InitializeOutputter(cmd)
+			defer outputter.WriteOutput()
+
+			result, err := params.run(cmd, operation)
+			if err != nil {
+				outputter.SetError(err)
+
+				return
+			}
+
+			outputter.SetCommandResult(result)
+		},
+	}
+
+	setFlags(cmd, params)
+	helper.SetRequiredFlags(cmd, params.getRequiredFlags())
+
+	return cmd
+}
+
+func setFlags(cmd *cobra.Command, params *haltParams) {
+	cmd.Flags().StringVar(
+		&params.operator,
+		operatorFlag,
+		defaultOperator(),
+		"who requests the operation, recorded in the audit log of the node",
+	)
+
+	cmd.Flags().StringVar(
+		&params.reason,
+		reasonFlag,
+		"",
+		"why the operation is requested, recorded in the audit log of the node",
+	)
+
+	helper.RegisterAdminFlags(cmd, &params.admin)
+}
@@ -0,0 +1,92 @@
+package chain
+
+import (
+	"context"
+	"os/user"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/dogechain-lab/dogechain/server/proto"
+	"github.com/spf13/cobra"
+)
+
+const (
+	operatorFlag = "operator"
+	reasonFlag   = "reason"
+)
+
+// the full gRPC methods the operators sign
+const (
+	haltMethod   = "/v1.System/Halt"
+	resumeMethod = "/v1.System/Resume"
+)
+
+// haltOperation halts or resumes the chain
+type haltOperation func(
+	ctx context.Context,
+	client proto.SystemClient,
+	req *proto.HaltRequest,
+) (*proto.HaltStatus, error)
+
+func haltChain(ctx context.Context, client proto.SystemClient, req *proto.HaltRequest) (*proto.HaltStatus, error) {
+	return client.Halt(ctx, req)
+}
+
+func resumeChain(ctx context.Context, client proto.SystemClient, req *proto.HaltRequest) (*proto.HaltStatus, error) {
+	return client.Resume(ctx, req)
+}
+
+type haltParams struct {
+	method   string
+	operator string
+	reason   string
+
+	admin helper.AdminParams
+}
+
+func (p *haltParams) getRequiredFlags() []string {
+	return []string{
+		reasonFlag,
+	}
+}
+
+func (p *haltParams) run(cmd *cobra.Command, operation haltOperation) (command.CommandResult, error) {
+	req := &proto.HaltRequest{
+		Operator: p.operator,
+		Reason:   p.reason,
+	}
+
+	if p.admin.IsSigning() {
+		return p.admin.Sign(cmd, p.method, req)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	ctx, err := p.admin.WithApproval(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := helper.GetSystemClientConnection(ctx, helper.GetGRPCAddress(cmd))
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := operation(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return newHaltResult(status), nil
+}
+
+// defaultOperator returns the name of the current user
+func defaultOperator() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+
+	return ""
+}
@@ -0,0 +1,51 @@
+package chain
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/dogechain-lab/dogechain/server/proto"
+)
+
+type HaltResult struct {
+	Halted   bool   `json:"halted"`
+	Operator string `json:"operator"`
+	Reason   string `json:"reason"`
+	Since    string `json:"since"`
+}
+
+func newHaltResult(status *proto.HaltStatus) *HaltResult {
+	result := &HaltResult{
+		Halted:   status.Halted,
+		Operator: status.Operator,
+		Reason:   status.Reason,
+	}
+
+	if status.Since != 0 {
+		result.Since = time.Unix(status.Since, 0).UTC().Format(time.RFC3339)
+	}
+
+	return result
+}
+
+func (r *HaltResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	state := "running"
+	if r.Halted {
+		state = "halted"
+	}
+
+	buffer.WriteString("\n[CHAIN HALT]\n")
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("State|%s", state),
+		fmt.Sprintf("Changed by|%s", r.Operator),
+		fmt.Sprintf("Reason|%s", r.Reason),
+		fmt.Sprintf("Since|%s", r.Since),
+	}))
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
@@ -5,8 +5,18 @@ const (
 	NoDiscoverFlag = "no-discover"
 
 	IgnoreDiscoverCIDRFlag = "ignore-discover-cidr"
+	AllowCIDRFlag          = "allow-cidr"
+	DenyCIDRFlag           = "deny-cidr"
+	DenyASNFlag            = "deny-asn"
 
 	BootnodeFlag   = "bootnode"
 	StaticnodeFlag = "staticnode"
 	LogLevelFlag   = "log-level"
+
+	AdminTokenFileFlag = "admin-token-file"
+	AdminSignatureFlag = "admin-signature"
+	AdminExpiryFlag    = "admin-expiry"
+	AdminChainIDFlag   = "admin-chain-id"
+	AdminNodeIDFlag    = "admin-node-id"
+	SignKeyFlag        = "sign-key"
 )
@@ -0,0 +1,86 @@
+package compact
+
+import (
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	compactCmd := &cobra.Command{
+		Use: "compact",
+		Short: "Compacts the databases of the running node, reclaiming the space of the pruned " +
+			"and deleted pairs. It blocks until the compaction is done",
+		PreRunE: runPreRun,
+		Run:     runCommand,
+	}
+
+	helper.RegisterGRPCAddressFlag(compactCmd)
+
+	setFlags(compactCmd)
+
+	return compactCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.db,
+		dbFlag,
+		"",
+		fmt.Sprintf("the database to compact, %s or %s. All the databases are compacted if omitted", trieDB, blockchainDB),
+	)
+
+	cmd.Flags().StringVar(
+		&params.startRaw,
+		startFlag,
+		"",
+		"the hex encoded first key of the compaction range, the first key of the database if omitted",
+	)
+
+	cmd.Flags().StringVar(
+		&params.limitRaw,
+		limitFlag,
+		"",
+		"the hex encoded key next to the last one of the compaction range, the last key of the database if omitted",
+	)
+
+	helper.RegisterAdminFlags(cmd, &params.admin)
+}
+
+func runPreRun(_ *cobra.Command, _ []string) error {
+	return params.validateFlags()
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	if params.admin.IsSigning() {
+		result, err := params.sign(cmd)
+		if err != nil {
+			outputter.SetError(err)
+
+			return
+		}
+
+		outputter.SetCommandResult(result)
+
+		return
+	}
+
+	resp, err := params.compact(cmd)
+	if err != nil {
+		outputter.SetError(err)
+
+		return
+	}
+
+	outputter.SetCommandResult(&CompactResult{
+		Databases: resp.Databases,
+		Start:     params.startRaw,
+		Limit:     params.limitRaw,
+		Elapsed:   resp.Elapsed,
+	})
+}
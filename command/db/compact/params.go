@@ -0,0 +1,101 @@
+package compact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/server/proto"
+	"github.com/spf13/cobra"
+)
+
+const (
+	dbFlag    = "db"
+	startFlag = "start"
+	limitFlag = "limit"
+)
+
+// the databases compacted by the server
+const (
+	trieDB       = "trie"
+	blockchainDB = "blockchain"
+)
+
+// compactMethod is the full gRPC method the operators sign
+const compactMethod = "/v1.System/Compact"
+
+var (
+	params = &compactParams{}
+)
+
+var (
+	errInvalidDB = errors.New("invalid database")
+)
+
+type compactParams struct {
+	db       string
+	startRaw string
+	limitRaw string
+
+	start []byte
+	limit []byte
+
+	admin helper.AdminParams
+}
+
+func (p *compactParams) validateFlags() error {
+	if p.db != "" && p.db != trieDB && p.db != blockchainDB {
+		return fmt.Errorf("%w: %s, expected %s or %s", errInvalidDB, p.db, trieDB, blockchainDB)
+	}
+
+	var err error
+
+	if p.startRaw != "" {
+		if p.start, err = hex.DecodeHex(p.startRaw); err != nil {
+			return fmt.Errorf("invalid start key: %w", err)
+		}
+	}
+
+	if p.limitRaw != "" {
+		if p.limit, err = hex.DecodeHex(p.limitRaw); err != nil {
+			return fmt.Errorf("invalid limit key: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (p *compactParams) request() *proto.CompactRequest {
+	return &proto.CompactRequest{
+		Database: p.db,
+		Start:    p.start,
+		Limit:    p.limit,
+	}
+}
+
+// sign signs the request by the operator key, instead of requesting it
+func (p *compactParams) sign(cmd *cobra.Command) (command.CommandResult, error) {
+	return p.admin.Sign(cmd, compactMethod, p.request())
+}
+
+func (p *compactParams) compact(cmd *cobra.Command) (*proto.CompactResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	systemClient, err := helper.GetSystemClientConnection(ctx, helper.GetGRPCAddress(cmd))
+	if err != nil {
+		return nil, err
+	}
+
+	// the compaction might take a long while on a large database
+	adminCtx, err := p.admin.WithApproval(context.Background(), cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return systemClient.Compact(adminCtx, p.request())
+}
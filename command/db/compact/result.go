@@ -0,0 +1,40 @@
+package compact
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/dogechain-lab/dogechain/command/helper"
+)
+
+type CompactResult struct {
+	Databases []string `json:"databases"`
+	Start     string   `json:"start,omitempty"`
+	Limit     string   `json:"limit,omitempty"`
+	Elapsed   string   `json:"elapsed"`
+}
+
+func (r *CompactResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	start, limit := r.Start, r.Limit
+	if start == "" {
+		start = "first key"
+	}
+
+	if limit == "" {
+		limit = "last key"
+	}
+
+	buffer.WriteString("\n[DB COMPACT]\n")
+	buffer.WriteString("Compacted the databases successfully:\n")
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("Databases|%s", strings.Join(r.Databases, ", ")),
+		fmt.Sprintf("Range|%s - %s", start, limit),
+		fmt.Sprintf("Elapsed|%s", r.Elapsed),
+	}))
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
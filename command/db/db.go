@@ -0,0 +1,30 @@
+package db
+
+import (
+	"github.com/dogechain-lab/dogechain/command/db/compact"
+	"github.com/dogechain-lab/dogechain/command/db/migrate"
+	"github.com/dogechain-lab/dogechain/command/db/preimages"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	dbCmd := &cobra.Command{
+		Use:   "db",
+		Short: "Top level command for maintaining the databases of the node. Only accepts subcommands.",
+	}
+
+	registerSubcommands(dbCmd)
+
+	return dbCmd
+}
+
+func registerSubcommands(baseCmd *cobra.Command) {
+	baseCmd.AddCommand(
+		// db migrate
+		migrate.GetCommand(),
+		// db compact
+		compact.GetCommand(),
+		// db preimages
+		preimages.GetCommand(),
+	)
+}
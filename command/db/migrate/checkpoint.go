@@ -0,0 +1,67 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/dogechain-lab/dogechain/helper/hex"
+)
+
+var errCheckpointMismatch = errors.New("the checkpoint is of another migration")
+
+// checkpoint is the progress of an interrupted migration, which is resumed from
+// the key next to the last one written
+type checkpoint struct {
+	From     string `json:"from"`
+	FromPath string `json:"from_path"`
+	To       string `json:"to"`
+	Last     string `json:"last"`
+	Pairs    uint64 `json:"pairs"`
+	Size     uint64 `json:"size"`
+}
+
+// readCheckpoint reads the checkpoint of the migration, it returns nil if missing
+func readCheckpoint(p *migrateParams) (*checkpoint, error) {
+	data, err := ioutil.ReadFile(p.checkpointPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	cp := &checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", p.checkpointPath(), err)
+	}
+
+	if cp.From != p.from || cp.FromPath != p.fromPath || cp.To != p.to {
+		return nil, fmt.Errorf("%w: %s from %s to %s, remove %s to restart",
+			errCheckpointMismatch, cp.FromPath, cp.From, cp.To, p.checkpointPath())
+	}
+
+	return cp, nil
+}
+
+// lastKey returns the last key written
+func (cp *checkpoint) lastKey() ([]byte, error) {
+	return hex.DecodeHex(cp.Last)
+}
+
+func (cp *checkpoint) write(path string) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	// never leave a partial checkpoint
+	return os.Rename(tmp, path)
+}
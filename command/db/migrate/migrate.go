@@ -0,0 +1,202 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
+	"github.com/hashicorp/go-hclog"
+	"github.com/spf13/cobra"
+)
+
+// progressLogInterval is the interval logging the progress
+const progressLogInterval = 10 * time.Second
+
+var errTargetNotEmpty = errors.New("the target database is not empty")
+
+func GetCommand() *cobra.Command {
+	migrateCmd := &cobra.Command{
+		Use: "migrate",
+		Short: "Copies all the key/value pairs of a database to another backend. " +
+			"The node should be stopped, and the interrupted migration is resumed by running it again",
+		PreRunE: runPreRun,
+		Run:     runCommand,
+	}
+
+	setFlags(migrateCmd)
+	helper.SetRequiredFlags(migrateCmd, params.getRequiredFlags())
+
+	return migrateCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.from,
+		fromFlag,
+		levelDBBackend,
+		fmt.Sprintf("the backend of the source database, %s or %s (needs the %s build tag)",
+			levelDBBackend, badgerDBBackend, badgerDBBackend),
+	)
+
+	cmd.Flags().StringVar(
+		&params.to,
+		toFlag,
+		badgerDBBackend,
+		fmt.Sprintf("the backend of the target database, %s or %s (needs the %s build tag)",
+			levelDBBackend, badgerDBBackend, badgerDBBackend),
+	)
+
+	cmd.Flags().StringVar(
+		&params.fromPath,
+		fromPathFlag,
+		"",
+		"the directory of the source database, such as <data-dir>/blockchain",
+	)
+
+	cmd.Flags().StringVar(
+		&params.toPath,
+		toPathFlag,
+		"",
+		"the directory of the target database",
+	)
+
+	cmd.Flags().IntVar(
+		&params.batchSize,
+		batchSizeFlag,
+		kvdb.DefaultMigrateBatchSize/1024/1024,
+		"the size of the pairs written in one batch, in MiB",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.verify,
+		verifyFlag,
+		true,
+		"compare all the pairs of both databases once copied",
+	)
+}
+
+func runPreRun(_ *cobra.Command, _ []string) error {
+	return params.validateFlags()
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:  "db-migrate",
+		Level: hclog.Info,
+	})
+
+	result, err := migrate(logger, params)
+	if err != nil {
+		outputter.SetError(err)
+
+		return
+	}
+
+	outputter.SetCommandResult(result)
+}
+
+func migrate(logger hclog.Logger, p *migrateParams) (*MigrateResult, error) {
+	cp, err := readCheckpoint(p)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := openStorage(logger.Named("source"), p.from, p.fromPath, true)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	dst, err := openStorage(logger.Named("target"), p.to, p.toPath, false)
+	if err != nil {
+		return nil, err
+	}
+	defer dst.Close()
+
+	var start []byte
+
+	if cp == nil {
+		if !isEmpty(dst) {
+			return nil, fmt.Errorf("%w: %s", errTargetNotEmpty, p.toPath)
+		}
+
+		cp = &checkpoint{From: p.from, FromPath: p.fromPath, To: p.to}
+	} else {
+		last, err := cp.lastKey()
+		if err != nil {
+			return nil, err
+		}
+
+		start = kvdb.MigrateNextKey(last)
+
+		logger.Info("resume migration", "pairs", cp.Pairs, "size", cp.Size, "last", cp.Last)
+	}
+
+	var (
+		begin        = time.Now()
+		lastLogged   = begin
+		resumedPairs = cp.Pairs
+		resumedSize  = cp.Size
+	)
+
+	err = kvdb.Migrate(src, dst, start, p.batchSize*1024*1024, func(last []byte, pairs, size uint64) error {
+		cp.Last = hex.EncodeToHex(last)
+		cp.Pairs = resumedPairs + pairs
+		cp.Size = resumedSize + size
+
+		if time.Since(lastLogged) >= progressLogInterval {
+			lastLogged = time.Now()
+
+			logger.Info("migrating", "pairs", cp.Pairs, "size", cp.Size, "last", cp.Last,
+				"elapsed", time.Since(begin).Round(time.Second))
+		}
+
+		return cp.write(p.checkpointPath())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("migrated", "pairs", cp.Pairs, "size", cp.Size, "elapsed", time.Since(begin).Round(time.Second))
+
+	result := &MigrateResult{
+		From:     p.from,
+		FromPath: p.fromPath,
+		To:       p.to,
+		ToPath:   p.toPath,
+		Pairs:    cp.Pairs,
+		Size:     cp.Size,
+	}
+
+	if p.verify {
+		logger.Info("verifying")
+
+		if _, err := kvdb.VerifyMigration(src, dst); err != nil {
+			return nil, err
+		}
+
+		result.Verified = true
+	}
+
+	// the migration is done, the checkpoint is kept until verified
+	if err := os.Remove(p.checkpointPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func isEmpty(db kvdb.KVBatchStorage) bool {
+	iter := db.Iterator(nil)
+	defer iter.Release()
+
+	return !iter.First()
+}
@@ -0,0 +1,156 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPairs = 2000
+
+// newTestParams returns the params migrating a leveldb source of test pairs to a
+// leveldb target
+func newTestParams(t *testing.T) *migrateParams {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	p := &migrateParams{
+		from:      levelDBBackend,
+		to:        levelDBBackend,
+		fromPath:  filepath.Join(dir, "source"),
+		toPath:    filepath.Join(dir, "target"),
+		batchSize: 1,
+		verify:    true,
+	}
+
+	src, err := openStorage(hclog.NewNullLogger(), p.from, p.fromPath, false)
+	assert.NoError(t, err)
+
+	// about 2 MiB, written in several batches
+	value := make([]byte, 1024)
+
+	for i := 0; i < testPairs; i++ {
+		assert.NoError(t, src.Set([]byte(fmt.Sprintf("key-%05d", i)), value))
+	}
+
+	assert.NoError(t, src.Close())
+	assert.NoError(t, p.validateFlags())
+
+	return p
+}
+
+// interrupt copies the pairs like an interrupted migration: the checkpoint is
+// written after the first batch, and the process stops after writing the second
+// batch but before checkpointing it. It returns the last key checkpointed.
+func interrupt(t *testing.T, p *migrateParams) []byte {
+	t.Helper()
+
+	src, err := openStorage(hclog.NewNullLogger(), p.from, p.fromPath, true)
+	assert.NoError(t, err)
+
+	defer src.Close()
+
+	dst, err := openStorage(hclog.NewNullLogger(), p.to, p.toPath, false)
+	assert.NoError(t, err)
+
+	defer dst.Close()
+
+	var (
+		errStop      = errors.New("stop")
+		cp           = &checkpoint{From: p.from, FromPath: p.fromPath, To: p.to}
+		checkpointed []byte
+		batches      = 0
+	)
+
+	err = kvdb.Migrate(src, dst, nil, 512*1024, func(last []byte, pairs, size uint64) error {
+		batches++
+		if batches > 1 {
+			return errStop
+		}
+
+		checkpointed = append([]byte{}, last...)
+		cp.Last, cp.Pairs, cp.Size = hex.EncodeToHex(last), pairs, size
+
+		return cp.write(p.checkpointPath())
+	})
+	assert.ErrorIs(t, err, errStop)
+
+	return checkpointed
+}
+
+func TestMigrate(t *testing.T) {
+	p := newTestParams(t)
+
+	result, err := migrate(hclog.NewNullLogger(), p)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(testPairs), result.Pairs)
+	assert.True(t, result.Verified)
+
+	// the checkpoint is removed once verified
+	_, err = os.Stat(p.checkpointPath())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// the migrated target is never migrated to again
+	_, err = migrate(hclog.NewNullLogger(), p)
+	assert.ErrorIs(t, err, errTargetNotEmpty)
+}
+
+func TestMigrate_Resume(t *testing.T) {
+	p := newTestParams(t)
+
+	last := interrupt(t, p)
+	assert.NotNil(t, last)
+
+	cp, err := readCheckpoint(p)
+	assert.NoError(t, err)
+	assert.Less(t, cp.Pairs, uint64(testPairs))
+
+	// the pairs written after the checkpoint are copied again, and counted once
+	result, err := migrate(hclog.NewNullLogger(), p)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(testPairs), result.Pairs)
+	assert.True(t, result.Verified)
+
+	_, err = os.Stat(p.checkpointPath())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestMigrate_ResumeOtherMigration(t *testing.T) {
+	p := newTestParams(t)
+
+	interrupt(t, p)
+
+	other := *p
+	other.fromPath = filepath.Join(filepath.Dir(p.fromPath), "other")
+
+	_, err := migrate(hclog.NewNullLogger(), &other)
+	assert.ErrorIs(t, err, errCheckpointMismatch)
+}
+
+func TestMigrate_VerifyMismatch(t *testing.T) {
+	p := newTestParams(t)
+
+	last := interrupt(t, p)
+
+	// a pair copied before the checkpoint is corrupted in the target, which is not
+	// copied again on resuming
+	dst, err := openStorage(hclog.NewNullLogger(), p.to, p.toPath, false)
+	assert.NoError(t, err)
+	assert.NoError(t, dst.Set(last, []byte("corrupted")))
+	assert.NoError(t, dst.Close())
+
+	_, err = migrate(hclog.NewNullLogger(), p)
+	assert.ErrorIs(t, err, kvdb.ErrMigrateMismatch)
+
+	// the checkpoint is kept since not verified
+	_, err = os.Stat(p.checkpointPath())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,106 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	fromFlag      = "from"
+	toFlag        = "to"
+	fromPathFlag  = "from-path"
+	toPathFlag    = "to-path"
+	batchSizeFlag = "batch-size"
+	verifyFlag    = "verify"
+)
+
+// the kvdb backends supported
+const (
+	levelDBBackend  = "leveldb"
+	badgerDBBackend = "badgerdb"
+)
+
+var (
+	params = &migrateParams{}
+)
+
+var (
+	errUnsupportedBackend = errors.New("unsupported database backend")
+	errSamePath           = errors.New("the source and the target database are the same")
+	errInvalidBatchSize   = errors.New("batch size must be positive")
+)
+
+type migrateParams struct {
+	from     string
+	to       string
+	fromPath string
+	toPath   string
+
+	batchSize int // MiB
+	verify    bool
+}
+
+func (p *migrateParams) getRequiredFlags() []string {
+	return []string{
+		fromPathFlag,
+		toPathFlag,
+	}
+}
+
+func (p *migrateParams) validateFlags() error {
+	for _, backend := range []string{p.from, p.to} {
+		if backend != levelDBBackend && backend != badgerDBBackend {
+			return fmt.Errorf("%w: %s", errUnsupportedBackend, backend)
+		}
+	}
+
+	if p.batchSize <= 0 {
+		return errInvalidBatchSize
+	}
+
+	var err error
+
+	if p.fromPath, err = filepath.Abs(p.fromPath); err != nil {
+		return err
+	}
+
+	if p.toPath, err = filepath.Abs(p.toPath); err != nil {
+		return err
+	}
+
+	if p.fromPath == p.toPath {
+		return errSamePath
+	}
+
+	// never create the source database by mistake
+	if _, err := os.Stat(p.fromPath); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkpointPath is the file tracking the progress, next to the target database
+func (p *migrateParams) checkpointPath() string {
+	return p.toPath + ".migrate"
+}
+
+func openStorage(logger hclog.Logger, backend, path string, readOnly bool) (kvdb.KVBatchStorage, error) {
+	switch backend {
+	case levelDBBackend:
+		return kvdb.NewLevelDBBuilder(logger, path).
+			SetReadOnly(readOnly).
+			Build()
+	case badgerDBBackend:
+		return kvdb.NewBadgerDBBuilder(logger, path).
+			SetReadOnly(readOnly).
+			Build()
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedBackend, backend)
+	}
+}
@@ -0,0 +1,35 @@
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/command/helper"
+)
+
+type MigrateResult struct {
+	From     string `json:"from"`
+	FromPath string `json:"from_path"`
+	To       string `json:"to"`
+	ToPath   string `json:"to_path"`
+	Pairs    uint64 `json:"pairs"`
+	Size     uint64 `json:"size"`
+	Verified bool   `json:"verified"`
+}
+
+func (r *MigrateResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("\n[DB MIGRATE]\n")
+	buffer.WriteString("Migrated the database successfully:\n")
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("From|%s (%s)", r.FromPath, r.From),
+		fmt.Sprintf("To|%s (%s)", r.ToPath, r.To),
+		fmt.Sprintf("Pairs|%d", r.Pairs),
+		fmt.Sprintf("Size|%d bytes", r.Size),
+		fmt.Sprintf("Verified|%v", r.Verified),
+	}))
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
@@ -0,0 +1,49 @@
+package preimages
+
+import (
+	"errors"
+
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+const (
+	dataDirFlag     = "data-dir"
+	genesisPathFlag = "chain"
+	startHeightFlag = "start-height"
+)
+
+var (
+	params = &preimagesParams{}
+)
+
+var (
+	errInvalidStartHeight = errors.New("start height must be greater than 0")
+)
+
+type preimagesParams struct {
+	dataDir     string
+	genesisPath string
+
+	startHeightRaw string
+	startHeight    uint64
+}
+
+func (p *preimagesParams) getRequiredFlags() []string {
+	return []string{
+		dataDirFlag,
+	}
+}
+
+func (p *preimagesParams) validateFlags() error {
+	var err error
+
+	if p.startHeight, err = types.ParseUint64orHex(&p.startHeightRaw); err != nil {
+		return err
+	}
+
+	if p.startHeight == 0 {
+		return errInvalidStartHeight
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package preimages
+
+import (
+	"time"
+
+	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/dogechain-lab/dogechain/reverify"
+	"github.com/hashicorp/go-hclog"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	preimagesCmd := &cobra.Command{
+		Use: "preimages",
+		Short: "Records the addresses and storage slots hashed to the state trie keys, by " +
+			"re-executing the blocks from the start height. The node should be stopped",
+		PreRunE: runPreRun,
+		Run:     runCommand,
+	}
+
+	setFlags(preimagesCmd)
+	helper.SetRequiredFlags(preimagesCmd, params.getRequiredFlags())
+
+	return preimagesCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.dataDir,
+		dataDirFlag,
+		"",
+		"the data directory of the node",
+	)
+
+	cmd.Flags().StringVar(
+		&params.genesisPath,
+		genesisPathFlag,
+		"./genesis.json",
+		"the genesis file path",
+	)
+
+	cmd.Flags().StringVar(
+		&params.startHeightRaw,
+		startHeightFlag,
+		"1",
+		"the height of the first re-executed block",
+	)
+}
+
+func runPreRun(_ *cobra.Command, _ []string) error {
+	return params.validateFlags()
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:  "db-preimages",
+		Level: hclog.Info,
+	})
+
+	genesis, err := chain.Import(params.genesisPath)
+	if err != nil {
+		outputter.SetError(err)
+
+		return
+	}
+
+	begin := time.Now()
+
+	end, err := reverify.BackfillPreimages(logger, genesis, params.dataDir, params.startHeight)
+	if err != nil {
+		outputter.SetError(err)
+
+		return
+	}
+
+	outputter.SetCommandResult(&PreimagesResult{
+		DataDir:     params.dataDir,
+		StartHeight: params.startHeight,
+		EndHeight:   end,
+		Elapsed:     time.Since(begin).Round(time.Second).String(),
+	})
+}
@@ -0,0 +1,30 @@
+package preimages
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/command/helper"
+)
+
+type PreimagesResult struct {
+	DataDir     string `json:"data_dir"`
+	StartHeight uint64 `json:"start_height"`
+	EndHeight   uint64 `json:"end_height"`
+	Elapsed     string `json:"elapsed"`
+}
+
+func (r *PreimagesResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("\n[DB PREIMAGES]\n")
+	buffer.WriteString("Recorded the preimages successfully:\n")
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("Data Dir|%s", r.DataDir),
+		fmt.Sprintf("Blocks|%d - %d", r.StartHeight, r.EndHeight),
+		fmt.Sprintf("Elapsed|%s", r.Elapsed),
+	}))
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
@@ -14,6 +14,7 @@ const (
 
 const (
 	JSONOutputFlag     = "json"
+	OutputFlag         = "output"
 	GRPCAddressFlag    = "grpc-address"
 	JSONRPCFlag        = "jsonrpc"
 	GraphQLAddressFlag = "graphql-address"
@@ -0,0 +1,64 @@
+package dryrun
+
+import (
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	dryRunCmd := &cobra.Command{
+		Use: "dry-run",
+		Short: "Verifies and executes a sealed block on the running node the way it is imported, " +
+			"and prints the would-be state root and receipts. Nothing is written to the chain",
+		PreRunE: runPreRun,
+		Run:     runCommand,
+	}
+
+	helper.RegisterGRPCAddressFlag(dryRunCmd)
+
+	setFlags(dryRunCmd)
+
+	return dryRunCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.rlpRaw,
+		rlpFlag,
+		"",
+		"the hex encoded RLP of the sealed block",
+	)
+
+	cmd.Flags().StringVar(
+		&params.file,
+		fileFlag,
+		"",
+		"the file of the hex encoded RLP of the sealed block",
+	)
+}
+
+func runPreRun(_ *cobra.Command, _ []string) error {
+	return params.validateFlags()
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	resp, err := params.dryRun(helper.GetGRPCAddress(cmd))
+	if err != nil {
+		outputter.SetError(err)
+
+		return
+	}
+
+	result, err := newDryRunResult(resp)
+	if err != nil {
+		outputter.SetError(err)
+
+		return
+	}
+
+	outputter.SetCommandResult(result)
+}
@@ -0,0 +1,74 @@
+package dryrun
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/server/proto"
+)
+
+const (
+	rlpFlag  = "rlp"
+	fileFlag = "file"
+)
+
+var (
+	params = &dryRunParams{}
+)
+
+var (
+	errNoSource       = errors.New("one of the rlp and file should be set")
+	errMultipleSource = errors.New("only one of the rlp and file could be set")
+)
+
+type dryRunParams struct {
+	rlpRaw string
+	file   string
+
+	block []byte
+}
+
+func (p *dryRunParams) validateFlags() error {
+	switch {
+	case p.rlpRaw == "" && p.file == "":
+		return errNoSource
+	case p.rlpRaw != "" && p.file != "":
+		return errMultipleSource
+	}
+
+	raw := p.rlpRaw
+
+	if p.file != "" {
+		content, err := os.ReadFile(p.file)
+		if err != nil {
+			return err
+		}
+
+		raw = strings.TrimSpace(string(content))
+	}
+
+	var err error
+
+	p.block, err = hex.DecodeHex(raw)
+
+	return err
+}
+
+func (p *dryRunParams) dryRun(grpcAddress string) (*proto.DryRunBlockResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	systemClient, err := helper.GetSystemClientConnection(ctx, grpcAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return systemClient.DryRunBlock(ctx, &proto.DryRunBlockRequest{
+		Block: p.block,
+	})
+}
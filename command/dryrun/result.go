@@ -0,0 +1,101 @@
+package dryrun
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/server/proto"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+type ReceiptResult struct {
+	Status            uint64 `json:"status"`
+	CumulativeGasUsed uint64 `json:"cumulativeGasUsed"`
+	Logs              int    `json:"logs"`
+}
+
+type DryRunResult struct {
+	Number       uint64           `json:"number"`
+	Hash         string           `json:"hash"`
+	Valid        bool             `json:"valid"`
+	Error        string           `json:"error,omitempty"`
+	StateRoot    string           `json:"stateRoot"`
+	ReceiptsRoot string           `json:"receiptsRoot"`
+	GasUsed      uint64           `json:"gasUsed"`
+	Receipts     []*ReceiptResult `json:"receipts"`
+	ReceiptsRLP  string           `json:"receiptsRlp"`
+	Elapsed      string           `json:"elapsed"`
+}
+
+func newDryRunResult(resp *proto.DryRunBlockResponse) (*DryRunResult, error) {
+	var receipts types.Receipts
+	if err := receipts.UnmarshalRLP(resp.Receipts); err != nil {
+		return nil, fmt.Errorf("invalid receipts rlp: %w", err)
+	}
+
+	result := &DryRunResult{
+		Number:       resp.Number,
+		Hash:         resp.Hash,
+		Valid:        resp.Error == "",
+		Error:        resp.Error,
+		StateRoot:    resp.StateRoot,
+		ReceiptsRoot: resp.ReceiptsRoot,
+		GasUsed:      resp.GasUsed,
+		Receipts:     make([]*ReceiptResult, len(receipts)),
+		ReceiptsRLP:  hex.EncodeToHex(resp.Receipts),
+		Elapsed:      resp.Elapsed,
+	}
+
+	for i, receipt := range receipts {
+		result.Receipts[i] = &ReceiptResult{
+			CumulativeGasUsed: receipt.CumulativeGasUsed,
+			Logs:              len(receipt.Logs),
+		}
+
+		if receipt.Status != nil {
+			result.Receipts[i].Status = uint64(*receipt.Status)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *DryRunResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	rows := []string{
+		fmt.Sprintf("Number|%d", r.Number),
+		fmt.Sprintf("Hash|%s", r.Hash),
+		fmt.Sprintf("Valid|%t", r.Valid),
+	}
+
+	if r.Error != "" {
+		rows = append(rows, fmt.Sprintf("Error|%s", r.Error))
+	}
+
+	rows = append(rows,
+		fmt.Sprintf("State Root|%s", r.StateRoot),
+		fmt.Sprintf("Receipts Root|%s", r.ReceiptsRoot),
+		fmt.Sprintf("Gas Used|%d", r.GasUsed),
+		fmt.Sprintf("Elapsed|%s", r.Elapsed),
+	)
+
+	buffer.WriteString("\n[DRY RUN]\n")
+	buffer.WriteString(helper.FormatKV(rows))
+	buffer.WriteString("\n")
+
+	receipts := make([]string, len(r.Receipts)+1)
+	receipts[0] = "INDEX|STATUS|CUMULATIVE GAS|LOGS"
+
+	for i, receipt := range r.Receipts {
+		receipts[i+1] = fmt.Sprintf("%d|%d|%d|%d", i, receipt.Status, receipt.CumulativeGasUsed, receipt.Logs)
+	}
+
+	buffer.WriteString("\n[RECEIPTS]\n")
+	buffer.WriteString(helper.FormatList(receipts))
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
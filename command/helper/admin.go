@@ -0,0 +1,186 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	protobuf "google.golang.org/protobuf/proto"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/server"
+)
+
+// defaultApprovalTTL is the time the operators have to approve the operation, if the
+// expiry is not set by the first one signing
+const defaultApprovalTTL = 30 * time.Minute
+
+// AdminParams are the operator approval of an admin operation, or the operator key
+// signing it instead of requesting it
+type AdminParams struct {
+	Signatures []string
+	Expiry     int64
+
+	SignKey string
+	ChainID uint64 // the chain the signature is bound to, queried from the node if zero
+	NodeID  string // the node the signature is bound to, queried from the node if empty
+}
+
+// RegisterAdminFlags registers the admin token and the operator approval flags of the
+// admin operation
+func RegisterAdminFlags(cmd *cobra.Command, params *AdminParams) {
+	RegisterAdminTokenFlag(cmd)
+
+	cmd.Flags().StringArrayVar(
+		&params.Signatures,
+		command.AdminSignatureFlag,
+		nil,
+		"the operator signature approving the operation, repeated for each operator",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.Expiry,
+		command.AdminExpiryFlag,
+		0,
+		"the unix time the operator signatures expire at, the same one as signed",
+	)
+
+	cmd.Flags().StringVar(
+		&params.SignKey,
+		command.SignKeyFlag,
+		"",
+		"the file of the operator key, to sign the operation instead of requesting it. "+
+			"The same arguments and expiry are required by the request",
+	)
+
+	cmd.Flags().Uint64Var(
+		&params.ChainID,
+		command.AdminChainIDFlag,
+		0,
+		"the chain ID the signature is bound to, queried from the node if not set",
+	)
+
+	cmd.Flags().StringVar(
+		&params.NodeID,
+		command.AdminNodeIDFlag,
+		"",
+		"the libp2p ID of the node the signature is bound to, queried from the node if not set",
+	)
+}
+
+// IsSigning returns true if the operation is signed instead of requested
+func (p *AdminParams) IsSigning() bool {
+	return p.SignKey != ""
+}
+
+// WithApproval returns the context carrying the admin token and the operator approval
+// of the command
+func (p *AdminParams) WithApproval(ctx context.Context, cmd *cobra.Command) (context.Context, error) {
+	if len(p.Signatures) > 0 && p.Expiry == 0 {
+		return nil, fmt.Errorf("--%s is required by the operator signatures", command.AdminExpiryFlag)
+	}
+
+	ctx, err := WithAdminToken(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return WithAdminApproval(ctx, p.Signatures, p.Expiry), nil
+}
+
+// Sign signs the request of the full gRPC method by the operator key, the chain and
+// the node it is bound to are queried from the node if not set
+func (p *AdminParams) Sign(cmd *cobra.Command, method string, req protobuf.Message) (*ApprovalResult, error) {
+	expiry := p.Expiry
+	if expiry == 0 {
+		expiry = time.Now().Add(defaultApprovalTTL).Unix()
+	} else if time.Until(time.Unix(expiry, 0)) > server.MaxAdminApprovalTTL {
+		return nil, fmt.Errorf("--%s exceeds %s", command.AdminExpiryFlag, server.MaxAdminApprovalTTL)
+	}
+
+	target, err := p.target(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, signer, err := SignAdminRequest(p.SignKey, target, method, req, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ApprovalResult{
+		Signer:    signer.String(),
+		Signature: signature,
+		Expiry:    expiry,
+		ChainID:   target.ChainID,
+		NodeID:    target.NodeID,
+	}, nil
+}
+
+// target returns the node the signature is bound to
+func (p *AdminParams) target(cmd *cobra.Command) (server.AdminTarget, error) {
+	target := server.AdminTarget{
+		ChainID: p.ChainID,
+		NodeID:  p.NodeID,
+	}
+
+	if target.ChainID != 0 && target.NodeID != "" {
+		return target, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client, err := GetSystemClientConnection(ctx, GetGRPCAddress(cmd))
+	if err != nil {
+		return target, err
+	}
+
+	if target.ChainID == 0 {
+		status, err := client.GetStatus(ctx, &empty.Empty{})
+		if err != nil {
+			return target, fmt.Errorf("failed to query the chain ID, set --%s: %w", command.AdminChainIDFlag, err)
+		}
+
+		target.ChainID = uint64(status.Network)
+	}
+
+	if target.NodeID == "" {
+		record, err := client.NodeRecord(ctx, &empty.Empty{})
+		if err != nil {
+			return target, fmt.Errorf("failed to query the node ID, set --%s: %w", command.AdminNodeIDFlag, err)
+		}
+
+		target.NodeID = record.Id
+	}
+
+	return target, nil
+}
+
+// ApprovalResult is the operator signature approving an operation
+type ApprovalResult struct {
+	Signer    string `json:"signer"`
+	Signature string `json:"signature"`
+	Expiry    int64  `json:"expiry"`
+	ChainID   uint64 `json:"chainId"`
+	NodeID    string `json:"nodeId"`
+}
+
+func (r *ApprovalResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("\n[OPERATOR APPROVAL]\n")
+	buffer.WriteString(FormatKV([]string{
+		fmt.Sprintf("Signer|%s", r.Signer),
+		fmt.Sprintf("Signature|%s", r.Signature),
+		fmt.Sprintf("Expiry|%d (%s)", r.Expiry, time.Unix(r.Expiry, 0).UTC().Format(time.RFC3339)),
+		fmt.Sprintf("Chain ID|%d", r.ChainID),
+		fmt.Sprintf("Node ID|%s", r.NodeID),
+	}))
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
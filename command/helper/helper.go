@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -8,21 +9,27 @@ import (
 	"io/ioutil"
 	"net"
 	"net/url"
-	"time"
+	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	protobuf "google.golang.org/protobuf/proto"
 
 	ibftOp "github.com/dogechain-lab/dogechain/consensus/ibft/proto"
 	txpoolOp "github.com/dogechain-lab/dogechain/txpool/proto"
 
 	"github.com/dogechain-lab/dogechain/chain"
 	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/crypto"
 	"github.com/dogechain-lab/dogechain/helper/columnize"
 	"github.com/dogechain-lab/dogechain/helper/common"
+	"github.com/dogechain-lab/dogechain/helper/hex"
 	"github.com/dogechain-lab/dogechain/server"
 	"github.com/dogechain-lab/dogechain/server/proto"
+	"github.com/dogechain-lab/dogechain/types"
 )
 
 type ClientCloseResult struct {
@@ -41,9 +48,10 @@ const (
 )
 
 // HandleSignals is a helper method for handling signals sent to the console
-// Like stop, error, etc.
+// Like stop, error, etc. The close callback bounds the shutdown itself, another
+// signal stops waiting for it.
 func HandleSignals(
-	closeFn func(),
+	closeFn func() error,
 	outputter command.OutputFormatter,
 ) error {
 	signalCh := common.GetTerminationSignalCh()
@@ -60,26 +68,23 @@ func HandleSignals(
 	outputter.WriteOutput()
 
 	// Call the Minimal server close callback
-	gracefulCh := make(chan struct{})
+	gracefulCh := make(chan error, 1)
 
 	go func() {
+		var err error
+
 		if closeFn != nil {
-			closeFn()
+			err = closeFn()
 		}
 
-		close(gracefulCh)
+		gracefulCh <- err
 	}()
 
-	timeoutDelay := time.NewTimer(5 * time.Second)
-	defer timeoutDelay.Stop()
-
 	select {
 	case <-signalCh:
 		return errors.New("shutdown by signal channel")
-	case <-timeoutDelay.C:
-		return errors.New("shutdown by timeout")
-	case <-gracefulCh:
-		return nil
+	case err := <-gracefulCh:
+		return err
 	}
 }
 
@@ -185,7 +190,28 @@ func RegisterJSONOutputFlag(cmd *cobra.Command) {
 	cmd.PersistentFlags().Bool(
 		command.JSONOutputFlag,
 		false,
-		"get all outputs in json format (default false)",
+		"get all outputs in json format, same as --output json (default false)",
+	)
+}
+
+// RegisterOutputFlag registers the --output format setting for all child commands
+func RegisterOutputFlag(cmd *cobra.Command) {
+	format := command.OutputFormat(command.OutputText)
+
+	cmd.PersistentFlags().VarP(
+		&format,
+		command.OutputFlag,
+		"o",
+		fmt.Sprintf("the output format of the command (%s|%s|%s)",
+			command.OutputText, command.OutputJSON, command.OutputYAML),
+	)
+
+	_ = cmd.RegisterFlagCompletionFunc(
+		command.OutputFlag,
+		cobra.FixedCompletions(
+			[]string{command.OutputText, command.OutputJSON, command.OutputYAML},
+			cobra.ShellCompDirectiveNoFileComp,
+		),
 	)
 }
 
@@ -198,6 +224,79 @@ func RegisterGRPCAddressFlag(cmd *cobra.Command) {
 	)
 }
 
+// RegisterAdminTokenFlag registers the admin token file flag for all child commands
+func RegisterAdminTokenFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(
+		command.AdminTokenFileFlag,
+		"",
+		"the file of the token authenticating the admin operations, the same one as the node's",
+	)
+}
+
+// WithAdminToken returns the context carrying the admin token of the command, which
+// authenticates the admin operations of the GRPC interface
+func WithAdminToken(ctx context.Context, cmd *cobra.Command) (context.Context, error) {
+	path := cmd.Flag(command.AdminTokenFileFlag).Value.String()
+	if path == "" {
+		return nil, fmt.Errorf("--%s is required for the admin operations", command.AdminTokenFileFlag)
+	}
+
+	token, err := server.ReadAdminToken(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, server.AdminTokenMetadataKey, token), nil
+}
+
+// WithAdminApproval returns the context carrying the operator signatures approving
+// the admin request, and the expiry they signed
+func WithAdminApproval(ctx context.Context, signatures []string, expiry int64) context.Context {
+	if len(signatures) == 0 {
+		return ctx
+	}
+
+	kv := []string{server.AdminExpiryMetadataKey, strconv.FormatInt(expiry, 10)}
+	for _, sig := range signatures {
+		kv = append(kv, server.AdminSignatureMetadataKey, sig)
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// SignAdminRequest signs the admin request of the full gRPC method to the target node
+// by the operator key of the file, it returns the hex encoded signature and the operator
+// address
+func SignAdminRequest(
+	keyPath string,
+	target server.AdminTarget,
+	method string,
+	req protobuf.Message,
+	expiry int64,
+) (string, types.Address, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return "", types.ZeroAddress, fmt.Errorf("failed to read operator key: %w", err)
+	}
+
+	key, err := crypto.BytesToPrivateKey(bytes.TrimSpace(data))
+	if err != nil {
+		return "", types.ZeroAddress, fmt.Errorf("invalid operator key: %w", err)
+	}
+
+	digest, err := server.AdminRequestDigest(target, method, req, expiry)
+	if err != nil {
+		return "", types.ZeroAddress, err
+	}
+
+	sig, err := crypto.Sign(key, digest)
+	if err != nil {
+		return "", types.ZeroAddress, err
+	}
+
+	return hex.EncodeToHex(sig), crypto.PubKeyToAddress(&key.PublicKey), nil
+}
+
 // RegisterLegacyGRPCAddressFlag registers the legacy GRPC address flag for all child commands
 func RegisterLegacyGRPCAddressFlag(cmd *cobra.Command) {
 	cmd.PersistentFlags().String(
@@ -3,7 +3,9 @@ package ibft
 import (
 	"github.com/dogechain-lab/dogechain/command/helper"
 	"github.com/dogechain-lab/dogechain/command/ibft/candidates"
+	"github.com/dogechain-lab/dogechain/command/ibft/inspect"
 	"github.com/dogechain-lab/dogechain/command/ibft/propose"
+	"github.com/dogechain-lab/dogechain/command/ibft/simulate"
 	"github.com/dogechain-lab/dogechain/command/ibft/snapshot"
 	"github.com/dogechain-lab/dogechain/command/ibft/status"
 	_switch "github.com/dogechain-lab/dogechain/command/ibft/switch"
@@ -35,5 +37,9 @@ func registerSubcommands(baseCmd *cobra.Command) {
 		candidates.GetCommand(),
 		// ibft switch
 		_switch.GetCommand(),
+		// ibft simulate
+		simulate.GetCommand(),
+		// ibft inspect
+		inspect.GetCommand(),
 	)
 }
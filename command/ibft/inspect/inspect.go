@@ -0,0 +1,325 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/dogechain-lab/dogechain/consensus/ibft"
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/server/proto"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/dogechain-lab/fastrlp"
+	"github.com/spf13/cobra"
+	web3rpc "github.com/umbracle/go-web3/jsonrpc"
+)
+
+var (
+	errBlockNotFound = errors.New("block not found")
+)
+
+// headerFieldNames are the names of the rlp fields of the header, in order
+var headerFieldNames = []string{
+	"parentHash",
+	"sha3Uncles",
+	"miner",
+	"stateRoot",
+	"transactionsRoot",
+	"receiptsRoot",
+	"logsBloom",
+	"difficulty",
+	"number",
+	"gasLimit",
+	"gasUsed",
+	"timestamp",
+	"extraData",
+	"mixHash",
+	"nonce",
+}
+
+func GetCommand() *cobra.Command {
+	inspectCmd := &cobra.Command{
+		Use: "inspect",
+		Short: "Prints the fields and the RLP layout of a block header, and recomputes its hash " +
+			"and recovers the sealers. The header is either given by the RLP, or fetched by the " +
+			"hash over JSON-RPC or by the number over GRPC",
+		PreRunE: runPreRun,
+		Run:     runCommand,
+	}
+
+	helper.RegisterJSONRPCFlag(inspectCmd)
+
+	setFlags(inspectCmd)
+
+	return inspectCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.rlpRaw,
+		rlpFlag,
+		"",
+		"the hex encoded RLP of the header or the block",
+	)
+
+	cmd.Flags().StringVar(
+		&params.hashRaw,
+		hashFlag,
+		"",
+		"the hash of the block fetched over JSON-RPC",
+	)
+
+	cmd.Flags().StringVar(
+		&params.numberRaw,
+		numberFlag,
+		"",
+		"the number of the block fetched over GRPC",
+	)
+}
+
+func runPreRun(_ *cobra.Command, _ []string) error {
+	return params.validateFlags()
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	var (
+		header   *types.Header
+		raw      []byte
+		reported *types.Hash
+		err      error
+	)
+
+	switch {
+	case params.rlp != nil:
+		header, raw, err = decodeHeader(params.rlp)
+	case params.hashRaw != "":
+		header, err = getHeaderByHash(helper.GetJSONRPCAddress(cmd), params.hash)
+		if err == nil {
+			// the node reported hash, there is no raw rlp over JSON-RPC
+			reported, raw = &params.hash, header.MarshalRLP()
+		}
+	default:
+		header, raw, err = getHeaderByNumber(helper.GetGRPCAddress(cmd), params.number)
+	}
+
+	if err != nil {
+		outputter.SetError(err)
+
+		return
+	}
+
+	result, err := inspectHeader(header, raw, reported)
+	if err != nil {
+		outputter.SetError(err)
+
+		return
+	}
+
+	outputter.SetCommandResult(result)
+}
+
+// decodeHeader decodes the header of the header or the block rlp, and returns the
+// raw rlp of the header
+func decodeHeader(input []byte) (*types.Header, []byte, error) {
+	p := &fastrlp.Parser{}
+
+	v, err := p.Parse(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the header is the first list of the block
+	if v.Type() == fastrlp.TypeArray && v.Elems() > 0 && v.Get(0).Type() == fastrlp.TypeArray {
+		v = v.Get(0)
+	}
+
+	raw := append([]byte{}, p.Raw(v)...)
+
+	header := &types.Header{}
+	if err := header.UnmarshalRLP(raw); err != nil {
+		return nil, nil, fmt.Errorf("invalid header rlp: %w", err)
+	}
+
+	return header, raw, nil
+}
+
+func getHeaderByNumber(grpcAddress string, number uint64) (*types.Header, []byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client, err := helper.GetSystemClientConnection(ctx, grpcAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := client.BlockByNumber(ctx, &proto.BlockByNumberRequest{Number: number})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return decodeHeader(resp.Data)
+}
+
+// jsonHeader is the header of the JSON-RPC block
+type jsonHeader struct {
+	ParentHash   types.Hash    `json:"parentHash"`
+	Sha3Uncles   types.Hash    `json:"sha3Uncles"`
+	Miner        types.Address `json:"miner"`
+	StateRoot    types.Hash    `json:"stateRoot"`
+	TxRoot       types.Hash    `json:"transactionsRoot"`
+	ReceiptsRoot types.Hash    `json:"receiptsRoot"`
+	LogsBloom    types.Bloom   `json:"logsBloom"`
+	Difficulty   string        `json:"difficulty"`
+	Number       string        `json:"number"`
+	GasLimit     string        `json:"gasLimit"`
+	GasUsed      string        `json:"gasUsed"`
+	Timestamp    string        `json:"timestamp"`
+	ExtraData    string        `json:"extraData"`
+	MixHash      types.Hash    `json:"mixHash"`
+	Nonce        types.Nonce   `json:"nonce"`
+}
+
+func (j *jsonHeader) toHeader() (*types.Header, error) {
+	h := &types.Header{
+		ParentHash:   j.ParentHash,
+		Sha3Uncles:   j.Sha3Uncles,
+		Miner:        j.Miner,
+		StateRoot:    j.StateRoot,
+		TxRoot:       j.TxRoot,
+		ReceiptsRoot: j.ReceiptsRoot,
+		LogsBloom:    j.LogsBloom,
+		MixHash:      j.MixHash,
+		Nonce:        j.Nonce,
+	}
+
+	var err error
+
+	for _, f := range []struct {
+		dst *uint64
+		raw *string
+	}{
+		{&h.Difficulty, &j.Difficulty},
+		{&h.Number, &j.Number},
+		{&h.GasLimit, &j.GasLimit},
+		{&h.GasUsed, &j.GasUsed},
+		{&h.Timestamp, &j.Timestamp},
+	} {
+		if *f.dst, err = types.ParseUint64orHex(f.raw); err != nil {
+			return nil, err
+		}
+	}
+
+	if h.ExtraData, err = hex.DecodeHex(j.ExtraData); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func getHeaderByHash(jsonrpcAddress string, hash types.Hash) (*types.Header, error) {
+	client, err := web3rpc.NewClient(jsonrpcAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = client.Close()
+	}()
+
+	var header *jsonHeader
+
+	if err := client.Call("eth_getBlockByHash", &header, hash, false); err != nil {
+		return nil, err
+	}
+
+	if header == nil {
+		return nil, errBlockNotFound
+	}
+
+	return header.toHeader()
+}
+
+// rlpLayout returns the encoded fields of the header rlp
+func rlpLayout(raw []byte) ([]*RLPField, error) {
+	p := &fastrlp.Parser{}
+
+	v, err := p.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	elems, err := v.GetElems()
+	if err != nil {
+		return nil, err
+	}
+
+	// the list prefix is what left of the fields
+	offset := len(raw)
+	for _, elem := range elems {
+		offset -= len(p.Raw(elem))
+	}
+
+	layout := []*RLPField{
+		{Name: "list", Offset: 0, Length: offset, RLP: hex.EncodeToHex(raw[:offset])},
+	}
+
+	for i, elem := range elems {
+		name := fmt.Sprintf("#%d", i)
+		if i < len(headerFieldNames) {
+			name = headerFieldNames[i]
+		}
+
+		enc := p.Raw(elem)
+
+		layout = append(layout, &RLPField{
+			Name:   name,
+			Offset: offset,
+			Length: len(enc),
+			RLP:    hex.EncodeToHex(enc),
+		})
+
+		offset += len(enc)
+	}
+
+	return layout, nil
+}
+
+func inspectHeader(h *types.Header, raw []byte, reported *types.Hash) (*InspectResult, error) {
+	layout, err := rlpLayout(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &InspectResult{
+		Header:     newHeaderFields(h),
+		RLP:        hex.EncodeToHex(raw),
+		Canonical:  bytes.Equal(raw, h.MarshalRLP()),
+		Layout:     layout,
+		KeccakHash: types.BytesToHash(crypto.Keccak256(raw)).String(),
+	}
+
+	if reported != nil {
+		result.ReportedHash = reported.String()
+	}
+
+	seals, err := ibft.InspectSeals(h)
+	if err != nil {
+		// not an istanbul header, only the keccak hash
+		result.SealsError = err.Error()
+
+		return result, nil
+	}
+
+	result.IstanbulHash = seals.Hash.String()
+	result.Seals = newSealFields(seals)
+
+	return result, nil
+}
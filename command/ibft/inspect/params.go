@@ -0,0 +1,73 @@
+package inspect
+
+import (
+	"errors"
+
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+const (
+	rlpFlag    = "rlp"
+	hashFlag   = "hash"
+	numberFlag = "number"
+)
+
+var (
+	params = &inspectParams{}
+)
+
+var (
+	errNoSource       = errors.New("one of the rlp, hash and number should be set")
+	errMultipleSource = errors.New("only one of the rlp, hash and number could be set")
+	errInvalidHash    = errors.New("invalid block hash")
+)
+
+type inspectParams struct {
+	rlpRaw    string
+	hashRaw   string
+	numberRaw string
+
+	rlp    []byte
+	hash   types.Hash
+	number uint64
+}
+
+func (p *inspectParams) validateFlags() error {
+	sources := 0
+
+	for _, raw := range []string{p.rlpRaw, p.hashRaw, p.numberRaw} {
+		if raw != "" {
+			sources++
+		}
+	}
+
+	switch {
+	case sources == 0:
+		return errNoSource
+	case sources > 1:
+		return errMultipleSource
+	}
+
+	var err error
+
+	switch {
+	case p.rlpRaw != "":
+		p.rlp, err = hex.DecodeHex(p.rlpRaw)
+	case p.hashRaw != "":
+		p.hash, err = parseHash(p.hashRaw)
+	default:
+		p.number, err = types.ParseUint64orHex(&p.numberRaw)
+	}
+
+	return err
+}
+
+func parseHash(raw string) (types.Hash, error) {
+	buf, err := hex.DecodeHex(raw)
+	if err != nil || len(buf) != types.HashLength {
+		return types.Hash{}, errInvalidHash
+	}
+
+	return types.BytesToHash(buf), nil
+}
@@ -0,0 +1,240 @@
+package inspect
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/dogechain-lab/dogechain/consensus/ibft"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+type HeaderFields struct {
+	ParentHash   string `json:"parentHash"`
+	Sha3Uncles   string `json:"sha3Uncles"`
+	Miner        string `json:"miner"`
+	StateRoot    string `json:"stateRoot"`
+	TxRoot       string `json:"transactionsRoot"`
+	ReceiptsRoot string `json:"receiptsRoot"`
+	LogsBloom    string `json:"logsBloom"`
+	Difficulty   uint64 `json:"difficulty"`
+	Number       uint64 `json:"number"`
+	GasLimit     uint64 `json:"gasLimit"`
+	GasUsed      uint64 `json:"gasUsed"`
+	Timestamp    uint64 `json:"timestamp"`
+	ExtraData    string `json:"extraData"`
+	MixHash      string `json:"mixHash"`
+	Nonce        string `json:"nonce"`
+}
+
+func newHeaderFields(h *types.Header) *HeaderFields {
+	return &HeaderFields{
+		ParentHash:   h.ParentHash.String(),
+		Sha3Uncles:   h.Sha3Uncles.String(),
+		Miner:        h.Miner.String(),
+		StateRoot:    h.StateRoot.String(),
+		TxRoot:       h.TxRoot.String(),
+		ReceiptsRoot: h.ReceiptsRoot.String(),
+		LogsBloom:    h.LogsBloom.String(),
+		Difficulty:   h.Difficulty,
+		Number:       h.Number,
+		GasLimit:     h.GasLimit,
+		GasUsed:      h.GasUsed,
+		Timestamp:    h.Timestamp,
+		ExtraData:    hex.EncodeToHex(h.ExtraData),
+		MixHash:      h.MixHash.String(),
+		Nonce:        h.Nonce.String(),
+	}
+}
+
+type RLPField struct {
+	Name   string `json:"name"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	RLP    string `json:"rlp"`
+}
+
+type SealFields struct {
+	Vanity     string         `json:"vanity"`
+	Validators []string       `json:"validators"`
+	Proposer   *SignerFields  `json:"proposer"`
+	Committers []SignerFields `json:"committers"`
+}
+
+type SignerFields struct {
+	Address string `json:"address"`
+	Seal    string `json:"seal"`
+}
+
+func newSealFields(seals *ibft.HeaderSeals) *SealFields {
+	res := &SealFields{
+		Vanity:     hex.EncodeToHex(seals.Vanity),
+		Validators: make([]string, len(seals.Validators)),
+		Proposer: &SignerFields{
+			Address: signerString(seals.Proposer),
+			Seal:    hex.EncodeToHex(seals.Seal),
+		},
+		Committers: make([]SignerFields, len(seals.Committers)),
+	}
+
+	for i, addr := range seals.Validators {
+		res.Validators[i] = addr.String()
+	}
+
+	for i, addr := range seals.Committers {
+		res.Committers[i].Address = signerString(addr)
+		res.Committers[i].Seal = hex.EncodeToHex(seals.CommittedSeals[i])
+	}
+
+	return res
+}
+
+// signerString returns the address of the recovered signer
+func signerString(addr types.Address) string {
+	if addr == types.ZeroAddress {
+		return "not recovered"
+	}
+
+	return addr.String()
+}
+
+type InspectResult struct {
+	Header       *HeaderFields `json:"header"`
+	RLP          string        `json:"rlp"`
+	Canonical    bool          `json:"canonical"`
+	Layout       []*RLPField   `json:"rlp_layout"`
+	ReportedHash string        `json:"reported_hash,omitempty"`
+	KeccakHash   string        `json:"keccak_hash"`
+	IstanbulHash string        `json:"istanbul_hash,omitempty"`
+	Seals        *SealFields   `json:"seals,omitempty"`
+	SealsError   string        `json:"seals_error,omitempty"`
+}
+
+func (r *InspectResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("\n[HEADER]\n")
+	r.writeHeaderData(&buffer)
+
+	buffer.WriteString("\n[RLP LAYOUT]\n")
+	r.writeLayoutData(&buffer)
+
+	buffer.WriteString("\n[HASH]\n")
+	r.writeHashData(&buffer)
+
+	buffer.WriteString("\n[SEALS]\n")
+	r.writeSealData(&buffer)
+
+	return buffer.String()
+}
+
+func (r *InspectResult) writeHeaderData(buffer *bytes.Buffer) {
+	h := r.Header
+
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("Parent Hash|%s", h.ParentHash),
+		fmt.Sprintf("Sha3 Uncles|%s", h.Sha3Uncles),
+		fmt.Sprintf("Miner|%s", h.Miner),
+		fmt.Sprintf("State Root|%s", h.StateRoot),
+		fmt.Sprintf("Transactions Root|%s", h.TxRoot),
+		fmt.Sprintf("Receipts Root|%s", h.ReceiptsRoot),
+		fmt.Sprintf("Logs Bloom|%s", h.LogsBloom),
+		fmt.Sprintf("Difficulty|%d", h.Difficulty),
+		fmt.Sprintf("Number|%d", h.Number),
+		fmt.Sprintf("Gas Limit|%d", h.GasLimit),
+		fmt.Sprintf("Gas Used|%d", h.GasUsed),
+		fmt.Sprintf("Timestamp|%d", h.Timestamp),
+		fmt.Sprintf("Extra Data|%s", h.ExtraData),
+		fmt.Sprintf("Mix Hash|%s", h.MixHash),
+		fmt.Sprintf("Nonce|%s", h.Nonce),
+	}))
+	buffer.WriteString("\n")
+}
+
+func (r *InspectResult) writeLayoutData(buffer *bytes.Buffer) {
+	rows := make([]string, len(r.Layout)+1)
+
+	rows[0] = "FIELD|OFFSET|LENGTH|RLP"
+
+	for i, f := range r.Layout {
+		rows[i+1] = fmt.Sprintf("%s|%d|%d|%s", f.Name, f.Offset, f.Length, f.RLP)
+	}
+
+	buffer.WriteString(helper.FormatList(rows))
+	buffer.WriteString("\n")
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("Size|%d", (len(r.RLP)-2)/2),
+		fmt.Sprintf("Canonical|%t", r.Canonical),
+	}))
+	buffer.WriteString("\n")
+}
+
+func (r *InspectResult) writeHashData(buffer *bytes.Buffer) {
+	rows := []string{
+		fmt.Sprintf("Keccak Hash|%s", r.KeccakHash),
+	}
+
+	if r.IstanbulHash != "" {
+		rows = append(rows, fmt.Sprintf("Istanbul Hash|%s", r.IstanbulHash))
+	}
+
+	if r.ReportedHash != "" {
+		rows = append(rows,
+			fmt.Sprintf("Reported Hash|%s", r.ReportedHash),
+			fmt.Sprintf("Matched|%s", r.matchedHash()),
+		)
+	}
+
+	buffer.WriteString(helper.FormatKV(rows))
+	buffer.WriteString("\n")
+}
+
+// matchedHash returns which recomputed hash matches the reported one
+func (r *InspectResult) matchedHash() string {
+	switch r.ReportedHash {
+	case r.IstanbulHash:
+		return "istanbul"
+	case r.KeccakHash:
+		return "keccak"
+	default:
+		return "none"
+	}
+}
+
+func (r *InspectResult) writeSealData(buffer *bytes.Buffer) {
+	if r.Seals == nil {
+		buffer.WriteString(helper.FormatKV([]string{
+			fmt.Sprintf("Error|%s", r.SealsError),
+		}))
+		buffer.WriteString("\n")
+
+		return
+	}
+
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("Vanity|%s", r.Seals.Vanity),
+		fmt.Sprintf("Validators|%d", len(r.Seals.Validators)),
+		fmt.Sprintf("Proposer|%s", r.Seals.Proposer.Address),
+		fmt.Sprintf("Committers|%d", len(r.Seals.Committers)),
+	}))
+	buffer.WriteString("\n")
+
+	validators := make([]string, len(r.Seals.Validators)+1)
+	validators[0] = "VALIDATOR"
+
+	copy(validators[1:], r.Seals.Validators)
+
+	buffer.WriteString(helper.FormatList(validators))
+	buffer.WriteString("\n")
+
+	committers := make([]string, len(r.Seals.Committers)+1)
+	committers[0] = "COMMITTER|SEAL"
+
+	for i, c := range r.Seals.Committers {
+		committers[i+1] = fmt.Sprintf("%s|%s", c.Address, c.Seal)
+	}
+
+	buffer.WriteString(helper.FormatList(committers))
+	buffer.WriteString("\n")
+}
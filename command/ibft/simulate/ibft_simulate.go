@@ -0,0 +1,51 @@
+package simulate
+
+import (
+	"context"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/command/helper"
+	ibftOp "github.com/dogechain-lab/dogechain/consensus/ibft/proto"
+	"github.com/spf13/cobra"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+)
+
+func GetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "simulate",
+		Short: "Returns the block the validator would build on top of the current head, without sealing it",
+		Run:   runCommand,
+	}
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	simulateResponse, err := getIBFTSimulation(helper.GetGRPCAddress(cmd))
+	if err != nil {
+		outputter.SetError(err)
+
+		return
+	}
+
+	outputter.SetCommandResult(
+		newIBFTSimulateResult(simulateResponse),
+	)
+}
+
+func getIBFTSimulation(grpcAddress string) (*ibftOp.SimulateResp, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client, err := helper.GetIBFTOperatorClientConnection(
+		ctx,
+		grpcAddress,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Simulate(context.Background(), &empty.Empty{})
+}
@@ -0,0 +1,101 @@
+package simulate
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/command/helper"
+	ibftOp "github.com/dogechain-lab/dogechain/consensus/ibft/proto"
+)
+
+type IBFTSimulatedTxn struct {
+	Hash     string `json:"hash"`
+	From     string `json:"from"`
+	Nonce    uint64 `json:"nonce"`
+	GasPrice string `json:"gas_price"`
+	Gas      uint64 `json:"gas"`
+	GasUsed  uint64 `json:"gas_used"`
+}
+
+type IBFTSimulateResult struct {
+	Number     uint64             `json:"number"`
+	ParentHash string             `json:"parent_hash"`
+	GasLimit   uint64             `json:"gas_limit"`
+	GasUsed    uint64             `json:"gas_used"`
+	Txns       []IBFTSimulatedTxn `json:"txns"`
+	Dropped    []string           `json:"dropped"`
+	Demoted    []string           `json:"demoted"`
+}
+
+func newIBFTSimulateResult(resp *ibftOp.SimulateResp) *IBFTSimulateResult {
+	res := &IBFTSimulateResult{
+		Number:     resp.Number,
+		ParentHash: resp.ParentHash,
+		GasLimit:   resp.GasLimit,
+		GasUsed:    resp.GasUsed,
+		Txns:       make([]IBFTSimulatedTxn, len(resp.Txns)),
+		Dropped:    resp.Dropped,
+		Demoted:    resp.Demoted,
+	}
+
+	for i, txn := range resp.Txns {
+		res.Txns[i] = IBFTSimulatedTxn{
+			Hash:     txn.Hash,
+			From:     txn.From,
+			Nonce:    txn.Nonce,
+			GasPrice: txn.GasPrice,
+			Gas:      txn.Gas,
+			GasUsed:  txn.GasUsed,
+		}
+	}
+
+	return res
+}
+
+func (r *IBFTSimulateResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("\n[IBFT SIMULATED BLOCK]\n")
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("Number|%d", r.Number),
+		fmt.Sprintf("Parent hash|%s", r.ParentHash),
+		fmt.Sprintf("Gas limit|%d", r.GasLimit),
+		fmt.Sprintf("Gas used|%d", r.GasUsed),
+		fmt.Sprintf("Transactions|%d", len(r.Txns)),
+		fmt.Sprintf("Dropped|%d", len(r.Dropped)),
+		fmt.Sprintf("Demoted|%d", len(r.Demoted)),
+	}))
+	buffer.WriteString("\n")
+
+	if len(r.Txns) > 0 {
+		buffer.WriteString("\n[TRANSACTIONS]\n")
+		buffer.WriteString(formatTxns(r.Txns))
+		buffer.WriteString("\n")
+	}
+
+	if len(r.Dropped) > 0 {
+		buffer.WriteString("\n[DROPPED]\n")
+		buffer.WriteString(helper.FormatList(r.Dropped))
+		buffer.WriteString("\n")
+	}
+
+	if len(r.Demoted) > 0 {
+		buffer.WriteString("\n[DEMOTED]\n")
+		buffer.WriteString(helper.FormatList(r.Demoted))
+		buffer.WriteString("\n")
+	}
+
+	return buffer.String()
+}
+
+func formatTxns(txns []IBFTSimulatedTxn) string {
+	generatedTxns := make([]string, 0, len(txns)+1)
+
+	generatedTxns = append(generatedTxns, "Hash|From|Nonce|Gas Price|Gas|Gas Used")
+	for _, t := range txns {
+		generatedTxns = append(generatedTxns, fmt.Sprintf("%s|%s|%d|%s|%d|%d",
+			t.Hash, t.From, t.Nonce, t.GasPrice, t.Gas, t.GasUsed))
+	}
+
+	return helper.FormatList(generatedTxns)
+}
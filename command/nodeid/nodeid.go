@@ -0,0 +1,61 @@
+package nodeid
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/dogechain-lab/dogechain/server/proto"
+)
+
+func GetCommand() *cobra.Command {
+	nodeIDCmd := &cobra.Command{
+		Use:   "node-id",
+		Short: "Returns the libp2p identity record of the Dogechain-Lab Dogechain client",
+		Args:  cobra.NoArgs,
+		Run:   runCommand,
+	}
+
+	helper.RegisterGRPCAddressFlag(nodeIDCmd)
+
+	return nodeIDCmd
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	recordResponse, err := getNodeRecord(helper.GetGRPCAddress(cmd))
+	if err != nil {
+		outputter.SetError(err)
+
+		return
+	}
+
+	outputter.SetCommandResult(&NodeIDResult{
+		ID:        recordResponse.Id,
+		Addrs:     recordResponse.Addrs,
+		Protocols: recordResponse.Protocols,
+		Record:    base64.StdEncoding.EncodeToString(recordResponse.Record),
+	})
+}
+
+func getNodeRecord(grpcAddress string) (*proto.NodeRecordResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client, err := helper.GetSystemClientConnection(
+		ctx,
+		grpcAddress,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.NodeRecord(context.Background(), &emptypb.Empty{})
+}
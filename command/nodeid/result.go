@@ -0,0 +1,43 @@
+package nodeid
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/command/helper"
+)
+
+type NodeIDResult struct {
+	ID        string   `json:"id"`
+	Addrs     []string `json:"addrs"`
+	Protocols []string `json:"protocols"`
+	Record    string   `json:"record"`
+}
+
+func (r *NodeIDResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("\n[NODE ID]\n")
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("Peer ID|%s", r.ID),
+		fmt.Sprintf("Signed Record (base64)|%s", r.Record),
+	}))
+
+	buffer.WriteString("\n[ADDRESSES]\n")
+
+	if len(r.Addrs) == 0 {
+		buffer.WriteString("No public addresses\n")
+	}
+
+	for _, addr := range r.Addrs {
+		buffer.WriteString(addr + "\n")
+	}
+
+	buffer.WriteString("\n[PROTOCOLS]\n")
+
+	for _, protocol := range r.Protocols {
+		buffer.WriteString(protocol + "\n")
+	}
+
+	return buffer.String()
+}
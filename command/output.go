@@ -1,9 +1,19 @@
 package command
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
 )
 
+// Output formats of the CLI commands
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
 // OutputFormatter is the standardized interface all output formatters
 // should use
 type OutputFormatter interface {
@@ -27,14 +37,49 @@ type CommandResult interface {
 	GetOutput() string
 }
 
-func shouldOutputJSON(baseCmd *cobra.Command) bool {
-	return baseCmd.Flag(JSONOutputFlag).Changed
+// OutputFormat is the value of the output format flag, it rejects the unknown formats
+type OutputFormat string
+
+func (f *OutputFormat) String() string {
+	return string(*f)
+}
+
+func (f *OutputFormat) Set(value string) error {
+	switch format := strings.ToLower(value); format {
+	case OutputText, OutputJSON, OutputYAML:
+		*f = OutputFormat(format)
+
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q, expected one of %s|%s|%s",
+			value, OutputText, OutputJSON, OutputYAML)
+	}
+}
+
+func (f *OutputFormat) Type() string {
+	return "format"
+}
+
+func getOutputFormat(baseCmd *cobra.Command) string {
+	// the legacy --json flag
+	if flag := baseCmd.Flag(JSONOutputFlag); flag != nil && flag.Changed {
+		return OutputJSON
+	}
+
+	if flag := baseCmd.Flag(OutputFlag); flag != nil {
+		return flag.Value.String()
+	}
+
+	return OutputText
 }
 
 func InitializeOutputter(cmd *cobra.Command) OutputFormatter {
-	if shouldOutputJSON(cmd) {
+	switch getOutputFormat(cmd) {
+	case OutputJSON:
 		return newJSONOutput()
+	case OutputYAML:
+		return newYAMLOutput()
+	default:
+		return newCLIOutput()
 	}
-
-	return newCLIOutput()
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/dogechain-lab/dogechain/command"
 	"github.com/dogechain-lab/dogechain/command/helper"
 	"github.com/dogechain-lab/dogechain/server/proto"
+	"github.com/spf13/cobra"
 )
 
 var (
@@ -19,6 +20,7 @@ var (
 
 var (
 	errInvalidAddresses = errors.New("at least 1 peer address is required")
+	errSignAddresses    = errors.New("the peer addresses are signed one at a time")
 )
 
 const (
@@ -26,11 +28,17 @@ const (
 	staticFlag = "static"
 )
 
+// peersAddMethod is the full gRPC method the operators sign
+const peersAddMethod = "/v1.System/PeersAdd"
+
 type addParams struct {
 	isStatic      bool
 	peerAddresses []string
 
+	admin helper.AdminParams
+
 	systemClient proto.SystemClient
+	adminCtx     context.Context // carrying the admin token and the approval
 
 	addedPeers []string
 	addErrors  []string
@@ -47,23 +55,43 @@ func (p *addParams) validateFlags() error {
 		return errInvalidAddresses
 	}
 
+	if p.admin.IsSigning() && len(p.peerAddresses) > 1 {
+		return errSignAddresses
+	}
+
 	return nil
 }
 
-func (p *addParams) initSystemClient(grpcAddress string) error {
+func (p *addParams) initSystemClient(cmd *cobra.Command) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	systemClient, err := helper.GetSystemClientConnection(ctx, grpcAddress)
+	systemClient, err := helper.GetSystemClientConnection(ctx, helper.GetGRPCAddress(cmd))
+	if err != nil {
+		return err
+	}
+
+	// the signatures of all the peers are sent along, each request is approved by
+	// the ones signing it
+	adminCtx, err := p.admin.WithApproval(context.Background(), cmd)
 	if err != nil {
 		return err
 	}
 
 	p.systemClient = systemClient
+	p.adminCtx = adminCtx
 
 	return nil
 }
 
+// sign signs the request of the peer by the operator key, instead of requesting it
+func (p *addParams) sign(cmd *cobra.Command) (command.CommandResult, error) {
+	return p.admin.Sign(cmd, peersAddMethod, &proto.PeersAddRequest{
+		Id:     p.peerAddresses[0],
+		Static: p.isStatic,
+	})
+}
+
 func (p *addParams) addPeers() {
 	for _, address := range p.peerAddresses {
 		if addErr := p.addPeer(address, p.isStatic); addErr != nil {
@@ -78,7 +106,7 @@ func (p *addParams) addPeers() {
 
 func (p *addParams) addPeer(peerAddress string, static bool) error {
 	if _, err := p.systemClient.PeersAdd(
-		context.Background(),
+		p.adminCtx,
 		&proto.PeersAddRequest{
 			Id:     peerAddress,
 			Static: static,
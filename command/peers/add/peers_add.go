@@ -34,6 +34,8 @@ func setFlags(cmd *cobra.Command) {
 		false,
 		"add the peers as static peers",
 	)
+
+	helper.RegisterAdminFlags(cmd, &params.admin)
 }
 
 func runPreRun(_ *cobra.Command, _ []string) error {
@@ -44,7 +46,20 @@ func runCommand(cmd *cobra.Command, _ []string) {
 	outputter := command.InitializeOutputter(cmd)
 	defer outputter.WriteOutput()
 
-	if err := params.initSystemClient(helper.GetGRPCAddress(cmd)); err != nil {
+	if params.admin.IsSigning() {
+		result, err := params.sign(cmd)
+		if err != nil {
+			outputter.SetError(err)
+
+			return
+		}
+
+		outputter.SetCommandResult(result)
+
+		return
+	}
+
+	if err := params.initSystemClient(cmd); err != nil {
 		outputter.SetError(err)
 
 		return
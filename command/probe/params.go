@@ -0,0 +1,145 @@
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/umbracle/go-web3/jsonrpc"
+)
+
+const (
+	rpcFlag           = "rpc"
+	samplesFlag       = "samples"
+	seedFlag          = "seed"
+	logsBloomForkFlag = "logs-bloom-fork"
+)
+
+const (
+	defaultSamples = 10
+)
+
+var (
+	params = &probeParams{}
+)
+
+var (
+	errInvalidRPCURL  = errors.New("invalid rpc url")
+	errInvalidSamples = errors.New("the samples should be at least 1")
+	errNoBlocks       = errors.New("no blocks to sample")
+)
+
+type probeParams struct {
+	rpcURL        string
+	samples       uint64
+	seed          int64
+	logsBloomFork uint64
+}
+
+func (p *probeParams) getRequiredFlags() []string {
+	return []string{
+		rpcFlag,
+	}
+}
+
+func (p *probeParams) validateFlags() error {
+	u, err := url.ParseRequestURI(p.rpcURL)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errInvalidRPCURL, err.Error())
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("%w: no host", errInvalidRPCURL)
+	}
+
+	if p.samples == 0 {
+		return errInvalidSamples
+	}
+
+	if p.seed == 0 {
+		// reported in the result, so that the probe could be repeated
+		p.seed = time.Now().UnixNano()
+	}
+
+	return nil
+}
+
+// probe verifies the sampled blocks of the remote node
+func (p *probeParams) probe() (*ProbeResult, error) {
+	client, err := jsonrpc.NewClient(p.rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new JSON RPC client: %w", err)
+	}
+
+	defer client.Close()
+
+	var head hexUint64
+	if err := client.Call("eth_blockNumber", &head); err != nil {
+		return nil, fmt.Errorf("failed to get the latest block number: %w", err)
+	}
+
+	if head == 0 {
+		return nil, errNoBlocks
+	}
+
+	result := &ProbeResult{
+		RPC:  p.rpcURL,
+		Head: uint64(head),
+		Seed: p.seed,
+	}
+
+	v := &verifier{
+		client:        client,
+		logsBloomFork: p.logsBloomFork,
+	}
+
+	for _, number := range sampleNumbers(uint64(head), p.samples, p.seed) {
+		discrepancies, err := v.verifyBlock(number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify block %d: %w", number, err)
+		}
+
+		result.Blocks = append(result.Blocks, number)
+		result.Discrepancies = append(result.Discrepancies, discrepancies...)
+	}
+
+	return result, nil
+}
+
+// sampleNumbers returns the distinct random numbers within [1, head] in order, all
+// of them if the samples outnumber the blocks
+func sampleNumbers(head, samples uint64, seed int64) []uint64 {
+	if samples >= head {
+		numbers := make([]uint64, head)
+		for i := range numbers {
+			numbers[i] = uint64(i) + 1
+		}
+
+		return numbers
+	}
+
+	//nolint:gosec
+	r := rand.New(rand.NewSource(seed))
+	picked := make(map[uint64]struct{}, samples)
+	numbers := make([]uint64, 0, samples)
+
+	for uint64(len(numbers)) < samples {
+		number := uint64(r.Int63n(int64(head))) + 1
+		if _, ok := picked[number]; ok {
+			continue
+		}
+
+		picked[number] = struct{}{}
+
+		numbers = append(numbers, number)
+	}
+
+	sort.Slice(numbers, func(i, j int) bool {
+		return numbers[i] < numbers[j]
+	})
+
+	return numbers
+}
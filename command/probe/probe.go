@@ -0,0 +1,86 @@
+package probe
+
+import (
+	"os"
+
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/spf13/cobra"
+)
+
+// the exit codes, so that the probe could be run by the monitoring checks
+const (
+	exitCodeFailure     = 1 // the node could not be probed
+	exitCodeDiscrepancy = 2 // any discrepancy is found
+)
+
+func GetCommand() *cobra.Command {
+	probeCmd := &cobra.Command{
+		Use: "probe",
+		Short: "Samples random historical blocks and receipts from a remote JSON-RPC node, recomputes " +
+			"their hashes and roots locally, and reports the discrepancies. It exits with code 2 " +
+			"if any discrepancy is found",
+		PreRunE: runPreRun,
+		Run:     runCommand,
+	}
+
+	helper.SetRequiredFlags(probeCmd, params.getRequiredFlags())
+
+	setFlags(probeCmd)
+
+	return probeCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.rpcURL,
+		rpcFlag,
+		"",
+		"the JSON-RPC url of the probed node",
+	)
+
+	cmd.Flags().Uint64Var(
+		&params.samples,
+		samplesFlag,
+		defaultSamples,
+		"the number of the random historical blocks to verify",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.seed,
+		seedFlag,
+		0,
+		"the seed of the sampled blocks, so that a probe could be repeated (0 for a random one)",
+	)
+
+	cmd.Flags().Uint64Var(
+		&params.logsBloomFork,
+		logsBloomForkFlag,
+		0,
+		"the LogsBloom fork block of the chain, the header logs blooms of the blocks before it "+
+			"are not verified by the nodes, so they are not checked either",
+	)
+}
+
+func runPreRun(_ *cobra.Command, _ []string) error {
+	return params.validateFlags()
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	outputter := command.InitializeOutputter(cmd)
+
+	result, err := params.probe()
+	if err != nil {
+		outputter.SetError(err)
+		outputter.WriteOutput()
+
+		os.Exit(exitCodeFailure)
+	}
+
+	outputter.SetCommandResult(result)
+	outputter.WriteOutput()
+
+	if len(result.Discrepancies) > 0 {
+		os.Exit(exitCodeDiscrepancy)
+	}
+}
@@ -0,0 +1,54 @@
+package probe
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/command/helper"
+)
+
+// Discrepancy is a field reported by the remote node differing from the one
+// recomputed locally
+type Discrepancy struct {
+	Number   uint64 `json:"number"`
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Reported string `json:"reported"`
+}
+
+type ProbeResult struct {
+	RPC           string         `json:"rpc"`
+	Head          uint64         `json:"head"`
+	Seed          int64          `json:"seed"`
+	Blocks        []uint64       `json:"blocks"`
+	Discrepancies []*Discrepancy `json:"discrepancies"`
+}
+
+func (r *ProbeResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("\n[PROBE]\n")
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("RPC|%s", r.RPC),
+		fmt.Sprintf("Head|%d", r.Head),
+		fmt.Sprintf("Seed|%d", r.Seed),
+		fmt.Sprintf("Blocks|%v", r.Blocks),
+		fmt.Sprintf("Discrepancies|%d", len(r.Discrepancies)),
+	}))
+	buffer.WriteString("\n")
+
+	if len(r.Discrepancies) > 0 {
+		rows := make([]string, len(r.Discrepancies)+1)
+		rows[0] = "NUMBER|FIELD|EXPECTED|REPORTED"
+
+		for i, d := range r.Discrepancies {
+			rows[i+1] = fmt.Sprintf("%d|%s|%s|%s", d.Number, d.Field, d.Expected, d.Reported)
+		}
+
+		buffer.WriteString("\n[DISCREPANCIES]\n")
+		buffer.WriteString(helper.FormatList(rows))
+		buffer.WriteString("\n")
+	}
+
+	return buffer.String()
+}
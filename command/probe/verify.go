@@ -0,0 +1,280 @@
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/dogechain-lab/dogechain/consensus/ibft"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/dogechain-lab/dogechain/types/buildroot"
+	"github.com/umbracle/go-web3/jsonrpc"
+)
+
+var (
+	errBlockNotFound   = errors.New("block not found")
+	errReceiptNotFound = errors.New("receipt not found")
+)
+
+type hexUint64 uint64
+
+func (u *hexUint64) UnmarshalText(input []byte) error {
+	num, err := strconv.ParseUint(strings.TrimPrefix(string(input), "0x"), 16, 64)
+	if err != nil {
+		return err
+	}
+
+	*u = hexUint64(num)
+
+	return nil
+}
+
+type hexBytes []byte
+
+func (b *hexBytes) UnmarshalText(input []byte) error {
+	buf, err := hex.DecodeHex(string(input))
+	if err != nil {
+		return err
+	}
+
+	*b = buf
+
+	return nil
+}
+
+type hexBig big.Int
+
+func (b *hexBig) UnmarshalText(input []byte) error {
+	if _, ok := (*big.Int)(b).SetString(strings.TrimPrefix(string(input), "0x"), 16); !ok {
+		return fmt.Errorf("invalid hex number %s", input)
+	}
+
+	return nil
+}
+
+func (b *hexBig) toBig() *big.Int {
+	return new(big.Int).Set((*big.Int)(b))
+}
+
+// jsonTransaction is the transaction reported by the remote node
+type jsonTransaction struct {
+	Nonce    hexUint64      `json:"nonce"`
+	GasPrice hexBig         `json:"gasPrice"`
+	Gas      hexUint64      `json:"gas"`
+	To       *types.Address `json:"to"`
+	Value    hexBig         `json:"value"`
+	Input    hexBytes       `json:"input"`
+	V        hexBig         `json:"v"`
+	R        hexBig         `json:"r"`
+	S        hexBig         `json:"s"`
+	Hash     types.Hash     `json:"hash"`
+}
+
+func (t *jsonTransaction) toTransaction() *types.Transaction {
+	return &types.Transaction{
+		Nonce:    uint64(t.Nonce),
+		GasPrice: t.GasPrice.toBig(),
+		Gas:      uint64(t.Gas),
+		To:       t.To,
+		Value:    t.Value.toBig(),
+		Input:    t.Input,
+		V:        t.V.toBig(),
+		R:        t.R.toBig(),
+		S:        t.S.toBig(),
+	}
+}
+
+// jsonBlock is the block reported by the remote node, with the full transactions
+type jsonBlock struct {
+	ParentHash   types.Hash         `json:"parentHash"`
+	Sha3Uncles   types.Hash         `json:"sha3Uncles"`
+	Miner        types.Address      `json:"miner"`
+	StateRoot    types.Hash         `json:"stateRoot"`
+	TxRoot       types.Hash         `json:"transactionsRoot"`
+	ReceiptsRoot types.Hash         `json:"receiptsRoot"`
+	LogsBloom    types.Bloom        `json:"logsBloom"`
+	Difficulty   hexUint64          `json:"difficulty"`
+	Number       hexUint64          `json:"number"`
+	GasLimit     hexUint64          `json:"gasLimit"`
+	GasUsed      hexUint64          `json:"gasUsed"`
+	Timestamp    hexUint64          `json:"timestamp"`
+	ExtraData    hexBytes           `json:"extraData"`
+	MixHash      types.Hash         `json:"mixHash"`
+	Nonce        types.Nonce        `json:"nonce"`
+	Hash         types.Hash         `json:"hash"`
+	Transactions []*jsonTransaction `json:"transactions"`
+}
+
+func (b *jsonBlock) toHeader() *types.Header {
+	return &types.Header{
+		ParentHash:   b.ParentHash,
+		Sha3Uncles:   b.Sha3Uncles,
+		Miner:        b.Miner,
+		StateRoot:    b.StateRoot,
+		TxRoot:       b.TxRoot,
+		ReceiptsRoot: b.ReceiptsRoot,
+		LogsBloom:    b.LogsBloom,
+		Difficulty:   uint64(b.Difficulty),
+		Number:       uint64(b.Number),
+		GasLimit:     uint64(b.GasLimit),
+		GasUsed:      uint64(b.GasUsed),
+		Timestamp:    uint64(b.Timestamp),
+		ExtraData:    b.ExtraData,
+		MixHash:      b.MixHash,
+		Nonce:        b.Nonce,
+		Hash:         b.Hash,
+	}
+}
+
+// jsonHeader is the header reported by the remote node, only the hash is needed
+type jsonHeader struct {
+	Hash types.Hash `json:"hash"`
+}
+
+type jsonLog struct {
+	Address types.Address `json:"address"`
+	Topics  []types.Hash  `json:"topics"`
+	Data    hexBytes      `json:"data"`
+}
+
+// jsonReceipt is the receipt reported by the remote node
+type jsonReceipt struct {
+	Root              types.Hash  `json:"root"`
+	CumulativeGasUsed hexUint64   `json:"cumulativeGasUsed"`
+	LogsBloom         types.Bloom `json:"logsBloom"`
+	Logs              []*jsonLog  `json:"logs"`
+	Status            hexUint64   `json:"status"`
+	TxHash            types.Hash  `json:"transactionHash"`
+	BlockHash         types.Hash  `json:"blockHash"`
+}
+
+func (r *jsonReceipt) toReceipt() *types.Receipt {
+	receipt := &types.Receipt{
+		CumulativeGasUsed: uint64(r.CumulativeGasUsed),
+		LogsBloom:         r.LogsBloom,
+		Logs:              make([]*types.Log, len(r.Logs)),
+		TxHash:            r.TxHash,
+	}
+
+	// the pre-byzantium receipts carry the root instead of the status
+	if r.Root != types.ZeroHash {
+		receipt.Root = r.Root
+	} else {
+		receipt.SetStatus(types.ReceiptStatus(r.Status))
+	}
+
+	for i, log := range r.Logs {
+		receipt.Logs[i] = &types.Log{
+			Address: log.Address,
+			Topics:  log.Topics,
+			Data:    log.Data,
+		}
+	}
+
+	return receipt
+}
+
+// verifier recomputes the hashes and roots of the blocks reported by the remote node
+type verifier struct {
+	client        *jsonrpc.Client
+	logsBloomFork uint64 // the header logs bloom is checked from the fork block
+}
+
+// verifyBlock verifies the block at the number against its parent, transactions and
+// receipts, and returns the discrepancies found
+func (v *verifier) verifyBlock(number uint64) ([]*Discrepancy, error) {
+	var block *jsonBlock
+	if err := v.client.Call("eth_getBlockByNumber", &block, hex.EncodeUint64(number), true); err != nil {
+		return nil, err
+	}
+
+	if block == nil {
+		return nil, errBlockNotFound
+	}
+
+	var (
+		discrepancies []*Discrepancy
+		header        = block.toHeader()
+	)
+
+	check := func(field string, expected, reported fmt.Stringer) {
+		if expected.String() != reported.String() {
+			discrepancies = append(discrepancies, &Discrepancy{
+				Number:   number,
+				Field:    field,
+				Expected: expected.String(),
+				Reported: reported.String(),
+			})
+		}
+	}
+
+	check("number", uint64Stringer(number), uint64Stringer(header.Number))
+	check("hash", ibft.HeaderHash(header), header.Hash)
+	check("sha3Uncles", types.EmptyUncleHash, header.Sha3Uncles)
+
+	// the parent hash links the block to the reported chain
+	var parent *jsonHeader
+	if err := v.client.Call("eth_getBlockByNumber", &parent, hex.EncodeUint64(number-1), false); err != nil {
+		return nil, err
+	}
+
+	if parent == nil {
+		return nil, fmt.Errorf("parent %w", errBlockNotFound)
+	}
+
+	check("parentHash", parent.Hash, header.ParentHash)
+
+	txs := make([]*types.Transaction, len(block.Transactions))
+	for i, jsonTx := range block.Transactions {
+		txs[i] = jsonTx.toTransaction()
+
+		check(fmt.Sprintf("transactions[%d].hash", i), txs[i].Hash(), jsonTx.Hash)
+	}
+
+	check("transactionsRoot", buildroot.CalculateTransactionsRoot(txs), header.TxRoot)
+
+	receipts := make([]*types.Receipt, len(txs))
+
+	for i, tx := range block.Transactions {
+		var reported *jsonReceipt
+		if err := v.client.Call("eth_getTransactionReceipt", &reported, tx.Hash); err != nil {
+			return nil, err
+		}
+
+		if reported == nil {
+			return nil, fmt.Errorf("%w: %s", errReceiptNotFound, tx.Hash)
+		}
+
+		receipts[i] = reported.toReceipt()
+
+		check(fmt.Sprintf("receipts[%d].transactionHash", i), tx.Hash, reported.TxHash)
+		check(fmt.Sprintf("receipts[%d].blockHash", i), header.Hash, reported.BlockHash)
+		check(fmt.Sprintf("receipts[%d].logsBloom", i),
+			types.CreateBloom([]*types.Receipt{receipts[i]}), reported.LogsBloom)
+	}
+
+	check("receiptsRoot", buildroot.CalculateReceiptsRoot(receipts), header.ReceiptsRoot)
+
+	// the header logs blooms before the fork are not validated on import
+	if number >= v.logsBloomFork {
+		check("logsBloom", types.CreateBloom(receipts), header.LogsBloom)
+	}
+
+	gasUsed := uint64(0)
+	if len(receipts) > 0 {
+		gasUsed = receipts[len(receipts)-1].CumulativeGasUsed
+	}
+
+	check("gasUsed", uint64Stringer(gasUsed), uint64Stringer(header.GasUsed))
+
+	return discrepancies, nil
+}
+
+type uint64Stringer uint64
+
+func (u uint64Stringer) String() string {
+	return strconv.FormatUint(uint64(u), 10)
+}
@@ -0,0 +1,238 @@
+package probe
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/consensus/ibft"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/dogechain-lab/dogechain/types/buildroot"
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/go-web3/jsonrpc"
+)
+
+// stubNode answers the JSON-RPC queries of the verifier with the given block
+type stubNode struct {
+	blocks   map[string]interface{} // by hex number
+	receipts map[types.Hash]interface{}
+}
+
+func (n *stubNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var result interface{}
+
+	switch req.Method {
+	case "eth_getBlockByNumber":
+		var number string
+
+		_ = json.Unmarshal(req.Params[0], &number)
+		result = n.blocks[number]
+	case "eth_getTransactionReceipt":
+		var hash types.Hash
+
+		_ = json.Unmarshal(req.Params[0], &hash)
+		result = n.receipts[hash]
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req.ID,
+		"result":  result,
+	})
+}
+
+// newStubNode returns the node reporting the block 1 with a transaction and its
+// receipt, whose header has an empty logs bloom like the ones before the fork
+func newStubNode(t *testing.T, tamper func(header *types.Header)) *stubNode {
+	t.Helper()
+
+	to := types.StringToAddress("2")
+	tx := &types.Transaction{
+		Nonce:    1,
+		GasPrice: big.NewInt(10),
+		Gas:      21000,
+		To:       &to,
+		Value:    big.NewInt(1),
+		Input:    []byte{},
+		V:        big.NewInt(0),
+		R:        big.NewInt(0),
+		S:        big.NewInt(0),
+	}
+
+	receipt := &types.Receipt{
+		CumulativeGasUsed: 21000,
+		Logs: []*types.Log{
+			{
+				Address: to,
+				Topics:  []types.Hash{types.StringToHash("3")},
+				Data:    []byte{0x1},
+			},
+		},
+		TxHash: tx.Hash(),
+	}
+	receipt.SetStatus(types.ReceiptSuccess)
+	receipt.LogsBloom = types.CreateBloom([]*types.Receipt{receipt})
+
+	parentHash := types.StringToHash("1")
+	header := &types.Header{
+		ParentHash:   parentHash,
+		Sha3Uncles:   types.EmptyUncleHash,
+		TxRoot:       buildroot.CalculateTransactionsRoot([]*types.Transaction{tx}),
+		ReceiptsRoot: buildroot.CalculateReceiptsRoot([]*types.Receipt{receipt}),
+		Number:       1,
+		GasLimit:     30000000,
+		GasUsed:      21000,
+		ExtraData:    []byte{},
+	}
+
+	if tamper != nil {
+		tamper(header)
+	}
+
+	header.Hash = ibft.HeaderHash(header)
+
+	return &stubNode{
+		blocks: map[string]interface{}{
+			hex.EncodeUint64(0): map[string]interface{}{
+				"hash": parentHash,
+			},
+			hex.EncodeUint64(1): map[string]interface{}{
+				"parentHash":       header.ParentHash,
+				"sha3Uncles":       header.Sha3Uncles,
+				"miner":            header.Miner,
+				"stateRoot":        header.StateRoot,
+				"transactionsRoot": header.TxRoot,
+				"receiptsRoot":     header.ReceiptsRoot,
+				"logsBloom":        header.LogsBloom,
+				"difficulty":       hex.EncodeUint64(header.Difficulty),
+				"number":           hex.EncodeUint64(header.Number),
+				"gasLimit":         hex.EncodeUint64(header.GasLimit),
+				"gasUsed":          hex.EncodeUint64(header.GasUsed),
+				"timestamp":        hex.EncodeUint64(header.Timestamp),
+				"extraData":        hex.EncodeToHex(header.ExtraData),
+				"mixHash":          header.MixHash,
+				"nonce":            header.Nonce,
+				"hash":             header.Hash,
+				"transactions": []interface{}{
+					map[string]interface{}{
+						"nonce":    hex.EncodeUint64(tx.Nonce),
+						"gasPrice": hex.EncodeBig(tx.GasPrice),
+						"gas":      hex.EncodeUint64(tx.Gas),
+						"to":       tx.To,
+						"value":    hex.EncodeBig(tx.Value),
+						"input":    hex.EncodeToHex(tx.Input),
+						"v":        hex.EncodeBig(tx.V),
+						"r":        hex.EncodeBig(tx.R),
+						"s":        hex.EncodeBig(tx.S),
+						"hash":     tx.Hash(),
+					},
+				},
+			},
+		},
+		receipts: map[types.Hash]interface{}{
+			tx.Hash(): map[string]interface{}{
+				"cumulativeGasUsed": hex.EncodeUint64(receipt.CumulativeGasUsed),
+				"logsBloom":         receipt.LogsBloom,
+				"logs": []interface{}{
+					map[string]interface{}{
+						"address": receipt.Logs[0].Address,
+						"topics":  receipt.Logs[0].Topics,
+						"data":    hex.EncodeToHex(receipt.Logs[0].Data),
+					},
+				},
+				"status":          hex.EncodeUint64(uint64(*receipt.Status)),
+				"transactionHash": tx.Hash(),
+				"blockHash":       header.Hash,
+			},
+		},
+	}
+}
+
+func newTestVerifier(t *testing.T, node *stubNode, logsBloomFork uint64) *verifier {
+	t.Helper()
+
+	server := httptest.NewServer(node)
+	t.Cleanup(server.Close)
+
+	client, err := jsonrpc.NewClient(server.URL)
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	return &verifier{
+		client:        client,
+		logsBloomFork: logsBloomFork,
+	}
+}
+
+func discrepancyFields(discrepancies []*Discrepancy) []string {
+	fields := make([]string, len(discrepancies))
+	for i, d := range discrepancies {
+		fields[i] = d.Field
+	}
+
+	return fields
+}
+
+func TestVerifyBlock(t *testing.T) {
+	t.Parallel()
+
+	t.Run("the header bloom before the fork is not checked", func(t *testing.T) {
+		t.Parallel()
+
+		v := newTestVerifier(t, newStubNode(t, nil), 2)
+
+		discrepancies, err := v.verifyBlock(1)
+		assert.NoError(t, err)
+		assert.Empty(t, discrepancies)
+	})
+
+	t.Run("the header bloom since the fork is checked", func(t *testing.T) {
+		t.Parallel()
+
+		v := newTestVerifier(t, newStubNode(t, nil), 1)
+
+		discrepancies, err := v.verifyBlock(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"logsBloom"}, discrepancyFields(discrepancies))
+	})
+
+	t.Run("the roots are checked", func(t *testing.T) {
+		t.Parallel()
+
+		v := newTestVerifier(t, newStubNode(t, func(header *types.Header) {
+			header.ReceiptsRoot = types.StringToHash("4")
+			header.GasUsed = 1
+		}), 2)
+
+		discrepancies, err := v.verifyBlock(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"receiptsRoot", "gasUsed"}, discrepancyFields(discrepancies))
+	})
+
+	t.Run("missing block", func(t *testing.T) {
+		t.Parallel()
+
+		v := newTestVerifier(t, newStubNode(t, nil), 0)
+
+		_, err := v.verifyBlock(2)
+		assert.ErrorIs(t, err, errBlockNotFound)
+	})
+}
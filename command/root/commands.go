@@ -0,0 +1,85 @@
+package root
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const printCommandsJSONFlag = "print-commands-json"
+
+// commandSchema is the machine readable description of a command and its sub commands
+type commandSchema struct {
+	Name     string           `json:"name"`
+	Path     string           `json:"path"`
+	Use      string           `json:"use"`
+	Short    string           `json:"short,omitempty"`
+	Aliases  []string         `json:"aliases,omitempty"`
+	Runnable bool             `json:"runnable"`
+	Flags    []*flagSchema    `json:"flags,omitempty"`
+	Commands []*commandSchema `json:"commands,omitempty"`
+}
+
+// flagSchema is the machine readable description of a command flag
+type flagSchema struct {
+	Name       string `json:"name"`
+	Shorthand  string `json:"shorthand,omitempty"`
+	Type       string `json:"type"`
+	Default    string `json:"default"`
+	Usage      string `json:"usage"`
+	Persistent bool   `json:"persistent"` // inherited by the sub commands
+	Deprecated string `json:"deprecated,omitempty"`
+}
+
+// newCommandSchema describes the command tree, the hidden commands and flags are skipped
+func newCommandSchema(cmd *cobra.Command) *commandSchema {
+	schema := &commandSchema{
+		Name:     cmd.Name(),
+		Path:     cmd.CommandPath(),
+		Use:      cmd.Use,
+		Short:    cmd.Short,
+		Aliases:  cmd.Aliases,
+		Runnable: cmd.Runnable(),
+	}
+
+	cmd.NonInheritedFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+
+		schema.Flags = append(schema.Flags, &flagSchema{
+			Name:       flag.Name,
+			Shorthand:  flag.Shorthand,
+			Type:       flag.Value.Type(),
+			Default:    flag.DefValue,
+			Usage:      flag.Usage,
+			Persistent: cmd.PersistentFlags().Lookup(flag.Name) != nil,
+			Deprecated: flag.Deprecated,
+		})
+	})
+
+	for _, sub := range cmd.Commands() {
+		if !sub.IsAvailableCommand() {
+			continue
+		}
+
+		schema.Commands = append(schema.Commands, newCommandSchema(sub))
+	}
+
+	return schema
+}
+
+// printCommandsJSON prints the schema of the whole command tree, for building wrappers
+func printCommandsJSON(cmd *cobra.Command) {
+	bytes, err := json.Marshal(newCommandSchema(cmd.Root()))
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+
+		os.Exit(1)
+	}
+
+	_, _ = fmt.Fprintln(os.Stdout, string(bytes))
+}
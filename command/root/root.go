@@ -5,16 +5,22 @@ import (
 	"os"
 
 	"github.com/dogechain-lab/dogechain/command/backup"
+	"github.com/dogechain-lab/dogechain/command/chain"
+	"github.com/dogechain-lab/dogechain/command/db"
+	"github.com/dogechain-lab/dogechain/command/dryrun"
 	"github.com/dogechain-lab/dogechain/command/genesis"
 	"github.com/dogechain-lab/dogechain/command/helper"
 	"github.com/dogechain-lab/dogechain/command/ibft"
 	"github.com/dogechain-lab/dogechain/command/license"
 	"github.com/dogechain-lab/dogechain/command/loadbot"
 	"github.com/dogechain-lab/dogechain/command/monitor"
+	"github.com/dogechain-lab/dogechain/command/nodeid"
 	"github.com/dogechain-lab/dogechain/command/peers"
+	"github.com/dogechain-lab/dogechain/command/probe"
 	"github.com/dogechain-lab/dogechain/command/reverify"
 	"github.com/dogechain-lab/dogechain/command/secrets"
 	"github.com/dogechain-lab/dogechain/command/server"
+	"github.com/dogechain-lab/dogechain/command/signer"
 	"github.com/dogechain-lab/dogechain/command/status"
 	"github.com/dogechain-lab/dogechain/command/txpool"
 	"github.com/dogechain-lab/dogechain/command/version"
@@ -28,11 +34,31 @@ type RootCommand struct {
 func NewRootCommand() *RootCommand {
 	rootCommand := &RootCommand{
 		baseCmd: &cobra.Command{
+			Use:   "dogechain",
 			Short: "Dogechain-Lab Dogechain is a framework for building Ethereum-compatible Blockchain networks",
+			Run: func(cmd *cobra.Command, _ []string) {
+				if printCommands, _ := cmd.Flags().GetBool(printCommandsJSONFlag); printCommands {
+					printCommandsJSON(cmd)
+
+					return
+				}
+
+				_ = cmd.Help()
+			},
 		},
 	}
 
 	helper.RegisterJSONOutputFlag(rootCommand.baseCmd)
+	helper.RegisterOutputFlag(rootCommand.baseCmd)
+
+	// dump the command tree for the wrappers, the completion scripts are
+	// generated by the default completion command
+	rootCommand.baseCmd.Flags().Bool(
+		printCommandsJSONFlag,
+		false,
+		"print the commands and flags in json format",
+	)
+	_ = rootCommand.baseCmd.Flags().MarkHidden(printCommandsJSONFlag)
 
 	rootCommand.registerSubCommands()
 
@@ -44,6 +70,7 @@ func (rc *RootCommand) registerSubCommands() {
 		version.GetCommand(),
 		txpool.GetCommand(),
 		status.GetCommand(),
+		nodeid.GetCommand(),
 		secrets.GetCommand(),
 		peers.GetCommand(),
 		reverify.GetCommand(),
@@ -51,8 +78,13 @@ func (rc *RootCommand) registerSubCommands() {
 		loadbot.GetCommand(),
 		ibft.GetCommand(),
 		backup.GetCommand(),
+		db.GetCommand(),
+		chain.GetCommand(),
+		dryrun.GetCommand(),
+		probe.GetCommand(),
 		genesis.GetCommand(),
 		server.GetCommand(),
+		signer.GetCommand(),
 		license.GetCommand(),
 	)
 }
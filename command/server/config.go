@@ -1,24 +1,41 @@
 package server
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"strings"
+	"time"
 
+	"github.com/dogechain-lab/dogechain/consensus"
+	"github.com/dogechain-lab/dogechain/consensus/lease"
 	"github.com/dogechain-lab/dogechain/helper/gasprice"
+	"github.com/dogechain-lab/dogechain/helper/units"
 	"github.com/dogechain-lab/dogechain/jsonrpc"
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/txpool"
 	"github.com/hashicorp/hcl"
+	"gopkg.in/yaml.v3"
 )
 
 // Config defines the server configuration params
 type Config struct {
+	Version                  uint64          `json:"version" yaml:"version"`
 	GenesisPath              string          `json:"chain_config"`
 	SecretsConfigPath        string          `json:"secrets_config"`
 	DataDir                  string          `json:"data_dir"`
 	BlockGasTarget           string          `json:"block_gas_target"`
+	BlockMaxSenderTxs        uint64          `json:"block_max_sender_txs" yaml:"block_max_sender_txs"`
+	BlockMaxSenderGasShare   uint64          `json:"block_max_sender_gas_share" yaml:"block_max_sender_gas_share"`
+	BlockExtraVanity         string          `json:"block_extra_vanity" yaml:"block_extra_vanity"`
+	SealLease                string          `json:"seal_lease" yaml:"seal_lease"`
+	SealLeaseTTL             units.Duration  `json:"seal_lease_ttl" yaml:"seal_lease_ttl"`
+	RemoteSigner             string          `json:"remote_signer" yaml:"remote_signer"`
+	DowntimeMissedTurns      uint64          `json:"downtime_missed_turns" yaml:"downtime_missed_turns"`
+	DowntimeAction           string          `json:"downtime_action" yaml:"downtime_action"`
+	DowntimeWebhook          string          `json:"downtime_webhook" yaml:"downtime_webhook"`
 	GRPCAddr                 string          `json:"grpc_addr"`
 	JSONRPCAddr              string          `json:"jsonrpc_addr"`
 	Telemetry                *Telemetry      `json:"telemetry"`
@@ -27,7 +44,7 @@ type Config struct {
 	TxPool                   *TxPool         `json:"tx_pool"`
 	LogLevel                 string          `json:"log_level"`
 	RestoreFile              string          `json:"restore_file"`
-	BlockTime                uint64          `json:"block_time_s"`
+	BlockTime                units.Duration  `json:"block_time" yaml:"block_time"`
 	Headers                  *Headers        `json:"headers"`
 	LogFilePath              string          `json:"log_to"`
 	EnableGraphQL            bool            `json:"enable_graphql"`
@@ -35,9 +52,46 @@ type Config struct {
 	JSONRPCBatchRequestLimit uint64          `json:"json_rpc_batch_request_limit" yaml:"json_rpc_batch_request_limit"`
 	JSONRPCBlockRangeLimit   uint64          `json:"json_rpc_block_range_limit" yaml:"json_rpc_block_range_limit"`
 	JSONNamespace            string          `json:"json_namespace" yaml:"json_namespace"`
+	JSONRPCCacheSize         uint64          `json:"json_rpc_cache_size" yaml:"json_rpc_cache_size"`
+	JSONRPCCacheRedis        string          `json:"json_rpc_cache_redis" yaml:"json_rpc_cache_redis"`
+	JSONRPCSigner            string          `json:"json_rpc_signer" yaml:"json_rpc_signer"`
+	JSONRPCSignerRules       string          `json:"json_rpc_signer_rules" yaml:"json_rpc_signer_rules"`
+	JSONRPCRateLimit         uint64          `json:"json_rpc_rate_limit" yaml:"json_rpc_rate_limit"`
+	JSONRPCRateLimitBurst    uint64          `json:"json_rpc_rate_limit_burst" yaml:"json_rpc_rate_limit_burst"`
+	JSONRPCMethodRateLimits  string          `json:"json_rpc_method_rate_limits" yaml:"json_rpc_method_rate_limits"`
+	JSONRPCTrustedProxies    string          `json:"json_rpc_trusted_proxies" yaml:"json_rpc_trusted_proxies"`
+	JSONRPCAuth              bool            `json:"json_rpc_auth" yaml:"json_rpc_auth"`
+	JSONRPCTLSCert           string          `json:"json_rpc_tls_cert" yaml:"json_rpc_tls_cert"`
+	JSONRPCTLSKey            string          `json:"json_rpc_tls_key" yaml:"json_rpc_tls_key"`
+	GraphQLTLSCert           string          `json:"graphql_tls_cert" yaml:"graphql_tls_cert"`
+	GraphQLTLSKey            string          `json:"graphql_tls_key" yaml:"graphql_tls_key"`
+	TLSReloadInterval        units.Duration  `json:"tls_reload_interval" yaml:"tls_reload_interval"`
 	EnableWS                 bool            `json:"enable_ws" yaml:"enable_ws"`
+	WSMaxMessageSize         uint64          `json:"ws_max_message_size" yaml:"ws_max_message_size"`
+	WSMessageRateLimit       uint64          `json:"ws_message_rate_limit" yaml:"ws_message_rate_limit"`
+	WSSendQueueSize          uint64          `json:"ws_send_queue_size" yaml:"ws_send_queue_size"`
+	WSDropPolicy             string          `json:"ws_drop_policy" yaml:"ws_drop_policy"`
 	EnablePprof              bool            `json:"enable_pprof" yaml:"enable_pprof"`
 	BlockBroadcast           bool            `json:"enable_block_broadcast" yaml:"enable_block_broadcast"`
+	MaxReorgDepth            uint64          `json:"max_reorg_depth" yaml:"max_reorg_depth"`
+	EnableLogIndex           bool            `json:"enable_log_index" yaml:"enable_log_index"`
+	EnableBloomIndex         bool            `json:"enable_bloom_index" yaml:"enable_bloom_index"`
+	EnableChainStats         bool            `json:"enable_chain_stats" yaml:"enable_chain_stats"`
+	EnableSystemTxIndex      bool            `json:"enable_systemtx_index" yaml:"enable_systemtx_index"`
+	TxLookupLimit            uint64          `json:"txlookup_limit" yaml:"txlookup_limit"`
+	BlockWriteQueue          uint64          `json:"block_write_queue" yaml:"block_write_queue"`
+	DBKeyLayout              string          `json:"db_key_layout" yaml:"db_key_layout"`
+	CachePreimages           bool            `json:"cache_preimages" yaml:"cache_preimages"`
+	WarmupHeaders            uint64          `json:"warmup_headers" yaml:"warmup_headers"`
+	WarmupReceipts           uint64          `json:"warmup_receipts" yaml:"warmup_receipts"`
+	WarmupStateDepth         int             `json:"warmup_state_depth" yaml:"warmup_state_depth"`
+	AdminSigners             []string        `json:"admin_signers" yaml:"admin_signers"`
+	AdminThreshold           int             `json:"admin_threshold" yaml:"admin_threshold"`
+	ReceiptsBackfill         bool            `json:"receipts_backfill" yaml:"receipts_backfill"`
+	ReceiptsBackfillRate     uint64          `json:"receipts_backfill_rate" yaml:"receipts_backfill_rate"`
+	ImportMaxWriteQueue      uint64          `json:"import_max_write_queue" yaml:"import_max_write_queue"`
+	ImportMaxHeapMB          uint64          `json:"import_max_heap_mb" yaml:"import_max_heap_mb"`
+	ImportMaxL0Tables        uint64          `json:"import_max_l0_tables" yaml:"import_max_l0_tables"`
 	GPO                      gasprice.Config `json:"gas_price_oracle" yaml:"gas_price_oracle"`
 }
 
@@ -52,6 +106,9 @@ type Telemetry struct {
 // Network defines the network configuration params
 type Network struct {
 	IgnoreDiscoverCIDR string `json:"ignore_discover_cidr"`
+	AllowCIDR          string `json:"allow_cidr"`
+	DenyCIDR           string `json:"deny_cidr"`
+	DenyASN            string `json:"deny_asn"`
 
 	NoDiscover       bool   `json:"no_discover"`
 	Libp2pAddr       string `json:"libp2p_addr"`
@@ -64,10 +121,10 @@ type Network struct {
 
 // TxPool defines the TxPool configuration params
 type TxPool struct {
-	PriceLimit            uint64 `json:"price_limit"`
-	MaxSlots              uint64 `json:"max_slots"`
-	PruneTickSeconds      uint64 `json:"prune_tick_seconds"`
-	PromoteOutdateSeconds uint64 `json:"promote_outdate_seconds"`
+	PriceLimit     uint64         `json:"price_limit"`
+	MaxSlots       uint64         `json:"max_slots"`
+	PruneTick      units.Duration `json:"prune_tick"`
+	PromoteOutdate units.Duration `json:"promote_outdate"`
 }
 
 // Headers defines the HTTP response headers required to enable CORS.
@@ -75,19 +132,37 @@ type Headers struct {
 	AccessControlAllowOrigins []string `json:"access_control_allow_origins"`
 }
 
-// minimum block generation time in seconds
-const defaultBlockTime uint64 = 2
+// minimum block generation time
+const defaultBlockTime = 2 * time.Second
+
+// max canonical blocks a reorg could drop, unlimited by default so that the node
+// is never stuck on a fork without the operator
+const defaultMaxReorgDepth uint64 = 0
+
+// max blocks re-executed per second to regenerate the missing receipts
+const defaultReceiptsBackfillRate uint64 = 10
+
+// level 0 tables of the database delaying the synced block imports, the leveldb
+// slows down the writes at 8 tables
+const defaultImportMaxL0Tables uint64 = 8
+
+// hit rate below which the full header or receipt cache grows within the budget
+const defaultCacheChainHitRate = 0.9
 
 // DefaultConfig returns the default server configuration
 func DefaultConfig() *Config {
 	defaultNetworkConfig := network.DefaultConfig()
 
 	return &Config{
+		Version:        configVersion,
 		GenesisPath:    "./genesis.json",
 		DataDir:        "./dogechain-chain",
 		BlockGasTarget: "0x0", // Special value signaling the parent gas limit should be applied
 		Network: &Network{
 			IgnoreDiscoverCIDR: "",
+			AllowCIDR:          "",
+			DenyCIDR:           "",
+			DenyASN:            "",
 			NoDiscover:         defaultNetworkConfig.NoDiscover,
 			MaxPeers:           defaultNetworkConfig.MaxPeers,
 			MaxOutboundPeers:   defaultNetworkConfig.MaxOutboundPeers,
@@ -97,16 +172,18 @@ func DefaultConfig() *Config {
 			EnableIOTimer: false,
 			EnableJaeger:  false,
 		},
-		ShouldSeal: false,
+		ShouldSeal:     false,
+		SealLeaseTTL:   units.DurationOf(lease.DefaultTTL),
+		DowntimeAction: string(consensus.DowntimeActionAlert),
 		TxPool: &TxPool{
-			PriceLimit:            0,
-			MaxSlots:              txpool.DefaultMaxSlots,
-			PruneTickSeconds:      txpool.DefaultPruneTickSeconds,
-			PromoteOutdateSeconds: txpool.DefaultPromoteOutdateSeconds,
+			PriceLimit:     0,
+			MaxSlots:       txpool.DefaultMaxSlots,
+			PruneTick:      secondsDuration(txpool.DefaultPruneTickSeconds),
+			PromoteOutdate: secondsDuration(txpool.DefaultPromoteOutdateSeconds),
 		},
 		LogLevel:    "INFO",
 		RestoreFile: "",
-		BlockTime:   defaultBlockTime,
+		BlockTime:   units.DurationOf(defaultBlockTime),
 		Headers: &Headers{
 			AccessControlAllowOrigins: []string{"*"},
 		},
@@ -116,30 +193,79 @@ func DefaultConfig() *Config {
 		JSONRPCBlockRangeLimit:   jsonrpc.DefaultJSONRPCBlockRangeLimit,
 		JSONNamespace:            string(jsonrpc.NamespaceAll),
 		EnableWS:                 false,
+		WSMaxMessageSize:         jsonrpc.DefaultWSMaxMessageSize,
+		WSMessageRateLimit:       jsonrpc.DefaultWSMessageRateLimit,
+		WSSendQueueSize:          jsonrpc.DefaultWSSendQueueSize,
+		WSDropPolicy:             string(jsonrpc.DefaultWSDropPolicy),
 		EnablePprof:              false,
+		TLSReloadInterval:        units.DurationOf(0),
+		MaxReorgDepth:            defaultMaxReorgDepth,
+		ReceiptsBackfillRate:     defaultReceiptsBackfillRate,
+		ImportMaxL0Tables:        defaultImportMaxL0Tables,
 		GPO:                      gasprice.Defaults,
 	}
 }
 
+// secondsDuration returns the duration value of the seconds
+func secondsDuration(seconds uint64) units.Duration {
+	return units.DurationOf(time.Duration(seconds) * time.Second)
+}
+
 // readConfigFile reads the config file from the specified path, builds a Config object
-// and returns it.
+// and returns it. The files of the older versions are migrated, and the renamed,
+// deprecated and unknown keys are warned.
 //
-// Supported file types: .json, .hcl
+// Supported file types: .json, .hcl, .yaml
 func readConfigFile(path string) (*Config, error) {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	var unmarshalFunc func([]byte, interface{}) error
+	return parseConfig(path, data)
+}
+
+// parseConfig parses the config file content, the format is told by the suffix of the path
+func parseConfig(path string, data []byte) (*Config, error) {
+	var err error
+
+	raw := map[string]interface{}{}
 
 	switch {
 	case strings.HasSuffix(path, ".hcl"):
-		unmarshalFunc = hcl.Unmarshal
+		if err := hcl.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+
+		normalizeHCLBlocks(raw)
+	case strings.HasSuffix(path, ".yaml"), strings.HasSuffix(path, ".yml"):
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
 	case strings.HasSuffix(path, ".json"):
-		unmarshalFunc = json.Unmarshal
+		// keep the big numbers, like the gas prices, precise
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, err
+		}
 	default:
-		return nil, fmt.Errorf("suffix of %s is neither hcl nor json", path)
+		return nil, fmt.Errorf("suffix of %s is neither hcl, yaml nor json", path)
+	}
+
+	warnings, err := migrateConfig(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, warning := range warnings {
+		log.Printf("[WARN] config %s: %s\n", path, warning)
+	}
+
+	// decode the migrated config in the json layout
+	if data, err = json.Marshal(raw); err != nil {
+		return nil, err
 	}
 
 	config := DefaultConfig()
@@ -148,7 +274,7 @@ func readConfigFile(path string) (*Config, error) {
 	config.Network.MaxInboundPeers = -1
 	config.Network.MaxOutboundPeers = -1
 
-	if err := unmarshalFunc(data, config); err != nil {
+	if err := json.Unmarshal(data, config); err != nil {
 		return nil, err
 	}
 
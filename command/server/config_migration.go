@@ -0,0 +1,207 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// configVersion is the current layout version of the server config files.
+// The files without version are of version 1.
+const configVersion uint64 = 2
+
+var (
+	errInvalidConfigVersion     = errors.New("invalid config version")
+	errUnsupportedConfigVersion = errors.New("config version is newer than supported, upgrade the node")
+)
+
+// configMigration migrates the raw config of the previous version in place, and returns the warnings
+type configMigration func(raw map[string]interface{}) []string
+
+// configMigrations are the migrations indexed by the version they migrate from
+var configMigrations = map[uint64]configMigration{
+	1: migrateConfigV1,
+}
+
+// deprecatedConfigKeys are the keys still accepted but to be removed, with the reasons
+var deprecatedConfigKeys = map[string]string{
+	"enable_block_broadcast": "block broadcast when syncing is deprecated",
+}
+
+// migrateConfigV1 renames the duration keys with unit suffixes, the values accept
+// human-friendly units now
+func migrateConfigV1(raw map[string]interface{}) []string {
+	warnings := renameConfigKey(raw, "", "block_time_s", "block_time")
+
+	if txPool, ok := raw["tx_pool"].(map[string]interface{}); ok {
+		warnings = append(warnings, renameConfigKey(txPool, "tx_pool.", "prune_tick_seconds", "prune_tick")...)
+		warnings = append(warnings, renameConfigKey(txPool, "tx_pool.", "promote_outdate_seconds", "promote_outdate")...)
+	}
+
+	return warnings
+}
+
+func renameConfigKey(raw map[string]interface{}, prefix, from, to string) []string {
+	value, ok := raw[from]
+	if !ok {
+		return nil
+	}
+
+	delete(raw, from)
+
+	if _, ok := raw[to]; ok {
+		return []string{fmt.Sprintf("key %q is replaced by %q and ignored", prefix+from, prefix+to)}
+	}
+
+	raw[to] = value
+
+	return []string{fmt.Sprintf("key %q is renamed to %q", prefix+from, prefix+to)}
+}
+
+// migrateConfig migrates the raw config to the current version in place, and returns
+// the warnings of the renamed, deprecated and unknown keys
+func migrateConfig(raw map[string]interface{}) ([]string, error) {
+	version, err := rawConfigVersion(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if version > configVersion {
+		return nil, fmt.Errorf("%w: %d > %d", errUnsupportedConfigVersion, version, configVersion)
+	}
+
+	var warnings []string
+
+	for v := version; v < configVersion; v++ {
+		warnings = append(warnings, configMigrations[v](raw)...)
+	}
+
+	if version < configVersion {
+		warnings = append(warnings, fmt.Sprintf(
+			"config version %d is migrated to %d, update the file and set \"version\" to %d",
+			version, configVersion, configVersion,
+		))
+	}
+
+	raw["version"] = configVersion
+
+	return append(warnings, checkConfigKeys(raw, reflect.TypeOf(Config{}), "")...), nil
+}
+
+func rawConfigVersion(raw map[string]interface{}) (uint64, error) {
+	value, ok := raw["version"]
+	if !ok {
+		return 1, nil
+	}
+
+	var version uint64
+
+	switch v := value.(type) {
+	case json.Number:
+		n, err := strconv.ParseUint(v.String(), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", errInvalidConfigVersion, value)
+		}
+
+		version = n
+	case int: // hcl and yaml
+		if v < 0 {
+			return 0, fmt.Errorf("%w: %v", errInvalidConfigVersion, value)
+		}
+
+		version = uint64(v)
+	default:
+		return 0, fmt.Errorf("%w: %v", errInvalidConfigVersion, value)
+	}
+
+	if version == 0 {
+		return 0, fmt.Errorf("%w: %v", errInvalidConfigVersion, value)
+	}
+
+	return version, nil
+}
+
+// checkConfigKeys warns the deprecated and unknown keys of the struct type. The keys
+// of the Go field names, used by the hcl and yaml files, are renamed to the json ones.
+func checkConfigKeys(raw map[string]interface{}, typ reflect.Type, prefix string) []string {
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var warnings []string
+
+	for _, key := range keys {
+		field, name, ok := findConfigField(typ, key)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("unknown key %q is ignored", prefix+key))
+
+			continue
+		}
+
+		if reason, ok := deprecatedConfigKeys[prefix+name]; ok {
+			warnings = append(warnings, fmt.Sprintf("key %q is deprecated: %s", prefix+key, reason))
+		}
+
+		value := raw[key]
+
+		if !strings.EqualFold(key, name) {
+			delete(raw, key)
+			raw[name] = value
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok && fieldType.Kind() == reflect.Struct {
+			warnings = append(warnings, checkConfigKeys(nested, fieldType, prefix+name+".")...)
+		}
+	}
+
+	return warnings
+}
+
+// findConfigField finds the struct field of the key, and returns its json name
+func findConfigField(typ reflect.Type, key string) (reflect.StructField, string, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		} else if name == "" {
+			name = field.Name
+		}
+
+		// the json keys are case insensitive
+		if strings.EqualFold(key, name) || strings.EqualFold(key, field.Name) {
+			return field, name, true
+		}
+	}
+
+	return reflect.StructField{}, "", false
+}
+
+// normalizeHCLBlocks converts the hcl blocks, decoded as lists of objects, to objects
+func normalizeHCLBlocks(raw map[string]interface{}) {
+	for key, value := range raw {
+		if blocks, ok := value.([]map[string]interface{}); ok && len(blocks) == 1 {
+			raw[key] = blocks[0]
+		}
+
+		if nested, ok := raw[key].(map[string]interface{}); ok {
+			normalizeHCLBlocks(nested)
+		}
+	}
+}
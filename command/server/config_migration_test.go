@@ -0,0 +1,339 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/helper/units"
+	"github.com/stretchr/testify/assert"
+)
+
+// testMigratedWarning tells the files without version to be updated
+const testMigratedWarning = `config version 1 is migrated to 2, update the file and set "version" to 2`
+
+func TestMigrateConfig_LegacyKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected map[string]interface{}
+		warnings []string
+	}{
+		{
+			name: "block_time_s",
+			raw:  map[string]interface{}{"block_time_s": json.Number("3")},
+			expected: map[string]interface{}{
+				"block_time": json.Number("3"),
+			},
+			warnings: []string{`key "block_time_s" is renamed to "block_time"`, testMigratedWarning},
+		},
+		{
+			name: "tx_pool.prune_tick_seconds",
+			raw: map[string]interface{}{
+				"tx_pool": map[string]interface{}{"prune_tick_seconds": json.Number("5")},
+			},
+			expected: map[string]interface{}{
+				"tx_pool": map[string]interface{}{"prune_tick": json.Number("5")},
+			},
+			warnings: []string{`key "tx_pool.prune_tick_seconds" is renamed to "tx_pool.prune_tick"`, testMigratedWarning},
+		},
+		{
+			name: "tx_pool.promote_outdate_seconds",
+			raw: map[string]interface{}{
+				"tx_pool": map[string]interface{}{"promote_outdate_seconds": json.Number("60")},
+			},
+			expected: map[string]interface{}{
+				"tx_pool": map[string]interface{}{"promote_outdate": json.Number("60")},
+			},
+			warnings: []string{
+				`key "tx_pool.promote_outdate_seconds" is renamed to "tx_pool.promote_outdate"`,
+				testMigratedWarning,
+			},
+		},
+		{
+			name: "the new key wins",
+			raw: map[string]interface{}{
+				"block_time_s": json.Number("3"),
+				"block_time":   "5s",
+			},
+			expected: map[string]interface{}{
+				"block_time": "5s",
+			},
+			warnings: []string{`key "block_time_s" is replaced by "block_time" and ignored`, testMigratedWarning},
+		},
+		{
+			name: "the version 2 keeps the legacy keys",
+			raw: map[string]interface{}{
+				"version":      json.Number("2"),
+				"block_time_s": json.Number("3"),
+			},
+			expected: map[string]interface{}{
+				"block_time_s": json.Number("3"),
+			},
+			warnings: []string{`unknown key "block_time_s" is ignored`},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			warnings, err := migrateConfig(test.raw)
+			assert.NoError(t, err)
+
+			test.expected["version"] = configVersion
+			assert.Equal(t, test.expected, test.raw)
+
+			assert.Equal(t, test.warnings, warnings)
+		})
+	}
+}
+
+func TestMigrateConfig_Keys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected map[string]interface{}
+		warnings []string
+	}{
+		{
+			name:     "unknown key",
+			raw:      map[string]interface{}{"block_time_ms": json.Number("3")},
+			expected: map[string]interface{}{"block_time_ms": json.Number("3")},
+			warnings: []string{`unknown key "block_time_ms" is ignored`},
+		},
+		{
+			name: "unknown nested key",
+			raw: map[string]interface{}{
+				"network": map[string]interface{}{"max_peer": json.Number("3")},
+			},
+			expected: map[string]interface{}{
+				"network": map[string]interface{}{"max_peer": json.Number("3")},
+			},
+			warnings: []string{`unknown key "network.max_peer" is ignored`},
+		},
+		{
+			name:     "deprecated key",
+			raw:      map[string]interface{}{"enable_block_broadcast": true},
+			expected: map[string]interface{}{"enable_block_broadcast": true},
+			warnings: []string{
+				`key "enable_block_broadcast" is deprecated: block broadcast when syncing is deprecated`,
+			},
+		},
+		{
+			name: "the field names are renamed to the json keys",
+			raw: map[string]interface{}{
+				"BlockTime": "3s",
+				"Network":   map[string]interface{}{"MaxPeers": 10},
+			},
+			expected: map[string]interface{}{
+				"block_time": "3s",
+				"Network":    map[string]interface{}{"max_peers": 10},
+			},
+		},
+		{
+			name:     "the json keys are case insensitive",
+			raw:      map[string]interface{}{"Block_Time": "3s"},
+			expected: map[string]interface{}{"Block_Time": "3s"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.raw["version"] = json.Number("2")
+
+			warnings, err := migrateConfig(test.raw)
+			assert.NoError(t, err)
+
+			test.expected["version"] = configVersion
+			assert.Equal(t, test.expected, test.raw)
+			assert.Equal(t, test.warnings, warnings)
+		})
+	}
+}
+
+func TestMigrateConfig_Version(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		version interface{}
+		err     error
+	}{
+		{name: "json", version: json.Number("2")},
+		{name: "hcl and yaml", version: 2},
+		{name: "newer", version: json.Number("3"), err: errUnsupportedConfigVersion},
+		{name: "zero", version: json.Number("0"), err: errInvalidConfigVersion},
+		{name: "negative", version: -1, err: errInvalidConfigVersion},
+		{name: "fraction", version: json.Number("1.5"), err: errInvalidConfigVersion},
+		{name: "string", version: "2", err: errInvalidConfigVersion},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := migrateConfig(map[string]interface{}{"version": test.version})
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNormalizeHCLBlocks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name: "single block",
+			raw: map[string]interface{}{
+				"network": []map[string]interface{}{{"max_peers": 10}},
+			},
+			expected: map[string]interface{}{
+				"network": map[string]interface{}{"max_peers": 10},
+			},
+		},
+		{
+			name: "nested blocks",
+			raw: map[string]interface{}{
+				"outer": []map[string]interface{}{{
+					"inner": []map[string]interface{}{{"key": "value"}},
+				}},
+			},
+			expected: map[string]interface{}{
+				"outer": map[string]interface{}{
+					"inner": map[string]interface{}{"key": "value"},
+				},
+			},
+		},
+		{
+			name: "repeated blocks are kept",
+			raw: map[string]interface{}{
+				"network": []map[string]interface{}{{"max_peers": 10}, {"max_peers": 20}},
+			},
+			expected: map[string]interface{}{
+				"network": []map[string]interface{}{{"max_peers": 10}, {"max_peers": 20}},
+			},
+		},
+		{
+			name:     "lists of values are kept",
+			raw:      map[string]interface{}{"admin_signers": []interface{}{"0x1", "0x2"}},
+			expected: map[string]interface{}{"admin_signers": []interface{}{"0x1", "0x2"}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			normalizeHCLBlocks(test.raw)
+			assert.Equal(t, test.expected, test.raw)
+		})
+	}
+}
+
+// the same version 1 config in every format
+var testLegacyConfigs = map[string]string{
+	"config.json": `{
+		"data_dir": "/data",
+		"block_time_s": 3,
+		"network": {"max_peers": 10, "nat_addr": "1.2.3.4"},
+		"tx_pool": {"price_limit": 1, "prune_tick_seconds": 5, "promote_outdate_seconds": 60},
+		"admin_signers": ["0x1", "0x2"]
+	}`,
+	"config.hcl": `
+		data_dir = "/data"
+		block_time_s = 3
+		network {
+			max_peers = 10
+			nat_addr = "1.2.3.4"
+		}
+		tx_pool {
+			price_limit = 1
+			prune_tick_seconds = 5
+			promote_outdate_seconds = 60
+		}
+		admin_signers = ["0x1", "0x2"]
+	`,
+	"config.yaml": `
+data_dir: /data
+block_time_s: 3
+network:
+  max_peers: 10
+  nat_addr: 1.2.3.4
+tx_pool:
+  price_limit: 1
+  prune_tick_seconds: 5
+  promote_outdate_seconds: 60
+admin_signers: ["0x1", "0x2"]
+`,
+}
+
+func TestParseConfig_Formats(t *testing.T) {
+	t.Parallel()
+
+	for name, data := range testLegacyConfigs {
+		name, data := name, data
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := parseConfig(name, []byte(data))
+			assert.NoError(t, err)
+
+			assert.Equal(t, configVersion, config.Version)
+			assert.Equal(t, "/data", config.DataDir)
+			assert.Equal(t, units.Duration("3"), config.BlockTime)
+			assert.Equal(t, int64(10), config.Network.MaxPeers)
+			assert.Equal(t, "1.2.3.4", config.Network.NatAddr)
+			assert.Equal(t, uint64(1), config.TxPool.PriceLimit)
+			assert.Equal(t, units.Duration("5"), config.TxPool.PruneTick)
+			assert.Equal(t, units.Duration("60"), config.TxPool.PromoteOutdate)
+			assert.Equal(t, []string{"0x1", "0x2"}, config.AdminSigners)
+
+			// the unset keys keep the defaults
+			assert.Equal(t, DefaultConfig().LogLevel, config.LogLevel)
+			assert.Equal(t, int64(-1), config.Network.MaxInboundPeers)
+
+			// the migrated config is read back as is
+			data, err := json.Marshal(config)
+			assert.NoError(t, err)
+
+			again, err := parseConfig("migrated.json", data)
+			assert.NoError(t, err)
+			assert.Equal(t, config, again)
+		})
+	}
+}
+
+func TestParseConfig_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseConfig("config.toml", []byte(`data_dir = "/data"`))
+	assert.Error(t, err)
+
+	_, err = parseConfig("config.json", []byte(`{"version": 3}`))
+	assert.ErrorIs(t, err, errUnsupportedConfigVersion)
+}
@@ -0,0 +1,409 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+const (
+	// remoteConfigTimeout is the timeout fetching the remote config file
+	remoteConfigTimeout = 30 * time.Second
+
+	// remoteConfigMaxSize is the max size of the remote config file
+	remoteConfigMaxSize = 4 * 1024 * 1024
+
+	// remoteConfigSignatureSuffix is appended to the config url path for its signature,
+	// and to the cache path for the cached one
+	remoteConfigSignatureSuffix = ".sig"
+
+	// remoteConfigVersionSuffix is appended to the cache path for the last version accepted
+	remoteConfigVersionSuffix = ".version"
+)
+
+// remoteConfigDomain separates the digest of the signed configs from the other signed
+// digests, so that no other signature of the signer is taken for a config
+var remoteConfigDomain = []byte("dogechain-remote-config")
+
+var (
+	errConfigChecksumMismatch  = errors.New("config checksum mismatch")
+	errConfigSignatureMismatch = errors.New("config is not signed by the signer")
+	errInvalidConfigChecksum   = errors.New("invalid config checksum, expected sha256 in hex")
+	errInvalidConfigSigner     = errors.New("invalid config signer address")
+	errConfigTooLarge          = errors.New("config file is too large")
+	errInvalidConfigSignature  = errors.New("invalid config signature")
+	errInvalidSignedVersion    = errors.New("invalid signed config version, expected a positive one")
+	errConfigVersionRollback   = errors.New("config version is not newer than the accepted one")
+	errConfigCacheRequired     = errors.New("config cache is required to track the signed config versions")
+	errUnverifiedConfig        = errors.New("remote config requires a checksum or a signer to verify it")
+)
+
+// remoteConfigSignature is the signature file of the config, the signature is of the
+// digest of the version and the config file
+type remoteConfigSignature struct {
+	Version   uint64 `json:"version"`
+	Signature string `json:"signature"`
+}
+
+// remoteConfigVersion is the last signed config accepted, persisted next to the cache
+type remoteConfigVersion struct {
+	Version uint64     `json:"version"`
+	Hash    types.Hash `json:"hash"`
+}
+
+// remoteConfig fetches the config file from http(s) or s3, verifies and caches it. The
+// signed config versions never go back, the last one accepted is persisted.
+type remoteConfig struct {
+	url       *url.URL
+	sigURL    *url.URL       // url of the signature, fetched only if signer is set
+	checksum  []byte         // sha256 of the file, skipped if empty
+	signer    *types.Address // signer of the file, skipped if nil
+	cachePath string         // the verified file is cached, skipped if empty
+
+	client *http.Client
+}
+
+// isRemoteConfigPath returns whether the config path is a url
+func isRemoteConfigPath(path string) bool {
+	for _, scheme := range []string{"http://", "https://", "s3://"} {
+		if strings.HasPrefix(path, scheme) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newRemoteConfig returns the remote config of the url. The signature is fetched from
+// the signature url if given, otherwise from the config url path with the .sig suffix
+// and without the query, as a presigned query only grants the config object itself.
+// The config is rewritable on the way, so it is never taken without a checksum or a
+// signer.
+func newRemoteConfig(rawURL, checksum, signer, signatureURL, cachePath string) (*remoteConfig, error) {
+	if checksum == "" && signer == "" {
+		return nil, errUnverifiedConfig
+	}
+
+	u, err := parseRemoteConfigURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &remoteConfig{
+		url:       u,
+		cachePath: cachePath,
+		client:    &http.Client{Timeout: remoteConfigTimeout},
+	}
+
+	if signatureURL != "" {
+		if rc.sigURL, err = parseRemoteConfigURL(signatureURL); err != nil {
+			return nil, err
+		}
+	} else {
+		rc.sigURL = &url.URL{
+			Scheme: u.Scheme,
+			Host:   u.Host,
+			Path:   u.Path + remoteConfigSignatureSuffix,
+		}
+	}
+
+	if checksum != "" {
+		if rc.checksum, err = hex.DecodeString(strings.TrimPrefix(checksum, "0x")); err != nil ||
+			len(rc.checksum) != sha256.Size {
+			return nil, errInvalidConfigChecksum
+		}
+	}
+
+	if signer != "" {
+		rc.signer = new(types.Address)
+
+		if err := rc.signer.UnmarshalText([]byte(signer)); err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidConfigSigner, signer)
+		}
+
+		if cachePath == "" {
+			return nil, errConfigCacheRequired
+		}
+	}
+
+	return rc, nil
+}
+
+// parseRemoteConfigURL parses the config url, the s3 objects are fetched by the
+// virtual-hosted style url, so they should be public or presigned
+func parseRemoteConfigURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "s3" {
+		if u.Host == "" {
+			return nil, fmt.Errorf("bucket of %s is missing", rawURL)
+		}
+
+		u.Host = u.Host + ".s3.amazonaws.com"
+		u.Scheme = "https"
+	}
+
+	return u, nil
+}
+
+// name returns the url of the config without the query, the suffix tells the format
+func (rc *remoteConfig) name() string {
+	u := url.URL{Scheme: rc.url.Scheme, Host: rc.url.Host, Path: rc.url.Path}
+
+	return u.String()
+}
+
+// read fetches the config file and verifies it. The cached file is used if the
+// fetching fails, and it is verified as well.
+func (rc *remoteConfig) read() ([]byte, error) {
+	data, signature, err := rc.fetch()
+	if err != nil {
+		if rc.cachePath == "" {
+			return nil, err
+		}
+
+		log.Printf("[WARN] failed to fetch config %s, use the cached one: %v\n", rc.name(), err)
+
+		if data, signature, err = rc.readCache(); err != nil {
+			return nil, err
+		}
+	}
+
+	version, err := rc.verify(data, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	// the version is never accepted again once a newer one is, even on failing to cache
+	if version != nil {
+		if err := rc.writeVersion(version); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rc.writeCache(data, signature); err != nil {
+		log.Printf("[WARN] failed to cache config %s: %v\n", rc.name(), err)
+	}
+
+	return data, nil
+}
+
+func (rc *remoteConfig) fetch() ([]byte, []byte, error) {
+	data, err := rc.get(rc.url)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if rc.signer == nil {
+		return data, nil, nil
+	}
+
+	signature, err := rc.get(rc.sigURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, signature, nil
+}
+
+func (rc *remoteConfig) get(u *url.URL) ([]byte, error) {
+	resp, err := rc.client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// the query might be a presigned token
+		return nil, fmt.Errorf("failed to fetch %s%s: %s", u.Host, u.Path, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, remoteConfigMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > remoteConfigMaxSize {
+		return nil, errConfigTooLarge
+	}
+
+	return data, nil
+}
+
+// remoteConfigDigest returns the digest signed for the version of the config file
+func remoteConfigDigest(version uint64, data []byte) []byte {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, version)
+
+	return crypto.Keccak256(remoteConfigDomain, v, crypto.Keccak256(data))
+}
+
+// verify checks the sha256 checksum, and the signature of the version and the file.
+// It returns the signed version, which is rejected if older than the accepted one, or
+// as old but of another file.
+func (rc *remoteConfig) verify(data, signature []byte) (*remoteConfigVersion, error) {
+	if len(rc.checksum) > 0 {
+		if sum := sha256.Sum256(data); !bytes.Equal(sum[:], rc.checksum) {
+			return nil, fmt.Errorf("%w: %x", errConfigChecksumMismatch, sum)
+		}
+	}
+
+	if rc.signer == nil {
+		return nil, nil
+	}
+
+	var signed remoteConfigSignature
+	if err := json.Unmarshal(signature, &signed); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidConfigSignature, err)
+	}
+
+	if signed.Version == 0 {
+		return nil, errInvalidSignedVersion
+	}
+
+	sig, err := hex.DecodeString(strings.TrimPrefix(signed.Signature, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidConfigSignature, err)
+	}
+
+	pub, err := crypto.RecoverPubkey(sig, remoteConfigDigest(signed.Version, data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidConfigSignature, err)
+	}
+
+	if signer := crypto.PubKeyToAddress(pub); signer != *rc.signer {
+		return nil, fmt.Errorf("%w: %s", errConfigSignatureMismatch, signer)
+	}
+
+	version := &remoteConfigVersion{
+		Version: signed.Version,
+		Hash:    types.BytesToHash(crypto.Keccak256(data)),
+	}
+
+	accepted, err := rc.readVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	// the accepted config itself is read again on every start
+	if accepted != nil && (version.Version < accepted.Version ||
+		version.Version == accepted.Version && version.Hash != accepted.Hash) {
+		return nil, fmt.Errorf("%w: version %d, accepted %d",
+			errConfigVersionRollback, version.Version, accepted.Version)
+	}
+
+	return version, nil
+}
+
+// readVersion reads the last signed config accepted, it returns nil if none
+func (rc *remoteConfig) readVersion() (*remoteConfigVersion, error) {
+	data, err := ioutil.ReadFile(rc.cachePath + remoteConfigVersionSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	version := &remoteConfigVersion{}
+	if err := json.Unmarshal(data, version); err != nil {
+		return nil, fmt.Errorf("invalid config version file: %w", err)
+	}
+
+	return version, nil
+}
+
+func (rc *remoteConfig) writeVersion(version *remoteConfigVersion) error {
+	data, err := json.Marshal(version)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rc.cachePath), 0755); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(rc.cachePath+remoteConfigVersionSuffix, data)
+}
+
+func (rc *remoteConfig) readCache() ([]byte, []byte, error) {
+	data, err := ioutil.ReadFile(rc.cachePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if rc.signer == nil {
+		return data, nil, nil
+	}
+
+	signature, err := ioutil.ReadFile(rc.cachePath + remoteConfigSignatureSuffix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, signature, nil
+}
+
+func (rc *remoteConfig) writeCache(data, signature []byte) error {
+	if rc.cachePath == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rc.cachePath), 0755); err != nil {
+		return err
+	}
+
+	if signature != nil {
+		if err := writeFileAtomic(rc.cachePath+remoteConfigSignatureSuffix, signature); err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(rc.cachePath, data)
+}
+
+// writeFileAtomic writes the file by renaming a temporary one, so that a partial
+// file is never read
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// readRemoteConfig fetches the config file from the url, verifies and parses it
+func readRemoteConfig(rawURL, checksum, signer, signatureURL, cachePath string) (*Config, error) {
+	rc, err := newRemoteConfig(rawURL, checksum, signer, signatureURL, cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := rc.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseConfig(rc.name(), data)
+}
@@ -0,0 +1,399 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var testRemoteConfig = []byte(`{"data_dir": "remote"}`)
+
+// configServer serves the files by path, recording the requested urls
+type configServer struct {
+	sync.Mutex
+
+	files     map[string][]byte
+	requested []string
+	down      bool
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.requested = append(s.requested, r.URL.String())
+
+	data, ok := s.files[r.URL.Path]
+	if s.down || !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	_, _ = w.Write(data)
+}
+
+func (s *configServer) setDown(down bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.down = down
+}
+
+func newConfigServer(t *testing.T, files map[string][]byte) (*configServer, string) {
+	t.Helper()
+
+	cs := &configServer{files: files}
+
+	server := httptest.NewServer(cs)
+	t.Cleanup(server.Close)
+
+	return cs, server.URL
+}
+
+// newConfigSigner returns the key signing the configs and its address
+func newConfigSigner(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	return key, crypto.PubKeyToAddress(&key.PublicKey).String()
+}
+
+// signConfigVersion returns the signature file of the version of the data
+func signConfigVersion(t *testing.T, key *ecdsa.PrivateKey, version uint64, data []byte) []byte {
+	t.Helper()
+
+	sig, err := crypto.Sign(key, remoteConfigDigest(version, data))
+	assert.NoError(t, err)
+
+	signature, err := json.Marshal(&remoteConfigSignature{
+		Version:   version,
+		Signature: "0x" + hex.EncodeToString(sig),
+	})
+	assert.NoError(t, err)
+
+	return signature
+}
+
+// signConfig returns the signer address and the signature file of the first version
+// of the data
+func signConfig(t *testing.T, data []byte) (string, []byte) {
+	t.Helper()
+
+	key, signer := newConfigSigner(t)
+
+	return signer, signConfigVersion(t, key, 1, data)
+}
+
+// testChecksum returns the checksum of the test config
+func testChecksum() string {
+	sum := sha256.Sum256(testRemoteConfig)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// testCachePath returns a config cache path of the test
+func testCachePath(t *testing.T) string {
+	t.Helper()
+
+	return filepath.Join(t.TempDir(), "cache", "config.json")
+}
+
+func TestRemoteConfig_Fetch(t *testing.T) {
+	t.Parallel()
+
+	signer, sig := signConfig(t, testRemoteConfig)
+
+	t.Run("the default signature url drops the query", func(t *testing.T) {
+		t.Parallel()
+
+		cs, host := newConfigServer(t, map[string][]byte{
+			"/config.json":     testRemoteConfig,
+			"/config.json.sig": sig,
+		})
+
+		rc, err := newRemoteConfig(host+"/config.json?token=abc", "", signer, "", testCachePath(t))
+		assert.NoError(t, err)
+
+		data, err := rc.read()
+		assert.NoError(t, err)
+		assert.Equal(t, testRemoteConfig, data)
+		assert.Equal(t, []string{"/config.json?token=abc", "/config.json.sig"}, cs.requested)
+	})
+
+	t.Run("the signature url is used as is", func(t *testing.T) {
+		t.Parallel()
+
+		cs, host := newConfigServer(t, map[string][]byte{
+			"/config.json":   testRemoteConfig,
+			"/signature.hex": sig,
+		})
+
+		rc, err := newRemoteConfig(
+			host+"/config.json?token=abc",
+			"",
+			signer,
+			host+"/signature.hex?token=def",
+			testCachePath(t),
+		)
+		assert.NoError(t, err)
+
+		data, err := rc.read()
+		assert.NoError(t, err)
+		assert.Equal(t, testRemoteConfig, data)
+		assert.Equal(t, []string{"/config.json?token=abc", "/signature.hex?token=def"}, cs.requested)
+	})
+
+	t.Run("the signature is not fetched without a signer", func(t *testing.T) {
+		t.Parallel()
+
+		cs, host := newConfigServer(t, map[string][]byte{
+			"/config.json": testRemoteConfig,
+		})
+
+		rc, err := newRemoteConfig(host+"/config.json", testChecksum(), "", "", "")
+		assert.NoError(t, err)
+
+		_, err = rc.read()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"/config.json"}, cs.requested)
+	})
+
+	t.Run("the missing signature fails", func(t *testing.T) {
+		t.Parallel()
+
+		_, host := newConfigServer(t, map[string][]byte{
+			"/config.json": testRemoteConfig,
+		})
+
+		rc, err := newRemoteConfig(host+"/config.json", "", signer, "", testCachePath(t))
+		assert.NoError(t, err)
+
+		_, err = rc.read()
+		assert.Error(t, err)
+	})
+
+	t.Run("s3 urls are virtual-hosted", func(t *testing.T) {
+		t.Parallel()
+
+		rc, err := newRemoteConfig("s3://bucket/config.json?X-Amz-Signature=abc", "", signer, "", testCachePath(t))
+		assert.NoError(t, err)
+
+		assert.Equal(t, "https://bucket.s3.amazonaws.com/config.json?X-Amz-Signature=abc", rc.url.String())
+		assert.Equal(t, "https://bucket.s3.amazonaws.com/config.json.sig", rc.sigURL.String())
+		assert.Equal(t, "https://bucket.s3.amazonaws.com/config.json", rc.name())
+	})
+}
+
+func TestRemoteConfig_Verify(t *testing.T) {
+	t.Parallel()
+
+	signer, sig := signConfig(t, testRemoteConfig)
+	otherSigner, _ := signConfig(t, testRemoteConfig)
+	sum := sha256.Sum256(testRemoteConfig)
+
+	// the raw signature of the file hash, and the signature of another version
+	var (
+		key, keySigner = newConfigSigner(t)
+		rawSig, _      = crypto.Sign(key, crypto.Keccak256(testRemoteConfig))
+		versionSig     = signConfigVersion(t, key, 2, testRemoteConfig)
+		forgedVersion  = bytes.Replace(versionSig, []byte(`"version":2`), []byte(`"version":3`), 1)
+	)
+
+	testTable := []struct {
+		name      string
+		checksum  string
+		signer    string
+		signature []byte
+		err       error
+	}{
+		{
+			name:     "checksum matches",
+			checksum: "0x" + hex.EncodeToString(sum[:]),
+		},
+		{
+			name:     "checksum mismatches",
+			checksum: hex.EncodeToString(make([]byte, sha256.Size)),
+			err:      errConfigChecksumMismatch,
+		},
+		{
+			name:      "signed by the signer",
+			signer:    signer,
+			signature: sig,
+		},
+		{
+			name:      "signed by another signer",
+			signer:    otherSigner,
+			signature: sig,
+			err:       errConfigSignatureMismatch,
+		},
+		{
+			name:      "signature of the file hash only",
+			signer:    keySigner,
+			signature: []byte(hex.EncodeToString(rawSig)),
+			err:       errInvalidConfigSignature,
+		},
+		{
+			name:      "signature of another version",
+			signer:    keySigner,
+			signature: forgedVersion,
+			err:       errConfigSignatureMismatch,
+		},
+		{
+			name:      "version zero",
+			signer:    keySigner,
+			signature: signConfigVersion(t, key, 0, testRemoteConfig),
+			err:       errInvalidSignedVersion,
+		},
+	}
+
+	for _, tt := range testTable {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rc, err := newRemoteConfig("https://host/config.json", tt.checksum, tt.signer, "", testCachePath(t))
+			assert.NoError(t, err)
+
+			_, err = rc.verify(testRemoteConfig, tt.signature)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	t.Run("nothing to verify", func(t *testing.T) {
+		t.Parallel()
+
+		for _, rawURL := range []string{"http://host/config.json", "https://host/config.json", "s3://bucket/config.json"} {
+			_, err := newRemoteConfig(rawURL, "", "", "", "")
+			assert.ErrorIs(t, err, errUnverifiedConfig)
+		}
+	})
+
+	t.Run("invalid checksum", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := newRemoteConfig("https://host/config.json", "0x1234", "", "", "")
+		assert.ErrorIs(t, err, errInvalidConfigChecksum)
+	})
+
+	t.Run("invalid signer", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := newRemoteConfig("https://host/config.json", "", "0x1234", "", "")
+		assert.ErrorIs(t, err, errInvalidConfigSigner)
+	})
+
+	t.Run("signer without cache", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := newRemoteConfig("https://host/config.json", "", signer, "", "")
+		assert.ErrorIs(t, err, errConfigCacheRequired)
+	})
+}
+
+func TestRemoteConfig_Reload(t *testing.T) {
+	t.Parallel()
+
+	signer, sig := signConfig(t, testRemoteConfig)
+	cachePath := testCachePath(t)
+
+	cs, host := newConfigServer(t, map[string][]byte{
+		"/config.json":     testRemoteConfig,
+		"/config.json.sig": sig,
+	})
+
+	config, err := readRemoteConfig(host+"/config.json", "", signer, "", cachePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "remote", config.DataDir)
+
+	// the cached config is reloaded and verified once the server is down
+	cs.setDown(true)
+
+	config, err = readRemoteConfig(host+"/config.json", "", signer, "", cachePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "remote", config.DataDir)
+
+	// the cached config is not trusted for another signer
+	otherSigner, _ := signConfig(t, testRemoteConfig)
+
+	_, err = readRemoteConfig(host+"/config.json", "", otherSigner, "", cachePath)
+	assert.ErrorIs(t, err, errConfigSignatureMismatch)
+
+	// no cache to reload from
+	cs.setDown(true)
+
+	_, err = readRemoteConfig(host+"/config.json", testChecksum(), "", "", "")
+	assert.Error(t, err)
+}
+
+func TestRemoteConfig_Version(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, signer = newConfigSigner(t)
+		cachePath   = testCachePath(t)
+		oldConfig   = []byte(`{"data_dir": "old"}`)
+		newConfig   = []byte(`{"data_dir": "new"}`)
+	)
+
+	cs, host := newConfigServer(t, map[string][]byte{
+		"/config.json":     newConfig,
+		"/config.json.sig": signConfigVersion(t, key, 2, newConfig),
+	})
+
+	serve := func(data, signature []byte) {
+		cs.Lock()
+		defer cs.Unlock()
+
+		cs.files["/config.json"], cs.files["/config.json.sig"] = data, signature
+	}
+
+	config, err := readRemoteConfig(host+"/config.json", "", signer, "", cachePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", config.DataDir)
+
+	// the accepted config is accepted again, fetched or cached
+	config, err = readRemoteConfig(host+"/config.json", "", signer, "", cachePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", config.DataDir)
+
+	// the older config signed before is never rolled back to
+	serve(oldConfig, signConfigVersion(t, key, 1, oldConfig))
+
+	_, err = readRemoteConfig(host+"/config.json", "", signer, "", cachePath)
+	assert.ErrorIs(t, err, errConfigVersionRollback)
+
+	// nor another config of the accepted version
+	serve(oldConfig, signConfigVersion(t, key, 2, oldConfig))
+
+	_, err = readRemoteConfig(host+"/config.json", "", signer, "", cachePath)
+	assert.ErrorIs(t, err, errConfigVersionRollback)
+
+	// the newer config is accepted, and the cached older one is rejected since
+	serve(oldConfig, signConfigVersion(t, key, 3, oldConfig))
+
+	config, err = readRemoteConfig(host+"/config.json", "", signer, "", cachePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "old", config.DataDir)
+
+	assert.NoError(t, writeFileAtomic(cachePath, newConfig))
+	assert.NoError(t, writeFileAtomic(cachePath+remoteConfigSignatureSuffix, signConfigVersion(t, key, 2, newConfig)))
+
+	cs.setDown(true)
+
+	_, err = readRemoteConfig(host+"/config.json", "", signer, "", cachePath)
+	assert.ErrorIs(t, err, errConfigVersionRollback)
+}
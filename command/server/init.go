@@ -6,11 +6,22 @@ import (
 	"math"
 	"math/big"
 	"net"
+	"net/url"
+	"os"
+	"time"
 
 	"github.com/dogechain-lab/dogechain/network/common"
 
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
 	"github.com/dogechain-lab/dogechain/chain"
 	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/dogechain-lab/dogechain/consensus"
+	"github.com/dogechain-lab/dogechain/consensus/ibft"
+	"github.com/dogechain-lab/dogechain/consensus/lease"
+	"github.com/dogechain-lab/dogechain/consensus/signer"
+	"github.com/dogechain-lab/dogechain/helper/tlsutil"
+	"github.com/dogechain-lab/dogechain/helper/units"
+	"github.com/dogechain-lab/dogechain/jsonrpc"
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/secrets"
 	"github.com/dogechain-lab/dogechain/server"
@@ -20,12 +31,40 @@ import (
 var (
 	errInvalidBlockTime       = errors.New("invalid block time specified")
 	errDataDirectoryUndefined = errors.New("data directory not defined")
+	errInvalidWSDropPolicy    = errors.New("invalid websocket drop policy specified")
+	errInvalidSenderGasShare  = errors.New("invalid block sender gas share specified")
+	errInvalidExtraVanity     = errors.New("invalid block extra vanity specified")
+	errInvalidTxPoolDuration  = errors.New("invalid tx pool duration specified")
+	errInvalidLevelDBSize     = errors.New("invalid leveldb size specified")
+	errInvalidSlowThreshold   = errors.New("invalid leveldb slow threshold specified")
+	errInvalidCacheSize       = errors.New("invalid cache size specified")
+	errInvalidCacheHitRate    = errors.New("invalid cache hit rate specified")
+	errInvalidDBKeyLayout     = errors.New("invalid database key layout specified")
+	errInvalidAdminApproval   = errors.New("invalid admin approval specified")
+	errInvalidRateLimit       = errors.New("invalid json-rpc rate limit specified")
+	errInvalidTrustedProxies  = errors.New("invalid json-rpc trusted proxies specified")
+	errInvalidTLSConfig       = errors.New("invalid tls certificate specified")
+	errInvalidDowntime        = errors.New("invalid validator downtime watchdog specified")
+	errInvalidSealLease       = errors.New("invalid sealing lease specified")
+	errInvalidRemoteSigner    = errors.New("invalid remote signer specified")
 )
 
 func (p *serverParams) initConfigFromFile() error {
 	var parseErr error
 
-	if p.rawConfig, parseErr = readConfigFile(p.configPath); parseErr != nil {
+	if isRemoteConfigPath(p.configPath) {
+		p.rawConfig, parseErr = readRemoteConfig(
+			p.configPath,
+			p.configChecksum,
+			p.configSigner,
+			p.configSignatureURL,
+			p.configCachePath,
+		)
+	} else {
+		p.rawConfig, parseErr = readConfigFile(p.configPath)
+	}
+
+	if parseErr != nil {
 		return parseErr
 	}
 
@@ -37,6 +76,26 @@ func (p *serverParams) initRawParams() error {
 		return err
 	}
 
+	if err := p.initBlockSenderLimits(); err != nil {
+		return err
+	}
+
+	if err := p.initBlockExtraVanity(); err != nil {
+		return err
+	}
+
+	if err := p.initSealLease(); err != nil {
+		return err
+	}
+
+	if err := p.initRemoteSigner(); err != nil {
+		return err
+	}
+
+	if err := p.initDowntime(); err != nil {
+		return err
+	}
+
 	if err := p.initSecretsConfig(); err != nil {
 		return err
 	}
@@ -53,6 +112,50 @@ func (p *serverParams) initRawParams() error {
 		return err
 	}
 
+	if err := p.initTxPoolDurations(); err != nil {
+		return err
+	}
+
+	if err := p.initLevelDBSizes(); err != nil {
+		return err
+	}
+
+	if err := p.initLevelDBSlowThreshold(); err != nil {
+		return err
+	}
+
+	if err := p.initCacheSizes(); err != nil {
+		return err
+	}
+
+	if err := p.initWSDropPolicy(); err != nil {
+		return err
+	}
+
+	if err := p.initJSONRPCRateLimit(); err != nil {
+		return err
+	}
+
+	if err := p.initJSONRPCTrustedProxies(); err != nil {
+		return err
+	}
+
+	if err := p.initTLSConfigs(); err != nil {
+		return err
+	}
+
+	if err := p.initDBKeyLayout(); err != nil {
+		return err
+	}
+
+	if err := p.initAdminToken(); err != nil {
+		return err
+	}
+
+	if err := p.initAdminApproval(); err != nil {
+		return err
+	}
+
 	if p.isDevMode {
 		p.initDevMode()
 	}
@@ -66,10 +169,364 @@ func (p *serverParams) initRawParams() error {
 }
 
 func (p *serverParams) initBlockTime() error {
-	if p.rawConfig.BlockTime < 1 {
+	blockTime, err := p.rawConfig.BlockTime.Seconds()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidBlockTime, err)
+	}
+
+	if blockTime < 1 {
 		return errInvalidBlockTime
 	}
 
+	p.blockTime = blockTime
+
+	return nil
+}
+
+func (p *serverParams) initTxPoolDurations() error {
+	var err error
+
+	if p.pruneTickSeconds, err = p.rawConfig.TxPool.PruneTick.Seconds(); err != nil {
+		return fmt.Errorf("%w: %s: %v", errInvalidTxPoolDuration, pruneTickSecondsFlag, err)
+	}
+
+	if p.promoteOutdateSeconds, err = p.rawConfig.TxPool.PromoteOutdate.Seconds(); err != nil {
+		return fmt.Errorf("%w: %s: %v", errInvalidTxPoolDuration, promoteOutdateSecondsFlag, err)
+	}
+
+	return nil
+}
+
+func (p *serverParams) initLevelDBSizes() error {
+	// the decimal sizes are rounded to MiB, down for the cache budgets,
+	// and up for the table sizes
+	sizes := []struct {
+		flag    string
+		raw     units.Size
+		value   *int
+		roundUp bool
+	}{
+		{leveldbCacheFlag, p.leveldbCacheSize, &p.leveldbCacheSizeMiB, false},
+		{leveldbTableSizeFlag, p.leveldbTableSize, &p.leveldbTableSizeMiB, true},
+		{leveldbTotalTableSizeFlag, p.leveldbTotalTableSize, &p.leveldbTotalTableSizeMiB, true},
+		{receiptsDBCacheFlag, p.receiptsDBCacheSize, &p.receiptsDBCacheSizeMiB, false},
+	}
+
+	for _, size := range sizes {
+		toMiB := size.raw.MiB
+		if size.roundUp {
+			toMiB = size.raw.MiBCeil
+		}
+
+		mib, err := toMiB()
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", errInvalidLevelDBSize, size.flag, err)
+		}
+
+		if mib > math.MaxInt32 {
+			return fmt.Errorf("%w: %s: %s is too large", errInvalidLevelDBSize, size.flag, size.raw)
+		}
+
+		*size.value = int(mib)
+	}
+
+	batchSize, err := p.leveldbBatchSize.Bytes()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", errInvalidLevelDBSize, leveldbBatchSizeFlag, err)
+	}
+
+	if batchSize > math.MaxInt32 {
+		return fmt.Errorf("%w: %s: %s is too large", errInvalidLevelDBSize, leveldbBatchSizeFlag, p.leveldbBatchSize)
+	}
+
+	p.leveldbBatchSizeBytes = int(batchSize)
+
+	return nil
+}
+
+func (p *serverParams) initLevelDBSlowThreshold() error {
+	threshold, err := p.leveldbSlowThreshold.Duration()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", errInvalidSlowThreshold, leveldbSlowThresholdFlag, err)
+	}
+
+	p.leveldbSlowOpThreshold = threshold
+
+	return nil
+}
+
+func (p *serverParams) initCacheSizes() error {
+	sizes := []struct {
+		flag  string
+		raw   units.Size
+		value *int
+	}{
+		{cacheStateFlag, p.cacheStateSize, &p.cacheStateSizeBytes},
+		{cacheCodeFlag, p.cacheCodeSize, &p.cacheCodeSizeBytes},
+	}
+
+	for _, size := range sizes {
+		bytes, err := size.raw.Bytes()
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", errInvalidCacheSize, size.flag, err)
+		}
+
+		if bytes > math.MaxInt32 {
+			return fmt.Errorf("%w: %s: %s is too large", errInvalidCacheSize, size.flag, size.raw)
+		}
+
+		*size.value = int(bytes)
+	}
+
+	var err error
+
+	if p.cacheChainBudgetBytes, err = p.cacheChainBudget.Bytes(); err != nil {
+		return fmt.Errorf("%w: %s: %v", errInvalidCacheSize, cacheChainBudgetFlag, err)
+	}
+
+	if p.cacheChainMaxHeapBytes, err = p.cacheChainMaxHeap.Bytes(); err != nil {
+		return fmt.Errorf("%w: %s: %v", errInvalidCacheSize, cacheChainMaxHeapFlag, err)
+	}
+
+	if p.cacheChainHitRate <= 0 || p.cacheChainHitRate > 1 {
+		return fmt.Errorf("%w: %v is not within (0, 1]", errInvalidCacheHitRate, p.cacheChainHitRate)
+	}
+
+	return nil
+}
+
+func (p *serverParams) initBlockSenderLimits() error {
+	if p.rawConfig.BlockMaxSenderGasShare > 100 {
+		return errInvalidSenderGasShare
+	}
+
+	return nil
+}
+
+func (p *serverParams) initBlockExtraVanity() error {
+	if len(p.rawConfig.BlockExtraVanity) > ibft.IstanbulExtraVanity {
+		return fmt.Errorf(
+			"%w: %d bytes exceeds %d bytes",
+			errInvalidExtraVanity,
+			len(p.rawConfig.BlockExtraVanity),
+			ibft.IstanbulExtraVanity,
+		)
+	}
+
+	return nil
+}
+
+func (p *serverParams) initSealLease() error {
+	if p.rawConfig.SealLease == "" {
+		return nil
+	}
+
+	if !p.rawConfig.ShouldSeal {
+		return fmt.Errorf("%w: the lease requires the %s flag", errInvalidSealLease, sealFlag)
+	}
+
+	ttl, err := p.rawConfig.SealLeaseTTL.Duration()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", errInvalidSealLease, sealLeaseTTLFlag, err)
+	}
+
+	config := &lease.Config{
+		URL: p.rawConfig.SealLease,
+		TTL: ttl,
+	}
+
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidSealLease, err)
+	}
+
+	p.sealLease = config
+
+	return nil
+}
+
+func (p *serverParams) initRemoteSigner() error {
+	if p.rawConfig.RemoteSigner == "" {
+		return nil
+	}
+
+	config := &signer.RemoteConfig{
+		URL:     p.rawConfig.RemoteSigner,
+		Token:   os.Getenv(signer.TokenEnv),
+		Timeout: signer.DefaultTimeout,
+	}
+
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidRemoteSigner, err)
+	}
+
+	p.remoteSigner = config
+
+	return nil
+}
+
+func (p *serverParams) initDowntime() error {
+	if p.rawConfig.DowntimeMissedTurns == 0 {
+		return nil
+	}
+
+	action, err := consensus.ParseDowntimeAction(p.rawConfig.DowntimeAction)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidDowntime, err)
+	}
+
+	if action == consensus.DowntimeActionFailover && p.rawConfig.DowntimeWebhook == "" {
+		return fmt.Errorf("%w: the %s action requires the %s", errInvalidDowntime, action, downtimeWebhookFlag)
+	}
+
+	if p.rawConfig.DowntimeWebhook != "" {
+		if u, err := url.Parse(p.rawConfig.DowntimeWebhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("%w: %s: %s", errInvalidDowntime, downtimeWebhookFlag, p.rawConfig.DowntimeWebhook)
+		}
+	}
+
+	p.downtime = &consensus.DowntimeConfig{
+		MissedTurns: p.rawConfig.DowntimeMissedTurns,
+		Action:      action,
+		WebhookURL:  p.rawConfig.DowntimeWebhook,
+	}
+
+	return nil
+}
+
+func (p *serverParams) initWSDropPolicy() error {
+	switch jsonrpc.WSDropPolicy(p.rawConfig.WSDropPolicy) {
+	case jsonrpc.WSDropPolicyDrop, jsonrpc.WSDropPolicyDisconnect:
+		return nil
+	default:
+		return errInvalidWSDropPolicy
+	}
+}
+
+func (p *serverParams) initJSONRPCRateLimit() error {
+	groups, err := jsonrpc.ParseRateLimits(p.rawConfig.JSONRPCMethodRateLimits)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidRateLimit, err)
+	}
+
+	if p.rawConfig.JSONRPCRateLimit == 0 && len(groups) == 0 {
+		return nil
+	}
+
+	p.jsonRPCRateLimit = &jsonrpc.RateLimitConfig{
+		PerIP: jsonrpc.RateLimit{
+			Rate:  p.rawConfig.JSONRPCRateLimit,
+			Burst: p.rawConfig.JSONRPCRateLimitBurst,
+		},
+		Groups: groups,
+	}
+
+	return nil
+}
+
+func (p *serverParams) initJSONRPCTrustedProxies() error {
+	proxies, err := jsonrpc.ParseTrustedProxies(p.rawConfig.JSONRPCTrustedProxies)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidTrustedProxies, err)
+	}
+
+	p.jsonRPCTrustedProxies = proxies
+
+	return nil
+}
+
+func (p *serverParams) initTLSConfigs() error {
+	var reloadInterval time.Duration
+
+	if p.rawConfig.TLSReloadInterval != "" {
+		var err error
+
+		if reloadInterval, err = p.rawConfig.TLSReloadInterval.Duration(); err != nil {
+			return fmt.Errorf("%w: %s: %v", errInvalidTLSConfig, tlsReloadIntervalFlag, err)
+		}
+	}
+
+	configs := []struct {
+		certFlag, keyFlag string
+		cert, key         string
+		value             **tlsutil.Config
+	}{
+		{
+			jsonRPCTLSCertFlag, jsonRPCTLSKeyFlag,
+			p.rawConfig.JSONRPCTLSCert, p.rawConfig.JSONRPCTLSKey,
+			&p.jsonRPCTLS,
+		},
+		{
+			graphqlTLSCertFlag, graphqlTLSKeyFlag,
+			p.rawConfig.GraphQLTLSCert, p.rawConfig.GraphQLTLSKey,
+			&p.graphqlTLS,
+		},
+	}
+
+	for _, c := range configs {
+		if c.cert == "" && c.key == "" {
+			continue
+		}
+
+		config := &tlsutil.Config{
+			CertFile:       c.cert,
+			KeyFile:        c.key,
+			ReloadInterval: reloadInterval,
+		}
+
+		if err := config.Validate(); err != nil {
+			return fmt.Errorf("%w: %s, %s: %v", errInvalidTLSConfig, c.certFlag, c.keyFlag, err)
+		}
+
+		*c.value = config
+	}
+
+	return nil
+}
+
+func (p *serverParams) initDBKeyLayout() error {
+	switch storage.KeyLayout(p.rawConfig.DBKeyLayout) {
+	case "", storage.DogechainKeyLayout, storage.GethKeyLayout:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", errInvalidDBKeyLayout, p.rawConfig.DBKeyLayout)
+	}
+}
+
+func (p *serverParams) initAdminToken() (err error) {
+	if p.adminTokenFile == "" {
+		return nil
+	}
+
+	p.adminToken, err = server.ReadAdminToken(p.adminTokenFile)
+
+	return err
+}
+
+func (p *serverParams) initAdminApproval() error {
+	threshold := p.rawConfig.AdminThreshold
+
+	switch {
+	case threshold == 0:
+		return nil
+	case threshold < 0 || threshold > len(p.rawConfig.AdminSigners):
+		return fmt.Errorf("%w: %d of %d operators", errInvalidAdminApproval, threshold, len(p.rawConfig.AdminSigners))
+	case p.adminToken == "":
+		return fmt.Errorf("%w: the admin operations are disabled without an admin token", errInvalidAdminApproval)
+	}
+
+	signers := make([]types.Address, len(p.rawConfig.AdminSigners))
+
+	for i, raw := range p.rawConfig.AdminSigners {
+		if err := signers[i].UnmarshalText([]byte(raw)); err != nil {
+			return fmt.Errorf("%w: %s: %v", errInvalidAdminApproval, raw, err)
+		}
+	}
+
+	p.adminApproval = &server.AdminApproval{
+		Signers:   signers,
+		Threshold: threshold,
+	}
+
 	return nil
 }
 
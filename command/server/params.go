@@ -5,10 +5,19 @@ import (
 	"log"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 
+	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
 	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/consensus"
+	"github.com/dogechain-lab/dogechain/consensus/lease"
+	"github.com/dogechain-lab/dogechain/consensus/signer"
+	"github.com/dogechain-lab/dogechain/helper/tlsutil"
+	"github.com/dogechain-lab/dogechain/helper/units"
+	"github.com/dogechain-lab/dogechain/jsonrpc"
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/secrets"
 	"github.com/dogechain-lab/dogechain/server"
@@ -17,6 +26,12 @@ import (
 
 const (
 	configFlag                   = "config"
+	configChecksumFlag           = "config-checksum"
+	configSignerFlag             = "config-signer"
+	configSignatureURLFlag       = "config-signature-url"
+	adminSignerFlag              = "admin-signer"
+	adminThresholdFlag           = "admin-threshold"
+	configCacheFlag              = "config-cache"
 	genesisPathFlag              = "chain"
 	dataDirFlag                  = "data-dir"
 	leveldbCacheFlag             = "leveldb.cache-size"
@@ -25,6 +40,20 @@ const (
 	leveldbTableSizeFlag         = "leveldb.table-size"
 	leveldbTotalTableSizeFlag    = "leveldb.total-table-size"
 	leveldbNoSyncFlag            = "leveldb.nosync"
+	leveldbBatchSizeFlag         = "leveldb.batch-size"
+	leveldbSlowThresholdFlag     = "leveldb.slow-threshold"
+	receiptsDBFlag               = "receipts-db"
+	receiptsDBCacheFlag          = "receipts-db.cache-size"
+	receiptsDBHandlesFlag        = "receipts-db.handles"
+	cacheStateFlag               = "cache.state"
+	cacheCodeFlag                = "cache.code"
+	cachePreimagesFlag           = "cache.preimages"
+	cacheChainBudgetFlag         = "cache.chain-budget"
+	cacheChainHitRateFlag        = "cache.chain-hit-rate"
+	cacheChainMaxHeapFlag        = "cache.chain-max-heap"
+	warmupHeadersFlag            = "warmup.headers"
+	warmupReceiptsFlag           = "warmup.receipts"
+	warmupStateDepthFlag         = "warmup.state-depth"
 	libp2pAddressFlag            = "libp2p"
 	prometheusAddressFlag        = "prometheus"
 	enableIOTimerFlag            = "prometheus-io-timer"
@@ -39,6 +68,15 @@ const (
 	pruneTickSecondsFlag         = "prune-tick-seconds"
 	promoteOutdateSecondsFlag    = "promote-outdate-seconds"
 	blockGasTargetFlag           = "block-gas-target"
+	blockMaxSenderTxsFlag        = "block-max-sender-txs"
+	blockMaxSenderGasShareFlag   = "block-max-sender-gas-share"
+	blockExtraVanityFlag         = "block-extra-vanity"
+	sealLeaseFlag                = "seal-lease"
+	sealLeaseTTLFlag             = "seal-lease-ttl"
+	remoteSignerFlag             = "remote-signer"
+	downtimeMissedTurnsFlag      = "downtime-missed-turns"
+	downtimeActionFlag           = "downtime-action"
+	downtimeWebhookFlag          = "downtime-webhook"
 	secretsConfigFlag            = "secrets-config"
 	restoreFlag                  = "restore"
 	blockTimeFlag                = "block-time"
@@ -46,17 +84,53 @@ const (
 	devFlag                      = "dev"
 	corsOriginFlag               = "access-control-allow-origins"
 	daemonFlag                   = "daemon"
+	nonInteractiveFlag           = "non-interactive"
+	validatorKeyFileFlag         = "validator-key-file"
 	logFileLocationFlag          = "log-to"
 	enableGraphQLFlag            = "enable-graphql"
 	jsonRPCBatchRequestLimitFlag = "json-rpc-batch-request-limit"
 	jsonRPCBlockRangeLimitFlag   = "json-rpc-block-range-limit"
 	jsonrpcNamespaceFlag         = "json-rpc-namespace"
+	jsonRPCCacheSizeFlag         = "json-rpc-cache-size"
+	jsonRPCCacheRedisFlag        = "json-rpc-cache-redis"
+	jsonRPCSignerFlag            = "json-rpc-signer"
+	jsonRPCSignerRulesFlag       = "json-rpc-signer-rules"
+	jsonRPCRateLimitFlag         = "json-rpc-rate-limit"
+	jsonRPCRateLimitBurstFlag    = "json-rpc-rate-limit-burst"
+	jsonRPCMethodRateLimitsFlag  = "json-rpc-method-rate-limits"
+	jsonRPCTrustedProxiesFlag    = "json-rpc-trusted-proxies"
+	jsonRPCAuthFlag              = "json-rpc-auth"
+	jsonRPCTLSCertFlag           = "json-rpc-tls-cert"
+	jsonRPCTLSKeyFlag            = "json-rpc-tls-key"
+	graphqlTLSCertFlag           = "graphql-tls-cert"
+	graphqlTLSKeyFlag            = "graphql-tls-key"
+	tlsReloadIntervalFlag        = "tls-reload-interval"
 	enableWSFlag                 = "enable-ws"
+	wsMaxMessageSizeFlag         = "ws-max-message-size"
+	wsMessageRateLimitFlag       = "ws-message-rate-limit"
+	wsSendQueueSizeFlag          = "ws-send-queue-size"
+	wsDropPolicyFlag             = "ws-drop-policy"
 	blockBroadcastFlag           = "block-broadcast"
+	maxReorgDepthFlag            = "max-reorg-depth"
+	logIndexFlag                 = "log-index"
+	bloomIndexFlag               = "bloom-index"
+	chainStatsFlag               = "chain-stats"
+	systemTxIndexFlag            = "systemtx-index"
+	txLookupLimitFlag            = "txlookup-limit"
+	blockWriteQueueFlag          = "block-write-queue"
+	dbKeyLayoutFlag              = "db.key-layout"
+	receiptsBackfillFlag         = "receipts-backfill"
+	receiptsBackfillRateFlag     = "receipts-backfill-rate"
+	importMaxWriteQueueFlag      = "import-max-write-queue"
+	importMaxHeapFlag            = "import-max-heap"
+	importMaxL0TablesFlag        = "import-max-l0-tables"
 	gpoBlocksFlag                = "gpo.blocks"
 	gpoPercentileFlag            = "gpo.percentile"
 	gpoMaxGasPriceFlag           = "gpo.maxprice"
 	gpoIgnoreGasPriceFlag        = "gpo.ignoreprice"
+	gpoSmoothingFlag             = "gpo.smoothing"
+	gpoCongestionThresholdFlag   = "gpo.congestionthreshold"
+	gpoCongestionMultiplierFlag  = "gpo.congestionmultiplier"
 )
 
 const (
@@ -76,18 +150,70 @@ var (
 var (
 	errInvalidPeerParams = errors.New("both max-peers and max-inbound/outbound flags are set")
 	errInvalidNATAddress = errors.New("could not parse NAT address (ip:port)")
+
+	errValidatorKeyFileNoDaemon = errors.New("validator key file is only used in daemon mode")
+	errValidatorKeyRequired     = errors.New("validator key prompt is disabled in non-interactive mode, " +
+		"set --validator-key-file or a non-local secrets manager")
+)
+
+// Exit codes of the server command
+const (
+	exitCodeFailure     = 1 // the server failed to start or stopped with an error
+	exitCodeInvalidKey  = 2 // the validator key could not be read or parsed
+	exitCodeKeyRequired = 3 // the validator key requires a prompt in non-interactive mode
+	exitCodeDowntime    = 4 // the validator missed too many turns with the exit downtime action
 )
 
 type serverParams struct {
 	rawConfig  *Config
 	configPath string
 
-	leveldbCacheSize      int
+	// verification and caching of the remote config file
+	configChecksum     string
+	configSigner       string
+	configSignatureURL string
+	configCachePath    string
+
+	adminTokenFile string
+	adminToken     string // read from the admin token file
+	adminApproval  *server.AdminApproval
+
+	leveldbCacheSize      units.Size
 	leveldbHandles        int
 	leveldbBloomKeyBits   int
-	leveldbTableSize      int
-	leveldbTotalTableSize int
+	leveldbTableSize      units.Size
+	leveldbTotalTableSize units.Size
 	leveldbNoSync         bool
+	leveldbBatchSize      units.Size
+	leveldbSlowThreshold  units.Duration
+
+	// the separate leveldb of the receipts and transaction lookups
+	receiptsDB          bool
+	receiptsDBCacheSize units.Size
+	receiptsDBHandles   int
+
+	cacheStateSize units.Size
+	cacheCodeSize  units.Size
+
+	// adaptive sizing of the header and receipt caches
+	cacheChainBudget  units.Size
+	cacheChainHitRate float64
+	cacheChainMaxHeap units.Size
+
+	// parsed from the raw sizes and durations
+	leveldbCacheSizeMiB      int
+	leveldbTableSizeMiB      int
+	leveldbTotalTableSizeMiB int
+	leveldbBatchSizeBytes    int
+	leveldbSlowOpThreshold   time.Duration
+	receiptsDBCacheSizeMiB   int
+	cacheStateSizeBytes      int
+	cacheCodeSizeBytes       int
+	cacheChainBudgetBytes    uint64
+	cacheChainMaxHeapBytes   uint64
+	blockTime                uint64
+	pruneTickSeconds         uint64
+	promoteOutdateSeconds    uint64
 
 	libp2pAddress *net.TCPAddr
 
@@ -106,8 +232,28 @@ type serverParams struct {
 	isDaemon       bool
 	validatorKey   string
 
+	isNonInteractive bool
+	validatorKeyFile string
+
 	corsAllowedOrigins []string
 
+	// json-rpc rate limits, nil if unlimited
+	jsonRPCRateLimit      *jsonrpc.RateLimitConfig
+	jsonRPCTrustedProxies []*net.IPNet
+
+	// certificates of the json-rpc and graphql servers, nil for plain http
+	jsonRPCTLS *tlsutil.Config
+	graphqlTLS *tlsutil.Config
+
+	// lease held while sealing, nil for sealing without it
+	sealLease *lease.Config
+
+	// remote signer holding the validator key, nil for the key of the secrets manager
+	remoteSigner *signer.RemoteConfig
+
+	// watchdog of the validator downtime, nil for disabled
+	downtime *consensus.DowntimeConfig
+
 	genesisConfig *chain.Chain
 	secretsConfig *secrets.SecretsManagerConfig
 
@@ -124,6 +270,10 @@ func (p *serverParams) validateFlags() error {
 		return errInvalidPeerParams
 	}
 
+	if p.validatorKeyFile != "" && !p.isDaemon {
+		return errValidatorKeyFileNoDaemon
+	}
+
 	return nil
 }
 
@@ -144,6 +294,11 @@ func (p *serverParams) isSecretsConfigPathSet() bool {
 	return p.rawConfig.SecretsConfigPath != ""
 }
 
+// isValidatorKeyRemote returns whether the validator key is read from a non-local secrets manager
+func (p *serverParams) isValidatorKeyRemote() bool {
+	return p.secretsConfig != nil && p.secretsConfig.Type != secrets.Local
+}
+
 func (p *serverParams) isPrometheusAddressSet() bool {
 	return p.rawConfig.Telemetry.PrometheusAddr != ""
 }
@@ -168,6 +323,25 @@ func (p *serverParams) getRestoreFilePath() *string {
 	return nil
 }
 
+// getReceiptsLeveldbOptions returns the options of the separate receipts database,
+// it shares the other options with the blockchain database
+func (p *serverParams) getReceiptsLeveldbOptions() *server.LeveldbOptions {
+	if !p.receiptsDB {
+		return nil
+	}
+
+	return &server.LeveldbOptions{
+		CacheSize:           p.receiptsDBCacheSizeMiB,
+		Handles:             p.receiptsDBHandles,
+		BloomKeyBits:        p.leveldbBloomKeyBits,
+		CompactionTableSize: p.leveldbTableSizeMiB,
+		CompactionTotalSize: p.leveldbTotalTableSizeMiB,
+		NoSync:              p.leveldbNoSync,
+		IdealBatchSize:      p.leveldbBatchSizeBytes,
+		SlowOpThreshold:     p.leveldbSlowOpThreshold,
+	}
+}
+
 func (p *serverParams) setRawGRPCAddress(grpcAddress string) {
 	p.rawConfig.GRPCAddr = grpcAddress
 }
@@ -192,24 +366,7 @@ func (p *serverParams) generateConfig() *server.Config {
 	ns := strings.Split(p.rawConfig.JSONNamespace, ",")
 
 	// ignore cidr
-	cidrList := strings.Split(p.rawConfig.Network.IgnoreDiscoverCIDR, ",")
-	ingoreCIDRs := []*net.IPNet{}
-
-	for _, cidrStr := range cidrList {
-		cidrStr = strings.TrimSpace(cidrStr)
-		if cidrStr == "" {
-			continue
-		}
-
-		_, ipnet, err := net.ParseCIDR(cidrStr)
-		if err != nil {
-			log.Printf("CIDR formart error: %s \n", err)
-
-			continue
-		}
-
-		ingoreCIDRs = append(ingoreCIDRs, ipnet)
-	}
+	ingoreCIDRs := parseCIDRList(p.rawConfig.Network.IgnoreDiscoverCIDR)
 
 	return &server.Config{
 		Chain: chainCfg,
@@ -220,7 +377,19 @@ func (p *serverParams) generateConfig() *server.Config {
 			BlockRangeLimit:          p.rawConfig.JSONRPCBlockRangeLimit,
 			JSONNamespace:            ns,
 			EnableWS:                 p.rawConfig.EnableWS,
+			WSMaxMessageSize:         p.rawConfig.WSMaxMessageSize,
+			WSMessageRateLimit:       p.rawConfig.WSMessageRateLimit,
+			WSSendQueueSize:          p.rawConfig.WSSendQueueSize,
+			WSDropPolicy:             p.rawConfig.WSDropPolicy,
 			EnablePprof:              p.rawConfig.EnablePprof,
+			CacheSize:                p.rawConfig.JSONRPCCacheSize,
+			CacheRedisURL:            p.rawConfig.JSONRPCCacheRedis,
+			SignerAddr:               p.rawConfig.JSONRPCSigner,
+			SignerRulesPath:          p.rawConfig.JSONRPCSignerRules,
+			RateLimit:                p.jsonRPCRateLimit,
+			TrustedProxies:           p.jsonRPCTrustedProxies,
+			Auth:                     p.rawConfig.JSONRPCAuth,
+			TLS:                      p.jsonRPCTLS,
 		},
 		EnableGraphQL: p.rawConfig.EnableGraphQL,
 		GraphQL: &server.GraphQL{
@@ -228,6 +397,7 @@ func (p *serverParams) generateConfig() *server.Config {
 			AccessControlAllowOrigin: p.corsAllowedOrigins,
 			BlockRangeLimit:          p.rawConfig.JSONRPCBlockRangeLimit,
 			EnablePprof:              p.rawConfig.EnablePprof,
+			TLS:                      p.graphqlTLS,
 		},
 		GRPCAddr:   p.grpcAddress,
 		LibP2PAddr: p.libp2pAddress,
@@ -240,6 +410,9 @@ func (p *serverParams) generateConfig() *server.Config {
 		Network: &network.Config{
 			NoDiscover:         p.rawConfig.Network.NoDiscover,
 			DiscoverIngoreCIDR: ingoreCIDRs,
+			AllowedCIDRs:       parseCIDRList(p.rawConfig.Network.AllowCIDR),
+			DeniedCIDRs:        parseCIDRList(p.rawConfig.Network.DenyCIDR),
+			DeniedASNs:         parseStringList(p.rawConfig.Network.DenyASN),
 
 			Addr:             p.libp2pAddress,
 			NatAddr:          p.natAddress,
@@ -254,24 +427,94 @@ func (p *serverParams) generateConfig() *server.Config {
 		Seal:                  p.rawConfig.ShouldSeal,
 		PriceLimit:            p.rawConfig.TxPool.PriceLimit,
 		MaxSlots:              p.rawConfig.TxPool.MaxSlots,
-		PruneTickSeconds:      p.rawConfig.TxPool.PruneTickSeconds,
-		PromoteOutdateSeconds: p.rawConfig.TxPool.PromoteOutdateSeconds,
+		PruneTickSeconds:      p.pruneTickSeconds,
+		PromoteOutdateSeconds: p.promoteOutdateSeconds,
 		SecretsManager:        p.secretsConfig,
 		RestoreFile:           p.getRestoreFilePath(),
 		LeveldbOptions: &server.LeveldbOptions{
-			CacheSize:           p.leveldbCacheSize,
+			CacheSize:           p.leveldbCacheSizeMiB,
 			Handles:             p.leveldbHandles,
 			BloomKeyBits:        p.leveldbBloomKeyBits,
-			CompactionTableSize: p.leveldbTableSize,
-			CompactionTotalSize: p.leveldbTotalTableSize,
+			CompactionTableSize: p.leveldbTableSizeMiB,
+			CompactionTotalSize: p.leveldbTotalTableSizeMiB,
 			NoSync:              p.leveldbNoSync,
+			IdealBatchSize:      p.leveldbBatchSizeBytes,
+			SlowOpThreshold:     p.leveldbSlowOpThreshold,
+		},
+		ReceiptsLeveldbOptions: p.getReceiptsLeveldbOptions(),
+		AdminToken:             p.adminToken,
+		AdminApproval:          p.adminApproval,
+		CacheOptions: &server.CacheOptions{
+			StateSize: p.cacheStateSizeBytes,
+			CodeSize:  p.cacheCodeSizeBytes,
+			Preimages: p.rawConfig.CachePreimages,
+			Chain: blockchain.AdaptiveCacheConfig{
+				BudgetBytes:  p.cacheChainBudgetBytes,
+				MinHitRate:   p.cacheChainHitRate,
+				MaxHeapBytes: p.cacheChainMaxHeapBytes,
+			},
 		},
-		BlockTime:      p.rawConfig.BlockTime,
-		LogLevel:       hclog.LevelFromString(p.rawConfig.LogLevel),
-		LogFilePath:    p.logFileLocation,
-		Daemon:         p.isDaemon,
-		ValidatorKey:   p.validatorKey,
-		BlockBroadcast: p.rawConfig.BlockBroadcast,
-		GasPriceOracle: p.rawConfig.GPO,
+		Warmup: &server.WarmupOptions{
+			Headers:    p.rawConfig.WarmupHeaders,
+			Receipts:   p.rawConfig.WarmupReceipts,
+			StateDepth: p.rawConfig.WarmupStateDepth,
+		},
+		BlockTime:            p.blockTime,
+		LogLevel:             hclog.LevelFromString(p.rawConfig.LogLevel),
+		LogFilePath:          p.logFileLocation,
+		Daemon:               p.isDaemon,
+		ValidatorKey:         p.validatorKey,
+		BlockBroadcast:       p.rawConfig.BlockBroadcast,
+		MaxSenderTxs:         p.rawConfig.BlockMaxSenderTxs,
+		MaxSenderGasShare:    p.rawConfig.BlockMaxSenderGasShare,
+		ExtraVanity:          p.rawConfig.BlockExtraVanity,
+		SealLease:            p.sealLease,
+		RemoteSigner:         p.remoteSigner,
+		Downtime:             p.downtime,
+		MaxReorgDepth:        p.rawConfig.MaxReorgDepth,
+		EnableLogIndex:       p.rawConfig.EnableLogIndex,
+		EnableBloomIndex:     p.rawConfig.EnableBloomIndex,
+		EnableChainStats:     p.rawConfig.EnableChainStats,
+		EnableSystemTxIndex:  p.rawConfig.EnableSystemTxIndex,
+		TxLookupLimit:        p.rawConfig.TxLookupLimit,
+		BlockWriteQueue:      p.rawConfig.BlockWriteQueue,
+		DBKeyLayout:          storage.KeyLayout(p.rawConfig.DBKeyLayout),
+		ReceiptsBackfill:     p.rawConfig.ReceiptsBackfill,
+		ReceiptsBackfillRate: p.rawConfig.ReceiptsBackfillRate,
+		ImportMaxWriteQueue:  p.rawConfig.ImportMaxWriteQueue,
+		ImportMaxHeapMB:      p.rawConfig.ImportMaxHeapMB,
+		ImportMaxL0Tables:    p.rawConfig.ImportMaxL0Tables,
+		GasPriceOracle:       p.rawConfig.GPO,
+	}
+}
+
+// parseCIDRList parses the comma separated CIDR ranges, malformed ones are skipped
+func parseCIDRList(raw string) []*net.IPNet {
+	cidrs := []*net.IPNet{}
+
+	for _, cidrStr := range parseStringList(raw) {
+		_, ipnet, err := net.ParseCIDR(cidrStr)
+		if err != nil {
+			log.Printf("CIDR formart error: %s \n", err)
+
+			continue
+		}
+
+		cidrs = append(cidrs, ipnet)
 	}
+
+	return cidrs
+}
+
+// parseStringList splits the comma separated list, empty items are skipped
+func parseStringList(raw string) []string {
+	list := []string{}
+
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
 }
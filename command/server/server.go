@@ -2,6 +2,7 @@ package server
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -11,12 +12,18 @@ import (
 
 	"github.com/dogechain-lab/dogechain/command"
 	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/dogechain-lab/dogechain/consensus"
+	"github.com/dogechain-lab/dogechain/consensus/ibft"
+	"github.com/dogechain-lab/dogechain/consensus/lease"
+	"github.com/dogechain-lab/dogechain/consensus/signer"
 	"github.com/dogechain-lab/dogechain/crypto"
 	"github.com/dogechain-lab/dogechain/helper/daemon"
 	"github.com/dogechain-lab/dogechain/helper/gasprice"
 	"github.com/dogechain-lab/dogechain/helper/kvdb"
+	"github.com/dogechain-lab/dogechain/helper/units"
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/server"
+	itrie "github.com/dogechain-lab/dogechain/state/immutable-trie"
 	"github.com/dogechain-lab/dogechain/txpool"
 	"github.com/howeyc/gopass"
 	"github.com/spf13/cobra"
@@ -49,7 +56,41 @@ func setFlags(cmd *cobra.Command) {
 			&params.configPath,
 			configFlag,
 			"",
-			"the path to the CLI config. Supports .json and .hcl",
+			"the path or http(s)/s3 url to the CLI config. Supports .json, .hcl and .yaml. "+
+				"The url requires the config checksum or signer to verify it",
+		)
+
+		cmd.Flags().StringVar(
+			&params.configChecksum,
+			configChecksumFlag,
+			"",
+			"the sha256 checksum in hex the remote config file must match",
+		)
+
+		cmd.Flags().StringVar(
+			&params.configSigner,
+			configSignerFlag,
+			"",
+			"the address signing the remote config file, whose signature is fetched from the signature url. "+
+				"The signature file is a json of the positive version and the signature of "+
+				"keccak256(\"dogechain-remote-config\" || version as uint64 || keccak256(file)), "+
+				"and the versions older than the accepted one are rejected. Requires the config cache",
+		)
+
+		cmd.Flags().StringVar(
+			&params.configSignatureURL,
+			configSignatureURLFlag,
+			"",
+			"the http(s)/s3 url to the signature of the remote config file, defaults to the config url "+
+				"with .sig suffix and without the query. Required for the presigned config urls",
+		)
+
+		cmd.Flags().StringVar(
+			&params.configCachePath,
+			configCacheFlag,
+			"",
+			"the path caching the verified remote config file, used if fetching fails. "+
+				"The last signed version accepted is kept next to it",
 		)
 
 		cmd.Flags().StringVar(
@@ -72,15 +113,38 @@ func setFlags(cmd *cobra.Command) {
 			"",
 			"the path to the archive blockchain data to restore on initialization",
 		)
+
+		cmd.Flags().StringVar(
+			&params.adminTokenFile,
+			command.AdminTokenFileFlag,
+			"",
+			"the file of the token authenticating the admin operations of the GRPC interface, "+
+				"such as halting the chain. They are disabled if omitted",
+		)
+
+		cmd.Flags().StringArrayVar(
+			&params.rawConfig.AdminSigners,
+			adminSignerFlag,
+			nil,
+			"the address of an operator key approving the admin operations, repeated for each operator",
+		)
+
+		cmd.Flags().IntVar(
+			&params.rawConfig.AdminThreshold,
+			adminThresholdFlag,
+			0,
+			"the operator signatures the admin operations require on top of the admin token, "+
+				"and the json-rpc admin_removePeer and debug_chaindbCompact require (0 for none)",
+		)
 	}
 
 	// block flags
 	{
-		cmd.Flags().Uint64Var(
+		params.rawConfig.BlockTime = defaultConfig.BlockTime
+		cmd.Flags().Var(
 			&params.rawConfig.BlockTime,
 			blockTimeFlag,
-			defaultConfig.BlockTime,
-			"minimum block time in seconds (at least 1s)",
+			"minimum block time in whole seconds, like \"2s\" or a bare number of seconds (at least 1s)",
 		)
 		cmd.Flags().BoolVar(
 			&params.rawConfig.BlockBroadcast,
@@ -88,6 +152,87 @@ func setFlags(cmd *cobra.Command) {
 			false,
 			"(deprecated) enable block broadcast when syncing",
 		)
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.MaxReorgDepth,
+			maxReorgDepthFlag,
+			defaultConfig.MaxReorgDepth,
+			"the max number of canonical blocks a reorg could drop, deeper reorgs are rejected (0 for unlimited). "+
+				"The node stays on its fork until restarted with a deeper limit",
+		)
+		cmd.Flags().BoolVar(
+			&params.rawConfig.EnableLogIndex,
+			logIndexFlag,
+			false,
+			"index the log addresses and topics in background to speed up wide range log queries",
+		)
+		cmd.Flags().BoolVar(
+			&params.rawConfig.EnableBloomIndex,
+			bloomIndexFlag,
+			false,
+			"index the block blooms into bloom bits sections in background to skip sections without matching logs",
+		)
+		cmd.Flags().BoolVar(
+			&params.rawConfig.EnableChainStats,
+			chainStatsFlag,
+			false,
+			"aggregate the hourly gas used, transaction count and gas price of the blocks in background for dc_getChainStats",
+		)
+		cmd.Flags().BoolVar(
+			&params.rawConfig.EnableSystemTxIndex,
+			systemTxIndexFlag,
+			false,
+			"index the slash system transactions of the blocks in background for dc_getSystemTransactions",
+		)
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.TxLookupLimit,
+			txLookupLimitFlag,
+			defaultConfig.TxLookupLimit,
+			"the number of recent blocks to maintain transaction lookups for, older ones are deleted (0 for all blocks)",
+		)
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.BlockWriteQueue,
+			blockWriteQueueFlag,
+			0,
+			"the max number of blocks whose bodies and receipts are flushed in background, "+
+				"they are lost on a crash and synced again (0 to write synchronously)",
+		)
+		cmd.Flags().StringVar(
+			&params.rawConfig.DBKeyLayout,
+			dbKeyLayoutFlag,
+			"",
+			"the key layout of a new blockchain database (dogechain, or geth for the go-ethereum "+
+				"rawdb schema), an existing database keeps its own layout",
+		)
+		cmd.Flags().BoolVar(
+			&params.rawConfig.ReceiptsBackfill,
+			receiptsBackfillFlag,
+			false,
+			"scan the canonical blocks for missing receipts in background, and regenerate them by re-executing the blocks",
+		)
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.ReceiptsBackfillRate,
+			receiptsBackfillRateFlag,
+			defaultConfig.ReceiptsBackfillRate,
+			"the max number of blocks re-executed per second by the receipts backfill (0 for unlimited)",
+		)
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.ImportMaxWriteQueue,
+			importMaxWriteQueueFlag,
+			0,
+			"delay the import of the synced blocks while more blocks are queued to flush in background (0 to disable)",
+		)
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.ImportMaxHeapMB,
+			importMaxHeapFlag,
+			0,
+			"delay the import of the synced blocks while the allocated heap exceeds the megabytes (0 to disable)",
+		)
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.ImportMaxL0Tables,
+			importMaxL0TablesFlag,
+			defaultConfig.ImportMaxL0Tables,
+			"delay the import of the synced blocks while more level 0 tables wait for compaction (0 to disable)",
+		)
 	}
 
 	// endpoint flags
@@ -114,6 +259,144 @@ func setFlags(cmd *cobra.Command) {
 			"the flag indicating that node enable websocket service",
 		)
 
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.WSMaxMessageSize,
+			wsMaxMessageSizeFlag,
+			defaultConfig.WSMaxMessageSize,
+			"the max size in bytes of an incoming websocket message, larger ones close the connection (0 for unlimited)",
+		)
+
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.WSMessageRateLimit,
+			wsMessageRateLimitFlag,
+			defaultConfig.WSMessageRateLimit,
+			"the max number of incoming messages per second of a websocket connection (0 for unlimited)",
+		)
+
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.WSSendQueueSize,
+			wsSendQueueSizeFlag,
+			defaultConfig.WSSendQueueSize,
+			"the max number of outgoing messages queued for a websocket connection (0 for writing synchronously)",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.WSDropPolicy,
+			wsDropPolicyFlag,
+			defaultConfig.WSDropPolicy,
+			"the policy applied to the subscription notifications when the websocket send queue is full "+
+				"(drop: drop the new notifications, disconnect: close the connection)",
+		)
+
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.JSONRPCCacheSize,
+			jsonRPCCacheSizeFlag,
+			defaultConfig.JSONRPCCacheSize,
+			"the memory in MiB caching the responses of the queries by hash, "+
+				"such as eth_getBlockByHash and eth_getTransactionReceipt (0 for disabled)",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.JSONRPCCacheRedis,
+			jsonRPCCacheRedisFlag,
+			defaultConfig.JSONRPCCacheRedis,
+			"the redis url backing the json-rpc response cache, "+
+				"in the form of redis://[[username]:password@]host[:port][/db] (empty for disabled)",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.JSONRPCSigner,
+			jsonRPCSignerFlag,
+			defaultConfig.JSONRPCSigner,
+			"the http url or ipc path of the external signer (clef compatible) signing the transactions "+
+				"of eth_sendTransaction, the node stores no keys (empty for disabled)",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.JSONRPCSignerRules,
+			jsonRPCSignerRulesFlag,
+			defaultConfig.JSONRPCSignerRules,
+			"the json file of the rules approving the transactions sent to the external signer, "+
+				"with the senders, recipients, allowCreate, maxValue, maxGas and maxGasPrice",
+		)
+
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.JSONRPCRateLimit,
+			jsonRPCRateLimitFlag,
+			defaultConfig.JSONRPCRateLimit,
+			"the max number of json-rpc requests per second of a client ip (0 for unlimited)",
+		)
+
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.JSONRPCRateLimitBurst,
+			jsonRPCRateLimitBurstFlag,
+			defaultConfig.JSONRPCRateLimitBurst,
+			"the max number of json-rpc requests at once of a client ip (0 for the same as the rate limit)",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.JSONRPCMethodRateLimits,
+			jsonRPCMethodRateLimitsFlag,
+			defaultConfig.JSONRPCMethodRateLimits,
+			"the json-rpc requests per second of a client ip to the method groups, "+
+				"in the form of group=rate[:burst] separated by comma, the group is a method or a namespace "+
+				"(e.g. eth_call=20:40,debug=1)",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.JSONRPCTrustedProxies,
+			jsonRPCTrustedProxiesFlag,
+			defaultConfig.JSONRPCTrustedProxies,
+			"the ips or cidrs of the trusted proxies separated by comma, the client ip of their requests "+
+				"is taken from the X-Forwarded-For or X-Real-IP header",
+		)
+
+		cmd.Flags().BoolVar(
+			&params.rawConfig.JSONRPCAuth,
+			jsonRPCAuthFlag,
+			defaultConfig.JSONRPCAuth,
+			"the json-rpc and ws requests must carry the HS256 bearer token signed with the secret "+
+				"of the secrets manager (generated if not present), the namespaces claim of the token "+
+				"limits the permitted namespaces",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.JSONRPCTLSCert,
+			jsonRPCTLSCertFlag,
+			defaultConfig.JSONRPCTLSCert,
+			"the PEM certificate chain file terminating the TLS of the json-rpc and ws server "+
+				"(empty for plain http)",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.JSONRPCTLSKey,
+			jsonRPCTLSKeyFlag,
+			defaultConfig.JSONRPCTLSKey,
+			"the PEM private key file of the json-rpc tls certificate",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.GraphQLTLSCert,
+			graphqlTLSCertFlag,
+			defaultConfig.GraphQLTLSCert,
+			"the PEM certificate chain file terminating the TLS of the graphql server (empty for plain http)",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.GraphQLTLSKey,
+			graphqlTLSKeyFlag,
+			defaultConfig.GraphQLTLSKey,
+			"the PEM private key file of the graphql tls certificate",
+		)
+
+		params.rawConfig.TLSReloadInterval = defaultConfig.TLSReloadInterval
+		cmd.Flags().Var(
+			&params.rawConfig.TLSReloadInterval,
+			tlsReloadIntervalFlag,
+			"the interval of checking the tls certificate files, the rotated certificate is reloaded "+
+				"without restarting, like \"1m\" (0 for disabled)",
+		)
+
 		cmd.Flags().BoolVar(
 			&params.rawConfig.EnableGraphQL,
 			enableGraphQLFlag,
@@ -126,17 +409,17 @@ func setFlags(cmd *cobra.Command) {
 			jsonrpcNamespaceFlag,
 			defaultConfig.JSONNamespace,
 			"the jsonrpc endpoint namespaces should be enabled "+
-				"(eth, net, web3, txpool, debug. concatenate with commas or * for all)",
+				"(eth, net, web3, txpool, debug, dc, admin. concatenate with commas or * for all but admin)",
 		)
 	}
 
 	// leveldb flags
 	{
-		cmd.Flags().IntVar(
+		params.leveldbCacheSize = units.SizeOf(kvdb.DefaultLevelDBCache * units.MiB)
+		cmd.Flags().Var(
 			&params.leveldbCacheSize,
 			leveldbCacheFlag,
-			kvdb.DefaultLevelDBCache,
-			"the size of the leveldb cache in MB",
+			"the size of the leveldb cache, like \"1GiB\" or a bare number of MiB",
 		)
 
 		cmd.Flags().IntVar(
@@ -153,18 +436,18 @@ func setFlags(cmd *cobra.Command) {
 			"the bits of leveldb bloom filters",
 		)
 
-		cmd.Flags().IntVar(
+		params.leveldbTableSize = units.SizeOf(kvdb.DefaultLevelDBCompactionTableSize * units.MiB)
+		cmd.Flags().Var(
 			&params.leveldbTableSize,
 			leveldbTableSizeFlag,
-			kvdb.DefaultLevelDBCompactionTableSize,
-			"the leveldb 'sorted table' size in MB",
+			"the leveldb 'sorted table' size, like \"4MiB\" or a bare number of MiB",
 		)
 
-		cmd.Flags().IntVar(
+		params.leveldbTotalTableSize = units.SizeOf(kvdb.DefaultLevelDBCompactionTotalSize * units.MiB)
+		cmd.Flags().Var(
 			&params.leveldbTotalTableSize,
 			leveldbTotalTableSizeFlag,
-			kvdb.DefaultLevelDBCompactionTotalSize,
-			"limits leveldb total size of 'sorted table' for each level in MB",
+			"limits leveldb total size of 'sorted table' for each level, like \"40MiB\" or a bare number of MiB",
 		)
 
 		cmd.Flags().BoolVar(
@@ -173,6 +456,114 @@ func setFlags(cmd *cobra.Command) {
 			kvdb.DefaultLevelDBNoSync,
 			"leveldb nosync allows completely disable fsync",
 		)
+
+		params.leveldbBatchSize = units.SizeOf(kvdb.IdealBatchSize)
+		cmd.Flags().Var(
+			&params.leveldbBatchSize,
+			leveldbBatchSizeFlag,
+			"the size of the bulk blockchain writes queued before flushed to leveldb, like \"100KiB\" or a bare number of MiB",
+		)
+
+		params.leveldbSlowThreshold = units.DurationOf(kvdb.DefaultLevelDBSlowOpThreshold)
+		cmd.Flags().Var(
+			&params.leveldbSlowThreshold,
+			leveldbSlowThresholdFlag,
+			"log the leveldb operations slower than it, like \"200ms\" or a bare number of seconds (0 disables)",
+		)
+
+		cmd.Flags().BoolVar(
+			&params.receiptsDB,
+			receiptsDBFlag,
+			false,
+			"keep the receipts and transaction lookups in a separate leveldb under <data-dir>/receipts, "+
+				"it could not be turned off once the node is started with it",
+		)
+
+		params.receiptsDBCacheSize = units.SizeOf(kvdb.DefaultLevelDBCache * units.MiB)
+		cmd.Flags().Var(
+			&params.receiptsDBCacheSize,
+			receiptsDBCacheFlag,
+			"the size of the receipts leveldb cache, like \"1GiB\" or a bare number of MiB",
+		)
+
+		cmd.Flags().IntVar(
+			&params.receiptsDBHandles,
+			receiptsDBHandlesFlag,
+			kvdb.DefaultLevelDBHandles,
+			"the number of handles to receipts leveldb open files",
+		)
+	}
+
+	// cache flags
+	{
+		params.cacheStateSize = units.SizeOf(itrie.DefaultCacheSize)
+		cmd.Flags().Var(
+			&params.cacheStateSize,
+			cacheStateFlag,
+			"the size of the cached state trie nodes, like \"256MiB\" or a bare number of MiB",
+		)
+
+		params.cacheCodeSize = units.SizeOf(itrie.DefaultCodeCacheSize)
+		cmd.Flags().Var(
+			&params.cacheCodeSize,
+			cacheCodeFlag,
+			"the size of the cached contract codes, like \"64MiB\" or a bare number of MiB",
+		)
+
+		params.cacheChainBudget = units.SizeOf(0)
+		cmd.Flags().Var(
+			&params.cacheChainBudget,
+			cacheChainBudgetFlag,
+			"the memory the header and receipt caches could grow to while missing too often, "+
+				"like \"256MiB\" or a bare number of MiB (0 keeps their default sizes)",
+		)
+
+		cmd.Flags().Float64Var(
+			&params.cacheChainHitRate,
+			cacheChainHitRateFlag,
+			defaultCacheChainHitRate,
+			"the hit rate below which the full header or receipt cache grows within the budget",
+		)
+
+		params.cacheChainMaxHeap = units.SizeOf(0)
+		cmd.Flags().Var(
+			&params.cacheChainMaxHeap,
+			cacheChainMaxHeapFlag,
+			"shrink the grown header and receipt caches while the allocated heap exceeds the size (0 to disable)",
+		)
+
+		cmd.Flags().BoolVar(
+			&params.rawConfig.CachePreimages,
+			cachePreimagesFlag,
+			false,
+			"record the addresses and storage slots hashed to the state trie keys, so that the "+
+				"state is listed by address (run \"db preimages\" to record the ones of the past blocks)",
+		)
+	}
+
+	// warmup flags
+	{
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.WarmupHeaders,
+			warmupHeadersFlag,
+			0,
+			"load the last headers into the cache in background on start (0 to disable)",
+		)
+
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.WarmupReceipts,
+			warmupReceiptsFlag,
+			0,
+			"read the bodies and receipts of the last blocks in background on start (0 to disable)",
+		)
+
+		cmd.Flags().IntVar(
+			&params.rawConfig.WarmupStateDepth,
+			warmupStateDepthFlag,
+			0,
+			"load the levels of the state trie nodes under the head root into the cache in background "+
+				"on start, each level is up to 16 times the previous one (0 to disable)",
+		)
 	}
 
 	// log flags
@@ -208,6 +599,94 @@ func setFlags(cmd *cobra.Command) {
 			"the target block gas limit for the chain. If omitted, the value of the parent block is used",
 		)
 
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.BlockMaxSenderTxs,
+			blockMaxSenderTxsFlag,
+			0,
+			"the max number of transactions of one sender in a sealed block (0 for unlimited)",
+		)
+
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.BlockMaxSenderGasShare,
+			blockMaxSenderGasShareFlag,
+			0,
+			"the max percentage of the block gas limit used by the transactions of one sender "+
+				"in a sealed block (0 for unlimited)",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.BlockExtraVanity,
+			blockExtraVanityFlag,
+			"",
+			fmt.Sprintf(
+				"the vanity string embedded in the extra data of the sealed blocks (max %d bytes)",
+				ibft.IstanbulExtraVanity,
+			),
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.SealLease,
+			sealLeaseFlag,
+			defaultConfig.SealLease,
+			"the distributed lease held while sealing, in the form of consul://host:port/key "+
+				"(consuls for https, the acl token is read from CONSUL_HTTP_TOKEN). The instances sharing "+
+				"the validator key or its remote signer stay synced, and only the lease holder seals "+
+				"(empty for sealing without it)",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.RemoteSigner,
+			remoteSignerFlag,
+			defaultConfig.RemoteSigner,
+			fmt.Sprintf(
+				"the url of the remote signer holding the validator key, served by the 'signer' command, so "+
+					"that the key is not copied to the standby instances (the token is read from %s, "+
+					"empty for the key of the secrets manager)",
+				signer.TokenEnv,
+			),
+		)
+
+		params.rawConfig.SealLeaseTTL = defaultConfig.SealLeaseTTL
+		cmd.Flags().Var(
+			&params.rawConfig.SealLeaseTTL,
+			sealLeaseTTLFlag,
+			fmt.Sprintf(
+				"the time the sealing lease is kept without renewal, after which a failed holder "+
+					"is taken over by the standby (min %s)",
+				lease.MinTTL,
+			),
+		)
+
+		cmd.Flags().Uint64Var(
+			&params.rawConfig.DowntimeMissedTurns,
+			downtimeMissedTurnsFlag,
+			defaultConfig.DowntimeMissedTurns,
+			"the number of consecutive turns the sealing validator missed triggering the downtime action "+
+				"(0 for disabled)",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.DowntimeAction,
+			downtimeActionFlag,
+			defaultConfig.DowntimeAction,
+			fmt.Sprintf(
+				"the action on the validator downtime: %s (logs and posts to the webhook), "+
+					"%s (alerts, then exits with code %d for the orchestrator), or %s (stops sealing for good, "+
+					"then posts the standby activation signal with the safe height to the webhook)",
+				consensus.DowntimeActionAlert,
+				consensus.DowntimeActionExit,
+				exitCodeDowntime,
+				consensus.DowntimeActionFailover,
+			),
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.DowntimeWebhook,
+			downtimeWebhookFlag,
+			defaultConfig.DowntimeWebhook,
+			"the url the validator downtime events are posted to in json (empty for none)",
+		)
+
 		cmd.Flags().BoolVar(
 			&params.isDaemon,
 			daemonFlag,
@@ -215,6 +694,23 @@ func setFlags(cmd *cobra.Command) {
 			"the flag indicating that the server ran as daemon",
 		)
 
+		cmd.Flags().BoolVar(
+			&params.isNonInteractive,
+			nonInteractiveFlag,
+			false,
+			fmt.Sprintf(
+				"never prompt on stdin, exit with code %d instead if the validator key is required",
+				exitCodeKeyRequired,
+			),
+		)
+
+		cmd.Flags().StringVar(
+			&params.validatorKeyFile,
+			validatorKeyFileFlag,
+			"",
+			"the file of the hex encoded validator key used in daemon mode, instead of the prompt",
+		)
+
 		cmd.Flags().StringVar(
 			&params.rawConfig.SecretsConfigPath,
 			secretsConfigFlag,
@@ -233,6 +729,28 @@ func setFlags(cmd *cobra.Command) {
 			"The comma separated list of CIDR ranges to ignore when discovering peers",
 		)
 
+		cmd.Flags().StringVar(
+			&params.rawConfig.Network.AllowCIDR,
+			command.AllowCIDRFlag,
+			defaultConfig.Network.AllowCIDR,
+			"The comma separated list of CIDR ranges to only accept and dial peers from (default: all)",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.Network.DenyCIDR,
+			command.DenyCIDRFlag,
+			defaultConfig.Network.DenyCIDR,
+			"The comma separated list of CIDR ranges to reject and never dial peers from",
+		)
+
+		cmd.Flags().StringVar(
+			&params.rawConfig.Network.DenyASN,
+			command.DenyASNFlag,
+			defaultConfig.Network.DenyASN,
+			"The comma separated list of autonomous system numbers to reject and never dial peers from "+
+				"(only IPv6 addresses are resolved)",
+		)
+
 		cmd.Flags().BoolVar(
 			&params.rawConfig.Network.NoDiscover,
 			command.NoDiscoverFlag,
@@ -335,18 +853,18 @@ func setFlags(cmd *cobra.Command) {
 
 		// pruning outdated account flags
 		{
-			cmd.Flags().Uint64Var(
-				&params.rawConfig.TxPool.PruneTickSeconds,
+			params.rawConfig.TxPool.PruneTick = defaultConfig.TxPool.PruneTick
+			cmd.Flags().Var(
+				&params.rawConfig.TxPool.PruneTick,
 				pruneTickSecondsFlag,
-				txpool.DefaultPruneTickSeconds,
-				"tick seconds for pruning account future transactions in the pool",
+				"tick for pruning account future transactions in the pool, like \"5m\" or a bare number of seconds",
 			)
 
-			cmd.Flags().Uint64Var(
-				&params.rawConfig.TxPool.PromoteOutdateSeconds,
+			params.rawConfig.TxPool.PromoteOutdate = defaultConfig.TxPool.PromoteOutdate
+			cmd.Flags().Var(
+				&params.rawConfig.TxPool.PromoteOutdate,
 				promoteOutdateSecondsFlag,
-				txpool.DefaultPromoteOutdateSeconds,
-				"account in the pool not promoted for a long time would be pruned",
+				"account in the pool not promoted for a long time would be pruned, like \"1h\" or a bare number of seconds",
 			)
 		}
 	}
@@ -379,6 +897,27 @@ func setFlags(cmd *cobra.Command) {
 			gasprice.Defaults.IgnorePrice.Int64(),
 			"gas price below which gpo will ignore transactions",
 		)
+
+		cmd.Flags().IntVar(
+			&params.rawConfig.GPO.Smoothing,
+			gpoSmoothingFlag,
+			gasprice.Defaults.Smoothing,
+			"weight percentage of the latest sample in the moving average of the suggested gas price (100 to disable)",
+		)
+
+		cmd.Flags().IntVar(
+			&params.rawConfig.GPO.CongestionThreshold,
+			gpoCongestionThresholdFlag,
+			gasprice.Defaults.CongestionThreshold,
+			"average gas used percentage of recent blocks above which the suggested gas price is raised",
+		)
+
+		cmd.Flags().IntVar(
+			&params.rawConfig.GPO.CongestionMultiplier,
+			gpoCongestionMultiplierFlag,
+			gasprice.Defaults.CongestionMultiplier,
+			"percentage applied to the suggested gas price when recent blocks are full (100 to disable)",
+		)
 	}
 
 	setDevFlags(cmd)
@@ -437,18 +976,20 @@ func isConfigFileSpecified(cmd *cobra.Command) bool {
 	return cmd.Flags().Changed(configFlag)
 }
 
-func askForConfirmation() string {
+func askForConfirmation() (string, error) {
 	reader := bufio.NewReader(os.Stdin)
 
 	for {
 		privateKeyRaw, err := gopass.GetPasswdPrompt("Enter ValidatorKey:", true, os.Stdin, os.Stdout)
 		if err != nil {
-			log.Println("Parent process ", os.Getpid(), " passwd prompt err:", err)
+			return "", fmt.Errorf("failed to read validator key, %w", err)
 		}
 
 		privateKey, err := crypto.BytesToPrivateKey(privateKeyRaw)
 		if err != nil {
 			log.Println("Parent process ", os.Getpid(), " input to private key, err:", err)
+
+			continue
 		}
 
 		validatorKeyAddr := crypto.PubKeyToAddress(&privateKey.PublicKey)
@@ -460,19 +1001,69 @@ func askForConfirmation() string {
 
 		response, err := reader.ReadString('\n')
 		if err != nil {
-			log.Fatal(err)
+			return "", fmt.Errorf("failed to read confirmation, %w", err)
 		}
 
 		response = strings.ToLower(strings.TrimSpace(response))
 
 		if response == "y" || response == "yes" {
-			return string(privateKeyRaw)
+			return string(privateKeyRaw), nil
 		} else if response == "n" || response == "no" {
 			continue
 		}
 	}
 }
 
+// readValidatorKeyFile reads the hex encoded validator key from the file
+func readValidatorKeyFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read validator key file, %w", err)
+	}
+
+	key := strings.TrimSpace(string(data))
+
+	if _, err := crypto.BytesToPrivateKey([]byte(key)); err != nil {
+		return "", fmt.Errorf("invalid validator key file %s, %w", path, err)
+	}
+
+	return key, nil
+}
+
+// getDaemonValidatorKey returns the validator key of the daemon with the exit code on failure.
+// The key is read from the file if set, or prompted unless in non-interactive mode. It is
+// empty if read by a non-local secrets manager.
+func getDaemonValidatorKey() (string, int, error) {
+	switch {
+	case params.validatorKeyFile != "":
+		key, err := readValidatorKeyFile(params.validatorKeyFile)
+		if err != nil {
+			return "", exitCodeInvalidKey, err
+		}
+
+		return key, 0, nil
+	case params.isValidatorKeyRemote():
+		return "", 0, nil
+	case params.isNonInteractive:
+		return "", exitCodeKeyRequired, errValidatorKeyRequired
+	}
+
+	key, err := askForConfirmation()
+	if err != nil {
+		return "", exitCodeInvalidKey, err
+	}
+
+	return key, 0, nil
+}
+
+// exitWithError writes the error and exits with the code
+func exitWithError(outputter command.OutputFormatter, err error, code int) {
+	outputter.SetError(err)
+	outputter.WriteOutput()
+
+	os.Exit(code)
+}
+
 func runCommand(cmd *cobra.Command, _ []string) {
 	command.InitializePprofServer(cmd)
 	outputter := command.InitializeOutputter(cmd)
@@ -484,7 +1075,12 @@ func runCommand(cmd *cobra.Command, _ []string) {
 		// First time, daemonIdx is empty
 		daemonIdx := os.Getenv(daemon.EnvDaemonIdx)
 		if len(daemonIdx) == 0 {
-			params.validatorKey = askForConfirmation()
+			key, code, err := getDaemonValidatorKey()
+			if err != nil {
+				exitWithError(outputter, err, code)
+			}
+
+			params.validatorKey = key
 		} else {
 			data, err := ioutil.ReadAll(os.Stdin)
 			if err != nil {
@@ -521,10 +1117,12 @@ func runCommand(cmd *cobra.Command, _ []string) {
 	}
 
 	if err := runServerLoop(params.generateConfig(), outputter); err != nil {
-		outputter.SetError(err)
-		outputter.WriteOutput()
+		code := exitCodeFailure
+		if errors.Is(err, server.ErrValidatorDowntime) {
+			code = exitCodeDowntime
+		}
 
-		return
+		exitWithError(outputter, err, code)
 	}
 }
 
@@ -537,5 +1135,20 @@ func runServerLoop(
 		return err
 	}
 
-	return helper.HandleSignals(serverInstance.Close, outputter)
+	signalErrCh := make(chan error, 1)
+
+	go func() {
+		signalErrCh <- helper.HandleSignals(serverInstance.Close, outputter)
+	}()
+
+	select {
+	case err := <-signalErrCh:
+		return err
+	case err := <-serverInstance.Halted():
+		if closeErr := serverInstance.Close(); closeErr != nil {
+			log.Println("failed to close the halted server:", closeErr)
+		}
+
+		return err
+	}
 }
@@ -0,0 +1,118 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/consensus/signer"
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/secrets"
+	"github.com/dogechain-lab/dogechain/secrets/local"
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	dataDirFlag = "data-dir"
+	listenFlag  = "listen"
+)
+
+const defaultListenAddr = "127.0.0.1:8555"
+
+var (
+	params = &signerParams{}
+)
+
+var (
+	errMissingToken = fmt.Errorf("the token authenticating the nodes is required in %s", signer.TokenEnv)
+	errNoValidator  = errors.New("no validator key in the data directory")
+)
+
+type signerParams struct {
+	dataDir    string
+	listenAddr string
+
+	token string
+	key   *ecdsa.PrivateKey
+}
+
+func (p *signerParams) getRequiredFlags() []string {
+	return []string{
+		dataDirFlag,
+	}
+}
+
+func (p *signerParams) validateFlags() error {
+	p.token = os.Getenv(signer.TokenEnv)
+	if p.token == "" {
+		return errMissingToken
+	}
+
+	if _, _, err := net.SplitHostPort(p.listenAddr); err != nil {
+		return fmt.Errorf("invalid listen address %s: %w", p.listenAddr, err)
+	}
+
+	return nil
+}
+
+// initKey reads the validator key of the local secrets manager
+func (p *signerParams) initKey() error {
+	manager, err := local.SecretsManagerFactory(
+		nil,
+		&secrets.SecretsManagerParams{
+			Logger: hclog.NewNullLogger(),
+			Extra: map[string]interface{}{
+				secrets.Path: p.dataDir,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if !manager.HasSecret(secrets.ValidatorKey) {
+		return fmt.Errorf("%w: %s", errNoValidator, p.dataDir)
+	}
+
+	p.key, err = crypto.ReadConsensusKey(manager)
+
+	return err
+}
+
+// serve serves the key in background until the returned server is closed
+func (p *signerParams) serve() (*http.Server, error) {
+	listener, err := net.Listen("tcp", p.listenAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:  "dogechain",
+		Level: hclog.Info,
+	})
+
+	srv := &http.Server{
+		Handler:           signer.NewHandler(logger, p.key, p.token),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("remote signer stopped", "err", err)
+		}
+	}()
+
+	return srv, nil
+}
+
+func (p *signerParams) getResult() command.CommandResult {
+	return &SignerResult{
+		Address: crypto.PubKeyToAddress(&p.key.PublicKey).String(),
+		Listen:  p.listenAddr,
+	}
+}
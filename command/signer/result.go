@@ -0,0 +1,26 @@
+package signer
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/command/helper"
+)
+
+type SignerResult struct {
+	Address string `json:"address"`
+	Listen  string `json:"listen"`
+}
+
+func (r *SignerResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("\n[REMOTE SIGNER]\n")
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("Validator address|%s", r.Address),
+		fmt.Sprintf("Listening on|%s", r.Listen),
+	}))
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
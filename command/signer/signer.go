@@ -0,0 +1,69 @@
+package signer
+
+import (
+	"github.com/dogechain-lab/dogechain/command"
+	"github.com/dogechain-lab/dogechain/command/helper"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	signerCmd := &cobra.Command{
+		Use: "signer",
+		Short: "Serves the validator key to the nodes signing remotely, so that the key is not copied " +
+			"to the active and the standby instances of the validator",
+		PreRunE: runPreRun,
+		Run:     runCommand,
+	}
+
+	setFlags(signerCmd)
+	helper.SetRequiredFlags(signerCmd, params.getRequiredFlags())
+
+	return signerCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.dataDir,
+		dataDirFlag,
+		"",
+		"the data directory holding the validator key in the local secrets manager",
+	)
+
+	cmd.Flags().StringVar(
+		&params.listenAddr,
+		listenFlag,
+		defaultListenAddr,
+		"the address the remote signer listens on",
+	)
+}
+
+func runPreRun(_ *cobra.Command, _ []string) error {
+	return params.validateFlags()
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	outputter := command.InitializeOutputter(cmd)
+
+	if err := params.initKey(); err != nil {
+		outputter.SetError(err)
+		outputter.WriteOutput()
+
+		return
+	}
+
+	srv, err := params.serve()
+	if err != nil {
+		outputter.SetError(err)
+		outputter.WriteOutput()
+
+		return
+	}
+
+	outputter.SetCommandResult(params.getResult())
+	outputter.WriteOutput()
+
+	if err := helper.HandleSignals(srv.Close, outputter); err != nil {
+		outputter.SetError(err)
+		outputter.WriteOutput()
+	}
+}
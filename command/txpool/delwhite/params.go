@@ -8,6 +8,7 @@ import (
 	"github.com/dogechain-lab/dogechain/command"
 	"github.com/dogechain-lab/dogechain/command/helper"
 	"github.com/dogechain-lab/dogechain/server/proto"
+	"github.com/spf13/cobra"
 )
 
 var (
@@ -24,8 +25,13 @@ const (
 	addrFlag = "addr"
 )
 
+// whitelistDeleteMethod is the full gRPC method the operators sign
+const whitelistDeleteMethod = "/v1.System/WhitelistDeleteList"
+
 type inoutParam struct {
 	systemClient      proto.SystemClient
+	adminCtx          context.Context // carrying the admin token and the approval
+	admin             helper.AdminParams
 	contractAddresses []string
 	deletedNum        int64
 	err               error
@@ -45,27 +51,39 @@ func (p *inoutParam) validateFlags() error {
 	return nil
 }
 
-func (p *inoutParam) initSystemClient(grpcAddress string) error {
+func (p *inoutParam) initSystemClient(cmd *cobra.Command) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	systemClient, err := helper.GetSystemClientConnection(ctx, grpcAddress)
+	systemClient, err := helper.GetSystemClientConnection(ctx, helper.GetGRPCAddress(cmd))
+	if err != nil {
+		return err
+	}
+
+	adminCtx, err := p.admin.WithApproval(context.Background(), cmd)
 	if err != nil {
 		return err
 	}
 
 	p.systemClient = systemClient
+	p.adminCtx = adminCtx
 
 	return nil
 }
 
+// sign signs the request by the operator key, instead of requesting it
+func (p *inoutParam) sign(cmd *cobra.Command) (command.CommandResult, error) {
+	return p.admin.Sign(cmd, whitelistDeleteMethod, p.request())
+}
+
+func (p *inoutParam) request() *proto.WhitelistDeleteListRequest {
+	return &proto.WhitelistDeleteListRequest{
+		Contracts: p.contractAddresses,
+	}
+}
+
 func (p *inoutParam) deleteWhitelistContracts() {
-	rsp, err := p.systemClient.WhitelistDeleteList(
-		context.Background(),
-		&proto.WhitelistDeleteListRequest{
-			Contracts: p.contractAddresses,
-		},
-	)
+	rsp, err := p.systemClient.WhitelistDeleteList(p.adminCtx, p.request())
 	if err != nil {
 		p.err = err
 
@@ -27,6 +27,8 @@ func setFlags(cmd *cobra.Command) {
 		[]string{},
 		"the contract addresses need add to ddos whitelist",
 	)
+
+	helper.RegisterAdminFlags(cmd, &params.admin)
 }
 
 func runPreRunE(_ *cobra.Command, _ []string) error {
@@ -37,7 +39,20 @@ func runCommand(cmd *cobra.Command, _ []string) {
 	outputter := command.InitializeOutputter(cmd)
 	defer outputter.WriteOutput()
 
-	if err := params.initSystemClient(helper.GetGRPCAddress(cmd)); err != nil {
+	if params.admin.IsSigning() {
+		result, err := params.sign(cmd)
+		if err != nil {
+			outputter.SetError(err)
+
+			return
+		}
+
+		outputter.SetCommandResult(result)
+
+		return
+	}
+
+	if err := params.initSystemClient(cmd); err != nil {
 		outputter.SetError(err)
 
 		return
@@ -15,6 +15,7 @@ var (
 	prunedPromotedFlag = "pruned-promoted"
 	prunedEnqueuedFlag = "pruned-enqueued"
 	replacedFlag       = "replaced"
+	rejectedFlag       = "rejected"
 )
 
 type subscribeParams struct {
@@ -23,16 +24,17 @@ type subscribeParams struct {
 }
 
 func (sp *subscribeParams) initEventMap() {
-	falseRaw := false
+	// every event type owns its flag value
 	sp.eventSubscriptionMap = map[proto.EventType]*bool{
-		proto.EventType_ADDED:           &falseRaw,
-		proto.EventType_ENQUEUED:        &falseRaw,
-		proto.EventType_PROMOTED:        &falseRaw,
-		proto.EventType_DROPPED:         &falseRaw,
-		proto.EventType_DEMOTED:         &falseRaw,
-		proto.EventType_PRUNED_PROMOTED: &falseRaw,
-		proto.EventType_PRUNED_ENQUEUED: &falseRaw,
-		proto.EventType_REPLACED:        &falseRaw,
+		proto.EventType_ADDED:           new(bool),
+		proto.EventType_ENQUEUED:        new(bool),
+		proto.EventType_PROMOTED:        new(bool),
+		proto.EventType_DROPPED:         new(bool),
+		proto.EventType_DEMOTED:         new(bool),
+		proto.EventType_PRUNED_PROMOTED: new(bool),
+		proto.EventType_PRUNED_ENQUEUED: new(bool),
+		proto.EventType_REPLACED:        new(bool),
+		proto.EventType_REJECTED:        new(bool),
 	}
 }
 
@@ -68,5 +70,6 @@ func (sp *subscribeParams) setAllEvents() {
 		proto.EventType_PRUNED_PROMOTED,
 		proto.EventType_PRUNED_ENQUEUED,
 		proto.EventType_REPLACED,
+		proto.EventType_REJECTED,
 	}
 }
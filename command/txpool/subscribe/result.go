@@ -9,18 +9,34 @@ import (
 )
 
 type TxPoolEventResult struct {
-	EventType txpoolProto.EventType `json:"event_type"`
-	TxHash    string                `json:"tx_hash"`
+	EventType  txpoolProto.EventType `json:"event_type"`
+	TxHash     string                `json:"tx_hash"`
+	From       string                `json:"from,omitempty"`
+	Nonce      uint64                `json:"nonce"`
+	Reason     string                `json:"reason,omitempty"`
+	ReplacedBy string                `json:"replaced_by,omitempty"`
 }
 
 func (r *TxPoolEventResult) GetOutput() string {
 	var buffer bytes.Buffer
 
 	buffer.WriteString("\n[TXPOOL EVENT]\n")
-	buffer.WriteString(helper.FormatKV([]string{
+	output := []string{
 		fmt.Sprintf("TYPE|%s", r.EventType),
 		fmt.Sprintf("HASH|%s", r.TxHash),
-	}))
+		fmt.Sprintf("FROM|%s", r.From),
+		fmt.Sprintf("NONCE|%d", r.Nonce),
+	}
+
+	if r.Reason != "" {
+		output = append(output, fmt.Sprintf("REASON|%s", r.Reason))
+	}
+
+	if r.ReplacedBy != "" {
+		output = append(output, fmt.Sprintf("REPLACED BY|%s", r.ReplacedBy))
+	}
+
+	buffer.WriteString(helper.FormatKV(output))
 	buffer.WriteString("\n")
 
 	return buffer.String()
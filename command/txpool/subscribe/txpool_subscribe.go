@@ -76,6 +76,12 @@ func setFlags(cmd *cobra.Command) {
 		false,
 		"should subscribe to replaced tx events in the TxPool",
 	)
+	cmd.Flags().BoolVar(
+		params.eventSubscriptionMap[txpoolProto.EventType_REJECTED],
+		rejectedFlag,
+		false,
+		"should subscribe to rejected tx events in the TxPool",
+	)
 }
 
 func runCommand(cmd *cobra.Command, _ []string) {
@@ -161,8 +167,12 @@ func runSubscribeLoop(
 			}
 
 			outputter.SetCommandResult(&TxPoolEventResult{
-				EventType: streamEvent.Type,
-				TxHash:    streamEvent.TxHash,
+				EventType:  streamEvent.Type,
+				TxHash:     streamEvent.TxHash,
+				From:       streamEvent.From,
+				Nonce:      streamEvent.Nonce,
+				Reason:     streamEvent.Reason,
+				ReplacedBy: streamEvent.ReplacedBy,
 			})
 			flushOutput()
 		}
@@ -0,0 +1,85 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type YAMLOutput struct {
+	commonOutputFormatter
+}
+
+func (yo *YAMLOutput) WriteOutput() {
+	if yo.errorOutput != nil {
+		_, _ = fmt.Fprintln(os.Stderr, yo.getErrorOutput())
+
+		return
+	}
+
+	_, _ = fmt.Fprintln(os.Stdout, yo.getCommandOutput())
+}
+
+func newYAMLOutput() *YAMLOutput {
+	return &YAMLOutput{}
+}
+
+func (yo *YAMLOutput) getErrorOutput() string {
+	return marshalYAMLToString(
+		struct {
+			Err string `json:"error"`
+		}{
+			Err: yo.errorOutput.Error(),
+		},
+	)
+}
+
+func (yo *YAMLOutput) getCommandOutput() string {
+	return marshalYAMLToString(yo.commandOutput)
+}
+
+// marshalYAMLToString marshals the input with its json field names and order,
+// so that the yaml output has the same schema as the json one
+func marshalYAMLToString(input interface{}) string {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return err.Error()
+	}
+
+	// json is a subset of yaml
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err.Error()
+	}
+
+	resetYAMLStyle(&node)
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&node); err != nil {
+		return err.Error()
+	}
+
+	if err := encoder.Close(); err != nil {
+		return err.Error()
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// resetYAMLStyle drops the json flow style and quotes of the nodes, the encoder
+// still quotes the strings which would be parsed as other types
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
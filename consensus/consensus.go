@@ -6,10 +6,13 @@ import (
 
 	"github.com/dogechain-lab/dogechain/blockchain"
 	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/consensus/lease"
+	"github.com/dogechain-lab/dogechain/consensus/signer"
 	"github.com/dogechain-lab/dogechain/helper/progress"
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/secrets"
 	"github.com/dogechain-lab/dogechain/state"
+	itrie "github.com/dogechain-lab/dogechain/state/immutable-trie"
 	"github.com/dogechain-lab/dogechain/txpool"
 	"github.com/dogechain-lab/dogechain/types"
 	"github.com/hashicorp/go-hclog"
@@ -70,12 +73,39 @@ type ConsensusParams struct {
 	Network        network.Server
 	Blockchain     *blockchain.Blockchain
 	Executor       *state.Executor
+	StateDB        itrie.StateDB
 	Grpc           *grpc.Server
 	Logger         hclog.Logger
 	Metrics        *Metrics
 	SecretsManager secrets.SecretsManager
 	BlockTime      uint64
 	BlockBroadcast bool
+
+	// per sender limits of the sealed blocks, 0 for unlimited
+	MaxSenderTxs      uint64
+	MaxSenderGasShare uint64 // percentage of the block gas limit
+
+	// vanity embedded in the extra data of the sealed blocks
+	ExtraVanity []byte
+
+	// the policy on the candidate transactions of the sealed blocks, every transaction
+	// is included if nil
+	TxPolicy TxPolicy
+
+	// the lease held by the sealing instance of the validator, so that the standby
+	// instances sharing the key take over only once it is released or expired. The
+	// node seals without the lease if nil.
+	SealLease lease.Lease
+
+	// the signer of the validator key held by the remote signer, so that the key is
+	// not copied to the standby instances. The key of the secrets manager is used if nil.
+	Signer signer.Signer
+
+	// the downtime watchdog of the sealing validator, nil for disabled
+	Downtime *DowntimeConfig
+	// called once the downtime is detected, after the action of the consensus, so that
+	// the node could act on it (e.g. exit)
+	OnDowntime func(*DowntimeEvent)
 }
 
 // Factory is the factory function to create a discovery backend
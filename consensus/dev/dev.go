@@ -3,6 +3,7 @@ package dev
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/dogechain-lab/dogechain/blockchain"
@@ -23,11 +24,13 @@ const (
 type Dev struct {
 	logger hclog.Logger
 
-	notifyCh chan struct{}
-	closeCh  chan struct{}
+	notifyCh  chan struct{}
+	closeCh   chan struct{}
+	closeOnce sync.Once
 
 	interval uint64
 	txpool   *txpool.TxPool
+	txPolicy consensus.TxPolicy
 
 	blockchain *blockchain.Blockchain
 	executor   *state.Executor
@@ -46,6 +49,11 @@ func Factory(
 		blockchain: params.Blockchain,
 		executor:   params.Executor,
 		txpool:     params.Txpool,
+		txPolicy:   params.TxPolicy,
+	}
+
+	if d.txPolicy == nil {
+		d.txPolicy = consensus.NoopTxPolicy{}
 	}
 
 	rawInterval, ok := params.Config.Config["interval"]
@@ -97,6 +105,12 @@ func (d *Dev) run() {
 			return
 		}
 
+		if d.blockchain.IsHalted() {
+			d.logger.Warn("chain is halted, skip sealing")
+
+			continue
+		}
+
 		// There are new transactions in the pool, try to seal them
 		header := d.blockchain.Header()
 		if err := d.writeNewBlock(header); err != nil {
@@ -125,6 +139,13 @@ func (d *Dev) writeTransactions(gasLimit uint64, transition transitionInterface)
 			break
 		}
 
+		if verdict := d.txPolicy.Classify(tx); verdict.Skip {
+			d.logger.Debug("transaction policy skips the sender", "from", tx.From, "tag", verdict.Tag)
+			priceTxs.Pop()
+
+			continue
+		}
+
 		if tx.ExceedsBlockGasLimit(gasLimit) {
 			// The address is punished. For current loop, it would not include its transactions any more.
 			d.txpool.Drop(tx)
@@ -294,7 +315,9 @@ func (d *Dev) Seal(block *types.Block, ctx context.Context) (*types.Block, error
 }
 
 func (d *Dev) Close() error {
-	close(d.closeCh)
+	d.closeOnce.Do(func() {
+		close(d.closeCh)
+	})
 
 	return nil
 }
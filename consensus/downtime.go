@@ -0,0 +1,203 @@
+package consensus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+var ErrInvalidDowntimeAction = errors.New("invalid downtime action")
+
+// DowntimeAction is the action taken once the validator missed too many turns in a row
+type DowntimeAction string
+
+const (
+	// DowntimeActionAlert logs the downtime and notifies the webhook
+	DowntimeActionAlert DowntimeAction = "alert"
+	// DowntimeActionExit alerts, then stops the node with the downtime exit code, so
+	// that the orchestrator could restart or replace it
+	DowntimeActionExit DowntimeAction = "exit"
+	// DowntimeActionFailover stops sealing for good, then signals the standby to take
+	// over through the webhook. The standby must not sign below the safe height of
+	// the signal, which this node could have signed already.
+	DowntimeActionFailover DowntimeAction = "failover"
+)
+
+// ParseDowntimeAction returns the downtime action of the name
+func ParseDowntimeAction(name string) (DowntimeAction, error) {
+	switch action := DowntimeAction(name); action {
+	case DowntimeActionAlert, DowntimeActionExit, DowntimeActionFailover:
+		return action, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidDowntimeAction, name)
+	}
+}
+
+// DowntimeConfig is the config of the downtime watchdog of the validator
+type DowntimeConfig struct {
+	MissedTurns uint64         // consecutive missed turns triggering the action
+	Action      DowntimeAction // action taken on the downtime
+	WebhookURL  string         // url the downtime events are posted to, empty for none
+}
+
+// DowntimeEvent is the downtime of the validator, it is posted to the webhook in json
+type DowntimeEvent struct {
+	Validator   types.Address  `json:"validator"`
+	Height      uint64         `json:"height"` // the latest block of the missed turns
+	MissedTurns uint64         `json:"missedTurns"`
+	Action      DowntimeAction `json:"action"`
+	// SafeHeight is the lowest block the standby could sign, only set on failover
+	SafeHeight uint64 `json:"safeHeight,omitempty"`
+}
+
+const downtimeWebhookTimeout = 10 * time.Second
+
+// DowntimeWatchdog counts the consecutive turns the validator missed, and triggers the
+// action once the count reaches the limit. It is triggered again only after the validator
+// recovers and misses the turns once more.
+type DowntimeWatchdog struct {
+	logger  hclog.Logger
+	config  DowntimeConfig
+	metrics *Metrics
+	client  *http.Client
+
+	// handler runs the action of the event before it is posted, it could complete the
+	// event (e.g. the safe height)
+	handler func(*DowntimeEvent)
+
+	lock      sync.Mutex
+	missed    uint64
+	triggered bool
+
+	wg sync.WaitGroup // pending webhook posts
+}
+
+// NewDowntimeWatchdog returns the watchdog of the config, the handler is called with
+// the event once triggered
+func NewDowntimeWatchdog(
+	logger hclog.Logger,
+	config DowntimeConfig,
+	metrics *Metrics,
+	handler func(*DowntimeEvent),
+) *DowntimeWatchdog {
+	return &DowntimeWatchdog{
+		logger:  logger.Named("downtime"),
+		config:  config,
+		metrics: metrics,
+		client:  &http.Client{Timeout: downtimeWebhookTimeout},
+		handler: handler,
+	}
+}
+
+// RecordTurn records the block of the validator turn, sealed is false if the block was
+// sealed by another validator instead
+func (w *DowntimeWatchdog) RecordTurn(validator types.Address, height uint64, sealed bool) {
+	w.lock.Lock()
+
+	if sealed {
+		if w.missed > 0 {
+			w.logger.Info("validator recovered", "height", height, "missed", w.missed)
+		}
+
+		w.missed = 0
+		w.triggered = false
+		w.lock.Unlock()
+
+		w.metrics.SetMissedTurns(0)
+
+		return
+	}
+
+	w.missed++
+	missed := w.missed
+
+	trigger := !w.triggered && missed >= w.config.MissedTurns
+	if trigger {
+		w.triggered = true
+	}
+
+	w.lock.Unlock()
+
+	w.metrics.SetMissedTurns(float64(missed))
+	w.logger.Warn("validator missed its turn", "height", height, "missed", missed)
+
+	if !trigger {
+		return
+	}
+
+	event := &DowntimeEvent{
+		Validator:   validator,
+		Height:      height,
+		MissedTurns: missed,
+		Action:      w.config.Action,
+	}
+
+	w.logger.Error("validator downtime detected", "height", height, "missed", missed, "action", event.Action)
+
+	if w.handler != nil {
+		w.handler(event)
+	}
+
+	if w.config.WebhookURL != "" {
+		w.wg.Add(1)
+
+		go func() {
+			defer w.wg.Done()
+
+			if err := w.post(event); err != nil {
+				w.logger.Error("failed to post downtime event", "url", w.config.WebhookURL, "err", err)
+			}
+		}()
+	}
+}
+
+// MissedTurns returns the number of the consecutive missed turns
+func (w *DowntimeWatchdog) MissedTurns() uint64 {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.missed
+}
+
+// Wait waits for the pending webhook posts
+func (w *DowntimeWatchdog) Wait() {
+	w.wg.Wait()
+}
+
+func (w *DowntimeWatchdog) post(event *DowntimeEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), downtimeWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package consensus
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDowntimeAction(t *testing.T) {
+	for _, action := range []DowntimeAction{DowntimeActionAlert, DowntimeActionExit, DowntimeActionFailover} {
+		parsed, err := ParseDowntimeAction(string(action))
+		assert.NoError(t, err)
+		assert.Equal(t, action, parsed)
+	}
+
+	_, err := ParseDowntimeAction("restart")
+	assert.ErrorIs(t, err, ErrInvalidDowntimeAction)
+}
+
+func TestDowntimeWatchdog_RecordTurn(t *testing.T) {
+	var (
+		lock   sync.Mutex
+		posted []DowntimeEvent
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event DowntimeEvent
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		lock.Lock()
+		posted = append(posted, event)
+		lock.Unlock()
+	}))
+	defer srv.Close()
+
+	var handled []*DowntimeEvent
+
+	validator := types.StringToAddress("1")
+	w := NewDowntimeWatchdog(
+		hclog.NewNullLogger(),
+		DowntimeConfig{MissedTurns: 3, Action: DowntimeActionExit, WebhookURL: srv.URL},
+		NilMetrics(),
+		func(event *DowntimeEvent) {
+			handled = append(handled, event)
+		},
+	)
+
+	// recovered before the limit
+	w.RecordTurn(validator, 1, false)
+	w.RecordTurn(validator, 2, false)
+	w.RecordTurn(validator, 3, true)
+	assert.Equal(t, uint64(0), w.MissedTurns())
+
+	for n := uint64(4); n <= 7; n++ {
+		w.RecordTurn(validator, n, false)
+	}
+
+	w.Wait()
+
+	// triggered once
+	expected := DowntimeEvent{
+		Validator:   validator,
+		Height:      6,
+		MissedTurns: 3,
+		Action:      DowntimeActionExit,
+	}
+
+	assert.Equal(t, uint64(4), w.MissedTurns())
+	assert.Equal(t, []*DowntimeEvent{&expected}, handled)
+	assert.Equal(t, []DowntimeEvent{expected}, posted)
+
+	// triggered again after recovered
+	w.RecordTurn(validator, 8, true)
+
+	for n := uint64(9); n <= 11; n++ {
+		w.RecordTurn(validator, n, false)
+	}
+
+	w.Wait()
+
+	assert.Len(t, handled, 2)
+	assert.Len(t, posted, 2)
+	assert.Equal(t, uint64(11), posted[1].Height)
+}
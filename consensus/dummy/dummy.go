@@ -1,6 +1,8 @@
 package dummy
 
 import (
+	"sync"
+
 	"github.com/dogechain-lab/dogechain/blockchain"
 	"github.com/dogechain-lab/dogechain/consensus"
 	"github.com/dogechain-lab/dogechain/helper/progress"
@@ -15,6 +17,7 @@ type Dummy struct {
 	logger     hclog.Logger
 	notifyCh   chan struct{}
 	closeCh    chan struct{}
+	closeOnce  sync.Once
 	txpool     *txpool.TxPool
 	blockchain *blockchain.Blockchain
 	executor   *state.Executor
@@ -74,7 +77,9 @@ func (d *Dummy) GetSyncProgression() *progress.Progression {
 }
 
 func (d *Dummy) Close() error {
-	close(d.closeCh)
+	d.closeOnce.Do(func() {
+		close(d.closeCh)
+	})
 
 	return nil
 }
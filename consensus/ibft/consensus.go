@@ -7,6 +7,8 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/consensus"
 	"github.com/dogechain-lab/dogechain/consensus/ibft/currentstate"
 	"github.com/dogechain-lab/dogechain/consensus/ibft/proto"
 	"github.com/dogechain-lab/dogechain/types"
@@ -149,6 +151,14 @@ func (i *Ibft) runAcceptState(ctx context.Context) (shouldStop bool) { // start
 		return
 	}
 
+	if i.blockchain.IsHalted() {
+		// the blocks could not be written, neither proposed nor voted until resumed
+		logger.Warn("chain is halted, stop sealing", "sequence", number)
+		time.Sleep(1 * time.Second)
+
+		return
+	}
+
 	// update current module cache
 	if err := i.updateCurrentModules(number); err != nil {
 		logger.Error(
@@ -196,6 +206,9 @@ func (i *Ibft) runAcceptState(ctx context.Context) (shouldStop bool) { // start
 		logger.Error(fmt.Sprintf("Unable to run hook %s, %v", CalculateProposerHook, hookErr))
 	}
 
+	// the stages of the former rounds are discarded
+	i.latency.Start(number)
+
 	if i.state.Proposer() == i.validatorKeyAddr {
 		logger.Info("we are the proposer", "block", number)
 
@@ -209,6 +222,7 @@ func (i *Ibft) runAcceptState(ctx context.Context) (shouldStop bool) { // start
 				return
 			}
 
+			i.latency.Mark(number, consensus.BlockStageExecute)
 			i.state.SetBlock(block)
 
 			// calculate how much time do we have to wait to mine the block
@@ -222,6 +236,9 @@ func (i *Ibft) runAcceptState(ctx context.Context) (shouldStop bool) { // start
 			case <-i.closeCh:
 				return
 			}
+
+			// waiting for the timestamp is not a stage
+			i.latency.Skip(number)
 		}
 
 		// send the preprepare message as an RLP encoded block
@@ -284,6 +301,8 @@ func (i *Ibft) runAcceptState(ctx context.Context) (shouldStop bool) { // start
 			return
 		}
 
+		i.latency.Mark(number, consensus.BlockStageGossip)
+
 		if i.state.IsLocked() {
 			// the state is locked, we need to receive the same block
 			if block.Hash() == i.state.Block().Hash() {
@@ -302,14 +321,18 @@ func (i *Ibft) runAcceptState(ctx context.Context) (shouldStop bool) { // start
 				continue
 			}
 
+			i.latency.Mark(number, consensus.BlockStageVerify)
+
 			// Verify other block params
 			if err := i.blockchain.VerifyPotentialBlock(block); err != nil {
-				logger.Error("block verification failed", "err", err)
+				logger.Error("block verification failed", blockchain.VerifyErrorLogArgs(err)...)
 				i.handleStateErr(errBlockVerificationFailed)
 
 				continue
 			}
 
+			i.latency.Mark(number, consensus.BlockStageExecute)
+
 			if hookErr := i.runHook(VerifyBlockHook, block.Number(), block); hookErr != nil {
 				if errors.Is(hookErr, errBlockVerificationFailed) {
 					logger.Error("block verification failed, block at the end of epoch has transactions")
@@ -484,6 +507,8 @@ func (i *Ibft) runCommitState(ctx context.Context) (shouldStop bool) {
 	block := i.state.Block()
 	i.state.Unlock()
 
+	i.latency.Mark(block.Number(), consensus.BlockStageCommit)
+
 	if err := i.insertBlock(block); err != nil {
 		// start a new round with the state unlocked since we need to
 		// be able to propose/validate a different block
@@ -0,0 +1,117 @@
+package ibft
+
+import (
+	"sort"
+
+	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/consensus"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// runDowntimeWatchdog records the turns of the validator on the new canonical blocks
+func (i *Ibft) runDowntimeWatchdog() {
+	sub := i.blockchain.SubscribeEvents()
+	defer sub.Unsubscribe()
+
+	// the turns are recorded once, the reorganized blocks are skipped
+	recorded := i.blockchain.Header().Number
+
+	for {
+		select {
+		case <-i.closeCh:
+			return
+		case ev, ok := <-sub.GetEvent():
+			if !ok {
+				return
+			}
+
+			if ev == nil || ev.Type == blockchain.EventFork {
+				continue
+			}
+
+			headers := make([]*types.Header, len(ev.NewChain))
+			copy(headers, ev.NewChain)
+
+			sort.Slice(headers, func(a, b int) bool {
+				return headers[a].Number < headers[b].Number
+			})
+
+			for _, header := range headers {
+				if header.Number <= recorded {
+					continue
+				}
+
+				recorded = header.Number
+
+				i.recordTurn(header)
+			}
+		}
+	}
+}
+
+// recordTurn records the block to the watchdog if it was the turn of the validator,
+// which is the proposer of the first round
+func (i *Ibft) recordTurn(header *types.Header) {
+	if header.Number == 0 || !i.isSealing() {
+		return
+	}
+
+	parent, ok := i.blockchain.GetHeaderByNumber(header.Number - 1)
+	if !ok {
+		return
+	}
+
+	snap, err := i.getSnapshot(parent.Number)
+	if err != nil || !snap.Set.Includes(i.validatorKeyAddr) {
+		return
+	}
+
+	var lastProposer types.Address
+	if parent.Number != 0 {
+		lastProposer, _ = ecrecoverFromHeader(parent)
+	}
+
+	if snap.Set.CalcProposer(0, lastProposer) != i.validatorKeyAddr {
+		return
+	}
+
+	proposer, err := ecrecoverFromHeader(header)
+	if err != nil {
+		i.logger.Error("failed to recover the block proposer", "height", header.Number, "err", err)
+
+		return
+	}
+
+	i.downtime.RecordTurn(i.validatorKeyAddr, header.Number, proposer == i.validatorKeyAddr)
+}
+
+// handleDowntime runs the downtime action of the consensus, then hands the event over
+func (i *Ibft) handleDowntime(event *consensus.DowntimeEvent) {
+	if event.Action == consensus.DowntimeActionFailover {
+		i.stepDown()
+
+		// the stopped sequence might have signed the pending block in any round
+		event.SafeHeight = i.blockchain.Header().Number + 2
+
+		i.logger.Warn("stepped down for the standby", "safe height", event.SafeHeight)
+	}
+
+	if i.onDowntime != nil {
+		i.onDowntime(event)
+	}
+}
+
+// stepDown stops sealing for good, it returns once the running sequence is stopped,
+// so that no more messages are signed by this node. The sealing lease is released.
+func (i *Ibft) stepDown() {
+	i.sealingLock.Lock()
+
+	if !i.steppedDown {
+		i.steppedDown = true
+		close(i.steppedDownCh)
+	}
+
+	i.sealingLock.Unlock()
+
+	i.setSealing(false)
+}
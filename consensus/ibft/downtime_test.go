@@ -0,0 +1,107 @@
+package ibft
+
+import (
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/consensus"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// newDowntimeMockIbft returns the mock ibft of the validator, which is the proposer
+// of the first round of block 1 if inTurn, the validator account and the others
+func newDowntimeMockIbft(t *testing.T, inTurn bool) (*mockIbft, *testerAccount, []*testerAccount) {
+	t.Helper()
+
+	m := newMockIbft(t, []string{"A", "B", "C"}, "A")
+	m.sealing.Store(true)
+	m.sealingCh = make(chan chan struct{})
+	m.steppedDownCh = make(chan struct{})
+
+	validators := m.pool.ValidatorSet()
+	first := validators.CalcProposer(0, types.ZeroAddress)
+
+	var (
+		self   *testerAccount
+		others []*testerAccount
+	)
+
+	for _, account := range m.pool.accounts {
+		if (account.Address() == first) == inTurn && self == nil {
+			self = account
+		} else {
+			others = append(others, account)
+		}
+	}
+
+	m.validatorSigner = self.signer()
+	m.validatorKeyAddr = self.Address()
+
+	return m, self, others
+}
+
+func TestIBFT_DowntimeWatchdog(t *testing.T) {
+	m, self, others := newDowntimeMockIbft(t, true)
+
+	var events []*consensus.DowntimeEvent
+
+	m.onDowntime = func(event *consensus.DowntimeEvent) {
+		events = append(events, event)
+	}
+	m.downtime = consensus.NewDowntimeWatchdog(
+		m.logger,
+		consensus.DowntimeConfig{MissedTurns: 2, Action: consensus.DowntimeActionFailover},
+		consensus.NilMetrics(),
+		m.handleDowntime,
+	)
+
+	// the consensus loop acknowledges the step down
+	go func() {
+		doneCh := <-m.sealingCh
+		close(doneCh)
+	}()
+
+	missed := others[0].sign(m.DummyBlock().Header)
+	sealed := self.sign(m.DummyBlock().Header)
+
+	// block sealed in its turn
+	m.recordTurn(sealed)
+	assert.Equal(t, uint64(0), m.downtime.MissedTurns())
+
+	m.recordTurn(missed)
+	assert.Equal(t, uint64(1), m.downtime.MissedTurns())
+	assert.Len(t, events, 0)
+
+	// the limit is reached
+	m.recordTurn(missed)
+	assert.Equal(t, uint64(2), m.downtime.MissedTurns())
+	assert.Equal(t, []*consensus.DowntimeEvent{{
+		Validator:   self.Address(),
+		Height:      1,
+		MissedTurns: 2,
+		Action:      consensus.DowntimeActionFailover,
+		SafeHeight:  2,
+	}}, events)
+
+	// stepped down, the turns are no longer recorded
+	assert.False(t, m.isSealing())
+
+	m.recordTurn(missed)
+	assert.Equal(t, uint64(2), m.downtime.MissedTurns())
+	assert.Len(t, events, 1)
+}
+
+func TestIBFT_DowntimeWatchdog_NotInTurn(t *testing.T) {
+	m, _, others := newDowntimeMockIbft(t, false)
+	m.downtime = consensus.NewDowntimeWatchdog(
+		m.logger,
+		consensus.DowntimeConfig{MissedTurns: 1, Action: consensus.DowntimeActionAlert},
+		consensus.NilMetrics(),
+		nil,
+	)
+
+	// sealed by the proposer or not, it is not the turn of the validator
+	m.recordTurn(others[0].sign(m.DummyBlock().Header))
+	m.recordTurn(others[1].sign(m.DummyBlock().Header))
+	assert.Equal(t, uint64(0), m.downtime.MissedTurns())
+}
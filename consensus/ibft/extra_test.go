@@ -1,6 +1,7 @@
 package ibft
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 
@@ -47,3 +48,45 @@ func TestExtraEncoding(t *testing.T) {
 		}
 	}
 }
+
+func TestExtraVanity(t *testing.T) {
+	vanity := []byte("dogechain validator")
+	validators := []types.Address{
+		types.StringToAddress("1"),
+	}
+
+	h := &types.Header{
+		ExtraData: append([]byte{}, vanity...),
+	}
+
+	putIbftExtraValidators(h, validators)
+
+	expected := make([]byte, IstanbulExtraVanity)
+	copy(expected, vanity)
+
+	if !bytes.Equal(h.ExtraData[:IstanbulExtraVanity], expected) {
+		t.Fatalf("expected vanity %x, but got %x", expected, h.ExtraData[:IstanbulExtraVanity])
+	}
+
+	// the vanity is kept when the seals are written
+	if err := PutIbftExtra(h, &IstanbulExtra{
+		Validators:    validators,
+		Seal:          []byte{0x1},
+		CommittedSeal: [][]byte{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(h.ExtraData[:IstanbulExtraVanity], expected) {
+		t.Fatalf("expected vanity %x, but got %x", expected, h.ExtraData[:IstanbulExtraVanity])
+	}
+
+	extra, err := getIbftExtra(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(extra.Validators, validators) {
+		t.Fatal("bad validators")
+	}
+}
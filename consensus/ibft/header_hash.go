@@ -6,6 +6,12 @@ import (
 	"github.com/dogechain-lab/fastrlp"
 )
 
+// HeaderHash returns the hash of the istanbul header, which excludes the seals in the
+// extra data, so that the hash of a sealed block could be verified on its own
+func HeaderHash(h *types.Header) types.Hash {
+	return istanbulHeaderHash(h)
+}
+
 // istanbulHeaderHash defines the custom implementation for getting the header hash,
 // because of the extraData field
 func istanbulHeaderHash(h *types.Header) types.Hash {
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
@@ -14,6 +15,8 @@ import (
 	"github.com/dogechain-lab/dogechain/consensus/ibft/currentstate"
 	"github.com/dogechain-lab/dogechain/consensus/ibft/proto"
 	"github.com/dogechain-lab/dogechain/consensus/ibft/validator"
+	"github.com/dogechain-lab/dogechain/consensus/lease"
+	"github.com/dogechain-lab/dogechain/consensus/signer"
 	"github.com/dogechain-lab/dogechain/contracts/systemcontracts"
 	"github.com/dogechain-lab/dogechain/contracts/upgrader"
 	"github.com/dogechain-lab/dogechain/contracts/validatorset"
@@ -51,6 +54,7 @@ var (
 	ErrWrongDifficulty       = errors.New("wrong difficulty")
 	ErrInvalidBlockTimestamp = errors.New("invalid block timestamp")
 	ErrInvalidCommittedSeal  = errors.New("invalid committed seal")
+	ErrInvalidExtraVanity    = errors.New("extra vanity exceeds the istanbul vanity size")
 )
 
 type blockchainInterface interface {
@@ -60,6 +64,7 @@ type blockchainInterface interface {
 	VerifyPotentialBlock(block *types.Block) error
 	CalculateGasLimit(number uint64) (uint64, error)
 	SubscribeEvents() blockchain.Subscription
+	IsHalted() bool
 }
 
 type ddosProtectionInterface interface {
@@ -77,7 +82,7 @@ type txPoolInterface interface {
 
 // Ibft represents the IBFT consensus mechanism object
 type Ibft struct {
-	sealing bool // Flag indicating if the node is a sealer
+	sealing atomic.Bool // Flag indicating if the node is a sealer, switched by the lease
 
 	logger hclog.Logger               // Output logger
 	config *consensus.Config          // Consensus configuration
@@ -89,7 +94,7 @@ type Ibft struct {
 	closeCh    chan struct{}       // Channel for closing
 	isClosed   *atomic.Bool
 
-	validatorKey     *ecdsa.PrivateKey // Private key for the validator
+	validatorSigner  signer.Signer // Signer of the validator key, held locally or remotely
 	validatorKeyAddr types.Address
 
 	txpool txPoolInterface // Reference to the transaction pool
@@ -118,6 +123,13 @@ type Ibft struct {
 
 	blockTime time.Duration // Minimum block generation time in seconds
 
+	maxSenderTxs      uint64 // Maximum transactions of one sender in a block, 0 for unlimited
+	maxSenderGasShare uint64 // Maximum block gas limit percentage of one sender in a block, 0 for unlimited
+
+	extraVanity []byte // Vanity embedded in the extra data of the sealed blocks
+
+	txPolicy consensus.TxPolicy // Policy on the candidate transactions of the sealed blocks
+
 	currentValidators    validator.Validators // Validator set at current sequence
 	currentValidatorsMux sync.RWMutex         // Mutex for currentValidators
 	// Recording resource exhausting contracts
@@ -128,6 +140,19 @@ type Ibft struct {
 	// consensus associated
 	cancelSequence context.CancelFunc
 	wg             sync.WaitGroup
+	sealingCh      chan chan struct{} // notifies the switch of sealing, acknowledged by the loop
+
+	sealingLock   sync.Mutex    // guards switching sealing
+	steppedDown   bool          // sealing is never switched on again once stepped down
+	steppedDownCh chan struct{} // closed once stepped down
+
+	lease   lease.Lease    // lease held while sealing, nil if sealing without it
+	leaseWg sync.WaitGroup // waits for the lease to be released
+
+	latency *consensus.BlockLatency // per stage latency of the sealed blocks, nil unless debugging
+
+	downtime   *consensus.DowntimeWatchdog    // watchdog of the missed turns, nil if disabled
+	onDowntime func(*consensus.DowntimeEvent) // called after the downtime action, nil for none
 }
 
 // runHook runs a specified hook if it is present in the hook map
@@ -179,6 +204,10 @@ func Factory(
 		}
 	}
 
+	if len(params.ExtraVanity) > IstanbulExtraVanity {
+		return nil, ErrInvalidExtraVanity
+	}
+
 	p := &Ibft{
 		logger:              params.Logger.Named("ibft"),
 		config:              params.Config,
@@ -191,11 +220,33 @@ func Factory(
 		state:               &currentstate.CurrentState{},
 		network:             params.Network,
 		epochSize:           epochSize,
-		sealing:             params.Seal,
 		metrics:             params.Metrics,
 		secretsManager:      params.SecretsManager,
 		blockTime:           time.Duration(params.BlockTime) * time.Second,
+		maxSenderTxs:        params.MaxSenderTxs,
+		maxSenderGasShare:   params.MaxSenderGasShare,
+		extraVanity:         params.ExtraVanity,
+		txPolicy:            params.TxPolicy,
 		exhaustingContracts: make(map[types.Address]uint64),
+		sealingCh:           make(chan chan struct{}),
+		steppedDownCh:       make(chan struct{}),
+		onDowntime:          params.OnDowntime,
+		validatorSigner:     params.Signer,
+	}
+
+	// the standby instances seal only once the lease is acquired
+	if params.Seal {
+		p.lease = params.SealLease
+	}
+
+	p.sealing.Store(params.Seal && p.lease == nil)
+
+	if params.Seal && params.Downtime != nil {
+		p.downtime = consensus.NewDowntimeWatchdog(p.logger, *params.Downtime, p.metrics, p.handleDowntime)
+	}
+
+	if p.txPolicy == nil {
+		p.txPolicy = consensus.NoopTxPolicy{}
 	}
 
 	// set up additional timeout for building block
@@ -214,8 +265,24 @@ func Factory(
 		params.Network,
 		params.Blockchain,
 		params.BlockBroadcast,
+		params.Blockchain.Config().Checkpoint,
+		params.StateDB,
 	)
 
+	// re-fetch the corrupted trie nodes from the peers
+	if params.StateDB != nil {
+		params.StateDB.SetNodeFetcher(p.syncer)
+	}
+
+	// the latency breakdown is reported at debug level only
+	if p.logger.IsDebug() {
+		p.latency = consensus.NewBlockLatency(p.logger, p.metrics)
+
+		p.syncer.SetPublishedHook(func(number uint64) {
+			p.latency.Mark(number, consensus.BlockStageBroadcast)
+		})
+	}
+
 	return p, nil
 }
 
@@ -270,6 +337,16 @@ func (i *Ibft) Start() error {
 	// Start the actual IBFT protocol
 	go i.startConsensus()
 
+	if i.downtime != nil {
+		go i.runDowntimeWatchdog()
+	}
+
+	if i.lease != nil {
+		i.leaseWg.Add(1)
+
+		go i.runSealingLease()
+	}
+
 	return nil
 }
 
@@ -316,6 +393,10 @@ type transport interface {
 // Define the IBFT libp2p protocol
 var ibftProto = "/ibft/0.1"
 
+// ibftTopicBufferSize is the number of the buffered IBFT messages. The round messages
+// are time critical, it is larger than the other topics to not drop them in bursts.
+const ibftTopicBufferSize = 4096
+
 type gossipTransport struct {
 	topic network.Topic
 }
@@ -393,7 +474,7 @@ func (i *Ibft) setupMechanism() error {
 // setupTransport sets up the gossip transport protocol
 func (i *Ibft) setupTransport() error {
 	// Define a new topic
-	topic, err := i.network.NewTopic(ibftProto, &proto.MessageReq{})
+	topic, err := i.network.NewTopic(ibftProto, &proto.MessageReq{}, network.WithTopicBufferSize(ibftTopicBufferSize))
 	if err != nil {
 		return err
 	}
@@ -443,13 +524,14 @@ func (i *Ibft) setupTransport() error {
 	return nil
 }
 
-// createKey sets the validator's private key from the secrets manager
+// createKey sets the validator's private key from the secrets manager, unless it is
+// held by the remote signer
 func (i *Ibft) createKey() error {
 	i.msgQueue = newMsgQueue()
 	i.closeCh = make(chan struct{})
 	i.updateCh = make(chan struct{})
 
-	if i.validatorKey == nil {
+	if i.validatorSigner == nil {
 		// Check if the validator key is initialized
 		var key *ecdsa.PrivateKey
 
@@ -477,10 +559,11 @@ func (i *Ibft) createKey() error {
 			key = validatorKey
 		}
 
-		i.validatorKey = key
-		i.validatorKeyAddr = crypto.PubKeyToAddress(&key.PublicKey)
+		i.validatorSigner = signer.NewLocal(key)
 	}
 
+	i.validatorKeyAddr = i.validatorSigner.Address()
+
 	return nil
 }
 
@@ -556,6 +639,17 @@ func (i *Ibft) startConsensus() {
 				i.logger.Info("canceled sequence", "sequence", pending)
 			}
 		case <-sequenceCh:
+		case doneCh := <-i.sealingCh:
+			// sealing is switched already, the running sequence is stopped if switched
+			// off, and a new one is started on the next loop if switched on
+			if isValidator && !i.isSealing() {
+				i.stopSequence()
+				i.logger.Info("sealing stopped", "sequence", pending)
+
+				sequenceCh = make(<-chan struct{})
+			}
+
+			close(doneCh)
 		case <-i.closeCh:
 			if isValidator {
 				i.stopSequence()
@@ -655,6 +749,9 @@ func (i *Ibft) buildBlock(snap *Snapshot, parent *types.Header) (*types.Block, e
 
 	header.Timestamp = uint64(headerTime.Unix())
 
+	// copy the vanity, the validators are appended to it
+	header.ExtraData = append([]byte{}, i.extraVanity...)
+
 	// we need to include in the extra field the current set of validators
 	putIbftExtraValidators(header, snap.Set)
 
@@ -717,7 +814,7 @@ func (i *Ibft) buildBlock(snap *Snapshot, parent *types.Header) (*types.Block, e
 	})
 
 	// write the seal of the block after all the fields are completed
-	header, err = writeSeal(i.validatorKey, block.Header)
+	header, err = writeSeal(i.validatorSigner, block.Header)
 	if err != nil {
 		return nil, err
 	}
@@ -748,6 +845,71 @@ func (i *Ibft) buildBlock(snap *Snapshot, parent *types.Header) (*types.Block, e
 	return block, nil
 }
 
+// simulatedBlock is the block the node would build on top of the current head
+type simulatedBlock struct {
+	header   *types.Header
+	txs      []*types.Transaction
+	receipts []*types.Receipt
+	dropTxs  []*types.Transaction
+	resetTxs []*demoteTransaction
+}
+
+// simulateBlock runs the transaction selection against the current head and pool,
+// without sealing or committing anything. The system transactions are left out,
+// since they depend on the round state, and the pool is not touched either.
+func (i *Ibft) simulateBlock() (*simulatedBlock, error) {
+	parent := i.blockchain.Header()
+
+	header := &types.Header{
+		ParentHash: parent.Hash,
+		Number:     parent.Number + 1,
+		Miner:      i.validatorKeyAddr,
+		MixHash:    IstanbulDigest,
+		Difficulty: parent.Number + 1,
+		StateRoot:  types.EmptyRootHash,
+		Sha3Uncles: types.EmptyUncleHash,
+	}
+
+	gasLimit, err := i.blockchain.CalculateGasLimit(header.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	header.GasLimit = gasLimit
+
+	now := time.Now()
+	header.Timestamp = uint64(now.Unix())
+
+	transition, err := i.executor.BeginTxn(parent.StateRoot, header, i.validatorKeyAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	upgrader.UpgradeSystem(
+		i.config.Params.ChainID,
+		i.config.Params.Forks,
+		header.Number,
+		transition.Txn(),
+		i.logger,
+	)
+
+	block := &simulatedBlock{header: header}
+
+	if i.shouldWriteTransactions(header.Number) {
+		block.txs, block.dropTxs, block.resetTxs = i.selectTransactions(
+			gasLimit,
+			transition,
+			now.Add(i.blockTime),
+			true,
+		)
+	}
+
+	header.GasUsed = transition.TotalGas()
+	block.receipts = transition.Receipts()
+
+	return block, nil
+}
+
 func (i *Ibft) writeSystemSlashTx(
 	transition *state.Transition,
 	parent, header *types.Header,
@@ -856,12 +1018,7 @@ func (i *Ibft) makeTransitionDepositTx(
 	}
 
 	// sign tx
-	tx, err = signer.SignTx(tx, i.validatorKey)
-	if err != nil {
-		return nil, err
-	}
-
-	return tx, err
+	return i.signTx(signer, tx)
 }
 
 func (i *Ibft) makeTransitionSlashTx(
@@ -879,12 +1036,26 @@ func (i *Ibft) makeTransitionSlashTx(
 	}
 
 	// sign tx
-	tx, err = signer.SignTx(tx, i.validatorKey)
+	return i.signTx(signer, tx)
+}
+
+// signTx signs the system transaction by the validator key, which might be held by
+// the remote signer
+func (i *Ibft) signTx(txSigner crypto.TxSigner, tx *types.Transaction) (*types.Transaction, error) {
+	tx = tx.Copy()
+
+	hash := txSigner.Hash(tx)
+
+	sig, err := i.validatorSigner.Sign(hash[:])
 	if err != nil {
 		return nil, err
 	}
 
-	return tx, err
+	tx.R = new(big.Int).SetBytes(sig[:32])
+	tx.S = new(big.Int).SetBytes(sig[32:64])
+	tx.V = new(big.Int).SetBytes(txSigner.CalculateV(sig[64]))
+
+	return tx, nil
 }
 
 func (i *Ibft) isActiveValidator(addr types.Address) bool {
@@ -942,11 +1113,30 @@ func (i *Ibft) writeTransactions(
 	includedTransactions []*types.Transaction,
 	shouldDropTxs []*types.Transaction,
 	shouldDemoteTxs []*demoteTransaction,
+) {
+	return i.selectTransactions(gasLimit, transition, terminalTime, false)
+}
+
+// selectTransactions writes transactions from the txpool to the transition object.
+// The ddos protection is not touched when simulating, as no block is built, but the
+// transactions of the exhausting contracts are dropped the same as the sealer does.
+func (i *Ibft) selectTransactions(
+	gasLimit uint64,
+	transition transitionInterface,
+	terminalTime time.Time,
+	simulate bool,
+) (
+	includedTransactions []*types.Transaction,
+	shouldDropTxs []*types.Transaction,
+	shouldDemoteTxs []*demoteTransaction,
 ) {
 	// get all pending transactions once and for all
 	pendingTxs := i.txpool.Pending()
 	// get highest price transaction queue
 	priceTxs := types.NewTransactionsByPriceAndNonce(pendingTxs)
+	// transactions and gas of the senders included in the block
+	senderTxs := make(map[types.Address]uint64)
+	senderGas := make(map[types.Address]uint64)
 
 	for {
 		// terminate transaction executing once timeout
@@ -963,12 +1153,13 @@ func (i *Ibft) writeTransactions(
 			break
 		}
 
-		if i.shouldMarkLongConsumingTx(tx) {
+		exhausting := i.shouldMarkLongConsumingTx(tx)
+		if exhausting && !simulate {
 			// count attack
 			i.countDDOSAttack(tx)
 		}
 
-		if i.txpool.IsDDOSTx(tx) {
+		if exhausting || i.txpool.IsDDOSTx(tx) {
 			i.logger.Info("drop ddos attack contract transaction",
 				"address", tx.To,
 				"from", tx.From,
@@ -982,6 +1173,30 @@ func (i *Ibft) writeTransactions(
 			continue
 		}
 
+		if verdict := i.txPolicy.Classify(tx); verdict.Skip {
+			i.logger.Debug("transaction policy skips the sender",
+				"hash", tx.Hash(),
+				"from", tx.From,
+				"tag", verdict.Tag,
+			)
+			// skip the sender, its transactions are kept in the pool for the next blocks
+			priceTxs.Pop()
+
+			if !simulate {
+				i.metrics.PolicySkippedTxsInc(verdict.Tag)
+			}
+
+			continue
+		}
+
+		if i.exceedsSenderLimits(tx, gasLimit, senderTxs[tx.From], senderGas[tx.From]) {
+			i.logger.Debug("sender exceeds block limits", "from", tx.From)
+			// skip the sender, its transactions are kept in the pool for the next blocks
+			priceTxs.Pop()
+
+			continue
+		}
+
 		if tx.ExceedsBlockGasLimit(gasLimit) {
 			// the account transactions should be dropped
 			shouldDropTxs = append(shouldDropTxs, tx)
@@ -999,7 +1214,9 @@ func (i *Ibft) writeTransactions(
 
 		if err := transition.Write(tx); err != nil {
 			// mark long time consuming contract to prevent ddos attack
-			i.markLongTimeConsumingContract(tx, begin)
+			if !simulate {
+				i.markLongTimeConsumingContract(tx, begin)
+			}
 
 			i.logger.Debug("write transaction failed", "hash", tx.Hash, "from", tx.From,
 				"nonce", tx.Nonce, "err", err)
@@ -1043,9 +1260,14 @@ func (i *Ibft) writeTransactions(
 		// no errors, go on
 		priceTxs.Shift()
 		// mark long time consuming contract to prevent ddos attack
-		i.markLongTimeConsumingContract(tx, begin)
+		if !simulate {
+			i.markLongTimeConsumingContract(tx, begin)
+		}
 
 		includedTransactions = append(includedTransactions, tx)
+
+		senderTxs[tx.From]++
+		senderGas[tx.From] += tx.Gas
 	}
 
 	i.logger.Info("executed txns",
@@ -1057,6 +1279,20 @@ func (i *Ibft) writeTransactions(
 	return
 }
 
+// exceedsSenderLimits returns whether including the transaction exceeds the limits
+// of its sender, with the transactions and gas of the sender included already
+func (i *Ibft) exceedsSenderLimits(tx *types.Transaction, gasLimit, txs, gas uint64) bool {
+	if i.maxSenderTxs > 0 && txs >= i.maxSenderTxs {
+		return true
+	}
+
+	if i.maxSenderGasShare > 0 && gas+tx.Gas > gasLimit*i.maxSenderGasShare/100 {
+		return true
+	}
+
+	return false
+}
+
 func (i *Ibft) shouldTerminate(terminalTime time.Time) bool {
 	return time.Now().After(terminalTime)
 }
@@ -1217,6 +1453,8 @@ func (i *Ibft) insertBlock(block *types.Block) error {
 		return err
 	}
 
+	i.latency.Mark(block.Number(), consensus.BlockStagePersist)
+
 	if hookErr := i.runHook(InsertBlockHook, header.Number, header.Number); hookErr != nil {
 		return hookErr
 	}
@@ -1286,7 +1524,7 @@ func (i *Ibft) gossip(typ proto.MessageReq_Type) {
 
 	// if the message is commit, we need to add the committed seal
 	if msg.Type == proto.MessageReq_Commit {
-		seal, err := writeCommittedSeal(i.validatorKey, i.state.Block().Header)
+		seal, err := writeCommittedSeal(i.validatorSigner, i.state.Block().Header)
 		if err != nil {
 			i.logger.Error("failed to commit seal", "err", err)
 
@@ -1317,7 +1555,7 @@ func (i *Ibft) gossip(typ proto.MessageReq_Type) {
 		i.pushMessage(msg2)
 	}
 
-	if err := signMsg(i.validatorKey, msg); err != nil {
+	if err := signMsg(i.validatorSigner, msg); err != nil {
 		i.logger.Error("failed to sign message", "err", err)
 
 		return
@@ -1335,7 +1573,7 @@ func (i *Ibft) forceTimeout() {
 
 // isSealing checks if the current node is sealing blocks
 func (i *Ibft) isSealing() bool {
-	return i.sealing
+	return i.sealing.Load()
 }
 
 // verifyHeaderImpl implements the actual header verification logic
@@ -1492,6 +1730,14 @@ func (i *Ibft) Close() error {
 
 	close(i.closeCh)
 
+	// release the lease for the other instances
+	i.leaseWg.Wait()
+
+	// deliver the pending downtime events
+	if i.downtime != nil {
+		i.downtime.Wait()
+	}
+
 	if i.config.Path != "" {
 		err := i.store.saveToPath(i.config.Path)
 
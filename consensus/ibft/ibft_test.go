@@ -15,6 +15,7 @@ import (
 	"github.com/dogechain-lab/dogechain/consensus/ibft/currentstate"
 	"github.com/dogechain-lab/dogechain/consensus/ibft/proto"
 	"github.com/dogechain-lab/dogechain/consensus/ibft/validator"
+	"github.com/dogechain-lab/dogechain/consensus/signer"
 	"github.com/dogechain-lab/dogechain/helper/common"
 	"github.com/dogechain-lab/dogechain/helper/hex"
 	"github.com/dogechain-lab/dogechain/state"
@@ -151,7 +152,7 @@ func (m *MockBlockchain) MockBlock(
 
 	header = header.ComputeHash()
 
-	header, err = writeSeal(proposer, header)
+	header, err = writeSeal(signer.NewLocal(proposer), header)
 	if err != nil {
 		m.t.Errorf("failed to write seal in DummyBlock: %v", err)
 	}
@@ -208,6 +209,10 @@ func (m *MockBlockchain) SubscribeEvents() blockchain.Subscription {
 	return m.subscription
 }
 
+func (m *MockBlockchain) IsHalted() bool {
+	return false
+}
+
 // interface check
 var _ blockchainInterface = (*MockBlockchain)(nil)
 
@@ -350,13 +355,38 @@ func TestTransition_AcceptState_Proposer_Locked(t *testing.T) {
 	}
 }
 
+func TestTransition_AcceptState_Halted(t *testing.T) {
+	// the locked block is not proposed while the chain is halted
+	i := newMockIbft(t, []string{"A", "B", "C", "D"}, "A")
+	i.setState(currentstate.AcceptState)
+
+	i.state.Lock()
+	i.state.SetBlock(&types.Block{
+		Header: &types.Header{
+			Number: 10,
+		},
+	})
+
+	//nolint:forcetypeassert
+	_, _, err := i.blockchain.(*blockchain.Blockchain).Halt("tester", "test")
+	assert.NoError(t, err)
+
+	i.runCycle(context.Background())
+
+	i.expect(expectResult{
+		sequence: 1,
+		state:    currentstate.AcceptState,
+		locked:   true,
+	})
+}
+
 func TestTransition_AcceptState_Validator_VerifyCorrect(t *testing.T) {
 	i := newMockIbft(t, []string{"A", "B", "C"}, "B")
 	i.state.SetView(proto.ViewMsg(1, 0))
 	i.setState(currentstate.AcceptState)
 
 	block := i.DummyBlock()
-	header, err := writeSeal(i.pool.get("A").priv, block.Header)
+	header, err := writeSeal(i.pool.get("A").signer(), block.Header)
 
 	assert.NoError(t, err)
 
@@ -389,7 +419,7 @@ func TestTransition_AcceptState_Validator_VerifyFails(t *testing.T) {
 	block := i.DummyBlock()
 	block.Header.MixHash = types.Hash{} // invalidates the block
 
-	header, err := writeSeal(i.pool.get("A").priv, block.Header)
+	header, err := writeSeal(i.pool.get("A").signer(), block.Header)
 
 	assert.NoError(t, err)
 
@@ -874,6 +904,133 @@ func TestIBFT_WriteTransactions(t *testing.T) {
 	}
 }
 
+func TestIBFT_WriteTransactions_SenderLimits(t *testing.T) {
+	var (
+		addrA = types.StringToAddress("A")
+		addrB = types.StringToAddress("B")
+	)
+
+	newTxs := func() []*types.Transaction {
+		txs := make([]*types.Transaction, 0, 8)
+
+		for nonce := uint64(0); nonce < 4; nonce++ {
+			txs = append(txs,
+				&types.Transaction{From: addrA, Nonce: nonce, Gas: 100, GasPrice: big.NewInt(2)},
+				&types.Transaction{From: addrB, Nonce: nonce, Gas: 100, GasPrice: big.NewInt(1)},
+			)
+		}
+
+		return txs
+	}
+
+	testCases := []struct {
+		description       string
+		maxSenderTxs      uint64
+		maxSenderGasShare uint64
+		expectedIncluded  int
+	}{
+		{"no limits", 0, 0, 8},
+		{"max transactions per sender", 2, 0, 4},
+		{"max gas share per sender", 0, 30, 6},
+		{"both limits", 2, 10, 2},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			m := newMockIbft(t, []string{"A", "B", "C"}, "A")
+			m.txpool = newMockTxPool(newTxs())
+			m.maxSenderTxs = test.maxSenderTxs
+			m.maxSenderGasShare = test.maxSenderGasShare
+
+			endTime := time.Now().Add(time.Second)
+			included, shouldDropTxs, shouldDemoteTxs := m.writeTransactions(1000, &mockTransition{}, endTime)
+
+			assert.Equal(t, test.expectedIncluded, len(included))
+			// the skipped transactions are kept in the pool
+			assert.Empty(t, shouldDropTxs)
+			assert.Empty(t, shouldDemoteTxs)
+		})
+	}
+}
+
+// txPolicyFunc is the transaction policy of the function
+type txPolicyFunc func(tx *types.Transaction) consensus.TxPolicyVerdict
+
+func (f txPolicyFunc) Classify(tx *types.Transaction) consensus.TxPolicyVerdict {
+	return f(tx)
+}
+
+func TestIBFT_WriteTransactions_TxPolicy(t *testing.T) {
+	var (
+		addrA = types.StringToAddress("A")
+		addrB = types.StringToAddress("B")
+	)
+
+	txs := make([]*types.Transaction, 0, 4)
+
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		txs = append(txs,
+			&types.Transaction{From: addrA, Nonce: nonce, Gas: 100, GasPrice: big.NewInt(2)},
+			&types.Transaction{From: addrB, Nonce: nonce, Gas: 100, GasPrice: big.NewInt(1)},
+		)
+	}
+
+	classified := 0
+
+	m := newMockIbft(t, []string{"A", "B", "C"}, "A")
+	m.txpool = newMockTxPool(txs)
+	m.txPolicy = txPolicyFunc(func(tx *types.Transaction) consensus.TxPolicyVerdict {
+		classified++
+
+		return consensus.TxPolicyVerdict{Skip: tx.From == addrB, Tag: "sanctioned"}
+	})
+
+	endTime := time.Now().Add(time.Second)
+	included, shouldDropTxs, shouldDemoteTxs := m.writeTransactions(1000, &mockTransition{}, endTime)
+
+	// the later transactions of the skipped sender are not classified
+	assert.Equal(t, 3, classified)
+	assert.Len(t, included, 2)
+
+	for _, tx := range included {
+		assert.Equal(t, addrA, tx.From)
+	}
+
+	// the skipped transactions are kept in the pool
+	assert.Empty(t, shouldDropTxs)
+	assert.Empty(t, shouldDemoteTxs)
+}
+
+func TestIBFT_SelectTransactions_Simulate(t *testing.T) {
+	newTx := func() *types.Transaction {
+		return &types.Transaction{From: addr1, To: &addr2, Gas: 100, GasPrice: big.NewInt(1)}
+	}
+
+	testCases := []struct {
+		description string
+		simulate    bool
+	}{
+		{"building a block counts the ddos attack", false},
+		{"simulating a block drops the transaction but leaves the ddos protection alone", true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			m := newMockIbft(t, []string{"A", "B", "C"}, "A")
+			mockTxPool := newMockTxPool([]*types.Transaction{newTx()})
+			m.txpool = mockTxPool
+			m.exhaustingContracts[addr2] = _annoyingContractThrshold
+
+			endTime := time.Now().Add(time.Second)
+			included, shouldDropTxs, _ := m.selectTransactions(1000, &mockTransition{}, endTime, test.simulate)
+
+			assert.Equal(t, 0, len(included))
+			assert.Equal(t, 1, len(shouldDropTxs))
+			assert.Equal(t, !test.simulate, mockTxPool.IsDDOSTx(newTx()))
+		})
+	}
+}
+
 type mockTxPool struct {
 	transactions          []*types.Transaction
 	demoted               []*types.Transaction
@@ -1026,6 +1183,10 @@ func (m *mockIbft) SubscribeEvents() blockchain.Subscription {
 	return m.blockchain.SubscribeEvents()
 }
 
+func (m *mockIbft) IsHalted() bool {
+	return m.blockchain.IsHalted()
+}
+
 func (m *mockIbft) emitMsg(msg *proto.MessageReq) {
 	// convert the address from the address pool
 	from := m.pool.get(msg.From).Address()
@@ -1085,7 +1246,7 @@ func newMockIbft(t *testing.T, accounts []string, validatorAccount string) *mock
 			},
 		},
 		blockchain:          m,
-		validatorKey:        addr.priv,
+		validatorSigner:     addr.signer(),
 		validatorKeyAddr:    addr.Address(),
 		closeCh:             make(chan struct{}),
 		isClosed:            atomic.NewBool(false),
@@ -1094,6 +1255,7 @@ func newMockIbft(t *testing.T, accounts []string, validatorAccount string) *mock
 		state:               currentstate.NewState(),
 		epochSize:           DefaultEpochSize,
 		metrics:             consensus.NilMetrics(),
+		txPolicy:            consensus.NoopTxPolicy{},
 		exhaustingContracts: make(map[types.Address]uint64),
 	}
 
@@ -1150,7 +1312,7 @@ func newMockIBFTWithMockBlockchain(
 			},
 		},
 		blockchain:       m,
-		validatorKey:     addr.priv,
+		validatorSigner:  addr.signer(),
 		validatorKeyAddr: addr.Address(),
 		closeCh:          make(chan struct{}),
 		isClosed:         atomic.NewBool(false),
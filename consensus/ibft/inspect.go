@@ -0,0 +1,58 @@
+package ibft
+
+import (
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// HeaderSeals is the istanbul extra of the header, with the signers recovered from
+// the seals
+type HeaderSeals struct {
+	// Hash is the istanbul hash of the header, which is the block hash and the
+	// message signed by the proposer
+	Hash types.Hash
+
+	Vanity     []byte
+	Validators []types.Address
+
+	Seal     []byte
+	Proposer types.Address // zero if not recovered
+
+	CommittedSeals [][]byte
+	Committers     []types.Address // zero if not recovered
+}
+
+// InspectSeals decodes the istanbul extra of the header, and recovers the proposer
+// and the committers of the seals. The seals failing to recover are kept with zero
+// signers, so that the broken headers could be inspected too.
+func InspectSeals(h *types.Header) (*HeaderSeals, error) {
+	extra, err := getIbftExtra(h)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := calculateHeaderHash(h)
+	if err != nil {
+		return nil, err
+	}
+
+	seals := &HeaderSeals{
+		Hash:           types.BytesToHash(hash),
+		Vanity:         h.ExtraData[:IstanbulExtraVanity],
+		Validators:     extra.Validators,
+		Seal:           extra.Seal,
+		CommittedSeals: extra.CommittedSeal,
+		Committers:     make([]types.Address, len(extra.CommittedSeal)),
+	}
+
+	if len(extra.Seal) > 0 {
+		seals.Proposer, _ = ecrecoverImpl(extra.Seal, hash)
+	}
+
+	rawMsg := commitMsg(hash)
+
+	for i, seal := range extra.CommittedSeal {
+		seals.Committers[i], _ = ecrecoverImpl(seal, rawMsg)
+	}
+
+	return seals, nil
+}
@@ -0,0 +1,58 @@
+package ibft
+
+import (
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspectSeals(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A", "B", "C")
+
+	h := &types.Header{Number: 1}
+	putIbftExtraValidators(h, pool.ValidatorSet())
+
+	// not sealed yet
+	seals, err := InspectSeals(h)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Address(pool.ValidatorSet()), seals.Validators)
+	assert.Equal(t, types.ZeroAddress, seals.Proposer)
+	assert.Empty(t, seals.Committers)
+
+	h, err = writeSeal(pool.get("A").signer(), h)
+	assert.NoError(t, err)
+
+	committedSeals := [][]byte{}
+
+	for _, name := range []string{"B", "C"} {
+		seal, err := writeCommittedSeal(pool.get(name).signer(), h)
+		assert.NoError(t, err)
+
+		committedSeals = append(committedSeals, seal)
+	}
+
+	// a broken seal is kept
+	committedSeals = append(committedSeals, make([]byte, IstanbulExtraSeal))
+
+	h, err = writeCommittedSeals(h, committedSeals)
+	assert.NoError(t, err)
+
+	seals, err = InspectSeals(h)
+	assert.NoError(t, err)
+
+	// the seals are not part of the hash
+	assert.Equal(t, istanbulHeaderHash(h), seals.Hash)
+	assert.Equal(t, pool.get("A").Address(), seals.Proposer)
+	assert.Equal(t, []types.Address{
+		pool.get("B").Address(),
+		pool.get("C").Address(),
+		types.ZeroAddress,
+	}, seals.Committers)
+	assert.Len(t, seals.CommittedSeals, 3)
+
+	// not an istanbul header
+	_, err = InspectSeals(&types.Header{})
+	assert.Error(t, err)
+}
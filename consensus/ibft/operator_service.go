@@ -28,6 +28,51 @@ func (o *operator) Status(ctx context.Context, req *empty.Empty) (*proto.IbftSta
 	return resp, nil
 }
 
+// Simulate returns the block the node would build on top of the current head,
+// nothing is sealed or written
+func (o *operator) Simulate(ctx context.Context, req *empty.Empty) (*proto.SimulateResp, error) {
+	block, err := o.ibft.simulateBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	gasUsed := make(map[types.Hash]uint64, len(block.receipts))
+	for _, receipt := range block.receipts {
+		gasUsed[receipt.TxHash] = receipt.GasUsed
+	}
+
+	resp := &proto.SimulateResp{
+		Number:     block.header.Number,
+		ParentHash: block.header.ParentHash.String(),
+		GasLimit:   block.header.GasLimit,
+		GasUsed:    block.header.GasUsed,
+		Txns:       make([]*proto.SimulateResp_Txn, 0, len(block.txs)),
+		Dropped:    make([]string, 0, len(block.dropTxs)),
+		Demoted:    make([]string, 0, len(block.resetTxs)),
+	}
+
+	for _, tx := range block.txs {
+		resp.Txns = append(resp.Txns, &proto.SimulateResp_Txn{
+			Hash:     tx.Hash().String(),
+			From:     tx.From.String(),
+			Nonce:    tx.Nonce,
+			GasPrice: tx.GasPrice.String(),
+			Gas:      tx.Gas,
+			GasUsed:  gasUsed[tx.Hash()],
+		})
+	}
+
+	for _, tx := range block.dropTxs {
+		resp.Dropped = append(resp.Dropped, tx.Hash().String())
+	}
+
+	for _, tx := range block.resetTxs {
+		resp.Demoted = append(resp.Demoted, tx.Tx.Hash().String())
+	}
+
+	return resp, nil
+}
+
 // getNextCandidate returns a candidate from the snapshot
 func (o *operator) getNextCandidate(snap *Snapshot) *proto.Candidate {
 	o.candidatesLock.Lock()
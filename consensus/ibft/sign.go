@@ -1,11 +1,11 @@
 package ibft
 
 import (
-	"crypto/ecdsa"
 	"fmt"
 
 	"github.com/dogechain-lab/dogechain/consensus/ibft/proto"
 	"github.com/dogechain-lab/dogechain/consensus/ibft/validator"
+	"github.com/dogechain-lab/dogechain/consensus/signer"
 	"github.com/dogechain-lab/dogechain/crypto"
 	"github.com/dogechain-lab/dogechain/helper/hex"
 	"github.com/dogechain-lab/dogechain/helper/keccak"
@@ -43,7 +43,7 @@ func ecrecoverFromHeader(h *types.Header) (types.Address, error) {
 	return ecrecoverImpl(extra.Seal, msg)
 }
 
-func signSealImpl(prv *ecdsa.PrivateKey, h *types.Header, committed bool) ([]byte, error) {
+func signSealImpl(s signer.Signer, h *types.Header, committed bool) ([]byte, error) {
 	hash, err := calculateHeaderHash(h)
 	if err != nil {
 		return nil, err
@@ -55,7 +55,7 @@ func signSealImpl(prv *ecdsa.PrivateKey, h *types.Header, committed bool) ([]byt
 		msg = commitMsg(hash)
 	}
 
-	seal, err := crypto.Sign(prv, crypto.Keccak256(msg))
+	seal, err := s.Sign(crypto.Keccak256(msg))
 
 	if err != nil {
 		return nil, err
@@ -64,9 +64,9 @@ func signSealImpl(prv *ecdsa.PrivateKey, h *types.Header, committed bool) ([]byt
 	return seal, nil
 }
 
-func writeSeal(prv *ecdsa.PrivateKey, h *types.Header) (*types.Header, error) {
+func writeSeal(s signer.Signer, h *types.Header) (*types.Header, error) {
 	h = h.Copy()
-	seal, err := signSealImpl(prv, h, false)
+	seal, err := signSealImpl(s, h, false)
 
 	if err != nil {
 		return nil, err
@@ -97,8 +97,8 @@ func committedSealFromHex(hexSeal string, rawHash types.Hash) (seal []byte, addr
 	return
 }
 
-func writeCommittedSeal(prv *ecdsa.PrivateKey, h *types.Header) ([]byte, error) {
-	return signSealImpl(prv, h, true)
+func writeCommittedSeal(s signer.Signer, h *types.Header) ([]byte, error) {
+	return signSealImpl(s, h, true)
 }
 
 func writeCommittedSeals(h *types.Header, seals [][]byte) (*types.Header, error) {
@@ -248,13 +248,13 @@ func validateMsg(msg *proto.MessageReq) error {
 	return nil
 }
 
-func signMsg(key *ecdsa.PrivateKey, msg *proto.MessageReq) error {
+func signMsg(s signer.Signer, msg *proto.MessageReq) error {
 	signMsg, err := msg.PayloadNoSig()
 	if err != nil {
 		return err
 	}
 
-	sig, err := crypto.Sign(key, crypto.Keccak256(signMsg))
+	sig, err := s.Sign(crypto.Keccak256(signMsg))
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package ibft
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/dogechain-lab/dogechain/consensus/ibft/proto"
@@ -23,11 +24,11 @@ func TestSign_Sealer(t *testing.T) {
 	// non-validator address
 	pool.add("X")
 
-	badSealedBlock, _ := writeSeal(pool.get("X").priv, h)
+	badSealedBlock, _ := writeSeal(pool.get("X").signer(), h)
 	assert.Error(t, verifySigner(snap, badSealedBlock))
 
 	// seal the block with a validator
-	goodSealedBlock, _ := writeSeal(pool.get("A").priv, h)
+	goodSealedBlock, _ := writeSeal(pool.get("A").signer(), h)
 	assert.NoError(t, verifySigner(snap, goodSealedBlock))
 }
 
@@ -49,7 +50,7 @@ func TestSign_CommittedSeals(t *testing.T) {
 		seals := [][]byte{}
 
 		for _, accnt := range accnt {
-			seal, err := writeCommittedSeal(pool.get(accnt).priv, h)
+			seal, err := writeCommittedSeal(pool.get(accnt).signer(), h)
 
 			assert.NoError(t, err)
 
@@ -90,8 +91,37 @@ func TestSign_Messages(t *testing.T) {
 	msg := &proto.MessageReq{
 		Type: proto.MessageReq_RoundChange,
 	}
-	assert.NoError(t, signMsg(pool.get("A").priv, msg))
+	assert.NoError(t, signMsg(pool.get("A").signer(), msg))
 	assert.NoError(t, validateMsg(msg))
 
 	assert.Equal(t, msg.From, pool.get("A").Address().String())
 }
+
+func TestSign_SystemTx(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A")
+
+	i := &Ibft{validatorSigner: pool.get("A").signer()}
+
+	txSigner := crypto.NewEIP155Signer(100)
+	to := types.StringToAddress("1")
+
+	tx, err := i.signTx(txSigner, &types.Transaction{
+		To:       &to,
+		Value:    big.NewInt(0),
+		GasPrice: big.NewInt(0),
+		Gas:      100000,
+	})
+	assert.NoError(t, err)
+
+	// the same transaction as signed by the key
+	expected, err := txSigner.SignTx(tx, pool.get("A").priv)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.V, tx.V)
+	assert.Equal(t, expected.R, tx.R)
+	assert.Equal(t, expected.S, tx.S)
+
+	sender, err := txSigner.Sender(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, pool.get("A").Address(), sender)
+}
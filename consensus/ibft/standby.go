@@ -0,0 +1,114 @@
+package ibft
+
+import (
+	"context"
+)
+
+// setSealing switches sealing on or off, it is never switched on again once stepped
+// down. It returns once the running sequence is stopped if switched off, so that no
+// more messages are signed by this node.
+func (i *Ibft) setSealing(sealing bool) {
+	i.sealingLock.Lock()
+	defer i.sealingLock.Unlock()
+
+	if (sealing && i.steppedDown) || !i.sealing.CAS(!sealing, sealing) {
+		return
+	}
+
+	doneCh := make(chan struct{})
+
+	select {
+	case i.sealingCh <- doneCh:
+	case <-i.closeCh:
+		return
+	}
+
+	select {
+	case <-doneCh:
+	case <-i.closeCh:
+	}
+}
+
+// runSealingLease seals only while holding the lease, so that the instances sharing
+// the validator key never sign concurrently. The standby instances stay synced, and
+// take over once the lease of the active one is released or expired. The instances
+// either hold a copy of the validator key, or sign through the remote signer holding it.
+func (i *Ibft) runSealingLease() {
+	defer i.leaseWg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-i.closeCh:
+		case <-i.steppedDownCh:
+		}
+
+		cancel()
+	}()
+
+	defer func() {
+		if err := i.lease.Release(); err != nil {
+			i.logger.Error("failed to release the sealing lease", "err", err)
+		}
+	}()
+
+	for {
+		i.logger.Info("standing by for the sealing lease")
+
+		lostCh, err := i.lease.Acquire(ctx)
+		if err != nil {
+			// closed or stepped down
+			return
+		}
+
+		// the previous holder might have signed the pending block in any round before
+		// its lease was lost, so the takeover starts from the block after it
+		safeHeight := i.blockchain.Header().Number + 2
+
+		i.logger.Info("sealing lease acquired, waiting for the safe height", "safe height", safeHeight)
+
+		if !i.awaitSafeHeight(ctx, lostCh, safeHeight) {
+			if ctx.Err() != nil {
+				return
+			}
+
+			i.logger.Warn("sealing lease lost before sealing started")
+
+			continue
+		}
+
+		i.setSealing(true)
+		i.logger.Info("sealing started", "height", i.blockchain.Header().Number+1)
+
+		select {
+		case <-lostCh:
+			i.setSealing(false)
+			i.logger.Warn("sealing lease lost, sealing stopped")
+		case <-ctx.Done():
+			i.setSealing(false)
+
+			return
+		}
+	}
+}
+
+// awaitSafeHeight waits for the chain to reach the safe height to sign, it returns
+// false if the lease is lost or the context is done meanwhile
+func (i *Ibft) awaitSafeHeight(ctx context.Context, lostCh <-chan struct{}, safeHeight uint64) bool {
+	sub := i.blockchain.SubscribeEvents()
+	defer sub.Unsubscribe()
+
+	for i.blockchain.Header().Number+1 < safeHeight {
+		select {
+		case <-sub.GetEvent():
+		case <-lostCh:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,148 @@
+package ibft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockLease is the lease granted by the test
+type mockLease struct {
+	grantCh   chan chan struct{} // grants the lease with the lost channel
+	releaseCh chan struct{}
+}
+
+func (l *mockLease) Acquire(ctx context.Context) (<-chan struct{}, error) {
+	select {
+	case lostCh := <-l.grantCh:
+		return lostCh, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *mockLease) Release() error {
+	close(l.releaseCh)
+
+	return nil
+}
+
+func TestIBFT_SealingLease(t *testing.T) {
+	m, _, _ := newDowntimeMockIbft(t, true)
+	m.sealing.Store(false)
+
+	lease := &mockLease{
+		grantCh:   make(chan chan struct{}),
+		releaseCh: make(chan struct{}),
+	}
+	m.lease = lease
+
+	// the consensus loop acknowledges the switches
+	switches := make(chan bool, 4)
+
+	go func() {
+		for {
+			select {
+			case doneCh := <-m.sealingCh:
+				switches <- m.isSealing()
+
+				close(doneCh)
+			case <-m.closeCh:
+				return
+			}
+		}
+	}()
+
+	m.leaseWg.Add(1)
+
+	go m.runSealingLease()
+
+	expectSwitch := func(sealing bool) {
+		t.Helper()
+
+		select {
+		case s := <-switches:
+			assert.Equal(t, sealing, s)
+		case <-time.After(5 * time.Second):
+			t.Fatal("sealing not switched")
+		}
+	}
+
+	// standby until the lease is acquired
+	assert.False(t, m.isSealing())
+
+	chain, ok := m.blockchain.(*blockchain.Blockchain)
+	assert.True(t, ok)
+
+	// the block after the head is finalized by the other validators
+	advance := func() {
+		t.Helper()
+
+		head := m.blockchain.Header()
+		header := &types.Header{
+			Number:     head.Number + 1,
+			ParentHash: head.Hash,
+			ExtraData:  head.ExtraData,
+		}
+		header.ComputeHash()
+
+		assert.NoError(t, chain.WriteHeaders([]*types.Header{header}))
+	}
+
+	expectNoSwitch := func() {
+		t.Helper()
+
+		select {
+		case <-switches:
+			t.Fatal("sealing switched")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	lostCh := make(chan struct{})
+	lease.grantCh <- lostCh
+
+	// never signs the block the previous holder might have signed
+	expectNoSwitch()
+	advance()
+	expectSwitch(true)
+
+	// stops sealing once lost
+	close(lostCh)
+	expectSwitch(false)
+
+	// lost again before the takeover
+	lostCh = make(chan struct{})
+	lease.grantCh <- lostCh
+
+	close(lostCh)
+	expectNoSwitch()
+
+	// takes over again
+	lease.grantCh <- make(chan struct{})
+
+	expectNoSwitch()
+	advance()
+	expectSwitch(true)
+
+	// never seals again once stepped down, and the lease is released
+	m.stepDown()
+	expectSwitch(false)
+
+	m.leaseWg.Wait()
+
+	select {
+	case <-lease.releaseCh:
+	default:
+		t.Fatal("lease not released")
+	}
+
+	m.setSealing(true)
+	assert.False(t, m.isSealing())
+
+	close(m.closeCh)
+}
@@ -0,0 +1,186 @@
+package consensus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// BlockStage is a stage of the block pipeline, the stages run in order
+type BlockStage int
+
+const (
+	// BlockStageGossip waits for the proposal from the gossip
+	BlockStageGossip BlockStage = iota
+	// BlockStageVerify verifies the header of the proposal
+	BlockStageVerify
+	// BlockStageExecute executes the transactions of the block, or builds it on the proposer
+	BlockStageExecute
+	// BlockStageCommit collects the votes until the block is committed by the quorum
+	BlockStageCommit
+	// BlockStagePersist writes the committed block to the chain
+	BlockStagePersist
+	// BlockStageBroadcast publishes the new head to the peers
+	BlockStageBroadcast
+
+	numBlockStages
+)
+
+var blockStageNames = [numBlockStages]string{
+	"gossip",
+	"verify",
+	"execute",
+	"commit",
+	"persist",
+	"broadcast",
+}
+
+func (s BlockStage) String() string {
+	if s < 0 || s >= numBlockStages {
+		return "unknown"
+	}
+
+	return blockStageNames[s]
+}
+
+// maxPendingBlocks is the number of the blocks tracked at most, the older ones are
+// dropped if never completed (e.g. the insertion failed)
+const maxPendingBlocks = 16
+
+// blockTimes is the time spent in the stages of the block
+type blockTimes struct {
+	last        time.Time // end of the latest stage
+	durations   [numBlockStages]time.Duration
+	marked      [numBlockStages]bool
+	broadcastAt time.Time // set if broadcast before the persisting is marked
+}
+
+// BlockLatency assembles the per stage latency of the blocks from the marks of the
+// consensus and the syncer, and reports the breakdown at debug level once the block
+// is persisted and broadcast, so that the regressing stage could be pinpointed.
+//
+// The methods are no-op on the nil instance.
+type BlockLatency struct {
+	logger  hclog.Logger
+	metrics *Metrics
+
+	lock   sync.Mutex
+	blocks map[uint64]*blockTimes // height -> times
+}
+
+// NewBlockLatency returns the block latency tracker
+func NewBlockLatency(logger hclog.Logger, metrics *Metrics) *BlockLatency {
+	return &BlockLatency{
+		logger:  logger.Named("latency"),
+		metrics: metrics,
+		blocks:  make(map[uint64]*blockTimes),
+	}
+}
+
+// Start starts timing the block at the height, the stages of the former rounds
+// are discarded
+func (l *BlockLatency) Start(height uint64) {
+	if l == nil {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.blocks[height] = &blockTimes{last: time.Now()}
+
+	for h := range l.blocks {
+		if h+maxPendingBlocks <= height {
+			delete(l.blocks, h)
+		}
+	}
+}
+
+// Skip excludes the time since the end of the latest stage, e.g. waiting for the
+// timestamp of the built block
+func (l *BlockLatency) Skip(height uint64) {
+	if l == nil {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if times, ok := l.blocks[height]; ok {
+		times.last = time.Now()
+	}
+}
+
+// Mark marks the end of the stage of the block at the height, the blocks not started
+// (e.g. the synced ones) are ignored
+func (l *BlockLatency) Mark(height uint64, stage BlockStage) {
+	if l == nil {
+		return
+	}
+
+	now := time.Now()
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	times, ok := l.blocks[height]
+	if !ok || stage < 0 || stage >= numBlockStages {
+		return
+	}
+
+	switch {
+	case stage == BlockStageBroadcast && !times.marked[BlockStagePersist]:
+		// the new head is published once dispatched within the persisting, which
+		// could be marked afterwards
+		times.broadcastAt = now
+
+		return
+	case stage == BlockStagePersist && !times.broadcastAt.IsZero():
+		times.record(BlockStagePersist, now)
+		// overlapped with the persisting
+		times.record(BlockStageBroadcast, now)
+	default:
+		times.record(stage, now)
+	}
+
+	if times.marked[BlockStageBroadcast] {
+		delete(l.blocks, height)
+		l.report(height, times)
+	}
+}
+
+func (t *blockTimes) record(stage BlockStage, end time.Time) {
+	t.durations[stage] = end.Sub(t.last)
+	t.marked[stage] = true
+	t.last = end
+}
+
+// report logs and exports the latency of the completed block
+func (l *BlockLatency) report(height uint64, times *blockTimes) {
+	var (
+		total time.Duration
+		args  = make([]interface{}, 0, 2*numBlockStages+4)
+	)
+
+	args = append(args, "height", height)
+
+	for stage := BlockStage(0); stage < numBlockStages; stage++ {
+		if !times.marked[stage] {
+			continue
+		}
+
+		d := times.durations[stage]
+		total += d
+
+		args = append(args, stage.String(), d)
+
+		if l.metrics != nil {
+			l.metrics.ObserveBlockStage(stage.String(), d.Seconds())
+		}
+	}
+
+	args = append(args, "total", total)
+
+	l.logger.Debug("block latency", args...)
+}
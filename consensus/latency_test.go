@@ -0,0 +1,65 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockLatency_Stages(t *testing.T) {
+	l := NewBlockLatency(hclog.NewNullLogger(), NilMetrics())
+
+	l.Start(1)
+
+	for stage := BlockStageGossip; stage <= BlockStagePersist; stage++ {
+		time.Sleep(time.Millisecond)
+		l.Mark(1, stage)
+	}
+
+	times := l.blocks[1]
+	for stage := BlockStageGossip; stage <= BlockStagePersist; stage++ {
+		assert.True(t, times.marked[stage])
+		assert.GreaterOrEqual(t, times.durations[stage], time.Millisecond, stage.String())
+	}
+
+	// reported once broadcast
+	l.Mark(1, BlockStageBroadcast)
+	assert.Len(t, l.blocks, 0)
+}
+
+func TestBlockLatency_BroadcastBeforePersist(t *testing.T) {
+	l := NewBlockLatency(hclog.NewNullLogger(), NilMetrics())
+
+	l.Start(1)
+	l.Mark(1, BlockStageCommit)
+
+	// the new head is published before the persisting is marked
+	l.Mark(1, BlockStageBroadcast)
+	assert.False(t, l.blocks[1].marked[BlockStageBroadcast])
+
+	l.Mark(1, BlockStagePersist)
+	assert.Len(t, l.blocks, 0)
+}
+
+func TestBlockLatency_Untracked(t *testing.T) {
+	l := NewBlockLatency(hclog.NewNullLogger(), NilMetrics())
+
+	// the synced blocks are not tracked
+	l.Mark(1, BlockStagePersist)
+	l.Mark(1, BlockStageBroadcast)
+	assert.Len(t, l.blocks, 0)
+
+	// the stale blocks are dropped
+	l.Start(1)
+	l.Start(1 + maxPendingBlocks)
+	assert.Len(t, l.blocks, 1)
+
+	// no-op if disabled
+	var disabled *BlockLatency
+
+	disabled.Start(1)
+	disabled.Skip(1)
+	disabled.Mark(1, BlockStageGossip)
+}
@@ -0,0 +1,260 @@
+package lease
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// consulTokenEnv is the environment variable of the consul acl token
+const consulTokenEnv = "CONSUL_HTTP_TOKEN"
+
+var errSessionInvalid = errors.New("consul session invalidated")
+
+// consulLease is the lease of a consul key, locked by a session with the ttl. Consul
+// invalidates the session once it is not renewed within the ttl, and the key could not
+// be locked by another session within the lock delay afterwards. The holder gives the
+// lease up once the session is not renewed within half of the ttl, well before it could
+// be invalidated.
+type consulLease struct {
+	logger hclog.Logger
+	client *http.Client
+	addr   string
+	key    string
+	token  string
+	config Config
+
+	retryInterval  time.Duration // interval of the acquiring attempts
+	renewInterval  time.Duration
+	requestTimeout time.Duration
+
+	lock    sync.Mutex
+	session string             // session holding the lease, empty if not held
+	cancel  context.CancelFunc // stops renewing the session
+	doneCh  chan struct{}      // closed once the renewal stops
+}
+
+func newConsulLease(logger hclog.Logger, addr, key string, config Config) *consulLease {
+	return &consulLease{
+		logger:         logger.Named("lease"),
+		client:         &http.Client{},
+		addr:           addr,
+		key:            key,
+		token:          os.Getenv(consulTokenEnv),
+		config:         config,
+		retryInterval:  config.TTL / 3,
+		renewInterval:  config.TTL / 3,
+		requestTimeout: config.TTL / 6,
+	}
+}
+
+// Acquire implements Lease
+func (l *consulLease) Acquire(ctx context.Context) (<-chan struct{}, error) {
+	for {
+		lostCh, err := l.tryAcquire(ctx)
+		if lostCh != nil {
+			return lostCh, nil
+		}
+
+		if err != nil {
+			l.logger.Debug("failed to acquire lease", "key", l.key, "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(l.retryInterval):
+		}
+	}
+}
+
+// tryAcquire locks the key with a new session, it returns nil if the key is locked
+// by others
+func (l *consulLease) tryAcquire(ctx context.Context) (<-chan struct{}, error) {
+	// the ttl of the session counts from the creation
+	renewed := time.Now()
+
+	session, err := l.createSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired bool
+
+	err = l.do(ctx, http.MethodPut, l.kvPath("acquire", session), []byte(l.config.Holder), &acquired)
+	if err != nil || !acquired {
+		l.destroySession(session)
+
+		return nil, err
+	}
+
+	renewCtx, cancel := context.WithCancel(context.Background())
+	lostCh := make(chan struct{})
+	doneCh := make(chan struct{})
+
+	l.lock.Lock()
+	l.session = session
+	l.cancel = cancel
+	l.doneCh = doneCh
+	l.lock.Unlock()
+
+	go l.renew(renewCtx, session, renewed, lostCh, doneCh)
+
+	l.logger.Info("lease acquired", "key", l.key, "session", session)
+
+	return lostCh, nil
+}
+
+// renew renews the session until cancelled, lostCh is closed once the session is
+// invalidated or not renewed within half of the ttl
+func (l *consulLease) renew(
+	ctx context.Context,
+	session string,
+	renewed time.Time,
+	lostCh, doneCh chan struct{},
+) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(l.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+
+		err := l.do(ctx, http.MethodPut, "/v1/session/renew/"+session, nil, nil)
+		if err == nil {
+			renewed = start
+
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if errors.Is(err, errSessionInvalid) || time.Since(renewed) > l.config.TTL/2 {
+			l.logger.Error("lease lost", "key", l.key, "session", session, "err", err)
+			close(lostCh)
+
+			return
+		}
+
+		l.logger.Warn("failed to renew lease", "key", l.key, "session", session, "err", err)
+	}
+}
+
+// Release implements Lease
+func (l *consulLease) Release() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.session == "" {
+		return nil
+	}
+
+	l.cancel()
+	<-l.doneCh
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.requestTimeout)
+	defer cancel()
+
+	var released bool
+
+	err := l.do(ctx, http.MethodPut, l.kvPath("release", l.session), nil, &released)
+
+	l.destroySession(l.session)
+	l.logger.Info("lease released", "key", l.key, "session", l.session)
+
+	l.session = ""
+
+	return err
+}
+
+func (l *consulLease) createSession(ctx context.Context) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"Name":      l.config.Holder,
+		"TTL":       l.config.TTL.String(),
+		"LockDelay": l.config.TTL.String(),
+		"Behavior":  "release",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		ID string `json:"ID"`
+	}
+
+	if err := l.do(ctx, http.MethodPut, "/v1/session/create", body, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.ID, nil
+}
+
+// destroySession destroys the session, it expires anyway if failed
+func (l *consulLease) destroySession(session string) {
+	ctx, cancel := context.WithTimeout(context.Background(), l.requestTimeout)
+	defer cancel()
+
+	if err := l.do(ctx, http.MethodPut, "/v1/session/destroy/"+session, nil, nil); err != nil {
+		l.logger.Debug("failed to destroy session", "session", session, "err", err)
+	}
+}
+
+func (l *consulLease) kvPath(op, session string) string {
+	return "/v1/kv/" + l.key + "?" + url.Values{op: {session}}.Encode()
+}
+
+// do sends the request to consul, and decodes the json response into out if not nil
+func (l *consulLease) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, l.requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, l.addr+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	if l.token != "" {
+		req.Header.Set("X-Consul-Token", l.token)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		// unknown session
+		return errSessionInvalid
+	case resp.StatusCode != http.StatusOK:
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	case out == nil:
+		return nil
+	default:
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+}
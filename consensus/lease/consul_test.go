@@ -0,0 +1,181 @@
+package lease
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConsul serves the session and kv lock endpoints of consul
+type fakeConsul struct {
+	lock     sync.Mutex
+	sessions map[string]bool
+	holders  map[string]string // key -> session
+	nextID   int
+}
+
+func newFakeConsul(t *testing.T) (*fakeConsul, *httptest.Server) {
+	t.Helper()
+
+	c := &fakeConsul{
+		sessions: map[string]bool{},
+		holders:  map[string]string{},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(c.serve))
+	t.Cleanup(srv.Close)
+
+	return c, srv
+}
+
+func (c *fakeConsul) serve(w http.ResponseWriter, r *http.Request) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, _ = ioutil.ReadAll(r.Body)
+
+	switch path := r.URL.Path; {
+	case path == "/v1/session/create":
+		c.nextID++
+		id := fmt.Sprintf("session-%d", c.nextID)
+		c.sessions[id] = true
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"ID": id})
+	case strings.HasPrefix(path, "/v1/session/renew/"):
+		if !c.sessions[strings.TrimPrefix(path, "/v1/session/renew/")] {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("[]"))
+	case strings.HasPrefix(path, "/v1/session/destroy/"):
+		c.invalidate(strings.TrimPrefix(path, "/v1/session/destroy/"))
+
+		_, _ = w.Write([]byte("true"))
+	case strings.HasPrefix(path, "/v1/kv/"):
+		key := strings.TrimPrefix(path, "/v1/kv/")
+
+		if session := r.URL.Query().Get("acquire"); session != "" {
+			holder, held := c.holders[key]
+			ok := c.sessions[session] && (!held || holder == session)
+
+			if ok {
+				c.holders[key] = session
+			}
+
+			_ = json.NewEncoder(w).Encode(ok)
+		} else if session := r.URL.Query().Get("release"); session != "" {
+			ok := c.holders[key] == session
+			if ok {
+				delete(c.holders, key)
+			}
+
+			_ = json.NewEncoder(w).Encode(ok)
+		}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// invalidate invalidates the session and releases its locks
+func (c *fakeConsul) invalidate(session string) {
+	delete(c.sessions, session)
+
+	for key, holder := range c.holders {
+		if holder == session {
+			delete(c.holders, key)
+		}
+	}
+}
+
+func (c *fakeConsul) holder(key string) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.holders[key]
+}
+
+func newTestConsulLease(addr, holder string) *consulLease {
+	l := newConsulLease(hclog.NewNullLogger(), addr, "dogechain/validator", Config{TTL: MinTTL, Holder: holder})
+	l.retryInterval = 10 * time.Millisecond
+	l.renewInterval = 10 * time.Millisecond
+
+	return l
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := Config{URL: "consul://127.0.0.1:8500/dogechain/validator", TTL: DefaultTTL}
+	assert.NoError(t, valid.Validate())
+
+	for _, c := range []struct {
+		config Config
+		err    error
+	}{
+		{Config{URL: "consul://127.0.0.1:8500", TTL: DefaultTTL}, ErrInvalidURL},
+		{Config{URL: "consul:///dogechain", TTL: DefaultTTL}, ErrInvalidURL},
+		{Config{URL: "etcd://127.0.0.1:2379/dogechain", TTL: DefaultTTL}, ErrUnsupportedBackend},
+		{Config{URL: valid.URL, TTL: time.Second}, ErrInvalidTTL},
+	} {
+		assert.ErrorIs(t, c.config.Validate(), c.err)
+	}
+}
+
+func TestConsulLease_Exclusive(t *testing.T) {
+	consul, srv := newFakeConsul(t)
+
+	first := newTestConsulLease(srv.URL, "first")
+	second := newTestConsulLease(srv.URL, "second")
+
+	_, err := first.Acquire(context.Background())
+	assert.NoError(t, err)
+
+	// held by the first one
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = second.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// taken over once released
+	assert.NoError(t, first.Release())
+
+	_, err = second.Acquire(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, second.session, consul.holder("dogechain/validator"))
+
+	assert.NoError(t, second.Release())
+	assert.Equal(t, "", consul.holder("dogechain/validator"))
+}
+
+func TestConsulLease_Lost(t *testing.T) {
+	consul, srv := newFakeConsul(t)
+
+	l := newTestConsulLease(srv.URL, "first")
+
+	lostCh, err := l.Acquire(context.Background())
+	assert.NoError(t, err)
+
+	// the session expired
+	consul.lock.Lock()
+	consul.invalidate(l.session)
+	consul.lock.Unlock()
+
+	select {
+	case <-lostCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("lease not lost")
+	}
+
+	assert.NoError(t, l.Release())
+}
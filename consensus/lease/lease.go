@@ -0,0 +1,104 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+var (
+	ErrInvalidURL         = errors.New("invalid lease url")
+	ErrUnsupportedBackend = errors.New("unsupported lease backend")
+	ErrInvalidTTL         = errors.New("invalid lease ttl")
+)
+
+const (
+	// DefaultTTL is the default time the lease is kept without renewal
+	DefaultTTL = 15 * time.Second
+
+	// MinTTL is the min ttl of the lease, so that the renewals are not too frequent
+	MinTTL = 10 * time.Second
+)
+
+// Lease is the distributed lease held by one instance at most, so that the instances
+// sharing a validator key, or its remote signer, never sign concurrently. The holder renews it in background
+// until it is released or lost.
+type Lease interface {
+	// Acquire blocks until the lease is acquired or the context is done. The returned
+	// channel is closed once the lease is lost, which happens before the lease could be
+	// acquired by others, so that the holder could stop acting on it in time.
+	Acquire(ctx context.Context) (<-chan struct{}, error)
+
+	// Release stops renewing the held lease and releases it
+	Release() error
+}
+
+// Config is the config of the lease
+type Config struct {
+	// URL locates the lease, in the form of backend://host:port/key,
+	// e.g. consul://127.0.0.1:8500/dogechain/validator
+	URL string
+	// TTL is the time the lease is kept without renewal
+	TTL time.Duration
+	// Holder names the instance holding the lease
+	Holder string
+}
+
+// location is the parsed lease url
+type location struct {
+	backend string
+	addr    string // http url of the backend
+	key     string
+}
+
+func parseURL(raw string) (*location, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	key := strings.Trim(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return nil, fmt.Errorf("%w: %s, expected backend://host:port/key", ErrInvalidURL, raw)
+	}
+
+	switch u.Scheme {
+	case "consul":
+		return &location{backend: u.Scheme, addr: "http://" + u.Host, key: key}, nil
+	case "consuls":
+		return &location{backend: "consul", addr: "https://" + u.Host, key: key}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedBackend, u.Scheme)
+	}
+}
+
+// Validate returns an error if the lease could not be created from the config
+func (c *Config) Validate() error {
+	if _, err := parseURL(c.URL); err != nil {
+		return err
+	}
+
+	if c.TTL < MinTTL {
+		return fmt.Errorf("%w: %s is less than %s", ErrInvalidTTL, c.TTL, MinTTL)
+	}
+
+	return nil
+}
+
+// New returns the lease of the config
+func New(logger hclog.Logger, config Config) (Lease, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	loc, _ := parseURL(config.URL)
+
+	// only consul is supported by now, the other backends (e.g. etcd) implement
+	// the same interface
+	return newConsulLease(logger, loc.addr, loc.key, config), nil
+}
@@ -15,6 +15,12 @@ type Metrics struct {
 	numTxs prometheus.Gauge
 	//Time between current block and the previous block in seconds
 	blockInterval prometheus.Gauge
+	// No.of transactions skipped by the transaction policy, by tag
+	policySkippedTxs *prometheus.CounterVec
+	// No.of consecutive turns missed by the validator
+	missedTurns prometheus.Gauge
+	// Time spent in each stage of the block pipeline in seconds, by stage
+	blockStageSeconds *prometheus.HistogramVec
 }
 
 func (m *Metrics) SetValidators(val float64) {
@@ -33,11 +39,27 @@ func (m *Metrics) SetBlockInterval(val float64) {
 	metrics.SetGauge(m.blockInterval, val)
 }
 
+func (m *Metrics) SetMissedTurns(val float64) {
+	metrics.SetGauge(m.missedTurns, val)
+}
+
+func (m *Metrics) ObserveBlockStage(stage string, seconds float64) {
+	if m.blockStageSeconds != nil {
+		m.blockStageSeconds.WithLabelValues(stage).Observe(seconds)
+	}
+}
+
+func (m *Metrics) PolicySkippedTxsInc(tag string) {
+	if m.policySkippedTxs != nil {
+		m.policySkippedTxs.WithLabelValues(tag).Inc()
+	}
+}
+
 // GetPrometheusMetrics return the consensus metrics instance
 func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics {
 	constLabels := metrics.ParseLables(labelsWithValues...)
 
-	return &Metrics{
+	m := &Metrics{
 		validators: prometheus.NewGauge(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Subsystem:   "consensus",
@@ -66,7 +88,41 @@ func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics
 			Help:        "Time between current block and the previous block in seconds.",
 			ConstLabels: constLabels,
 		}),
+		policySkippedTxs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "consensus",
+			Name:        "policy_skipped_txs",
+			Help:        "Number of transactions skipped by the transaction policy.",
+			ConstLabels: constLabels,
+		}, []string{"tag"}),
+		missedTurns: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   "consensus",
+			Name:        "missed_turns",
+			Help:        "Number of consecutive turns missed by the validator.",
+			ConstLabels: constLabels,
+		}),
+		blockStageSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   "consensus",
+			Name:        "block_stage_seconds",
+			Help:        "Time spent in each stage of the block pipeline in seconds.",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(0.001, 2, 14),
+		}, []string{"stage"}),
 	}
+
+	prometheus.MustRegister(
+		m.validators,
+		m.rounds,
+		m.numTxs,
+		m.blockInterval,
+		m.policySkippedTxs,
+		m.missedTurns,
+		m.blockStageSeconds,
+	)
+
+	return m
 }
 
 // NilMetrics will return the non operational metrics
@@ -0,0 +1,32 @@
+package consensus
+
+import (
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// TxPolicy classifies the candidate transactions of the blocks built, so that the
+// validators could plug their own inclusion rules without patching the sealer.
+// It is called on the sealing path, so it should return quickly.
+type TxPolicy interface {
+	// Classify returns the verdict on the candidate transaction, it is called in the
+	// order the transactions are picked out of the pool
+	Classify(tx *types.Transaction) TxPolicyVerdict
+}
+
+// TxPolicyVerdict is the verdict of a transaction policy on a candidate transaction
+type TxPolicyVerdict struct {
+	// Skip leaves the transaction out of the block. The later transactions of its
+	// sender are left out as well, as they depend on its nonce. They are all kept in
+	// the pool for the next blocks.
+	Skip bool
+	// Tag classifies the transaction, the skipped transactions are logged and counted
+	// by it. Keep the tags few, as each one is a metric series.
+	Tag string
+}
+
+// NoopTxPolicy includes every candidate transaction
+type NoopTxPolicy struct{}
+
+func (NoopTxPolicy) Classify(*types.Transaction) TxPolicyVerdict {
+	return TxPolicyVerdict{}
+}
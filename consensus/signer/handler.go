@@ -0,0 +1,106 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+// maxRequestSize bounds the body of the sign requests
+const maxRequestSize = 1024
+
+// handler serves the key to the nodes signing remotely
+type handler struct {
+	logger  hclog.Logger
+	key     *ecdsa.PrivateKey
+	address types.Address
+	token   string
+}
+
+// NewHandler returns the http handler of the remote signer holding the key, the
+// requests are authenticated by the bearer token if not empty
+func NewHandler(logger hclog.Logger, key *ecdsa.PrivateKey, token string) http.Handler {
+	h := &handler{
+		logger:  logger.Named("signer"),
+		key:     key,
+		address: crypto.PubKeyToAddress(&key.PublicKey),
+		token:   token,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(addressPath, h.authenticated(http.MethodGet, h.handleAddress))
+	mux.HandleFunc(signPath, h.authenticated(http.MethodPost, h.handleSign))
+
+	return mux
+}
+
+// authenticated verifies the method and the bearer token of the request
+func (h *handler) authenticated(method string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		if h.token != "" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+				h.logger.Warn("unauthenticated request", "remote", r.RemoteAddr, "path", r.URL.Path)
+				http.Error(w, "invalid token", http.StatusUnauthorized)
+
+				return
+			}
+		}
+
+		next(w, r)
+	}
+}
+
+func (h *handler) handleAddress(w http.ResponseWriter, _ *http.Request) {
+	h.writeJSON(w, &addressResponse{Address: h.address})
+}
+
+func (h *handler) handleSign(w http.ResponseWriter, r *http.Request) {
+	var req signRequest
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+
+		return
+	}
+
+	digest, err := hex.DecodeHex(req.Digest)
+	if err != nil || len(digest) != types.HashLength {
+		http.Error(w, ErrInvalidDigest.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	sig, err := crypto.Sign(h.key, digest)
+	if err != nil {
+		h.logger.Error("failed to sign", "err", err)
+		http.Error(w, "failed to sign", http.StatusInternalServerError)
+
+		return
+	}
+
+	h.logger.Debug("signed", "remote", r.RemoteAddr, "digest", req.Digest)
+
+	h.writeJSON(w, &signResponse{Signature: hex.EncodeToHex(sig)})
+}
+
+func (h *handler) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Debug("failed to write response", "err", err)
+	}
+}
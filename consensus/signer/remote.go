@@ -0,0 +1,199 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+var (
+	ErrInvalidURL       = errors.New("invalid remote signer url")
+	ErrInvalidTimeout   = errors.New("invalid remote signer timeout")
+	ErrInvalidDigest    = errors.New("invalid digest")
+	ErrSignerMismatched = errors.New("signature not signed by the validator key")
+
+	errNoAddress = errors.New("remote signer returned no address")
+)
+
+const (
+	// DefaultTimeout is the default timeout of the requests to the remote signer
+	DefaultTimeout = 2 * time.Second
+
+	// TokenEnv is the environment variable of the token authenticating the nodes to
+	// the remote signer
+	TokenEnv = "DOGECHAIN_SIGNER_TOKEN"
+
+	// the paths served by the remote signer
+	addressPath = "/v1/address"
+	signPath    = "/v1/sign"
+)
+
+// RemoteConfig is the config of the remote signer
+type RemoteConfig struct {
+	// URL locates the remote signer, in the form of http(s)://host:port
+	URL string
+	// Token authenticates the node to the remote signer, none if empty
+	Token string
+	// Timeout bounds each request to the remote signer, which blocks the consensus
+	Timeout time.Duration
+}
+
+// Validate returns an error if the remote signer could not be created from the config
+func (c *RemoteConfig) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %s, expected http(s)://host:port", ErrInvalidURL, c.URL)
+	}
+
+	if c.Timeout <= 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidTimeout, c.Timeout)
+	}
+
+	return nil
+}
+
+// addressResponse is the response of the address of the remote signer
+type addressResponse struct {
+	Address types.Address `json:"address"`
+}
+
+// signRequest is the request of the signature of the digest
+type signRequest struct {
+	Digest string `json:"digest"`
+}
+
+// signResponse is the response of the signature of the digest
+type signResponse struct {
+	Signature string `json:"signature"`
+}
+
+// remoteSigner signs by the key held by the remote signer. The remote signer is not
+// trusted, each signature is verified to be signed by the validator key.
+type remoteSigner struct {
+	logger  hclog.Logger
+	client  *http.Client
+	addr    string
+	token   string
+	timeout time.Duration
+	address types.Address
+}
+
+// NewRemote returns the signer of the key held by the remote signer, the address of
+// the key is queried from it
+func NewRemote(logger hclog.Logger, config RemoteConfig) (Signer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &remoteSigner{
+		logger:  logger.Named("signer"),
+		client:  &http.Client{},
+		addr:    strings.TrimRight(config.URL, "/"),
+		token:   config.Token,
+		timeout: config.Timeout,
+	}
+
+	var resp addressResponse
+
+	if err := s.do(http.MethodGet, addressPath, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to query the remote signer address: %w", err)
+	}
+
+	if resp.Address == types.ZeroAddress {
+		return nil, errNoAddress
+	}
+
+	s.address = resp.Address
+
+	s.logger.Info("remote signer connected", "url", s.addr, "address", s.address)
+
+	return s, nil
+}
+
+// Address implements Signer
+func (s *remoteSigner) Address() types.Address {
+	return s.address
+}
+
+// Sign implements Signer
+func (s *remoteSigner) Sign(digest []byte) ([]byte, error) {
+	if len(digest) != types.HashLength {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidDigest, len(digest))
+	}
+
+	body, err := json.Marshal(&signRequest{Digest: hex.EncodeToHex(digest)})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp signResponse
+
+	if err := s.do(http.MethodPost, signPath, body, &resp); err != nil {
+		return nil, fmt.Errorf("remote signer: %w", err)
+	}
+
+	sig, err := hex.DecodeHex(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer: invalid signature: %w", err)
+	}
+
+	pub, err := crypto.RecoverPubkey(sig, digest)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer: invalid signature: %w", err)
+	}
+
+	if signer := crypto.PubKeyToAddress(pub); signer != s.address {
+		return nil, fmt.Errorf("%w: signed by %s", ErrSignerMismatched, signer)
+	}
+
+	return sig, nil
+}
+
+// do sends the request to the remote signer, and decodes the json response into out
+func (s *remoteSigner) do(method, path string, body []byte, out interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, s.addr+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,106 @@
+package signer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRemote(t *testing.T, url, token string) (Signer, error) {
+	t.Helper()
+
+	return NewRemote(hclog.NewNullLogger(), RemoteConfig{
+		URL:     url,
+		Token:   token,
+		Timeout: time.Second,
+	})
+}
+
+func TestRemoteSigner_Sign(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	srv := httptest.NewServer(NewHandler(hclog.NewNullLogger(), key, "secret"))
+	defer srv.Close()
+
+	s, err := newTestRemote(t, srv.URL, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.PubKeyToAddress(&key.PublicKey), s.Address())
+
+	digest := crypto.Keccak256([]byte("block"))
+
+	sig, err := s.Sign(digest)
+	assert.NoError(t, err)
+
+	// the same signature as the local key
+	local, err := NewLocal(key).Sign(digest)
+	assert.NoError(t, err)
+	assert.Equal(t, local, sig)
+
+	_, err = s.Sign([]byte("short"))
+	assert.ErrorIs(t, err, ErrInvalidDigest)
+}
+
+func TestRemoteSigner_Unauthenticated(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	srv := httptest.NewServer(NewHandler(hclog.NewNullLogger(), key, "secret"))
+	defer srv.Close()
+
+	_, err = newTestRemote(t, srv.URL, "")
+	assert.Error(t, err)
+
+	_, err = newTestRemote(t, srv.URL, "guess")
+	assert.Error(t, err)
+}
+
+func TestRemoteSigner_Mismatched(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	other, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	// the signer claims the key, but signs by another one
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case addressPath:
+			_ = json.NewEncoder(w).Encode(&addressResponse{Address: crypto.PubKeyToAddress(&key.PublicKey)})
+		case signPath:
+			var req signRequest
+
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			digest, _ := hex.DecodeHex(req.Digest)
+			sig, _ := crypto.Sign(other, digest)
+
+			_ = json.NewEncoder(w).Encode(&signResponse{Signature: hex.EncodeToHex(sig)})
+		}
+	}))
+	defer srv.Close()
+
+	s, err := newTestRemote(t, srv.URL, "")
+	assert.NoError(t, err)
+
+	_, err = s.Sign(crypto.Keccak256([]byte("block")))
+	assert.ErrorIs(t, err, ErrSignerMismatched)
+}
+
+func TestRemoteConfig_Validate(t *testing.T) {
+	for _, config := range []RemoteConfig{
+		{URL: "127.0.0.1:8555", Timeout: time.Second},
+		{URL: "tcp://127.0.0.1:8555", Timeout: time.Second},
+		{URL: "http://127.0.0.1:8555"},
+	} {
+		assert.Error(t, config.Validate(), config.URL)
+	}
+
+	assert.NoError(t, (&RemoteConfig{URL: "https://signer:8555", Timeout: time.Second}).Validate())
+}
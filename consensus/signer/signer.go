@@ -0,0 +1,42 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// Signer signs the digests by the validator key, which is either held by the node or
+// by a remote signer, so that the key is not copied to the standby instances
+type Signer interface {
+	// Address returns the address of the validator key
+	Address() types.Address
+
+	// Sign signs the 32 bytes digest, the signature is in the [R || S || V] form
+	Sign(digest []byte) ([]byte, error)
+}
+
+// localSigner signs by the key held by the node
+type localSigner struct {
+	key     *ecdsa.PrivateKey
+	address types.Address
+}
+
+// NewLocal returns the signer of the key held by the node
+func NewLocal(key *ecdsa.PrivateKey) Signer {
+	return &localSigner{
+		key:     key,
+		address: crypto.PubKeyToAddress(&key.PublicKey),
+	}
+}
+
+// Address implements Signer
+func (s *localSigner) Address() types.Address {
+	return s.address
+}
+
+// Sign implements Signer
+func (s *localSigner) Sign(digest []byte) ([]byte, error) {
+	return crypto.Sign(s.key, digest)
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/secrets"
 	"github.com/dogechain-lab/dogechain/secrets/local"
+	dogechainServer "github.com/dogechain-lab/dogechain/server"
 	"github.com/dogechain-lab/dogechain/server/proto"
 	txpoolProto "github.com/dogechain-lab/dogechain/txpool/proto"
 	"github.com/dogechain-lab/dogechain/types"
@@ -39,6 +40,7 @@ import (
 	"github.com/umbracle/go-web3/jsonrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	empty "google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -47,6 +49,10 @@ type TestServerConfigCallback func(*TestServerConfig)
 const (
 	serverIP   = "127.0.0.1"
 	binaryName = "dogechain"
+
+	// the token authenticating the admin operations of the test servers
+	adminToken     = "e2e-admin-token"
+	adminTokenFile = "admin-token"
 )
 
 var lock sync.Mutex
@@ -122,10 +128,12 @@ func (t *TestServer) JSONRPC() *jsonrpc.Client {
 	return clt
 }
 
+// Operator returns the client of the system service, the calls carry the admin token
 func (t *TestServer) Operator() proto.SystemClient {
 	conn, err := grpc.Dial(
 		t.GrpcAddr(),
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(adminTokenInterceptor),
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallRecvMsgSize(common.MaxGrpcMsgSize),
 			grpc.MaxCallSendMsgSize(common.MaxGrpcMsgSize)))
@@ -136,6 +144,20 @@ func (t *TestServer) Operator() proto.SystemClient {
 	return proto.NewSystemClient(conn)
 }
 
+// adminTokenInterceptor appends the admin token of the test servers to the calls
+func adminTokenInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, dogechainServer.AdminTokenMetadataKey, adminToken)
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
 func (t *TestServer) TxnPoolOperator() txpoolProto.TxnPoolOperatorClient {
 	conn, err := grpc.Dial(
 		t.GrpcAddr(),
@@ -344,6 +366,12 @@ func (t *TestServer) Start(ctx context.Context) error {
 		"--libp2p", t.LibP2PAddr(),
 		// enable jsonrpc
 		"--jsonrpc", t.JSONRPCAddr(),
+		// enable the admin operations
+		"--admin-token-file", filepath.Join(t.Config.RootDir, adminTokenFile),
+	}
+
+	if err := os.WriteFile(filepath.Join(t.Config.RootDir, adminTokenFile), []byte(adminToken), 0600); err != nil {
+		return err
 	}
 
 	if t.Config.IsWSEnable {
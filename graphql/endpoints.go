@@ -8,6 +8,7 @@ import (
 	"github.com/dogechain-lab/dogechain/helper/progress"
 	"github.com/dogechain-lab/dogechain/state"
 	"github.com/dogechain-lab/dogechain/state/runtime"
+	"github.com/dogechain-lab/dogechain/txpool"
 	"github.com/dogechain-lab/dogechain/types"
 )
 
@@ -82,8 +83,11 @@ type filterManagerStore interface {
 	// Header returns the current header of the chain (genesis if empty)
 	Header() *types.Header
 
-	// SubscribeEvents subscribes for chain head events
-	SubscribeEvents() blockchain.Subscription
+	// SubscribeEventsWithFilter subscribes for the blockchain events matching the filter
+	SubscribeEventsWithFilter(filter *blockchain.EventFilter) blockchain.Subscription
+
+	// SubscribePendingTxs subscribes for the transactions promoted to the pending queue
+	SubscribePendingTxs() txpool.PendingTxsSubscription
 
 	// GetReceiptsByHash returns the receipts for a block hash
 	GetReceiptsByHash(hash types.Hash) ([]*types.Receipt, error)
@@ -93,4 +97,12 @@ type filterManagerStore interface {
 
 	// GetBlockByNumber returns a block using the provided number
 	GetBlockByNumber(num uint64, full bool) (*types.Block, bool)
+
+	// GetHeadersInRange returns the canonical headers within [from, to], stopping at
+	// the first missing one
+	GetHeadersInRange(from, to uint64) []*types.Header
+
+	// FilterLogBlocks returns the block numbers within [from, to] which might contain
+	// the logs, it returns false if neither the log index nor the bloom bits are available
+	FilterLogBlocks(addresses []types.Address, topics [][]types.Hash, from, to uint64) ([]uint64, bool)
 }
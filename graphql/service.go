@@ -1,23 +1,29 @@
 package graphql
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"time"
 
 	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/helper/tlsutil"
 	rpc "github.com/dogechain-lab/dogechain/jsonrpc"
 	"github.com/graph-gophers/graphql-go"
 	"github.com/hashicorp/go-hclog"
 )
 
 type GraphQLService struct {
-	logger  hclog.Logger
-	config  *Config
-	ui      *GraphiQL
-	handler *handler
+	logger     hclog.Logger
+	config     *Config
+	ui         *GraphiQL
+	handler    *handler
+	httpServer *http.Server
+
+	certReloader *tlsutil.CertReloader // nil if serving plain http
 }
 
 type Config struct {
@@ -29,6 +35,7 @@ type Config struct {
 	BlockRangeLimit          uint64
 	EnablePProf              bool
 	PriceLimit               uint64
+	TLS                      *tlsutil.Config // certificate terminating TLS, nil for plain http
 }
 
 // GraphQLStore defines all the methods required
@@ -69,13 +76,23 @@ func NewGraphQLService(logger hclog.Logger, config *Config) (*GraphQLService, er
 }
 
 func (svc *GraphQLService) setupHTTP() error {
-	svc.logger.Info("graphql server started", "addr", svc.config.Addr.String())
+	svc.logger.Info("graphql server started", "addr", svc.config.Addr.String(), "tls", svc.config.TLS != nil)
 
 	lis, err := net.Listen("tcp", svc.config.Addr.String())
 	if err != nil {
 		return err
 	}
 
+	if svc.config.TLS != nil {
+		if svc.certReloader, err = tlsutil.NewCertReloader(svc.logger, *svc.config.TLS); err != nil {
+			lis.Close()
+
+			return err
+		}
+
+		lis = tlsutil.NewListener(lis, svc.certReloader)
+	}
+
 	var mux *http.ServeMux
 	if svc.config.EnablePProf {
 		// debug feature enabled
@@ -93,13 +110,15 @@ func (svc *GraphQLService) setupHTTP() error {
 	mux.Handle("/graphql", middlewareFactory(svc.config)(graphqlHandler))
 	mux.Handle("/graphql/", middlewareFactory(svc.config)(graphqlHandler))
 
-	srv := http.Server{
+	srv := &http.Server{
 		Handler:           mux,
 		ReadHeaderTimeout: time.Minute,
 	}
 
+	svc.httpServer = srv
+
 	go func() {
-		if err := srv.Serve(lis); err != nil {
+		if err := srv.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
 			svc.logger.Error("closed http connection", "err", err)
 		}
 	}()
@@ -107,6 +126,24 @@ func (svc *GraphQLService) setupHTTP() error {
 	return nil
 }
 
+// Close stops serving, the in-flight requests are given the timeout to finish
+// before their connections are closed
+func (svc *GraphQLService) Close(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if svc.certReloader != nil {
+		svc.certReloader.Close()
+	}
+
+	err := svc.httpServer.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return svc.httpServer.Close()
+	}
+
+	return err
+}
+
 type handler struct {
 	Schema *graphql.Schema
 }
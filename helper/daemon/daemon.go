@@ -140,8 +140,14 @@ func (d *Daemon) Run() {
 }
 
 func startProc(args, env []string, logFile string, pipeData string) (*exec.Cmd, error) {
+	// args[0] might be a bare name looked up in PATH, or miss the .exe suffix on windows
+	path, err := os.Executable()
+	if err != nil {
+		path = args[0]
+	}
+
 	cmd := &exec.Cmd{
-		Path:        args[0],
+		Path:        path,
 		Args:        args,
 		Env:         env,
 		SysProcAttr: NewSysProcAttr(),
@@ -3,28 +3,25 @@ package gasprice
 import (
 	"errors"
 	"math/big"
-	"sort"
 	"sync"
 
-	"github.com/dogechain-lab/dogechain/blockchain"
-	"github.com/dogechain-lab/dogechain/chain"
-	"github.com/dogechain-lab/dogechain/crypto"
 	"github.com/dogechain-lab/dogechain/types"
 )
 
 var (
 	Defaults = Config{
-		Blocks:      30,
-		Percentile:  60,
-		MaxPrice:    defaultMaxPrice,
-		IgnorePrice: defaultIgnorePrice,
+		Blocks:               30,
+		Percentile:           60,
+		MaxPrice:             defaultMaxPrice,
+		IgnorePrice:          defaultIgnorePrice,
+		Smoothing:            30,
+		CongestionThreshold:  80,
+		CongestionMultiplier: 150,
 	}
 )
 
 const (
 	gwei = 1e9
-
-	sampleNumber = 3 // Number of transactions sampled in a block
 )
 
 var (
@@ -33,37 +30,49 @@ var (
 )
 
 type Config struct {
-	Blocks      int
-	Percentile  int
-	Default     *big.Int `toml:",omitempty"`
-	MaxPrice    *big.Int `toml:",omitempty"`
+	// Blocks is the number of recent blocks sampled by the blockchain gas price history
+	Blocks     int
+	Percentile int
+	Default    *big.Int `toml:",omitempty"`
+	MaxPrice   *big.Int `toml:",omitempty"`
+	// IgnorePrice is the gas price below which transactions are not sampled
 	IgnorePrice *big.Int `toml:",omitempty"`
+	// Smoothing is the weight percentage of the latest sample in the exponential
+	// moving average of the suggested price, 0 or 100 disables smoothing
+	Smoothing int
+	// CongestionThreshold is the average gas used percentage of the sampled blocks
+	// above which the suggested price is raised
+	CongestionThreshold int
+	// CongestionMultiplier is the percentage applied to the suggested price when
+	// the sampled blocks are full, scaled linearly from the threshold, 0 disables it
+	CongestionMultiplier int
 }
 
 // OracleBackend includes most necessary background APIs for oracle.
 type OracleBackend interface {
 	Header() *types.Header
-	GetBlockByNumber(n uint64, full bool) (*types.Block, bool)
-	GetReceiptsByHash(hash types.Hash) ([]*types.Receipt, error)
-	// PendingBlockAndReceipts() (*types.Block, types.Receipts)
-	ChainID() uint64
-	ForksInTime(number uint64) chain.ForksInTime
-	SubscribeEvents() blockchain.Subscription
+	// SuggestedPrice returns the percentile of the gas prices sampled from the recent
+	// blocks, nil if no price is sampled
+	SuggestedPrice(percentile int) *big.Int
+	// GasUsedRatios returns the gas used percentages of the recent blocks
+	GasUsedRatios() []uint64
 }
 
-// Oracle recommends gas prices based on the content of recent
-// blocks. Suitable for both light and full clients.
+// Oracle recommends gas prices based on the gas price history of recent
+// blocks kept by the blockchain.
 type Oracle struct {
-	backend     OracleBackend
-	lastHead    types.Hash
-	priceLimit  *big.Int
-	lastPrice   *big.Int
-	maxPrice    *big.Int
-	ignorePrice *big.Int
-	cacheLock   sync.RWMutex
-	fetchLock   sync.Mutex
-
-	checkBlocks, percentile int
+	backend    OracleBackend
+	lastHead   types.Hash
+	priceLimit *big.Int
+	lastPrice  *big.Int
+	maxPrice   *big.Int
+	avgPrice   *big.Int // moving average of the sampled prices, guarded by fetchLock
+	cacheLock  sync.RWMutex
+	fetchLock  sync.Mutex
+
+	percentile int
+
+	smoothing, congestionThreshold, congestionMultiplier int
 }
 
 // NewOracle returns a new gasprice oracle which can recommend suitable
@@ -72,8 +81,7 @@ func NewOracle(
 	backend OracleBackend,
 	params Config,
 ) (*Oracle, error) {
-	blocks := params.Blocks
-	if blocks < 1 {
+	if params.Blocks < 1 {
 		return nil, errors.New("invalid gasprice oracle sample blocks")
 	}
 
@@ -87,19 +95,38 @@ func NewOracle(
 		return nil, errors.New("invalid gasprice oracle price cap")
 	}
 
-	ignorePrice := params.IgnorePrice
-	if ignorePrice == nil || ignorePrice.Int64() <= 0 {
+	if params.IgnorePrice == nil || params.IgnorePrice.Int64() <= 0 {
 		return nil, errors.New("invalid gasprice oracle ignore price")
 	}
 
+	smoothing := params.Smoothing
+	if smoothing < 0 || smoothing > 100 {
+		return nil, errors.New("invalid gasprice oracle smoothing percentage")
+	} else if smoothing == 0 {
+		smoothing = 100
+	}
+
+	threshold := params.CongestionThreshold
+	if threshold < 0 || threshold >= 100 {
+		return nil, errors.New("invalid gasprice oracle congestion threshold")
+	}
+
+	multiplier := params.CongestionMultiplier
+	if multiplier == 0 {
+		multiplier = 100
+	} else if multiplier < 100 {
+		return nil, errors.New("invalid gasprice oracle congestion multiplier")
+	}
+
 	return &Oracle{
-		backend:     backend,
-		priceLimit:  params.Default,
-		lastPrice:   params.Default,
-		maxPrice:    maxPrice,
-		ignorePrice: ignorePrice,
-		checkBlocks: blocks,
-		percentile:  percent,
+		backend:              backend,
+		priceLimit:           params.Default,
+		lastPrice:            params.Default,
+		maxPrice:             maxPrice,
+		percentile:           percent,
+		smoothing:            smoothing,
+		congestionThreshold:  threshold,
+		congestionMultiplier: multiplier,
 	}, nil
 }
 
@@ -140,77 +167,15 @@ func (oracle *Oracle) SuggestTipCap() (*big.Int, error) {
 		return new(big.Int).Set(lastPrice), nil
 	}
 
-	var (
-		sent, exp int
-		number    = head.Number
-		result    = make(chan results, oracle.checkBlocks)
-		quit      = make(chan struct{})
-		results   []*big.Int
-		chainid   = oracle.backend.ChainID()
-	)
-
-	for sent < oracle.checkBlocks && number > 0 {
-		go oracle.getBlockValues(
-			crypto.NewSigner(oracle.backend.ForksInTime(number), chainid),
-			number,
-			sampleNumber,
-			oracle.ignorePrice,
-			result,
-			quit,
-		)
-
-		sent++
-		exp++
-		number--
+	// Sample the recent blocks, use the price limit if nothing sampled
+	price := oracle.backend.SuggestedPrice(oracle.percentile)
+	if price == nil {
+		price = oracle.priceLimit
 	}
 
-	for exp > 0 {
-		res := <-result
-		if res.err != nil {
-			close(quit)
-
-			return new(big.Int).Set(lastPrice), res.err
-		}
-
-		exp--
-
-		// Nothing returned. There are two special cases here:
-		// - The block is empty
-		// - All the transactions included are sent by the miner itself.
-		// In these cases, use the price limit for sampling.
-		if len(res.values) == 0 {
-			res.values = []*big.Int{oracle.priceLimit}
-		}
-
-		// Besides, in order to collect enough data for sampling, if nothing
-		// meaningful returned, try to query more blocks. But the maximum
-		// is 2*checkBlocks.
-		if len(res.values) == 1 && len(results)+1+exp < oracle.checkBlocks*2 && number > 0 {
-			go oracle.getBlockValues(
-				crypto.NewSigner(oracle.backend.ForksInTime(number), chainid),
-				number,
-				sampleNumber,
-				oracle.ignorePrice,
-				result,
-				quit,
-			)
-
-			sent++
-			exp++
-			number--
-		}
-
-		results = append(results, res.values...)
-	}
-
-	// First update
-	price := oracle.priceLimit
-
-	if len(results) > 0 {
-		sort.Sort(bigIntArray(results))
-		// Update with percentile
-		price = results[(len(results)-1)*oracle.percentile/100]
-	}
+	// Smooth the sampled price, and raise it when blocks are congested
+	price = oracle.smoothPrice(price)
+	price = oracle.congestionPrice(price, oracle.backend.GasUsedRatios())
 
 	// price should not exceed max limit
 	if price.Cmp(oracle.maxPrice) > 0 {
@@ -223,86 +188,54 @@ func (oracle *Oracle) SuggestTipCap() (*big.Int, error) {
 	return new(big.Int).Set(price), nil
 }
 
-type results struct {
-	values []*big.Int
-	err    error
-}
-
-type txSorter struct {
-	txs []*types.Transaction
-}
-
-func newSorter(txs []*types.Transaction) *txSorter {
-	return &txSorter{
-		txs: txs,
+// smoothPrice updates the moving average with the sampled price, and returns the average.
+// It is called once per chain head.
+func (oracle *Oracle) smoothPrice(sample *big.Int) *big.Int {
+	if sample == nil {
+		return nil
 	}
-}
 
-func (s *txSorter) Len() int { return len(s.txs) }
-func (s *txSorter) Swap(i, j int) {
-	s.txs[i], s.txs[j] = s.txs[j], s.txs[i]
-}
-func (s *txSorter) Less(i, j int) bool {
-	return s.txs[i].GasPrice.Cmp(s.txs[j].GasPrice) < 0
-}
+	if oracle.avgPrice == nil || oracle.smoothing == 100 {
+		oracle.avgPrice = new(big.Int).Set(sample)
 
-// getBlockPrices calculates the lowest transaction gas price in a given block
-// and sends it to the result channel. If the block is empty or all transactions
-// are sent by the miner itself(it doesn't make any sense to include this kind of
-// transaction prices for sampling), nil gasprice is returned.
-func (oracle *Oracle) getBlockValues(
-	signer crypto.TxSigner,
-	blockNum uint64,
-	limit int,
-	ignoreUnder *big.Int,
-	result chan results,
-	quit chan struct{},
-) {
-	block, _ := oracle.backend.GetBlockByNumber(blockNum, true)
-	if block == nil {
-		select {
-		case result <- results{nil, errors.New("block not exists")}:
-		case <-quit:
-		}
-
-		return
+		return new(big.Int).Set(sample)
 	}
 
-	// Sort the transaction by gas price in ascending sort.
-	txs := make([]*types.Transaction, len(block.Transactions))
-	copy(txs, block.Transactions)
+	// avg += (sample - avg) * smoothing / 100
+	delta := new(big.Int).Sub(sample, oracle.avgPrice)
+	delta.Mul(delta, big.NewInt(int64(oracle.smoothing)))
+	delta.Quo(delta, big.NewInt(100))
 
-	sorter := newSorter(txs)
-	sort.Sort(sorter)
+	oracle.avgPrice = new(big.Int).Add(oracle.avgPrice, delta)
 
-	var prices = make([]*big.Int, 0, limit)
+	return new(big.Int).Set(oracle.avgPrice)
+}
 
-	for _, tx := range sorter.txs {
-		tip := tx.GasPrice
-		if ignoreUnder != nil && tip.Cmp(ignoreUnder) == -1 {
-			continue
-		}
+// congestionPrice raises the price by the congestion multiplier, scaled linearly
+// by the average gas used percentage of the sampled blocks above the threshold
+func (oracle *Oracle) congestionPrice(price *big.Int, gasRatios []uint64) *big.Int {
+	if price == nil || len(gasRatios) == 0 || oracle.congestionMultiplier == 100 {
+		return price
+	}
 
-		sender, err := signer.Sender(tx)
-		// ignore validator sending txs, mostly are system contract txns
-		if err != nil || sender == block.Header.Miner {
-			continue
-		}
+	var total uint64
+	for _, ratio := range gasRatios {
+		total += ratio
+	}
 
-		prices = append(prices, tip)
-		if len(prices) >= limit {
-			break
-		}
+	avgRatio := int(total / uint64(len(gasRatios)))
+	if avgRatio <= oracle.congestionThreshold {
+		return price
 	}
 
-	select {
-	case result <- results{prices, nil}:
-	case <-quit:
+	if avgRatio > 100 {
+		avgRatio = 100
 	}
-}
 
-type bigIntArray []*big.Int
+	multiplier := 100 + (oracle.congestionMultiplier-100)*
+		(avgRatio-oracle.congestionThreshold)/(100-oracle.congestionThreshold)
+
+	raised := new(big.Int).Mul(price, big.NewInt(int64(multiplier)))
 
-func (s bigIntArray) Len() int           { return len(s) }
-func (s bigIntArray) Less(i, j int) bool { return s[i].Cmp(s[j]) < 0 }
-func (s bigIntArray) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+	return raised.Quo(raised, big.NewInt(100))
+}
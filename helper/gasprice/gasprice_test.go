@@ -0,0 +1,142 @@
+package gasprice
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOracle(t *testing.T, smoothing, threshold, multiplier int) *Oracle {
+	t.Helper()
+
+	config := Defaults
+	config.Smoothing = smoothing
+	config.CongestionThreshold = threshold
+	config.CongestionMultiplier = multiplier
+
+	oracle, err := NewOracle(nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return oracle
+}
+
+func TestOracle_SmoothPrice(t *testing.T) {
+	t.Parallel()
+
+	oracle := newTestOracle(t, 50, 80, 0)
+
+	// the first sample initializes the average
+	assert.Equal(t, big.NewInt(100), oracle.smoothPrice(big.NewInt(100)))
+	assert.Equal(t, big.NewInt(150), oracle.smoothPrice(big.NewInt(200)))
+	assert.Equal(t, big.NewInt(125), oracle.smoothPrice(big.NewInt(100)))
+
+	// no smoothing
+	oracle = newTestOracle(t, 0, 80, 0)
+
+	assert.Equal(t, big.NewInt(100), oracle.smoothPrice(big.NewInt(100)))
+	assert.Equal(t, big.NewInt(200), oracle.smoothPrice(big.NewInt(200)))
+}
+
+func TestOracle_CongestionPrice(t *testing.T) {
+	t.Parallel()
+
+	oracle := newTestOracle(t, 0, 80, 200)
+	price := big.NewInt(1000)
+
+	// below the threshold
+	assert.Equal(t, big.NewInt(1000), oracle.congestionPrice(price, []uint64{50, 90}))
+	// halfway from the threshold to full blocks
+	assert.Equal(t, big.NewInt(1500), oracle.congestionPrice(price, []uint64{90, 90}))
+	// full blocks
+	assert.Equal(t, big.NewInt(2000), oracle.congestionPrice(price, []uint64{100, 100}))
+
+	// disabled
+	oracle = newTestOracle(t, 0, 80, 0)
+
+	assert.Equal(t, big.NewInt(1000), oracle.congestionPrice(price, []uint64{100, 100}))
+}
+
+func TestNewOracle_InvalidSmoothingConfig(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []Config{
+		{Smoothing: 101},
+		{CongestionThreshold: 100},
+		{CongestionMultiplier: 50},
+	} {
+		params := Defaults
+		params.Smoothing = config.Smoothing
+		params.CongestionThreshold = config.CongestionThreshold
+		params.CongestionMultiplier = config.CongestionMultiplier
+
+		_, err := NewOracle(nil, params)
+		assert.Error(t, err)
+	}
+}
+
+type mockBackend struct {
+	head      *types.Header
+	price     *big.Int
+	gasRatios []uint64
+}
+
+func (m *mockBackend) Header() *types.Header {
+	return m.head
+}
+
+func (m *mockBackend) SuggestedPrice(percentile int) *big.Int {
+	return m.price
+}
+
+func (m *mockBackend) GasUsedRatios() []uint64 {
+	return m.gasRatios
+}
+
+func TestOracle_SuggestTipCap(t *testing.T) {
+	t.Parallel()
+
+	backend := &mockBackend{
+		head: &types.Header{Hash: types.StringToHash("1")},
+	}
+
+	config := Defaults
+	config.Default = big.NewInt(100)
+	config.MaxPrice = big.NewInt(1000)
+	config.Smoothing = 0
+	config.CongestionMultiplier = 0
+
+	oracle, err := NewOracle(backend, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing sampled, use the price limit
+	price, err := oracle.SuggestTipCap()
+	assert.NoError(t, err)
+	assert.Equal(t, "100", price.String())
+
+	// the price is cached until the head changes
+	backend.price = big.NewInt(200)
+
+	price, err = oracle.SuggestTipCap()
+	assert.NoError(t, err)
+	assert.Equal(t, "100", price.String())
+
+	backend.head = &types.Header{Hash: types.StringToHash("2")}
+
+	price, err = oracle.SuggestTipCap()
+	assert.NoError(t, err)
+	assert.Equal(t, "200", price.String())
+
+	// the price is capped
+	backend.head = &types.Header{Hash: types.StringToHash("3")}
+	backend.price = big.NewInt(2000)
+
+	price, err = oracle.SuggestTipCap()
+	assert.NoError(t, err)
+	assert.Equal(t, "1000", price.String())
+}
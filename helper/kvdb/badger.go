@@ -0,0 +1,369 @@
+//go:build badgerdb
+// +build badgerdb
+
+package kvdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/hashicorp/go-hclog"
+)
+
+func openBadgerDB(builder *badgerBuilder) (KVBatchStorage, error) {
+	options := badger.DefaultOptions(builder.path).
+		WithBlockCacheSize(int64(builder.cacheSize) * 1024 * 1024).
+		WithSyncWrites(builder.syncWrites).
+		WithReadOnly(builder.readOnly).
+		WithLogger(&badgerLogger{logger: builder.logger.Named("badgerdb")})
+
+	db, err := badger.Open(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &badgerDBKV{db: db, stallTables: options.NumLevelZeroTablesStall}, nil
+}
+
+// badgerFlattenWorkers is the number of the workers flattening the tree on compaction
+const badgerFlattenWorkers = 2
+
+// badgerLogger forwards the badger logs, the info ones are too chatty
+type badgerLogger struct {
+	logger hclog.Logger
+}
+
+func (l *badgerLogger) Errorf(format string, args ...interface{}) {
+	l.logger.Error(fmt.Sprintf(format, args...))
+}
+
+func (l *badgerLogger) Warningf(format string, args ...interface{}) {
+	l.logger.Warn(fmt.Sprintf(format, args...))
+}
+
+func (l *badgerLogger) Infof(format string, args ...interface{}) {
+	l.logger.Debug(fmt.Sprintf(format, args...))
+}
+
+func (l *badgerLogger) Debugf(format string, args ...interface{}) {
+	l.logger.Trace(fmt.Sprintf(format, args...))
+}
+
+type badgerBatch struct {
+	db     *badger.DB
+	keys   [][]byte
+	values [][]byte // nil for the deletions
+	size   int
+}
+
+func (b *badgerBatch) Set(k, v []byte) {
+	// the caller might reuse the slices before writing
+	b.keys = append(b.keys, append([]byte{}, k...))
+	b.values = append(b.values, append([]byte{}, v...))
+	b.size += len(k) + len(v)
+}
+
+func (b *badgerBatch) Delete(k []byte) {
+	b.keys = append(b.keys, append([]byte{}, k...))
+	b.values = append(b.values, nil)
+	b.size += len(k)
+}
+
+func (b *badgerBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *badgerBatch) Write() error {
+	batch := b.db.NewWriteBatch()
+
+	for i := range b.keys {
+		var err error
+
+		if b.values[i] == nil {
+			err = batch.Delete(b.keys[i])
+		} else {
+			err = batch.Set(b.keys[i], b.values[i])
+		}
+
+		if err != nil {
+			batch.Cancel()
+
+			return err
+		}
+	}
+
+	return batch.Flush()
+}
+
+func (b *badgerBatch) Reset() {
+	b.keys = b.keys[:0]
+	b.values = b.values[:0]
+	b.size = 0
+}
+
+// badgerDBKV is the badger implementation of the kv storage
+type badgerDBKV struct {
+	db          *badger.DB
+	stallTables int // level 0 tables stalling the writes
+}
+
+func (kv *badgerDBKV) Batch() KVBatch {
+	return &badgerBatch{db: kv.db}
+}
+
+func (kv *badgerDBKV) Iterator(Range *KVIteratorRange) KVIterator {
+	iter := &badgerIterator{txn: kv.db.NewTransaction(false)}
+
+	if Range != nil {
+		iter.start, iter.limit = Range.Start, Range.Limit
+	}
+
+	return iter
+}
+
+// Set sets the key-value pair in badger storage
+func (kv *badgerDBKV) Set(p []byte, v []byte) error {
+	return kv.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(p, v)
+	})
+}
+
+// Get retrieves the key-value pair in badger storage
+func (kv *badgerDBKV) Get(p []byte) ([]byte, bool, error) {
+	var data []byte
+
+	err := kv.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(p)
+		if err != nil {
+			return err
+		}
+
+		data, err = item.ValueCopy(nil)
+
+		return err
+	})
+
+	if errors.Is(err, badger.ErrKeyNotFound) || errors.Is(err, badger.ErrDBClosed) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+// Delete removes the key-value pair in badger storage
+func (kv *badgerDBKV) Delete(p []byte) error {
+	return kv.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(p)
+	})
+}
+
+// CompactionBacklog returns the number of the level 0 tables, and whether the writes are stalled
+func (kv *badgerDBKV) CompactionBacklog() (int, bool) {
+	levels := kv.db.Levels()
+	if len(levels) == 0 {
+		return 0, false
+	}
+
+	return levels[0].NumTables, levels[0].NumTables >= kv.stallTables
+}
+
+// Compact flattens the tables of all the levels into the last one. The range is
+// ignored, badger always compacts the whole tree.
+func (kv *badgerDBKV) Compact(_, _ []byte) error {
+	return kv.db.Flatten(badgerFlattenWorkers)
+}
+
+// Close closes the badger storage instance
+func (kv *badgerDBKV) Close() error {
+	return kv.db.Close()
+}
+
+// positions of the badger iterator out of the pairs
+const (
+	badgerIterBefore = iota // before the first pair, or not moved yet
+	badgerIterAt            // at a pair
+	badgerIterAfter         // after the last pair
+)
+
+// badgerIterator iterates the pairs within [start, limit) of a read-only transaction,
+// which is a consistent snapshot of the database. The badger iterators are
+// unidirectional, so it switches between a forward and a reverse one when the
+// direction changes.
+type badgerIterator struct {
+	txn          *badger.Txn
+	start, limit []byte
+
+	iter     *badger.Iterator
+	reverse  bool
+	position int
+
+	key, value []byte
+	err        error
+	released   bool
+}
+
+func (it *badgerIterator) open(reverse bool) {
+	if it.iter != nil {
+		it.iter.Close()
+	}
+
+	options := badger.DefaultIteratorOptions
+	options.Reverse = reverse
+
+	it.iter = it.txn.NewIterator(options)
+	it.reverse = reverse
+}
+
+// settle loads the current pair, or moves to the exhausted position if out of the range
+func (it *badgerIterator) settle(exhausted int) bool {
+	it.key, it.value = nil, nil
+
+	if it.err != nil || !it.iter.Valid() {
+		it.position = exhausted
+
+		return false
+	}
+
+	item := it.iter.Item()
+	key := item.Key()
+
+	if (it.start != nil && bytes.Compare(key, it.start) < 0) ||
+		(it.limit != nil && bytes.Compare(key, it.limit) >= 0) {
+		it.position = exhausted
+
+		return false
+	}
+
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		it.err = err
+		it.position = exhausted
+
+		return false
+	}
+
+	it.key, it.value = item.KeyCopy(nil), value
+	it.position = badgerIterAt
+
+	return true
+}
+
+// turn switches the direction at the current pair, and skips it
+func (it *badgerIterator) turn(reverse bool) {
+	key := it.key
+
+	it.open(reverse)
+	it.iter.Seek(key)
+
+	if it.iter.Valid() && bytes.Equal(it.iter.Item().Key(), key) {
+		it.iter.Next()
+	}
+}
+
+func (it *badgerIterator) First() bool {
+	if it.released {
+		return false
+	}
+
+	it.open(false)
+	it.iter.Seek(it.start)
+
+	return it.settle(badgerIterAfter)
+}
+
+func (it *badgerIterator) Last() bool {
+	if it.released {
+		return false
+	}
+
+	it.open(true)
+
+	if it.limit == nil {
+		it.iter.Rewind()
+	} else {
+		// the limit is exclusive
+		it.iter.Seek(it.limit)
+
+		if it.iter.Valid() && bytes.Equal(it.iter.Item().Key(), it.limit) {
+			it.iter.Next()
+		}
+	}
+
+	return it.settle(badgerIterBefore)
+}
+
+func (it *badgerIterator) Seek(key []byte) bool {
+	if it.released {
+		return false
+	}
+
+	if it.start != nil && bytes.Compare(key, it.start) < 0 {
+		key = it.start
+	}
+
+	it.open(false)
+	it.iter.Seek(append([]byte{}, key...))
+
+	return it.settle(badgerIterAfter)
+}
+
+func (it *badgerIterator) Next() bool {
+	switch {
+	case it.released || it.position == badgerIterAfter:
+		return false
+	case it.position == badgerIterBefore:
+		return it.First()
+	case it.reverse:
+		it.turn(false)
+	default:
+		it.iter.Next()
+	}
+
+	return it.settle(badgerIterAfter)
+}
+
+func (it *badgerIterator) Prev() bool {
+	switch {
+	case it.released || it.position == badgerIterBefore && it.iter != nil:
+		return false
+	case it.position == badgerIterAfter || it.iter == nil:
+		return it.Last()
+	case !it.reverse:
+		it.turn(true)
+	default:
+		it.iter.Next()
+	}
+
+	return it.settle(badgerIterBefore)
+}
+
+func (it *badgerIterator) Key() []byte {
+	return it.key
+}
+
+func (it *badgerIterator) Value() []byte {
+	return it.value
+}
+
+func (it *badgerIterator) Release() {
+	if it.released {
+		return
+	}
+
+	if it.iter != nil {
+		it.iter.Close()
+	}
+
+	it.txn.Discard()
+
+	it.released = true
+	it.key, it.value = nil, nil
+}
+
+func (it *badgerIterator) Error() error {
+	return it.err
+}
@@ -0,0 +1,87 @@
+package kvdb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// minBadgerDBCache is the minimum memory allocated to the badger block cache
+	minBadgerDBCache = 16 // 16 MiB
+
+	DefaultBadgerDBCache      = 256 // 256 MiB
+	DefaultBadgerDBSyncWrites = false
+)
+
+// ErrBadgerDBNotBuilt is returned when the binary is built without the badgerdb tag
+var ErrBadgerDBNotBuilt = errors.New("badgerdb is not supported, rebuild with the badgerdb tag")
+
+type BadgerDBBuilder interface {
+	// set block cache size
+	SetCacheSize(int) BadgerDBBuilder
+
+	// set sync writes, every write is synced to the disk when set
+	SetSyncWrites(bool) BadgerDBBuilder
+
+	// set read only. The read-only database takes a shared directory lock, so it
+	// could be opened by several readers at once, but not while a writer holds it
+	SetReadOnly(bool) BadgerDBBuilder
+
+	// build the storage
+	Build() (KVBatchStorage, error)
+}
+
+type badgerBuilder struct {
+	logger     hclog.Logger
+	path       string
+	cacheSize  int // MiB
+	syncWrites bool
+	readOnly   bool
+}
+
+func (builder *badgerBuilder) SetCacheSize(cacheSize int) BadgerDBBuilder {
+	builder.cacheSize = max(cacheSize, minBadgerDBCache)
+
+	builder.logger.Info("badgerdb",
+		"BlockCacheSize", fmt.Sprintf("%d Mib", builder.cacheSize),
+	)
+
+	return builder
+}
+
+func (builder *badgerBuilder) SetSyncWrites(syncWrites bool) BadgerDBBuilder {
+	builder.syncWrites = syncWrites
+
+	builder.logger.Info("badgerdb",
+		"SyncWrites", syncWrites,
+	)
+
+	return builder
+}
+
+func (builder *badgerBuilder) SetReadOnly(readOnly bool) BadgerDBBuilder {
+	builder.readOnly = readOnly
+
+	builder.logger.Info("badgerdb",
+		"ReadOnly", readOnly,
+	)
+
+	return builder
+}
+
+func (builder *badgerBuilder) Build() (KVBatchStorage, error) {
+	return openBadgerDB(builder)
+}
+
+// NewBadgerDBBuilder creates the new badger storage builder. The storage is only
+// supported by the binaries built with the badgerdb tag.
+func NewBadgerDBBuilder(logger hclog.Logger, path string) BadgerDBBuilder {
+	return &badgerBuilder{
+		logger:     logger,
+		path:       path,
+		cacheSize:  DefaultBadgerDBCache,
+		syncWrites: DefaultBadgerDBSyncWrites,
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !badgerdb
+// +build !badgerdb
+
+package kvdb
+
+func openBadgerDB(*badgerBuilder) (KVBatchStorage, error) {
+	return nil, ErrBadgerDBNotBuilt
+}
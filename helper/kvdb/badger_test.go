@@ -0,0 +1,124 @@
+//go:build badgerdb
+// +build badgerdb
+
+package kvdb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestBadgerDB(t *testing.T) KVBatchStorage {
+	t.Helper()
+
+	tempDir, err := os.MkdirTemp("", "badgerdb-")
+	assert.NoError(t, err)
+
+	db, err := NewBadgerDBBuilder(
+		hclog.NewNullLogger(),
+		tempDir,
+	).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(tempDir)
+	})
+
+	return db
+}
+
+func TestBadgerDB(t *testing.T) {
+	t.Parallel()
+
+	t.Run("test KVStorage Get/Set/Delete", func(t *testing.T) {
+		t.Parallel()
+
+		db := createTestBadgerDB(t)
+
+		assert.NoError(t, db.Set([]byte("hello"), []byte("world")))
+
+		v, exist, err := db.Get([]byte("hello"))
+		assert.NoError(t, err)
+		assert.True(t, exist)
+		assert.Equal(t, []byte("world"), v)
+
+		assert.NoError(t, db.Delete([]byte("hello")))
+
+		_, exist, err = db.Get([]byte("hello"))
+		assert.NoError(t, err)
+		assert.False(t, exist)
+	})
+
+	t.Run("test KVStorage Batch Write", func(t *testing.T) {
+		t.Parallel()
+
+		db := createTestBadgerDB(t)
+
+		batch := db.Batch()
+		key := []byte("a")
+
+		for _, c := range []byte("abc") {
+			// reuse the slice, the batch should keep its own copy
+			key[0] = c
+			batch.Set(key, []byte{c})
+		}
+
+		assert.NoError(t, batch.Write())
+
+		for _, c := range []byte("abc") {
+			v, exist, err := db.Get([]byte{c})
+			assert.NoError(t, err)
+			assert.True(t, exist)
+			assert.Equal(t, []byte{c}, v)
+		}
+	})
+
+	t.Run("test KVStorage Iterator", func(t *testing.T) {
+		t.Parallel()
+
+		db := createTestBadgerDB(t)
+
+		for _, c := range []byte("abcde") {
+			assert.NoError(t, db.Set([]byte{c}, []byte{c}))
+		}
+
+		iter := db.Iterator(&KVIteratorRange{Start: []byte("b"), Limit: []byte("e")})
+		defer iter.Release()
+
+		keys := func(move func() bool) string {
+			s := ""
+			for move() {
+				s += string(iter.Key())
+			}
+
+			return s
+		}
+
+		assert.Equal(t, "bcd", keys(iter.Next))
+		assert.Equal(t, "dcb", keys(iter.Prev))
+
+		assert.True(t, iter.Last())
+		assert.Equal(t, []byte("d"), iter.Key())
+
+		// switch the direction in the middle
+		assert.True(t, iter.Prev())
+		assert.Equal(t, []byte("c"), iter.Key())
+		assert.True(t, iter.Next())
+		assert.Equal(t, []byte("d"), iter.Key())
+
+		assert.True(t, iter.Seek([]byte("a")))
+		assert.Equal(t, []byte("b"), iter.Key())
+		assert.False(t, iter.Seek([]byte("e")))
+		assert.Nil(t, iter.Key())
+
+		assert.True(t, iter.First())
+		assert.Equal(t, []byte("b"), iter.Value())
+		assert.NoError(t, iter.Error())
+	})
+}
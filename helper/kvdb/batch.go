@@ -0,0 +1,62 @@
+package kvdb
+
+// autoFlushBatch writes the queued pairs once their size reaches the ideal batch
+// size, so that the large writes neither hold all the pairs in memory nor write
+// them one by one
+type autoFlushBatch struct {
+	KVBatch
+
+	idealSize int
+	err       error // the first error of the automatic writes
+}
+
+// NewAutoFlushBatch returns the batch flushing itself once the size of the queued
+// pairs reaches idealSize, IdealBatchSize is used if it is not positive. The
+// errors of the automatic flushes are returned by Write.
+func NewAutoFlushBatch(batch KVBatch, idealSize int) KVBatch {
+	if idealSize <= 0 {
+		idealSize = IdealBatchSize
+	}
+
+	return &autoFlushBatch{
+		KVBatch:   batch,
+		idealSize: idealSize,
+	}
+}
+
+func (b *autoFlushBatch) Set(k, v []byte) {
+	b.KVBatch.Set(k, v)
+	b.flushIfFull()
+}
+
+func (b *autoFlushBatch) Delete(k []byte) {
+	b.KVBatch.Delete(k)
+	b.flushIfFull()
+}
+
+func (b *autoFlushBatch) flushIfFull() {
+	if b.err != nil || b.KVBatch.ValueSize() < b.idealSize {
+		return
+	}
+
+	if err := b.KVBatch.Write(); err != nil {
+		b.err = err
+
+		return
+	}
+
+	b.KVBatch.Reset()
+}
+
+func (b *autoFlushBatch) Write() error {
+	if b.err != nil {
+		return b.err
+	}
+
+	return b.KVBatch.Write()
+}
+
+func (b *autoFlushBatch) Reset() {
+	b.KVBatch.Reset()
+	b.err = nil
+}
@@ -0,0 +1,85 @@
+package kvdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoFlushBatch(t *testing.T) {
+	t.Parallel()
+
+	db := createTestDB(t)
+	defer db.Close()
+
+	assert.NoError(t, db.Set([]byte("d"), []byte("v")))
+
+	// flushed on every 2 pairs
+	batch := NewAutoFlushBatch(db.Batch(), 8)
+
+	batch.Set([]byte("k1"), []byte("v1"))
+	assert.Equal(t, 4, batch.ValueSize())
+
+	_, found, err := db.Get([]byte("k1"))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	batch.Set([]byte("k2"), []byte("v2"))
+	assert.Equal(t, 0, batch.ValueSize())
+
+	_, found, err = db.Get([]byte("k1"))
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	batch.Delete([]byte("d"))
+	batch.Set([]byte("k3"), []byte("v3"))
+
+	_, found, err = db.Get([]byte("k3"))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, batch.Write())
+
+	_, found, err = db.Get([]byte("k3"))
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	_, found, err = db.Get([]byte("d"))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// the dropped pairs are never written
+	batch.Reset()
+	batch.Set([]byte("k4"), []byte("v"))
+	batch.Reset()
+
+	assert.NoError(t, batch.Write())
+
+	_, found, err = db.Get([]byte("k4"))
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestTableBatch_Delete(t *testing.T) {
+	t.Parallel()
+
+	db := createTestDB(t)
+	defer db.Close()
+
+	a := NewTable(NewMeteredStorage(db, NilMetrics()), "a")
+	assert.NoError(t, a.Set([]byte("1"), []byte("a1")))
+
+	batch := a.Batch()
+	batch.Delete([]byte("1"))
+	batch.Set([]byte("2"), []byte("a2"))
+	assert.Equal(t, len("a1")+len("a2a2"), batch.ValueSize())
+	assert.NoError(t, batch.Write())
+
+	_, found, err := a.Get([]byte("1"))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, found, err = db.Get([]byte("a2"))
+	assert.NoError(t, err)
+	assert.True(t, found)
+}
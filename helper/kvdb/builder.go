@@ -2,6 +2,7 @@ package kvdb
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/syndtr/goleveldb/leveldb"
@@ -17,12 +18,18 @@ const (
 	// minLevelDBHandles is the minimum number of files handles to leveldb open files
 	minLevelDBHandles = 16
 
+	// reservedFileHandles is the number of file handles left to the network and logs
+	reservedFileHandles = 512
+
 	DefaultLevelDBCache               = 1024 // 1 GiB
 	DefaultLevelDBHandles             = 512  // files handles to leveldb open files
 	DefaultLevelDBBloomKeyBits        = 2048 // bloom filter bits (256 bytes)
 	DefaultLevelDBCompactionTableSize = 4    // 4  MiB
 	DefaultLevelDBCompactionTotalSize = 40   // 40 MiB
 	DefaultLevelDBNoSync              = false
+
+	// DefaultLevelDBSlowOpThreshold disables the slow operation logging
+	DefaultLevelDBSlowOpThreshold = time.Duration(0)
 )
 
 func max(a, b int) int {
@@ -52,14 +59,32 @@ type LevelDBBuilder interface {
 	// set no sync
 	SetNoSync(bool) LevelDBBuilder
 
+	// set read only. Build takes a shared file lock then, so the database could be
+	// built by several readers at once, but not while a writer like a running node
+	// holds it. OpenReadOnly attaches to the database of a running node.
+	SetReadOnly(bool) LevelDBBuilder
+
+	// set metrics, the storage is metered if set
+	SetMetrics(*Metrics) LevelDBBuilder
+
+	// set the threshold of the operations logged as slow, disabled if zero
+	SetSlowOpThreshold(time.Duration) LevelDBBuilder
+
 	// build the storage
 	Build() (KVBatchStorage, error)
+
+	// open the storage read-only without taking the file lock, so that it could
+	// attach to a database in use by a live node
+	OpenReadOnly() (KVBatchStorage, error)
 }
 
 type leveldbBuilder struct {
 	logger  hclog.Logger
 	path    string
 	options *opt.Options
+	metrics *Metrics
+
+	slowOpThreshold time.Duration
 }
 
 func (builder *leveldbBuilder) SetCacheSize(cacheSize int) LevelDBBuilder {
@@ -75,7 +100,19 @@ func (builder *leveldbBuilder) SetCacheSize(cacheSize int) LevelDBBuilder {
 }
 
 func (builder *leveldbBuilder) SetHandles(handles int) LevelDBBuilder {
-	builder.options.OpenFilesCacheCapacity = max(handles, minLevelDBHandles)
+	handles = max(handles, minLevelDBHandles)
+
+	// the process limit differs among the platforms, raise it if possible
+	allowed, err := raiseFileHandles(handles + reservedFileHandles)
+	if err != nil {
+		builder.logger.Warn("failed to check file handles limit", "err", err)
+	} else if handles > allowed-reservedFileHandles {
+		handles = max(allowed-reservedFileHandles, minLevelDBHandles)
+
+		builder.logger.Warn("leveldb handles exceed the process limit", "limit", allowed, "handles", handles)
+	}
+
+	builder.options.OpenFilesCacheCapacity = handles
 
 	builder.logger.Info("leveldb",
 		"OpenFilesCacheCapacity", builder.options.OpenFilesCacheCapacity,
@@ -126,13 +163,70 @@ func (builder *leveldbBuilder) SetNoSync(noSync bool) LevelDBBuilder {
 	return builder
 }
 
+func (builder *leveldbBuilder) SetReadOnly(readOnly bool) LevelDBBuilder {
+	builder.options.ReadOnly = readOnly
+
+	builder.logger.Info("leveldb",
+		"ReadOnly", readOnly,
+	)
+
+	return builder
+}
+
+func (builder *leveldbBuilder) SetMetrics(metrics *Metrics) LevelDBBuilder {
+	builder.metrics = metrics
+
+	return builder
+}
+
+func (builder *leveldbBuilder) SetSlowOpThreshold(threshold time.Duration) LevelDBBuilder {
+	builder.slowOpThreshold = threshold
+
+	builder.logger.Info("leveldb",
+		"SlowOpThreshold", threshold,
+	)
+
+	return builder
+}
+
 func (builder *leveldbBuilder) Build() (KVBatchStorage, error) {
 	db, err := leveldb.OpenFile(builder.path, builder.options)
 	if err != nil {
 		return nil, err
 	}
 
-	return &levelDBKV{db: db}, nil
+	return builder.metered(&levelDBKV{db: db}), nil
+}
+
+func (builder *leveldbBuilder) metered(db KVBatchStorage) KVBatchStorage {
+	if builder.metrics == nil && builder.slowOpThreshold <= 0 {
+		return db
+	}
+
+	metrics := builder.metrics
+	if metrics == nil {
+		metrics = NilMetrics()
+	}
+
+	logger := builder.logger.Named("leveldb").With("path", builder.path)
+
+	return newMeteredStorage(db, metrics, logger, builder.slowOpThreshold)
+}
+
+// OpenReadOnly opens the database as of the time it is called, the writes of the
+// live node afterwards are invisible. Reading might fail once the live node compacts
+// the tables away, reopen the storage then.
+func (builder *leveldbBuilder) OpenReadOnly() (KVBatchStorage, error) {
+	options := *builder.options
+	options.ReadOnly = true
+	options.ErrorIfMissing = true
+
+	db, err := leveldb.Open(&readOnlyFileStorage{path: builder.path}, &options)
+	if err != nil {
+		return nil, err
+	}
+
+	return builder.metered(&levelDBReadOnlyKV{levelDBKV{db: db}}), nil
 }
 
 // NewBuilder creates the new leveldb storage builder
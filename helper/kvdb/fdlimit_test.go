@@ -0,0 +1,18 @@
+package kvdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRaiseFileHandles(t *testing.T) {
+	allowed, err := raiseFileHandles(minLevelDBHandles)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, allowed, minLevelDBHandles)
+
+	// the allowed number never shrinks
+	again, err := raiseFileHandles(minLevelDBHandles)
+	assert.NoError(t, err)
+	assert.Equal(t, allowed, again)
+}
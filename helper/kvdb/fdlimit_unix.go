@@ -0,0 +1,45 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package kvdb
+
+import (
+	"math"
+	"syscall"
+)
+
+// raiseFileHandles tries to raise the soft limit of the process open files to the
+// wanted number, and returns the allowed number of the open files.
+func raiseFileHandles(wanted int) (int, error) {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return 0, err
+	}
+
+	if limit.Cur >= uint64(wanted) {
+		return rlimitToInt(limit.Cur), nil
+	}
+
+	raised := limit
+	raised.Cur = uint64(wanted)
+
+	if raised.Cur > raised.Max {
+		raised.Cur = raised.Max
+	}
+
+	// darwin might refuse the hard limit, keep the current one then
+	if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &raised); err != nil {
+		return rlimitToInt(limit.Cur), nil
+	}
+
+	return rlimitToInt(raised.Cur), nil
+}
+
+// rlimitToInt converts the limit, which might be infinity
+func rlimitToInt(limit uint64) int {
+	if limit > math.MaxInt32 {
+		return math.MaxInt32
+	}
+
+	return int(limit)
+}
@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package kvdb
+
+// windowsMaxFileHandles is the open files allowed on windows, which has no
+// per-process limit like the POSIX ones
+const windowsMaxFileHandles = 16384
+
+// raiseFileHandles returns the allowed number of the open files
+func raiseFileHandles(wanted int) (int, error) {
+	if wanted > windowsMaxFileHandles {
+		return windowsMaxFileHandles, nil
+	}
+
+	return wanted, nil
+}
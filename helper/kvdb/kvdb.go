@@ -1,8 +1,26 @@
 package kvdb
 
+import "errors"
+
+// ErrCompactNotSupported is returned when compacting a storage that compacts in background only
+var ErrCompactNotSupported = errors.New("storage does not support compaction on demand")
+
+// ErrPropertyNotSupported is returned when querying the property of a storage that reports none
+var ErrPropertyNotSupported = errors.New("storage does not support property queries")
+
+// IdealBatchSize is the default size of the pairs queued in a batch before it is
+// flushed automatically, see NewAutoFlushBatch
+const IdealBatchSize = 100 * 1024
+
 type KVBatch interface {
 	Set(k, v []byte)
+	Delete(k []byte)
+	// ValueSize returns the size of the keys and values queued
+	ValueSize() int
+	// Write writes the queued pairs, the batch is kept until reset
 	Write() error
+	// Reset drops the queued pairs, so that the batch could be reused
+	Reset()
 }
 
 type KVIteratorRange struct {
@@ -61,10 +79,32 @@ type KVIterator interface {
 type KVStorage interface {
 	Set(k, v []byte) error
 	Get(k []byte) ([]byte, bool, error)
+	Delete(k []byte) error
 
 	Close() error
 }
 
+// KVCompactionStats is implemented by the storages compacting the data in background
+type KVCompactionStats interface {
+	// CompactionBacklog returns the number of the level 0 tables waiting for compaction,
+	// and whether the writes are paused by the compaction
+	CompactionBacklog() (int, bool)
+}
+
+// KVCompacter is implemented by the storages compacting the data on demand
+type KVCompacter interface {
+	// Compact compacts the keys within [start, limit), nil start and limit stand for
+	// the first and the last key of the storage. It reclaims the space of the deleted
+	// and overwritten pairs, and blocks until done.
+	Compact(start, limit []byte) error
+}
+
+// KVProperties is implemented by the storages reporting their internal properties
+type KVProperties interface {
+	// Property returns the named property of the storage, like "leveldb.stats"
+	Property(name string) (string, error)
+}
+
 // KVBatchStorage is a batch write for leveldb
 type KVBatchStorage interface {
 	KVStorage
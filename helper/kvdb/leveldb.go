@@ -2,6 +2,8 @@ package kvdb
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/util"
@@ -10,16 +12,32 @@ import (
 type levelBatch struct {
 	db    *leveldb.DB
 	batch *leveldb.Batch
+	size  int
 }
 
 func (b *levelBatch) Set(k, v []byte) {
 	b.batch.Put(k, v)
+	b.size += len(k) + len(v)
+}
+
+func (b *levelBatch) Delete(k []byte) {
+	b.batch.Delete(k)
+	b.size += len(k)
+}
+
+func (b *levelBatch) ValueSize() int {
+	return b.size
 }
 
 func (b *levelBatch) Write() error {
 	return b.db.Write(b.batch, nil)
 }
 
+func (b *levelBatch) Reset() {
+	b.batch.Reset()
+	b.size = 0
+}
+
 // levelDBKV is the leveldb implementation of the kv storage
 type levelDBKV struct {
 	db *leveldb.DB
@@ -61,6 +79,53 @@ func (kv *levelDBKV) Get(p []byte) ([]byte, bool, error) {
 	return data, true, nil
 }
 
+// Delete removes the key-value pair in leveldb storage
+func (kv *levelDBKV) Delete(p []byte) error {
+	return kv.db.Delete(p, nil)
+}
+
+// CompactionBacklog returns the number of the level 0 tables, and whether the writes are paused
+func (kv *levelDBKV) CompactionBacklog() (int, bool) {
+	var stats leveldb.DBStats
+
+	if err := kv.db.Stats(&stats); err != nil || len(stats.LevelTablesCounts) == 0 {
+		return 0, false
+	}
+
+	return stats.LevelTablesCounts[0], stats.WritePaused
+}
+
+// Compact compacts the keys within [start, limit)
+func (kv *levelDBKV) Compact(start, limit []byte) error {
+	return kv.db.CompactRange(util.Range{Start: start, Limit: limit})
+}
+
+// Property returns the leveldb property, the "leveldb." prefix could be omitted
+func (kv *levelDBKV) Property(name string) (string, error) {
+	if !strings.HasPrefix(name, "leveldb.") {
+		name = "leveldb." + name
+	}
+
+	return kv.db.GetProperty(name)
+}
+
+// compactionTime returns the total time spent compacting the levels, and the total
+// time of the writes delayed
+func (kv *levelDBKV) compactionTime() (time.Duration, time.Duration, error) {
+	var stats leveldb.DBStats
+
+	if err := kv.db.Stats(&stats); err != nil {
+		return 0, 0, err
+	}
+
+	var compaction time.Duration
+	for _, d := range stats.LevelDurations {
+		compaction += d
+	}
+
+	return compaction, stats.WriteDelayDuration, nil
+}
+
 // Close closes the leveldb storage instance
 func (kv *levelDBKV) Close() error {
 	return kv.db.Close()
@@ -0,0 +1,199 @@
+package kvdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+)
+
+const (
+	// readOnlyMetaRetries is the number of retries reading the manifest, which
+	// might be replaced by the live node in the meantime
+	readOnlyMetaRetries = 5
+	readOnlyMetaBackoff = 10 * time.Millisecond
+)
+
+var errLevelDBCorruptedCurrent = errors.New("leveldb: corrupted CURRENT file")
+
+type noopLocker struct{}
+
+func (noopLocker) Unlock() {}
+
+// readOnlyFileStorage is a leveldb file storage which neither takes the file lock,
+// nor writes anything to the database directory. So it could be opened while the
+// database is in use by a live node.
+type readOnlyFileStorage struct {
+	path string
+}
+
+func (fs *readOnlyFileStorage) Lock() (storage.Locker, error) {
+	return noopLocker{}, nil
+}
+
+func (fs *readOnlyFileStorage) Log(string) {}
+
+func (fs *readOnlyFileStorage) SetMeta(storage.FileDesc) error {
+	return leveldb.ErrReadOnly
+}
+
+func (fs *readOnlyFileStorage) GetMeta() (storage.FileDesc, error) {
+	var err error
+
+	for i := 0; i < readOnlyMetaRetries; i++ {
+		var fd storage.FileDesc
+
+		fd, err = fs.readCurrent()
+		if err == nil {
+			return fd, nil
+		} else if !os.IsNotExist(err) {
+			return storage.FileDesc{}, err
+		}
+
+		time.Sleep(readOnlyMetaBackoff)
+	}
+
+	return storage.FileDesc{}, err
+}
+
+// readCurrent reads the manifest file pointed by the CURRENT file
+func (fs *readOnlyFileStorage) readCurrent() (storage.FileDesc, error) {
+	b, err := os.ReadFile(filepath.Join(fs.path, "CURRENT"))
+	if err != nil {
+		return storage.FileDesc{}, err
+	}
+
+	fd, ok := parseLevelDBFileName(string(bytes.TrimSuffix(b, []byte("\n"))))
+	if !ok || fd.Type != storage.TypeManifest {
+		return storage.FileDesc{}, errLevelDBCorruptedCurrent
+	}
+
+	// the manifest might be replaced after reading the CURRENT file
+	if _, err := os.Stat(filepath.Join(fs.path, levelDBFileName(fd))); err != nil {
+		return storage.FileDesc{}, err
+	}
+
+	return fd, nil
+}
+
+func (fs *readOnlyFileStorage) List(ft storage.FileType) ([]storage.FileDesc, error) {
+	entries, err := os.ReadDir(fs.path)
+	if err != nil {
+		return nil, err
+	}
+
+	fds := []storage.FileDesc{}
+
+	for _, entry := range entries {
+		if fd, ok := parseLevelDBFileName(entry.Name()); ok && fd.Type&ft != 0 {
+			fds = append(fds, fd)
+		}
+	}
+
+	return fds, nil
+}
+
+func (fs *readOnlyFileStorage) Open(fd storage.FileDesc) (storage.Reader, error) {
+	if !storage.FileDescOk(fd) {
+		return nil, storage.ErrInvalidFile
+	}
+
+	f, err := os.Open(filepath.Join(fs.path, levelDBFileName(fd)))
+	if os.IsNotExist(err) && fd.Type == storage.TypeTable {
+		// tables of the old leveldb versions
+		f, err = os.Open(filepath.Join(fs.path, fmt.Sprintf("%06d.sst", fd.Num)))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (fs *readOnlyFileStorage) Create(storage.FileDesc) (storage.Writer, error) {
+	return nil, leveldb.ErrReadOnly
+}
+
+func (fs *readOnlyFileStorage) Remove(storage.FileDesc) error {
+	return leveldb.ErrReadOnly
+}
+
+func (fs *readOnlyFileStorage) Rename(storage.FileDesc, storage.FileDesc) error {
+	return leveldb.ErrReadOnly
+}
+
+func (fs *readOnlyFileStorage) Close() error {
+	return nil
+}
+
+func levelDBFileName(fd storage.FileDesc) string {
+	switch fd.Type {
+	case storage.TypeManifest:
+		return fmt.Sprintf("MANIFEST-%06d", fd.Num)
+	case storage.TypeJournal:
+		return fmt.Sprintf("%06d.log", fd.Num)
+	case storage.TypeTable:
+		return fmt.Sprintf("%06d.ldb", fd.Num)
+	default:
+		return fmt.Sprintf("%06d.tmp", fd.Num)
+	}
+}
+
+func parseLevelDBFileName(name string) (storage.FileDesc, bool) {
+	var (
+		fd   storage.FileDesc
+		tail string
+	)
+
+	if strings.HasPrefix(name, "MANIFEST-") {
+		if _, err := fmt.Sscanf(name, "MANIFEST-%d", &fd.Num); err != nil {
+			return fd, false
+		}
+
+		fd.Type = storage.TypeManifest
+
+		return fd, true
+	}
+
+	if _, err := fmt.Sscanf(name, "%d.%s", &fd.Num, &tail); err != nil {
+		return fd, false
+	}
+
+	switch tail {
+	case "log":
+		fd.Type = storage.TypeJournal
+	case "ldb", "sst":
+		fd.Type = storage.TypeTable
+	case "tmp":
+		fd.Type = storage.TypeTemp
+	default:
+		return fd, false
+	}
+
+	return fd, true
+}
+
+// levelDBReadOnlyKV is the leveldb attached read-only, whose tables might be
+// compacted away by the live node
+type levelDBReadOnlyKV struct {
+	levelDBKV
+}
+
+// Get retrieves the key-value pair, the missing tables are reported as an error
+func (kv *levelDBReadOnlyKV) Get(p []byte) ([]byte, bool, error) {
+	data, err := kv.db.Get(p, nil)
+	if errors.Is(err, leveldb.ErrNotFound) || errors.Is(err, leveldb.ErrClosed) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
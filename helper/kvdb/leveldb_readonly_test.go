@@ -0,0 +1,74 @@
+package kvdb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func TestLevelDBOpenReadOnly(t *testing.T) {
+	path, err := os.MkdirTemp("", "leveldb-")
+	assert.NoError(t, err)
+
+	defer os.RemoveAll(path)
+
+	builder := NewLevelDBBuilder(hclog.NewNullLogger(), path)
+
+	// missing database
+	_, err = builder.OpenReadOnly()
+	assert.Error(t, err)
+
+	live, err := builder.Build()
+	assert.NoError(t, err)
+
+	defer live.Close()
+
+	assert.NoError(t, live.Set([]byte("hello"), []byte("world")))
+
+	// the live database holds the file lock
+	db, err := builder.OpenReadOnly()
+	assert.NoError(t, err)
+
+	defer db.Close()
+
+	v, ok, err := db.Get([]byte("hello"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("world"), v)
+
+	assert.ErrorIs(t, db.Set([]byte("hello"), []byte("dogechain")), leveldb.ErrReadOnly)
+	assert.ErrorIs(t, db.Delete([]byte("hello")), leveldb.ErrReadOnly)
+
+	// the live writes afterwards are invisible
+	assert.NoError(t, live.Set([]byte("foo"), []byte("bar")))
+
+	_, ok, err = db.Get([]byte("foo"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// nothing is written by the reader
+	v, ok, err = live.Get([]byte("hello"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("world"), v)
+}
+
+func TestParseLevelDBFileName(t *testing.T) {
+	for _, name := range []string{"MANIFEST-000012", "000034.log", "000056.ldb", "000078.tmp"} {
+		fd, ok := parseLevelDBFileName(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, name, levelDBFileName(fd))
+	}
+
+	fd, ok := parseLevelDBFileName("000090.sst")
+	assert.True(t, ok)
+	assert.Equal(t, "000090.ldb", levelDBFileName(fd))
+
+	for _, name := range []string{"CURRENT", "LOCK", "LOG", "LOG.old", "000012.txt"} {
+		_, ok := parseLevelDBFileName(name)
+		assert.False(t, ok, name)
+	}
+}
@@ -15,7 +15,7 @@ import (
 func createTestDB(t *testing.T) KVBatchStorage {
 	t.Helper()
 
-	tempDir, err := ioutil.TempDir("/tmp", "leveldb-")
+	tempDir, err := ioutil.TempDir("", "leveldb-")
 	assert.NoError(t, err)
 
 	db, err := NewLevelDBBuilder(
@@ -158,4 +158,26 @@ func TestLevelDB(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("test KVStorage Property", func(t *testing.T) {
+		t.Parallel()
+
+		db := createTestDB(t)
+		defer db.Close()
+
+		props, ok := db.(KVProperties)
+		assert.True(t, ok)
+
+		stats, err := props.Property("leveldb.stats")
+		assert.NoError(t, err)
+		assert.Contains(t, stats, "Compactions")
+
+		// the prefix could be omitted
+		count, err := props.Property("num-files-at-level0")
+		assert.NoError(t, err)
+		assert.Equal(t, "0", count)
+
+		_, err = props.Property("unknown")
+		assert.Error(t, err)
+	})
 }
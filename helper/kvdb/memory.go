@@ -0,0 +1,18 @@
+package kvdb
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+)
+
+// NewMemoryDB returns a leveldb storage kept in memory, so that the tests and the
+// embedders run without a data directory. It is safe for concurrent use, and its
+// data is dropped on Close.
+func NewMemoryDB() (KVBatchStorage, error) {
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &levelDBKV{db: db}, nil
+}
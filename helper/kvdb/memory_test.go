@@ -0,0 +1,56 @@
+package kvdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryDB(t *testing.T) {
+	t.Parallel()
+
+	db, err := NewMemoryDB()
+	assert.NoError(t, err)
+
+	assert.NoError(t, db.Set([]byte("b"), []byte("2")))
+
+	batch := db.Batch()
+	batch.Set([]byte("a"), []byte("1"))
+	batch.Set([]byte("c"), []byte("3"))
+	batch.Delete([]byte("b"))
+
+	// not written until the batch is
+	_, found, err := db.Get([]byte("a"))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, batch.Write())
+
+	v, found, err := db.Get([]byte("a"))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("1"), v)
+
+	iter := db.Iterator(nil)
+
+	keys := []string{}
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+
+	iter.Release()
+
+	assert.Equal(t, []string{"a", "c"}, keys)
+
+	assert.NoError(t, db.Close())
+
+	// the memory storages are not shared
+	other, err := NewMemoryDB()
+	assert.NoError(t, err)
+
+	defer other.Close()
+
+	_, found, err = other.Get([]byte("a"))
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
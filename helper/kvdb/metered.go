@@ -0,0 +1,323 @@
+package kvdb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/hashicorp/go-hclog"
+)
+
+// meteredPollInterval is the interval polling the compaction stats of the storage
+const meteredPollInterval = 3 * time.Second
+
+// kvCompactionTimer is implemented by the storages tracking the compaction time
+type kvCompactionTimer interface {
+	// compactionTime returns the total time spent compacting, and the total time
+	// of the writes delayed by the compaction
+	compactionTime() (time.Duration, time.Duration, error)
+}
+
+// slowOpLog logs and counts the operations slower than the threshold
+type slowOpLog struct {
+	logger    hclog.Logger
+	threshold time.Duration // disabled if zero
+	metrics   *Metrics
+}
+
+// since returns the elapsed time of the operation begun, it is logged with the key
+// operated if slow. The key is encoded only then, as it is on the hot path.
+func (l *slowOpLog) since(begin time.Time, op string, key []byte, args ...interface{}) time.Duration {
+	elapsed := time.Since(begin)
+
+	if l.threshold > 0 && elapsed >= l.threshold {
+		l.metrics.SlowOpInc()
+
+		logArgs := []interface{}{"op", op, "elapsed", elapsed}
+		if key != nil {
+			logArgs = append(logArgs, "key", hex.EncodeToHex(key))
+		}
+
+		l.logger.Warn("slow storage operation", append(logArgs, args...)...)
+	}
+
+	return elapsed
+}
+
+type meteredBatch struct {
+	batch   KVBatch
+	metrics *Metrics
+	slowLog *slowOpLog
+
+	pairs   int
+	size    int
+	deletes int
+}
+
+func (b *meteredBatch) Set(k, v []byte) {
+	b.batch.Set(k, v)
+
+	b.pairs++
+	b.size += len(k) + len(v)
+}
+
+func (b *meteredBatch) Delete(k []byte) {
+	b.batch.Delete(k)
+
+	b.deletes++
+}
+
+func (b *meteredBatch) ValueSize() int {
+	return b.batch.ValueSize()
+}
+
+func (b *meteredBatch) Reset() {
+	b.batch.Reset()
+
+	b.pairs, b.size, b.deletes = 0, 0, 0
+}
+
+func (b *meteredBatch) Write() error {
+	begin := time.Now()
+
+	if err := b.batch.Write(); err != nil {
+		return err
+	}
+
+	elapsed := b.slowLog.since(begin, "batch", nil, "pairs", b.pairs, "size", b.size, "deletes", b.deletes)
+
+	b.metrics.WriteObserve(b.pairs, b.size)
+	b.metrics.BatchObserve(b.pairs, b.size, elapsed.Seconds())
+	b.metrics.DeleteAdd(b.deletes)
+
+	return nil
+}
+
+// meteredIterator records the seeks and steps of the iterator
+type meteredIterator struct {
+	KVIterator
+
+	metrics *Metrics
+	slowLog *slowOpLog
+}
+
+func (it *meteredIterator) First() bool {
+	begin := time.Now()
+	ok := it.KVIterator.First()
+
+	it.metrics.IteratorSeekObserve(it.slowLog.since(begin, "iterator_first", nil).Seconds())
+
+	return ok
+}
+
+func (it *meteredIterator) Last() bool {
+	begin := time.Now()
+	ok := it.KVIterator.Last()
+
+	it.metrics.IteratorSeekObserve(it.slowLog.since(begin, "iterator_last", nil).Seconds())
+
+	return ok
+}
+
+func (it *meteredIterator) Seek(key []byte) bool {
+	begin := time.Now()
+	ok := it.KVIterator.Seek(key)
+
+	it.metrics.IteratorSeekObserve(it.slowLog.since(begin, "iterator_seek", key).Seconds())
+
+	return ok
+}
+
+func (it *meteredIterator) Next() bool {
+	begin := time.Now()
+	ok := it.KVIterator.Next()
+
+	it.metrics.IteratorNextObserve(it.slowLog.since(begin, "iterator_next", nil).Seconds())
+
+	return ok
+}
+
+func (it *meteredIterator) Prev() bool {
+	begin := time.Now()
+	ok := it.KVIterator.Prev()
+
+	it.metrics.IteratorNextObserve(it.slowLog.since(begin, "iterator_prev", nil).Seconds())
+
+	return ok
+}
+
+// meteredStorage records the reads, writes, iterations and compaction of the storage,
+// the operations slower than the threshold are logged
+type meteredStorage struct {
+	KVBatchStorage
+
+	metrics *Metrics
+	slowLog *slowOpLog
+
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMeteredStorage wraps the storage, recording its reads, writes and compaction
+// into the metrics until it is closed
+func NewMeteredStorage(db KVBatchStorage, metrics *Metrics) KVBatchStorage {
+	return newMeteredStorage(db, metrics, hclog.NewNullLogger(), 0)
+}
+
+// newMeteredStorage wraps the storage like NewMeteredStorage, and logs the operations
+// taking longer than the slow threshold, none is logged if it is zero
+func newMeteredStorage(
+	db KVBatchStorage,
+	metrics *Metrics,
+	logger hclog.Logger,
+	slowThreshold time.Duration,
+) KVBatchStorage {
+	m := &meteredStorage{
+		KVBatchStorage: db,
+		metrics:        metrics,
+		slowLog: &slowOpLog{
+			logger:    logger,
+			threshold: slowThreshold,
+			metrics:   metrics,
+		},
+		closeCh: make(chan struct{}),
+	}
+
+	go m.pollCompaction()
+
+	return m
+}
+
+func (m *meteredStorage) Get(k []byte) ([]byte, bool, error) {
+	begin := time.Now()
+	v, found, err := m.KVBatchStorage.Get(k)
+
+	m.slowLog.since(begin, "get", k)
+
+	if err == nil {
+		m.metrics.ReadObserve(len(v), found)
+	}
+
+	return v, found, err
+}
+
+func (m *meteredStorage) Set(k, v []byte) error {
+	begin := time.Now()
+	if err := m.KVBatchStorage.Set(k, v); err != nil {
+		return err
+	}
+
+	m.slowLog.since(begin, "set", k)
+
+	m.metrics.WriteObserve(1, len(k)+len(v))
+
+	return nil
+}
+
+func (m *meteredStorage) Delete(k []byte) error {
+	begin := time.Now()
+	if err := m.KVBatchStorage.Delete(k); err != nil {
+		return err
+	}
+
+	m.slowLog.since(begin, "delete", k)
+
+	m.metrics.DeleteInc()
+
+	return nil
+}
+
+func (m *meteredStorage) Batch() KVBatch {
+	return &meteredBatch{
+		batch:   m.KVBatchStorage.Batch(),
+		metrics: m.metrics,
+		slowLog: m.slowLog,
+	}
+}
+
+func (m *meteredStorage) Iterator(r *KVIteratorRange) KVIterator {
+	var start []byte
+	if r != nil {
+		start = r.Start
+	}
+
+	begin := time.Now()
+	iter := m.KVBatchStorage.Iterator(r)
+
+	m.metrics.IteratorOpenObserve(m.slowLog.since(begin, "iterator_open", start).Seconds())
+
+	return &meteredIterator{
+		KVIterator: iter,
+		metrics:    m.metrics,
+		slowLog:    m.slowLog,
+	}
+}
+
+// CompactionBacklog returns the compaction backlog of the storage, if it compacts in background
+func (m *meteredStorage) CompactionBacklog() (int, bool) {
+	if stats, ok := m.KVBatchStorage.(KVCompactionStats); ok {
+		return stats.CompactionBacklog()
+	}
+
+	return 0, false
+}
+
+// Compact compacts the keys within [start, limit) of the storage, if it compacts on demand
+func (m *meteredStorage) Compact(start, limit []byte) error {
+	if compacter, ok := m.KVBatchStorage.(KVCompacter); ok {
+		return compacter.Compact(start, limit)
+	}
+
+	return ErrCompactNotSupported
+}
+
+// Property returns the named property of the storage, if it reports any
+func (m *meteredStorage) Property(name string) (string, error) {
+	if props, ok := m.KVBatchStorage.(KVProperties); ok {
+		return props.Property(name)
+	}
+
+	return "", ErrPropertyNotSupported
+}
+
+func (m *meteredStorage) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.closeCh)
+	})
+
+	return m.KVBatchStorage.Close()
+}
+
+func (m *meteredStorage) pollCompaction() {
+	ticker := time.NewTicker(meteredPollInterval)
+	defer ticker.Stop()
+
+	var lastCompaction, lastWriteDelay time.Duration
+
+	for {
+		select {
+		case <-m.closeCh:
+			return
+		case <-ticker.C:
+		}
+
+		if _, ok := m.KVBatchStorage.(KVCompactionStats); ok {
+			m.metrics.SetCompactionBacklog(m.CompactionBacklog())
+		}
+
+		timer, ok := m.KVBatchStorage.(kvCompactionTimer)
+		if !ok {
+			continue
+		}
+
+		compaction, writeDelay, err := timer.compactionTime()
+		if err != nil {
+			continue
+		}
+
+		m.metrics.CompactionSecondsAdd((compaction - lastCompaction).Seconds())
+		m.metrics.WriteDelaySecondsAdd((writeDelay - lastWriteDelay).Seconds())
+
+		lastCompaction, lastWriteDelay = compaction, writeDelay
+	}
+}
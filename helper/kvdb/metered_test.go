@@ -0,0 +1,146 @@
+package kvdb
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeteredStorage(t *testing.T) {
+	t.Parallel()
+
+	metrics := GetPrometheusMetrics("test", "db", "metered")
+	db := NewMeteredStorage(createTestDB(t), metrics)
+
+	defer db.Close()
+
+	assert.NoError(t, db.Set([]byte("hello"), []byte("world")))
+
+	batch := db.Batch()
+	batch.Set([]byte("k1"), []byte("v1"))
+	batch.Set([]byte("k2"), []byte("v2"))
+	assert.NoError(t, batch.Write())
+
+	_, found, err := db.Get([]byte("hello"))
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	_, found, err = db.Get([]byte("missing"))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, db.Delete([]byte("k1")))
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.reads))
+	assert.Equal(t, float64(5), testutil.ToFloat64(metrics.readBytes))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.readMisses))
+	assert.Equal(t, float64(3), testutil.ToFloat64(metrics.writes))
+	assert.Equal(t, float64(18), testutil.ToFloat64(metrics.writeBytes))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.deletes))
+
+	_, ok := db.(KVCompactionStats)
+	assert.True(t, ok)
+}
+
+func TestMeteredStorage_NilMetrics(t *testing.T) {
+	t.Parallel()
+
+	db := NewMeteredStorage(createTestDB(t), NilMetrics())
+
+	assert.NoError(t, db.Set([]byte("hello"), []byte("world")))
+
+	v, found, err := db.Get([]byte("hello"))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("world"), v)
+
+	assert.NoError(t, db.Close())
+	// closing twice must not panic
+	assert.Error(t, db.Close())
+}
+
+func TestMeteredStorage_Iterator(t *testing.T) {
+	t.Parallel()
+
+	metrics := GetPrometheusMetrics("test", "db", "metered_iterator")
+	db := NewMeteredStorage(createTestDB(t), metrics)
+
+	defer db.Close()
+
+	for _, k := range []string{"a", "b", "c"} {
+		assert.NoError(t, db.Set([]byte(k), []byte(k)))
+	}
+
+	iter := db.Iterator(nil)
+	defer iter.Release()
+
+	assert.True(t, iter.Seek([]byte("b")))
+	assert.Equal(t, []byte("b"), iter.Key())
+	assert.True(t, iter.Next())
+	assert.False(t, iter.Next())
+	assert.True(t, iter.First())
+	assert.NoError(t, iter.Error())
+
+	assert.Equal(t, uint64(1), histogramCount(t, "test_kvdb_iterator_open_seconds", "metered_iterator"))
+	assert.Equal(t, uint64(2), histogramCount(t, "test_kvdb_iterator_seek_seconds", "metered_iterator"))
+	assert.Equal(t, uint64(2), histogramCount(t, "test_kvdb_iterator_next_seconds", "metered_iterator"))
+}
+
+// histogramCount returns the observations of the registered histogram of the db label
+func histogramCount(t *testing.T, name, db string) uint64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "db" && label.GetValue() == db {
+					return metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+
+	t.Fatalf("histogram %s of %s not found", name, db)
+
+	return 0
+}
+
+func TestMeteredStorage_SlowOp(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	logger := hclog.New(&hclog.LoggerOptions{Output: &buf})
+	metrics := GetPrometheusMetrics("test", "db", "metered_slow")
+
+	db := newMeteredStorage(createTestDB(t), metrics, logger, time.Nanosecond)
+	defer db.Close()
+
+	assert.NoError(t, db.Set([]byte("hello"), []byte("world")))
+
+	assert.Contains(t, buf.String(), "slow storage operation")
+	assert.Contains(t, buf.String(), "op=set")
+	assert.Contains(t, buf.String(), "key=0x68656c6c6f")
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.slowOps))
+
+	// nothing is logged once disabled
+	buf.Reset()
+
+	db = newMeteredStorage(createTestDB(t), NilMetrics(), logger, 0)
+	defer db.Close()
+
+	assert.NoError(t, db.Set([]byte("hello"), []byte("world")))
+	assert.Empty(t, buf.String())
+}
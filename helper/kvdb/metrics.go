@@ -0,0 +1,273 @@
+package kvdb
+
+import (
+	"github.com/dogechain-lab/dogechain/helper/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const subsystem = "kvdb"
+
+// iteratorBuckets spans 1µs to about 4s, the iterator steps are way faster than
+// the default buckets
+var iteratorBuckets = prometheus.ExponentialBuckets(1e-6, 4, 12)
+
+// Metrics represents the kv storage metrics
+type Metrics struct {
+	// Pairs read
+	reads prometheus.Counter
+	// Bytes of the values read
+	readBytes prometheus.Counter
+	// Keys read but missing
+	readMisses prometheus.Counter
+	// Pairs written, including the batched ones
+	writes prometheus.Counter
+	// Bytes of the keys and values written
+	writeBytes prometheus.Counter
+	// Keys deleted
+	deletes prometheus.Counter
+	// Pairs per batch
+	batchPairs prometheus.Histogram
+	// Bytes per batch
+	batchBytes prometheus.Histogram
+	// Batch write duration
+	batchWriteSeconds prometheus.Histogram
+	// Time spent compacting
+	compactionSeconds prometheus.Counter
+	// Time of the writes delayed by the compaction
+	writeDelaySeconds prometheus.Counter
+	// Level 0 tables waiting for compaction
+	level0Tables prometheus.Gauge
+	// Whether the writes are paused by the compaction
+	writePaused prometheus.Gauge
+	// Iterator open duration
+	iteratorOpenSeconds prometheus.Histogram
+	// Iterator seek duration, including the first and last
+	iteratorSeekSeconds prometheus.Histogram
+	// Iterator step duration, including the previous
+	iteratorNextSeconds prometheus.Histogram
+	// Operations slower than the slow log threshold
+	slowOps prometheus.Counter
+}
+
+func (m *Metrics) ReadObserve(size int, found bool) {
+	metrics.CounterInc(m.reads)
+
+	if found {
+		metrics.CounterAdd(m.readBytes, float64(size))
+	} else {
+		metrics.CounterInc(m.readMisses)
+	}
+}
+
+func (m *Metrics) WriteObserve(pairs int, size int) {
+	metrics.CounterAdd(m.writes, float64(pairs))
+	metrics.CounterAdd(m.writeBytes, float64(size))
+}
+
+func (m *Metrics) DeleteInc() {
+	metrics.CounterInc(m.deletes)
+}
+
+func (m *Metrics) DeleteAdd(n int) {
+	metrics.CounterAdd(m.deletes, float64(n))
+}
+
+func (m *Metrics) BatchObserve(pairs int, size int, seconds float64) {
+	metrics.HistogramObserve(m.batchPairs, float64(pairs))
+	metrics.HistogramObserve(m.batchBytes, float64(size))
+	metrics.HistogramObserve(m.batchWriteSeconds, seconds)
+}
+
+func (m *Metrics) CompactionSecondsAdd(v float64) {
+	metrics.CounterAdd(m.compactionSeconds, v)
+}
+
+func (m *Metrics) WriteDelaySecondsAdd(v float64) {
+	metrics.CounterAdd(m.writeDelaySeconds, v)
+}
+
+func (m *Metrics) IteratorOpenObserve(seconds float64) {
+	metrics.HistogramObserve(m.iteratorOpenSeconds, seconds)
+}
+
+func (m *Metrics) IteratorSeekObserve(seconds float64) {
+	metrics.HistogramObserve(m.iteratorSeekSeconds, seconds)
+}
+
+func (m *Metrics) IteratorNextObserve(seconds float64) {
+	metrics.HistogramObserve(m.iteratorNextSeconds, seconds)
+}
+
+func (m *Metrics) SlowOpInc() {
+	metrics.CounterInc(m.slowOps)
+}
+
+func (m *Metrics) SetCompactionBacklog(level0Tables int, writePaused bool) {
+	metrics.SetGauge(m.level0Tables, float64(level0Tables))
+
+	if writePaused {
+		metrics.SetGauge(m.writePaused, 1)
+	} else {
+		metrics.SetGauge(m.writePaused, 0)
+	}
+}
+
+// GetPrometheusMetrics return the kv storage metrics instance, the storages of the
+// same namespace are told apart by the labels
+func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics {
+	constLabels := metrics.ParseLables(labelsWithValues...)
+
+	m := &Metrics{
+		reads: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "reads",
+			Help:        "key-value pairs read",
+			ConstLabels: constLabels,
+		}),
+		readBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "read_bytes",
+			Help:        "bytes of the values read",
+			ConstLabels: constLabels,
+		}),
+		readMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "read_misses",
+			Help:        "keys read but missing",
+			ConstLabels: constLabels,
+		}),
+		writes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "writes",
+			Help:        "key-value pairs written, including the batched ones",
+			ConstLabels: constLabels,
+		}),
+		writeBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "write_bytes",
+			Help:        "bytes of the keys and values written",
+			ConstLabels: constLabels,
+		}),
+		deletes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "deletes",
+			Help:        "keys deleted",
+			ConstLabels: constLabels,
+		}),
+		batchPairs: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "batch_pairs",
+			Help:        "key-value pairs per batch",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(1, 4, 10),
+		}),
+		batchBytes: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "batch_bytes",
+			Help:        "bytes of the keys and values per batch",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(64, 4, 12),
+		}),
+		batchWriteSeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "batch_write_seconds",
+			Help:        "batch write time (seconds)",
+			ConstLabels: constLabels,
+		}),
+		compactionSeconds: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "compaction_seconds",
+			Help:        "time spent compacting (seconds)",
+			ConstLabels: constLabels,
+		}),
+		writeDelaySeconds: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "write_delay_seconds",
+			Help:        "time of the writes delayed by the compaction (seconds)",
+			ConstLabels: constLabels,
+		}),
+		level0Tables: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "level0_tables",
+			Help:        "level 0 tables waiting for compaction",
+			ConstLabels: constLabels,
+		}),
+		writePaused: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "write_paused",
+			Help:        "whether the writes are paused by the compaction",
+			ConstLabels: constLabels,
+		}),
+		iteratorOpenSeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "iterator_open_seconds",
+			Help:        "iterator open time (seconds)",
+			ConstLabels: constLabels,
+			Buckets:     iteratorBuckets,
+		}),
+		iteratorSeekSeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "iterator_seek_seconds",
+			Help:        "iterator seek time, including the first and last (seconds)",
+			ConstLabels: constLabels,
+			Buckets:     iteratorBuckets,
+		}),
+		iteratorNextSeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "iterator_next_seconds",
+			Help:        "iterator step time, including the previous (seconds)",
+			ConstLabels: constLabels,
+			Buckets:     iteratorBuckets,
+		}),
+		slowOps: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "slow_ops",
+			Help:        "operations slower than the slow log threshold",
+			ConstLabels: constLabels,
+		}),
+	}
+
+	prometheus.MustRegister(
+		m.reads,
+		m.readBytes,
+		m.readMisses,
+		m.writes,
+		m.writeBytes,
+		m.deletes,
+		m.batchPairs,
+		m.batchBytes,
+		m.batchWriteSeconds,
+		m.compactionSeconds,
+		m.writeDelaySeconds,
+		m.level0Tables,
+		m.writePaused,
+		m.iteratorOpenSeconds,
+		m.iteratorSeekSeconds,
+		m.iteratorNextSeconds,
+		m.slowOps,
+	)
+
+	return m
+}
+
+// NilMetrics will return the non operational kv storage metrics
+func NilMetrics() *Metrics {
+	return &Metrics{}
+}
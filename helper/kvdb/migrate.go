@@ -0,0 +1,131 @@
+package kvdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// DefaultMigrateBatchSize is the size of the pairs written in one batch when migrating
+const DefaultMigrateBatchSize = 4 * 1024 * 1024 // 4 MiB
+
+var ErrMigrateMismatch = errors.New("migrated storage mismatch")
+
+// MigrateProgress is called once a batch is written, with the last key of the batch
+// and the totals so far. The migration stops if it returns an error.
+type MigrateProgress func(last []byte, pairs, size uint64) error
+
+// Migrate copies the pairs of the source storage, from the start key on, to the
+// destination storage in batches. The migration could be resumed by the key next
+// to the last one reported.
+func Migrate(src, dst KVBatchStorage, start []byte, batchSize int, progress MigrateProgress) error {
+	iter := src.Iterator(&KVIteratorRange{Start: start})
+	defer iter.Release()
+
+	var (
+		batch       = dst.Batch()
+		batchPairs  int
+		batchBytes  int
+		pairs, size uint64
+		last        []byte
+	)
+
+	flush := func() error {
+		if batchPairs == 0 {
+			return nil
+		}
+
+		if err := batch.Write(); err != nil {
+			return err
+		}
+
+		pairs += uint64(batchPairs)
+		size += uint64(batchBytes)
+		batch, batchPairs, batchBytes = dst.Batch(), 0, 0
+
+		if progress == nil {
+			return nil
+		}
+
+		return progress(last, pairs, size)
+	}
+
+	for iter.Next() {
+		key, value := iter.Key(), iter.Value()
+
+		batch.Set(key, value)
+
+		// the iterator might reuse the slice
+		last = append(last[:0], key...)
+		batchPairs++
+		batchBytes += len(key) + len(value)
+
+		if batchBytes >= batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// MigrateNextKey returns the key to resume the migration after the last key written
+func MigrateNextKey(last []byte) []byte {
+	if last == nil {
+		return nil
+	}
+
+	return append(append([]byte{}, last...), 0)
+}
+
+// VerifyMigration compares the pairs of both storages one by one, and returns the
+// number of the pairs compared
+func VerifyMigration(src, dst KVBatchStorage) (uint64, error) {
+	srcIter := src.Iterator(nil)
+	defer srcIter.Release()
+
+	dstIter := dst.Iterator(nil)
+	defer dstIter.Release()
+
+	var pairs uint64
+
+	for {
+		srcNext, dstNext := srcIter.Next(), dstIter.Next()
+
+		if !srcNext || !dstNext {
+			if err := srcIter.Error(); err != nil {
+				return pairs, err
+			}
+
+			if err := dstIter.Error(); err != nil {
+				return pairs, err
+			}
+
+			if srcNext {
+				return pairs, fmt.Errorf("%w: key %x is missing", ErrMigrateMismatch, srcIter.Key())
+			} else if dstNext {
+				return pairs, fmt.Errorf("%w: key %x is unexpected", ErrMigrateMismatch, dstIter.Key())
+			}
+
+			return pairs, nil
+		}
+
+		switch bytes.Compare(srcIter.Key(), dstIter.Key()) {
+		case -1:
+			return pairs, fmt.Errorf("%w: key %x is missing", ErrMigrateMismatch, srcIter.Key())
+		case 1:
+			return pairs, fmt.Errorf("%w: key %x is unexpected", ErrMigrateMismatch, dstIter.Key())
+		}
+
+		if !bytes.Equal(srcIter.Value(), dstIter.Value()) {
+			return pairs, fmt.Errorf("%w: value of key %x differs", ErrMigrateMismatch, srcIter.Key())
+		}
+
+		pairs++
+	}
+}
@@ -0,0 +1,80 @@
+package kvdb
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	src := createTestDB(t)
+	defer src.Close()
+
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, src.Set([]byte(fmt.Sprintf("key-%03d", i)), []byte(fmt.Sprintf("value-%d", i))))
+	}
+
+	t.Run("migrate in batches", func(t *testing.T) {
+		dst := createTestDB(t)
+		defer dst.Close()
+
+		batches := 0
+
+		assert.NoError(t, Migrate(src, dst, nil, 100, func(last []byte, pairs, size uint64) error {
+			batches++
+
+			return nil
+		}))
+		assert.Greater(t, batches, 1)
+
+		pairs, err := VerifyMigration(src, dst)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(100), pairs)
+	})
+
+	t.Run("resume migration", func(t *testing.T) {
+		dst := createTestDB(t)
+		defer dst.Close()
+
+		errStop := errors.New("stop")
+
+		var last []byte
+
+		assert.ErrorIs(t, Migrate(src, dst, nil, 100, func(key []byte, pairs, size uint64) error {
+			last = append([]byte{}, key...)
+
+			return errStop
+		}), errStop)
+
+		_, err := VerifyMigration(src, dst)
+		assert.ErrorIs(t, err, ErrMigrateMismatch)
+
+		assert.NoError(t, Migrate(src, dst, MigrateNextKey(last), 100, nil))
+
+		pairs, err := VerifyMigration(src, dst)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(100), pairs)
+	})
+
+	t.Run("verify mismatch", func(t *testing.T) {
+		dst := createTestDB(t)
+		defer dst.Close()
+
+		assert.NoError(t, Migrate(src, dst, nil, DefaultMigrateBatchSize, nil))
+		assert.NoError(t, dst.Set([]byte("key-050"), []byte("changed")))
+
+		_, err := VerifyMigration(src, dst)
+		assert.ErrorIs(t, err, ErrMigrateMismatch)
+
+		assert.NoError(t, dst.Set([]byte("key-050"), []byte("value-50")))
+		assert.NoError(t, dst.Set([]byte("key-100"), []byte("value-100")))
+
+		pairs, err := VerifyMigration(src, dst)
+		assert.ErrorIs(t, err, ErrMigrateMismatch)
+		assert.Equal(t, uint64(100), pairs)
+	})
+}
@@ -0,0 +1,184 @@
+package kvdb
+
+// table is a key-value storage with all keys under a prefix of a shared database,
+// so that several subsystems could share one physical database with isolated
+// keyspaces. Wrap the table by NewMeteredStorage for the per-table stats.
+type table struct {
+	db     KVBatchStorage
+	prefix []byte
+}
+
+// NewTable returns the table of the keys with the prefix in the database. The
+// table does not own the database, closing the table leaves it open.
+func NewTable(db KVBatchStorage, prefix string) KVBatchStorage {
+	return &table{
+		db:     db,
+		prefix: []byte(prefix),
+	}
+}
+
+func (t *table) key(k []byte) []byte {
+	key := make([]byte, 0, len(t.prefix)+len(k))
+	key = append(key, t.prefix...)
+
+	return append(key, k...)
+}
+
+func (t *table) Set(k, v []byte) error {
+	return t.db.Set(t.key(k), v)
+}
+
+func (t *table) Get(k []byte) ([]byte, bool, error) {
+	return t.db.Get(t.key(k))
+}
+
+func (t *table) Delete(k []byte) error {
+	return t.db.Delete(t.key(k))
+}
+
+func (t *table) Batch() KVBatch {
+	return &tableBatch{table: t, batch: t.db.Batch()}
+}
+
+func (t *table) Iterator(Range *KVIteratorRange) KVIterator {
+	r := &KVIteratorRange{
+		Start: t.prefix,
+		Limit: prefixUpperBound(t.prefix),
+	}
+
+	if Range != nil {
+		if Range.Start != nil {
+			r.Start = t.key(Range.Start)
+		}
+
+		if Range.Limit != nil {
+			r.Limit = t.key(Range.Limit)
+		}
+	}
+
+	return &tableIterator{table: t, iter: t.db.Iterator(r)}
+}
+
+// CompactionBacklog returns the compaction backlog of the shared database
+func (t *table) CompactionBacklog() (int, bool) {
+	if stats, ok := t.db.(KVCompactionStats); ok {
+		return stats.CompactionBacklog()
+	}
+
+	return 0, false
+}
+
+// Compact compacts the keys within [start, limit) of the table in the shared database
+func (t *table) Compact(start, limit []byte) error {
+	compacter, ok := t.db.(KVCompacter)
+	if !ok {
+		return ErrCompactNotSupported
+	}
+
+	if start == nil {
+		start = t.prefix
+	} else {
+		start = t.key(start)
+	}
+
+	if limit == nil {
+		limit = prefixUpperBound(t.prefix)
+	} else {
+		limit = t.key(limit)
+	}
+
+	return compacter.Compact(start, limit)
+}
+
+// Close does nothing, the shared database is closed by its owner
+func (t *table) Close() error {
+	return nil
+}
+
+// prefixUpperBound returns the smallest key greater than all the keys with the
+// prefix, or nil if there is none
+func prefixUpperBound(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			limit := make([]byte, i+1)
+			copy(limit, prefix)
+			limit[i]++
+
+			return limit
+		}
+	}
+
+	return nil
+}
+
+type tableBatch struct {
+	table *table
+	batch KVBatch
+}
+
+func (b *tableBatch) Set(k, v []byte) {
+	b.batch.Set(b.table.key(k), v)
+}
+
+func (b *tableBatch) Delete(k []byte) {
+	b.batch.Delete(b.table.key(k))
+}
+
+func (b *tableBatch) ValueSize() int {
+	return b.batch.ValueSize()
+}
+
+func (b *tableBatch) Write() error {
+	return b.batch.Write()
+}
+
+func (b *tableBatch) Reset() {
+	b.batch.Reset()
+}
+
+// tableIterator strips the table prefix from the keys
+type tableIterator struct {
+	table *table
+	iter  KVIterator
+}
+
+func (it *tableIterator) First() bool {
+	return it.iter.First()
+}
+
+func (it *tableIterator) Last() bool {
+	return it.iter.Last()
+}
+
+func (it *tableIterator) Seek(key []byte) bool {
+	return it.iter.Seek(it.table.key(key))
+}
+
+func (it *tableIterator) Next() bool {
+	return it.iter.Next()
+}
+
+func (it *tableIterator) Prev() bool {
+	return it.iter.Prev()
+}
+
+func (it *tableIterator) Key() []byte {
+	key := it.iter.Key()
+	if key == nil {
+		return nil
+	}
+
+	return key[len(it.table.prefix):]
+}
+
+func (it *tableIterator) Value() []byte {
+	return it.iter.Value()
+}
+
+func (it *tableIterator) Release() {
+	it.iter.Release()
+}
+
+func (it *tableIterator) Error() error {
+	return it.iter.Error()
+}
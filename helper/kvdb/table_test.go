@@ -0,0 +1,127 @@
+package kvdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	t.Parallel()
+
+	db := createTestDB(t)
+	defer db.Close()
+
+	a := NewTable(db, "a")
+	b := NewTable(db, "b")
+
+	assert.NoError(t, a.Set([]byte("1"), []byte("a1")))
+	assert.NoError(t, a.Set([]byte("2"), []byte("a2")))
+	assert.NoError(t, b.Set([]byte("1"), []byte("b1")))
+
+	batch := a.Batch()
+	batch.Set([]byte("3"), []byte("a3"))
+	assert.NoError(t, batch.Write())
+
+	v, found, err := a.Get([]byte("1"))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("a1"), v)
+
+	v, found, err = db.Get([]byte("b1"))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("b1"), v)
+
+	_, found, err = b.Get([]byte("2"))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	t.Run("iterate isolated keyspace", func(t *testing.T) {
+		iter := a.Iterator(nil)
+		defer iter.Release()
+
+		keys := []string{}
+		for iter.Next() {
+			keys = append(keys, string(iter.Key()))
+		}
+
+		assert.Equal(t, []string{"1", "2", "3"}, keys)
+		assert.NoError(t, iter.Error())
+
+		assert.True(t, iter.Last())
+		assert.Equal(t, []byte("3"), iter.Key())
+		assert.True(t, iter.Seek([]byte("2")))
+		assert.Equal(t, []byte("a2"), iter.Value())
+	})
+
+	t.Run("iterate range", func(t *testing.T) {
+		iter := a.Iterator(&KVIteratorRange{Start: []byte("2"), Limit: []byte("3")})
+		defer iter.Release()
+
+		assert.True(t, iter.First())
+		assert.Equal(t, []byte("2"), iter.Key())
+		assert.False(t, iter.Next())
+	})
+
+	assert.NoError(t, a.Delete([]byte("1")))
+	assert.NoError(t, a.Close())
+
+	// the shared database is still open
+	_, found, err = a.Get([]byte("1"))
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestPrefixUpperBound(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []byte("b"), prefixUpperBound([]byte("a")))
+	assert.Equal(t, []byte{0x01}, prefixUpperBound([]byte{0x00, 0xff}))
+	assert.Nil(t, prefixUpperBound([]byte{0xff, 0xff}))
+	assert.Nil(t, prefixUpperBound(nil))
+}
+
+func TestTable_Compact(t *testing.T) {
+	t.Parallel()
+
+	db := createTestDB(t)
+	defer db.Close()
+
+	a := NewTable(db, "a")
+	b := NewTable(db, "b")
+
+	for i := byte(0); i < 100; i++ {
+		assert.NoError(t, a.Set([]byte{i}, []byte("a")))
+		assert.NoError(t, b.Set([]byte{i}, []byte("b")))
+	}
+
+	for i := byte(0); i < 50; i++ {
+		assert.NoError(t, a.Delete([]byte{i}))
+	}
+
+	compacter, ok := a.(KVCompacter)
+	assert.True(t, ok)
+
+	assert.NoError(t, compacter.Compact(nil, nil))
+	assert.NoError(t, compacter.Compact([]byte{10}, []byte{20}))
+
+	// compaction keeps the live pairs of both tables
+	_, found, err := a.Get([]byte{10})
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	v, found, err := a.Get([]byte{50})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("a"), v)
+
+	v, found, err = b.Get([]byte{10})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("b"), v)
+
+	// the storage compacting in background only
+	background := NewTable(struct{ KVBatchStorage }{db}, "a")
+	assert.ErrorIs(t, background.(KVCompacter).Compact(nil, nil), ErrCompactNotSupported)
+}
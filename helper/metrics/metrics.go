@@ -41,3 +41,11 @@ func HistogramObserve(histogram prometheus.Histogram, v float64) {
 
 	histogram.Observe(v)
 }
+
+func CounterAdd(counter prometheus.Counter, v float64) {
+	if counter == nil {
+		return
+	}
+
+	counter.Add(v)
+}
@@ -0,0 +1,45 @@
+package recovery
+
+import (
+	"github.com/dogechain-lab/dogechain/helper/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics represents the recovered panics metrics
+type Metrics struct {
+	// No.of recovered panics by worker
+	panics *prometheus.CounterVec
+}
+
+// PanicsInc increases the recovered panics of the worker
+func (m *Metrics) PanicsInc(worker string) {
+	if m.panics == nil {
+		return
+	}
+
+	m.panics.With(prometheus.Labels{"worker": worker}).Inc()
+}
+
+// GetPrometheusMetrics return the recovered panics metrics instance
+func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics {
+	constLabels := metrics.ParseLables(labelsWithValues...)
+
+	m := &Metrics{
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "recovery",
+			Name:        "panics",
+			Help:        "Number of the recovered panics of the workers.",
+			ConstLabels: constLabels,
+		}, []string{"worker"}),
+	}
+
+	prometheus.MustRegister(m.panics)
+
+	return m
+}
+
+// NilMetrics will return the non operational metrics
+func NilMetrics() *Metrics {
+	return &Metrics{}
+}
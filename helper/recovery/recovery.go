@@ -0,0 +1,74 @@
+// Package recovery isolates the panics of the long-running goroutines, so that one
+// worker does not take down the node
+package recovery
+
+import (
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// minRestartDelay is the delay of restarting a worker after its first panic, it
+	// doubles on the panics in a row up to maxRestartDelay
+	minRestartDelay = time.Second
+	maxRestartDelay = time.Minute
+)
+
+var globalMetrics atomic.Value // *Metrics
+
+// SetMetrics sets the metrics counting the recovered panics of all the workers
+func SetMetrics(m *Metrics) {
+	globalMetrics.Store(m)
+}
+
+func currentMetrics() *Metrics {
+	if m, ok := globalMetrics.Load().(*Metrics); ok {
+		return m
+	}
+
+	return NilMetrics()
+}
+
+// Go runs the worker in a goroutine. A panic of it is logged with the stack trace
+// and counted, the worker is not restarted.
+func Go(logger hclog.Logger, name string, worker func()) {
+	go Run(logger, name, worker) //nolint:errcheck
+}
+
+// GoRestartable runs the worker in a goroutine, and restarts it after a panic until
+// it returns. Only the workers keeping no state across the runs are safe to restart,
+// like the loops over a channel.
+func GoRestartable(logger hclog.Logger, name string, worker func()) {
+	go func() {
+		delay := minRestartDelay
+
+		for Run(logger, name, worker) {
+			logger.Warn("restart worker after panic", "worker", name, "delay", delay)
+
+			time.Sleep(delay)
+
+			if delay *= 2; delay > maxRestartDelay {
+				delay = maxRestartDelay
+			}
+		}
+	}()
+}
+
+// Run runs the worker, and returns whether it panicked
+func Run(logger hclog.Logger, name string, worker func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+
+			logger.Error("worker panicked", "worker", name, "panic", r, "stack", string(debug.Stack()))
+			currentMetrics().PanicsInc(name)
+		}
+	}()
+
+	worker()
+
+	return false
+}
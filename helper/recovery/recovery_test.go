@@ -0,0 +1,42 @@
+package recovery
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	logger := hclog.NewNullLogger()
+
+	assert.False(t, Run(logger, "ok", func() {}))
+	assert.True(t, Run(logger, "panic", func() {
+		panic("boom")
+	}))
+}
+
+func TestGoRestartable(t *testing.T) {
+	t.Parallel()
+
+	var (
+		runs   = 0
+		doneCh = make(chan struct{})
+	)
+
+	GoRestartable(hclog.NewNullLogger(), "worker", func() {
+		runs++
+
+		if runs == 1 {
+			panic("boom")
+		}
+
+		close(doneCh)
+	})
+
+	<-doneCh
+
+	assert.Equal(t, 2, runs)
+}
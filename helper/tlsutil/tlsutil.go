@@ -0,0 +1,173 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+var (
+	ErrMissingCertFile = errors.New("missing tls certificate file")
+	ErrMissingKeyFile  = errors.New("missing tls key file")
+)
+
+// Config is the certificate terminating the TLS of a server
+type Config struct {
+	CertFile string // PEM encoded certificate chain
+	KeyFile  string // PEM encoded private key
+
+	// ReloadInterval is the interval of checking the files for the rotation,
+	// 0 for loading the certificate once
+	ReloadInterval time.Duration
+}
+
+// Validate returns an error if the certificate can't be loaded
+func (c *Config) Validate() error {
+	if c.CertFile == "" {
+		return ErrMissingCertFile
+	}
+
+	if c.KeyFile == "" {
+		return ErrMissingKeyFile
+	}
+
+	_, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+
+	return err
+}
+
+// CertReloader serves the certificate of the files, and reloads it in background
+// once the files are modified
+type CertReloader struct {
+	logger hclog.Logger
+	config Config
+
+	lock    sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time // the latest modification time of the loaded files
+
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewCertReloader loads the certificate, the files are watched if the reload
+// interval is set
+func NewCertReloader(logger hclog.Logger, config Config) (*CertReloader, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := &CertReloader{
+		logger:  logger.Named("tls"),
+		config:  config,
+		closeCh: make(chan struct{}),
+	}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	if config.ReloadInterval > 0 {
+		go r.watch()
+	}
+
+	return r, nil
+}
+
+// GetCertificate returns the loaded certificate, it implements tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.cert, nil
+}
+
+// Reload loads the certificate again if the files are modified since the last load,
+// it returns true if reloaded. The former certificate is kept on failure.
+func (r *CertReloader) Reload() (bool, error) {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return false, err
+	}
+
+	r.lock.RLock()
+	unchanged := r.cert != nil && modTime.Equal(r.modTime)
+	r.lock.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to load tls certificate: %w", err)
+	}
+
+	r.lock.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.lock.Unlock()
+
+	return true, nil
+}
+
+// latestModTime returns the latest modification time of the certificate and key files
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+
+	for _, path := range []string{r.config.CertFile, r.config.KeyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+func (r *CertReloader) watch() {
+	ticker := time.NewTicker(r.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.closeCh:
+			return
+		case <-ticker.C:
+		}
+
+		// the files might be written one after another, the mismatched pair fails
+		// to load and is retried on the next tick
+		reloaded, err := r.Reload()
+		if err != nil {
+			r.logger.Error("failed to reload tls certificate", "cert", r.config.CertFile, "err", err)
+		} else if reloaded {
+			r.logger.Info("tls certificate reloaded", "cert", r.config.CertFile)
+		}
+	}
+}
+
+// Close stops watching the files
+func (r *CertReloader) Close() {
+	r.closeOnce.Do(func() {
+		close(r.closeCh)
+	})
+}
+
+// NewListener wraps the listener terminating TLS with the certificate of the reloader
+func NewListener(inner net.Listener, r *CertReloader) net.Listener {
+	return tls.NewListener(inner, &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	})
+}
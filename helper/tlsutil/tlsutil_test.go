@@ -0,0 +1,169 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestCert writes the self-signed certificate of the common name, and sets the
+// modification time of the files
+func writeTestCert(t *testing.T, dir, commonName string, modTime time.Time) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	for _, path := range []string{certFile, keyFile} {
+		assert.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	return certFile, keyFile
+}
+
+func commonName(t *testing.T, r *CertReloader) string {
+	t.Helper()
+
+	cert, err := r.GetCertificate(nil)
+	assert.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.NoError(t, err)
+
+	return leaf.Subject.CommonName
+}
+
+func TestConfig_Validate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, "first", time.Now())
+
+	assert.NoError(t, (&Config{CertFile: certFile, KeyFile: keyFile}).Validate())
+	assert.ErrorIs(t, (&Config{KeyFile: keyFile}).Validate(), ErrMissingCertFile)
+	assert.ErrorIs(t, (&Config{CertFile: certFile}).Validate(), ErrMissingKeyFile)
+	assert.Error(t, (&Config{CertFile: keyFile, KeyFile: certFile}).Validate())
+	assert.Error(t, (&Config{CertFile: filepath.Join(dir, "missing"), KeyFile: keyFile}).Validate())
+}
+
+func TestCertReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Minute)
+	certFile, keyFile := writeTestCert(t, dir, "first", start)
+
+	r, err := NewCertReloader(hclog.NewNullLogger(), Config{CertFile: certFile, KeyFile: keyFile})
+	assert.NoError(t, err)
+
+	defer r.Close()
+
+	assert.Equal(t, "first", commonName(t, r))
+
+	// unmodified files are not loaded again
+	reloaded, err := r.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// rotated
+	writeTestCert(t, dir, "second", start.Add(time.Second))
+
+	reloaded, err = r.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, "second", commonName(t, r))
+
+	// the broken files keep the former certificate
+	assert.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0600))
+	assert.NoError(t, os.Chtimes(keyFile, start.Add(2*time.Second), start.Add(2*time.Second)))
+
+	_, err = r.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, "second", commonName(t, r))
+}
+
+func TestCertReloader_Watch(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Minute)
+	certFile, keyFile := writeTestCert(t, dir, "first", start)
+
+	r, err := NewCertReloader(hclog.NewNullLogger(), Config{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ReloadInterval: 10 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	defer r.Close()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	lis = NewListener(lis, r)
+	defer lis.Close()
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			// complete the handshake before closing
+			_ = conn.(*tls.Conn).Handshake() //nolint:forcetypeassert
+			conn.Close()
+		}
+	}()
+
+	// served certificate of the handshake
+	served := func() string {
+		conn, err := tls.Dial("tcp", lis.Addr().String(), &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec
+		})
+		if err != nil {
+			return ""
+		}
+
+		defer conn.Close()
+
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+	}
+
+	assert.Equal(t, "first", served())
+
+	writeTestCert(t, dir, "second", start.Add(time.Second))
+
+	assert.Eventually(t, func() bool {
+		return served() == "second"
+	}, 5*time.Second, 20*time.Millisecond)
+}
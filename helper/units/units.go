@@ -0,0 +1,274 @@
+// Package units parses the durations and sizes of the flags and config files in
+// human-friendly units, like "2s" or "512MiB". The bare numbers used by the former
+// flags and configs are still accepted, in their legacy units.
+package units
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Size units
+const (
+	B   uint64 = 1
+	KB  uint64 = 1000
+	MB  uint64 = 1000 * KB
+	GB  uint64 = 1000 * MB
+	TB  uint64 = 1000 * GB
+	KiB uint64 = 1 << 10
+	MiB uint64 = 1 << 20
+	GiB uint64 = 1 << 30
+	TiB uint64 = 1 << 40
+)
+
+var (
+	ErrInvalidDuration = errors.New("invalid duration, expected a value like \"2s\", \"500ms\" or \"1m30s\"")
+	ErrInvalidSize     = errors.New("invalid size, expected a value like \"512MiB\", \"1GB\" or \"4096B\"")
+	ErrNotWholeUnit    = errors.New("value is not a whole number of the unit")
+)
+
+var (
+	bareNumberRegex = regexp.MustCompile(`^[0-9]+$`)
+	sizeRegex       = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z]*)$`)
+
+	sizeUnits = map[string]uint64{
+		"b":   B,
+		"kb":  KB,
+		"mb":  MB,
+		"gb":  GB,
+		"tb":  TB,
+		"kib": KiB,
+		"mib": MiB,
+		"gib": GiB,
+		"tib": TiB,
+	}
+
+	// sizeFormatUnits are the units to format sizes, the largest first
+	sizeFormatUnits = []struct {
+		name string
+		size uint64
+	}{
+		{"TiB", TiB},
+		{"GiB", GiB},
+		{"MiB", MiB},
+		{"KiB", KiB},
+	}
+)
+
+// ParseDuration parses a duration string like "2s" or "1m30s". A bare number is
+// taken in the legacy unit.
+func ParseDuration(s string, legacyUnit time.Duration) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	if bareNumberRegex.MatchString(s) {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || time.Duration(n) > time.Duration(1<<63-1)/legacyUnit {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, s)
+		}
+
+		return time.Duration(n) * legacyUnit, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, s)
+	}
+
+	return d, nil
+}
+
+// ParseSize parses a size string like "512MiB" or "1.5GB" into bytes. The units are
+// case insensitive, KB/MB/GB/TB are decimal, and KiB/MiB/GiB/TiB are binary. A bare
+// number is taken in the legacy unit.
+func ParseSize(s string, legacyUnit uint64) (uint64, error) {
+	s = strings.TrimSpace(s)
+
+	match := sizeRegex.FindStringSubmatch(s)
+	if match == nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+	}
+
+	unit := legacyUnit
+
+	if match[2] != "" {
+		var ok bool
+
+		if unit, ok = sizeUnits[strings.ToLower(match[2])]; !ok {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+		}
+	}
+
+	n, ok := new(big.Rat).SetString(match[1])
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+	}
+
+	n.Mul(n, new(big.Rat).SetInt(new(big.Int).SetUint64(unit)))
+
+	if !n.IsInt() {
+		return 0, fmt.Errorf("%w: %q is not a whole number of bytes", ErrInvalidSize, s)
+	} else if !n.Num().IsUint64() {
+		return 0, fmt.Errorf("%w: %q is too large", ErrInvalidSize, s)
+	}
+
+	return n.Num().Uint64(), nil
+}
+
+// FormatSize formats the bytes in the largest binary unit dividing it
+func FormatSize(bytes uint64) string {
+	for _, unit := range sizeFormatUnits {
+		if bytes >= unit.size && bytes%unit.size == 0 {
+			return fmt.Sprintf("%d%s", bytes/unit.size, unit.name)
+		}
+	}
+
+	return fmt.Sprintf("%dB", bytes)
+}
+
+// unmarshalNumberOrString unmarshals a JSON number or string as a string
+func unmarshalNumberOrString(data []byte) (string, error) {
+	var s string
+
+	if err := json.Unmarshal(data, &s); err == nil {
+		return s, nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return "", err
+	}
+
+	return n.String(), nil
+}
+
+// Duration is a duration flag and config value, like "2s" or "1m30s". A bare number,
+// used by the former flags and configs, is in seconds.
+type Duration string
+
+// DurationOf returns the duration value of d
+func DurationOf(d time.Duration) Duration {
+	return Duration(d.String())
+}
+
+// Duration returns the parsed duration
+func (d Duration) Duration() (time.Duration, error) {
+	return ParseDuration(string(d), time.Second)
+}
+
+// Seconds returns the parsed duration in whole seconds
+func (d Duration) Seconds() (uint64, error) {
+	duration, err := d.Duration()
+	if err != nil {
+		return 0, err
+	}
+
+	if duration%time.Second != 0 {
+		return 0, fmt.Errorf("%w: %q is not a whole number of seconds", ErrNotWholeUnit, d)
+	}
+
+	return uint64(duration / time.Second), nil
+}
+
+// String implements pflag.Value
+func (d *Duration) String() string {
+	return string(*d)
+}
+
+// Set implements pflag.Value
+func (d *Duration) Set(s string) error {
+	if _, err := ParseDuration(s, time.Second); err != nil {
+		return err
+	}
+
+	*d = Duration(strings.TrimSpace(s))
+
+	return nil
+}
+
+// Type implements pflag.Value
+func (d *Duration) Type() string {
+	return "duration"
+}
+
+// UnmarshalJSON accepts a duration string, or a number in seconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	s, err := unmarshalNumberOrString(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDuration, string(data))
+	}
+
+	return d.Set(s)
+}
+
+// Size is a size flag and config value, like "512MiB" or "1GB". A bare number, used
+// by the former flags and configs, is in MiB.
+type Size string
+
+// SizeOf returns the size value of the bytes
+func SizeOf(bytes uint64) Size {
+	return Size(FormatSize(bytes))
+}
+
+// Bytes returns the parsed size in bytes
+func (s Size) Bytes() (uint64, error) {
+	return ParseSize(string(s), MiB)
+}
+
+// MiB returns the parsed size in MiB, rounded down, so that the decimal sizes like
+// "512MB" never exceed a budget like a cache size
+func (s Size) MiB() (uint64, error) {
+	bytes, err := s.Bytes()
+	if err != nil {
+		return 0, err
+	}
+
+	return bytes / MiB, nil
+}
+
+// MiBCeil returns the parsed size in MiB, rounded up, so that the decimal sizes like
+// "1GB" are never shrunk below a minimum like a table size
+func (s Size) MiBCeil() (uint64, error) {
+	bytes, err := s.Bytes()
+	if err != nil {
+		return 0, err
+	}
+
+	return bytes/MiB + (bytes%MiB+MiB-1)/MiB, nil
+}
+
+// String implements pflag.Value
+func (s *Size) String() string {
+	return string(*s)
+}
+
+// Set implements pflag.Value
+func (s *Size) Set(value string) error {
+	if _, err := ParseSize(value, MiB); err != nil {
+		return err
+	}
+
+	*s = Size(strings.TrimSpace(value))
+
+	return nil
+}
+
+// Type implements pflag.Value
+func (s *Size) Type() string {
+	return "size"
+}
+
+// UnmarshalJSON accepts a size string, or a number in MiB
+func (s *Size) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalNumberOrString(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSize, string(data))
+	}
+
+	return s.Set(value)
+}
@@ -0,0 +1,157 @@
+package units
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected time.Duration
+		valid    bool
+	}{
+		{"2s", 2 * time.Second, true},
+		{"1m30s", 90 * time.Second, true},
+		{"500ms", 500 * time.Millisecond, true},
+		{" 5 ", 5 * time.Second, true}, // legacy seconds
+		{"0", 0, true},
+		{"-1s", 0, false},
+		{"2x", 0, false},
+		{"1.5", 0, false},
+		{"", 0, false},
+	}
+
+	for _, c := range cases {
+		d, err := ParseDuration(c.input, time.Second)
+		if !c.valid {
+			assert.ErrorIs(t, err, ErrInvalidDuration, c.input)
+
+			continue
+		}
+
+		assert.NoError(t, err, c.input)
+		assert.Equal(t, c.expected, d, c.input)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected uint64
+		valid    bool
+	}{
+		{"512MiB", 512 * MiB, true},
+		{"512mib", 512 * MiB, true},
+		{"1GB", GB, true},
+		{"1.5GiB", 3 * GiB / 2, true},
+		{"4096 B", 4096, true},
+		{"16", 16 * MiB, true}, // legacy MiB
+		{"1.5B", 0, false},
+		{"1XB", 0, false},
+		{"-1MiB", 0, false},
+		{"20000000TiB", 0, false},
+		{"", 0, false},
+	}
+
+	for _, c := range cases {
+		size, err := ParseSize(c.input, MiB)
+		if !c.valid {
+			assert.ErrorIs(t, err, ErrInvalidSize, c.input)
+
+			continue
+		}
+
+		assert.NoError(t, err, c.input)
+		assert.Equal(t, c.expected, size, c.input)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "1GiB", FormatSize(GiB))
+	assert.Equal(t, "1536MiB", FormatSize(3*GiB/2))
+	assert.Equal(t, "4KiB", FormatSize(4*KiB))
+	assert.Equal(t, "1000B", FormatSize(KB))
+	assert.Equal(t, "0B", FormatSize(0))
+}
+
+func TestDurationValue(t *testing.T) {
+	var config struct {
+		Legacy   Duration `json:"legacy"`
+		Flexible Duration `json:"flexible"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"legacy": 3, "flexible": "1m"}`), &config))
+
+	seconds, err := config.Legacy.Seconds()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), seconds)
+
+	seconds, err = config.Flexible.Seconds()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(60), seconds)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"legacy": "3 apples"}`), &config))
+	assert.Error(t, json.Unmarshal([]byte(`{"legacy": true}`), &config))
+
+	d := DurationOf(1500 * time.Millisecond)
+	assert.Equal(t, "1.5s", d.String())
+
+	_, err = d.Seconds()
+	assert.ErrorIs(t, err, ErrNotWholeUnit)
+
+	assert.NoError(t, d.Set("10s"))
+	assert.Equal(t, Duration("10s"), d)
+	assert.Error(t, d.Set("10 seconds"))
+	assert.Equal(t, Duration("10s"), d)
+}
+
+func TestSizeValue(t *testing.T) {
+	var config struct {
+		Legacy   Size `json:"legacy"`
+		Flexible Size `json:"flexible"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"legacy": 1024, "flexible": "2GiB"}`), &config))
+
+	mib, err := config.Legacy.MiB()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1024), mib)
+
+	mib, err = config.Flexible.MiB()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2048), mib)
+
+	s := SizeOf(1536 * KiB)
+	assert.Equal(t, "1536KiB", s.String())
+
+	mib, err = s.MiB()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), mib)
+
+	mib, err = s.MiBCeil()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), mib)
+
+	// the decimal sizes are rounded to MiB
+	assert.NoError(t, s.Set("1GB"))
+
+	mib, err = s.MiB()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(953), mib)
+
+	mib, err = s.MiBCeil()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(954), mib)
+
+	mib, err = config.Flexible.MiBCeil()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2048), mib)
+
+	assert.NoError(t, s.Set("512MiB"))
+	assert.Equal(t, Size("512MiB"), s)
+	assert.Error(t, s.Set("512 apples"))
+}
@@ -0,0 +1,83 @@
+package jsonrpc
+
+import "time"
+
+// PeerConn is an open connection to the peer
+type PeerConn struct {
+	Direction     string    `json:"direction"` // inbound or outbound
+	LocalAddress  string    `json:"localAddress"`
+	RemoteAddress string    `json:"remoteAddress"`
+	Opened        time.Time `json:"opened"`
+}
+
+// PeerInfo is the connected peer, with its connections
+type PeerInfo struct {
+	ID          string      `json:"id"`
+	Addrs       []string    `json:"addrs"`
+	Protocols   []string    `json:"protocols"`
+	Static      bool        `json:"static"`
+	Connections []*PeerConn `json:"connections"`
+}
+
+// NodeInfo is the identity of the node on the network
+type NodeInfo struct {
+	ID        string   `json:"id"`
+	Addrs     []string `json:"addrs"` // full multiaddrs with the peer ID
+	Protocols []string `json:"protocols"`
+	PeerCount int64    `json:"peerCount"`
+}
+
+// adminStore provides access to the methods needed by admin endpoint
+type adminStore interface {
+	// GetPeers returns the connected peers
+	GetPeers() []*PeerInfo
+
+	// GetNodeInfo returns the identity of the node
+	GetNodeInfo() (*NodeInfo, error)
+
+	// AddPeer marks the peer of the multiaddr ready for dialing
+	AddPeer(rawPeerMultiaddr string, static bool) error
+
+	// RemovePeer disconnects the peer, and returns whether it was connected
+	RemovePeer(peerID string) (bool, error)
+}
+
+// Admin is the admin jsonrpc endpoint, managing the peers of the node. It is only
+// enabled when listed in the namespaces explicitly.
+type Admin struct {
+	store adminStore
+
+	metrics *Metrics
+}
+
+// Peers returns the connected peers, with the detail of their connections
+func (a *Admin) Peers() (interface{}, error) {
+	a.metrics.AdminAPICounterInc(AdminPeersLabel)
+
+	return a.store.GetPeers(), nil
+}
+
+// NodeInfo returns the identity and the addresses of the node
+func (a *Admin) NodeInfo() (interface{}, error) {
+	a.metrics.AdminAPICounterInc(AdminNodeInfoLabel)
+
+	return a.store.GetNodeInfo()
+}
+
+// AddPeer dials the peer of the multiaddr, which is kept connected if static
+func (a *Admin) AddPeer(rawPeerMultiaddr string, static *bool) (interface{}, error) {
+	a.metrics.AdminAPICounterInc(AdminAddPeerLabel)
+
+	if err := a.store.AddPeer(rawPeerMultiaddr, static != nil && *static); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RemovePeer disconnects the peer of the ID, the static peers are not removed
+func (a *Admin) RemovePeer(peerID string) (interface{}, error) {
+	a.metrics.AdminAPICounterInc(AdminRemovePeerLabel)
+
+	return a.store.RemovePeer(peerID)
+}
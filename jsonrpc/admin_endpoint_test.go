@@ -0,0 +1,256 @@
+package jsonrpc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+var errMockStaticPeer = errors.New("static peer")
+
+type mockAdminStore struct {
+	*mockStore
+
+	peers     []*PeerInfo
+	added     map[string]bool // the added multiaddrs, and whether static
+	static    map[string]bool
+	compacted [][2][]byte
+}
+
+func newMockAdminStore() *mockAdminStore {
+	return &mockAdminStore{
+		mockStore: newMockStore(),
+		peers: []*PeerInfo{
+			{
+				ID:        "peer1",
+				Addrs:     []string{"/ip4/127.0.0.1/tcp/1478"},
+				Protocols: []string{"/syncer/0.2"},
+				Connections: []*PeerConn{
+					{Direction: "outbound", RemoteAddress: "/ip4/127.0.0.1/tcp/1478"},
+				},
+			},
+		},
+		added:  make(map[string]bool),
+		static: map[string]bool{"peer2": true},
+	}
+}
+
+func (m *mockAdminStore) GetPeers() []*PeerInfo {
+	return m.peers
+}
+
+func (m *mockAdminStore) GetNodeInfo() (*NodeInfo, error) {
+	return &NodeInfo{ID: "node", PeerCount: int64(len(m.peers))}, nil
+}
+
+func (m *mockAdminStore) AddPeer(rawPeerMultiaddr string, static bool) error {
+	m.added[rawPeerMultiaddr] = static
+
+	return nil
+}
+
+func (m *mockAdminStore) RemovePeer(peerID string) (bool, error) {
+	if m.static[peerID] {
+		return false, errMockStaticPeer
+	}
+
+	return peerID == "peer1", nil
+}
+
+func (m *mockAdminStore) ChaindbCompact(start, limit []byte) error {
+	m.compacted = append(m.compacted, [2][]byte{start, limit})
+
+	return nil
+}
+
+func TestAdminEndpoint_Namespace(t *testing.T) {
+	store := newMockAdminStore()
+
+	// the wildcard leaves the admin namespace out
+	dispatcher := newDispatcher(hclog.NewNullLogger(), NilMetrics(), store, 0, 0, 0, 0, []Namespace{
+		NamespaceAll,
+	})
+
+	resp, err := dispatcher.Handle([]byte(`{"method": "admin_peers", "params": []}`))
+	assert.NoError(t, err)
+
+	var peers []*PeerInfo
+
+	assert.Error(t, expectJSONResult(resp, &peers))
+
+	dispatcher = newDispatcher(hclog.NewNullLogger(), NilMetrics(), store, 0, 0, 0, 0, []Namespace{
+		NamespaceAll,
+		NamespaceAdmin,
+	})
+
+	resp, err = dispatcher.Handle([]byte(`{"method": "admin_peers", "params": []}`))
+	assert.NoError(t, err)
+
+	assert.NoError(t, expectJSONResult(resp, &peers))
+	assert.Equal(t, store.peers, peers)
+
+	var info NodeInfo
+
+	resp, err = dispatcher.Handle([]byte(`{"method": "admin_nodeInfo", "params": []}`))
+	assert.NoError(t, err)
+
+	assert.NoError(t, expectJSONResult(resp, &info))
+	assert.Equal(t, "node", info.ID)
+	assert.Equal(t, int64(1), info.PeerCount)
+}
+
+func TestAdminEndpoint_AddRemovePeer(t *testing.T) {
+	store := newMockAdminStore()
+	dispatcher := newDispatcher(hclog.NewNullLogger(), NilMetrics(), store, 0, 0, 0, 0, []Namespace{
+		NamespaceAdmin,
+	})
+
+	var ok bool
+
+	// not static unless required
+	resp, err := dispatcher.Handle([]byte(`{"method": "admin_addPeer", "params": ["/ip4/1.2.3.4/tcp/1478/p2p/a"]}`))
+	assert.NoError(t, err)
+	assert.NoError(t, expectJSONResult(resp, &ok))
+	assert.True(t, ok)
+
+	resp, err = dispatcher.Handle([]byte(`{"method": "admin_addPeer", "params": ["/ip4/1.2.3.4/tcp/1478/p2p/b", true]}`))
+	assert.NoError(t, err)
+	assert.NoError(t, expectJSONResult(resp, &ok))
+	assert.True(t, ok)
+
+	assert.Equal(t, map[string]bool{
+		"/ip4/1.2.3.4/tcp/1478/p2p/a": false,
+		"/ip4/1.2.3.4/tcp/1478/p2p/b": true,
+	}, store.added)
+
+	resp, err = dispatcher.Handle([]byte(`{"method": "admin_removePeer", "params": ["peer1"]}`))
+	assert.NoError(t, err)
+	assert.NoError(t, expectJSONResult(resp, &ok))
+	assert.True(t, ok)
+
+	// not connected
+	resp, err = dispatcher.Handle([]byte(`{"method": "admin_removePeer", "params": ["peer3"]}`))
+	assert.NoError(t, err)
+	assert.NoError(t, expectJSONResult(resp, &ok))
+	assert.False(t, ok)
+
+	resp, err = dispatcher.Handle([]byte(`{"method": "admin_removePeer", "params": ["peer2"]}`))
+	assert.NoError(t, err)
+	assert.Error(t, expectJSONResult(resp, &ok))
+}
+
+func TestAdminEndpoint_ChaindbCompact(t *testing.T) {
+	store := newMockAdminStore()
+
+	var res interface{}
+
+	// the compaction is not exposed by the wildcard
+	dispatcher := newDispatcher(hclog.NewNullLogger(), NilMetrics(), store, 0, 0, 0, 0, []Namespace{
+		NamespaceAll,
+	})
+
+	resp, err := dispatcher.Handle([]byte(`{"method": "debug_chaindbCompact", "params": []}`))
+	assert.NoError(t, err)
+	assert.Error(t, expectJSONResult(resp, &res))
+	assert.Len(t, store.compacted, 0)
+
+	// nor by the debug namespace alone
+	dispatcher = newDispatcher(hclog.NewNullLogger(), NilMetrics(), store, 0, 0, 0, 0, []Namespace{
+		NamespaceDebug,
+	})
+
+	resp, err = dispatcher.Handle([]byte(`{"method": "debug_chaindbCompact", "params": []}`))
+	assert.NoError(t, err)
+	assert.Error(t, expectJSONResult(resp, &res))
+	assert.Len(t, store.compacted, 0)
+
+	dispatcher = newDispatcher(hclog.NewNullLogger(), NilMetrics(), store, 0, 0, 0, 0, []Namespace{
+		NamespaceAll,
+		NamespaceAdmin,
+	})
+
+	// the whole database
+	resp, err = dispatcher.Handle([]byte(`{"method": "debug_chaindbCompact", "params": []}`))
+	assert.NoError(t, err)
+	assert.NoError(t, expectJSONResult(resp, &res))
+
+	resp, err = dispatcher.Handle([]byte(`{"method": "debug_chaindbCompact", "params": ["0x01", "0x02"]}`))
+	assert.NoError(t, err)
+	assert.NoError(t, expectJSONResult(resp, &res))
+
+	// the admin namespace has no compaction of its own
+	resp, err = dispatcher.Handle([]byte(`{"method": "admin_chaindbCompact", "params": []}`))
+	assert.NoError(t, err)
+	assert.Error(t, expectJSONResult(resp, &res))
+
+	assert.Equal(t, [][2][]byte{{nil, nil}, {{0x01}, {0x02}}}, store.compacted)
+}
+
+var errMockNotApproved = errors.New("not approved")
+
+// mockAdminApprover approves the calls signed by the "ok" signature
+type mockAdminApprover struct {
+	approved []string
+}
+
+func (m *mockAdminApprover) ApproveRequest(remote, method string, params []byte, signatures, expiry []string) error {
+	if len(signatures) != 1 || signatures[0] != "ok" {
+		return errMockNotApproved
+	}
+
+	m.approved = append(m.approved, method+" "+string(params))
+
+	return nil
+}
+
+func TestAdminEndpoint_Approval(t *testing.T) {
+	store := newMockAdminStore()
+	approver := &mockAdminApprover{}
+
+	dispatcher := newDispatcher(hclog.NewNullLogger(), NilMetrics(), store, 0, 0, 0, 0, []Namespace{
+		NamespaceAdmin,
+		NamespaceDebug,
+	})
+	dispatcher.approver = approver
+
+	var (
+		ok  bool
+		res interface{}
+	)
+
+	unapproved := client{}
+	approved := client{signatures: []string{"ok"}, expiry: []string{"1"}}
+
+	// the approval is required to add and remove the peers, and to compact the database
+	resp, err := dispatcher.handleClient(unapproved, []byte(`{"method": "admin_addPeer", "params": ["/ip4/1.2.3.4/tcp/1478/p2p/a"]}`))
+	assert.NoError(t, err)
+	assert.Error(t, expectJSONResult(resp, &ok))
+
+	resp, err = dispatcher.handleClient(unapproved, []byte(`{"method": "admin_removePeer", "params": ["peer1"]}`))
+	assert.NoError(t, err)
+	assert.Error(t, expectJSONResult(resp, &ok))
+
+	resp, err = dispatcher.handleClient(unapproved, []byte(`{"method": "debug_chaindbCompact", "params": []}`))
+	assert.NoError(t, err)
+	assert.Error(t, expectJSONResult(resp, &res))
+	assert.Len(t, store.compacted, 0)
+
+	// but not to read the peers
+	resp, err = dispatcher.handleClient(unapproved, []byte(`{"method": "admin_peers", "params": []}`))
+	assert.NoError(t, err)
+	assert.NoError(t, expectJSONResult(resp, &res))
+
+	resp, err = dispatcher.handleClient(approved, []byte(`{"method": "admin_removePeer", "params": ["peer1"]}`))
+	assert.NoError(t, err)
+	assert.NoError(t, expectJSONResult(resp, &ok))
+	assert.True(t, ok)
+
+	resp, err = dispatcher.handleClient(approved, []byte(`{"method": "debug_chaindbCompact", "params": []}`))
+	assert.NoError(t, err)
+	assert.NoError(t, expectJSONResult(resp, &res))
+	assert.Len(t, store.compacted, 1)
+
+	assert.Equal(t, []string{`admin_removePeer ["peer1"]`, `debug_chaindbCompact []`}, approver.approved)
+}
@@ -0,0 +1,190 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+var (
+	ErrMissingAuthToken = errors.New("missing bearer token")
+	ErrInvalidAuthToken = errors.New("invalid bearer token")
+	ErrAuthTokenExpired = errors.New("bearer token expired")
+)
+
+const (
+	// AdminSignatureHeader is the http header of the operator signatures approving the
+	// admin method, one header per operator
+	AdminSignatureHeader = "X-Admin-Signature"
+
+	// AdminExpiryHeader is the http header of the unix time the approval of the admin
+	// method expires at, which is signed by the operators
+	AdminExpiryHeader = "X-Admin-Expiry"
+)
+
+// approvedMethods are the methods managing the node, which require the N-of-M operator
+// approval if the approver is set
+var approvedMethods = map[string]struct{}{
+	"admin_addPeer":        {},
+	"admin_removePeer":     {},
+	"debug_chaindbCompact": {},
+}
+
+// AdminApprover verifies the N-of-M operator approval of the methods managing the node
+type AdminApprover interface {
+	// ApproveRequest verifies that the call of the client is signed by enough operators
+	// along with the expiry, and that the approval is neither expired nor used already
+	ApproveRequest(remote, method string, params []byte, signatures, expiry []string) error
+}
+
+// AuthClaims are the claims of the bearer token, besides the registered ones
+// (exp, nbf, iat...) which are validated if present
+type AuthClaims struct {
+	// Namespaces are the namespaces permitted to the token, all the enabled
+	// namespaces if empty or containing the wildcard
+	Namespaces []Namespace `json:"namespaces,omitempty"`
+}
+
+// NewAuthToken signs the HS256 bearer token permitting the namespaces, it never expires
+// if expiry is zero
+func NewAuthToken(secret []byte, namespaces []Namespace, expiry time.Time) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.HS256, Key: secret},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	registered := jwt.Claims{IssuedAt: jwt.NewNumericDate(time.Now())}
+	if !expiry.IsZero() {
+		registered.Expiry = jwt.NewNumericDate(expiry)
+	}
+
+	return jwt.Signed(signer).Claims(registered).Claims(AuthClaims{Namespaces: namespaces}).CompactSerialize()
+}
+
+// client is the caller of the requests
+type client struct {
+	ip          string                 // empty if unknown
+	namespaces  map[Namespace]struct{} // permitted by the token, nil for all
+	tokenExpiry time.Time              // expiry of the token, zero if never
+
+	// the operator approval of the admin methods, from the request headers
+	signatures []string
+	expiry     []string
+}
+
+// permits returns true if the method is permitted to the client
+func (c client) permits(method string) bool {
+	if c.namespaces == nil {
+		return true
+	}
+
+	namespace, _, _ := strings.Cut(method, "_")
+	_, ok := c.namespaces[Namespace(namespace)]
+
+	return ok
+}
+
+// expired returns true if the token of the client has expired, the websocket clients
+// outlive the tokens checked on the upgrade
+func (c client) expired(now time.Time) bool {
+	return !c.tokenExpiry.IsZero() && !now.Before(c.tokenExpiry)
+}
+
+// authenticator verifies the HS256 bearer tokens of the requests
+type authenticator struct {
+	secret []byte
+}
+
+// newAuthenticator returns the authenticator of the shared secret, nil if the secret
+// is empty
+func newAuthenticator(secret []byte) *authenticator {
+	if len(secret) == 0 {
+		return nil
+	}
+
+	return &authenticator{secret: secret}
+}
+
+// authenticate returns the client of the http request from the ip, the bearer token
+// is required unless the authenticator is nil
+func (a *authenticator) authenticate(req *http.Request, ip string) (client, error) {
+	c := client{
+		ip:         ip,
+		signatures: req.Header.Values(AdminSignatureHeader),
+		expiry:     req.Header.Values(AdminExpiryHeader),
+	}
+
+	if a == nil {
+		return c, nil
+	}
+
+	raw := req.Header.Get("Authorization")
+	if len(raw) <= len("Bearer ") || !strings.EqualFold(raw[:len("Bearer ")], "Bearer ") {
+		return c, ErrMissingAuthToken
+	}
+
+	claims, expiry, err := a.verify(strings.TrimSpace(raw[len("Bearer "):]))
+	if err != nil {
+		return c, err
+	}
+
+	c.tokenExpiry = expiry
+
+	for _, ns := range claims.Namespaces {
+		if ns == NamespaceAll {
+			return c, nil
+		}
+	}
+
+	if len(claims.Namespaces) > 0 {
+		c.namespaces = make(map[Namespace]struct{}, len(claims.Namespaces))
+
+		for _, ns := range claims.Namespaces {
+			c.namespaces[ns] = struct{}{}
+		}
+	}
+
+	return c, nil
+}
+
+// verify returns the claims of the token signed with the secret, and its expiry which
+// is zero if never
+func (a *authenticator) verify(token string) (*AuthClaims, time.Time, error) {
+	tok, err := jwt.ParseSigned(token)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("%w: %v", ErrInvalidAuthToken, err)
+	}
+
+	// the secret is shared, no other algorithm is accepted
+	if len(tok.Headers) != 1 || tok.Headers[0].Algorithm != string(jose.HS256) {
+		return nil, time.Time{}, fmt.Errorf("%w: unsupported algorithm", ErrInvalidAuthToken)
+	}
+
+	var (
+		registered jwt.Claims
+		claims     AuthClaims
+	)
+
+	if err := tok.Claims(a.secret, &registered, &claims); err != nil {
+		return nil, time.Time{}, fmt.Errorf("%w: %v", ErrInvalidAuthToken, err)
+	}
+
+	if err := registered.Validate(jwt.Expected{Time: time.Now()}); err != nil {
+		return nil, time.Time{}, fmt.Errorf("%w: %v", ErrInvalidAuthToken, err)
+	}
+
+	var expiry time.Time
+	if registered.Expiry != nil {
+		expiry = registered.Expiry.Time()
+	}
+
+	return &claims, expiry, nil
+}
@@ -0,0 +1,195 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+var testAuthSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func newAuthRequest(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.RemoteAddr = "1.2.3.4:5678"
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return req
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	// the token is not required without the secret
+	assert.Nil(t, newAuthenticator(nil))
+
+	var disabled *authenticator
+
+	c, err := disabled.authenticate(newAuthRequest(""), "1.2.3.4")
+	assert.NoError(t, err)
+	assert.Equal(t, client{ip: "1.2.3.4"}, c)
+
+	auth := newAuthenticator(testAuthSecret)
+
+	// missing token
+	_, err = auth.authenticate(newAuthRequest(""), "1.2.3.4")
+	assert.ErrorIs(t, err, ErrMissingAuthToken)
+
+	// all the namespaces are permitted without the claim
+	token, err := NewAuthToken(testAuthSecret, nil, time.Time{})
+	assert.NoError(t, err)
+
+	c, err = auth.authenticate(newAuthRequest(token), "1.2.3.4")
+	assert.NoError(t, err)
+	assert.Equal(t, client{ip: "1.2.3.4"}, c)
+
+	// or with the wildcard
+	token, err = NewAuthToken(testAuthSecret, []Namespace{NamespaceEth, NamespaceAll}, time.Time{})
+	assert.NoError(t, err)
+
+	c, err = auth.authenticate(newAuthRequest(token), "1.2.3.4")
+	assert.NoError(t, err)
+	assert.Nil(t, c.namespaces)
+
+	// the permitted namespaces
+	token, err = NewAuthToken(testAuthSecret, []Namespace{NamespaceEth, NamespaceDebug}, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	c, err = auth.authenticate(newAuthRequest(token), "1.2.3.4")
+	assert.NoError(t, err)
+	assert.False(t, c.tokenExpiry.IsZero())
+	assert.False(t, c.expired(time.Now()))
+	assert.True(t, c.expired(time.Now().Add(2*time.Hour)))
+	assert.True(t, c.permits("eth_call"))
+	assert.True(t, c.permits("debug_traceTransaction"))
+	assert.False(t, c.permits("admin_addPeer"))
+	assert.False(t, c.permits("txpool_content"))
+
+	// signed with another secret
+	token, err = NewAuthToken([]byte("another secret"), nil, time.Time{})
+	assert.NoError(t, err)
+
+	_, err = auth.authenticate(newAuthRequest(token), "1.2.3.4")
+	assert.ErrorIs(t, err, ErrInvalidAuthToken)
+
+	// expired
+	token, err = NewAuthToken(testAuthSecret, nil, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+
+	_, err = auth.authenticate(newAuthRequest(token), "1.2.3.4")
+	assert.ErrorIs(t, err, ErrInvalidAuthToken)
+
+	// the other algorithms are rejected
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS512, Key: testAuthSecret}, nil)
+	assert.NoError(t, err)
+
+	token, err = jwt.Signed(signer).Claims(jwt.Claims{}).CompactSerialize()
+	assert.NoError(t, err)
+
+	_, err = auth.authenticate(newAuthRequest(token), "1.2.3.4")
+	assert.ErrorIs(t, err, ErrInvalidAuthToken)
+
+	// malformed
+	_, err = auth.authenticate(newAuthRequest("abc"), "1.2.3.4")
+	assert.ErrorIs(t, err, ErrInvalidAuthToken)
+}
+
+func TestDispatcher_MethodNotPermitted(t *testing.T) {
+	dispatcher := newDispatcher(hclog.NewNullLogger(), NilMetrics(), newMockStore(), 0, 0, 0, 0, []Namespace{
+		NamespaceWeb3,
+		NamespaceNet,
+	})
+
+	c := client{namespaces: map[Namespace]struct{}{NamespaceWeb3: {}}}
+
+	resp, err := dispatcher.handleClient(c, []byte(`[
+		{"id":1,"jsonrpc":"2.0","method":"web3_clientVersion","params":[]},
+		{"id":2,"jsonrpc":"2.0","method":"net_version","params":[]}]`))
+	assert.NoError(t, err)
+
+	var responses []*ErrorResponse
+
+	assert.NoError(t, json.Unmarshal(resp, &responses))
+	assert.Len(t, responses, 2)
+	assert.Nil(t, responses[0].Error)
+	assert.Equal(t, &ObjectError{
+		Code:    -32001,
+		Message: "the method net_version is not permitted to the token",
+	}, responses[1].Error)
+}
+
+func TestDispatcher_TokenExpired(t *testing.T) {
+	dispatcher := newDispatcher(hclog.NewNullLogger(), NilMetrics(), newMockStore(), 0, 0, 0, 0, []Namespace{
+		NamespaceWeb3,
+	})
+
+	request := []byte(`{"id":1,"jsonrpc":"2.0","method":"web3_clientVersion","params":[]}`)
+
+	// the token of the websocket client expired since the upgrade
+	resp, err := dispatcher.handleClient(client{tokenExpiry: time.Now().Add(-time.Second)}, request)
+	assert.NoError(t, err)
+
+	var response ErrorResponse
+
+	assert.NoError(t, json.Unmarshal(resp, &response))
+	assert.Equal(t, &ObjectError{
+		Code:    -32001,
+		Message: ErrAuthTokenExpired.Error(),
+	}, response.Error)
+
+	resp, err = dispatcher.handleClient(client{tokenExpiry: time.Now().Add(time.Hour)}, request)
+	assert.NoError(t, err)
+
+	var res string
+
+	assert.NoError(t, expectJSONResult(resp, &res))
+}
+
+func TestJSONRPC_Unauthorized(t *testing.T) {
+	jsonRPC := &JSONRPC{
+		logger: hclog.NewNullLogger(),
+		config: &Config{},
+		dispatcher: newDispatcher(hclog.NewNullLogger(), NilMetrics(), newMockStore(), 0, 0, 0, 0, []Namespace{
+			NamespaceWeb3,
+		}),
+		auth:    newAuthenticator(testAuthSecret),
+		metrics: NilMetrics(),
+	}
+
+	request := `{"id":1,"jsonrpc":"2.0","method":"web3_clientVersion","params":[]}`
+
+	// without the token
+	w := httptest.NewRecorder()
+	jsonRPC.handle(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(request)))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	var response ErrorResponse
+
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, -32001, response.Error.Code)
+
+	// with the token
+	token, err := NewAuthToken(testAuthSecret, []Namespace{NamespaceWeb3}, time.Time{})
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(request))
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w = httptest.NewRecorder()
+	jsonRPC.handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res string
+
+	assert.NoError(t, expectJSONResult(w.Body.Bytes(), &res))
+}
@@ -0,0 +1,189 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"sync"
+
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// ResponseCache caches the responses of the immutable queries
+type ResponseCache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+}
+
+// cacheableMethods are the methods queried by hash, whose results never change once
+// found. The ones set are queried by transaction hash, whose results depend on the
+// block including the transaction, so they are cached along with the block hash.
+var cacheableMethods = map[string]bool{
+	"eth_getBlockByHash":        false,
+	"eth_getTransactionReceipt": true,
+	"debug_traceTransaction":    true,
+	"debug_getCodeByHash":       false,
+}
+
+// responseCacheKey returns the cache key of the request, and whether it is cacheable
+func responseCacheKey(req Request) (string, bool) {
+	if _, ok := cacheableMethods[req.Method]; !ok {
+		return "", false
+	}
+
+	// the same params might be formatted differently
+	var params bytes.Buffer
+	if len(req.Params) > 0 {
+		if err := json.Compact(&params, req.Params); err != nil {
+			return "", false
+		}
+	}
+
+	return req.Method + ":" + params.String(), true
+}
+
+// txResponseCacheKey returns the cache key of the request, keyed by the hash of the
+// block including the queried transaction for the methods queried by transaction hash.
+// The results of the transactions reorganized into another block are never hit then,
+// and the ones not included yet are not cacheable.
+func txResponseCacheKey(req Request, lookup func(types.Hash) (types.Hash, bool)) (string, bool) {
+	key, ok := responseCacheKey(req)
+	if !ok || !cacheableMethods[req.Method] {
+		return key, ok
+	}
+
+	var params []json.RawMessage
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) == 0 {
+		return "", false
+	}
+
+	var txHash types.Hash
+	if err := json.Unmarshal(params[0], &txHash); err != nil {
+		return "", false
+	}
+
+	blockHash, ok := lookup(txHash)
+	if !ok {
+		return "", false
+	}
+
+	return key + "@" + blockHash.String(), true
+}
+
+// isCacheableResponse returns whether the response is found, the missing ones
+// might be found later
+func isCacheableResponse(data []byte) bool {
+	return len(data) > 0 && !bytes.Equal(data, []byte("null"))
+}
+
+type memoryCacheEntry struct {
+	key   string
+	value []byte
+}
+
+// memoryCache is a least recently used cache bounded by the size of the entries
+type memoryCache struct {
+	lock    sync.Mutex
+	maxSize int
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List // front is the most recently used
+}
+
+// NewMemoryCache creates a response cache holding up to maxSize bytes
+func NewMemoryCache(maxSize int) ResponseCache {
+	return &memoryCache{
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (c *memoryCache) Get(key string) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+
+	return elem.Value.(*memoryCacheEntry).value, true //nolint:forcetypeassert
+}
+
+func (c *memoryCache) Set(key string, value []byte) {
+	size := len(key) + len(value)
+	if size > c.maxSize {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+
+	for c.size+size > c.maxSize {
+		c.remove(c.lru.Back())
+	}
+
+	c.entries[key] = c.lru.PushFront(&memoryCacheEntry{key: key, value: value})
+	c.size += size
+}
+
+func (c *memoryCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*memoryCacheEntry) //nolint:forcetypeassert
+
+	delete(c.entries, entry.key)
+	c.size -= len(entry.key) + len(entry.value)
+}
+
+// layeredCache looks up the caches in order, the entries found in a later one are
+// copied to the earlier ones
+type layeredCache []ResponseCache
+
+func (c layeredCache) Get(key string) ([]byte, bool) {
+	for i, cache := range c {
+		if value, ok := cache.Get(key); ok {
+			for j := 0; j < i; j++ {
+				c[j].Set(key, value)
+			}
+
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+func (c layeredCache) Set(key string, value []byte) {
+	for _, cache := range c {
+		cache.Set(key, value)
+	}
+}
+
+// NewResponseCache creates the response cache of the memory size in bytes, backed by
+// the redis cache if set. It returns nil if neither is enabled.
+func NewResponseCache(memorySize int, redis ResponseCache) ResponseCache {
+	var caches layeredCache
+
+	if memorySize > 0 {
+		caches = append(caches, NewMemoryCache(memorySize))
+	}
+
+	if redis != nil {
+		caches = append(caches, redis)
+	}
+
+	switch len(caches) {
+	case 0:
+		return nil
+	case 1:
+		return caches[0]
+	default:
+		return caches
+	}
+}
@@ -0,0 +1,338 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// DefaultRedisCacheTTL is the expiry of the responses cached in redis
+	DefaultRedisCacheTTL = 24 * time.Hour
+
+	// redisCacheTimeout is the timeout of a redis command, the cache is skipped once exceeded
+	redisCacheTimeout = 500 * time.Millisecond
+
+	// redisCacheMaxIdle is the max number of the idle redis connections kept
+	redisCacheMaxIdle = 16
+
+	// redisCacheMaxReply is the max size of a redis reply
+	redisCacheMaxReply = 64 * 1024 * 1024
+
+	// redisCacheMinBackoff and redisCacheMaxBackoff bound the time redis is skipped
+	// once unreachable, doubled on each failed retry
+	redisCacheMinBackoff = time.Second
+	redisCacheMaxBackoff = time.Minute
+)
+
+var (
+	errInvalidRedisReply = errors.New("invalid redis reply")
+	errRedisCacheDown    = errors.New("redis cache is down")
+)
+
+// redisCache is the response cache stored in redis, which could be shared by the nodes
+// behind a load balancer. The failures are logged and taken as misses. Once redis is
+// unreachable, it is skipped for a backoff, and a single request retries it after.
+type redisCache struct {
+	logger   hclog.Logger
+	addr     string
+	username string
+	password string
+	db       int
+	prefix   string // prefix of the keys, the chains sharing one redis should differ
+	ttl      time.Duration
+
+	idle chan *redisConn
+
+	lock      sync.Mutex
+	backoff   time.Duration // zero if redis is up
+	downUntil time.Time     // redis is skipped until then
+}
+
+// NewRedisCache creates the response cache of the redis url, in the form of
+// redis://[[username]:password@]host[:port][/db]
+func NewRedisCache(logger hclog.Logger, rawURL, prefix string, ttl time.Duration) (ResponseCache, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("invalid redis url scheme: %s", u.Scheme)
+	}
+
+	c := &redisCache{
+		logger: logger.Named("redis-cache"),
+		addr:   u.Host,
+		prefix: prefix,
+		ttl:    ttl,
+		idle:   make(chan *redisConn, redisCacheMaxIdle),
+	}
+
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis db: %s", db)
+		}
+	}
+
+	// fail fast on a wrong config
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(conn, nil)
+
+	return c, nil
+}
+
+func (c *redisCache) Get(key string) ([]byte, bool) {
+	value, err := c.do("GET", c.prefix+key)
+	if err != nil {
+		c.logger.Debug("failed to get cached response", "key", key, "err", err)
+
+		return nil, false
+	}
+
+	return value, value != nil
+}
+
+func (c *redisCache) Set(key string, value []byte) {
+	if _, err := c.do("SET", c.prefix+key, string(value), "PX", strconv.FormatInt(c.ttl.Milliseconds(), 10)); err != nil {
+		c.logger.Debug("failed to cache response", "key", key, "err", err)
+	}
+}
+
+// do runs the command on an idle connection, and returns the bulk reply
+func (c *redisCache) do(args ...string) ([]byte, error) {
+	if !c.available() {
+		return nil, errRedisCacheDown
+	}
+
+	conn, err := c.get()
+	if err != nil {
+		c.markDown(err)
+
+		return nil, err
+	}
+
+	reply, err := conn.do(args...)
+
+	c.put(conn, err)
+
+	if isRedisConnError(err) {
+		c.markDown(err)
+	} else {
+		c.markUp()
+	}
+
+	return reply, err
+}
+
+// available returns whether redis should be tried. Once the backoff expires, only
+// the first caller retries it, the others skip it for another backoff.
+func (c *redisCache) available() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.backoff == 0 {
+		return true
+	}
+
+	now := time.Now()
+	if now.Before(c.downUntil) {
+		return false
+	}
+
+	c.downUntil = now.Add(c.backoff)
+
+	return true
+}
+
+// markDown skips redis for the backoff, doubled on each failed retry
+func (c *redisCache) markDown(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.backoff == 0 {
+		c.logger.Warn("redis is unreachable, skipped for a while", "err", err)
+
+		c.backoff = redisCacheMinBackoff
+	} else if c.backoff < redisCacheMaxBackoff {
+		c.backoff *= 2
+
+		if c.backoff > redisCacheMaxBackoff {
+			c.backoff = redisCacheMaxBackoff
+		}
+	}
+
+	c.downUntil = time.Now().Add(c.backoff)
+}
+
+// markUp resets the backoff once redis replies
+func (c *redisCache) markUp() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.backoff != 0 {
+		c.logger.Info("redis is reachable again")
+
+		c.backoff = 0
+		c.downUntil = time.Time{}
+	}
+}
+
+func (c *redisCache) get() (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+		return c.dial()
+	}
+}
+
+// put keeps the connection for reuse, unless it fails or there are enough idle ones
+func (c *redisCache) put(conn *redisConn, err error) {
+	if isRedisConnError(err) {
+		conn.Close()
+
+		return
+	}
+
+	select {
+	case c.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+func (c *redisCache) dial() (*redisConn, error) {
+	netConn, err := net.DialTimeout("tcp", c.addr, redisCacheTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+
+	if c.password != "" {
+		args := []string{"AUTH", c.password}
+		if c.username != "" {
+			args = []string{"AUTH", c.username, c.password}
+		}
+
+		if _, err := conn.do(args...); err != nil {
+			conn.Close()
+
+			return nil, err
+		}
+	}
+
+	if c.db != 0 {
+		if _, err := conn.do("SELECT", strconv.Itoa(c.db)); err != nil {
+			conn.Close()
+
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// redisError is the error replied by redis, the connection is still usable
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// isRedisConnError returns whether the error is a failure of the connection, rather
+// than replied by redis
+func isRedisConnError(err error) bool {
+	var redisErr redisError
+
+	return err != nil && !errors.As(err, &redisErr)
+}
+
+// redisConn is a connection speaking the redis serialization protocol
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (c *redisConn) Close() error {
+	return c.conn.Close()
+}
+
+// do sends the command and reads the reply, the nil bulk reply is returned as nil
+func (c *redisConn) do(args ...string) ([]byte, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(redisCacheTimeout)); err != nil {
+		return nil, err
+	}
+
+	var cmd strings.Builder
+
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if _, err := io.WriteString(c.conn, cmd.String()); err != nil {
+		return nil, err
+	}
+
+	return c.readReply()
+}
+
+func (c *redisConn) readReply() ([]byte, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	line = strings.TrimSuffix(line, "\r\n")
+	if len(line) == 0 {
+		return nil, errInvalidRedisReply
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return []byte(line[1:]), nil
+	case '-':
+		return nil, redisError(line[1:])
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size > redisCacheMaxReply {
+			return nil, errInvalidRedisReply
+		} else if size < 0 {
+			return nil, nil
+		}
+
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+
+		return data[:size], nil
+	default:
+		return nil, errInvalidRedisReply
+	}
+}
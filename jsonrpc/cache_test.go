@@ -0,0 +1,329 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache_Evict(t *testing.T) {
+	t.Parallel()
+
+	// room for two entries
+	cache := NewMemoryCache(8)
+
+	cache.Set("a", []byte("aaa"))
+	cache.Set("b", []byte("bbb"))
+
+	// a is the most recently used now
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+
+	cache.Set("c", []byte("ccc"))
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+
+	value, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("aaa"), value)
+
+	// too large to cache
+	cache.Set("d", []byte("ddddddddd"))
+
+	_, ok = cache.Get("d")
+	assert.False(t, ok)
+
+	_, ok = cache.Get("c")
+	assert.True(t, ok)
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	t.Parallel()
+
+	key1, ok := responseCacheKey(Request{Method: "eth_getBlockByHash", Params: []byte(`["0x01", true]`)})
+	assert.True(t, ok)
+
+	key2, ok := responseCacheKey(Request{Method: "eth_getBlockByHash", Params: []byte("[\n\"0x01\",true ]")})
+	assert.True(t, ok)
+	assert.Equal(t, key1, key2)
+
+	key3, _ := responseCacheKey(Request{Method: "eth_getBlockByHash", Params: []byte(`["0x01",false]`)})
+	assert.NotEqual(t, key1, key3)
+
+	_, ok = responseCacheKey(Request{Method: "eth_getBlockByNumber", Params: []byte(`["latest",true]`)})
+	assert.False(t, ok)
+}
+
+func TestTxResponseCacheKey(t *testing.T) {
+	t.Parallel()
+
+	var (
+		txHash = types.StringToHash("1")
+		block1 = types.StringToHash("2")
+		block2 = types.StringToHash("3")
+		params = []byte(`["` + txHash.String() + `"]`)
+		lookup = map[types.Hash]types.Hash{}
+	)
+
+	lookupTx := func(hash types.Hash) (types.Hash, bool) {
+		blockHash, ok := lookup[hash]
+
+		return blockHash, ok
+	}
+
+	// not included yet
+	_, ok := txResponseCacheKey(Request{Method: "eth_getTransactionReceipt", Params: params}, lookupTx)
+	assert.False(t, ok)
+
+	lookup[txHash] = block1
+
+	key1, ok := txResponseCacheKey(Request{Method: "eth_getTransactionReceipt", Params: params}, lookupTx)
+	assert.True(t, ok)
+
+	// reorganized into another block
+	lookup[txHash] = block2
+
+	key2, ok := txResponseCacheKey(Request{Method: "eth_getTransactionReceipt", Params: params}, lookupTx)
+	assert.True(t, ok)
+	assert.NotEqual(t, key1, key2)
+
+	// the ones queried by block hash are not looked up
+	key3, ok := txResponseCacheKey(Request{Method: "eth_getBlockByHash", Params: []byte(`["0x01",true]`)}, lookupTx)
+	assert.True(t, ok)
+
+	key4, _ := responseCacheKey(Request{Method: "eth_getBlockByHash", Params: []byte(`["0x01",true]`)})
+	assert.Equal(t, key4, key3)
+
+	_, ok = txResponseCacheKey(Request{Method: "debug_traceTransaction", Params: []byte(`[1]`)}, lookupTx)
+	assert.False(t, ok)
+}
+
+type cacheTestService struct {
+	calls int
+}
+
+func (s *cacheTestService) GetCodeByHash(hash types.Hash) (interface{}, error) {
+	s.calls++
+
+	if hash == types.ZeroHash {
+		return nil, nil
+	}
+
+	return "0x01", nil
+}
+
+func TestDispatcher_ResponseCache(t *testing.T) {
+	t.Parallel()
+
+	srv := &cacheTestService{}
+
+	dispatcher := newDispatcher(hclog.NewNullLogger(), NilMetrics(), newMockStore(), 0, 0, 0, 0, nil)
+	dispatcher.registerService("debug", srv)
+	dispatcher.responseCache = NewMemoryCache(1024)
+
+	handleReq := func(params string) []byte {
+		t.Helper()
+
+		data, err := dispatcher.handleReq(Request{Method: "debug_getCodeByHash", Params: []byte(params)})
+		assert.NoError(t, err)
+
+		return data
+	}
+
+	hash := types.StringToHash("1").String()
+
+	assert.Equal(t, []byte(`"0x01"`), handleReq(`["`+hash+`"]`))
+	assert.Equal(t, []byte(`"0x01"`), handleReq(` [ "`+hash+`" ]`))
+	assert.Equal(t, 1, srv.calls)
+
+	// the missing ones are not cached
+	handleReq(`["` + types.ZeroHash.String() + `"]`)
+	handleReq(`["` + types.ZeroHash.String() + `"]`)
+	assert.Equal(t, 3, srv.calls)
+}
+
+// fakeRedis serves the GET and SET commands of the redis protocol
+type fakeRedis struct {
+	lock sync.Mutex
+	data map[string]string
+}
+
+func newFakeRedis(t *testing.T) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	t.Cleanup(func() { lis.Close() })
+
+	r := &fakeRedis{data: make(map[string]string)}
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			go r.serve(conn)
+		}
+	}()
+
+	return "redis://" + lis.Addr().String()
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	for {
+		args, err := readFakeRedisCommand(reader)
+		if err != nil {
+			return
+		}
+
+		r.lock.Lock()
+
+		switch strings.ToUpper(args[0]) {
+		case "GET":
+			if value, ok := r.data[args[1]]; ok {
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(value), value)
+			} else {
+				fmt.Fprint(conn, "$-1\r\n")
+			}
+		case "SET":
+			r.data[args[1]] = args[2]
+
+			fmt.Fprint(conn, "+OK\r\n")
+		default:
+			fmt.Fprint(conn, "-ERR unknown command\r\n")
+		}
+
+		r.lock.Unlock()
+	}
+}
+
+func readFakeRedisCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, count)
+
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+
+		args[i] = string(arg[:size])
+	}
+
+	return args, nil
+}
+
+func TestRedisCache(t *testing.T) {
+	t.Parallel()
+
+	url := newFakeRedis(t)
+
+	redis, err := NewRedisCache(hclog.NewNullLogger(), url, "test:", time.Minute)
+	assert.NoError(t, err)
+
+	_, ok := redis.Get("a")
+	assert.False(t, ok)
+
+	redis.Set("a", []byte("value\r\nwith line breaks"))
+
+	value, ok := redis.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value\r\nwith line breaks"), value)
+
+	t.Run("layered on the memory cache", func(t *testing.T) {
+		t.Parallel()
+
+		memory := NewMemoryCache(1024)
+		cache := layeredCache{memory, redis}
+
+		value, ok := cache.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, []byte("value\r\nwith line breaks"), value)
+
+		// copied to the memory cache
+		_, ok = memory.Get("a")
+		assert.True(t, ok)
+	})
+
+	_, err = NewRedisCache(hclog.NewNullLogger(), "http://127.0.0.1:6379", "test:", time.Minute)
+	assert.Error(t, err)
+}
+
+func TestRedisCache_Backoff(t *testing.T) {
+	t.Parallel()
+
+	url := newFakeRedis(t)
+
+	cache, err := NewRedisCache(hclog.NewNullLogger(), url, "test:", time.Minute)
+	assert.NoError(t, err)
+
+	redis, _ := cache.(*redisCache)
+	redis.Set("a", []byte("value"))
+
+	// redis becomes unreachable
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	addr := redis.addr
+	redis.addr = lis.Addr().String()
+
+	lis.Close()
+
+	for len(redis.idle) > 0 {
+		(<-redis.idle).Close()
+	}
+
+	_, ok := redis.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, redisCacheMinBackoff, redis.backoff)
+
+	// skipped within the backoff, even though redis is back
+	redis.addr = addr
+
+	_, err = redis.do("GET", "test:a")
+	assert.ErrorIs(t, err, errRedisCacheDown)
+
+	// retried once the backoff expires
+	redis.downUntil = time.Now()
+
+	value, ok := redis.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), value)
+	assert.Zero(t, redis.backoff)
+}
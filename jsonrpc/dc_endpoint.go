@@ -0,0 +1,708 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/contracts/systemcontracts"
+	"github.com/dogechain-lab/dogechain/contracts/validatorset"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/state"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+const (
+	// maxStorageSlots is the max number of slots queried in one request
+	maxStorageSlots = 1024
+	// maxBalanceHistoryPoints is the max number of balances queried in one request
+	maxBalanceHistoryPoints = 1024
+	// maxListedAccounts is the max number of accounts listed in one request
+	maxListedAccounts = 1024
+	// defaultListedAccounts is the number of accounts listed without a limit
+	defaultListedAccounts = 256
+	// maxSystemTxBlocks is the max number of blocks read for the system transactions in one request
+	maxSystemTxBlocks = 1024
+)
+
+var (
+	ErrTooManyStorageSlots   = fmt.Errorf("too many storage slots, max %d", maxStorageSlots)
+	ErrTooManyHistoryPoints  = fmt.Errorf("too many balance history points, max %d", maxBalanceHistoryPoints)
+	ErrTooManyAccounts       = fmt.Errorf("too many accounts, max %d", maxListedAccounts)
+	ErrInvalidBlockRange     = errors.New("invalid block range")
+	ErrInvalidStatsInterval  = errors.New("invalid stats interval, expected hour or day")
+	ErrInvalidSystemTxType   = errors.New("invalid system transaction type, expected deposit or slash")
+	ErrTooManySystemTxBlocks = fmt.Errorf(
+		"too many blocks to read for the system transactions, max %d, narrow the range or enable the index of the slashes",
+		maxSystemTxBlocks,
+	)
+)
+
+// chainStatsIntervals are the interval names of the chain stats, in seconds
+var chainStatsIntervals = map[string]uint64{
+	"hour": blockchain.ChainStatsHour,
+	"day":  blockchain.ChainStatsDay,
+}
+
+// the denomination of the native token, whose smallest unit is wei like ether
+const (
+	nativeTokenName     = "Dogecoin"
+	nativeTokenSymbol   = "DOGE"
+	nativeTokenDecimals = 18
+)
+
+// nativeUnits are the named units of the native token, by the decimals in wei
+var nativeUnits = []*nativeUnit{
+	{Name: "wei", Decimals: 0},
+	{Name: "gwei", Decimals: 9},
+	{Name: "doge", Decimals: nativeTokenDecimals},
+}
+
+// dcStore provides access to the methods needed by dc endpoint
+type dcStore interface {
+	ethStateStore
+	ethBlockchainStore
+
+	// IterateAccounts walks the accounts within the state root in the order of their
+	// address hashes, from the start hash (inclusive). The walk stops once fn returns false.
+	IterateAccounts(root types.Hash, start types.Hash, fn func(hash types.Hash, account *state.Account) bool) error
+
+	// GetPreimage returns the address or storage slot hashed to the trie key, if recorded
+	GetPreimage(hash types.Hash) ([]byte, bool)
+
+	// GetBlockExecutionStats returns the execution stats of the recent verified blocks in range
+	GetBlockExecutionStats(from, to uint64) []*blockchain.BlockExecutionStats
+
+	// GetChainStats returns the usage of the canonical blocks within the time range,
+	// aggregated by the interval
+	GetChainStats(from, to, interval uint64) ([]*blockchain.ChainStats, error)
+
+	// ValidateTx runs the admission of the tx pool on the transaction without adding
+	// it, and returns all the violated rules
+	ValidateTx(tx *types.Transaction) []error
+
+	// GetChainParams returns the params of the chain
+	GetChainParams() *chain.Params
+
+	// FilterSystemTxBlocks returns the block numbers within [from, to] which might contain
+	// system transactions of the type, false if the index is disabled
+	FilterSystemTxBlocks(txType blockchain.SystemTxType, from, to uint64) ([]uint64, bool)
+}
+
+// Dc is the dogechain specific jsonrpc endpoint
+type Dc struct {
+	store dcStore
+
+	metrics *Metrics
+}
+
+type storageSlotsOptions struct {
+	Proofs bool `json:"proofs"`
+}
+
+type storageSlot struct {
+	Key   types.Hash `json:"key"`
+	Value types.Hash `json:"value"`
+	Proof []argBytes `json:"proof,omitempty"`
+}
+
+type storageSlotsResult struct {
+	Address      types.Address  `json:"address"`
+	StorageHash  types.Hash     `json:"storageHash"`
+	AccountProof []argBytes     `json:"accountProof,omitempty"`
+	Storage      []*storageSlot `json:"storage"`
+}
+
+// GetStorageSlots returns the values of multiple storage slots of the account
+// at the referenced block, with merkle proofs attached if required
+func (d *Dc) GetStorageSlots(
+	address types.Address,
+	slots []types.Hash,
+	filter BlockNumberOrHash,
+	options *storageSlotsOptions,
+) (interface{}, error) {
+	d.metrics.DcAPICounterInc(DcGetStorageSlotsLabel)
+
+	if len(slots) > maxStorageSlots {
+		return nil, ErrTooManyStorageSlots
+	}
+
+	// The filter is empty, use the latest block by default
+	if filter.BlockNumber == nil && filter.BlockHash == nil {
+		filter.BlockNumber, _ = CreateBlockNumberPointer(LatestBlockFlag)
+	}
+
+	header, err := getHeaderFromBlockNumberOrHash(d.store, &filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header from block hash or block number")
+	}
+
+	withProofs := options != nil && options.Proofs
+
+	result := &storageSlotsResult{
+		Address:     address,
+		StorageHash: types.EmptyRootHash,
+		Storage:     make([]*storageSlot, len(slots)),
+	}
+
+	values := make([]types.Hash, len(slots))
+
+	acc, err := d.store.GetAccount(header.StateRoot, address)
+	if err != nil && !errors.Is(err, ErrStateNotFound) {
+		return nil, err
+	}
+
+	// Account not found, all slots are empty
+	if acc != nil {
+		result.StorageHash = acc.Root
+
+		if values, err = d.store.GetStorageSlots(acc.Root, slots); err != nil {
+			return nil, err
+		}
+	}
+
+	if withProofs {
+		proof, err := d.store.GetAccountProof(header.StateRoot, address)
+		if err != nil {
+			return nil, err
+		}
+
+		result.AccountProof = toArgBytesList(proof)
+	}
+
+	for i, slot := range slots {
+		item := &storageSlot{
+			Key:   slot,
+			Value: values[i],
+		}
+
+		if withProofs {
+			proof, err := d.store.GetStorageProof(result.StorageHash, slot)
+			if err != nil {
+				return nil, err
+			}
+
+			item.Proof = toArgBytesList(proof)
+		}
+
+		result.Storage[i] = item
+	}
+
+	return result, nil
+}
+
+type balancePoint struct {
+	BlockNumber argUint64 `json:"blockNumber"`
+	Balance     argBig    `json:"balance"`
+}
+
+// GetBalanceHistory returns the account balances from block 'fromBlock' to 'toBlock'
+// (inclusive) every 'step' blocks (1 by default). The number of points is capped.
+func (d *Dc) GetBalanceHistory(
+	address types.Address,
+	fromBlock BlockNumber,
+	toBlock BlockNumber,
+	step *argUint64,
+) (interface{}, error) {
+	d.metrics.DcAPICounterInc(DcGetBalanceHistoryLabel)
+
+	fromHeader, err := getBlockHeader(d.store, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	toHeader, err := getBlockHeader(d.store, toBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := fromHeader.Number, toHeader.Number
+	if from > to {
+		return nil, ErrInvalidBlockRange
+	}
+
+	interval := uint64(1)
+	if step != nil && *step > 0 {
+		interval = uint64(*step)
+	}
+
+	// cost budget, every point costs one state lookup
+	count := (to-from)/interval + 1
+	if count > maxBalanceHistoryPoints {
+		return nil, ErrTooManyHistoryPoints
+	}
+
+	points := make([]*balancePoint, 0, count)
+
+	for i := uint64(0); i < count; i++ {
+		number := from + i*interval
+
+		header, ok := d.store.GetHeaderByNumber(number)
+		if !ok {
+			return nil, fmt.Errorf("error fetching block number %d header", number)
+		}
+
+		point := &balancePoint{
+			BlockNumber: argUint64(number),
+		}
+
+		acc, err := d.store.GetAccount(header.StateRoot, address)
+		if errors.Is(err, ErrStateNotFound) {
+			// Account not found, balance is zero
+			point.Balance = argBig(*new(big.Int))
+		} else if err != nil {
+			return nil, err
+		} else {
+			point.Balance = argBig(*acc.Balance)
+		}
+
+		points = append(points, point)
+	}
+
+	return points, nil
+}
+
+type listedAccount struct {
+	Hash        types.Hash     `json:"hash"`
+	Address     *types.Address `json:"address,omitempty"` // nil unless the preimage is recorded
+	Nonce       argUint64      `json:"nonce"`
+	Balance     argBig         `json:"balance"`
+	StorageHash types.Hash     `json:"storageHash"`
+	CodeHash    types.Hash     `json:"codeHash"`
+}
+
+type listAccountsResult struct {
+	Accounts []*listedAccount `json:"accounts"`
+	Next     *types.Hash      `json:"next"` // start of the next page, null once all listed
+}
+
+// ListAccounts returns a page of the accounts at the referenced block, in the order
+// of their address hashes, from the 'start' hash (inclusive). Up to 'limit' accounts
+// are listed, and the hash to resume from is returned as 'next'.
+func (d *Dc) ListAccounts(
+	filter BlockNumberOrHash,
+	start *types.Hash,
+	limit *argUint64,
+) (interface{}, error) {
+	d.metrics.DcAPICounterInc(DcListAccountsLabel)
+
+	count := uint64(defaultListedAccounts)
+	if limit != nil && *limit > 0 {
+		count = uint64(*limit)
+	}
+
+	if count > maxListedAccounts {
+		return nil, ErrTooManyAccounts
+	}
+
+	// The filter is empty, use the latest block by default
+	if filter.BlockNumber == nil && filter.BlockHash == nil {
+		filter.BlockNumber, _ = CreateBlockNumberPointer(LatestBlockFlag)
+	}
+
+	header, err := getHeaderFromBlockNumberOrHash(d.store, &filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header from block hash or block number")
+	}
+
+	from := types.Hash{}
+	if start != nil {
+		from = *start
+	}
+
+	result := &listAccountsResult{
+		Accounts: make([]*listedAccount, 0, count),
+	}
+
+	err = d.store.IterateAccounts(header.StateRoot, from, func(hash types.Hash, acc *state.Account) bool {
+		// one more account is walked to tell where the next page starts
+		if uint64(len(result.Accounts)) == count {
+			next := hash
+			result.Next = &next
+
+			return false
+		}
+
+		account := &listedAccount{
+			Hash:        hash,
+			Nonce:       argUint64(acc.Nonce),
+			Balance:     argBig(*acc.Balance),
+			StorageHash: acc.Root,
+			CodeHash:    types.BytesToHash(acc.CodeHash),
+		}
+
+		if preimage, ok := d.store.GetPreimage(hash); ok && len(preimage) == types.AddressLength {
+			addr := types.BytesToAddress(preimage)
+			account.Address = &addr
+		}
+
+		result.Accounts = append(result.Accounts, account)
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type blockExecutionStats struct {
+	Number          argUint64  `json:"number"`
+	Hash            types.Hash `json:"hash"`
+	ExecutionMicros argUint64  `json:"executionMicros"`
+	TxCount         argUint64  `json:"txCount"`
+	SystemTxCount   argUint64  `json:"systemTxCount"`
+	GasUsed         argUint64  `json:"gasUsed"`
+	AccountReads    argUint64  `json:"accountReads"`
+	StorageReads    argUint64  `json:"storageReads"`
+	StorageWrites   argUint64  `json:"storageWrites"`
+	NodeLoads       argUint64  `json:"nodeLoads"`
+	NodeCacheHits   argUint64  `json:"nodeCacheHits"`
+	Imported        bool       `json:"imported"`
+}
+
+// GetBlockExecutionStats returns the execution stats of the blocks from 'fromBlock'
+// to 'toBlock' (inclusive), collected when the node verified them. The proposals never
+// imported are listed as well, after the imported block of the same number. Only the
+// recent blocks are kept, and the blocks not executed by the node are skipped.
+func (d *Dc) GetBlockExecutionStats(fromBlock BlockNumber, toBlock BlockNumber) (interface{}, error) {
+	d.metrics.DcAPICounterInc(DcGetBlockExecutionStatsLabel)
+
+	fromHeader, err := getBlockHeader(d.store, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	toHeader, err := getBlockHeader(d.store, toBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromHeader.Number > toHeader.Number {
+		return nil, ErrInvalidBlockRange
+	}
+
+	list := d.store.GetBlockExecutionStats(fromHeader.Number, toHeader.Number)
+	result := make([]*blockExecutionStats, 0, len(list))
+
+	for _, stats := range list {
+		result = append(result, &blockExecutionStats{
+			Number:          argUint64(stats.Number),
+			Hash:            stats.Hash,
+			ExecutionMicros: argUint64(stats.ExecutionTime.Microseconds()),
+			TxCount:         argUint64(stats.TxCount),
+			SystemTxCount:   argUint64(stats.SystemTxCount),
+			GasUsed:         argUint64(stats.GasUsed),
+			AccountReads:    argUint64(stats.StateAccess.AccountReads),
+			StorageReads:    argUint64(stats.StateAccess.StorageReads),
+			StorageWrites:   argUint64(stats.StateAccess.StorageWrites),
+			NodeLoads:       argUint64(stats.StateAccess.NodeLoads),
+			NodeCacheHits:   argUint64(stats.StateAccess.NodeCacheHits),
+			Imported:        stats.Imported,
+		})
+	}
+
+	return result, nil
+}
+
+type systemTransaction struct {
+	BlockNumber argUint64               `json:"blockNumber"`
+	BlockHash   types.Hash              `json:"blockHash"`
+	TxHash      types.Hash              `json:"transactionHash"`
+	TxIndex     argUint64               `json:"transactionIndex"`
+	Type        blockchain.SystemTxType `json:"type"`
+	From        types.Address           `json:"from"`
+}
+
+// GetSystemTransactions returns the consensus system transactions (deposit and slash)
+// of the canonical blocks from 'fromBlock' to 'toBlock' (inclusive), of the type if
+// specified. The system transaction index narrows the blocks read for the slashes if
+// enabled, the deposits are in every block and are not indexed.
+func (d *Dc) GetSystemTransactions(
+	fromBlock BlockNumber,
+	toBlock BlockNumber,
+	txType *string,
+) (interface{}, error) {
+	d.metrics.DcAPICounterInc(DcGetSystemTransactionsLabel)
+
+	txTypes := blockchain.SystemTxTypes
+	if txType != nil {
+		if !isSystemTxType(*txType) {
+			return nil, ErrInvalidSystemTxType
+		}
+
+		txTypes = []blockchain.SystemTxType{blockchain.SystemTxType(*txType)}
+	}
+
+	fromHeader, err := getBlockHeader(d.store, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	toHeader, err := getBlockHeader(d.store, toBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := fromHeader.Number, toHeader.Number
+	if from > to {
+		return nil, ErrInvalidBlockRange
+	}
+
+	numbers, err := d.systemTxBlocks(txTypes, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*systemTransaction, 0)
+
+	for _, number := range numbers {
+		block, ok := d.store.GetBlockByNumber(number, true)
+		if !ok {
+			return nil, fmt.Errorf("error fetching block number %d", number)
+		}
+
+		for i, t := range d.store.GetSystemTxTypes(block.Header, block.Transactions) {
+			if t == "" || (txType != nil && string(t) != *txType) {
+				continue
+			}
+
+			tx := block.Transactions[i]
+
+			result = append(result, &systemTransaction{
+				BlockNumber: argUint64(number),
+				BlockHash:   block.Hash(),
+				TxHash:      tx.Hash(),
+				TxIndex:     argUint64(i),
+				Type:        t,
+				From:        tx.From,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// systemTxBlocks returns the block numbers within [from, to] to read for the system
+// transactions of the types, in ascending order
+func (d *Dc) systemTxBlocks(txTypes []blockchain.SystemTxType, from, to uint64) ([]uint64, error) {
+	var (
+		candidates = map[uint64]struct{}{}
+		indexed    = true
+	)
+
+	for _, t := range txTypes {
+		numbers, ok := d.store.FilterSystemTxBlocks(t, from, to)
+		if !ok {
+			indexed = false
+
+			break
+		}
+
+		for _, n := range numbers {
+			candidates[n] = struct{}{}
+		}
+	}
+
+	if !indexed {
+		// every block is read without the index
+		if to-from >= maxSystemTxBlocks {
+			return nil, ErrTooManySystemTxBlocks
+		}
+
+		numbers := make([]uint64, 0, to-from+1)
+		for n := from; n <= to; n++ {
+			numbers = append(numbers, n)
+		}
+
+		return numbers, nil
+	}
+
+	if len(candidates) > maxSystemTxBlocks {
+		return nil, ErrTooManySystemTxBlocks
+	}
+
+	numbers := make([]uint64, 0, len(candidates))
+	for n := range candidates {
+		numbers = append(numbers, n)
+	}
+
+	sort.Slice(numbers, func(i, j int) bool {
+		return numbers[i] < numbers[j]
+	})
+
+	return numbers, nil
+}
+
+func isSystemTxType(txType string) bool {
+	for _, t := range blockchain.SystemTxTypes {
+		if string(t) == txType {
+			return true
+		}
+	}
+
+	return false
+}
+
+type chainStats struct {
+	Time         argUint64 `json:"time"`
+	Blocks       argUint64 `json:"blocks"`
+	TxCount      argUint64 `json:"txCount"`
+	GasUsed      argUint64 `json:"gasUsed"`
+	GasLimit     argUint64 `json:"gasLimit"`
+	GasUsedRatio float64   `json:"gasUsedRatio"`
+	AvgGasPrice  argBig    `json:"avgGasPrice"`
+}
+
+// GetChainStats returns the gas used ratio, transaction count and average gas price
+// of the canonical blocks with timestamps from 'fromTime' to 'toTime' (inclusive),
+// aggregated per hour or day. The intervals without blocks are skipped.
+func (d *Dc) GetChainStats(fromTime argUint64, toTime argUint64, interval string) (interface{}, error) {
+	d.metrics.DcAPICounterInc(DcGetChainStatsLabel)
+
+	seconds, ok := chainStatsIntervals[interval]
+	if !ok {
+		return nil, ErrInvalidStatsInterval
+	}
+
+	if fromTime > toTime {
+		return nil, ErrInvalidBlockRange
+	}
+
+	list, err := d.store.GetChainStats(uint64(fromTime), uint64(toTime), seconds)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*chainStats, 0, len(list))
+
+	for _, stats := range list {
+		result = append(result, &chainStats{
+			Time:         argUint64(stats.Time),
+			Blocks:       argUint64(stats.Blocks),
+			TxCount:      argUint64(stats.TxCount),
+			GasUsed:      argUint64(stats.GasUsed),
+			GasLimit:     argUint64(stats.GasLimit),
+			GasUsedRatio: stats.GasUsedRatio(),
+			AvgGasPrice:  argBig(*stats.AvgGasPrice()),
+		})
+	}
+
+	return result, nil
+}
+
+type validateTransactionResult struct {
+	Hash       types.Hash `json:"hash"`
+	Valid      bool       `json:"valid"`
+	Violations []string   `json:"violations"`
+}
+
+// ValidateTransaction runs the admission of the tx pool on the raw transaction in dry
+// run, and returns all the violated rules instead of the first one. The transaction
+// is not added to the pool nor broadcast.
+func (d *Dc) ValidateTransaction(input string) (interface{}, error) {
+	d.metrics.DcAPICounterInc(DcValidateTransactionLabel)
+
+	buf, err := hex.DecodeHex(input)
+	if err != nil {
+		return nil, fmt.Errorf("raw tx input decode hex err: %w", err)
+	}
+
+	tx := &types.Transaction{}
+	if err := tx.UnmarshalRLP(buf); err != nil {
+		return nil, err
+	}
+
+	errs := d.store.ValidateTx(tx)
+
+	result := &validateTransactionResult{
+		Hash:       tx.Hash(),
+		Valid:      len(errs) == 0,
+		Violations: make([]string, len(errs)),
+	}
+
+	for i, err := range errs {
+		result.Violations[i] = err.Error()
+	}
+
+	return result, nil
+}
+
+type nativeUnit struct {
+	Name     string    `json:"name"`
+	Decimals argUint64 `json:"decimals"`
+}
+
+type denomination struct {
+	Name     string        `json:"name"`
+	Symbol   string        `json:"symbol"`
+	Decimals argUint64     `json:"decimals"`
+	Units    []*nativeUnit `json:"units"`
+}
+
+type chainConstantsResult struct {
+	ChainID          argUint64                `json:"chainId"`
+	Denomination     *denomination            `json:"denomination"`
+	BlockGasTarget   argUint64                `json:"blockGasTarget"`
+	BlockGasLimit    argUint64                `json:"blockGasLimit"`
+	SystemContracts  map[string]types.Address `json:"systemContracts"`
+	SystemTxGasLimit argUint64                `json:"systemTxGasLimit"`
+	// the blocks from which the bridge and the system transactions are enabled,
+	// nil if never
+	BridgeFromBlock   *argUint64 `json:"bridgeFromBlock"`
+	SystemTxFromBlock *argUint64 `json:"systemTxFromBlock"`
+}
+
+// ChainConstants returns the constants of the chain which differ from the Ethereum
+// defaults, so that the SDKs do not have to hardcode them
+func (d *Dc) ChainConstants() (interface{}, error) {
+	d.metrics.DcAPICounterInc(DcChainConstantsLabel)
+
+	params := d.store.GetChainParams()
+
+	result := &chainConstantsResult{
+		ChainID: argUint64(params.ChainID),
+		Denomination: &denomination{
+			Name:     nativeTokenName,
+			Symbol:   nativeTokenSymbol,
+			Decimals: nativeTokenDecimals,
+			Units:    nativeUnits,
+		},
+		BlockGasTarget: argUint64(params.BlockGasTarget),
+		BlockGasLimit:  argUint64(d.store.Header().GasLimit),
+		SystemContracts: map[string]types.Address{
+			"validatorSet": systemcontracts.AddrValidatorSetContract,
+			"bridge":       systemcontracts.AddrBridgeContract,
+			"vault":        systemcontracts.AddrVaultContract,
+		},
+		SystemTxGasLimit: argUint64(validatorset.SystemTransactionGasLimit),
+	}
+
+	if forks := params.Forks; forks != nil {
+		result.BridgeFromBlock = forkBlock(forks.Portland)
+		result.SystemTxFromBlock = forkBlock(forks.Detroit)
+	}
+
+	return result, nil
+}
+
+func forkBlock(fork *chain.Fork) *argUint64 {
+	if fork == nil {
+		return nil
+	}
+
+	return argUintPtr(uint64(*fork))
+}
+
+func toArgBytesList(list [][]byte) []argBytes {
+	res := make([]argBytes, len(list))
+	for i, b := range list {
+		res[i] = argBytes(b)
+	}
+
+	return res
+}
@@ -0,0 +1,592 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/contracts/systemcontracts"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/state"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDcStore struct {
+	dcStore
+
+	header   *types.Header
+	headers  map[uint64]*types.Header
+	accounts map[types.Address]*state.Account
+	storage  map[types.Hash]map[types.Hash]types.Hash
+	// states overrides accounts when set, indexed by state root
+	states map[types.Hash]map[types.Address]*state.Account
+	// hashedAccounts are the accounts indexed by address hash
+	hashedAccounts map[types.Hash]*state.Account
+	// preimages are the recorded addresses, indexed by hash
+	preimages map[types.Hash][]byte
+
+	executionStats []*blockchain.BlockExecutionStats
+	chainStats     []*blockchain.ChainStats
+
+	// violations are the rules violated by the validated transactions
+	violations []error
+
+	params *chain.Params
+
+	blocks        map[uint64]*types.Block
+	systemTxs     map[types.Hash]blockchain.SystemTxType
+	systemTxIndex map[blockchain.SystemTxType][]uint64 // nil if disabled
+}
+
+func (m *mockDcStore) GetBlockByNumber(n uint64, full bool) (*types.Block, bool) {
+	block, ok := m.blocks[n]
+
+	return block, ok
+}
+
+func (m *mockDcStore) GetSystemTxTypes(header *types.Header, txs []*types.Transaction) []blockchain.SystemTxType {
+	txTypes := make([]blockchain.SystemTxType, len(txs))
+	for i, tx := range txs {
+		txTypes[i] = m.systemTxs[tx.Hash()]
+	}
+
+	return txTypes
+}
+
+func (m *mockDcStore) FilterSystemTxBlocks(txType blockchain.SystemTxType, from, to uint64) ([]uint64, bool) {
+	if m.systemTxIndex == nil {
+		return nil, false
+	}
+
+	numbers := []uint64{}
+
+	for _, n := range m.systemTxIndex[txType] {
+		if n >= from && n <= to {
+			numbers = append(numbers, n)
+		}
+	}
+
+	return numbers, true
+}
+
+func (m *mockDcStore) ValidateTx(tx *types.Transaction) []error {
+	return m.violations
+}
+
+func (m *mockDcStore) GetChainParams() *chain.Params {
+	return m.params
+}
+
+func (m *mockDcStore) GetChainStats(from, to, interval uint64) ([]*blockchain.ChainStats, error) {
+	return m.chainStats, nil
+}
+
+func (m *mockDcStore) GetBlockExecutionStats(from, to uint64) []*blockchain.BlockExecutionStats {
+	result := []*blockchain.BlockExecutionStats{}
+
+	for _, stats := range m.executionStats {
+		if stats.Number >= from && stats.Number <= to {
+			result = append(result, stats)
+		}
+	}
+
+	return result
+}
+
+func (m *mockDcStore) Header() *types.Header {
+	return m.header
+}
+
+func (m *mockDcStore) GetHeaderByNumber(n uint64) (*types.Header, bool) {
+	header, ok := m.headers[n]
+
+	return header, ok
+}
+
+func (m *mockDcStore) GetAccount(root types.Hash, addr types.Address) (*state.Account, error) {
+	accounts := m.accounts
+	if m.states != nil {
+		accounts = m.states[root]
+	}
+
+	acc, ok := accounts[addr]
+	if !ok {
+		return nil, ErrStateNotFound
+	}
+
+	return acc, nil
+}
+
+func (m *mockDcStore) GetStorageSlots(storageRoot types.Hash, slots []types.Hash) ([]types.Hash, error) {
+	values := make([]types.Hash, len(slots))
+	for i, slot := range slots {
+		values[i] = m.storage[storageRoot][slot]
+	}
+
+	return values, nil
+}
+
+func (m *mockDcStore) IterateAccounts(
+	root types.Hash,
+	start types.Hash,
+	fn func(hash types.Hash, account *state.Account) bool,
+) error {
+	hashes := make([]types.Hash, 0, len(m.hashedAccounts))
+	for hash := range m.hashedAccounts {
+		hashes = append(hashes, hash)
+	}
+
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i].Bytes(), hashes[j].Bytes()) < 0
+	})
+
+	for _, hash := range hashes {
+		if bytes.Compare(hash.Bytes(), start.Bytes()) < 0 {
+			continue
+		}
+
+		if !fn(hash, m.hashedAccounts[hash]) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (m *mockDcStore) GetPreimage(hash types.Hash) ([]byte, bool) {
+	preimage, ok := m.preimages[hash]
+
+	return preimage, ok
+}
+
+func (m *mockDcStore) GetAccountProof(root types.Hash, addr types.Address) ([][]byte, error) {
+	return [][]byte{root.Bytes(), addr.Bytes()}, nil
+}
+
+func (m *mockDcStore) GetStorageProof(storageRoot types.Hash, slot types.Hash) ([][]byte, error) {
+	return [][]byte{storageRoot.Bytes(), slot.Bytes()}, nil
+}
+
+func TestDc_GetStorageSlots(t *testing.T) {
+	var (
+		addr        = types.StringToAddress("1")
+		storageRoot = types.StringToHash("2")
+		slot1       = types.StringToHash("10")
+		slot2       = types.StringToHash("11")
+		value1      = types.StringToHash("20")
+	)
+
+	store := &mockDcStore{
+		header: &types.Header{StateRoot: types.StringToHash("3")},
+		accounts: map[types.Address]*state.Account{
+			addr: {Balance: big.NewInt(1), Root: storageRoot},
+		},
+		storage: map[types.Hash]map[types.Hash]types.Hash{
+			storageRoot: {slot1: value1},
+		},
+	}
+	dc := &Dc{store, NilMetrics()}
+
+	t.Run("without proofs", func(t *testing.T) {
+		res, err := dc.GetStorageSlots(addr, []types.Hash{slot1, slot2}, BlockNumberOrHash{}, nil)
+		assert.NoError(t, err)
+
+		result, ok := res.(*storageSlotsResult)
+		assert.True(t, ok)
+		assert.Equal(t, storageRoot, result.StorageHash)
+		assert.Nil(t, result.AccountProof)
+		assert.Len(t, result.Storage, 2)
+		assert.Equal(t, value1, result.Storage[0].Value)
+		assert.Equal(t, types.ZeroHash, result.Storage[1].Value)
+		assert.Nil(t, result.Storage[0].Proof)
+	})
+
+	t.Run("with proofs", func(t *testing.T) {
+		res, err := dc.GetStorageSlots(addr, []types.Hash{slot1}, BlockNumberOrHash{}, &storageSlotsOptions{Proofs: true})
+		assert.NoError(t, err)
+
+		result, ok := res.(*storageSlotsResult)
+		assert.True(t, ok)
+		assert.Len(t, result.AccountProof, 2)
+		assert.Len(t, result.Storage[0].Proof, 2)
+		assert.Equal(t, argBytes(slot1.Bytes()), result.Storage[0].Proof[1])
+	})
+
+	t.Run("account not found", func(t *testing.T) {
+		res, err := dc.GetStorageSlots(types.StringToAddress("ff"), []types.Hash{slot1}, BlockNumberOrHash{}, nil)
+		assert.NoError(t, err)
+
+		result, ok := res.(*storageSlotsResult)
+		assert.True(t, ok)
+		assert.Equal(t, types.EmptyRootHash, result.StorageHash)
+		assert.Equal(t, types.ZeroHash, result.Storage[0].Value)
+	})
+
+	t.Run("too many slots", func(t *testing.T) {
+		_, err := dc.GetStorageSlots(addr, make([]types.Hash, maxStorageSlots+1), BlockNumberOrHash{}, nil)
+		assert.ErrorIs(t, err, ErrTooManyStorageSlots)
+	})
+}
+
+func TestDc_ListAccounts(t *testing.T) {
+	store := &mockDcStore{
+		header:         &types.Header{StateRoot: types.StringToHash("3")},
+		hashedAccounts: map[types.Hash]*state.Account{},
+		preimages:      map[types.Hash][]byte{},
+	}
+
+	hashes := make([]types.Hash, 5)
+	for i := range hashes {
+		hashes[i] = types.StringToHash(fmt.Sprintf("%x", i+1))
+		store.hashedAccounts[hashes[i]] = &state.Account{
+			Nonce:    uint64(i),
+			Balance:  big.NewInt(int64(i)),
+			Root:     types.EmptyRootHash,
+			CodeHash: types.StringToHash("ff").Bytes(),
+		}
+	}
+
+	addr := types.StringToAddress("1")
+	store.preimages[hashes[0]] = addr.Bytes()
+
+	dc := &Dc{store, NilMetrics()}
+
+	listAccounts := func(start *types.Hash, limit uint64) *listAccountsResult {
+		t.Helper()
+
+		count := argUint64(limit)
+
+		res, err := dc.ListAccounts(BlockNumberOrHash{}, start, &count)
+		assert.NoError(t, err)
+
+		result, ok := res.(*listAccountsResult)
+		assert.True(t, ok)
+
+		return result
+	}
+
+	// first page
+	result := listAccounts(nil, 2)
+	assert.Len(t, result.Accounts, 2)
+	assert.Equal(t, hashes[0], result.Accounts[0].Hash)
+	assert.Equal(t, &addr, result.Accounts[0].Address)
+	assert.Nil(t, result.Accounts[1].Address)
+	assert.Equal(t, argUint64(1), result.Accounts[1].Nonce)
+	assert.Equal(t, types.StringToHash("ff"), result.Accounts[1].CodeHash)
+	assert.Equal(t, &hashes[2], result.Next)
+
+	// resumed from the cursor
+	result = listAccounts(result.Next, 2)
+	assert.Equal(t, hashes[2], result.Accounts[0].Hash)
+	assert.Equal(t, hashes[3], result.Accounts[1].Hash)
+
+	// last page
+	result = listAccounts(result.Next, 2)
+	assert.Len(t, result.Accounts, 1)
+	assert.Equal(t, hashes[4], result.Accounts[0].Hash)
+	assert.Nil(t, result.Next)
+
+	tooMany := argUint64(maxListedAccounts + 1)
+
+	_, err := dc.ListAccounts(BlockNumberOrHash{}, nil, &tooMany)
+	assert.ErrorIs(t, err, ErrTooManyAccounts)
+}
+
+func TestDc_GetBalanceHistory(t *testing.T) {
+	addr := types.StringToAddress("1")
+
+	store := &mockDcStore{
+		headers: map[uint64]*types.Header{},
+		states:  map[types.Hash]map[types.Address]*state.Account{},
+	}
+
+	// the account appears at block 2, and its balance grows 10 every block
+	for i := uint64(0); i < 10; i++ {
+		root := types.StringToHash(fmt.Sprintf("%d", i+1))
+		store.headers[i] = &types.Header{Number: i, StateRoot: root}
+		store.states[root] = map[types.Address]*state.Account{}
+
+		if i >= 2 {
+			store.states[root][addr] = &state.Account{Balance: big.NewInt(int64(i * 10))}
+		}
+	}
+
+	store.header = store.headers[9]
+
+	dc := &Dc{store, NilMetrics()}
+
+	t.Run("every block", func(t *testing.T) {
+		res, err := dc.GetBalanceHistory(addr, BlockNumber(1), BlockNumber(3), nil)
+		assert.NoError(t, err)
+
+		points, ok := res.([]*balancePoint)
+		assert.True(t, ok)
+		assert.Len(t, points, 3)
+
+		for i, expected := range []int64{0, 20, 30} {
+			assert.Equal(t, argUint64(i+1), points[i].BlockNumber)
+			assert.Equal(t, big.NewInt(expected), (*big.Int)(&points[i].Balance))
+		}
+	})
+
+	t.Run("with step", func(t *testing.T) {
+		step := argUint64(4)
+
+		res, err := dc.GetBalanceHistory(addr, EarliestBlockNumber, LatestBlockNumber, &step)
+		assert.NoError(t, err)
+
+		points, ok := res.([]*balancePoint)
+		assert.True(t, ok)
+		assert.Len(t, points, 3)
+
+		for i, expected := range []uint64{0, 4, 8} {
+			assert.Equal(t, argUint64(expected), points[i].BlockNumber)
+		}
+
+		assert.Equal(t, big.NewInt(80), (*big.Int)(&points[2].Balance))
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		_, err := dc.GetBalanceHistory(addr, BlockNumber(5), BlockNumber(3), nil)
+		assert.ErrorIs(t, err, ErrInvalidBlockRange)
+	})
+
+	t.Run("too many points", func(t *testing.T) {
+		store.headers[maxBalanceHistoryPoints+1] = &types.Header{Number: maxBalanceHistoryPoints + 1}
+
+		_, err := dc.GetBalanceHistory(addr, EarliestBlockNumber, BlockNumber(maxBalanceHistoryPoints+1), nil)
+		assert.ErrorIs(t, err, ErrTooManyHistoryPoints)
+	})
+}
+
+func TestDc_GetBlockExecutionStats(t *testing.T) {
+	store := &mockDcStore{
+		headers: map[uint64]*types.Header{},
+	}
+
+	for i := uint64(0); i < 5; i++ {
+		store.headers[i] = &types.Header{Number: i}
+	}
+
+	store.header = store.headers[4]
+	store.executionStats = []*blockchain.BlockExecutionStats{
+		{
+			Number:        2,
+			ExecutionTime: 1500 * time.Microsecond,
+			TxCount:       3,
+			SystemTxCount: 1,
+			StateAccess:   state.AccessStats{AccountReads: 7, NodeLoads: 20, NodeCacheHits: 15},
+		},
+		{Number: 4},
+	}
+
+	dc := &Dc{store, NilMetrics()}
+
+	res, err := dc.GetBlockExecutionStats(BlockNumber(1), BlockNumber(3))
+	assert.NoError(t, err)
+
+	list, ok := res.([]*blockExecutionStats)
+	assert.True(t, ok)
+	assert.Len(t, list, 1)
+	assert.Equal(t, argUint64(2), list[0].Number)
+	assert.Equal(t, argUint64(1500), list[0].ExecutionMicros)
+	assert.Equal(t, argUint64(3), list[0].TxCount)
+	assert.Equal(t, argUint64(1), list[0].SystemTxCount)
+	assert.Equal(t, argUint64(7), list[0].AccountReads)
+	assert.Equal(t, argUint64(15), list[0].NodeCacheHits)
+
+	res, err = dc.GetBlockExecutionStats(EarliestBlockNumber, LatestBlockNumber)
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+
+	_, err = dc.GetBlockExecutionStats(BlockNumber(3), BlockNumber(1))
+	assert.ErrorIs(t, err, ErrInvalidBlockRange)
+}
+
+func TestDc_GetChainStats(t *testing.T) {
+	store := &mockDcStore{
+		chainStats: []*blockchain.ChainStats{
+			{
+				Time:        blockchain.ChainStatsDay,
+				Blocks:      2,
+				TxCount:     4,
+				GasUsed:     50,
+				GasLimit:    200,
+				GasPriceSum: big.NewInt(100),
+			},
+		},
+	}
+
+	dc := &Dc{store, NilMetrics()}
+
+	res, err := dc.GetChainStats(argUint64(0), argUint64(2*blockchain.ChainStatsDay), "day")
+	assert.NoError(t, err)
+
+	list, ok := res.([]*chainStats)
+	assert.True(t, ok)
+	assert.Len(t, list, 1)
+	assert.Equal(t, argUint64(blockchain.ChainStatsDay), list[0].Time)
+	assert.Equal(t, argUint64(4), list[0].TxCount)
+	assert.Equal(t, 0.25, list[0].GasUsedRatio)
+	assert.Equal(t, argBig(*big.NewInt(25)), list[0].AvgGasPrice)
+
+	_, err = dc.GetChainStats(argUint64(0), argUint64(1), "week")
+	assert.ErrorIs(t, err, ErrInvalidStatsInterval)
+
+	_, err = dc.GetChainStats(argUint64(2), argUint64(1), "hour")
+	assert.ErrorIs(t, err, ErrInvalidBlockRange)
+}
+
+func TestDc_ValidateTransaction(t *testing.T) {
+	store := &mockDcStore{}
+	dc := &Dc{store, NilMetrics()}
+
+	tx := &types.Transaction{
+		Nonce:    1,
+		GasPrice: big.NewInt(1),
+		Gas:      21000,
+		To:       &addr1,
+		Value:    big.NewInt(1),
+		V:        big.NewInt(1),
+		R:        big.NewInt(1),
+		S:        big.NewInt(1),
+	}
+	raw := hex.EncodeToHex(tx.MarshalRLP())
+
+	res, err := dc.ValidateTransaction(raw)
+	assert.NoError(t, err)
+
+	result, ok := res.(*validateTransactionResult)
+	assert.True(t, ok)
+	assert.True(t, result.Valid)
+	assert.Equal(t, tx.Hash(), result.Hash)
+	assert.Empty(t, result.Violations)
+
+	store.violations = []error{errors.New("nonce too low"), errors.New("insufficient funds")}
+
+	res, err = dc.ValidateTransaction(raw)
+	assert.NoError(t, err)
+
+	result, ok = res.(*validateTransactionResult)
+	assert.True(t, ok)
+	assert.False(t, result.Valid)
+	assert.Equal(t, []string{"nonce too low", "insufficient funds"}, result.Violations)
+
+	_, err = dc.ValidateTransaction("0xzz")
+	assert.Error(t, err)
+}
+
+func TestDc_ChainConstants(t *testing.T) {
+	detroit := chain.Fork(100)
+
+	store := &mockDcStore{
+		header: &types.Header{GasLimit: 30_000_000},
+		params: &chain.Params{
+			ChainID:        2000,
+			BlockGasTarget: 20_000_000,
+			Forks: &chain.Forks{
+				Detroit: &detroit,
+			},
+		},
+	}
+	dc := &Dc{store, NilMetrics()}
+
+	res, err := dc.ChainConstants()
+	assert.NoError(t, err)
+
+	result, ok := res.(*chainConstantsResult)
+	assert.True(t, ok)
+	assert.Equal(t, argUint64(2000), result.ChainID)
+	assert.Equal(t, "DOGE", result.Denomination.Symbol)
+	assert.Equal(t, argUint64(18), result.Denomination.Decimals)
+	assert.Equal(t, argUint64(20_000_000), result.BlockGasTarget)
+	assert.Equal(t, argUint64(30_000_000), result.BlockGasLimit)
+	assert.Equal(t, systemcontracts.AddrValidatorSetContract, result.SystemContracts["validatorSet"])
+	assert.Equal(t, argUint64(1_000_000), result.SystemTxGasLimit)
+	assert.Nil(t, result.BridgeFromBlock)
+	assert.Equal(t, argUintPtr(100), result.SystemTxFromBlock)
+}
+
+func TestDc_GetSystemTransactions(t *testing.T) {
+	store := &mockDcStore{
+		headers:   map[uint64]*types.Header{},
+		blocks:    map[uint64]*types.Block{},
+		systemTxs: map[types.Hash]blockchain.SystemTxType{},
+	}
+
+	from := types.StringToAddress("1")
+
+	for i := uint64(0); i < 4; i++ {
+		header := &types.Header{Number: i}
+		header.ComputeHash()
+
+		userTx := &types.Transaction{Nonce: i, From: from, Value: big.NewInt(1)}
+		depositTx := &types.Transaction{Nonce: i, From: from, Input: []byte{1}}
+
+		txs := []*types.Transaction{userTx, depositTx}
+		store.systemTxs[depositTx.Hash()] = blockchain.SystemTxDeposit
+
+		if i == 2 {
+			slashTx := &types.Transaction{Nonce: i, From: from, Input: []byte{2}}
+			store.systemTxs[slashTx.Hash()] = blockchain.SystemTxSlash
+			txs = append(txs, slashTx)
+		}
+
+		store.headers[i] = header
+		store.blocks[i] = &types.Block{Header: header, Transactions: txs}
+	}
+
+	store.header = store.headers[3]
+
+	dc := &Dc{store, NilMetrics()}
+
+	// all the blocks are read without the index
+	res, err := dc.GetSystemTransactions(BlockNumber(1), LatestBlockNumber, nil)
+	assert.NoError(t, err)
+
+	list, ok := res.([]*systemTransaction)
+	assert.True(t, ok)
+	assert.Len(t, list, 4)
+	assert.Equal(t, argUint64(1), list[0].BlockNumber)
+	assert.Equal(t, argUint64(1), list[0].TxIndex)
+	assert.Equal(t, blockchain.SystemTxDeposit, list[0].Type)
+	assert.Equal(t, from, list[0].From)
+	assert.Equal(t, blockchain.SystemTxSlash, list[2].Type)
+	assert.Equal(t, argUint64(2), list[2].TxIndex)
+
+	slash := string(blockchain.SystemTxSlash)
+
+	res, err = dc.GetSystemTransactions(EarliestBlockNumber, LatestBlockNumber, &slash)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+
+	// only the indexed blocks are read
+	store.systemTxIndex = map[blockchain.SystemTxType][]uint64{
+		blockchain.SystemTxSlash: {2},
+	}
+	delete(store.blocks, 1)
+
+	res, err = dc.GetSystemTransactions(EarliestBlockNumber, LatestBlockNumber, &slash)
+	assert.NoError(t, err)
+
+	list, ok = res.([]*systemTransaction)
+	assert.True(t, ok)
+	assert.Len(t, list, 1)
+	assert.Equal(t, argUint64(2), list[0].BlockNumber)
+	assert.Equal(t, store.blocks[2].Transactions[2].Hash(), list[0].TxHash)
+
+	invalid := "transfer"
+
+	_, err = dc.GetSystemTransactions(EarliestBlockNumber, LatestBlockNumber, &invalid)
+	assert.ErrorIs(t, err, ErrInvalidSystemTxType)
+
+	_, err = dc.GetSystemTransactions(BlockNumber(3), BlockNumber(1), nil)
+	assert.ErrorIs(t, err, ErrInvalidBlockRange)
+}
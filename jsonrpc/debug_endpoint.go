@@ -1,29 +1,68 @@
 package jsonrpc
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
+	"time"
 
 	"github.com/dogechain-lab/dogechain/helper/hex"
 	"github.com/dogechain-lab/dogechain/state"
 	"github.com/dogechain-lab/dogechain/state/runtime"
+	"github.com/dogechain-lab/dogechain/state/tracer"
 	"github.com/dogechain-lab/dogechain/state/tracer/structlogger"
 	"github.com/dogechain-lab/dogechain/types"
+
+	// the built-in tracers
+	_ "github.com/dogechain-lab/dogechain/state/tracer/js"
+	_ "github.com/dogechain-lab/dogechain/state/tracer/native"
 )
 
+// defaultTraceTimeout bounds the traces of the named and the JavaScript tracers by default
+const defaultTraceTimeout = 5 * time.Second
+
 var (
 	ErrTransactionNotSeal         = errors.New("transaction not sealed")
 	ErrGenesisNotTracable         = errors.New("genesis is not traceable")
 	ErrTransactionNotFoundInBlock = errors.New("transaction not found in block")
+	ErrCodeNotFound               = errors.New("code not found")
+	ErrTraceTimeout               = errors.New("execution timeout")
 )
 
+// debugStore provides access to the methods needed by debug endpoint
+type debugStore interface {
+	ethStore
+
+	// GetCodeByHash returns the contract code stored under the code hash
+	GetCodeByHash(hash types.Hash) ([]byte, bool)
+
+	// ChaindbProperty returns the named property of the chain database
+	ChaindbProperty(property string) (string, error)
+
+	// ChaindbCompact compacts the keys within [start, limit) of the chain database
+	ChaindbCompact(start, limit []byte) error
+
+	// GetBlockNumbersByStateRoot returns the numbers of the canonical blocks with the
+	// state root
+	GetBlockNumbersByStateRoot(root types.Hash) []uint64
+}
+
 type Debug struct {
-	store ethStore
+	store debugStore
 
 	metrics *Metrics
 }
 
-func (d *Debug) TraceTransaction(hash types.Hash) (interface{}, error) {
+// TraceConfig is the optional config of the traces, the struct logs are traced
+// unless a tracer is named
+type TraceConfig struct {
+	Tracer       *string         `json:"tracer"`       // the built-in tracer like callTracer, or the JavaScript code
+	Timeout      *string         `json:"timeout"`      // the duration bounding the tracer, like "10s"
+	TracerConfig json.RawMessage `json:"tracerConfig"` // the config specific to the tracer
+}
+
+func (d *Debug) TraceTransaction(hash types.Hash, config *TraceConfig) (interface{}, error) {
 	d.metrics.DebugAPICounterInc(DebugTraceTransactionLabel)
 
 	// Check the chain state for the transaction
@@ -68,19 +107,134 @@ func (d *Debug) TraceTransaction(hash types.Hash) (interface{}, error) {
 		return nil, err
 	}
 
+	if config != nil && config.Tracer != nil {
+		return d.traceTxWithTracer(txn, tx, &tracer.Context{
+			BlockHash:   blockHash,
+			BlockNumber: block.Number(),
+			TxIndex:     txIdx,
+			TxHash:      hash,
+			GasPrice:    tx.GasPrice,
+			GasLimit:    tx.Gas,
+		}, config)
+	}
+
 	return d.traceTx(txn, tx)
 }
 
+// GetCodeByHash returns the contract code by its keccak256 hash
+func (d *Debug) GetCodeByHash(hash types.Hash) (interface{}, error) {
+	d.metrics.DebugAPICounterInc(DebugGetCodeByHashLabel)
+
+	code, ok := d.store.GetCodeByHash(hash)
+	if !ok {
+		return nil, ErrCodeNotFound
+	}
+
+	return argBytesPtr(code), nil
+}
+
+// ChaindbProperty returns the property of the chain database like geth, "leveldb.stats"
+// by default
+func (d *Debug) ChaindbProperty(property string) (interface{}, error) {
+	d.metrics.DebugAPICounterInc(DebugChaindbPropertyLabel)
+
+	if property == "" {
+		property = "leveldb.stats"
+	}
+
+	return d.store.ChaindbProperty(property)
+}
+
+// GetBlockNumbersByStateRoot returns the numbers of the canonical blocks with the state
+// root in ascending order. Only the first blocks keeping the root are recorded.
+func (d *Debug) GetBlockNumbersByStateRoot(root types.Hash) (interface{}, error) {
+	d.metrics.DebugAPICounterInc(DebugGetBlockNumbersByStateRootLabel)
+
+	numbers := d.store.GetBlockNumbersByStateRoot(root)
+
+	res := make([]argUint64, len(numbers))
+	for i, n := range numbers {
+		res[i] = argUint64(n)
+	}
+
+	return res, nil
+}
+
+// ChaindbCompact compacts the keys within [start, limit) of the chain database, the
+// whole database if omitted. It returns once the compaction is done. It is an admin
+// method, only served if the admin namespace is listed explicitly.
+func (d *Debug) ChaindbCompact(start, limit *argBytes) (interface{}, error) {
+	d.metrics.DebugAPICounterInc(DebugChaindbCompactLabel)
+
+	var startKey, limitKey []byte
+
+	if start != nil {
+		startKey = *start
+	}
+
+	if limit != nil {
+		limitKey = *limit
+	}
+
+	if err := d.store.ChaindbCompact(startKey, limitKey); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// traceTxWithTracer traces the transaction with the named or the JavaScript tracer,
+// which is stopped once the timeout expires
+func (d *Debug) traceTxWithTracer(
+	txn *state.Transition,
+	tx *types.Transaction,
+	ctx *tracer.Context,
+	config *TraceConfig,
+) (interface{}, error) {
+	timeout := defaultTraceTimeout
+
+	if config.Timeout != nil {
+		var err error
+
+		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	t, err := tracer.New(*config.Tracer, ctx, config.TracerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.AfterFunc(timeout, func() {
+		t.Stop(ErrTraceTimeout)
+	})
+	defer deadline.Stop()
+
+	txn.SetEVMLogger(t)
+
+	if _, err := txn.Apply(tx); err != nil {
+		return nil, fmt.Errorf("tracing failed: %w", err)
+	}
+
+	return t.GetResult()
+}
+
 func (d *Debug) traceTx(txn *state.Transition, tx *types.Transaction) (interface{}, error) {
 	var tracer runtime.EVMLogger = structlogger.NewStructLogger(txn.Txn())
 
 	txn.SetEVMLogger(tracer)
 
+	// the state accesses of the preceding transactions are excluded
+	accessBefore := txn.AccessStats()
+
 	result, err := txn.Apply(tx)
 	if err != nil {
 		return nil, fmt.Errorf("tracing failed: %w", err)
 	}
 
+	access := txn.AccessStats().Sub(accessBefore)
+
 	switch tracer := tracer.(type) {
 	case *structlogger.StructLogger:
 		returnVal := fmt.Sprintf("%x", result.Return())
@@ -90,10 +244,16 @@ func (d *Debug) traceTx(txn *state.Transition, tx *types.Transaction) (interface
 		}
 
 		return &ExecutionResult{
-			Gas:         result.GasUsed,
-			Failed:      result.Failed(),
-			ReturnValue: returnVal,
-			StructLogs:  formatLogs(tracer.StructLogs()),
+			Gas:           result.GasUsed,
+			IntrinsicGas:  result.IntrinsicGas,
+			ExecutionGas:  result.ExecutionGas(),
+			RefundedGas:   result.GasRefund,
+			GasPrice:      argBig(*tx.GasPrice),
+			EffectiveCost: argBig(*new(big.Int).Mul(tx.GasPrice, new(big.Int).SetUint64(result.GasUsed))),
+			Failed:        result.Failed(),
+			ReturnValue:   returnVal,
+			StructLogs:    formatLogs(tracer.StructLogs()),
+			StateAccess:   &access,
 		}, nil
 	default:
 		panic(fmt.Sprintf("bad tracer type %T", tracer))
@@ -102,12 +262,19 @@ func (d *Debug) traceTx(txn *state.Transition, tx *types.Transaction) (interface
 
 // ExecutionResult groups all structured logs emitted by the EVM
 // while replaying a transaction in debug mode as well as transaction
-// execution status, the amount of gas used and the return value
+// execution status, the amount of gas used, the return value and the state accesses.
+// The gas used is broken down into the intrinsic and the execution gas, less the refund.
 type ExecutionResult struct {
-	Gas         uint64         `json:"gas"`
-	Failed      bool           `json:"failed"`
-	ReturnValue string         `json:"returnValue"`
-	StructLogs  []StructLogRes `json:"structLogs"`
+	Gas           uint64             `json:"gas"`
+	IntrinsicGas  uint64             `json:"intrinsicGas"`
+	ExecutionGas  uint64             `json:"executionGas"`
+	RefundedGas   uint64             `json:"refundedGas"`
+	GasPrice      argBig             `json:"gasPrice"`
+	EffectiveCost argBig             `json:"effectiveCost"`
+	Failed        bool               `json:"failed"`
+	ReturnValue   string             `json:"returnValue"`
+	StructLogs    []StructLogRes     `json:"structLogs"`
+	StateAccess   *state.AccessStats `json:"stateAccess,omitempty"`
 }
 
 // StructLogRes stores a structured log emitted by the EVM while replaying a
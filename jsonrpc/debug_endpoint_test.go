@@ -1,6 +1,7 @@
 package jsonrpc
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 
@@ -196,3 +197,88 @@ func TestDebug_FormatLogs(t *testing.T) {
 		})
 	}
 }
+
+type mockChaindbStore struct {
+	debugStore
+
+	properties map[string]string
+	compacted  [][2][]byte
+}
+
+func (m *mockChaindbStore) ChaindbProperty(property string) (string, error) {
+	value, ok := m.properties[property]
+	if !ok {
+		return "", errors.New("unknown property")
+	}
+
+	return value, nil
+}
+
+func (m *mockChaindbStore) ChaindbCompact(start, limit []byte) error {
+	m.compacted = append(m.compacted, [2][]byte{start, limit})
+
+	return nil
+}
+
+func TestDebug_Chaindb(t *testing.T) {
+	store := &mockChaindbStore{
+		properties: map[string]string{
+			"leveldb.stats":     "stats",
+			"leveldb.iostats":   "iostats",
+			"leveldb.blockpool": "blockpool",
+		},
+	}
+	debug := &Debug{store, NilMetrics()}
+
+	// the stats by default
+	res, err := debug.ChaindbProperty("")
+	assert.NoError(t, err)
+	assert.Equal(t, "stats", res)
+
+	res, err = debug.ChaindbProperty("leveldb.iostats")
+	assert.NoError(t, err)
+	assert.Equal(t, "iostats", res)
+
+	_, err = debug.ChaindbProperty("leveldb.unknown")
+	assert.Error(t, err)
+
+	// the whole database
+	_, err = debug.ChaindbCompact(nil, nil)
+	assert.NoError(t, err)
+
+	start, limit := argBytes{0x01}, argBytes{0x02}
+
+	_, err = debug.ChaindbCompact(&start, &limit)
+	assert.NoError(t, err)
+
+	assert.Equal(t, [][2][]byte{{nil, nil}, {{0x01}, {0x02}}}, store.compacted)
+}
+
+type mockStateRootStore struct {
+	debugStore
+
+	numbers map[types.Hash][]uint64
+}
+
+func (m *mockStateRootStore) GetBlockNumbersByStateRoot(root types.Hash) []uint64 {
+	return m.numbers[root]
+}
+
+func TestDebug_GetBlockNumbersByStateRoot(t *testing.T) {
+	root := types.StringToHash("1")
+	store := &mockStateRootStore{
+		numbers: map[types.Hash][]uint64{
+			root: {1, 2, 5},
+		},
+	}
+	debug := &Debug{store, NilMetrics()}
+
+	res, err := debug.GetBlockNumbersByStateRoot(root)
+	assert.NoError(t, err)
+	assert.Equal(t, []argUint64{1, 2, 5}, res)
+
+	// an empty list rather than null for the unknown roots
+	res, err = debug.GetBlockNumbersByStateRoot(types.StringToHash("2"))
+	assert.NoError(t, err)
+	assert.Equal(t, []argUint64{}, res)
+}
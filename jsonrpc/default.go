@@ -6,4 +6,15 @@ const (
 	// DefaultJSONRPCBlockRangeLimit maximum block range allowed for json_rpc
 	// requests with fromBlock/toBlock values (e.g. eth_getLogs)
 	DefaultJSONRPCBlockRangeLimit uint64 = 100
+	// DefaultWSMaxMessageSize maximum size in bytes of an incoming websocket message
+	DefaultWSMaxMessageSize uint64 = 1024 * 1024
+	// DefaultWSMessageRateLimit maximum number of incoming messages per second
+	// of a websocket connection
+	DefaultWSMessageRateLimit uint64 = 100
+	// DefaultWSSendQueueSize maximum number of outgoing messages queued for a websocket
+	// connection
+	DefaultWSSendQueueSize uint64 = 256
+	// DefaultWSDropPolicy policy applied to the subscription notifications when the
+	// websocket send queue is full
+	DefaultWSDropPolicy = WSDropPolicyDrop
 )
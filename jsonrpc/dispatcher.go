@@ -8,8 +8,10 @@ import (
 	"math"
 	"reflect"
 	"strings"
+	"time"
 	"unicode"
 
+	"github.com/dogechain-lab/dogechain/types"
 	"github.com/hashicorp/go-hclog"
 )
 
@@ -21,6 +23,8 @@ const (
 	NamespaceWeb3   Namespace = "web3"
 	NamespaceTxpool Namespace = "txpool"
 	NamespaceDebug  Namespace = "debug"
+	NamespaceDc     Namespace = "dc"
+	NamespaceAdmin  Namespace = "admin" // not enabled by the wildcard
 	NamespaceAll    Namespace = "*"
 )
 
@@ -40,12 +44,20 @@ func (f *funcData) numParams() int {
 	return f.inNum - 1
 }
 
+// adminMethods are the methods of the other namespaces managing the node, which are
+// only served if the admin namespace is listed explicitly, like the admin ones
+var adminMethods = map[string]struct{}{
+	"debug_chaindbCompact": {},
+}
+
 type endpoints struct {
 	Eth    *Eth
 	Web3   *Web3
 	Net    *Net
 	TxPool *TxPool
 	Debug  *Debug
+	Dc     *Dc
+	Admin  *Admin
 }
 
 // Dispatcher handles all json rpc requests by delegating
@@ -59,6 +71,10 @@ type Dispatcher struct {
 	jsonRPCBatchLengthLimit uint64
 	priceLimit              uint64
 	namespaces              map[Namespace]struct{}
+	metrics                 *Metrics
+	responseCache           ResponseCache // nil if disabled
+	rateLimiter             *rateLimiter  // nil if unlimited
+	approver                AdminApprover // nil if the admin methods need no approval
 }
 
 func newDispatcher(
@@ -77,6 +93,7 @@ func newDispatcher(
 		jsonRPCBatchLengthLimit: jsonRPCBatchLengthLimit,
 		priceLimit:              priceLimit,
 		namespaces:              make(map[Namespace]struct{}),
+		metrics:                 metrics,
 	}
 
 	// map namespaces
@@ -109,6 +126,8 @@ func (d *Dispatcher) initEndpoints(store JSONRPCStore, metrics *Metrics) {
 	d.endpoints.Web3 = &Web3{d.chainID, metrics}
 	d.endpoints.TxPool = &TxPool{store, metrics}
 	d.endpoints.Debug = &Debug{store, metrics}
+	d.endpoints.Dc = &Dc{store, metrics}
+	d.endpoints.Admin = &Admin{store, metrics}
 }
 
 func (d *Dispatcher) registerEndpoints() {
@@ -119,6 +138,12 @@ func (d *Dispatcher) registerEndpoints() {
 		d.registerService(string(NamespaceWeb3), d.endpoints.Web3)
 		d.registerService(string(NamespaceTxpool), d.endpoints.TxPool)
 		d.registerService(string(NamespaceDebug), d.endpoints.Debug)
+		d.registerService(string(NamespaceDc), d.endpoints.Dc)
+
+		// the peers are managed by the admin namespace only if listed explicitly
+		if _, ok := d.namespaces[NamespaceAdmin]; ok {
+			d.registerService(string(NamespaceAdmin), d.endpoints.Admin)
+		}
 
 		return
 	}
@@ -135,6 +160,10 @@ func (d *Dispatcher) registerEndpoints() {
 			d.registerService(string(ns), d.endpoints.TxPool)
 		case NamespaceDebug:
 			d.registerService(string(ns), d.endpoints.Debug)
+		case NamespaceDc:
+			d.registerService(string(ns), d.endpoints.Dc)
+		case NamespaceAdmin:
+			d.registerService(string(ns), d.endpoints.Admin)
 		}
 	}
 }
@@ -158,13 +187,24 @@ func (d *Dispatcher) getFnHandler(req Request) (*serviceData, *funcData, Error)
 		return nil, nil, NewMethodNotFoundError(req.Method)
 	}
 
+	if _, isAdmin := adminMethods[req.Method]; isAdmin {
+		if _, ok := d.namespaces[NamespaceAdmin]; !ok {
+			return nil, nil, NewMethodNotFoundError(req.Method)
+		}
+	}
+
 	return service, fd, nil
 }
 
 type wsConn interface {
 	WriteMessage(messageType int, data []byte) error
+	// WriteNotification writes the subscription notification, which could be dropped
+	// for the slow peer
+	WriteNotification(messageType int, data []byte) error
 	GetFilterID() string
 	SetFilterID(string)
+	// GetClient returns the caller of the requests
+	GetClient() client
 }
 
 // as per https://www.jsonrpc.org/specification, the `id` in JSON-RPC 2.0
@@ -210,6 +250,16 @@ func (d *Dispatcher) handleSubscribe(req Request, conn wsConn) (string, Error) {
 			return "", NewInternalError(err.Error())
 		}
 		filterID = d.filterManager.NewLogFilter(logQuery, conn)
+	} else if subscribeMethod == "newPendingTransactions" {
+		// the optional flag to deliver the full transaction objects
+		fullTx := false
+		if len(params) > 1 {
+			if fullTx, ok = params[1].(bool); !ok {
+				return "", NewInvalidParamsError("Invalid params")
+			}
+		}
+
+		filterID = d.filterManager.NewPendingTxFilter(fullTx, conn)
 	} else {
 		return "", NewSubscriptionNotFoundError(subscribeMethod)
 	}
@@ -239,12 +289,18 @@ func (d *Dispatcher) RemoveFilterByWs(conn wsConn) {
 	d.filterManager.RemoveFilterByWs(conn)
 }
 
+// HandleWs handles the request of the websocket connection, it returns the response
+// to write out, nil if written already
 func (d *Dispatcher) HandleWs(reqBody []byte, conn wsConn) ([]byte, error) {
 	var req Request
 	if err := json.Unmarshal(reqBody, &req); err != nil {
 		return NewRPCResponse(req.ID, "2.0", nil, NewInvalidRequestError("Invalid json request")).Bytes()
 	}
 
+	if err := d.checkClient(conn.GetClient(), req); err != nil {
+		return NewRPCResponse(req.ID, "2.0", nil, err).Bytes()
+	}
+
 	// if the request method is eth_subscribe we need to create a
 	// new filter with ws connection
 	if req.Method == "eth_subscribe" {
@@ -281,6 +337,14 @@ func (d *Dispatcher) HandleWs(reqBody []byte, conn wsConn) ([]byte, error) {
 		return []byte(resp), nil
 	}
 
+	// the logs of the huge historical filters are streamed in chunks, the messages
+	// are written out by the stream
+	if req.Method == "eth_getFilterLogs" {
+		if filterID, opts, ok := parseLogStreamRequest(req); ok {
+			return nil, d.streamFilterLogs(req, filterID, opts, conn)
+		}
+	}
+
 	// its a normal query that we handle with the dispatcher
 	resp, err := d.handleReq(req)
 	if err != nil {
@@ -290,7 +354,41 @@ func (d *Dispatcher) HandleWs(reqBody []byte, conn wsConn) ([]byte, error) {
 	return NewRPCResponse(req.ID, "2.0", resp, err).Bytes()
 }
 
+// checkClient returns the error if the token of the client has expired, the method is
+// not permitted to the client, the client exceeds the rate limits, or the admin method
+// is not approved by the operators
+func (d *Dispatcher) checkClient(c client, req Request) Error {
+	if c.expired(time.Now()) {
+		return NewUnauthorizedError(ErrAuthTokenExpired.Error())
+	}
+
+	if !c.permits(req.Method) {
+		return NewMethodNotPermittedError(req.Method)
+	}
+
+	if !d.rateLimiter.allow(c.ip, req.Method) {
+		return NewLimitExceededError()
+	}
+
+	if _, ok := approvedMethods[req.Method]; ok && d.approver != nil {
+		if err := d.approver.ApproveRequest(c.ip, req.Method, req.Params, c.signatures, c.expiry); err != nil {
+			d.logger.Warn("unapproved admin request", "method", req.Method, "remote", c.ip, "err", err)
+
+			return NewUnauthorizedError(err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Handle handles the requests of an unknown client
 func (d *Dispatcher) Handle(reqBody []byte) ([]byte, error) {
+	return d.handleClient(client{}, reqBody)
+}
+
+// handleClient handles the requests of the client, each request of the batch is
+// checked on its own
+func (d *Dispatcher) handleClient(c client, reqBody []byte) ([]byte, error) {
 	x := bytes.TrimLeft(reqBody, " \t\r\n")
 	if len(x) == 0 {
 		return NewRPCResponse(nil, "2.0", nil, NewInvalidRequestError("Invalid json request")).Bytes()
@@ -306,6 +404,10 @@ func (d *Dispatcher) Handle(reqBody []byte) ([]byte, error) {
 			return NewRPCResponse(req.ID, "2.0", nil, NewInvalidRequestError("Invalid json request")).Bytes()
 		}
 
+		if err := d.checkClient(c, req); err != nil {
+			return NewRPCResponse(req.ID, "2.0", nil, err).Bytes()
+		}
+
 		resp, err := d.handleReq(req)
 
 		return NewRPCResponse(req.ID, "2.0", resp, err).Bytes()
@@ -326,6 +428,12 @@ func (d *Dispatcher) Handle(reqBody []byte) ([]byte, error) {
 	responses := make([]Response, 0)
 
 	for _, req := range requests {
+		if err := d.checkClient(c, req); err != nil {
+			responses = append(responses, NewRPCResponse(req.ID, "2.0", nil, err))
+
+			continue
+		}
+
 		var response, err = d.handleReq(req)
 		if err != nil {
 			errorResponse := NewRPCResponse(req.ID, "2.0", nil, err)
@@ -346,14 +454,46 @@ func (d *Dispatcher) Handle(reqBody []byte) ([]byte, error) {
 	return respBytes, nil
 }
 
+// unknownMethodLabel labels the requests of the unknown methods in the metrics, so that
+// the arbitrary method names do not bloat the series
+const unknownMethodLabel = "unknown"
+
 func (d *Dispatcher) handleReq(req Request) ([]byte, Error) {
 	d.logger.Debug("request", "method", req.Method, "id", req.ID)
 
 	service, fd, ferr := d.getFnHandler(req)
 	if ferr != nil {
+		d.metrics.MethodCallEnd(unknownMethodLabel, d.metrics.MethodCallBegin(unknownMethodLabel), true)
+
 		return nil, ferr
 	}
 
+	begin := d.metrics.MethodCallBegin(req.Method)
+
+	data, err := d.callReq(req, service, fd)
+
+	d.metrics.MethodCallEnd(req.Method, begin, err != nil)
+
+	return data, err
+}
+
+// callReq calls the handler of the request
+func (d *Dispatcher) callReq(req Request, service *serviceData, fd *funcData) ([]byte, Error) {
+	cacheKey, cacheable := "", false
+	if d.responseCache != nil {
+		cacheKey, cacheable = txResponseCacheKey(req, d.lookupTx)
+	}
+
+	if cacheable {
+		if data, ok := d.responseCache.Get(cacheKey); ok {
+			d.metrics.CacheHitsCounterInc()
+
+			return data, nil
+		}
+
+		d.metrics.CacheMissesCounterInc()
+	}
+
 	inArgs := make([]reflect.Value, fd.inNum)
 	inArgs[0] = service.sv
 
@@ -373,6 +513,12 @@ func (d *Dispatcher) handleReq(req Request) ([]byte, Error) {
 
 	output := fd.fv.Call(inArgs)
 	if err := getError(output[1]); err != nil {
+		// the reverted execution is returned with its revert data
+		var revertErr *revertError
+		if errors.As(err, &revertErr) {
+			return nil, revertErr
+		}
+
 		d.logInternalError(req.Method, err)
 
 		return nil, NewInvalidRequestError(err.Error())
@@ -392,9 +538,22 @@ func (d *Dispatcher) handleReq(req Request) ([]byte, Error) {
 		}
 	}
 
+	if cacheable && isCacheableResponse(data) {
+		d.responseCache.Set(cacheKey, data)
+	}
+
 	return data, nil
 }
 
+// lookupTx returns the hash of the block including the transaction
+func (d *Dispatcher) lookupTx(hash types.Hash) (types.Hash, bool) {
+	if d.endpoints.Eth.store == nil {
+		return types.ZeroHash, false
+	}
+
+	return d.endpoints.Eth.store.ReadTxLookup(hash)
+}
+
 func (d *Dispatcher) logInternalError(method string, err error) {
 	d.logger.Error("failed to dispatch", "method", method, "err", err)
 }
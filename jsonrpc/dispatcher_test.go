@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"math/big"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/dogechain-lab/dogechain/types"
 	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -93,6 +95,66 @@ func TestDispatcher_HandleWebsocketConnection_EthSubscribe(t *testing.T) {
 			t.Fatal("\"newHeads\" event not received in 2 seconds")
 		}
 	})
+
+	t.Run("clients should be able to receive \"newPendingTransactions\" event thru eth_subscribe", func(t *testing.T) {
+		tx := &types.Transaction{
+			Nonce:    1,
+			GasPrice: big.NewInt(10),
+			Value:    big.NewInt(0),
+			V:        big.NewInt(1),
+			R:        big.NewInt(2),
+			S:        big.NewInt(3),
+			From:     types.StringToAddress("1"),
+		}
+
+		testTable := []struct {
+			name   string
+			params string
+			result interface{}
+		}{
+			{"hashes", `["newPendingTransactions"]`, tx.Hash()},
+			{"full transactions", `["newPendingTransactions", true]`, toPendingTransaction(tx)},
+		}
+
+		for _, tt := range testTable {
+			store := newMockStore()
+			dispatcher := newDispatcher(hclog.NewNullLogger(), NilMetrics(), store, 0, 0, 0, 0, []Namespace{
+				NamespaceEth,
+			})
+
+			mockConnection := &mockWsConn{
+				msgCh: make(chan []byte, 1),
+			}
+
+			req := []byte(`{"method": "eth_subscribe", "params": ` + tt.params + `}`)
+			if _, err := dispatcher.HandleWs(req, mockConnection); err != nil {
+				t.Fatal(err)
+			}
+
+			store.emitPendingTxs([]*types.Transaction{tx})
+
+			delayTimer := time.NewTimer(2 * time.Second)
+
+			select {
+			case msg := <-mockConnection.msgCh:
+				var notification struct {
+					Params struct {
+						Result json.RawMessage `json:"result"`
+					} `json:"params"`
+				}
+
+				assert.NoError(t, json.Unmarshal(msg, &notification))
+
+				expected, err := json.Marshal(tt.result)
+				assert.NoError(t, err)
+				assert.JSONEq(t, string(expected), string(notification.Params.Result), tt.name)
+			case <-delayTimer.C:
+				t.Fatalf("\"newPendingTransactions\" %s not received in 2 seconds", tt.name)
+			}
+
+			delayTimer.Stop()
+		}
+	})
 }
 
 func TestDispatcher_WebsocketConnection_RequestFormats(t *testing.T) {
@@ -471,3 +533,121 @@ func TestDispatcherBatchRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestDispatcher_WebsocketConnection_StreamFilterLogs(t *testing.T) {
+	topics := []types.Hash{types.StringToHash("4"), types.StringToHash("5"), types.StringToHash("6")}
+
+	store := &mockBlockStore{topics: topics}
+	store.setupLogs()
+
+	for i := 0; i < 5; i++ {
+		store.add(&types.Block{
+			Header: &types.Header{
+				Number: uint64(i),
+				Hash:   types.StringToHash(strconv.Itoa(i)),
+			},
+			Transactions: []*types.Transaction{
+				{Value: big.NewInt(10)},
+				{Value: big.NewInt(11)},
+				{Value: big.NewInt(12)},
+			},
+		})
+	}
+
+	filterManager := NewFilterManager(hclog.NewNullLogger(), store, 1000)
+	defer filterManager.Close()
+
+	dispatcher := &Dispatcher{
+		logger:        hclog.NewNullLogger(),
+		filterManager: filterManager,
+		metrics:       NilMetrics(),
+	}
+
+	filterID := filterManager.NewLogFilter(&LogQuery{
+		FromBlock: 1,
+		ToBlock:   3,
+		Topics:    [][]types.Hash{{topics[0]}, {topics[1]}, {topics[2]}},
+	}, nil)
+
+	mockConnection := &mockWsConn{
+		msgCh: make(chan []byte, 8),
+	}
+
+	req := []byte(`{
+		"id": 1,
+		"method": "eth_getFilterLogs",
+		"params": ["` + filterID + `", {"stream": true, "chunkSize": 2}]
+	}`)
+
+	resp, err := dispatcher.HandleWs(req, mockConnection)
+	assert.NoError(t, err)
+	// the messages are written by the stream
+	assert.Nil(t, resp)
+
+	var streamID string
+	assert.NoError(t, expectJSONResult(<-mockConnection.msgCh, &streamID))
+
+	readChunk := func() *logStreamChunk {
+		var notification struct {
+			Method string `json:"method"`
+			Params struct {
+				Subscription string          `json:"subscription"`
+				Result       *logStreamChunk `json:"result"`
+			} `json:"params"`
+		}
+
+		assert.NoError(t, json.Unmarshal(<-mockConnection.msgCh, &notification))
+		assert.Equal(t, "eth_subscription", notification.Method)
+		assert.Equal(t, streamID, notification.Params.Subscription)
+
+		return notification.Params.Result
+	}
+
+	// one matching log per block
+	chunk := readChunk()
+	assert.Len(t, chunk.Logs, 2)
+	assert.False(t, chunk.Done)
+
+	chunk = readChunk()
+	assert.Len(t, chunk.Logs, 1)
+	assert.True(t, chunk.Done)
+	assert.Equal(t, uint64(3), chunk.Total)
+	assert.Empty(t, chunk.Error)
+
+	assert.Len(t, mockConnection.msgCh, 0)
+
+	// the chunk size is bounded
+	resp, err = dispatcher.HandleWs([]byte(`{
+		"id": 2,
+		"method": "eth_getFilterLogs",
+		"params": ["`+filterID+`", {"stream": true, "chunkSize": 100000}]
+	}`), mockConnection)
+	assert.NoError(t, err)
+	assert.Nil(t, resp)
+
+	var logs []*Log
+	assert.Error(t, expectJSONResult(<-mockConnection.msgCh, &logs))
+}
+
+func TestDispatcher_MethodMetrics(t *testing.T) {
+	srv := &mockService{msgCh: make(chan interface{}, 10)}
+
+	metrics := GetPrometheusMetrics("test_dispatcher")
+	dispatcher := newDispatcher(hclog.NewNullLogger(), metrics, newMockStore(), 0, 0, 0, 0, nil)
+	dispatcher.registerService("mock", srv)
+
+	for _, params := range []string{`["latest"]`, `["0x1"]`, `["invalid"]`} {
+		_, _ = dispatcher.handleReq(Request{Method: "mock_block", Params: []byte(params)})
+	}
+
+	_, err := dispatcher.handleReq(Request{Method: "mock_missing"})
+	assert.Error(t, err)
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(metrics.methodRequests.WithLabelValues("mock_block")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.methodErrors.WithLabelValues("mock_block")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.methodInFlight.WithLabelValues("mock_block")))
+
+	// the unknown methods are not labeled by name
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.methodErrors.WithLabelValues(unknownMethodLabel)))
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.methodSeconds))
+}
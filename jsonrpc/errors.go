@@ -1,9 +1,12 @@
 package jsonrpc
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"math/big"
 
+	"github.com/dogechain-lab/dogechain/helper/hex"
 	"github.com/dogechain-lab/dogechain/state/runtime"
 	"github.com/umbracle/go-web3/abi"
 )
@@ -16,6 +19,13 @@ type Error interface {
 	Error() string
 	ErrorCode() int
 }
+
+// DataError is the error with the additional data of the error object
+type DataError interface {
+	Error
+	ErrorData() interface{}
+}
+
 type invalidParamsError struct {
 	err string
 }
@@ -76,6 +86,30 @@ func (e *methodNotFoundError) ErrorCode() int {
 	return -32601
 }
 
+type limitExceededError struct {
+	err string
+}
+
+func (e *limitExceededError) Error() string {
+	return e.err
+}
+
+func (e *limitExceededError) ErrorCode() int {
+	return -32005
+}
+
+type unauthorizedError struct {
+	err string
+}
+
+func (e *unauthorizedError) Error() string {
+	return e.err
+}
+
+func (e *unauthorizedError) ErrorCode() int {
+	return -32001
+}
+
 func NewMethodNotFoundError(method string) *methodNotFoundError {
 	return &methodNotFoundError{fmt.Sprintf("the method %s does not exist/is not available", method)}
 }
@@ -90,10 +124,101 @@ func NewInternalError(msg string) *internalError {
 	return &internalError{msg}
 }
 
+func NewLimitExceededError() *limitExceededError {
+	return &limitExceededError{"limit exceeded"}
+}
+
+func NewUnauthorizedError(msg string) *unauthorizedError {
+	return &unauthorizedError{msg}
+}
+
+func NewMethodNotPermittedError(method string) *unauthorizedError {
+	return &unauthorizedError{fmt.Sprintf("the method %s is not permitted to the token", method)}
+}
+
 func NewSubscriptionNotFoundError(method string) *subscriptionNotFoundError {
 	return &subscriptionNotFoundError{fmt.Sprintf("subscribe method %s not found", method)}
 }
 
+// revertError is the error of the reverted execution, with the revert data returned in
+// the data field the way geth does, so that the tooling could decode the reason
+type revertError struct {
+	reason string
+	data   []byte
+}
+
+func (e *revertError) Error() string {
+	if e.reason == "" {
+		return "execution reverted"
+	}
+
+	return "execution reverted: " + e.reason
+}
+
+func (e *revertError) ErrorCode() int {
+	return 3
+}
+
+func (e *revertError) ErrorData() interface{} {
+	if len(e.data) == 0 {
+		return nil
+	}
+
+	return hex.EncodeToHex(e.data)
+}
+
+func (e *revertError) Unwrap() error {
+	return runtime.ErrExecutionReverted
+}
+
+var (
+	// panicSelector is the selector of the solidity Panic(uint256)
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+	panicReasons = map[uint64]string{
+		0x00: "generic panic",
+		0x01: "assert(false)",
+		0x11: "arithmetic underflow or overflow",
+		0x12: "division or modulo by zero",
+		0x21: "enum overflow",
+		0x22: "invalid encoded storage byte array accessed",
+		0x31: "out-of-bounds array access; popping on an empty array",
+		0x32: "out-of-bounds access of an array or bytesN",
+		0x41: "out of memory",
+		0x51: "uninitialized function",
+	}
+)
+
+// unpackPanicReason decodes the reason of the solidity Panic(uint256)
+func unpackPanicReason(data []byte) (string, bool) {
+	if len(data) != len(panicSelector)+32 || !bytes.HasPrefix(data, panicSelector) {
+		return "", false
+	}
+
+	code := new(big.Int).SetBytes(data[len(panicSelector):])
+	if code.IsUint64() {
+		if reason, ok := panicReasons[code.Uint64()]; ok {
+			return reason, true
+		}
+	}
+
+	return fmt.Sprintf("unknown panic code: %#x", code), true
+}
+
+// newRevertError decodes the reason of the solidity Error(string) or Panic(uint256)
+// from the revert data, the data of the custom errors is returned as is
+func newRevertError(result *runtime.ExecutionResult) *revertError {
+	reason, err := abi.UnpackRevertError(result.ReturnValue)
+	if err != nil {
+		reason, _ = unpackPanicReason(result.ReturnValue)
+	}
+
+	return &revertError{
+		reason: reason,
+		data:   result.ReturnValue,
+	}
+}
+
 func constructErrorFromRevert(result *runtime.ExecutionResult) error {
 	revertErrMsg, unpackErr := abi.UnpackRevertError(result.ReturnValue)
 	if unpackErr != nil {
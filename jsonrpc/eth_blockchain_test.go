@@ -1,6 +1,7 @@
 package jsonrpc
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -8,8 +9,10 @@ import (
 	"testing"
 
 	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/chain"
 	"github.com/dogechain-lab/dogechain/helper/progress"
 	"github.com/dogechain-lab/dogechain/state/runtime"
+	"github.com/dogechain-lab/dogechain/txpool"
 	"github.com/dogechain-lab/dogechain/types"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/atomic"
@@ -180,6 +183,7 @@ func TestEth_GetTransactionReceipt(t *testing.T) {
 		txn := newTestTransaction(uint64(0), addr0)
 		block.Transactions = append(block.Transactions, txn)
 		rec := &types.Receipt{
+			GasUsed: 21016,
 			Logs: []*types.Log{
 				{
 					Topics: []types.Hash{
@@ -201,6 +205,37 @@ func TestEth_GetTransactionReceipt(t *testing.T) {
 		assert.Equal(t, txn.Hash(), response.TxHash)
 		assert.Equal(t, block.Hash(), response.BlockHash)
 		assert.NotNil(t, response.Logs)
+
+		// the transfer with a non-zero byte of input, at the gas price of 1
+		assert.Equal(t, argUint64(21016), response.IntrinsicGas)
+		assert.Equal(t, argBig(*big.NewInt(1)), response.EffectiveGasPrice)
+		assert.Equal(t, argBig(*big.NewInt(21016)), response.EffectiveCost)
+		assert.Empty(t, response.SystemTxType)
+	})
+
+	t.Run("tags the receipt of the system transaction", func(t *testing.T) {
+		store := newMockBlockStore()
+		eth := newTestEthEndpoint(store)
+		block := newTestBlock(1, hash4)
+		store.add(block)
+		txn := newTestTransaction(uint64(0), addr0)
+		block.Transactions = append(block.Transactions, txn)
+		store.systemTxs = map[types.Hash]blockchain.SystemTxType{txn.Hash(): blockchain.SystemTxSlash}
+
+		rec := &types.Receipt{}
+		rec.SetStatus(types.ReceiptSuccess)
+		store.receipts[hash4] = []*types.Receipt{rec}
+
+		res, err := eth.GetTransactionReceipt(txn.Hash())
+		assert.NoError(t, err)
+
+		//nolint:forcetypeassert
+		response := res.(*receipt)
+		assert.Equal(t, blockchain.SystemTxSlash, response.SystemTxType)
+
+		data, err := json.Marshal(response)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"systemTxType":"slash"`)
 	})
 }
 
@@ -303,6 +338,9 @@ type mockBlockStore struct {
 	isSyncing       bool
 	averageGasPrice int64
 	ethCallError    error
+	logIndex        []uint64 // indexed block numbers having logs, nil if disabled
+
+	systemTxs map[types.Hash]blockchain.SystemTxType // types of the system transactions
 }
 
 func newMockBlockStore() *mockBlockStore {
@@ -409,6 +447,15 @@ func (m *mockBlockStore) GetReceiptsByHash(hash types.Hash) ([]*types.Receipt, e
 	return receipts, nil
 }
 
+func (m *mockBlockStore) GetSystemTxTypes(header *types.Header, txs []*types.Transaction) []blockchain.SystemTxType {
+	txTypes := make([]blockchain.SystemTxType, len(txs))
+	for i, tx := range txs {
+		txTypes[i] = m.systemTxs[tx.Hash()]
+	}
+
+	return txTypes
+}
+
 func (m *mockBlockStore) GetHeaderByNumber(blockNumber uint64) (*types.Header, bool) {
 	b, ok := m.GetBlockByNumber(blockNumber, false)
 	if !ok {
@@ -428,6 +475,21 @@ func (m *mockBlockStore) GetBlockByNumber(blockNumber uint64, full bool) (*types
 	return nil, false
 }
 
+func (m *mockBlockStore) GetHeadersInRange(from, to uint64) []*types.Header {
+	headers := []*types.Header{}
+
+	for n := from; n <= to; n++ {
+		h, ok := m.GetHeaderByNumber(n)
+		if !ok {
+			break
+		}
+
+		headers = append(headers, h)
+	}
+
+	return headers
+}
+
 func (m *mockBlockStore) GetBlockByHash(hash types.Hash, full bool) (*types.Block, bool) {
 	for _, b := range m.blocks {
 		if b.Hash() == hash {
@@ -442,6 +504,10 @@ func (m *mockBlockStore) Header() *types.Header {
 	return m.blocks[len(m.blocks)-1].Header
 }
 
+func (m *mockBlockStore) GetForksInTime(blockNumber uint64) chain.ForksInTime {
+	return chain.AllForksEnabled.At(blockNumber)
+}
+
 func (m *mockBlockStore) ReadTxLookup(txnHash types.Hash) (types.Hash, bool) {
 	for _, block := range m.blocks {
 		for _, txn := range block.Transactions {
@@ -489,6 +555,34 @@ func (m *mockBlockStore) SubscribeEvents() blockchain.Subscription {
 	return nil
 }
 
+func (m *mockBlockStore) SubscribeEventsWithFilter(*blockchain.EventFilter) blockchain.Subscription {
+	return nil
+}
+
+func (m *mockBlockStore) SubscribePendingTxs() txpool.PendingTxsSubscription {
+	return nil
+}
+
+func (m *mockBlockStore) FilterLogBlocks(
+	addresses []types.Address,
+	topics [][]types.Hash,
+	from, to uint64,
+) ([]uint64, bool) {
+	if m.logIndex == nil {
+		return nil, false
+	}
+
+	numbers := []uint64{}
+
+	for _, n := range m.logIndex {
+		if n >= from && n <= to {
+			numbers = append(numbers, n)
+		}
+	}
+
+	return numbers, true
+}
+
 func newTestBlock(number uint64, hash types.Hash) *types.Block {
 	return &types.Block{
 		Header: &types.Header{
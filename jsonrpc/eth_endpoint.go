@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/dogechain-lab/dogechain/blockchain"
 	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/crypto"
 	"github.com/dogechain-lab/dogechain/helper/hex"
 	"github.com/dogechain-lab/dogechain/helper/progress"
 	"github.com/dogechain-lab/dogechain/state"
 	"github.com/dogechain-lab/dogechain/state/runtime"
+	"github.com/dogechain-lab/dogechain/state/tracer"
 	"github.com/dogechain-lab/dogechain/types"
 	"github.com/dogechain-lab/fastrlp"
 	"github.com/hashicorp/go-hclog"
@@ -35,6 +38,15 @@ type ethStateStore interface {
 	GetStorage(root types.Hash, addr types.Address, slot types.Hash) ([]byte, error)
 	GetForksInTime(blockNumber uint64) chain.ForksInTime
 	GetCode(stateRoot types.Hash, accoun types.Address) ([]byte, error)
+
+	// GetStorageSlots returns the values of the slots within the account storage root
+	GetStorageSlots(storageRoot types.Hash, slots []types.Hash) ([]types.Hash, error)
+
+	// GetAccountProof returns the merkle proof of the account within the state root
+	GetAccountProof(root types.Hash, addr types.Address) ([][]byte, error)
+
+	// GetStorageProof returns the merkle proof of the slot within the account storage root
+	GetStorageProof(storageRoot types.Hash, slot types.Hash) ([][]byte, error)
 }
 
 type ethBlockchainStore interface {
@@ -59,12 +71,19 @@ type ethBlockchainStore interface {
 	// GetReceiptsByHash returns the receipts for a block hash
 	GetReceiptsByHash(hash types.Hash) ([]*types.Receipt, error)
 
+	// GetSystemTxTypes returns the system transaction type of every transaction of the
+	// block, empty for the user transactions
+	GetSystemTxTypes(header *types.Header, txs []*types.Transaction) []blockchain.SystemTxType
+
 	// GetAvgGasPrice returns the average gas price
 	GetAvgGasPrice() *big.Int
 
 	// ApplyTxn applies a transaction object to the blockchain
 	ApplyTxn(header *types.Header, txn *types.Transaction) (*runtime.ExecutionResult, error)
 
+	// TraceTxn applies a transaction object to the blockchain, traced by the logger
+	TraceTxn(header *types.Header, txn *types.Transaction, logger runtime.EVMLogger) (*runtime.ExecutionResult, error)
+
 	// GetSyncProgression retrieves the current sync progression, if any
 	GetSyncProgression() *progress.Progression
 
@@ -87,13 +106,19 @@ type Eth struct {
 	chainID       uint64
 	filterManager *FilterManager
 	priceLimit    uint64
+	signer        Signer // signer of eth_sendTransaction, nil if not supported
 
 	metrics *Metrics
 }
 
+// emptyCodeHash is the code hash of the accounts without code
+var emptyCodeHash = types.BytesToHash(crypto.Keccak256(nil))
+
 var (
 	ErrInsufficientFunds = errors.New("insufficient funds for execution")
 	ErrGasCapOverflow    = errors.New("unable to apply transaction for the highest gas limit")
+	ErrSignerNotEnabled  = errors.New("request calls to eth_sendTransaction method are not supported," +
+		" use eth_sendRawTransaction insead")
 )
 
 // ChainId returns the chain id of the client
@@ -106,18 +131,22 @@ func (e *Eth) ChainId() (interface{}, error) {
 }
 
 func (e *Eth) getHeaderFromBlockNumberOrHash(bnh *BlockNumberOrHash) (*types.Header, error) {
+	return getHeaderFromBlockNumberOrHash(e.store, bnh)
+}
+
+func getHeaderFromBlockNumberOrHash(store ethBlockchainStore, bnh *BlockNumberOrHash) (*types.Header, error) {
 	var (
 		header *types.Header
 		err    error
 	)
 
 	if bnh.BlockNumber != nil {
-		header, err = e.getBlockHeader(*bnh.BlockNumber)
+		header, err = getBlockHeader(store, *bnh.BlockNumber)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get the header of block %d: %w", *bnh.BlockNumber, err)
 		}
 	} else if bnh.BlockHash != nil {
-		block, ok := e.store.GetBlockByHash(*bnh.BlockHash, false)
+		block, ok := store.GetBlockByHash(*bnh.BlockHash, false)
 		if !ok {
 			return nil, fmt.Errorf("could not find block referenced by the hash %s", bnh.BlockHash.String())
 		}
@@ -246,10 +275,70 @@ func (e *Eth) SendRawTransaction(input string) (interface{}, error) {
 	return tx.Hash().String(), nil
 }
 
-// Reject eth_sendTransaction json-rpc call as we don't support wallet management
+// Accounts returns the accounts of the external signer, none if it is not enabled
+func (e *Eth) Accounts() (interface{}, error) {
+	e.metrics.EthAPICounterInc(EthAccountsLabel)
+
+	if e.signer == nil {
+		return []types.Address{}, nil
+	}
+
+	return e.signer.Accounts()
+}
+
+// SendTransaction has the transaction signed by the external signer, and sends it.
+// It is rejected if the signer is not enabled, as the node manages no wallets.
 func (e *Eth) SendTransaction(arg *txnArgs) (interface{}, error) {
-	return nil, fmt.Errorf("request calls to eth_sendTransaction method are not supported," +
-		" use eth_sendRawTransaction insead")
+	e.metrics.EthAPICounterInc(EthSendTransactionLabel)
+
+	if e.signer == nil {
+		return nil, ErrSignerNotEnabled
+	}
+
+	if arg.From == nil {
+		return nil, errors.New("from address is required")
+	}
+
+	// the pending nonce, following the transactions of the pool
+	if arg.Nonce == nil {
+		arg.Nonce = argUintPtr(e.store.GetNonce(*arg.From))
+	}
+
+	if arg.GasPrice == nil {
+		arg.GasPrice = argBytesPtr(e.suggestGasPrice().Bytes())
+	}
+
+	if arg.Gas == nil {
+		estimate, err := e.EstimateGas(arg, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas: %w", err)
+		}
+
+		gasStr, _ := estimate.(string)
+
+		gas, err := types.ParseUint64orHex(&gasStr)
+		if err != nil {
+			return nil, err
+		}
+
+		arg.Gas = argUintPtr(gas)
+	}
+
+	tx, err := e.decodeTxn(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := e.signer.SignTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.store.AddTx(signed); err != nil {
+		return nil, err
+	}
+
+	return signed.Hash().String(), nil
 }
 
 // GetTransactionByHash returns a transaction by its hash.
@@ -392,6 +481,15 @@ func (e *Eth) GetTransactionReceipt(hash types.Hash) (interface{}, error) {
 		}
 	}
 
+	// the intrinsic gas is charged by the rules of the block, the refund is only
+	// known to the trace of the transaction
+	forks := e.store.GetForksInTime(block.Number())
+
+	intrinsicGas, err := state.TransactionGasCost(txn, forks.Homestead, forks.Istanbul)
+	if err != nil {
+		return nil, err
+	}
+
 	res := &receipt{
 		Root:              raw.Root,
 		CumulativeGasUsed: argUint64(raw.CumulativeGasUsed),
@@ -402,10 +500,14 @@ func (e *Eth) GetTransactionReceipt(hash types.Hash) (interface{}, error) {
 		BlockHash:         block.Hash(),
 		BlockNumber:       argUint64(block.Number()),
 		GasUsed:           argUint64(raw.GasUsed),
+		IntrinsicGas:      argUint64(intrinsicGas),
+		EffectiveGasPrice: argBig(*txn.GasPrice),
+		EffectiveCost:     argBig(*new(big.Int).Mul(txn.GasPrice, new(big.Int).SetUint64(raw.GasUsed))),
 		ContractAddress:   raw.ContractAddress,
 		FromAddr:          txn.From,
 		ToAddr:            txn.To,
 		Logs:              logs,
+		SystemTxType:      e.store.GetSystemTxTypes(block.Header, []*types.Transaction{txn})[0],
 	}
 
 	return res, nil
@@ -461,11 +563,89 @@ func (e *Eth) GetStorageAt(
 	return argBytesPtr(types.BytesToHash(data).Bytes()), nil
 }
 
+// GetProof returns the account and the storage values at the referenced block, with
+// the merkle proofs of the state trie, so that they could be verified against the
+// state root of the block. The proofs of the missing keys prove their absence.
+func (e *Eth) GetProof(
+	address types.Address,
+	slots []types.Hash,
+	filter BlockNumberOrHash,
+) (interface{}, error) {
+	e.metrics.EthAPICounterInc(EthGetProofLabel)
+
+	if len(slots) > maxStorageSlots {
+		return nil, ErrTooManyStorageSlots
+	}
+
+	// The filter is empty, use the latest block by default
+	if filter.BlockNumber == nil && filter.BlockHash == nil {
+		filter.BlockNumber, _ = CreateBlockNumberPointer(LatestBlockFlag)
+	}
+
+	header, err := e.getHeaderFromBlockNumberOrHash(&filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header from block hash or block number")
+	}
+
+	accountProof, err := e.store.GetAccountProof(header.StateRoot, address)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &accountProofResult{
+		Address:      address,
+		AccountProof: toArgBytesList(accountProof),
+		Balance:      argBig{},
+		CodeHash:     emptyCodeHash,
+		StorageHash:  types.EmptyRootHash,
+		StorageProof: make([]*storageProof, len(slots)),
+	}
+
+	values := make([]types.Hash, len(slots))
+
+	acc, err := e.store.GetAccount(header.StateRoot, address)
+	if err != nil && !errors.Is(err, ErrStateNotFound) {
+		return nil, err
+	}
+
+	// Account not found, all slots are empty
+	if acc != nil {
+		result.Balance = argBig(*acc.Balance)
+		result.Nonce = argUint64(acc.Nonce)
+		result.CodeHash = types.BytesToHash(acc.CodeHash)
+		result.StorageHash = acc.Root
+
+		// the values are read through the snapshot, the proofs through the trie
+		if values, err = e.store.GetStorageSlots(acc.Root, slots); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, slot := range slots {
+		proof, err := e.store.GetStorageProof(result.StorageHash, slot)
+		if err != nil {
+			return nil, err
+		}
+
+		result.StorageProof[i] = &storageProof{
+			Key:   slot,
+			Value: argBig(*new(big.Int).SetBytes(values[i].Bytes())),
+			Proof: toArgBytesList(proof),
+		}
+	}
+
+	return result, nil
+}
+
 // GasPrice returns the average gas price based on the last x blocks
 func (e *Eth) GasPrice() (interface{}, error) {
 	e.metrics.EthAPICounterInc(EthGasPriceLabel)
 
-	// Grab the average gas price and convert it to a hex value
+	return hex.EncodeBig(e.suggestGasPrice()), nil
+}
+
+// suggestGasPrice returns the average gas price, no less than the price limit
+func (e *Eth) suggestGasPrice() *big.Int {
 	priceLimit := new(big.Int).SetUint64(e.priceLimit)
 	minGasPrice, _ := new(big.Int).SetString(defaultMinGasPrice, 0)
 
@@ -479,7 +659,7 @@ func (e *Eth) GasPrice() (interface{}, error) {
 		v = priceLimit
 	}
 
-	return hex.EncodeBig(v), nil
+	return v
 }
 
 // Call executes a smart contract call using the transaction object data
@@ -519,7 +699,7 @@ func (e *Eth) Call(arg *txnArgs, filter BlockNumberOrHash) (interface{}, error)
 
 	// Check if an EVM revert happened
 	if result.Reverted() {
-		return nil, constructErrorFromRevert(result)
+		return nil, newRevertError(result)
 	}
 
 	if result.Failed() {
@@ -529,6 +709,64 @@ func (e *Eth) Call(arg *txnArgs, filter BlockNumberOrHash) (interface{}, error)
 	return argBytesPtr(result.ReturnValue), nil
 }
 
+// CreateAccessList simulates the call with the access list tracer, and returns the
+// access list of the accounts and the storage slots it touches, with the gas used
+func (e *Eth) CreateAccessList(arg *txnArgs, filter BlockNumberOrHash) (interface{}, error) {
+	e.metrics.EthAPICounterInc(EthCreateAccessListLabel)
+
+	// The filter is empty, use the latest block by default
+	if filter.BlockNumber == nil && filter.BlockHash == nil {
+		filter.BlockNumber, _ = CreateBlockNumberPointer(LatestBlockFlag)
+	}
+
+	header, err := e.getHeaderFromBlockNumberOrHash(&filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header from block hash or block number")
+	}
+
+	transaction, err := e.decodeTxn(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	// If the caller didn't supply the gas limit in the message, then we set it to maximum possible => block gas limit
+	if transaction.Gas == 0 {
+		transaction.Gas = header.GasLimit
+	}
+
+	t, err := tracer.New("accessListTracer", &tracer.Context{
+		GasPrice: transaction.GasPrice,
+		GasLimit: transaction.Gas,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := e.store.TraceTxn(header, transaction, t)
+	if err != nil {
+		return nil, err
+	}
+
+	accessList, err := t.GetResult()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &accessListResult{
+		AccessList: accessList,
+		GasUsed:    argUint64(result.GasUsed),
+	}
+
+	// the access list of the failed call is returned too, with the error
+	if result.Reverted() {
+		res.Error = constructErrorFromRevert(result).Error()
+	} else if result.Failed() {
+		res.Error = result.Err.Error()
+	}
+
+	return res, nil
+}
+
 // EstimateGas estimates the gas needed to execute a transaction
 func (e *Eth) EstimateGas(arg *txnArgs, rawNum *BlockNumber) (interface{}, error) {
 	e.metrics.EthAPICounterInc(EthEstimateGasLabel)
@@ -675,7 +913,7 @@ func (e *Eth) EstimateGas(arg *txnArgs, rawNum *BlockNumber) (interface{}, error
 			if isEVMRevertError(result.Err) {
 				// The EVM reverted during execution, attempt to extract the
 				// error message and return it
-				return true, constructErrorFromRevert(result)
+				return true, newRevertError(result)
 			}
 
 			return true, result.Err
@@ -707,6 +945,13 @@ func (e *Eth) EstimateGas(arg *txnArgs, rawNum *BlockNumber) (interface{}, error
 
 	// Check if the highEnd is a good value to make the transaction pass
 	failed, err := testTransaction(highEnd, false)
+
+	var revertErr *revertError
+	if errors.As(err, &revertErr) {
+		// returned as is, so that the revert data is kept in the error object
+		return 0, revertErr
+	}
+
 	if failed {
 		// The transaction shouldn't fail, for whatever reason, at highEnd
 		return 0, fmt.Errorf(
@@ -878,12 +1123,16 @@ func (e *Eth) Unsubscribe(id string) (bool, error) {
 }
 
 func (e *Eth) getBlockHeader(number BlockNumber) (*types.Header, error) {
+	return getBlockHeader(e.store, number)
+}
+
+func getBlockHeader(store ethBlockchainStore, number BlockNumber) (*types.Header, error) {
 	switch number {
 	case LatestBlockNumber:
-		return e.store.Header(), nil
+		return store.Header(), nil
 
 	case EarliestBlockNumber:
-		header, ok := e.store.GetHeaderByNumber(uint64(0))
+		header, ok := store.GetHeaderByNumber(uint64(0))
 		if !ok {
 			return nil, fmt.Errorf("error fetching genesis block header")
 		}
@@ -895,7 +1144,7 @@ func (e *Eth) getBlockHeader(number BlockNumber) (*types.Header, error) {
 
 	default:
 		// Convert the block number from hex to uint64
-		header, ok := e.store.GetHeaderByNumber(uint64(number))
+		header, ok := store.GetHeaderByNumber(uint64(number))
 		if !ok {
 			return nil, fmt.Errorf("error fetching block number %d header", uint64(number))
 		}
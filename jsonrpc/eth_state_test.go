@@ -10,6 +10,7 @@ import (
 	"github.com/dogechain-lab/dogechain/helper/hex"
 	"github.com/dogechain-lab/dogechain/state"
 	"github.com/dogechain-lab/dogechain/state/runtime"
+	"github.com/dogechain-lab/dogechain/state/runtime/evm"
 	"github.com/dogechain-lab/dogechain/types"
 	"github.com/dogechain-lab/fastrlp"
 	"github.com/stretchr/testify/assert"
@@ -740,6 +741,63 @@ func TestEth_EstimateGas_Reverts(t *testing.T) {
 
 	// Make sure the EVM revert reason is contained
 	assert.ErrorAs(t, estimateErr, &revertReason)
+
+	// Make sure the revert data is returned in the error object
+	var revertErr *revertError
+
+	assert.ErrorAs(t, estimateErr, &revertErr)
+	assert.Equal(t, "execution reverted: revert reason", revertErr.Error())
+	assert.Equal(t, "0x"+exampleReturnData, revertErr.ErrorData())
+}
+
+func TestEth_RevertError(t *testing.T) {
+	// Error(string) with the "revert reason"
+	errorData := hex.MustDecodeHex("0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"000000000000000000000000000000000000000000000000000000000000000d" +
+		"72657665727420726561736f6e00000000000000000000000000000000000000")
+	// Panic(uint256) of the arithmetic overflow
+	panicData := hex.MustDecodeHex("0x4e487b71" +
+		"0000000000000000000000000000000000000000000000000000000000000011")
+	// custom error InsufficientBalance(uint256)
+	customData := hex.MustDecodeHex("0xcf479181" +
+		"0000000000000000000000000000000000000000000000000000000000000064")
+
+	for _, c := range []struct {
+		data    []byte
+		message string
+	}{
+		{errorData, "execution reverted: revert reason"},
+		{panicData, "execution reverted: arithmetic underflow or overflow"},
+		{customData, "execution reverted"},
+		{nil, "execution reverted"},
+	} {
+		err := newRevertError(&runtime.ExecutionResult{
+			ReturnValue: c.data,
+			Err:         runtime.ErrExecutionReverted,
+		})
+
+		assert.Equal(t, c.message, err.Error())
+		assert.Equal(t, 3, err.ErrorCode())
+		assert.ErrorIs(t, err, runtime.ErrExecutionReverted)
+
+		if len(c.data) > 0 {
+			assert.Equal(t, hex.EncodeToHex(c.data), err.ErrorData())
+		} else {
+			assert.Nil(t, err.ErrorData())
+		}
+	}
+
+	// the revert data is kept in the error object
+	resp, err := NewRPCResponse(1, "2.0", nil, newRevertError(&runtime.ExecutionResult{
+		ReturnValue: errorData,
+		Err:         runtime.ErrExecutionReverted,
+	})).Bytes()
+	assert.NoError(t, err)
+	assert.JSONEq(t, fmt.Sprintf(
+		`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted: revert reason","data":"%s"}}`,
+		hex.EncodeToHex(errorData),
+	), string(resp))
 }
 
 func TestEth_EstimateGas_Errors(t *testing.T) {
@@ -765,12 +823,134 @@ func TestEth_EstimateGas_Errors(t *testing.T) {
 	assert.ErrorIs(t, estimateErr, ErrInsufficientFunds)
 }
 
+func TestEth_State_GetProof(t *testing.T) {
+	storageRoot := types.StringToHash("10")
+	codeHash := types.StringToHash("11")
+
+	store := &mockSpecialStore{
+		account: &mockAccount{
+			address: addr0,
+			account: &state.Account{
+				Balance:  big.NewInt(100),
+				Nonce:    100,
+				Root:     storageRoot,
+				CodeHash: codeHash.Bytes(),
+			},
+			storage: map[types.Hash][]byte{hash1: {0x5}},
+		},
+		block: &types.Block{
+			Header: &types.Header{
+				Hash:      types.ZeroHash,
+				Number:    0,
+				StateRoot: types.StringToHash("12"),
+			},
+		},
+	}
+
+	eth := newTestEthEndpoint(store)
+	latest := LatestBlockNumber
+
+	uint64Of := func(v argBig) uint64 {
+		return (*big.Int)(&v).Uint64()
+	}
+
+	res, err := eth.GetProof(addr0, []types.Hash{hash1, hash2}, BlockNumberOrHash{BlockNumber: &latest})
+	assert.NoError(t, err)
+
+	result, ok := res.(*accountProofResult)
+	assert.True(t, ok)
+
+	assert.Equal(t, addr0, result.Address)
+	assert.Equal(t, toArgBytesList([][]byte{store.block.Header.StateRoot.Bytes(), addr0.Bytes()}), result.AccountProof)
+	assert.Equal(t, uint64(100), uint64Of(result.Balance))
+	assert.Equal(t, argUint64(100), result.Nonce)
+	assert.Equal(t, codeHash, result.CodeHash)
+	assert.Equal(t, storageRoot, result.StorageHash)
+
+	assert.Len(t, result.StorageProof, 2)
+	assert.Equal(t, hash1, result.StorageProof[0].Key)
+	assert.Equal(t, uint64(5), uint64Of(result.StorageProof[0].Value))
+	assert.Equal(t, toArgBytesList([][]byte{storageRoot.Bytes(), hash1.Bytes()}), result.StorageProof[0].Proof)
+	assert.Equal(t, uint64(0), uint64Of(result.StorageProof[1].Value))
+
+	// the missing account is proven absent, with the empty storage
+	res, err = eth.GetProof(uninitializedAddress, []types.Hash{hash1}, BlockNumberOrHash{})
+	assert.NoError(t, err)
+
+	result, ok = res.(*accountProofResult)
+	assert.True(t, ok)
+
+	assert.Equal(t, uint64(0), uint64Of(result.Balance))
+	assert.Equal(t, emptyCodeHash, result.CodeHash)
+	assert.Equal(t, types.EmptyRootHash, result.StorageHash)
+	assert.Equal(t, uint64(0), uint64Of(result.StorageProof[0].Value))
+	assert.Equal(t, toArgBytesList([][]byte{types.EmptyRootHash.Bytes(), hash1.Bytes()}), result.StorageProof[0].Proof)
+
+	_, err = eth.GetProof(addr0, make([]types.Hash, maxStorageSlots+1), BlockNumberOrHash{})
+	assert.ErrorIs(t, err, ErrTooManyStorageSlots)
+}
+
+func TestEth_CreateAccessList(t *testing.T) {
+	store := getExampleStore()
+	ethEndpoint := newTestEthEndpoint(store)
+
+	callee := types.StringToAddress("bb")
+	slot := types.BytesToHash([]byte{1})
+
+	// the call touches the slot of the callee
+	store.traceTxnHook = func(
+		header *types.Header,
+		txn *types.Transaction,
+		logger runtime.EVMLogger,
+	) (*runtime.ExecutionResult, error) {
+		assert.Equal(t, header.GasLimit, txn.Gas)
+
+		logger.CaptureStart(nil, txn.From, *txn.To, false, txn.Input, txn.Gas, txn.Value)
+		logger.(runtime.EVMOpcodeHook).CaptureOpcode(&runtime.ScopeContext{
+			ContractAddress: callee,
+			Stack:           []*big.Int{new(big.Int).SetBytes(slot.Bytes())},
+		}, 0, evm.SLOAD, 1)
+
+		return &runtime.ExecutionResult{GasUsed: 30000}, nil
+	}
+
+	res, err := ethEndpoint.CreateAccessList(constructMockTx(nil, nil), BlockNumberOrHash{})
+	assert.NoError(t, err)
+
+	result, ok := res.(*accessListResult)
+	assert.True(t, ok)
+	assert.Equal(t, argUint64(30000), result.GasUsed)
+	assert.Empty(t, result.Error)
+	assert.JSONEq(t,
+		fmt.Sprintf(`[{"address":"%s","storageKeys":["%s"]}]`, callee, slot),
+		string(result.AccessList),
+	)
+
+	// the access list of the reverted call is returned with the error
+	store.traceTxnHook = func(
+		header *types.Header,
+		txn *types.Transaction,
+		logger runtime.EVMLogger,
+	) (*runtime.ExecutionResult, error) {
+		return &runtime.ExecutionResult{Err: runtime.ErrExecutionReverted}, nil
+	}
+
+	res, err = ethEndpoint.CreateAccessList(constructMockTx(nil, nil), BlockNumberOrHash{})
+	assert.NoError(t, err)
+
+	result, ok = res.(*accessListResult)
+	assert.True(t, ok)
+	assert.Equal(t, runtime.ErrExecutionReverted.Error(), result.Error)
+	assert.JSONEq(t, `[]`, string(result.AccessList))
+}
+
 type mockSpecialStore struct {
 	ethStore
 	account *mockAccount
 	block   *types.Block
 
 	applyTxnHook func(header *types.Header, txn *types.Transaction) (*runtime.ExecutionResult, error)
+	traceTxnHook func(header *types.Header, txn *types.Transaction, logger runtime.EVMLogger) (*runtime.ExecutionResult, error)
 }
 
 func (m *mockSpecialStore) GetBlockByHash(hash types.Hash, full bool) (*types.Block, bool) {
@@ -839,3 +1019,32 @@ func (m *mockSpecialStore) ApplyTxn(header *types.Header, txn *types.Transaction
 
 	return &runtime.ExecutionResult{}, nil
 }
+
+func (m *mockSpecialStore) TraceTxn(
+	header *types.Header,
+	txn *types.Transaction,
+	logger runtime.EVMLogger,
+) (*runtime.ExecutionResult, error) {
+	if m.traceTxnHook != nil {
+		return m.traceTxnHook(header, txn, logger)
+	}
+
+	return &runtime.ExecutionResult{}, nil
+}
+
+func (m *mockSpecialStore) GetStorageSlots(storageRoot types.Hash, slots []types.Hash) ([]types.Hash, error) {
+	values := make([]types.Hash, len(slots))
+	for i, slot := range slots {
+		values[i] = types.BytesToHash(m.account.storage[slot])
+	}
+
+	return values, nil
+}
+
+func (m *mockSpecialStore) GetAccountProof(root types.Hash, addr types.Address) ([][]byte, error) {
+	return [][]byte{root.Bytes(), addr.Bytes()}, nil
+}
+
+func (m *mockSpecialStore) GetStorageProof(storageRoot types.Hash, slot types.Hash) ([][]byte, error) {
+	return [][]byte{storageRoot.Bytes(), slot.Bytes()}, nil
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/txpool"
 	"github.com/dogechain-lab/dogechain/types"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -36,6 +37,12 @@ const (
 	NoIndexInHeap = -1
 	// _checkDuration is for filter timeout check
 	_checkDuration = time.Second
+	// _eventBufferSize is the size of the blockchain events buffered for the filters,
+	// the events are dropped once it is full
+	_eventBufferSize = 64
+	// _logWalkHeadersWindow is the headers read at once by the log queries walking a
+	// range of blocks
+	_logWalkHeadersWindow = 1024
 )
 
 // filter is an interface that BlockFilter and LogFilter implement
@@ -107,7 +114,7 @@ func (f *filterBase) writeMessageToWs(msg string) error {
 		return err
 	}
 
-	return f.ws.WriteMessage(
+	return f.ws.WriteNotification(
 		websocket.TextMessage,
 		v.Bytes(),
 	)
@@ -221,13 +228,89 @@ func (f *logFilter) sendUpdates() error {
 	return nil
 }
 
+// pendingTxFilter is a filter to store the transactions promoted to the pending queue
+type pendingTxFilter struct {
+	filterBase
+	sync.Mutex
+	fullTx bool
+	txs    []*types.Transaction
+}
+
+// appendTxs appends new pending transactions to txs
+func (f *pendingTxFilter) appendTxs(txs []*types.Transaction) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.txs = append(f.txs, txs...)
+}
+
+// takeTxUpdates returns all saved transactions in filter and set new tx slice
+func (f *pendingTxFilter) takeTxUpdates() []*types.Transaction {
+	f.Lock()
+	defer f.Unlock()
+
+	txs := f.txs
+	f.txs = nil
+
+	return txs
+}
+
+// getUpdates returns the hashes of the stored transactions in string
+func (f *pendingTxFilter) getUpdates() (string, error) {
+	txs := f.takeTxUpdates()
+
+	hashes := make([]types.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
+
+	res, err := json.Marshal(hashes)
+	if err != nil {
+		return "", err
+	}
+
+	return string(res), nil
+}
+
+// sendUpdates writes the stored transactions to web socket stream, either the
+// hashes or the full transaction objects
+func (f *pendingTxFilter) sendUpdates() error {
+	txs := f.takeTxUpdates()
+
+	for _, tx := range txs {
+		var (
+			raw []byte
+			err error
+		)
+
+		if f.fullTx {
+			raw, err = json.Marshal(toPendingTransaction(tx))
+		} else {
+			raw, err = json.Marshal(tx.Hash())
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := f.writeMessageToWs(string(raw)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // filterManagerStore provides methods required by FilterManager
 type filterManagerStore interface {
 	// Header returns the current header of the chain (genesis if empty)
 	Header() *types.Header
 
-	// SubscribeEvents subscribes for chain head events
-	SubscribeEvents() blockchain.Subscription
+	// SubscribeEventsWithFilter subscribes for the blockchain events matching the filter
+	SubscribeEventsWithFilter(filter *blockchain.EventFilter) blockchain.Subscription
+
+	// SubscribePendingTxs subscribes for the transactions promoted to the pending queue
+	SubscribePendingTxs() txpool.PendingTxsSubscription
 
 	// GetReceiptsByHash returns the receipts for a block hash
 	GetReceiptsByHash(hash types.Hash) ([]*types.Receipt, error)
@@ -237,6 +320,14 @@ type filterManagerStore interface {
 
 	// GetBlockByNumber returns a block using the provided number
 	GetBlockByNumber(num uint64, full bool) (*types.Block, bool)
+
+	// GetHeadersInRange returns the canonical headers within [from, to], stopping at
+	// the first missing one
+	GetHeadersInRange(from, to uint64) []*types.Header
+
+	// FilterLogBlocks returns the block numbers within [from, to] which might contain
+	// the logs, it returns false if neither the log index nor the bloom bits are available
+	FilterLogBlocks(addresses []types.Address, topics [][]types.Hash, from, to uint64) ([]uint64, bool)
 }
 
 // FilterManager manages all running filters
@@ -280,8 +371,13 @@ func NewFilterManager(logger hclog.Logger, store filterManagerStore, blockRangeL
 
 // Run starts worker process to handle events
 func (f *FilterManager) Run() {
-	// subscribe for new blockchain events
-	sub := f.store.SubscribeEvents()
+	// subscribe for the new headers above the current one, the forks bring no new
+	// chain to the filters
+	sub := f.store.SubscribeEventsWithFilter(&blockchain.EventFilter{
+		Types:          []blockchain.EventType{blockchain.EventHead, blockchain.EventReorg},
+		MinBlockNumber: f.store.Header().Number + 1,
+		BufferSize:     _eventBufferSize,
+	})
 	defer sub.Unsubscribe()
 
 	// watch for new events in the blockchain
@@ -302,6 +398,12 @@ func (f *FilterManager) Run() {
 		}
 	}()
 
+	// subscribe for the promoted transactions of the txpool
+	pendingSub := f.store.SubscribePendingTxs()
+	defer pendingSub.Unsubscribe()
+
+	pendingCh := pendingSub.GetTxs()
+
 	// Do not use 'for range + create long time after chan' any more,
 	// which would bring out some unpredictable result, especially when
 	// re-assgining the chan, the elder one would not be recycled by
@@ -331,6 +433,18 @@ func (f *FilterManager) Run() {
 			if err := f.dispatchEvent(ev); err != nil {
 				f.logger.Error("failed to dispatch event", "err", err)
 			}
+		case txs, ok := <-pendingCh:
+			if !ok {
+				// the txpool is closed, stop listening
+				pendingCh = nil
+
+				continue
+			}
+
+			// new pending transactions
+			if err := f.dispatchPendingTxs(txs); err != nil {
+				f.logger.Error("failed to dispatch pending transactions", "err", err)
+			}
 		case <-checkTimer.C:
 			// no need to do anything, checkout the timeout filter in the next loop
 		case <-f.updateCh:
@@ -371,6 +485,21 @@ func (f *FilterManager) NewLogFilter(logQuery *LogQuery, ws wsConn) string {
 	return f.addFilter(filter)
 }
 
+// NewPendingTxFilter adds new PendingTxFilter, which delivers the full transaction
+// objects instead of the hashes if fullTx is set
+func (f *FilterManager) NewPendingTxFilter(fullTx bool, ws wsConn) string {
+	filter := &pendingTxFilter{
+		filterBase: newFilterBase(ws),
+		fullTx:     fullTx,
+	}
+
+	if filter.hasWSConn() {
+		ws.SetFilterID(filter.id)
+	}
+
+	return f.addFilter(filter)
+}
+
 // Exists checks the filter with given ID exists
 func (f *FilterManager) Exists(id string) bool {
 	f.RLock()
@@ -411,7 +540,8 @@ func (f *FilterManager) getLogsFromBlock(query *LogQuery, block *types.Block) ([
 	return logs, nil
 }
 
-func (f *FilterManager) getLogsFromBlocks(query *LogQuery) ([]*Log, error) {
+// visitLogsFromBlocks passes the matching logs of the block range to visit, block by block
+func (f *FilterManager) visitLogsFromBlocks(query *LogQuery, visit func([]*Log) error) error {
 	latestBlockNumber := f.store.Header().Number
 
 	resolveNum := func(num BlockNumber) (uint64, error) {
@@ -429,12 +559,12 @@ func (f *FilterManager) getLogsFromBlocks(query *LogQuery) ([]*Log, error) {
 
 	from, err := resolveNum(query.FromBlock)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	to, err := resolveNum(query.ToBlock)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// If from equals genesis block
@@ -444,57 +574,137 @@ func (f *FilterManager) getLogsFromBlocks(query *LogQuery) ([]*Log, error) {
 	}
 
 	if to < from {
-		return nil, ErrIncorrectBlockRange
+		return ErrIncorrectBlockRange
 	}
 
 	// if not disabled, avoid handling large block ranges
 	if f.blockRangeLimit > 0 && to-from > f.blockRangeLimit {
-		return nil, ErrBlockRangeTooHigh
+		return ErrBlockRangeTooHigh
 	}
 
-	logs := make([]*Log, 0)
+	visitBlock := func(num uint64) (bool, error) {
+		blockLogs, found, err := f.getLogsFromBlockNumber(query, num)
+		if err != nil || !found || len(blockLogs) == 0 {
+			return found, err
+		}
 
-	for i := from; i <= to; i++ {
-		block, ok := f.store.GetBlockByNumber(i, true)
-		if !ok {
-			break
+		return true, visit(blockLogs)
+	}
+
+	// consult the log index or bloom bits to skip the blocks without matching logs
+	if numbers, ok := f.store.FilterLogBlocks(query.Addresses, query.Topics, from, to); ok {
+		for _, i := range numbers {
+			if found, err := visitBlock(i); err != nil {
+				return err
+			} else if !found {
+				break
+			}
 		}
 
-		if len(block.Transactions) == 0 {
-			// do not check logs if no txs
-			continue
+		return nil
+	}
+
+	// walk the headers of the range, so that the bodies of the empty blocks are not read
+	for from <= to {
+		end := to
+		if end-from >= _logWalkHeadersWindow {
+			end = from + _logWalkHeadersWindow - 1
 		}
 
-		blockLogs, err := f.getLogsFromBlock(query, block)
-		if err != nil {
-			return nil, err
+		headers := f.store.GetHeadersInRange(from, end)
+
+		for _, header := range headers {
+			if header.TxRoot == types.EmptyRootHash {
+				continue
+			}
+
+			if err := f.visitLogsFromHeader(query, header, visit); err != nil {
+				return err
+			}
 		}
 
-		logs = append(logs, blockLogs...)
+		if uint64(len(headers)) < end-from+1 {
+			break
+		}
+
+		from = end + 1
 	}
 
-	return logs, nil
+	return nil
+}
+
+// visitLogsFromHeader passes the matching logs of the block to visit
+func (f *FilterManager) visitLogsFromHeader(query *LogQuery, header *types.Header, visit func([]*Log) error) error {
+	block, ok := f.store.GetBlockByHash(header.Hash, true)
+	if !ok || len(block.Transactions) == 0 {
+		return nil
+	}
+
+	logs, err := f.getLogsFromBlock(query, block)
+	if err != nil || len(logs) == 0 {
+		return err
+	}
+
+	return visit(logs)
+}
+
+// getLogsFromBlockNumber returns the matching logs of the block, false if the block not found
+func (f *FilterManager) getLogsFromBlockNumber(query *LogQuery, num uint64) ([]*Log, bool, error) {
+	block, ok := f.store.GetBlockByNumber(num, true)
+	if !ok {
+		return nil, false, nil
+	}
+
+	if len(block.Transactions) == 0 {
+		// do not check logs if no txs
+		return nil, true, nil
+	}
+
+	logs, err := f.getLogsFromBlock(query, block)
+
+	return logs, true, err
 }
 
 // GetLogs return array of logs for given query
 func (f *FilterManager) GetLogs(query *LogQuery) ([]*Log, error) {
+	logs := make([]*Log, 0)
+
+	if err := f.visitLogs(query, func(blockLogs []*Log) error {
+		logs = append(logs, blockLogs...)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// visitLogs passes the logs of the query to visit block by block, so that the huge
+// results are not held at once. It stops at the first error of visit.
+func (f *FilterManager) visitLogs(query *LogQuery, visit func([]*Log) error) error {
 	if query.BlockHash != nil {
 		//	BlockHash is set -> fetch logs from this block only
 		block, ok := f.store.GetBlockByHash(*query.BlockHash, true)
 		if !ok {
-			return nil, ErrBlockNotFound
+			return ErrBlockNotFound
 		}
 
 		if len(block.Transactions) == 0 {
-			// no txs in block, return empty response
-			return []*Log{}, nil
+			// no txs in block, nothing to visit
+			return nil
 		}
 
-		return f.getLogsFromBlock(query, block)
+		logs, err := f.getLogsFromBlock(query, block)
+		if err != nil || len(logs) == 0 {
+			return err
+		}
+
+		return visit(logs)
 	}
 
 	//	gets logs from a range of blocks
-	return f.getLogsFromBlocks(query)
+	return f.visitLogsFromBlocks(query, visit)
 }
 
 // getFilterByID fetches the filter by the ID
@@ -734,6 +944,21 @@ func (f *FilterManager) appendLogsToFilters(header *types.Header) error {
 	return nil
 }
 
+// dispatchPendingTxs is an event handler for new pending transactions
+func (f *FilterManager) dispatchPendingTxs(txs []*types.Transaction) error {
+	pendingTxFilters := f.getPendingTxFilters()
+	if len(pendingTxFilters) == 0 {
+		return nil
+	}
+
+	for _, filter := range pendingTxFilters {
+		filter.appendTxs(txs)
+	}
+
+	// send data to web socket stream
+	return f.flushWsFilters()
+}
+
 // flushWsFilters make each filters with web socket connection write the updates to web socket stream
 // flushWsFilters also removes the filters if flushWsFilters notices the connection is closed
 func (f *FilterManager) flushWsFilters() error {
@@ -756,6 +981,13 @@ func (f *FilterManager) flushWsFilters() error {
 				continue
 			}
 
+			// slow client, the updates are dropped
+			if errors.Is(flushErr, ErrWSQueueFull) {
+				f.logger.Debug(fmt.Sprintf("Subscription %s updates dropped", id))
+
+				continue
+			}
+
 			f.logger.Error(fmt.Sprintf("Unable to process flush, %v", flushErr))
 		}
 	}
@@ -792,6 +1024,22 @@ func (f *FilterManager) getLogFilters() []*logFilter {
 	return logFilters
 }
 
+// getPendingTxFilters returns pendingTxFilters
+func (f *FilterManager) getPendingTxFilters() []*pendingTxFilter {
+	f.RLock()
+	defer f.RUnlock()
+
+	pendingTxFilters := make([]*pendingTxFilter, 0)
+
+	for _, f := range f.filters {
+		if pendingTxFilter, ok := f.(*pendingTxFilter); ok {
+			pendingTxFilters = append(pendingTxFilters, pendingTxFilter)
+		}
+	}
+
+	return pendingTxFilters
+}
+
 type timeHeapImpl []*filterBase
 
 func (t *timeHeapImpl) addFilter(filter *filterBase) {
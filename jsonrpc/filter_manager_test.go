@@ -283,6 +283,48 @@ func Test_GetLogsForQuery(t *testing.T) {
 	}
 }
 
+func Test_GetLogsForQuery_LogIndex(t *testing.T) {
+	t.Parallel()
+
+	topics := [][]types.Hash{{types.StringToHash("4")}}
+
+	store := &mockBlockStore{
+		topics:   []types.Hash{types.StringToHash("4")},
+		logIndex: []uint64{2, 4},
+	}
+	store.setupLogs()
+
+	for i := 0; i < 5; i++ {
+		store.add(&types.Block{
+			Header: &types.Header{
+				Number: uint64(i),
+				Hash:   types.StringToHash(strconv.Itoa(i)),
+			},
+			Transactions: []*types.Transaction{
+				{Value: big.NewInt(10)},
+				{Value: big.NewInt(11)},
+				{Value: big.NewInt(12)},
+			},
+		})
+	}
+
+	f := NewFilterManager(hclog.NewNullLogger(), store, 1000)
+
+	t.Cleanup(func() {
+		f.Close() // prevent memory leak
+	})
+
+	// only the indexed blocks are scanned, block 1 and 3 are skipped
+	logs, err := f.GetLogs(&LogQuery{
+		FromBlock: 1,
+		ToBlock:   4,
+		Topics:    topics,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	assert.Equal(t, argUint64(2), logs[0].BlockNumber)
+}
+
 func Test_GetLogFilterFromID(t *testing.T) {
 	t.Parallel() // speed it up
 
@@ -395,12 +437,20 @@ func (m *mockWsConn) GetFilterID() string {
 	return m.filterID
 }
 
+func (m *mockWsConn) GetClient() client {
+	return client{}
+}
+
 func (m *mockWsConn) WriteMessage(messageType int, b []byte) error {
 	m.msgCh <- b
 
 	return nil
 }
 
+func (m *mockWsConn) WriteNotification(messageType int, b []byte) error {
+	return m.WriteMessage(messageType, b)
+}
+
 func TestHeadStream(t *testing.T) {
 	t.Parallel()
 
@@ -433,10 +483,18 @@ func (m *MockClosedWSConnection) GetFilterID() string {
 	return ""
 }
 
+func (m *MockClosedWSConnection) GetClient() client {
+	return client{}
+}
+
 func (m *MockClosedWSConnection) WriteMessage(_messageType int, _data []byte) error {
 	return websocket.ErrCloseSent
 }
 
+func (m *MockClosedWSConnection) WriteNotification(_messageType int, _data []byte) error {
+	return websocket.ErrCloseSent
+}
+
 func TestClosedFilterDeletion(t *testing.T) {
 	t.Parallel()
 
@@ -470,3 +528,25 @@ func TestClosedFilterDeletion(t *testing.T) {
 	// false because filter was removed automatically
 	assert.False(t, m.Exists(id))
 }
+
+func TestFilterManager_EventFilter(t *testing.T) {
+	t.Parallel()
+
+	store := newMockStore()
+	store.header = &types.Header{Number: 10}
+
+	m := NewFilterManager(hclog.NewNullLogger(), store, 1000)
+	defer m.Close()
+
+	go m.Run()
+
+	select {
+	case filter := <-store.eventFilter:
+		// the new chains above the current header only
+		assert.Equal(t, []blockchain.EventType{blockchain.EventHead, blockchain.EventReorg}, filter.Types)
+		assert.Equal(t, uint64(11), filter.MinBlockNumber)
+		assert.Equal(t, _eventBufferSize, filter.BufferSize)
+	case <-time.After(5 * time.Second):
+		t.Fatal("events not subscribed")
+	}
+}
@@ -0,0 +1,153 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// defaultLogStreamChunkSize is the logs per chunk of a streamed query by default
+	defaultLogStreamChunkSize = 1000
+	// maxLogStreamChunkSize is the most logs per chunk of a streamed query
+	maxLogStreamChunkSize = 10000
+)
+
+// logStreamOptions is the optional second parameter of eth_getFilterLogs over
+// websocket, the logs are streamed in chunks if Stream is set
+type logStreamOptions struct {
+	Stream    bool   `json:"stream"`
+	ChunkSize uint64 `json:"chunkSize"` // logs per chunk, defaultLogStreamChunkSize if zero
+}
+
+// logStreamChunk is a chunk of the streamed logs. The last chunk is marked done, with
+// the total logs streamed, or the error stopping the stream.
+type logStreamChunk struct {
+	Logs  []*Log `json:"logs"`
+	Done  bool   `json:"done"`
+	Total uint64 `json:"total,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// parseLogStreamRequest returns the filter ID and the stream options of the request,
+// false if the logs are not requested in stream
+func parseLogStreamRequest(req Request) (string, *logStreamOptions, bool) {
+	var params []json.RawMessage
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) != 2 {
+		return "", nil, false
+	}
+
+	var (
+		filterID string
+		opts     logStreamOptions
+	)
+
+	if err := json.Unmarshal(params[0], &filterID); err != nil {
+		return "", nil, false
+	}
+
+	if err := json.Unmarshal(params[1], &opts); err != nil || !opts.Stream {
+		return "", nil, false
+	}
+
+	return filterID, &opts, true
+}
+
+// streamFilterLogs streams the logs of the filter over the websocket, for the huge
+// historical queries exceeding the response size limits. The stream ID is responded
+// at once, then the logs are delivered in chunks by eth_subscription notifications
+// of the stream ID, until the one marked done.
+func (d *Dispatcher) streamFilterLogs(req Request, filterID string, opts *logStreamOptions, conn wsConn) error {
+	d.metrics.EthAPICounterInc(EthGetFilterLogsLabel)
+
+	chunkSize := opts.ChunkSize
+
+	switch {
+	case chunkSize == 0:
+		chunkSize = defaultLogStreamChunkSize
+	case chunkSize > maxLogStreamChunkSize:
+		return writeStreamError(conn, req,
+			NewInvalidParamsError(fmt.Sprintf("chunk size exceeds %d", maxLogStreamChunkSize)))
+	}
+
+	logFilter, err := d.filterManager.GetLogFilterFromID(filterID)
+	if err != nil {
+		return writeStreamError(conn, req, NewInvalidRequestError(err.Error()))
+	}
+
+	streamID := uuid.New().String()
+
+	resp, rpcErr := formatFilterResponse(req.ID, streamID)
+	if rpcErr != nil {
+		return writeStreamError(conn, req, rpcErr)
+	}
+
+	if err := writeStreamMessage(conn, []byte(resp)); err != nil {
+		return err
+	}
+
+	var (
+		pending []*Log
+		total   uint64
+	)
+
+	visitErr := d.filterManager.visitLogs(logFilter.query, func(logs []*Log) error {
+		pending = append(pending, logs...)
+
+		for uint64(len(pending)) >= chunkSize {
+			if err := writeLogStreamChunk(conn, streamID, &logStreamChunk{Logs: pending[:chunkSize]}); err != nil {
+				return err
+			}
+
+			total += chunkSize
+			pending = pending[chunkSize:]
+		}
+
+		return nil
+	})
+
+	last := &logStreamChunk{Logs: pending, Done: true}
+
+	if visitErr != nil {
+		d.logger.Debug("log stream stopped", "stream", streamID, "err", visitErr)
+
+		last.Logs, last.Error = nil, visitErr.Error()
+	} else {
+		last.Total = total + uint64(len(pending))
+	}
+
+	if last.Logs == nil {
+		last.Logs = []*Log{}
+	}
+
+	return writeLogStreamChunk(conn, streamID, last)
+}
+
+// writeStreamError responds the error of the stream request
+func writeStreamError(conn wsConn, req Request, rpcErr Error) error {
+	data, err := NewRPCResponse(req.ID, "2.0", nil, rpcErr).Bytes()
+	if err != nil {
+		return err
+	}
+
+	return writeStreamMessage(conn, data)
+}
+
+// writeLogStreamChunk writes the chunk as an eth_subscription notification of the stream
+func writeLogStreamChunk(conn wsConn, streamID string, chunk *logStreamChunk) error {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return err
+	}
+
+	return writeStreamMessage(conn, []byte(fmt.Sprintf(ethSubscriptionTemplate, streamID, data)))
+}
+
+// writeStreamMessage writes the message of a stream, which is never dropped like the
+// notifications. WriteMessage waits for the send queue of the slow client up to the
+// write timeout, and the stream stops with the connection closed after it.
+func writeStreamMessage(conn wsConn, data []byte) error {
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
@@ -1,7 +1,9 @@
 package jsonrpc
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -9,9 +11,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/dogechain-lab/dogechain/helper/tlsutil"
 	"github.com/dogechain-lab/dogechain/versioning"
 	"github.com/gorilla/websocket"
 	"github.com/hashicorp/go-hclog"
+	"golang.org/x/time/rate"
 )
 
 type serverType int
@@ -37,6 +41,27 @@ func (s serverType) String() string {
 
 const (
 	_authoritativeChainName = "Dogechain"
+
+	// wsWriteTimeout is how long a websocket message waits for the slow client, the
+	// connection is closed after it
+	wsWriteTimeout = 30 * time.Second
+)
+
+var (
+	ErrWSRateLimited  = errors.New("websocket message rate limit exceeded")
+	ErrWSQueueFull    = errors.New("websocket send queue is full")
+	ErrWSWriteTimeout = errors.New("websocket write timed out")
+)
+
+// WSDropPolicy is the policy applied to the subscription notifications when the send
+// queue of a websocket connection is full
+type WSDropPolicy string
+
+const (
+	// WSDropPolicyDrop drops the outgoing messages until the queue drains
+	WSDropPolicyDrop WSDropPolicy = "drop"
+	// WSDropPolicyDisconnect closes the connection of the slow client
+	WSDropPolicyDisconnect WSDropPolicy = "disconnect"
 )
 
 // JSONRPC is an API backend
@@ -44,20 +69,27 @@ type JSONRPC struct {
 	logger     hclog.Logger
 	config     *Config
 	dispatcher dispatcher
+	auth       *authenticator // nil if the bearer token is not required
 	metrics    *Metrics
+	httpServer *http.Server
+
+	certReloader *tlsutil.CertReloader // nil if serving plain http
 }
 
 type dispatcher interface {
 	RemoveFilterByWs(conn wsConn)
 	HandleWs(reqBody []byte, conn wsConn) ([]byte, error)
-	Handle(reqBody []byte) ([]byte, error)
+	handleClient(c client, reqBody []byte) ([]byte, error)
 }
 
 // JSONRPCStore defines all the methods required
 // by all the JSON RPC endpoints
 type JSONRPCStore interface {
 	ethStore
+	debugStore
+	dcStore
 	networkStore
+	adminStore
 	txPoolStore
 	filterManagerStore
 }
@@ -71,28 +103,46 @@ type Config struct {
 	BlockRangeLimit          uint64
 	JSONNamespaces           []Namespace
 	EnableWS                 bool
+	WSMaxMessageSize         uint64       // max incoming message size in bytes, 0 for unlimited
+	WSMessageRateLimit       uint64       // max incoming messages per second, 0 for unlimited
+	WSSendQueueSize          uint64       // max queued outgoing messages, 0 for writing synchronously
+	WSDropPolicy             WSDropPolicy // policy applied when the send queue is full
 	PriceLimit               uint64
-	EnablePProf              bool // whether pprof enable or not
-	EnableJaeger             bool // whether jaeger enable or not
+	EnablePProf              bool             // whether pprof enable or not
+	EnableJaeger             bool             // whether jaeger enable or not
+	ResponseCache            ResponseCache    // cache of the immutable queries, nil if disabled
+	Signer                   Signer           // signer of eth_sendTransaction, nil if disabled
+	RateLimit                *RateLimitConfig // limits of the requests per client IP, nil if unlimited
+	TrustedProxies           []*net.IPNet     // proxies whose forwarded client IP headers are honoured
+	AuthSecret               []byte           // HS256 secret of the bearer tokens, nil if not required
+	AdminApprover            AdminApprover    // approver of the admin methods, nil if not required
+	TLS                      *tlsutil.Config  // certificate terminating TLS, nil for plain http
 	Metrics                  *Metrics
 }
 
 // NewJSONRPC returns the JSONRPC http server
 func NewJSONRPC(logger hclog.Logger, config *Config) (*JSONRPC, error) {
+	d := newDispatcher(
+		logger,
+		NewDummyMetrics(config.Metrics),
+		config.Store,
+		config.ChainID,
+		config.BatchLengthLimit,
+		config.BlockRangeLimit,
+		config.PriceLimit,
+		config.JSONNamespaces,
+	)
+	d.responseCache = config.ResponseCache
+	d.endpoints.Eth.signer = config.Signer
+	d.rateLimiter = newRateLimiter(config.RateLimit)
+	d.approver = config.AdminApprover
+
 	srv := &JSONRPC{
-		logger: logger.Named("jsonrpc"),
-		config: config,
-		dispatcher: newDispatcher(
-			logger,
-			NewDummyMetrics(config.Metrics),
-			config.Store,
-			config.ChainID,
-			config.BatchLengthLimit,
-			config.BlockRangeLimit,
-			config.PriceLimit,
-			config.JSONNamespaces,
-		),
-		metrics: NewDummyMetrics(config.Metrics),
+		logger:     logger.Named("jsonrpc"),
+		config:     config,
+		dispatcher: d,
+		auth:       newAuthenticator(config.AuthSecret),
+		metrics:    NewDummyMetrics(config.Metrics),
 	}
 
 	// start http server
@@ -104,13 +154,23 @@ func NewJSONRPC(logger hclog.Logger, config *Config) (*JSONRPC, error) {
 }
 
 func (j *JSONRPC) setupHTTP() error {
-	j.logger.Info("http server started", "addr", j.config.Addr.String())
+	j.logger.Info("http server started", "addr", j.config.Addr.String(), "tls", j.config.TLS != nil)
 
 	lis, err := net.Listen("tcp", j.config.Addr.String())
 	if err != nil {
 		return err
 	}
 
+	if j.config.TLS != nil {
+		if j.certReloader, err = tlsutil.NewCertReloader(j.logger, *j.config.TLS); err != nil {
+			lis.Close()
+
+			return err
+		}
+
+		lis = tlsutil.NewListener(lis, j.certReloader)
+	}
+
 	var mux *http.ServeMux
 	if j.config.EnablePProf {
 		// debug feature enabled
@@ -131,7 +191,7 @@ func (j *JSONRPC) setupHTTP() error {
 		mux.HandleFunc("/ws", j.handleWs)
 	}
 
-	srv := http.Server{
+	srv := &http.Server{
 		Handler:           mux,
 		ReadTimeout:       time.Minute,
 		ReadHeaderTimeout: time.Minute,
@@ -139,8 +199,10 @@ func (j *JSONRPC) setupHTTP() error {
 		IdleTimeout:       2 * time.Minute,
 	}
 
+	j.httpServer = srv
+
 	go func() {
-		if err := srv.Serve(lis); err != nil {
+		if err := srv.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
 			j.logger.Error("closed http connection", "err", err)
 		}
 	}()
@@ -148,6 +210,24 @@ func (j *JSONRPC) setupHTTP() error {
 	return nil
 }
 
+// Close stops serving, the in-flight requests are given the timeout to finish
+// before their connections are closed
+func (j *JSONRPC) Close(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if j.certReloader != nil {
+		j.certReloader.Close()
+	}
+
+	err := j.httpServer.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return j.httpServer.Close()
+	}
+
+	return err
+}
+
 // The middlewareFactory builds a middleware which enables CORS using the provided config.
 func middlewareFactory(config *Config) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -182,6 +262,12 @@ var wsUpgrader = websocket.Upgrader{
 	WriteBufferSize: 1024,
 }
 
+// wsMessage is an outgoing message queued for the WS peer
+type wsMessage struct {
+	messageType int
+	data        []byte
+}
+
 // wsWrapper is a wrapping object for the web socket connection and logger
 type wsWrapper struct {
 	sync.Mutex // basic r/w lock
@@ -189,6 +275,36 @@ type wsWrapper struct {
 	ws       *websocket.Conn // the actual WS connection
 	logger   hclog.Logger    // module logger
 	filterID string          // filter ID
+	client   client          // caller of the requests
+
+	policy       WSDropPolicy   // policy applied when the send queue is full
+	sendCh       chan wsMessage // bounded send queue, nil means writing synchronously
+	writeTimeout time.Duration  // the longest wait of a message for the slow client
+	closeCh      chan struct{}
+	closeOnce    sync.Once
+}
+
+func newWsWrapper(
+	ws *websocket.Conn,
+	logger hclog.Logger,
+	client client,
+	queueSize uint64,
+	policy WSDropPolicy,
+) *wsWrapper {
+	w := &wsWrapper{
+		ws:           ws,
+		logger:       logger,
+		client:       client,
+		policy:       policy,
+		writeTimeout: wsWriteTimeout,
+		closeCh:      make(chan struct{}),
+	}
+
+	if queueSize > 0 {
+		w.sendCh = make(chan wsMessage, queueSize)
+	}
+
+	return w
 }
 
 func (w *wsWrapper) SetFilterID(filterID string) {
@@ -199,10 +315,69 @@ func (w *wsWrapper) GetFilterID() string {
 	return w.filterID
 }
 
-// WriteMessage writes out the message to the WS peer
+func (w *wsWrapper) GetClient() client {
+	return w.client
+}
+
+// WriteMessage writes out the message to the WS peer. When the send queue is enabled,
+// it waits for the queue to take the message, so that the responses are never dropped.
+// The connection of the client not draining the queue in time is closed.
 func (w *wsWrapper) WriteMessage(messageType int, data []byte) error {
+	if w.sendCh == nil {
+		return w.write(messageType, data)
+	}
+
+	timer := time.NewTimer(w.writeTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-w.closeCh:
+		return websocket.ErrCloseSent
+	case w.sendCh <- wsMessage{messageType: messageType, data: data}:
+		return nil
+	case <-timer.C:
+		w.logger.Warn("WS send queue is not drained in time, closing the connection")
+		w.close()
+
+		return ErrWSWriteTimeout
+	}
+}
+
+// WriteNotification writes out the subscription notification to the WS peer. When the
+// send queue is enabled, the notification is queued, and the drop policy applies if
+// the queue is full.
+func (w *wsWrapper) WriteNotification(messageType int, data []byte) error {
+	if w.sendCh == nil {
+		return w.write(messageType, data)
+	}
+
+	select {
+	case <-w.closeCh:
+		return websocket.ErrCloseSent
+	case w.sendCh <- wsMessage{messageType: messageType, data: data}:
+		return nil
+	default:
+	}
+
+	if w.policy == WSDropPolicyDisconnect {
+		w.logger.Warn("WS send queue is full, closing the connection")
+		w.close()
+
+		return websocket.ErrCloseSent
+	}
+
+	return ErrWSQueueFull
+}
+
+func (w *wsWrapper) write(messageType int, data []byte) error {
 	w.Lock()
 	defer w.Unlock()
+
+	// the write to the slow client fails after the deadline
+	if err := w.ws.SetWriteDeadline(time.Now().Add(w.writeTimeout)); err != nil {
+		return err
+	}
+
 	writeErr := w.ws.WriteMessage(messageType, data)
 
 	if writeErr != nil {
@@ -214,6 +389,47 @@ func (w *wsWrapper) WriteMessage(messageType int, data []byte) error {
 	return writeErr
 }
 
+// writeLoop writes out the queued messages until the connection is closed
+func (w *wsWrapper) writeLoop() {
+	for {
+		select {
+		case <-w.closeCh:
+			return
+		case msg := <-w.sendCh:
+			if err := w.write(msg.messageType, msg.data); err != nil {
+				w.close()
+
+				return
+			}
+		}
+	}
+}
+
+// close closes the WS connection, which also stops the read and write loops
+func (w *wsWrapper) close() {
+	w.closeOnce.Do(func() {
+		close(w.closeCh)
+
+		if err := w.ws.Close(); err != nil {
+			w.logger.Error(
+				fmt.Sprintf("Unable to gracefully close WS connection, %s", err.Error()),
+			)
+		}
+	})
+}
+
+// closeWith closes the WS connection with the close code and reason
+func (w *wsWrapper) closeWith(code int, reason string) {
+	// the control messages are safe to write along the other writes
+	_ = w.ws.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(time.Second),
+	)
+
+	w.close()
+}
+
 // isSupportedWSType returns a status indicating if the message type is supported
 func isSupportedWSType(messageType int) bool {
 	return messageType == websocket.TextMessage ||
@@ -224,6 +440,14 @@ func (j *JSONRPC) handleWs(w http.ResponseWriter, req *http.Request) {
 	// CORS rule - Allow requests from anywhere
 	wsUpgrader.CheckOrigin = func(r *http.Request) bool { return true }
 
+	// the token is checked on the upgrade, and its expiry on each request
+	c, err := j.auth.authenticate(req, clientIP(req, j.config.TrustedProxies))
+	if err != nil {
+		j.writeUnauthorized(w, err)
+
+		return
+	}
+
 	// Upgrade the connection to a WS one
 	ws, err := wsUpgrader.Upgrade(w, req, nil)
 	if err != nil {
@@ -232,17 +456,35 @@ func (j *JSONRPC) handleWs(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	wrapConn := newWsWrapper(ws, j.logger, c, j.config.WSSendQueueSize, j.config.WSDropPolicy)
+
 	// Defer WS closure
-	defer func(ws *websocket.Conn) {
-		err = ws.Close()
-		if err != nil {
-			j.logger.Error(
-				fmt.Sprintf("Unable to gracefully close WS connection, %s", err.Error()),
-			)
-		}
-	}(ws)
+	defer wrapConn.close()
 
-	wrapConn := &wsWrapper{ws: ws, logger: j.logger}
+	// the connection is closed once the token expires
+	if !c.tokenExpiry.IsZero() {
+		expiryTimer := time.AfterFunc(time.Until(c.tokenExpiry), func() {
+			j.logger.Info("Closing WS connection of the expired token")
+
+			wrapConn.closeWith(websocket.ClosePolicyViolation, ErrAuthTokenExpired.Error())
+		})
+
+		defer expiryTimer.Stop()
+	}
+
+	if wrapConn.sendCh != nil {
+		go wrapConn.writeLoop()
+	}
+
+	if j.config.WSMaxMessageSize > 0 {
+		// larger messages fail the read and close the connection
+		ws.SetReadLimit(int64(j.config.WSMaxMessageSize))
+	}
+
+	var limiter *rate.Limiter
+	if j.config.WSMessageRateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(j.config.WSMessageRateLimit), int(j.config.WSMessageRateLimit))
+	}
 
 	j.logger.Info("Websocket connection established")
 	// Run the listen loop
@@ -268,6 +510,17 @@ func (j *JSONRPC) handleWs(w http.ResponseWriter, req *http.Request) {
 			break
 		}
 
+		if limiter != nil && !limiter.Allow() {
+			j.metrics.ErrorsCounterInc()
+
+			_ = wrapConn.WriteMessage(
+				msgType,
+				[]byte(fmt.Sprintf("WS Handle error: %s", ErrWSRateLimited.Error())),
+			)
+
+			continue
+		}
+
 		if isSupportedWSType(msgType) {
 			go func() {
 				resp, handleErr := j.dispatcher.HandleWs(message, wrapConn)
@@ -278,7 +531,7 @@ func (j *JSONRPC) handleWs(w http.ResponseWriter, req *http.Request) {
 						msgType,
 						[]byte(fmt.Sprintf("WS Handle error: %s", handleErr.Error())),
 					)
-				} else {
+				} else if resp != nil {
 					_ = wrapConn.WriteMessage(msgType, resp)
 				}
 			}()
@@ -309,7 +562,25 @@ func (j *JSONRPC) handle(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// writeUnauthorized rejects the request failing the authentication
+func (j *JSONRPC) writeUnauthorized(w http.ResponseWriter, err error) {
+	j.metrics.ErrorsCounterInc()
+
+	resp, _ := NewRPCResponse(nil, "2.0", nil, NewUnauthorizedError(err.Error())).Bytes()
+
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	w.WriteHeader(http.StatusUnauthorized)
+	w.Write(resp)
+}
+
 func (j *JSONRPC) handleJSONRPCRequest(w http.ResponseWriter, req *http.Request) {
+	c, err := j.auth.authenticate(req, clientIP(req, j.config.TrustedProxies))
+	if err != nil {
+		j.writeUnauthorized(w, err)
+
+		return
+	}
+
 	data, err := io.ReadAll(req.Body)
 	if err != nil {
 		j.metrics.ErrorsCounterInc()
@@ -324,7 +595,7 @@ func (j *JSONRPC) handleJSONRPCRequest(w http.ResponseWriter, req *http.Request)
 	startT := time.Now()
 
 	// handle request
-	resp, err := j.dispatcher.Handle(data)
+	resp, err := j.dispatcher.handleClient(c, data)
 
 	j.metrics.ResponseTimeObserve(time.Since(startT).Seconds())
 
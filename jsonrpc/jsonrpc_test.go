@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dogechain-lab/dogechain/helper/tests"
 	"github.com/dogechain-lab/dogechain/versioning"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/hashicorp/go-hclog"
@@ -65,3 +70,201 @@ func Test_handleGetRequest(t *testing.T) {
 		response,
 	)
 }
+
+// newTestWSServer starts a websocket server with the config, returns the client connection
+func newTestWSServer(t *testing.T, config *Config) *websocket.Conn {
+	t.Helper()
+
+	return newTestAuthWSServer(t, config, nil, "")
+}
+
+// newTestAuthWSServer starts a websocket server with the config and the auth secret,
+// returns the client connection upgraded with the token
+func newTestAuthWSServer(t *testing.T, config *Config, secret []byte, token string) *websocket.Conn {
+	t.Helper()
+
+	jsonRPC := &JSONRPC{
+		logger: hclog.NewNullLogger(),
+		config: config,
+		dispatcher: newDispatcher(
+			hclog.NewNullLogger(),
+			NilMetrics(),
+			newMockStore(),
+			100,
+			20,
+			1000,
+			0,
+			[]Namespace{NamespaceWeb3},
+		),
+		auth:    newAuthenticator(secret),
+		metrics: NilMetrics(),
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(jsonRPC.handleWs))
+	t.Cleanup(srv.Close)
+
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), header)
+	if err != nil {
+		t.Fatalf("Unable to dial websocket, %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	return conn
+}
+
+func TestHandleWs_MessageSizeLimit(t *testing.T) {
+	conn := newTestWSServer(t, &Config{
+		WSMaxMessageSize: 64,
+	})
+
+	request := `{"id": 1, "method": "web3_clientVersion", "params": []}`
+
+	// message within the limit is handled
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(request)))
+
+	_, resp, err := conn.ReadMessage()
+	assert.NoError(t, err)
+
+	var res string
+
+	assert.NoError(t, expectJSONResult(resp, &res))
+
+	// larger message closes the connection
+	large := strings.Replace(request, `"params": []`, `"params": ["`+strings.Repeat("a", 64)+`"]`, 1)
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(large)))
+
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig))
+}
+
+func TestHandleWs_MessageRateLimit(t *testing.T) {
+	conn := newTestWSServer(t, &Config{
+		WSMessageRateLimit: 1,
+		WSSendQueueSize:    8,
+		WSDropPolicy:       WSDropPolicyDrop,
+	})
+
+	request := []byte(`{"id": 1, "method": "web3_clientVersion", "params": []}`)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, request))
+	}
+
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	limited := 0
+
+	for i := 0; i < 3; i++ {
+		_, resp, err := conn.ReadMessage()
+		assert.NoError(t, err)
+
+		if strings.Contains(string(resp), ErrWSRateLimited.Error()) {
+			limited++
+		}
+	}
+
+	// the burst allows one message per second
+	assert.GreaterOrEqual(t, limited, 1)
+}
+
+func TestHandleWs_TokenExpiry(t *testing.T) {
+	token, err := NewAuthToken(testAuthSecret, nil, time.Now().Add(2*time.Second))
+	assert.NoError(t, err)
+
+	conn := newTestAuthWSServer(t, &Config{}, testAuthSecret, token)
+
+	request := []byte(`{"id": 1, "method": "web3_clientVersion", "params": []}`)
+
+	// the token is valid yet
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, request))
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	_, resp, err := conn.ReadMessage()
+	assert.NoError(t, err)
+
+	var res string
+
+	assert.NoError(t, expectJSONResult(resp, &res))
+
+	// the connection is closed once the token expires
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation))
+}
+
+func TestWSWrapper_SendQueue(t *testing.T) {
+	newWrapper := func(policy WSDropPolicy) *wsWrapper {
+		conn := newTestWSServer(t, &Config{})
+
+		// the write loop is not started, so that the queue is never drained
+		return newWsWrapper(conn, hclog.NewNullLogger(), client{}, 1, policy)
+	}
+
+	t.Run("full queue drops notifications", func(t *testing.T) {
+		w := newWrapper(WSDropPolicyDrop)
+
+		assert.NoError(t, w.WriteNotification(websocket.TextMessage, []byte("1")))
+		assert.ErrorIs(t, w.WriteNotification(websocket.TextMessage, []byte("2")), ErrWSQueueFull)
+	})
+
+	t.Run("full queue closes the connection", func(t *testing.T) {
+		w := newWrapper(WSDropPolicyDisconnect)
+
+		assert.NoError(t, w.WriteNotification(websocket.TextMessage, []byte("1")))
+		assert.ErrorIs(t, w.WriteNotification(websocket.TextMessage, []byte("2")), websocket.ErrCloseSent)
+
+		// closed connection rejects new messages
+		assert.ErrorIs(t, w.WriteNotification(websocket.TextMessage, []byte("3")), websocket.ErrCloseSent)
+		assert.ErrorIs(t, w.WriteMessage(websocket.TextMessage, []byte("4")), websocket.ErrCloseSent)
+	})
+
+	t.Run("full queue delays responses", func(t *testing.T) {
+		w := newWrapper(WSDropPolicyDisconnect)
+
+		assert.NoError(t, w.WriteNotification(websocket.TextMessage, []byte("1")))
+
+		written := make(chan error, 1)
+
+		go func() {
+			written <- w.WriteMessage(websocket.TextMessage, []byte("2"))
+		}()
+
+		select {
+		case <-written:
+			t.Fatal("response written to the full queue")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		// the response is queued once the queue drains
+		<-w.sendCh
+
+		select {
+		case err := <-written:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("response not written")
+		}
+
+		assert.Equal(t, []byte("2"), (<-w.sendCh).data)
+	})
+
+	t.Run("full queue times out responses", func(t *testing.T) {
+		w := newWrapper(WSDropPolicyDrop)
+		w.writeTimeout = 100 * time.Millisecond
+
+		assert.NoError(t, w.WriteNotification(websocket.TextMessage, []byte("1")))
+		assert.ErrorIs(t, w.WriteMessage(websocket.TextMessage, []byte("2")), ErrWSWriteTimeout)
+
+		// the connection of the slow client is closed
+		assert.ErrorIs(t, w.WriteMessage(websocket.TextMessage, []byte("3")), websocket.ErrCloseSent)
+	})
+}
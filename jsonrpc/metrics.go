@@ -1,6 +1,8 @@
 package jsonrpc
 
 import (
+	"time"
+
 	"github.com/dogechain-lab/dogechain/helper/metrics"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -8,9 +10,11 @@ import (
 type EthAPILabels prometheus.Labels
 
 var (
+	EthAccountsLabel         = EthAPILabels{"method": "eth_accounts"}
 	EthBlockNumberLabel      = EthAPILabels{"method": "eth_blockNumber"}
 	EthCallLabel             = EthAPILabels{"method": "eth_call"}
 	EthChainIDLabel          = EthAPILabels{"method": "eth_chainId"}
+	EthCreateAccessListLabel = EthAPILabels{"method": "eth_createAccessList"}
 	EthEstimateGasLabel      = EthAPILabels{"method": "eth_estimateGas"}
 	EthGasPriceLabel         = EthAPILabels{"method": "eth_gasPrice"}
 	EthGetBalanceLabel       = EthAPILabels{"method": "eth_getBalance"}
@@ -23,6 +27,7 @@ var (
 	EthGetFilterChangesLabel = EthAPILabels{"method": "eth_getFilterChanges"}
 	EthGetFilterLogsLabel    = EthAPILabels{"method": "eth_getFilterLogs"}
 	EthGetLogsLabel          = EthAPILabels{"method": "eth_getLogs"}
+	EthGetProofLabel         = EthAPILabels{"method": "eth_getProof"}
 	EthGetStorageAtLabel     = EthAPILabels{"method": "eth_getStorageAt"}
 
 	EthGetTransactionByHashLabel  = EthAPILabels{"method": "eth_getTransactionByHash"}
@@ -33,6 +38,7 @@ var (
 	EthNewFilterLabel      = EthAPILabels{"method": "eth_newFilter"}
 
 	EthSendRawTransactionLabel = EthAPILabels{"method": "eth_sendRawTransaction"}
+	EthSendTransactionLabel    = EthAPILabels{"method": "eth_sendTransaction"}
 	EthSyncingLabel            = EthAPILabels{"method": "eth_syncing"}
 
 	EthUninstallFilterLabel = EthAPILabels{"method": "eth_uninstallFilter"}
@@ -57,15 +63,42 @@ var (
 type TxPoolAPILabels prometheus.Labels
 
 var (
-	TxPoolContentLabel = TxPoolAPILabels{"method": "txpool_content"}
-	TxPoolInspectLabel = TxPoolAPILabels{"method": "txpool_inspect"}
-	TxPoolStatusLabel  = TxPoolAPILabels{"method": "txpool_status"}
+	TxPoolContentLabel     = TxPoolAPILabels{"method": "txpool_content"}
+	TxPoolContentFromLabel = TxPoolAPILabels{"method": "txpool_contentFrom"}
+	TxPoolInspectLabel     = TxPoolAPILabels{"method": "txpool_inspect"}
+	TxPoolStatusLabel      = TxPoolAPILabels{"method": "txpool_status"}
 )
 
 type DebugAPILabels prometheus.Labels
 
 var (
-	DebugTraceTransactionLabel = DebugAPILabels{"method": "debug_traceTransaction"}
+	DebugTraceTransactionLabel           = DebugAPILabels{"method": "debug_traceTransaction"}
+	DebugGetCodeByHashLabel              = DebugAPILabels{"method": "debug_getCodeByHash"}
+	DebugChaindbPropertyLabel            = DebugAPILabels{"method": "debug_chaindbProperty"}
+	DebugChaindbCompactLabel             = DebugAPILabels{"method": "debug_chaindbCompact"}
+	DebugGetBlockNumbersByStateRootLabel = DebugAPILabels{"method": "debug_getBlockNumbersByStateRoot"}
+)
+
+type DcAPILabels prometheus.Labels
+
+var (
+	DcGetStorageSlotsLabel        = DcAPILabels{"method": "dc_getStorageSlots"}
+	DcGetBalanceHistoryLabel      = DcAPILabels{"method": "dc_getBalanceHistory"}
+	DcGetBlockExecutionStatsLabel = DcAPILabels{"method": "dc_getBlockExecutionStats"}
+	DcGetChainStatsLabel          = DcAPILabels{"method": "dc_getChainStats"}
+	DcListAccountsLabel           = DcAPILabels{"method": "dc_listAccounts"}
+	DcValidateTransactionLabel    = DcAPILabels{"method": "dc_validateTransaction"}
+	DcChainConstantsLabel         = DcAPILabels{"method": "dc_chainConstants"}
+	DcGetSystemTransactionsLabel  = DcAPILabels{"method": "dc_getSystemTransactions"}
+)
+
+type AdminAPILabels prometheus.Labels
+
+var (
+	AdminPeersLabel      = AdminAPILabels{"method": "admin_peers"}
+	AdminNodeInfoLabel   = AdminAPILabels{"method": "admin_nodeInfo"}
+	AdminAddPeerLabel    = AdminAPILabels{"method": "admin_addPeer"}
+	AdminRemovePeerLabel = AdminAPILabels{"method": "admin_removePeer"}
 )
 
 // Metrics represents the jsonrpc metrics
@@ -79,6 +112,16 @@ type Metrics struct {
 	// Requests duration (seconds)
 	responseTime prometheus.Histogram
 
+	// Response cache hits and misses
+	cacheHits   prometheus.Counter
+	cacheMisses prometheus.Counter
+
+	// Dispatched requests, errors, duration (seconds) and requests in flight by method
+	methodRequests *prometheus.CounterVec
+	methodErrors   *prometheus.CounterVec
+	methodSeconds  *prometheus.HistogramVec
+	methodInFlight *prometheus.GaugeVec
+
 	// Eth metrics
 	ethAPI *prometheus.CounterVec
 
@@ -93,6 +136,12 @@ type Metrics struct {
 
 	// Debug metrics
 	debugAPI *prometheus.CounterVec
+
+	// Dc metrics
+	dcAPI *prometheus.CounterVec
+
+	// Admin metrics
+	adminAPI *prometheus.CounterVec
 }
 
 func (m *Metrics) RequestsCounterInc() {
@@ -107,6 +156,44 @@ func (m *Metrics) ResponseTimeObserve(duration float64) {
 	metrics.HistogramObserve(m.responseTime, duration)
 }
 
+func (m *Metrics) CacheHitsCounterInc() {
+	metrics.CounterInc(m.cacheHits)
+}
+
+func (m *Metrics) CacheMissesCounterInc() {
+	metrics.CounterInc(m.cacheMisses)
+}
+
+// MethodCallBegin counts the dispatched request of the method in flight, and returns
+// the beginning of it
+func (m *Metrics) MethodCallBegin(method string) time.Time {
+	if m.methodInFlight != nil {
+		m.methodInFlight.WithLabelValues(method).Inc()
+	}
+
+	return time.Now()
+}
+
+// MethodCallEnd counts the dispatched request of the method done, with its duration
+// since the beginning
+func (m *Metrics) MethodCallEnd(method string, begin time.Time, failed bool) {
+	if m.methodInFlight != nil {
+		m.methodInFlight.WithLabelValues(method).Dec()
+	}
+
+	if m.methodRequests != nil {
+		m.methodRequests.WithLabelValues(method).Inc()
+	}
+
+	if failed && m.methodErrors != nil {
+		m.methodErrors.WithLabelValues(method).Inc()
+	}
+
+	if m.methodSeconds != nil {
+		m.methodSeconds.WithLabelValues(method).Observe(time.Since(begin).Seconds())
+	}
+}
+
 func (m *Metrics) EthAPICounterInc(label EthAPILabels) {
 	if m.ethAPI != nil {
 		m.ethAPI.With((prometheus.Labels)(label)).Inc()
@@ -137,6 +224,18 @@ func (m *Metrics) DebugAPICounterInc(label DebugAPILabels) {
 	}
 }
 
+func (m *Metrics) DcAPICounterInc(label DcAPILabels) {
+	if m.dcAPI != nil {
+		m.dcAPI.With((prometheus.Labels)(label)).Inc()
+	}
+}
+
+func (m *Metrics) AdminAPICounterInc(label AdminAPILabels) {
+	if m.adminAPI != nil {
+		m.adminAPI.With((prometheus.Labels)(label)).Inc()
+	}
+}
+
 // GetPrometheusMetrics return the blockchain metrics instance
 func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics {
 	constLabels := metrics.ParseLables(labelsWithValues...)
@@ -171,6 +270,49 @@ func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics
 			},
 			ConstLabels: constLabels,
 		}),
+		cacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "jsonrpc",
+			Name:        "cache_hits",
+			Help:        "Responses served by the response cache",
+			ConstLabels: constLabels,
+		}),
+		cacheMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "jsonrpc",
+			Name:        "cache_misses",
+			Help:        "Cacheable requests missing in the response cache",
+			ConstLabels: constLabels,
+		}),
+		methodRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "jsonrpc",
+			Name:        "method_requests",
+			Help:        "Dispatched requests number by method",
+			ConstLabels: constLabels,
+		}, []string{"method"}),
+		methodErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "jsonrpc",
+			Name:        "method_errors",
+			Help:        "Dispatched request errors number by method",
+			ConstLabels: constLabels,
+		}, []string{"method"}),
+		methodSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   "jsonrpc",
+			Name:        "method_seconds",
+			Help:        "Dispatched request duration (seconds) by method",
+			Buckets:     prometheus.ExponentialBuckets(0.0005, 2, 14),
+			ConstLabels: constLabels,
+		}, []string{"method"}),
+		methodInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   "jsonrpc",
+			Name:        "method_in_flight",
+			Help:        "Dispatched requests in flight by method",
+			ConstLabels: constLabels,
+		}, []string{"method"}),
 		ethAPI: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace:   namespace,
 			Subsystem:   "jsonrpc",
@@ -206,17 +348,39 @@ func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics
 			Help:        "debug api requests",
 			ConstLabels: constLabels,
 		}, []string{"method"}),
+		dcAPI: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "jsonrpc",
+			Name:        "dc_api_requests",
+			Help:        "dc api requests",
+			ConstLabels: constLabels,
+		}, []string{"method"}),
+		adminAPI: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "jsonrpc",
+			Name:        "admin_api_requests",
+			Help:        "admin api requests",
+			ConstLabels: constLabels,
+		}, []string{"method"}),
 	}
 
 	prometheus.MustRegister(
 		m.requests,
 		m.errors,
 		m.responseTime,
+		m.cacheHits,
+		m.cacheMisses,
+		m.methodRequests,
+		m.methodErrors,
+		m.methodSeconds,
+		m.methodInFlight,
 		m.ethAPI,
 		m.netAPI,
 		m.web3API,
 		m.txPoolAPI,
 		m.debugAPI,
+		m.dcAPI,
+		m.adminAPI,
 	)
 
 	return m
@@ -6,6 +6,7 @@ import (
 
 	"github.com/dogechain-lab/dogechain/blockchain"
 	"github.com/dogechain-lab/dogechain/state"
+	"github.com/dogechain-lab/dogechain/txpool"
 	"github.com/dogechain-lab/dogechain/types"
 )
 
@@ -44,11 +45,23 @@ type mockEvent struct {
 	NewChain []*mockHeader
 }
 
+type mockPendingTxsSubscription struct {
+	txsCh chan []*types.Transaction
+}
+
+func (m *mockPendingTxsSubscription) GetTxs() <-chan []*types.Transaction {
+	return m.txsCh
+}
+
+func (m *mockPendingTxsSubscription) Unsubscribe() {}
+
 type mockStore struct {
 	JSONRPCStore
 
 	header       *types.Header
 	subscription *blockchain.MockSubscription
+	eventFilter  chan *blockchain.EventFilter // the filters of the event subscriptions
+	pendingSub   *mockPendingTxsSubscription
 	receiptsLock sync.Mutex
 	receipts     map[types.Hash][]*types.Receipt
 	accounts     map[types.Address]*state.Account
@@ -58,6 +71,8 @@ func newMockStore() *mockStore {
 	return &mockStore{
 		header:       &types.Header{Number: 0},
 		subscription: blockchain.NewMockSubscription(),
+		eventFilter:  make(chan *blockchain.EventFilter, 1),
+		pendingSub:   &mockPendingTxsSubscription{txsCh: make(chan []*types.Transaction)},
 		accounts:     map[types.Address]*state.Account{},
 	}
 }
@@ -88,6 +103,10 @@ func (m *mockStore) emitEvent(evnt *mockEvent) {
 	m.subscription.Push(bEvnt)
 }
 
+func (m *mockStore) emitPendingTxs(txs []*types.Transaction) {
+	m.pendingSub.txsCh <- txs
+}
+
 func (m *mockStore) GetAccount(root types.Hash, addr types.Address) (*state.Account, error) {
 	if acc, ok := m.accounts[addr]; ok {
 		return acc, nil
@@ -117,6 +136,19 @@ func (m *mockStore) SubscribeEvents() blockchain.Subscription {
 	return m.subscription
 }
 
+func (m *mockStore) SubscribeEventsWithFilter(filter *blockchain.EventFilter) blockchain.Subscription {
+	select {
+	case m.eventFilter <- filter:
+	default:
+	}
+
+	return m.subscription
+}
+
+func (m *mockStore) SubscribePendingTxs() txpool.PendingTxsSubscription {
+	return m.pendingSub
+}
+
 func (m *mockStore) GetBlockByHash(hash types.Hash, full bool) (*types.Block, bool) {
 	return nil, false
 }
@@ -125,6 +157,18 @@ func (m *mockStore) GetBlockByNumber(num uint64, full bool) (*types.Block, bool)
 	return nil, false
 }
 
+func (m *mockStore) GetHeadersInRange(from, to uint64) []*types.Header {
+	return nil
+}
+
+func (m *mockStore) FilterLogBlocks(
+	addresses []types.Address,
+	topics [][]types.Hash,
+	from, to uint64,
+) ([]uint64, bool) {
+	return nil, false
+}
+
 func (m *mockStore) GetTxs(inclQueued bool) (
 	map[types.Address][]*types.Transaction,
 	map[types.Address][]*types.Transaction,
@@ -132,6 +176,17 @@ func (m *mockStore) GetTxs(inclQueued bool) (
 	return nil, nil
 }
 
+func (m *mockStore) GetTxCounts() (uint64, uint64) {
+	return 0, 0
+}
+
+func (m *mockStore) GetAccountTxs(addr types.Address) (
+	[]*types.Transaction,
+	[]*types.Transaction,
+) {
+	return nil, nil
+}
+
 func (m *mockStore) GetCapacity() (uint64, uint64) {
 	return 0, 0
 }
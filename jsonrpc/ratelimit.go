@@ -0,0 +1,280 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+var (
+	ErrInvalidRateLimit    = errors.New("invalid rate limit, expected group=rate[:burst]")
+	ErrInvalidTrustedProxy = errors.New("invalid trusted proxy, expected an ip or a cidr")
+)
+
+const (
+	// rateLimiterIdleTimeout is the idle time after which the limiter of a client is released
+	rateLimiterIdleTimeout = 3 * time.Minute
+	// rateLimiterSweepInterval is the interval of releasing the idle limiters
+	rateLimiterSweepInterval = time.Minute
+)
+
+// RateLimit is the requests per second allowed, with the burst
+type RateLimit struct {
+	Rate  uint64 // requests per second, 0 for unlimited
+	Burst uint64 // max requests at once, 0 for the same as the rate
+}
+
+func (l RateLimit) newLimiter() *rate.Limiter {
+	burst := l.Burst
+	if burst == 0 {
+		burst = l.Rate
+	}
+
+	return rate.NewLimiter(rate.Limit(l.Rate), int(burst))
+}
+
+// RateLimitConfig limits the requests of every client IP, in total and per method group
+type RateLimitConfig struct {
+	// PerIP limits all the requests of a client IP
+	PerIP RateLimit
+	// Groups limit the requests of a client IP to the methods of the group, keyed by
+	// the method name (eth_call) or the namespace (debug). The method name takes
+	// precedence over its namespace.
+	Groups map[string]RateLimit
+}
+
+// ParseRateLimits parses the rate limits of the method groups, in the form of
+// group=rate[:burst] separated by comma, such as "eth_call=20:40,debug=1"
+func ParseRateLimits(raw string) (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit)
+
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+
+		group, value, ok := strings.Cut(item, "=")
+		if !ok || group == "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRateLimit, item)
+		}
+
+		rawRate, rawBurst, hasBurst := strings.Cut(value, ":")
+
+		var (
+			limit RateLimit
+			err   error
+		)
+
+		if limit.Rate, err = strconv.ParseUint(rawRate, 10, 64); err != nil || limit.Rate == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRateLimit, item)
+		}
+
+		if hasBurst {
+			if limit.Burst, err = strconv.ParseUint(rawBurst, 10, 64); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidRateLimit, item)
+			}
+		}
+
+		limits[group] = limit
+	}
+
+	return limits, nil
+}
+
+type rateLimiterKey struct {
+	group string // empty for all the requests
+	ip    string
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiter holds the token buckets of the clients, the ones idle for a while are
+// released
+type rateLimiter struct {
+	config *RateLimitConfig
+
+	lock      sync.Mutex
+	limiters  map[rateLimiterKey]*clientLimiter
+	lastSweep time.Time
+}
+
+// newRateLimiter returns the rate limiter of the config, nil if no limit is set
+func newRateLimiter(config *RateLimitConfig) *rateLimiter {
+	if config == nil || (config.PerIP.Rate == 0 && len(config.Groups) == 0) {
+		return nil
+	}
+
+	return &rateLimiter{
+		config:    config,
+		limiters:  make(map[rateLimiterKey]*clientLimiter),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow returns true if the request of the method from the client IP is within the limits
+func (r *rateLimiter) allow(ip, method string) bool {
+	if r == nil {
+		return true
+	}
+
+	now := time.Now()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.sweep(now)
+
+	if r.config.PerIP.Rate > 0 && !r.limiter(rateLimiterKey{ip: ip}, r.config.PerIP, now).AllowN(now, 1) {
+		return false
+	}
+
+	group, limit, ok := r.groupLimit(method)
+	if !ok {
+		return true
+	}
+
+	return r.limiter(rateLimiterKey{group: group, ip: ip}, limit, now).AllowN(now, 1)
+}
+
+// groupLimit returns the group limiting the method, by the method name first
+func (r *rateLimiter) groupLimit(method string) (string, RateLimit, bool) {
+	if limit, ok := r.config.Groups[method]; ok {
+		return method, limit, true
+	}
+
+	if namespace, _, found := strings.Cut(method, "_"); found {
+		if limit, ok := r.config.Groups[namespace]; ok {
+			return namespace, limit, true
+		}
+	}
+
+	return "", RateLimit{}, false
+}
+
+// limiter returns the limiter of the key, created if not exists
+//
+// Not thread safe
+func (r *rateLimiter) limiter(key rateLimiterKey, limit RateLimit, now time.Time) *rate.Limiter {
+	l, ok := r.limiters[key]
+	if !ok {
+		l = &clientLimiter{limiter: limit.newLimiter()}
+		r.limiters[key] = l
+	}
+
+	l.lastSeen = now
+
+	return l.limiter
+}
+
+// sweep releases the limiters idle for a while
+//
+// Not thread safe
+func (r *rateLimiter) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < rateLimiterSweepInterval {
+		return
+	}
+
+	r.lastSweep = now
+
+	for key, l := range r.limiters {
+		if now.Sub(l.lastSeen) > rateLimiterIdleTimeout {
+			delete(r.limiters, key)
+		}
+	}
+}
+
+// ParseTrustedProxies parses the ips or cidrs of the trusted proxies separated by
+// comma, such as "10.0.0.0/8,192.168.1.1"
+func ParseTrustedProxies(raw string) ([]*net.IPNet, error) {
+	proxies := []*net.IPNet{}
+
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+
+		if ip := net.ParseIP(item); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+
+			continue
+		}
+
+		_, ipnet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTrustedProxy, item)
+		}
+
+		proxies = append(proxies, ipnet)
+	}
+
+	return proxies, nil
+}
+
+// isTrustedProxy returns whether the ip is one of the trusted proxies
+func isTrustedProxy(ip net.IP, proxies []*net.IPNet) bool {
+	for _, proxy := range proxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clientIP returns the IP of the http client. The X-Forwarded-For and X-Real-IP
+// headers are honoured only if the request comes from a trusted proxy, and the
+// forwarded ips are taken from the right, skipping the trusted proxies, since the
+// left ones are set by the client.
+func clientIP(req *http.Request, proxies []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	if remote := net.ParseIP(host); remote == nil || !isTrustedProxy(remote, proxies) {
+		return host
+	}
+
+	var forwarded []string
+
+	for _, header := range req.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(header, ",")...)
+	}
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			break
+		}
+
+		if host = ip.String(); !isTrustedProxy(ip, proxies) {
+			return host
+		}
+	}
+
+	if len(forwarded) > 0 {
+		// all the forwarded ips are trusted proxies, or the invalid one stops the walk
+		return host
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(req.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+
+	return host
+}
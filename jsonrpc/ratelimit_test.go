@@ -0,0 +1,186 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := ParseRateLimits(" eth_call=20:40, debug=1,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]RateLimit{
+		"eth_call": {Rate: 20, Burst: 40},
+		"debug":    {Rate: 1},
+	}, limits)
+
+	limits, err = ParseRateLimits("")
+	assert.NoError(t, err)
+	assert.Empty(t, limits)
+
+	for _, raw := range []string{"eth_call", "=1", "eth_call=0", "eth_call=a", "eth_call=1:b"} {
+		_, err := ParseRateLimits(raw)
+		assert.ErrorIs(t, err, ErrInvalidRateLimit, raw)
+	}
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	assert.Nil(t, newRateLimiter(nil))
+	assert.Nil(t, newRateLimiter(&RateLimitConfig{}))
+
+	// the nil limiter allows all
+	var unlimited *rateLimiter
+	assert.True(t, unlimited.allow("1.1.1.1", "eth_call"))
+
+	limiter := newRateLimiter(&RateLimitConfig{
+		PerIP: RateLimit{Rate: 1, Burst: 3},
+		Groups: map[string]RateLimit{
+			"eth_call": {Rate: 1},
+			"debug":    {Rate: 1, Burst: 2},
+		},
+	})
+
+	// the method limit of the client
+	assert.True(t, limiter.allow("1.1.1.1", "eth_call"))
+	assert.False(t, limiter.allow("1.1.1.1", "eth_call"))
+
+	// the namespace limit of the client
+	assert.True(t, limiter.allow("1.1.1.1", "debug_traceTransaction"))
+	assert.False(t, limiter.allow("1.1.1.1", "debug_traceCall"))
+
+	// the total limit of the client is exhausted
+	assert.False(t, limiter.allow("1.1.1.1", "eth_blockNumber"))
+
+	// the clients are limited on their own
+	assert.True(t, limiter.allow("2.2.2.2", "eth_call"))
+	assert.True(t, limiter.allow("2.2.2.2", "eth_blockNumber"))
+
+	// the idle limiters are released
+	limiter.sweep(time.Now().Add(rateLimiterIdleTimeout + rateLimiterSweepInterval + time.Second))
+	assert.Empty(t, limiter.limiters)
+}
+
+func TestDispatcher_RateLimit(t *testing.T) {
+	dispatcher := newDispatcher(hclog.NewNullLogger(), NilMetrics(), newMockStore(), 0, 0, 0, 0, []Namespace{
+		NamespaceWeb3,
+	})
+	dispatcher.rateLimiter = newRateLimiter(&RateLimitConfig{
+		Groups: map[string]RateLimit{"web3_clientVersion": {Rate: 1}},
+	})
+
+	resp, err := dispatcher.handleClient(client{ip: "1.1.1.1"}, []byte(`[
+		{"id":1,"jsonrpc":"2.0","method":"web3_clientVersion","params":[]},
+		{"id":2,"jsonrpc":"2.0","method":"web3_clientVersion","params":[]},
+		{"id":3,"jsonrpc":"2.0","method":"web3_sha3","params":["0x00"]}]`))
+	assert.NoError(t, err)
+
+	var responses []*ErrorResponse
+
+	assert.NoError(t, json.Unmarshal(resp, &responses))
+	assert.Len(t, responses, 3)
+	assert.Nil(t, responses[0].Error)
+	assert.Equal(t, &ObjectError{Code: -32005, Message: "limit exceeded"}, responses[1].Error)
+	assert.Nil(t, responses[2].Error)
+
+	// another client is not limited
+	resp, err = dispatcher.handleClient(client{ip: "2.2.2.2"}, []byte(`{"id":1,"method":"web3_clientVersion","params":[]}`))
+	assert.NoError(t, err)
+
+	var response ErrorResponse
+
+	assert.NoError(t, json.Unmarshal(resp, &response))
+	assert.Nil(t, response.Error)
+}
+
+func TestClientIP(t *testing.T) {
+	assert.Equal(t, "1.2.3.4", clientIP(&http.Request{RemoteAddr: "1.2.3.4:5678"}, nil))
+	assert.Equal(t, "::1", clientIP(&http.Request{RemoteAddr: "[::1]:5678"}, nil))
+	assert.Equal(t, "unknown", clientIP(&http.Request{RemoteAddr: "unknown"}, nil))
+
+	proxies, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.1")
+	assert.NoError(t, err)
+
+	newRequest := func(remoteAddr string, headers ...string) *http.Request {
+		req := &http.Request{RemoteAddr: remoteAddr, Header: http.Header{}}
+		for i := 0; i < len(headers); i += 2 {
+			req.Header.Add(headers[i], headers[i+1])
+		}
+
+		return req
+	}
+
+	testTable := []struct {
+		name string
+		req  *http.Request
+		ip   string
+	}{
+		{
+			"untrusted remote",
+			newRequest("1.2.3.4:5678", "X-Forwarded-For", "5.6.7.8", "X-Real-IP", "5.6.7.8"),
+			"1.2.3.4",
+		},
+		{
+			"trusted proxy without headers",
+			newRequest("192.168.1.1:5678"),
+			"192.168.1.1",
+		},
+		{
+			"forwarded by a trusted proxy",
+			newRequest("10.1.1.1:5678", "X-Forwarded-For", "5.6.7.8"),
+			"5.6.7.8",
+		},
+		{
+			"the spoofed forwarded ips are skipped",
+			newRequest("10.1.1.1:5678", "X-Forwarded-For", "9.9.9.9, 5.6.7.8, 10.2.2.2"),
+			"5.6.7.8",
+		},
+		{
+			"multiple forwarded headers",
+			newRequest("10.1.1.1:5678", "X-Forwarded-For", "9.9.9.9", "X-Forwarded-For", "5.6.7.8"),
+			"5.6.7.8",
+		},
+		{
+			"all forwarded by the trusted proxies",
+			newRequest("10.1.1.1:5678", "X-Forwarded-For", "10.2.2.2, 192.168.1.1"),
+			"10.2.2.2",
+		},
+		{
+			"the invalid forwarded ip stops the walk",
+			newRequest("10.1.1.1:5678", "X-Forwarded-For", "5.6.7.8, unknown, 10.2.2.2"),
+			"10.2.2.2",
+		},
+		{
+			"real ip of a trusted proxy",
+			newRequest("192.168.1.1:5678", "X-Real-IP", "5.6.7.8"),
+			"5.6.7.8",
+		},
+	}
+
+	for _, tt := range testTable {
+		assert.Equal(t, tt.ip, clientIP(tt.req, proxies), tt.name)
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies("")
+	assert.NoError(t, err)
+	assert.Empty(t, proxies)
+
+	proxies, err = ParseTrustedProxies("10.0.0.0/8,::1,1.2.3.4")
+	assert.NoError(t, err)
+	assert.Len(t, proxies, 3)
+	assert.Equal(t, "1.2.3.4/32", proxies[2].String())
+	assert.True(t, isTrustedProxy(net.ParseIP("::1"), proxies))
+	assert.False(t, isTrustedProxy(net.ParseIP("1.2.3.5"), proxies))
+
+	_, err = ParseTrustedProxies("10.0.0.0/33")
+	assert.ErrorIs(t, err, ErrInvalidTrustedProxy)
+
+	_, err = ParseTrustedProxies("proxy.local")
+	assert.ErrorIs(t, err, ErrInvalidTrustedProxy)
+}
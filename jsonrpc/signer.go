@@ -0,0 +1,233 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	web3rpc "github.com/umbracle/go-web3/jsonrpc"
+)
+
+var (
+	ErrSignerRejected = errors.New("transaction rejected by the signer rules")
+	ErrSignerTampered = errors.New("transaction signed by the signer differs from the one sent")
+)
+
+// Signer signs the transactions sent by eth_sendTransaction, so that the node could
+// be a transaction gateway without storing any keys
+type Signer interface {
+	// Accounts returns the accounts the signer signs for
+	Accounts() ([]types.Address, error)
+
+	// SignTransaction returns the signed transaction
+	SignTransaction(tx *types.Transaction) (*types.Transaction, error)
+}
+
+// txSender recovers the sender of the signed transactions
+type txSender interface {
+	Sender(tx *types.Transaction) (types.Address, error)
+}
+
+// SignerRules are the approval rules of the node on the transactions sent to the
+// signer, on top of the rules of the signer itself
+type SignerRules struct {
+	Senders     []types.Address `json:"senders"`     // the senders allowed, any if empty
+	Recipients  []types.Address `json:"recipients"`  // the recipients allowed, any if empty
+	AllowCreate bool            `json:"allowCreate"` // whether the contract creations are allowed
+	MaxValue    *big.Int        `json:"maxValue"`    // the max value transferred, unlimited if nil
+	MaxGas      uint64          `json:"maxGas"`      // the max gas, unlimited if zero
+	MaxGasPrice *big.Int        `json:"maxGasPrice"` // the max gas price, unlimited if nil
+}
+
+// LoadSignerRules reads the signer rules of the json file
+func LoadSignerRules(path string) (*SignerRules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules := &SignerRules{}
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(rules); err != nil {
+		return nil, fmt.Errorf("invalid signer rules %s: %w", path, err)
+	}
+
+	return rules, nil
+}
+
+// allowSender returns whether the transactions of the sender are allowed
+func (r *SignerRules) allowSender(addr types.Address) bool {
+	return len(r.Senders) == 0 || containsAddress(r.Senders, addr)
+}
+
+// Check returns ErrSignerRejected with the reason if the transaction is not allowed
+func (r *SignerRules) Check(tx *types.Transaction) error {
+	switch {
+	case !r.allowSender(tx.From):
+		return fmt.Errorf("%w: sender %s not allowed", ErrSignerRejected, tx.From)
+	case tx.To == nil && !r.AllowCreate:
+		return fmt.Errorf("%w: contract creation not allowed", ErrSignerRejected)
+	case tx.To != nil && len(r.Recipients) > 0 && !containsAddress(r.Recipients, *tx.To):
+		return fmt.Errorf("%w: recipient %s not allowed", ErrSignerRejected, tx.To)
+	case r.MaxValue != nil && tx.Value.Cmp(r.MaxValue) > 0:
+		return fmt.Errorf("%w: value exceeds %s", ErrSignerRejected, r.MaxValue)
+	case r.MaxGas > 0 && tx.Gas > r.MaxGas:
+		return fmt.Errorf("%w: gas exceeds %d", ErrSignerRejected, r.MaxGas)
+	case r.MaxGasPrice != nil && tx.GasPrice.Cmp(r.MaxGasPrice) > 0:
+		return fmt.Errorf("%w: gas price exceeds %s", ErrSignerRejected, r.MaxGasPrice)
+	}
+
+	return nil
+}
+
+func containsAddress(addrs []types.Address, addr types.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+
+	return false
+}
+
+// signTxArgs are the transaction arguments of account_signTransaction
+type signTxArgs struct {
+	From     types.Address  `json:"from"`
+	To       *types.Address `json:"to,omitempty"`
+	Gas      string         `json:"gas"`
+	GasPrice string         `json:"gasPrice"`
+	Value    string         `json:"value"`
+	Nonce    string         `json:"nonce"`
+	Data     string         `json:"data"`
+}
+
+// signTxResult is the result of account_signTransaction
+type signTxResult struct {
+	Raw string `json:"raw"`
+}
+
+// externalSigner is the signer out of the node, speaking the account namespace of
+// the clef protocol over http or ipc
+type externalSigner struct {
+	logger hclog.Logger
+	client *web3rpc.Client
+	rules  *SignerRules
+	sender txSender
+}
+
+// NewExternalSigner creates the signer of the http url or the ipc path, the rules
+// are checked before the transactions are sent to it. The senders of the signed
+// transactions are recovered by the sender.
+func NewExternalSigner(
+	logger hclog.Logger,
+	addr string,
+	rules *SignerRules,
+	sender txSender,
+) (Signer, error) {
+	client, err := web3rpc.NewClient(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if rules == nil {
+		rules = &SignerRules{}
+	}
+
+	return &externalSigner{
+		logger: logger.Named("signer"),
+		client: client,
+		rules:  rules,
+		sender: sender,
+	}, nil
+}
+
+// Accounts returns the accounts of the signer allowed by the rules
+func (s *externalSigner) Accounts() ([]types.Address, error) {
+	var accounts []types.Address
+
+	if err := s.client.Call("account_list", &accounts); err != nil {
+		return nil, fmt.Errorf("failed to list the signer accounts: %w", err)
+	}
+
+	allowed := make([]types.Address, 0, len(accounts))
+
+	for _, addr := range accounts {
+		if s.rules.allowSender(addr) {
+			allowed = append(allowed, addr)
+		}
+	}
+
+	return allowed, nil
+}
+
+// SignTransaction checks the transaction against the rules, and has it signed by the
+// signer, which might ask for its own approval. The signed transaction is verified
+// to be the one sent.
+func (s *externalSigner) SignTransaction(tx *types.Transaction) (*types.Transaction, error) {
+	if err := s.rules.Check(tx); err != nil {
+		s.logger.Info("transaction rejected", "from", tx.From, "nonce", tx.Nonce, "err", err)
+
+		return nil, err
+	}
+
+	args := &signTxArgs{
+		From:     tx.From,
+		To:       tx.To,
+		Gas:      hex.EncodeUint64(tx.Gas),
+		GasPrice: hex.EncodeBig(tx.GasPrice),
+		Value:    hex.EncodeBig(tx.Value),
+		Nonce:    hex.EncodeUint64(tx.Nonce),
+		Data:     hex.EncodeToHex(tx.Input),
+	}
+
+	var result signTxResult
+
+	if err := s.client.Call("account_signTransaction", &result, args); err != nil {
+		return nil, fmt.Errorf("failed to sign the transaction: %w", err)
+	}
+
+	raw, err := hex.DecodeHex(result.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signed transaction: %w", err)
+	}
+
+	signed := &types.Transaction{}
+	if err := signed.UnmarshalRLP(raw); err != nil {
+		return nil, fmt.Errorf("invalid signed transaction: %w", err)
+	}
+
+	if signed.From, err = s.sender.Sender(signed); err != nil {
+		return nil, fmt.Errorf("invalid signed transaction: %w", err)
+	}
+
+	if !sameTransaction(tx, signed) {
+		s.logger.Warn("signed transaction differs", "from", tx.From, "nonce", tx.Nonce, "hash", signed.Hash())
+
+		return nil, ErrSignerTampered
+	}
+
+	return signed, nil
+}
+
+// sameTransaction returns whether the signed transaction is the one sent
+func sameTransaction(tx, signed *types.Transaction) bool {
+	sameTo := (tx.To == nil && signed.To == nil) ||
+		(tx.To != nil && signed.To != nil && *tx.To == *signed.To)
+
+	return sameTo &&
+		tx.From == signed.From &&
+		tx.Nonce == signed.Nonce &&
+		tx.Gas == signed.Gas &&
+		tx.GasPrice.Cmp(signed.GasPrice) == 0 &&
+		tx.Value.Cmp(signed.Value) == 0 &&
+		string(tx.Input) == string(signed.Input)
+}
@@ -0,0 +1,269 @@
+package jsonrpc
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockClef is an external signer signing by the key, the tamper func alters the
+// transactions before they are signed
+type mockClef struct {
+	key    *ecdsa.PrivateKey
+	signer crypto.TxSigner
+	tamper func(tx *types.Transaction)
+	calls  int
+}
+
+func (m *mockClef) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     interface{}       `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	m.calls++
+
+	var result interface{}
+
+	switch req.Method {
+	case "account_list":
+		result = []types.Address{crypto.PubKeyToAddress(&m.key.PublicKey), types.StringToAddress("1")}
+	case "account_signTransaction":
+		var args signTxArgs
+		if err := json.Unmarshal(req.Params[0], &args); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		tx := &types.Transaction{To: args.To, Input: hex.MustDecodeHex(args.Data)}
+		tx.Gas, _ = types.ParseUint64orHex(&args.Gas)
+		tx.GasPrice, _ = types.ParseUint256orHex(&args.GasPrice)
+		tx.Value, _ = types.ParseUint256orHex(&args.Value)
+		tx.Nonce, _ = types.ParseUint64orHex(&args.Nonce)
+
+		if m.tamper != nil {
+			m.tamper(tx)
+		}
+
+		signed, _ := m.signer.SignTx(tx, m.key)
+		result = &signTxResult{Raw: hex.EncodeToHex(signed.MarshalRLP())}
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req.ID,
+		"result":  result,
+	})
+}
+
+func newTestExternalSigner(t *testing.T, rules *SignerRules) (Signer, *mockClef, types.Address) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	clef := &mockClef{key: key, signer: crypto.NewEIP155Signer(100)}
+
+	srv := httptest.NewServer(clef)
+	t.Cleanup(srv.Close)
+
+	signer, err := NewExternalSigner(hclog.NewNullLogger(), srv.URL, rules, clef.signer)
+	assert.NoError(t, err)
+
+	return signer, clef, crypto.PubKeyToAddress(&key.PublicKey)
+}
+
+func TestSignerRules_Check(t *testing.T) {
+	rules := &SignerRules{
+		Senders:     []types.Address{addr0},
+		Recipients:  []types.Address{addr1},
+		MaxValue:    big.NewInt(100),
+		MaxGas:      21000,
+		MaxGasPrice: big.NewInt(10),
+	}
+
+	newTx := func(f func(tx *types.Transaction)) *types.Transaction {
+		tx := &types.Transaction{
+			From:     addr0,
+			To:       argAddrPtr(addr1),
+			Gas:      21000,
+			GasPrice: big.NewInt(10),
+			Value:    big.NewInt(100),
+		}
+		f(tx)
+
+		return tx
+	}
+
+	cases := []struct {
+		name    string
+		tx      *types.Transaction
+		allowed bool
+	}{
+		{"allowed", newTx(func(tx *types.Transaction) {}), true},
+		{"sender", newTx(func(tx *types.Transaction) { tx.From = addr1 }), false},
+		{"recipient", newTx(func(tx *types.Transaction) { tx.To = argAddrPtr(addr2) }), false},
+		{"creation", newTx(func(tx *types.Transaction) { tx.To = nil }), false},
+		{"value", newTx(func(tx *types.Transaction) { tx.Value = big.NewInt(101) }), false},
+		{"gas", newTx(func(tx *types.Transaction) { tx.Gas = 21001 }), false},
+		{"gas price", newTx(func(tx *types.Transaction) { tx.GasPrice = big.NewInt(11) }), false},
+	}
+
+	for _, c := range cases {
+		err := rules.Check(c.tx)
+		if c.allowed {
+			assert.NoError(t, err, c.name)
+		} else {
+			assert.ErrorIs(t, err, ErrSignerRejected, c.name)
+		}
+	}
+
+	// no rules allow any
+	assert.NoError(t, (&SignerRules{AllowCreate: true}).Check(newTx(func(tx *types.Transaction) {
+		tx.From, tx.To, tx.Value = addr2, nil, big.NewInt(1000)
+	})))
+}
+
+func TestLoadSignerRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"senders":["`+addr0.String()+`"],"maxValue":1000}`), 0600))
+
+	rules, err := LoadSignerRules(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Address{addr0}, rules.Senders)
+	assert.Equal(t, big.NewInt(1000), rules.MaxValue)
+
+	// the unknown rules are not ignored
+	assert.NoError(t, os.WriteFile(path, []byte(`{"maxValues":1000}`), 0600))
+
+	_, err = LoadSignerRules(path)
+	assert.Error(t, err)
+}
+
+func TestExternalSigner(t *testing.T) {
+	signer, _, _ := newTestExternalSigner(t, &SignerRules{
+		Senders: []types.Address{addr0},
+	})
+
+	// the senders not allowed are not listed
+	accounts, err := signer.Accounts()
+	assert.NoError(t, err)
+	assert.Empty(t, accounts)
+
+	signer, clef, from := newTestExternalSigner(t, nil)
+
+	accounts, err = signer.Accounts()
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Address{from, types.StringToAddress("1")}, accounts)
+
+	tx := &types.Transaction{
+		From:     from,
+		To:       argAddrPtr(addr1),
+		Nonce:    3,
+		Gas:      21000,
+		GasPrice: big.NewInt(10),
+		Value:    big.NewInt(1),
+		Input:    []byte{},
+	}
+
+	signed, err := signer.SignTransaction(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, from, signed.From)
+	assert.Equal(t, tx.Nonce, signed.Nonce)
+	assert.NotNil(t, signed.R)
+
+	// the signer altering the transaction
+	clef.tamper = func(tx *types.Transaction) { tx.Value = big.NewInt(1000) }
+
+	_, err = signer.SignTransaction(tx)
+	assert.ErrorIs(t, err, ErrSignerTampered)
+}
+
+func TestExternalSigner_Rejected(t *testing.T) {
+	signer, clef, from := newTestExternalSigner(t, &SignerRules{MaxValue: big.NewInt(10)})
+
+	_, err := signer.SignTransaction(&types.Transaction{
+		From:     from,
+		To:       argAddrPtr(addr1),
+		GasPrice: big.NewInt(10),
+		Value:    big.NewInt(11),
+	})
+	assert.ErrorIs(t, err, ErrSignerRejected)
+
+	// rejected before sent to the signer
+	assert.Zero(t, clef.calls)
+}
+
+type mockSigner struct {
+	signed *types.Transaction
+}
+
+func (m *mockSigner) Accounts() ([]types.Address, error) {
+	return []types.Address{addr0}, nil
+}
+
+func (m *mockSigner) SignTransaction(tx *types.Transaction) (*types.Transaction, error) {
+	m.signed = tx
+
+	return tx, nil
+}
+
+func TestEth_SendTransaction(t *testing.T) {
+	store := &mockStoreTxn{}
+	store.AddAccount(addr0)
+	eth := newTestEthEndpoint(store)
+
+	arg := &txnArgs{
+		From:     argAddrPtr(addr0),
+		To:       argAddrPtr(addr1),
+		Gas:      argUintPtr(21000),
+		GasPrice: argBytesPtr([]byte{1}),
+	}
+
+	// no signer enabled
+	_, err := eth.SendTransaction(arg)
+	assert.ErrorIs(t, err, ErrSignerNotEnabled)
+
+	accounts, err := eth.Accounts()
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Address{}, accounts)
+
+	signer := &mockSigner{}
+	eth.signer = signer
+
+	accounts, err = eth.Accounts()
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Address{addr0}, accounts)
+
+	hash, err := eth.SendTransaction(arg)
+	assert.NoError(t, err)
+	assert.Equal(t, store.txn.Hash().String(), hash)
+
+	// the pending nonce of the pool
+	assert.Equal(t, uint64(1), signer.signed.Nonce)
+	assert.Equal(t, uint64(21000), signer.signed.Gas)
+
+	// the sender is required
+	_, err = eth.SendTransaction(&txnArgs{To: argAddrPtr(addr1)})
+	assert.Error(t, err)
+}
@@ -12,6 +12,12 @@ type txPoolStore interface {
 	// GetTxs gets tx pool transactions currently pending for inclusion and currently queued for validation
 	GetTxs(inclQueued bool) (map[types.Address][]*types.Transaction, map[types.Address][]*types.Transaction)
 
+	// GetTxCounts returns the number of the pending and the queued transactions
+	GetTxCounts() (uint64, uint64)
+
+	// GetAccountTxs gets the pending and the queued transactions of the account
+	GetAccountTxs(addr types.Address) ([]*types.Transaction, []*types.Transaction)
+
 	// GetCapacity returns the current and max capacity of the pool in slots
 	GetCapacity() (uint64, uint64)
 
@@ -31,6 +37,11 @@ type ContentResponse struct {
 	Queued  map[types.Address]map[uint64]*txpoolTransaction `json:"queued"`
 }
 
+type ContentFromResponse struct {
+	Pending map[uint64]*txpoolTransaction `json:"pending"`
+	Queued  map[uint64]*txpoolTransaction `json:"queued"`
+}
+
 type InspectResponse struct {
 	Pending         map[string]map[string]string `json:"pending"`
 	Queued          map[string]map[string]string `json:"queued"`
@@ -114,6 +125,29 @@ func (t *TxPool) Content() (interface{}, error) {
 	return resp, nil
 }
 
+// Create response for txpool_contentFrom request, the content of the account.
+// See https://geth.ethereum.org/docs/rpc/ns-txpool#txpool_contentfrom.
+func (t *TxPool) ContentFrom(address types.Address) (interface{}, error) {
+	t.metrics.TxPoolAPICounterInc(TxPoolContentFromLabel)
+
+	pendingTxs, queuedTxs := t.store.GetAccountTxs(address)
+
+	resp := ContentFromResponse{
+		Pending: make(map[uint64]*txpoolTransaction, len(pendingTxs)),
+		Queued:  make(map[uint64]*txpoolTransaction, len(queuedTxs)),
+	}
+
+	for _, tx := range pendingTxs {
+		resp.Pending[tx.Nonce] = toTxPoolTransaction(tx)
+	}
+
+	for _, tx := range queuedTxs {
+		resp.Queued[tx.Nonce] = toTxPoolTransaction(tx)
+	}
+
+	return resp, nil
+}
+
 // Create response for txpool_inspect request.
 // See https://geth.ethereum.org/docs/rpc/ns-txpool#txpool_inspect.
 func (t *TxPool) Inspect() (interface{}, error) {
@@ -164,23 +198,13 @@ func (t *TxPool) Inspect() (interface{}, error) {
 // See https://geth.ethereum.org/docs/rpc/ns-txpool#txpool_status.
 func (t *TxPool) Status() (interface{}, error) {
 	t.metrics.TxPoolAPICounterInc(TxPoolStatusLabel)
-	pendingTxs, queuedTxs := t.store.GetTxs(true)
 
-	var pendingCount int
-
-	for _, t := range pendingTxs {
-		pendingCount += len(t)
-	}
-
-	var queuedCount int
-
-	for _, t := range queuedTxs {
-		queuedCount += len(t)
-	}
+	// counted by the pool, not collecting the transactions
+	pendingCount, queuedCount := t.store.GetTxCounts()
 
 	resp := StatusResponse{
-		Pending: uint64(pendingCount),
-		Queued:  uint64(queuedCount),
+		Pending: pendingCount,
+		Queued:  queuedCount,
 	}
 
 	return resp, nil
@@ -107,6 +107,36 @@ func TestContentEndpoint(t *testing.T) {
 	})
 }
 
+func TestContentFromEndpoint(t *testing.T) {
+	mockStore := newMockTxPoolStore()
+	address1 := types.Address{0x1}
+	testTx1 := newTestTransaction(2, address1)
+	testTx2 := newTestTransaction(11, address1)
+	address2 := types.Address{0x2}
+	testTx3 := newTestTransaction(7, address2)
+	mockStore.pending[address1] = []*types.Transaction{testTx1}
+	mockStore.queued[address1] = []*types.Transaction{testTx2}
+	mockStore.pending[address2] = []*types.Transaction{testTx3}
+	txPoolEndpoint := &TxPool{mockStore, NilMetrics()}
+
+	result, _ := txPoolEndpoint.ContentFrom(address1)
+	//nolint:forcetypeassert
+	response := result.(ContentFromResponse)
+
+	assert.Equal(t, 1, len(response.Pending))
+	assert.Equal(t, 1, len(response.Queued))
+	assert.Equal(t, testTx1.Hash(), response.Pending[testTx1.Nonce].Hash)
+	assert.Equal(t, testTx2.Hash(), response.Queued[testTx2.Nonce].Hash)
+
+	// unknown account
+	result, _ = txPoolEndpoint.ContentFrom(types.Address{0x3})
+	//nolint:forcetypeassert
+	response = result.(ContentFromResponse)
+
+	assert.Equal(t, 0, len(response.Pending))
+	assert.Equal(t, 0, len(response.Queued))
+}
+
 func TestInspectEndpoint(t *testing.T) {
 	t.Run("returns empty InspectResponse if tx pool has no transactions", func(t *testing.T) {
 		mockStore := newMockTxPoolStore()
@@ -225,6 +255,22 @@ func (s *mockTxPoolStore) GetTxs(inclQueued bool) (map[types.Address][]*types.Tr
 	return s.pending, s.queued
 }
 
+func (s *mockTxPoolStore) GetTxCounts() (pending uint64, queued uint64) {
+	for _, txs := range s.pending {
+		pending += uint64(len(txs))
+	}
+
+	for _, txs := range s.queued {
+		queued += uint64(len(txs))
+	}
+
+	return
+}
+
+func (s *mockTxPoolStore) GetAccountTxs(addr types.Address) ([]*types.Transaction, []*types.Transaction) {
+	return s.pending[addr], s.queued[addr]
+}
+
 func (s *mockTxPoolStore) GetCapacity() (uint64, uint64) {
 	return s.capacity, s.maxSlots
 }
@@ -1,10 +1,12 @@
 package jsonrpc
 
 import (
+	"encoding/json"
 	"math/big"
 	"strconv"
 	"strings"
 
+	"github.com/dogechain-lab/dogechain/blockchain"
 	"github.com/dogechain-lab/dogechain/helper/hex"
 	"github.com/dogechain-lab/dogechain/types"
 )
@@ -178,9 +180,15 @@ type receipt struct {
 	BlockHash         types.Hash     `json:"blockHash"`
 	BlockNumber       argUint64      `json:"blockNumber"`
 	GasUsed           argUint64      `json:"gasUsed"`
+	IntrinsicGas      argUint64      `json:"intrinsicGas"`
+	EffectiveGasPrice argBig         `json:"effectiveGasPrice"`
+	EffectiveCost     argBig         `json:"effectiveCost"`
 	ContractAddress   *types.Address `json:"contractAddress"`
 	FromAddr          types.Address  `json:"from"`
 	ToAddr            *types.Address `json:"to"`
+
+	// SystemTxType tags the consensus system transaction, omitted for the user ones
+	SystemTxType blockchain.SystemTxType `json:"systemTxType,omitempty"`
 }
 
 type Log struct {
@@ -316,6 +324,31 @@ type txnArgs struct {
 	Nonce    *argUint64
 }
 
+// storageProof is the storage slot value with its merkle proof
+type storageProof struct {
+	Key   types.Hash `json:"key"`
+	Value argBig     `json:"value"`
+	Proof []argBytes `json:"proof"`
+}
+
+// accountProofResult is the account with its merkle proof, and the storage proofs
+type accountProofResult struct {
+	Address      types.Address   `json:"address"`
+	AccountProof []argBytes      `json:"accountProof"`
+	Balance      argBig          `json:"balance"`
+	CodeHash     types.Hash      `json:"codeHash"`
+	Nonce        argUint64       `json:"nonce"`
+	StorageHash  types.Hash      `json:"storageHash"`
+	StorageProof []*storageProof `json:"storageProof"`
+}
+
+// accessListResult is the access list the call produces, with the gas it uses
+type accessListResult struct {
+	AccessList json.RawMessage `json:"accessList"`
+	GasUsed    argUint64       `json:"gasUsed"`
+	Error      string          `json:"error,omitempty"`
+}
+
 type progression struct {
 	Type          string `json:"type"`
 	SyncingPeer   string `json:"syncingPeer"`
@@ -12,6 +12,11 @@ import (
 type Config struct {
 	DiscoverIngoreCIDR []*net.IPNet // list of CIDR ranges to ignore when discovering peers
 
+	AllowedCIDRs []*net.IPNet // list of CIDR ranges to only connect with, all if empty
+	DeniedCIDRs  []*net.IPNet // list of CIDR ranges to never connect with
+	DeniedASNs   []string     // list of autonomous system numbers to never connect with
+	GatePolicies []GatePolicy // custom connection gate policies
+
 	NoDiscover       bool                   // flag indicating if the discovery mechanism should be turned on
 	Addr             *net.TCPAddr           // the base address
 	NatAddr          *net.TCPAddr           // the NAT address
@@ -0,0 +1,207 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/dogechain-lab/dogechain/network/common"
+	"github.com/hashicorp/go-hclog"
+	ranger "github.com/libp2p/go-cidranger"
+	asnutil "github.com/libp2p/go-libp2p-asn-util"
+	"github.com/libp2p/go-libp2p/core/control"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+var (
+	ErrDeniedCIDR = errors.New("address in denied CIDR range")
+	ErrDeniedASN  = errors.New("address in denied autonomous system")
+	ErrNotAllowed = errors.New("address not in allowed CIDR ranges")
+)
+
+// GatePolicy decides whether a peer connection is allowed. It is evaluated before
+// dialing a peer and before accepting an inbound connection, so that a custom policy,
+// e.g. a GeoIP country policy, could be plugged into the networking server.
+type GatePolicy interface {
+	// Allow returns nil if the connection in the direction with the remote address is allowed
+	Allow(direction network.Direction, addr multiaddr.Multiaddr) error
+}
+
+// ASNResolver resolves the autonomous system number of the IP, empty if unknown
+type ASNResolver interface {
+	ASN(ip net.IP) (string, error)
+}
+
+// ipv6ASNResolver resolves the ASN of IPv6 addresses with the embedded libp2p mapping,
+// IPv4 addresses are unknown
+type ipv6ASNResolver struct{}
+
+func (ipv6ASNResolver) ASN(ip net.IP) (string, error) {
+	if ip.To4() != nil {
+		return "", nil
+	}
+
+	return asnutil.Store.AsnForIPv6(ip)
+}
+
+// ListPolicy is a GatePolicy rejecting the addresses within the denied CIDR ranges
+// or autonomous systems. When allowed CIDR ranges are set, only they are accepted.
+type ListPolicy struct {
+	allowed  ranger.Ranger // nil means all ranges are allowed
+	denied   ranger.Ranger
+	asns     map[string]struct{}
+	resolver ASNResolver
+}
+
+// NewListPolicy creates a ListPolicy, the resolver defaults to the IPv6 only libp2p mapping
+func NewListPolicy(allowed, denied []*net.IPNet, asns []string, resolver ASNResolver) *ListPolicy {
+	p := &ListPolicy{
+		denied:   newCIDRRanger(denied),
+		asns:     make(map[string]struct{}, len(asns)),
+		resolver: resolver,
+	}
+
+	if len(allowed) > 0 {
+		p.allowed = newCIDRRanger(allowed)
+	}
+
+	for _, asn := range asns {
+		p.asns[asn] = struct{}{}
+	}
+
+	if p.resolver == nil {
+		p.resolver = ipv6ASNResolver{}
+	}
+
+	return p
+}
+
+// Allow implements GatePolicy, the rules apply to both directions
+func (p *ListPolicy) Allow(_ network.Direction, addr multiaddr.Multiaddr) error {
+	ip, err := common.ParseMultiaddrIP(addr)
+	if err != nil {
+		// dns addresses are checked once resolved
+		return nil
+	}
+
+	if p.allowed != nil {
+		if ok, err := p.allowed.Contains(ip); err != nil || !ok {
+			return ErrNotAllowed
+		}
+	}
+
+	if ok, err := p.denied.Contains(ip); err == nil && ok {
+		return ErrDeniedCIDR
+	}
+
+	if len(p.asns) == 0 {
+		return nil
+	}
+
+	asn, err := p.resolver.ASN(ip)
+	if err != nil || asn == "" {
+		return nil
+	}
+
+	if _, ok := p.asns[asn]; ok {
+		return fmt.Errorf("%w %s", ErrDeniedASN, asn)
+	}
+
+	return nil
+}
+
+func newCIDRRanger(list []*net.IPNet) ranger.Ranger {
+	r := ranger.NewPCTrieRanger()
+
+	for _, cidr := range list {
+		if cidr != nil {
+			_ = r.Insert(ranger.NewBasicRangerEntry(*cidr))
+		}
+	}
+
+	return r
+}
+
+// connectionGater evaluates the gate policies on the libp2p connection lifecycle
+type connectionGater struct {
+	logger   hclog.Logger
+	policies []GatePolicy
+
+	lock     sync.RWMutex
+	exemptFn func(peer.ID) bool // exempted peers skip the policies, e.g. static peers
+}
+
+func newConnectionGater(logger hclog.Logger, policies []GatePolicy) *connectionGater {
+	return &connectionGater{
+		logger:   logger.Named("gater"),
+		policies: policies,
+	}
+}
+
+func (g *connectionGater) setExemptFn(fn func(peer.ID) bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.exemptFn = fn
+}
+
+func (g *connectionGater) isExempt(id peer.ID) bool {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	return g.exemptFn != nil && g.exemptFn(id)
+}
+
+func (g *connectionGater) allow(direction network.Direction, id peer.ID, addr multiaddr.Multiaddr) bool {
+	if len(g.policies) == 0 || g.isExempt(id) {
+		return true
+	}
+
+	for _, policy := range g.policies {
+		if err := policy.Allow(direction, addr); err != nil {
+			g.logger.Debug("connection gated", "direction", direction, "peer", id, "addr", addr, "err", err)
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// InterceptPeerDial implements connmgr.ConnectionGater, the addresses are unknown yet
+func (g *connectionGater) InterceptPeerDial(peer.ID) bool {
+	return true
+}
+
+// InterceptAddrDial implements connmgr.ConnectionGater
+func (g *connectionGater) InterceptAddrDial(id peer.ID, addr multiaddr.Multiaddr) bool {
+	return g.allow(network.DirOutbound, id, addr)
+}
+
+// InterceptAccept implements connmgr.ConnectionGater, the check is deferred until
+// the peer ID is known, so that exempted peers could connect
+func (g *connectionGater) InterceptAccept(network.ConnMultiaddrs) bool {
+	return true
+}
+
+// InterceptSecured implements connmgr.ConnectionGater
+func (g *connectionGater) InterceptSecured(
+	direction network.Direction,
+	id peer.ID,
+	addrs network.ConnMultiaddrs,
+) bool {
+	if direction != network.DirInbound {
+		// outbound connections are checked on dialing
+		return true
+	}
+
+	return g.allow(direction, id, addrs.RemoteMultiaddr())
+}
+
+// InterceptUpgraded implements connmgr.ConnectionGater
+func (g *connectionGater) InterceptUpgraded(network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
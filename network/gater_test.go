@@ -0,0 +1,92 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockASNResolver map[string]string
+
+func (m mockASNResolver) ASN(ip net.IP) (string, error) {
+	return m[ip.String()], nil
+}
+
+func mustCIDRs(t *testing.T, list ...string) []*net.IPNet {
+	t.Helper()
+
+	cidrs := make([]*net.IPNet, len(list))
+
+	for i, raw := range list {
+		_, ipnet, err := net.ParseCIDR(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cidrs[i] = ipnet
+	}
+
+	return cidrs
+}
+
+func TestListPolicy_Allow(t *testing.T) {
+	policy := NewListPolicy(
+		nil,
+		mustCIDRs(t, "10.0.0.0/8"),
+		[]string{"64512"},
+		mockASNResolver{"1.2.3.4": "64512", "1.2.3.5": "64513"},
+	)
+
+	cases := []struct {
+		addr string
+		err  error
+	}{
+		{"/ip4/10.1.2.3/tcp/1478", ErrDeniedCIDR},
+		{"/ip4/1.2.3.4/tcp/1478", ErrDeniedASN},
+		{"/ip4/1.2.3.5/tcp/1478", nil},
+		{"/ip4/192.168.1.1/tcp/1478", nil},
+		{"/dns/example.com/tcp/1478", nil},
+	}
+
+	for _, c := range cases {
+		err := policy.Allow(network.DirInbound, multiaddr.StringCast(c.addr))
+		if c.err == nil {
+			assert.NoError(t, err, c.addr)
+		} else {
+			assert.ErrorIs(t, err, c.err, c.addr)
+		}
+	}
+}
+
+func TestListPolicy_AllowedCIDRs(t *testing.T) {
+	policy := NewListPolicy(mustCIDRs(t, "192.168.0.0/16"), mustCIDRs(t, "192.168.1.0/24"), nil, nil)
+
+	assert.NoError(t, policy.Allow(network.DirOutbound, multiaddr.StringCast("/ip4/192.168.2.1/tcp/1478")))
+	assert.ErrorIs(t, policy.Allow(network.DirOutbound, multiaddr.StringCast("/ip4/192.168.1.1/tcp/1478")), ErrDeniedCIDR)
+	assert.ErrorIs(t, policy.Allow(network.DirOutbound, multiaddr.StringCast("/ip4/10.0.0.1/tcp/1478")), ErrNotAllowed)
+}
+
+func TestConnectionGater_Exempt(t *testing.T) {
+	gater := newConnectionGater(hclog.NewNullLogger(), []GatePolicy{
+		NewListPolicy(nil, mustCIDRs(t, "10.0.0.0/8"), nil, nil),
+	})
+
+	static := peer.ID("static")
+	other := peer.ID("other")
+	addr := multiaddr.StringCast("/ip4/10.0.0.1/tcp/1478")
+
+	assert.False(t, gater.InterceptAddrDial(static, addr))
+
+	gater.setExemptFn(func(id peer.ID) bool {
+		return id == static
+	})
+
+	assert.True(t, gater.InterceptAddrDial(static, addr))
+	assert.False(t, gater.InterceptAddrDial(other, addr))
+	assert.True(t, gater.InterceptAddrDial(other, multiaddr.StringCast("/ip4/1.2.3.4/tcp/1478")))
+}
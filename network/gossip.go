@@ -36,11 +36,39 @@ type Topic interface {
 	Close() error
 }
 
+// TopicOption configures the delivery of the subscribed messages
+type TopicOption func(*topicOptions)
+
+type topicOptions struct {
+	bufferSize int // messages buffered before dropped by the pubsub
+	workers    int // handlers running concurrently
+}
+
+// WithTopicBufferSize sets the number of messages buffered before they are dropped.
+// The consensus topics need more room than the others to ride out message bursts.
+func WithTopicBufferSize(size int) TopicOption {
+	return func(o *topicOptions) {
+		if size > 0 {
+			o.bufferSize = size
+		}
+	}
+}
+
+// WithTopicWorkers sets the number of the message handlers running concurrently
+func WithTopicWorkers(workers int) TopicOption {
+	return func(o *topicOptions) {
+		if workers > 0 {
+			o.workers = workers
+		}
+	}
+}
+
 type topicImp struct {
 	logger hclog.Logger
 
 	subTopic *pubsub.Topic
 	typ      reflect.Type
+	options  topicOptions
 
 	wg            sync.WaitGroup
 	unsubscribeCh chan struct{}
@@ -65,7 +93,7 @@ func (t *topicImp) Publish(obj proto.Message) error {
 }
 
 func (t *topicImp) Subscribe(handler func(obj interface{}, from peer.ID)) error {
-	sub, err := t.subTopic.Subscribe(pubsub.WithBufferSize(subscribeOutputBufferSize))
+	sub, err := t.subTopic.Subscribe(pubsub.WithBufferSize(t.options.bufferSize))
 	if err != nil {
 		return err
 	}
@@ -91,7 +119,7 @@ func (t *topicImp) readLoop(sub *pubsub.Subscription, handler func(obj interface
 	// wait group for better close?
 	t.wg.Add(1)
 	// work queue for less goroutine allocation
-	workqueue := make(chan *task, _workerNum*4)
+	workqueue := make(chan *task, t.options.workers*4)
 	defer close(workqueue)
 
 	// cancel context
@@ -120,7 +148,7 @@ func (t *topicImp) readLoop(sub *pubsub.Subscription, handler func(obj interface
 		t.wg.Done()
 	}()
 
-	for i := 0; i < _workerNum; i++ {
+	for i := 0; i < t.options.workers; i++ {
 		go func() {
 			for {
 				task, ok := <-workqueue
@@ -162,17 +190,27 @@ func (t *topicImp) readLoop(sub *pubsub.Subscription, handler func(obj interface
 	}
 }
 
-func (s *DefaultServer) NewTopic(protoID string, obj proto.Message) (Topic, error) {
+func (s *DefaultServer) NewTopic(protoID string, obj proto.Message, opts ...TopicOption) (Topic, error) {
 	topic, err := s.ps.Join(protoID)
 	if err != nil {
 		return nil, err
 	}
 
+	options := topicOptions{
+		bufferSize: subscribeOutputBufferSize,
+		workers:    _workerNum,
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	tt := &topicImp{
 		logger: s.logger.Named(protoID),
 
 		subTopic: topic,
 		typ:      reflect.TypeOf(obj).Elem(),
+		options:  options,
 
 		unsubscribeCh: make(chan struct{}),
 	}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net"
 	"sync"
+	"time"
 
 	"github.com/dogechain-lab/dogechain/helper/common"
 
@@ -13,7 +14,9 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	manet "github.com/multiformats/go-multiaddr/net"
 	grpcPeer "google.golang.org/grpc/peer"
@@ -28,7 +31,56 @@ type GrpcStream struct {
 	grpcServer *grpc.Server
 }
 
-func NewGrpcStream(ctx context.Context) *GrpcStream {
+// StreamOption configures the quality of service of the served requests
+type StreamOption func(*streamOptions)
+
+type streamOptions struct {
+	requestTimeout   time.Duration       // deadline of the served requests, 0 for none
+	maxRequests      int                 // requests served concurrently, 0 for unlimited
+	unlimitedMethods map[string]struct{} // full methods not counted by the concurrency limit
+}
+
+// WithRequestTimeout sets the deadline of the served requests
+func WithRequestTimeout(timeout time.Duration) StreamOption {
+	return func(o *streamOptions) {
+		o.requestTimeout = timeout
+	}
+}
+
+// WithMaxConcurrentRequests limits the requests served concurrently, so that a
+// protocol could not starve the others of the node. The excess requests wait for
+// a free slot until their deadline.
+func WithMaxConcurrentRequests(n int) StreamOption {
+	return func(o *streamOptions) {
+		o.maxRequests = n
+	}
+}
+
+// WithUnlimitedMethods exempts the full methods, like "/v1.V1/GetStatus", from the
+// concurrency limit, so that the cheap queries are not starved by the bulk ones.
+// They are still bound by the request deadline.
+func WithUnlimitedMethods(methods ...string) StreamOption {
+	return func(o *streamOptions) {
+		if o.unlimitedMethods == nil {
+			o.unlimitedMethods = make(map[string]struct{}, len(methods))
+		}
+
+		for _, method := range methods {
+			o.unlimitedMethods[method] = struct{}{}
+		}
+	}
+}
+
+func newStreamOptions(opts ...StreamOption) streamOptions {
+	options := streamOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+func NewGrpcStream(ctx context.Context, opts ...StreamOption) *GrpcStream {
 	ctx, cancel := context.WithCancel(ctx)
 
 	return &GrpcStream{
@@ -36,7 +88,8 @@ func NewGrpcStream(ctx context.Context) *GrpcStream {
 		ctxCancel: cancel,
 		streamCh:  make(chan network.Stream),
 		grpcServer: grpc.NewServer(
-			grpc.UnaryInterceptor(interceptor),
+			// the peer context is wrapped at last, so that the handlers could get it
+			grpc.ChainUnaryInterceptor(qosInterceptor(newStreamOptions(opts...)), interceptor),
 			grpc.MaxRecvMsgSize(common.MaxGrpcMsgSize),
 			grpc.MaxSendMsgSize(common.MaxGrpcMsgSize)),
 	}
@@ -79,6 +132,39 @@ func interceptor(
 	)
 }
 
+// qosInterceptor applies the request deadline and concurrency limit of the options
+func qosInterceptor(options streamOptions) grpc.UnaryServerInterceptor {
+	var slots chan struct{}
+	if options.maxRequests > 0 {
+		slots = make(chan struct{}, options.maxRequests)
+	}
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if options.requestTimeout > 0 {
+			var cancel context.CancelFunc
+
+			ctx, cancel = context.WithTimeout(ctx, options.requestTimeout)
+			defer cancel()
+		}
+
+		if _, unlimited := options.unlimitedMethods[info.FullMethod]; slots != nil && !unlimited {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return nil, status.Error(codes.ResourceExhausted, "too many concurrent requests")
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
 func (g *GrpcStream) Client(ctx context.Context, stream network.Stream) (*grpc.ClientConn, error) {
 	return WrapClient(ctx, stream)
 }
@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testBulkMethod   = "/v1.V1/GetBlocks"
+	testStatusMethod = "/v1.V1/GetStatus"
+)
+
+func TestQoSInterceptor_Saturated(t *testing.T) {
+	t.Parallel()
+
+	intercept := qosInterceptor(newStreamOptions(
+		WithRequestTimeout(100*time.Millisecond),
+		WithMaxConcurrentRequests(2),
+		WithUnlimitedMethods(testStatusMethod),
+	))
+
+	call := func(method string, handler grpc.UnaryHandler) error {
+		_, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+
+		return err
+	}
+
+	// saturate the limit with the bulk requests running till released
+	var (
+		release = make(chan struct{})
+		running = make(chan struct{})
+		done    = make(chan error, 2)
+	)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			done <- call(testBulkMethod, func(context.Context, interface{}) (interface{}, error) {
+				running <- struct{}{}
+				<-release
+
+				return nil, nil
+			})
+		}()
+	}
+
+	<-running
+	<-running
+
+	// the status is still served
+	assert.NoError(t, call(testStatusMethod, func(context.Context, interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+
+	// the other bulk requests wait for a free slot till their deadline
+	err := call(testBulkMethod, func(context.Context, interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	close(release)
+
+	assert.NoError(t, <-done)
+	assert.NoError(t, <-done)
+
+	// served once the slots are freed
+	assert.NoError(t, call(testBulkMethod, func(context.Context, interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+}
+
+func TestQoSInterceptor_Deadline(t *testing.T) {
+	t.Parallel()
+
+	intercept := qosInterceptor(newStreamOptions(
+		WithRequestTimeout(time.Second),
+		WithUnlimitedMethods(testStatusMethod),
+	))
+
+	// the unlimited methods are still bound by the deadline
+	_, err := intercept(
+		context.Background(),
+		nil,
+		&grpc.UnaryServerInfo{FullMethod: testStatusMethod},
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+
+			return nil, nil
+		},
+	)
+	assert.NoError(t, err)
+}
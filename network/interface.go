@@ -15,12 +15,16 @@ type Network interface {
 
 	// AddrInfo returns Network Info
 	AddrInfo() *peer.AddrInfo
+	// NodeRecord returns the signed identity record of the node
+	NodeRecord() (*NodeRecord, error)
 	// Peers returns current connected peers
 	Peers() []*PeerConnInfo
 	// PeerCount returns the number of connected peers
 	PeerCount() int64
 	// GetPeerInfo returns the peer info for the given peer ID
 	GetPeerInfo(peerID peer.ID) *peer.AddrInfo
+	// PeerConns returns the open connections to the peer
+	PeerConns(peerID peer.ID) []network.Conn
 	// JoinPeer joins a peer to the network
 	JoinPeer(rawPeerMultiaddr string, static bool) error
 	// HasPeer returns true if the peer is connected
@@ -35,7 +39,7 @@ type Network interface {
 	// **Topic**
 
 	// NewTopic Creates New Topic for gossip
-	NewTopic(protoID string, obj proto.Message) (Topic, error)
+	NewTopic(protoID string, obj proto.Message, opts ...TopicOption) (Topic, error)
 	// SubscribeFn subscribe of peer event
 	SubscribeFn(ctx context.Context, handler func(evnt *event.PeerEvent)) error
 
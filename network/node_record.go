@@ -0,0 +1,63 @@
+package network
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/record"
+	"github.com/multiformats/go-multiaddr"
+)
+
+var ErrNoPrivateKey = errors.New("no private key for local peer")
+
+// NodeRecord is the identity record of the node, which could be shared as
+// a bootnode or static peer
+type NodeRecord struct {
+	// ID is the libp2p peer ID
+	ID peer.ID
+	// Addrs are the public listen addresses, NAT mapped if configured
+	Addrs []multiaddr.Multiaddr
+	// Protocols are the protocols supported by the node
+	Protocols []string
+	// Envelope is the marshaled signed peer record
+	Envelope []byte
+}
+
+// NodeRecord returns the identity record of the node signed by the libp2p key
+func (s *DefaultServer) NodeRecord() (*NodeRecord, error) {
+	id := s.host.ID()
+
+	key := s.host.Peerstore().PrivKey(id)
+	if key == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	// host addresses are filtered by the address factory, which maps NAT and DNS
+	addrs := s.host.Addrs()
+
+	rec := peer.PeerRecordFromAddrInfo(peer.AddrInfo{
+		ID:    id,
+		Addrs: addrs,
+	})
+
+	envelope, err := record.Seal(rec, key)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := envelope.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	protocols := s.host.Mux().Protocols()
+	sort.Strings(protocols)
+
+	return &NodeRecord{
+		ID:        id,
+		Addrs:     addrs,
+		Protocols: protocols,
+		Envelope:  raw,
+	}, nil
+}
@@ -72,10 +72,12 @@ type DefaultServer struct {
 
 	config *Config // the base networking server configuration
 
-	closeCh chan struct{}  // the channel used for closing the networking server
-	closeWg sync.WaitGroup // the waitgroup used for closing the networking server
+	closeCh   chan struct{}  // the channel used for closing the networking server
+	closeWg   sync.WaitGroup // the waitgroup used for closing the networking server
+	closeOnce sync.Once      // closes the networking server once
 
 	host   host.Host             // the libp2p host reference
+	gater  *connectionGater      // the connection gater of the host
 	selfID peer.ID               // the node ID
 	addrs  []multiaddr.Multiaddr // the list of supported (bound) addresses
 
@@ -185,6 +187,14 @@ func newServer(
 		return nil, fmt.Errorf("failed to create libp2p stack: %w", err)
 	}
 
+	// gate the connections before dialing and accepting
+	policies := append([]GatePolicy{}, config.GatePolicies...)
+	if len(config.AllowedCIDRs) > 0 || len(config.DeniedCIDRs) > 0 || len(config.DeniedASNs) > 0 {
+		policies = append(policies, NewListPolicy(config.AllowedCIDRs, config.DeniedCIDRs, config.DeniedASNs, nil))
+	}
+
+	gater := newConnectionGater(logger, policies)
+
 	host, err := libp2p.New(
 		// Use noise as the encryption protocol
 		libp2p.Security(noise.ID, noise.New),
@@ -192,6 +202,7 @@ func newServer(
 		libp2p.AddrsFactory(addrsFactory),
 		libp2p.Identity(key),
 		libp2p.ConnectionManager(cm),
+		libp2p.ConnectionGater(gater),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create libp2p stack: %w", err)
@@ -207,6 +218,7 @@ func newServer(
 		tracer:           tracer,
 		config:           config,
 		host:             host,
+		gater:            gater,
 		selfID:           host.ID(),
 		addrs:            host.Addrs(),
 		peers:            make(map[peer.ID]*PeerConnInfo),
@@ -288,6 +300,9 @@ func (s *DefaultServer) Start() error {
 		return fmt.Errorf("unable to parse static node data, %w", setupErr)
 	}
 
+	// static peers are always allowed
+	s.gater.setExemptFn(s.IsStaticPeer)
+
 	// Set up the peer discovery mechanism if needed
 	if !s.config.NoDiscover {
 		// Parse the bootnode data
@@ -605,6 +620,11 @@ func (s *DefaultServer) IsStaticPeer(peerID peer.ID) bool {
 	return s.staticnodes.isStaticnode(peerID)
 }
 
+// PeerConns returns the open connections to the peer
+func (s *DefaultServer) PeerConns(peerID peer.ID) []network.Conn {
+	return s.host.Network().ConnsToPeer(peerID)
+}
+
 // GetProtocols fetches the list of node-supported protocols
 func (s *DefaultServer) GetProtocols(peerID peer.ID) ([]string, error) {
 	return s.host.Peerstore().GetProtocols(peerID)
@@ -768,6 +788,16 @@ func (s *DefaultServer) markStaticPeer(peerInfo *peer.AddrInfo) {
 }
 
 func (s *DefaultServer) Close() error {
+	var err error
+
+	s.closeOnce.Do(func() {
+		err = s.close()
+	})
+
+	return err
+}
+
+func (s *DefaultServer) close() error {
 	s.keepAvailable.Close()
 
 	// close dial queue
@@ -174,7 +174,7 @@ func (s *DefaultServer) setupDiscovery() error {
 		}
 
 		return ignoreRange
-	}(s.config.DiscoverIngoreCIDR)
+	}(append(append([]*net.IPNet{}, s.config.DiscoverIngoreCIDR...), s.config.DeniedCIDRs...))
 
 	// Create an instance of the discovery service
 	discoveryService := discovery.NewDiscoveryService(
@@ -7,6 +7,7 @@ import (
 
 	"github.com/dogechain-lab/dogechain/network/event"
 
+	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"go.uber.org/atomic"
 	"google.golang.org/protobuf/proto"
@@ -43,6 +44,10 @@ func (s *NonetworkServer) AddrInfo() *peer.AddrInfo {
 	return &peer.AddrInfo{}
 }
 
+func (s *NonetworkServer) NodeRecord() (*NodeRecord, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (s *NonetworkServer) Peers() []*PeerConnInfo {
 	return []*PeerConnInfo{}
 }
@@ -63,7 +68,7 @@ func (s *NonetworkServer) SubscribeFn(context.Context, func(evnt *event.PeerEven
 	return nil
 }
 
-func (s *NonetworkServer) NewTopic(protoID string, obj proto.Message) (Topic, error) {
+func (s *NonetworkServer) NewTopic(protoID string, obj proto.Message, opts ...TopicOption) (Topic, error) {
 	return &NonetworkTopic{}, nil
 }
 
@@ -83,6 +88,10 @@ func (s *NonetworkServer) GetMetrics() *Metrics {
 
 func (s *NonetworkServer) ForgetPeer(peer peer.ID, reason string) {}
 
+func (s *NonetworkServer) PeerConns(peerID peer.ID) []network.Conn {
+	return nil
+}
+
 func (s *NonetworkServer) Start() error {
 	s.isClose.Store(false)
 
@@ -39,6 +39,8 @@ type syncPeerClient struct {
 
 	shouldEmitBlocks bool // flag for emitting blocks in the topic
 
+	publishedHook func(number uint64) // called once the status is published, could be nil
+
 	isClosed *atomic.Bool
 
 	ctx    context.Context
@@ -105,6 +107,11 @@ func (client *syncPeerClient) EnablePublishingPeerStatus() {
 	client.shouldEmitBlocks = true
 }
 
+// SetPublishedHook sets the hook called once the status is published in syncer topic
+func (client *syncPeerClient) SetPublishedHook(hook func(number uint64)) {
+	client.publishedHook = hook
+}
+
 // GetPeerStatus fetches peer status
 func (client *syncPeerClient) GetPeerStatus(peerID peer.ID) (*NoForkPeer, error) {
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), defaultTimeoutForStatus)
@@ -260,6 +267,8 @@ func (client *syncPeerClient) startNewBlockProcess() {
 				Number: latest.Number,
 			}); err != nil {
 				client.logger.Warn("failed to publish status", "err", err)
+			} else if client.publishedHook != nil {
+				client.publishedHook(latest.Number)
 			}
 		}
 	}
@@ -326,6 +335,143 @@ func (client *syncPeerClient) GetBlocks(
 	return blocks, err
 }
 
+// GetHeaders returns the consecutive headers from the given height, up to amount
+func (client *syncPeerClient) GetHeaders(
+	ctx context.Context,
+	peerID peer.ID,
+	from uint64,
+	amount uint64,
+) ([]*types.Header, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeoutForBlocks)
+	defer cancel()
+
+	clt, err := client.newSyncPeerClient(ctx, peerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sync peer client: %w", err)
+	}
+
+	defer clt.Close()
+
+	rsp, err := clt.GetHeaders(ctx, &proto.GetHeadersRequest{
+		Number: int64(from),
+		Amount: int64(amount),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make([]*types.Header, len(rsp.Objs))
+
+	for i, obj := range rsp.Objs {
+		header := new(types.Header)
+
+		if obj.Spec == nil {
+			return nil, fmt.Errorf("empty header at %d", from+uint64(i))
+		}
+
+		if err := header.UnmarshalRLP(obj.Spec.Value); err != nil {
+			return nil, fmt.Errorf("failed to UnmarshalRLP: %w", err)
+		}
+
+		headers[i] = header
+	}
+
+	return headers, nil
+}
+
+// GetReceipts returns the receipts of the blocks by hash from the peer
+func (client *syncPeerClient) GetReceipts(
+	ctx context.Context,
+	peerID peer.ID,
+	hashes []types.Hash,
+) ([][]*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeoutForBlocks)
+	defer cancel()
+
+	clt, err := client.newSyncPeerClient(ctx, peerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sync peer client: %w", err)
+	}
+
+	defer clt.Close()
+
+	input := make([]string, 0, len(hashes))
+
+	for _, h := range hashes {
+		input = append(input, h.String())
+	}
+
+	resp, err := clt.GetObjectsByHash(ctx, &proto.HashRequest{
+		Hash: input,
+		Type: proto.HashRequest_RECEIPTS,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Objs) != len(hashes) {
+		return nil, fmt.Errorf("not correct size")
+	}
+
+	receipts := make([][]*types.Receipt, len(hashes))
+
+	for i, obj := range resp.Objs {
+		var raw types.Receipts
+
+		if obj.Spec != nil && len(obj.Spec.Value) > 0 {
+			if err := raw.UnmarshalRLP(obj.Spec.Value); err != nil {
+				return nil, fmt.Errorf("failed to UnmarshalRLP: %w", err)
+			}
+		}
+
+		receipts[i] = raw
+	}
+
+	return receipts, nil
+}
+
+// GetTrieNodes returns the trie nodes by hash from the peer, the missing ones are empty
+func (client *syncPeerClient) GetTrieNodes(
+	ctx context.Context,
+	peerID peer.ID,
+	hashes []types.Hash,
+) ([][]byte, error) {
+	clt, err := client.newSyncPeerClient(ctx, peerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sync peer client: %w", err)
+	}
+
+	defer clt.Close()
+
+	input := make([]string, 0, len(hashes))
+
+	for _, h := range hashes {
+		input = append(input, h.String())
+	}
+
+	resp, err := clt.GetObjectsByHash(ctx, &proto.HashRequest{
+		Hash: input,
+		Type: proto.HashRequest_TRIE_NODES,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([][]byte, len(hashes))
+
+	for i, obj := range resp.Objs {
+		if i >= len(nodes) {
+			break
+		}
+
+		if obj.Spec != nil {
+			nodes[i] = obj.Spec.Value
+		}
+	}
+
+	return nodes, nil
+}
+
 // GetConnectedPeerStatuses fetches the statuses of all connecting peers
 func (client *syncPeerClient) Broadcast(block *types.Block) error {
 	var ps = client.network.Peers()
@@ -25,6 +25,11 @@ type Syncer interface {
 	IsSyncing() bool
 	// Sync starts routine to sync blocks
 	Sync(func(*types.Block) bool) error
+	// FetchTrieNodes fetches the trie nodes by hash from the connected peers
+	FetchTrieNodes(hashes []types.Hash) ([][]byte, error)
+	// SetPublishedHook sets the hook called once the new head is published to the peers,
+	// it must be set before started
+	SetPublishedHook(hook func(number uint64))
 }
 
 // Blockchain is the interface required by the syncer to connect to the blockchain
@@ -40,16 +45,47 @@ type Blockchain interface {
 	GetBodyByHash(types.Hash) (*types.Body, bool)
 	GetHeaderByHash(types.Hash) (*types.Header, bool)
 	GetHeaderByNumber(n uint64) (*types.Header, bool)
+	// GetCanonicalHashesInRange returns the canonical hashes within [from, to], stopping
+	// at the first missing one
+	GetCanonicalHashesInRange(from, to uint64) []types.Hash
+	// GetHeadersInRange returns the canonical headers within [from, to], stopping at the
+	// first missing one
+	GetHeadersInRange(from, to uint64) []*types.Header
 	CalculateGasLimit(number uint64) (uint64, error)
 
 	// advance chain methods
 	WriteBlock(block *types.Block, source string) error
 	VerifyFinalizedBlock(block *types.Block) error
+	// VerifyAnchoredBlock verifies a block before the trusted checkpoint, linked to it
+	// by the parent hashes, along with the receipts served by the peer
+	VerifyAnchoredBlock(block *types.Block, receipts []*types.Receipt) error
+	// ReadCheckpointAnchor returns the hash at the height linked to the trusted checkpoint
+	ReadCheckpointAnchor(n uint64) (types.Hash, bool)
+	// WriteCheckpointAnchor persists the hash at the height linked to the trusted checkpoint
+	WriteCheckpointAnchor(n uint64, hash types.Hash) error
+	// AwaitImportAdmission delays the import while the node is under resource pressure
+	AwaitImportAdmission(ctx context.Context) error
 
 	// GetBlockByNumber returns block by number
 	GetBlockByNumber(uint64, bool) (*types.Block, bool)
 }
 
+// StateNodeReader is the interface required by the syncer to serve the trie nodes
+type StateNodeReader interface {
+	// Get returns the stored value of the key
+	Get(k []byte) ([]byte, bool, error)
+	// GetCode returns the contract code by hash
+	GetCode(hash types.Hash) ([]byte, bool)
+}
+
+// StateDB is the interface required by the syncer to serve and download the state
+type StateDB interface {
+	StateNodeReader
+
+	// SyncState downloads the whole state of the root from the peers
+	SyncState(ctx context.Context, root types.Hash) error
+}
+
 type Progression interface {
 	// StartProgression starts progression
 	StartProgression(syncingPeer string, startingBlock uint64, subscription blockchain.Subscription)
@@ -84,6 +120,12 @@ type SyncPeerClient interface {
 	GetConnectedPeerStatuses() []*NoForkPeer
 	// GetBlocks returns a stream of blocks from given height to peer's latest
 	GetBlocks(ctx context.Context, peerID peer.ID, from uint64, to uint64) ([]*types.Block, error)
+	// GetHeaders returns the consecutive headers from the given height, up to amount
+	GetHeaders(ctx context.Context, peerID peer.ID, from uint64, amount uint64) ([]*types.Header, error)
+	// GetReceipts returns the receipts of the blocks by hash from the peer
+	GetReceipts(ctx context.Context, peerID peer.ID, hashes []types.Hash) ([][]*types.Receipt, error)
+	// GetTrieNodes returns the trie nodes by hash from the peer, the missing ones are empty
+	GetTrieNodes(ctx context.Context, peerID peer.ID, hashes []types.Hash) ([][]byte, error)
 	// GetPeerStatusUpdateCh returns a channel of peer's status update
 	GetPeerStatusUpdateCh() <-chan *NoForkPeer
 	// GetPeerConnectionUpdateEventCh returns peer's connection change event
@@ -92,6 +134,8 @@ type SyncPeerClient interface {
 	DisablePublishingPeerStatus()
 	// EnablePublishingPeerStatus enables publishing status in syncer topic
 	EnablePublishingPeerStatus()
+	// SetPublishedHook sets the hook called once the status is published in syncer topic
+	SetPublishedHook(hook func(number uint64))
 
 	// deprecated methods
 
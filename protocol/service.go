@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/network/grpc"
@@ -14,6 +15,9 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// maxTrieNodesAmount is the max number of trie nodes served in one request
+const maxTrieNodesAmount = 128
+
 var (
 	errBlockNotFound         = errors.New("block not found")
 	errInvalidHeadersRequest = errors.New("cannot provide both a number and a hash")
@@ -24,6 +28,7 @@ type syncPeerService struct {
 	proto.UnimplementedV1Server
 
 	blockchain Blockchain       // blockchain service
+	stateNodes StateNodeReader  // trie nodes storage, nil if not serving
 	network    network.Network  // network service
 	stream     *grpc.GrpcStream // grpc stream controlling
 
@@ -34,9 +39,11 @@ type syncPeerService struct {
 func NewSyncPeerService(
 	network network.Network,
 	blockchain Blockchain,
+	stateNodes StateNodeReader,
 ) SyncPeerService {
 	return &syncPeerService{
 		blockchain: blockchain,
+		stateNodes: stateNodes,
 		network:    network,
 	}
 }
@@ -57,7 +64,15 @@ func (s *syncPeerService) SetSyncer(syncer *noForkSyncer) {
 
 // setupGRPCServer setup GRPC server
 func (s *syncPeerService) setupGRPCServer() {
-	s.stream = grpc.NewGrpcStream(context.TODO())
+	// the bulk sync is served with a bounded concurrency, so that a peer syncing
+	// from the node could not saturate it and delay the consensus messages. The
+	// status is cheap and polled by the syncing peers, so it is never starved.
+	s.stream = grpc.NewGrpcStream(
+		context.TODO(),
+		grpc.WithRequestTimeout(_syncRequestTimeout),
+		grpc.WithMaxConcurrentRequests(_maxSyncRequests),
+		grpc.WithUnlimitedMethods(_getStatusMethod),
+	)
 
 	proto.RegisterV1Server(s.stream.GrpcServer(), s)
 	s.stream.Serve()
@@ -67,8 +82,16 @@ func (s *syncPeerService) setupGRPCServer() {
 const (
 	// _minCompressSize = 4 * 1024        // 4k
 	_maxSendingSize = 8 * 1024 * 1024 // = 8M => 2-4M after compression, which is reasonable
+
+	// deadline of the served sync requests
+	_syncRequestTimeout = 30 * time.Second
+	// sync requests served concurrently
+	_maxSyncRequests = 8
 )
 
+// _getStatusMethod is the full method of GetStatus, exempted from the concurrency limit
+var _getStatusMethod = "/" + proto.V1_ServiceDesc.ServiceName + "/GetStatus"
+
 // GetBlocks is a gRPC endpoint to return blocks from the specific height
 //
 // It is designed forward and backward competible.
@@ -90,6 +113,11 @@ func (s *syncPeerService) GetBlocks(
 	)
 
 	for to := req.From; to <= req.To; to++ {
+		// stop serving once the request deadline is exceeded
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		block, ok := s.blockchain.GetBlockByNumber(to, true)
 		if !ok {
 			return nil, errBlockNotFound
@@ -150,6 +178,10 @@ func (s *syncPeerService) GetObjectsByHash(_ context.Context, req *proto.HashReq
 		return nil, err
 	}
 
+	if req.Type == proto.HashRequest_TRIE_NODES {
+		return s.getTrieNodes(hashes), nil
+	}
+
 	resp := &proto.Response{
 		Objs: []*proto.Response_Component{},
 	}
@@ -192,8 +224,44 @@ func (s *syncPeerService) GetObjectsByHash(_ context.Context, req *proto.HashReq
 	return resp, nil
 }
 
+// getTrieNodes returns the encoded trie nodes, the missing ones are empty. The
+// contract codes are addressed by their hashes as well, so they are served too.
+func (s *syncPeerService) getTrieNodes(hashes []types.Hash) *proto.Response {
+	if len(hashes) > maxTrieNodesAmount {
+		hashes = hashes[:maxTrieNodesAmount]
+	}
+
+	resp := &proto.Response{
+		Objs: make([]*proto.Response_Component, 0, len(hashes)),
+	}
+
+	for _, hash := range hashes {
+		data := []byte{}
+
+		if s.stateNodes != nil {
+			if v, ok, err := s.stateNodes.Get(hash.Bytes()); err == nil && ok {
+				data = v
+			} else if code, ok := s.stateNodes.GetCode(hash); ok {
+				data = code
+			}
+		}
+
+		resp.Objs = append(resp.Objs, &proto.Response_Component{
+			Spec: &anypb.Any{
+				Value: data,
+			},
+		})
+	}
+
+	return resp
+}
+
 const maxSkeletonHeadersAmount = 190
 
+// maxSkeletonHashesSpan is the most canonical hashes read at once for the skipped
+// headers, the wider skeletons are looked up header by header
+const maxSkeletonHashesSpan = 190 * 192
+
 // GetHeaders implements the V1Server interface
 func (s *syncPeerService) GetHeaders(_ context.Context, req *proto.GetHeadersRequest) (*proto.Response, error) {
 	if req.Number != 0 && req.Hash != "" {
@@ -240,6 +308,33 @@ func (s *syncPeerService) GetHeaders(_ context.Context, req *proto.GetHeadersReq
 	// resp
 	addData(origin)
 
+	// the consecutive headers are read within one storage iteration
+	if skip == 1 {
+		if req.Amount > 1 {
+			for _, h := range s.blockchain.GetHeadersInRange(origin.Number+1, origin.Number+uint64(req.Amount-1)) {
+				addData(h)
+			}
+		}
+
+		return resp, nil
+	}
+
+	// the skipped headers are picked out of the canonical hashes of the span
+	if skip > 1 && req.Amount > 1 && skip <= maxSkeletonHashesSpan/(req.Amount-1) {
+		hashes := s.blockchain.GetCanonicalHashesInRange(origin.Number+1, origin.Number+uint64(skip*(req.Amount-1)))
+
+		for i := skip - 1; i < int64(len(hashes)); i += skip {
+			h, ok := s.blockchain.GetHeaderByHash(hashes[i])
+			if !ok {
+				break
+			}
+
+			addData(h)
+		}
+
+		return resp, nil
+	}
+
 	for count := int64(1); count < req.Amount; {
 		block := int64(origin.Number) + skip
 
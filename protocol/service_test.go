@@ -157,3 +157,82 @@ func TestGetStatus(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, headerNumber, status.Number)
 }
+
+func TestGetHeaders(t *testing.T) {
+	t.Parallel()
+
+	blocks := createMockBlocks(10)
+
+	// the skipped headers are looked up by hash
+	for _, b := range blocks {
+		b.Header.ComputeHash()
+	}
+
+	service := &syncPeerService{
+		blockchain: &mockBlockchain{
+			blocks: blocks,
+		},
+	}
+
+	numbers := func(resp *proto.Response) []uint64 {
+		res := make([]uint64, 0, len(resp.Objs))
+
+		for _, obj := range resp.Objs {
+			header := &types.Header{}
+			assert.NoError(t, header.UnmarshalRLP(obj.Spec.Value))
+
+			res = append(res, header.Number)
+		}
+
+		return res
+	}
+
+	tests := []struct {
+		name     string
+		req      *proto.GetHeadersRequest
+		expected []uint64
+	}{
+		{
+			name:     "consecutive headers",
+			req:      &proto.GetHeadersRequest{Number: 3, Amount: 4},
+			expected: []uint64{3, 4, 5, 6},
+		},
+		{
+			name:     "consecutive headers stop at the head",
+			req:      &proto.GetHeadersRequest{Number: 8, Amount: 5},
+			expected: []uint64{8, 9, 10},
+		},
+		{
+			name:     "skipped headers",
+			req:      &proto.GetHeadersRequest{Number: 2, Skip: 1, Amount: 3},
+			expected: []uint64{2, 4, 6},
+		},
+		{
+			name:     "skipped headers stop at the head",
+			req:      &proto.GetHeadersRequest{Number: 6, Skip: 1, Amount: 4},
+			expected: []uint64{6, 8, 10},
+		},
+		{
+			name:     "the origin only",
+			req:      &proto.GetHeadersRequest{Number: 5, Amount: 1},
+			expected: []uint64{5},
+		},
+		{
+			name:     "missing origin",
+			req:      &proto.GetHeadersRequest{Number: 11, Amount: 2},
+			expected: []uint64{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := service.GetHeaders(context.Background(), test.req)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, numbers(resp))
+		})
+	}
+}
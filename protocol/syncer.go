@@ -9,8 +9,11 @@ import (
 	"time"
 
 	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/crypto"
 	"github.com/dogechain-lab/dogechain/helper/common"
 	"github.com/dogechain-lab/dogechain/helper/progress"
+	"github.com/dogechain-lab/dogechain/helper/recovery"
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/network/event"
 	"github.com/dogechain-lab/dogechain/types"
@@ -38,6 +41,10 @@ const (
 	_blockSyncStep = 100
 
 	_blockSyncTimeout = 30 * time.Second
+
+	// max peers asked for the missing trie nodes
+	_trieNodesFetchPeers   = 3
+	_trieNodesFetchTimeout = 5 * time.Second
 )
 
 var (
@@ -51,8 +58,13 @@ var (
 	ErrDecodeDifficulty       = errors.New("failed to decode difficulty")
 	ErrInvalidTypeAssertion   = errors.New("invalid type assertion")
 	ErrBlockVerifyFailed      = errors.New("block verifying failed")
+	ErrCheckpointMismatch     = errors.New("peer does not have the trusted checkpoint")
+	ErrAnchorMismatch         = errors.New("headers are not linked to the trusted checkpoint")
+	ErrTrieNodesNotFound      = errors.New("trie nodes not found from peers")
+	ErrNoCheckpointState      = errors.New("no state database to sync the checkpoint state to")
 
-	errTimeout = errors.New("timeout awaiting block from peer")
+	errTimeout          = errors.New("timeout awaiting block from peer")
+	errPeerBehindAnchor = errors.New("peer is behind the anchored headers")
 )
 
 // blocks sorted by number (ascending)
@@ -112,6 +124,20 @@ type noForkSyncer struct {
 	selfID peer.ID
 	// broadcasting block flag for backward compatible nodes
 	blockBroadcast bool
+
+	// trusted checkpoint, nil if not configured
+	checkpoint *chain.Checkpoint
+	// peers serving the checkpoint block, only accessed by the syncing routine
+	checkpointPeers map[peer.ID]struct{}
+
+	// state database the checkpoint state is downloaded to, nil if not set
+	stateDB StateDB
+}
+
+// anchor is a block hash linked to the trusted checkpoint by the parent hashes
+type anchor struct {
+	number uint64
+	hash   types.Hash
 }
 
 // NewSyncer creates a new Syncer instance
@@ -120,6 +146,8 @@ func NewSyncer(
 	server network.Network,
 	blockchain Blockchain,
 	enableBlockBroadcast bool,
+	checkpoint *chain.Checkpoint,
+	stateDB StateDB,
 ) Syncer {
 	s := &noForkSyncer{
 		logger: logger.Named(_syncerName),
@@ -129,7 +157,7 @@ func NewSyncer(
 
 		syncProgression: progress.NewProgressionWrapper(progress.ChainSyncBulk),
 		peerMap:         new(PeerMap),
-		syncPeerService: NewSyncPeerService(server, blockchain),
+		syncPeerService: NewSyncPeerService(server, blockchain, stateDB),
 		syncPeerClient:  NewSyncPeerClient(logger, server, blockchain),
 		newStatusCh:     make(chan struct{}, 1),
 		syncing:         atomic.NewBool(false),
@@ -138,6 +166,9 @@ func NewSyncer(
 		server:          server,
 		selfID:          server.AddrInfo().ID,
 		blockBroadcast:  enableBlockBroadcast,
+		checkpoint:      checkpoint,
+		checkpointPeers: make(map[peer.ID]struct{}),
+		stateDB:         stateDB,
 	}
 
 	// set reference instance
@@ -265,13 +296,13 @@ func (s *noForkSyncer) Start() error {
 	// init peer list
 	s.initializePeerMap()
 
-	// process
-	go s.startPeerStatusUpdateProcess()
-	go s.startPeerConnectionEventProcess()
+	// process, the loops over the peer events are restarted after a panic
+	recovery.GoRestartable(s.logger, "syncer peer status", s.startPeerStatusUpdateProcess)
+	recovery.GoRestartable(s.logger, "syncer peer connection", s.startPeerConnectionEventProcess)
 
 	// Run the blockchain event listener loop
 	// deprecated, only for backward compatibility
-	go s.runUpdateCurrentStatus()
+	recovery.Go(s.logger, "syncer status", s.runUpdateCurrentStatus)
 
 	return nil
 }
@@ -286,6 +317,11 @@ func (s *noForkSyncer) Close() error {
 	return nil
 }
 
+// SetPublishedHook sets the hook called once the new head is published to the peers
+func (s *noForkSyncer) SetPublishedHook(hook func(number uint64)) {
+	s.syncPeerClient.SetPublishedHook(hook)
+}
+
 // HasSyncPeer returns whether syncer has the peer to syncs blocks
 // return false if syncer has no peer whose latest block height doesn't exceed local height
 func (s *noForkSyncer) HasSyncPeer() bool {
@@ -295,6 +331,73 @@ func (s *noForkSyncer) HasSyncPeer() bool {
 	return bestPeer != nil && bestPeer.Number > header.Number
 }
 
+// FetchTrieNodes fetches the trie nodes by hash from the best peers. The nodes are
+// verified by their hashes, the missing ones are asked from the next peer.
+func (s *noForkSyncer) FetchTrieNodes(hashes []types.Hash) ([][]byte, error) {
+	nodes := make([][]byte, len(hashes))
+	skipList := make(map[peer.ID]int64)
+
+	for i := 0; i < _trieNodesFetchPeers; i++ {
+		bestPeer := s.peerMap.BestPeer(&skipList)
+		if bestPeer == nil {
+			break
+		}
+
+		skipList[bestPeer.ID] = 0
+
+		missing := make([]types.Hash, 0, len(hashes))
+
+		for j, hash := range hashes {
+			if len(nodes[j]) == 0 {
+				missing = append(missing, hash)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), _trieNodesFetchTimeout)
+		fetched, err := s.syncPeerClient.GetTrieNodes(ctx, bestPeer.ID, missing)
+
+		cancel()
+
+		if err != nil {
+			s.logger.Debug("failed to fetch trie nodes", "peer", bestPeer.ID, "err", err)
+
+			continue
+		}
+
+		for j, data := range fetched {
+			if j >= len(missing) || len(data) == 0 || types.BytesToHash(crypto.Keccak256(data)) != missing[j] {
+				continue
+			}
+
+			for k, hash := range hashes {
+				if hash == missing[j] {
+					nodes[k] = data
+				}
+			}
+		}
+
+		if allTrieNodesFetched(nodes) {
+			return nodes, nil
+		}
+	}
+
+	if allTrieNodesFetched(nodes) {
+		return nodes, nil
+	}
+
+	return nodes, ErrTrieNodesNotFound
+}
+
+func allTrieNodesFetched(nodes [][]byte) bool {
+	for _, data := range nodes {
+		if len(data) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Sync syncs block with the best peer until callback returns true
 func (s *noForkSyncer) Sync(callback func(*types.Block) bool) error {
 	// skipList is used to skip the peer that has been tried failed
@@ -440,9 +543,44 @@ func (s *noForkSyncer) bulkSyncWithPeer(
 		return result, nil
 	}
 
+	// the history before the checkpoint is linked to it backwards first, which takes
+	// many requests, so it is not bounded by the fetching timeout
+	var (
+		anchored = s.checkpoint != nil && from <= s.checkpoint.Number
+		top      anchor
+	)
+
+	if anchored {
+		var err error
+
+		if top, err = s.anchorHeaders(p, from); err != nil {
+			s.logger.Warn("anchoring headers failed", "peer", p.ID, "err", err)
+
+			return result, s.skipPeer(result, p, err)
+		}
+	}
+
+	// the blocks above the checkpoint are executed on its state, which is not built
+	// from the history, so the whole state is downloaded first
+	if s.checkpoint != nil && from == s.checkpoint.Number+1 {
+		if err := s.syncCheckpointState(); err != nil {
+			s.logger.Warn("checkpoint state syncing failed", "root", s.checkpoint.StateRoot, "err", err)
+
+			return result, err
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), _blockSyncTimeout)
 	defer cancel()
 
+	// only sync the history before the checkpoint from the peers on the checkpoint
+	// chain, the others are dropped early
+	if err := s.verifyCheckpointPeer(ctx, p, from); err != nil {
+		s.logger.Warn("peer checkpoint verifying failed", "peer", p.ID, "err", err)
+
+		return result, s.skipPeer(result, p, err)
+	}
+
 	// sync up to the current known header
 	to := from + _blockSyncStep - 1
 	if to > target {
@@ -450,6 +588,12 @@ func (s *noForkSyncer) bulkSyncWithPeer(
 		to = target
 	}
 
+	// the blocks before the checkpoint are synced up to the anchor above, and must be
+	// linked to it
+	if anchored {
+		to = top.number
+	}
+
 	s.logger.Info("sync up to block", "peer", p.ID, "from", from, "to", to)
 
 	blocks, err := s.syncPeerClient.GetBlocks(ctx, p.ID, from, to)
@@ -480,11 +624,46 @@ func (s *noForkSyncer) bulkSyncWithPeer(
 		return result, nil
 	}
 
+	var receipts [][]*types.Receipt
+
+	if anchored {
+		receipts, err = s.fetchAnchoredReceipts(ctx, p, blocks, from, top)
+		if err != nil {
+			s.logger.Warn("anchored blocks fetching failed", "peer", p.ID, "err", err)
+
+			return result, s.skipPeer(result, p, err)
+		}
+	}
+
+	// the import delay is bounded by the syncer lifetime, not the fetching timeout
+	admissionCtx, cancelAdmission := context.WithCancel(context.Background())
+	defer cancelAdmission()
+
+	go func() {
+		select {
+		case <-s.stopCh:
+			cancelAdmission()
+		case <-admissionCtx.Done():
+		}
+	}()
+
 	// write block
-	for _, block := range blocks {
-		if err := s.blockchain.VerifyFinalizedBlock(block); err != nil {
+	for i, block := range blocks {
+		// slow down the catch-up rather than running out of resources
+		if err := s.blockchain.AwaitImportAdmission(admissionCtx); err != nil {
+			return result, err
+		}
+
+		if anchored {
+			err = s.blockchain.VerifyAnchoredBlock(block, receipts[i])
+		} else {
+			err = s.blockchain.VerifyFinalizedBlock(block)
+		}
+
+		if err != nil {
 			// not the same network or bad peer
-			s.logger.Error("block verifying failed", "peer", p.ID, "err", err)
+			logArgs := append([]interface{}{"peer", p.ID}, blockchain.VerifyErrorLogArgs(err)...)
+			s.logger.Error("block verifying failed", logArgs...)
 
 			result.SkipList[p.ID] = time.Now().Add(time.Hour).Unix()
 
@@ -522,6 +701,211 @@ func (s *noForkSyncer) bulkSyncWithPeer(
 	return result, nil
 }
 
+// verifyCheckpointPeer makes sure the peer serves the trusted checkpoint block,
+// when syncing the blocks before the checkpoint
+func (s *noForkSyncer) verifyCheckpointPeer(ctx context.Context, p *NoForkPeer, from uint64) error {
+	checkpoint := s.checkpoint
+
+	// peers behind the checkpoint can not be verified
+	if checkpoint == nil || from > checkpoint.Number || p.Number < checkpoint.Number {
+		return nil
+	}
+
+	if _, ok := s.checkpointPeers[p.ID]; ok {
+		return nil
+	}
+
+	blocks, err := s.syncPeerClient.GetBlocks(ctx, p.ID, checkpoint.Number, checkpoint.Number)
+	if err != nil {
+		return err
+	}
+
+	if len(blocks) != 1 || blocks[0].Hash() != checkpoint.Hash {
+		return ErrCheckpointMismatch
+	}
+
+	s.checkpointPeers[p.ID] = struct{}{}
+
+	return nil
+}
+
+// syncCheckpointState downloads the state of the trusted checkpoint from the peers,
+// it returns at once if the state is complete. The download takes long, so it is
+// only bounded by the syncer lifetime.
+func (s *noForkSyncer) syncCheckpointState() error {
+	if s.stateDB == nil {
+		return ErrNoCheckpointState
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-s.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return s.stateDB.SyncState(ctx, s.checkpoint.StateRoot)
+}
+
+// skipPeer puts the peer into the skip list for the error, the peers off the trusted
+// checkpoint chain are dropped for long
+func (s *noForkSyncer) skipPeer(result *bulkSyncResult, p *NoForkPeer, err error) error {
+	if errors.Is(err, ErrCheckpointMismatch) || errors.Is(err, ErrAnchorMismatch) {
+		result.SkipList[p.ID] = time.Now().Add(time.Hour).Unix()
+
+		// if server is nil, it running in test mode
+		if s.server != nil {
+			s.server.ForgetPeer(p.ID, err.Error())
+		}
+	} else {
+		result.SkipList[p.ID] = time.Now().Add(
+			time.Duration(_skipListTTL+common.SecureRandInt(_skipListRandTTLRange)) * time.Second,
+		).Unix()
+	}
+
+	return err
+}
+
+// anchorHeaders returns the anchor the blocks from the given height are synced up to.
+// The anchors are spaced by the sync step below the checkpoint, each one is linked to
+// the one above by the headers fetched backwards from the peer. They are persisted, so
+// the linking resumes from the lowest one after a restart, and the blocks synced
+// forward are linked to them without fetching the headers again.
+func (s *noForkSyncer) anchorHeaders(p *NoForkPeer, from uint64) (anchor, error) {
+	checkpoint := s.checkpoint
+
+	// the anchor of the height is the lowest one not below it
+	steps := (checkpoint.Number - from) / _blockSyncStep
+
+	// the anchors are written downwards from the checkpoint, find the lowest one
+	stored := uint64(sort.Search(int(steps), func(i int) bool {
+		_, ok := s.blockchain.ReadCheckpointAnchor(checkpoint.Number - uint64(i+1)*_blockSyncStep)
+
+		return !ok
+	}))
+
+	lowest := anchor{number: checkpoint.Number - stored*_blockSyncStep, hash: checkpoint.Hash}
+
+	if stored > 0 {
+		hash, ok := s.blockchain.ReadCheckpointAnchor(lowest.number)
+		if !ok {
+			return anchor{}, ErrAnchorMismatch
+		}
+
+		lowest.hash = hash
+	}
+
+	for ; ; stored++ {
+		if p.Number < lowest.number {
+			return anchor{}, errPeerBehindAnchor
+		}
+
+		if stored == steps {
+			return lowest, nil
+		}
+
+		select {
+		case <-s.stopCh:
+			return anchor{}, ErrConnectionClosed
+		default:
+		}
+
+		start := lowest.number - _blockSyncStep
+
+		ctx, cancel := context.WithTimeout(context.Background(), _blockSyncTimeout)
+		headers, err := s.fetchLinkedHeaders(ctx, p, start, lowest)
+
+		cancel()
+
+		if err != nil {
+			return anchor{}, err
+		}
+
+		if err := s.blockchain.WriteCheckpointAnchor(start, headers[0].Hash); err != nil {
+			return anchor{}, err
+		}
+
+		s.logger.Debug("anchored headers", "peer", p.ID, "from", start, "to", lowest.number)
+
+		lowest = anchor{number: start, hash: headers[0].Hash}
+	}
+}
+
+// fetchLinkedHeaders fetches the headers within [from, top] from the peer, and makes
+// sure they are linked to the top anchor by the parent hashes
+func (s *noForkSyncer) fetchLinkedHeaders(
+	ctx context.Context,
+	p *NoForkPeer,
+	from uint64,
+	top anchor,
+) ([]*types.Header, error) {
+	headers, err := s.syncPeerClient.GetHeaders(ctx, p.ID, from, top.number-from+1)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := linkHeaders(headers, from, top); err != nil {
+		return nil, err
+	}
+
+	return headers, nil
+}
+
+// fetchAnchoredReceipts makes sure the blocks from the given height are linked to the
+// top anchor by the parent hashes, and fetches their receipts from the peer
+func (s *noForkSyncer) fetchAnchoredReceipts(
+	ctx context.Context,
+	p *NoForkPeer,
+	blocks []*types.Block,
+	from uint64,
+	top anchor,
+) ([][]*types.Receipt, error) {
+	headers := make([]*types.Header, len(blocks))
+	hashes := make([]types.Hash, len(blocks))
+
+	for i, block := range blocks {
+		headers[i] = block.Header
+		hashes[i] = block.Hash()
+	}
+
+	if err := linkHeaders(headers, from, top); err != nil {
+		return nil, err
+	}
+
+	return s.syncPeerClient.GetReceipts(ctx, p.ID, hashes)
+}
+
+// linkHeaders makes sure the headers are the ones within [from, top], linked to the
+// top anchor by the parent hashes
+func linkHeaders(headers []*types.Header, from uint64, top anchor) error {
+	if uint64(len(headers)) != top.number-from+1 {
+		return errPeerBehindAnchor
+	}
+
+	for i, header := range headers {
+		if header.Number != from+uint64(i) {
+			return ErrAnchorMismatch
+		}
+	}
+
+	// the header hashes are computed locally, not served by the peer
+	if headers[len(headers)-1].Hash != top.hash {
+		return ErrAnchorMismatch
+	}
+
+	for i := len(headers) - 1; i > 0; i-- {
+		if headers[i].ParentHash != headers[i-1].Hash {
+			return ErrAnchorMismatch
+		}
+	}
+
+	return nil
+}
+
 func blockNearEnough(a, b uint64) bool {
 	const nearBlockHeight = 1
 
@@ -559,7 +943,9 @@ func (s *noForkSyncer) startPeerConnectionEventProcess() {
 
 		switch e.Type {
 		case event.PeerConnected:
-			go s.initNewPeerStatus(peerID)
+			recovery.Go(s.logger, "syncer new peer status", func() {
+				s.initNewPeerStatus(peerID)
+			})
 		case event.PeerDisconnected:
 			s.removeFromPeerMap(peerID)
 		}
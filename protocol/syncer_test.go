@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/chain"
 	"github.com/dogechain-lab/dogechain/helper/progress"
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/network/event"
@@ -53,6 +54,8 @@ type mockSyncPeerClient struct {
 	getPeerStatusHandler                  func(peer.ID) (*NoForkPeer, error)
 	getConnectedPeerStatusesHandler       func() []*NoForkPeer
 	getBlocksHandler                      func(context.Context, peer.ID, uint64, uint64) ([]*types.Block, error)
+	getHeadersHandler                     func(context.Context, peer.ID, uint64, uint64) ([]*types.Header, error)
+	getReceiptsHandler                    func(context.Context, peer.ID, []types.Hash) ([][]*types.Receipt, error)
 	getPeerStatusUpdateChHandler          func() <-chan *NoForkPeer
 	getPeerConnectionUpdateEventChHandler func() <-chan *event.PeerEvent
 }
@@ -61,6 +64,8 @@ func (m *mockSyncPeerClient) DisablePublishingPeerStatus() {}
 
 func (m *mockSyncPeerClient) EnablePublishingPeerStatus() {}
 
+func (m *mockSyncPeerClient) SetPublishedHook(hook func(number uint64)) {}
+
 func (m *mockSyncPeerClient) Start() error {
 	return nil
 }
@@ -84,6 +89,51 @@ func (m *mockSyncPeerClient) GetBlocks(
 	return m.getBlocksHandler(ctx, id, from, to)
 }
 
+func (m *mockSyncPeerClient) GetHeaders(
+	ctx context.Context,
+	id peer.ID,
+	from uint64,
+	amount uint64,
+) ([]*types.Header, error) {
+	return m.getHeadersHandler(ctx, id, from, amount)
+}
+
+func (m *mockSyncPeerClient) GetReceipts(
+	ctx context.Context,
+	id peer.ID,
+	hashes []types.Hash,
+) ([][]*types.Receipt, error) {
+	return m.getReceiptsHandler(ctx, id, hashes)
+}
+
+func (m *mockSyncPeerClient) GetTrieNodes(
+	ctx context.Context,
+	id peer.ID,
+	hashes []types.Hash,
+) ([][]byte, error) {
+	return nil, nil
+}
+
+// mockStateDB records the state roots synced
+type mockStateDB struct {
+	synced       []types.Hash
+	syncStateErr error
+}
+
+func (m *mockStateDB) Get(k []byte) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func (m *mockStateDB) GetCode(hash types.Hash) ([]byte, bool) {
+	return nil, false
+}
+
+func (m *mockStateDB) SyncState(ctx context.Context, root types.Hash) error {
+	m.synced = append(m.synced, root)
+
+	return m.syncStateErr
+}
+
 func (m *mockSyncPeerClient) GetPeerStatusUpdateCh() <-chan *NoForkPeer {
 	return m.getPeerStatusUpdateChHandler()
 }
@@ -141,6 +191,7 @@ func NewTestSyncer(
 		peerMap:         new(PeerMap),
 		syncing:         atomic.NewBool(false),
 		syncingPeer:     atomic.NewString(""),
+		checkpointPeers: make(map[peer.ID]struct{}),
 	}
 }
 
@@ -795,3 +846,360 @@ func Test_bulkSyncWithPeer(t *testing.T) {
 		})
 	}
 }
+
+func Test_verifyCheckpointPeer(t *testing.T) {
+	t.Parallel()
+
+	checkpoint := &chain.Checkpoint{
+		Number: 100,
+		Hash:   types.StringToHash("1"),
+	}
+
+	tests := []struct {
+		name string
+
+		// local
+		from uint64
+
+		// peer
+		peerHeight uint64
+		blockHash  types.Hash
+
+		// results
+		requested bool
+		err       error
+	}{
+		{
+			name:       "should accept the peer serving the checkpoint",
+			from:       1,
+			peerHeight: 200,
+			blockHash:  checkpoint.Hash,
+			requested:  true,
+			err:        nil,
+		},
+		{
+			name:       "should reject the peer on another chain",
+			from:       1,
+			peerHeight: 200,
+			blockHash:  types.StringToHash("2"),
+			requested:  true,
+			err:        ErrCheckpointMismatch,
+		},
+		{
+			name:       "should skip the peer behind the checkpoint",
+			from:       1,
+			peerHeight: 50,
+			requested:  false,
+			err:        nil,
+		},
+		{
+			name:       "should skip verifying once the checkpoint is synced",
+			from:       101,
+			peerHeight: 200,
+			requested:  false,
+			err:        nil,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			requested := false
+
+			syncer := NewTestSyncer(
+				nil,
+				nil,
+				&mockSyncPeerClient{
+					getBlocksHandler: func(ctx context.Context, id peer.ID, start, end uint64) ([]*types.Block, error) {
+						requested = true
+
+						assert.Equal(t, checkpoint.Number, start)
+						assert.Equal(t, checkpoint.Number, end)
+
+						return []*types.Block{
+							{
+								Header: &types.Header{
+									Number: checkpoint.Number,
+									Hash:   test.blockHash,
+								},
+							},
+						}, nil
+					},
+				},
+				&mockProgression{},
+			)
+			syncer.checkpoint = checkpoint
+
+			p := &NoForkPeer{
+				ID:     peer.ID("A"),
+				Number: test.peerHeight,
+			}
+
+			assert.ErrorIs(t, syncer.verifyCheckpointPeer(context.Background(), p, test.from), test.err)
+			assert.Equal(t, test.requested, requested)
+
+			if test.err == nil && test.requested {
+				// verified peers are not requested again
+				requested = false
+
+				assert.NoError(t, syncer.verifyCheckpointPeer(context.Background(), p, test.from))
+				assert.False(t, requested)
+			}
+		})
+	}
+}
+
+func Test_anchorHeaders(t *testing.T) {
+	t.Parallel()
+
+	// 0 to 250, linked by the parent hashes
+	headers := blockchain.NewTestHeaders(251)
+	forged := blockchain.NewTestHeadersWithSeed(nil, 251, 1)
+
+	tests := []struct {
+		name string
+
+		// local
+		from    uint64
+		anchors []uint64
+
+		// peer
+		peerHeight    uint64
+		servedHeaders []*types.Header
+
+		// results
+		top       uint64
+		requested []uint64
+		stored    []uint64
+		err       error
+	}{
+		{
+			name:          "should link the headers down to the anchor of the local head",
+			from:          1,
+			peerHeight:    250,
+			servedHeaders: headers,
+			top:           50,
+			requested:     []uint64{150, 50},
+			stored:        []uint64{150, 50},
+			err:           nil,
+		},
+		{
+			name:          "should resume from the lowest stored anchor",
+			from:          1,
+			anchors:       []uint64{150},
+			peerHeight:    250,
+			servedHeaders: headers,
+			top:           50,
+			requested:     []uint64{50},
+			stored:        []uint64{150, 50},
+			err:           nil,
+		},
+		{
+			name:          "should not fetch the stored anchors again",
+			from:          51,
+			anchors:       []uint64{150, 50},
+			peerHeight:    250,
+			servedHeaders: headers,
+			top:           150,
+			requested:     []uint64{},
+			stored:        []uint64{150, 50},
+			err:           nil,
+		},
+		{
+			name:          "should sync up to the checkpoint from the last step",
+			from:          151,
+			peerHeight:    250,
+			servedHeaders: headers,
+			top:           250,
+			requested:     []uint64{},
+			stored:        []uint64{},
+			err:           nil,
+		},
+		{
+			name:          "should reject the headers off the checkpoint",
+			from:          1,
+			peerHeight:    250,
+			servedHeaders: forged,
+			requested:     []uint64{150},
+			stored:        []uint64{},
+			err:           ErrAnchorMismatch,
+		},
+		{
+			name:          "should skip the peer behind the checkpoint",
+			from:          1,
+			peerHeight:    200,
+			servedHeaders: headers,
+			requested:     []uint64{},
+			stored:        []uint64{},
+			err:           errPeerBehindAnchor,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			mock := &mockBlockchain{}
+			for _, n := range test.anchors {
+				assert.NoError(t, mock.WriteCheckpointAnchor(n, headers[n].Hash))
+			}
+
+			requested := []uint64{}
+
+			syncer := NewTestSyncer(
+				nil,
+				mock,
+				&mockSyncPeerClient{
+					getHeadersHandler: func(ctx context.Context, id peer.ID, from, amount uint64) ([]*types.Header, error) {
+						requested = append(requested, from)
+
+						return test.servedHeaders[from : from+amount], nil
+					},
+				},
+				&mockProgression{},
+			)
+			syncer.checkpoint = &chain.Checkpoint{
+				Number: 250,
+				Hash:   headers[250].Hash,
+			}
+
+			top, err := syncer.anchorHeaders(&NoForkPeer{ID: peer.ID("A"), Number: test.peerHeight}, test.from)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.requested, requested)
+
+			if test.err == nil {
+				assert.Equal(t, test.top, top.number)
+				assert.Equal(t, headers[test.top].Hash, top.hash)
+			}
+
+			stored := []uint64{}
+
+			for _, n := range []uint64{150, 50} {
+				if hash, ok := mock.ReadCheckpointAnchor(n); ok {
+					assert.Equal(t, headers[n].Hash, hash)
+
+					stored = append(stored, n)
+				}
+			}
+
+			assert.Equal(t, test.stored, stored)
+		})
+	}
+}
+
+func Test_bulkSyncWithPeer_Anchored(t *testing.T) {
+	t.Parallel()
+
+	// 0 to 150, linked by the parent hashes
+	headers := blockchain.NewTestHeaders(151)
+	blocks := blockchain.HeadersToBlocks(headers)
+
+	var (
+		anchoredBlocks = make([]*types.Block, 0)
+		syncedBlocks   = make([]*types.Block, 0)
+		latest         = uint64(0)
+		headerRequests = 0
+	)
+
+	syncer := NewTestSyncer(
+		nil,
+		&mockBlockchain{
+			headerHandler: func() *types.Header {
+				return headers[latest]
+			},
+			verifyFinalizedBlockHandler: func(b *types.Block) error {
+				t.Fatalf("block %d before the checkpoint is fully verified", b.Number())
+
+				return nil
+			},
+			verifyAnchoredBlockHandler: func(b *types.Block, receipts []*types.Receipt) error {
+				anchoredBlocks = append(anchoredBlocks, b)
+
+				return nil
+			},
+			writeBlockHandler: func(b *types.Block) error {
+				syncedBlocks = append(syncedBlocks, b)
+				latest = b.Number()
+
+				return nil
+			},
+		},
+		&mockSyncPeerClient{
+			getHeadersHandler: func(ctx context.Context, id peer.ID, from, amount uint64) ([]*types.Header, error) {
+				headerRequests++
+
+				return headers[from : from+amount], nil
+			},
+			getBlocksHandler: func(ctx context.Context, id peer.ID, start, end uint64) ([]*types.Block, error) {
+				return blocks[start : end+1], nil
+			},
+			getReceiptsHandler: func(ctx context.Context, id peer.ID, hashes []types.Hash) ([][]*types.Receipt, error) {
+				return make([][]*types.Receipt, len(hashes)), nil
+			},
+		},
+		&mockProgression{},
+	)
+	syncer.checkpoint = &chain.Checkpoint{
+		Number:    150,
+		Hash:      headers[150].Hash,
+		StateRoot: types.StringToHash("150"),
+	}
+
+	p := &NoForkPeer{ID: peer.ID("A"), Number: 200}
+
+	// synced up to the lowest anchor above the head
+	result, err := syncer.bulkSyncWithPeer(p, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(50), result.LastReceivedNumber)
+
+	// then up to the checkpoint
+	result, err = syncer.bulkSyncWithPeer(p, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(150), result.LastReceivedNumber)
+
+	assert.Equal(t, blocks[1:], syncedBlocks)
+	assert.Equal(t, syncedBlocks, anchoredBlocks)
+
+	// the headers are only fetched to link the anchor below the checkpoint, the blocks
+	// are linked to the anchors as they are
+	assert.Equal(t, 1, headerRequests)
+
+	// the blocks above the checkpoint are not synced before its state
+	result, err = syncer.bulkSyncWithPeer(p, nil)
+	assert.ErrorIs(t, err, ErrNoCheckpointState)
+	assert.Equal(t, uint64(0), result.LastReceivedNumber)
+
+	errPeers := errors.New("no peers")
+	stateDB := &mockStateDB{syncStateErr: errPeers}
+	syncer.stateDB = stateDB
+
+	result, err = syncer.bulkSyncWithPeer(p, nil)
+	assert.ErrorIs(t, err, errPeers)
+	assert.Equal(t, uint64(0), result.LastReceivedNumber)
+	assert.Equal(t, []types.Hash{syncer.checkpoint.StateRoot}, stateDB.synced)
+	assert.Equal(t, blocks[1:], syncedBlocks)
+
+	// the blocks served off the anchored headers are rejected
+	latest = 0
+	syncer.blockchain.(*mockBlockchain).writeBlockHandler = func(b *types.Block) error {
+		t.Fatalf("block %d off the anchored headers is written", b.Number())
+
+		return nil
+	}
+	syncer.syncPeerClient.(*mockSyncPeerClient).getBlocksHandler = func(
+		ctx context.Context,
+		id peer.ID,
+		start, end uint64,
+	) ([]*types.Block, error) {
+		return blockchain.HeadersToBlocks(blockchain.NewTestHeadersWithSeed(nil, 151, 1))[start : end+1], nil
+	}
+
+	result, err = syncer.bulkSyncWithPeer(p, nil)
+	assert.ErrorIs(t, err, ErrAnchorMismatch)
+	assert.Contains(t, result.SkipList, p.ID)
+}
@@ -1,6 +1,7 @@
 package protocol
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/dogechain-lab/dogechain/blockchain"
@@ -31,7 +32,11 @@ type mockBlockchain struct {
 	headerHandler               func() *types.Header
 	getBlockByNumberHandler     func(uint64, bool) (*types.Block, bool)
 	verifyFinalizedBlockHandler func(*types.Block) error
+	verifyAnchoredBlockHandler  func(*types.Block, []*types.Receipt) error
 	writeBlockHandler           func(*types.Block) error
+
+	// anchors linked to the checkpoint
+	anchors map[uint64]types.Hash
 }
 
 func (b *mockBlockchain) CalculateGasLimit(number uint64) (uint64, error) {
@@ -113,6 +118,31 @@ func (b *mockBlockchain) GetHeaderByNumber(n uint64) (*types.Header, bool) {
 	return nil, false
 }
 
+func (b *mockBlockchain) GetCanonicalHashesInRange(from, to uint64) []types.Hash {
+	hashes := []types.Hash{}
+
+	for _, h := range b.GetHeadersInRange(from, to) {
+		hashes = append(hashes, h.Hash)
+	}
+
+	return hashes
+}
+
+func (b *mockBlockchain) GetHeadersInRange(from, to uint64) []*types.Header {
+	headers := []*types.Header{}
+
+	for n := from; n <= to; n++ {
+		h, ok := b.GetHeaderByNumber(n)
+		if !ok {
+			break
+		}
+
+		headers = append(headers, h)
+	}
+
+	return headers
+}
+
 func (b *mockBlockchain) GetBlockByNumber(n uint64, full bool) (*types.Block, bool) {
 	if b.getBlockByNumberHandler != nil {
 		return b.getBlockByNumberHandler(n, full)
@@ -153,6 +183,34 @@ func (b *mockBlockchain) VerifyFinalizedBlock(block *types.Block) error {
 	return nil
 }
 
+func (b *mockBlockchain) VerifyAnchoredBlock(block *types.Block, receipts []*types.Receipt) error {
+	if b.verifyAnchoredBlockHandler != nil {
+		return b.verifyAnchoredBlockHandler(block, receipts)
+	}
+
+	return nil
+}
+
+func (b *mockBlockchain) ReadCheckpointAnchor(n uint64) (types.Hash, bool) {
+	hash, ok := b.anchors[n]
+
+	return hash, ok
+}
+
+func (b *mockBlockchain) WriteCheckpointAnchor(n uint64, hash types.Hash) error {
+	if b.anchors == nil {
+		b.anchors = make(map[uint64]types.Hash)
+	}
+
+	b.anchors[n] = hash
+
+	return nil
+}
+
+func (b *mockBlockchain) AwaitImportAdmission(ctx context.Context) error {
+	return nil
+}
+
 func (b *mockBlockchain) WriteBlocks(blocks []*types.Block) error {
 	for _, block := range blocks {
 		if writeErr := b.WriteBlock(block, WriteBlockSource); writeErr != nil {
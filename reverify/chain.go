@@ -6,9 +6,11 @@ import (
 	"path/filepath"
 
 	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
 	"github.com/dogechain-lab/dogechain/blockchain/storage/kvstorage"
 	"github.com/dogechain-lab/dogechain/chain"
 	"github.com/dogechain-lab/dogechain/consensus"
+	"github.com/dogechain-lab/dogechain/helper/common"
 	"github.com/dogechain-lab/dogechain/helper/kvdb"
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/secrets"
@@ -30,6 +32,23 @@ func newLevelDBBuilder(log hclog.Logger, path string) kvdb.LevelDBBuilder {
 	return leveldbBuilder
 }
 
+// newStorageBuilder returns the storage builder of the blockchain, with the separate
+// receipts database if the node keeps one, as the storage refuses to open without it
+func newStorageBuilder(log hclog.Logger, dataDir string) storage.StorageBuilder {
+	leveldbBuilder := newLevelDBBuilder(log, filepath.Join(dataDir, "blockchain"))
+
+	receiptsPath := filepath.Join(dataDir, "receipts")
+	if common.DirectoryExists(receiptsPath) {
+		return kvstorage.NewLevelDBStorageBuilderWithReceipts(
+			log,
+			leveldbBuilder,
+			newLevelDBBuilder(log, receiptsPath),
+		)
+	}
+
+	return kvstorage.NewLevelDBStorageBuilder(log, leveldbBuilder)
+}
+
 func createConsensus(
 	logger hclog.Logger,
 	genesis *chain.Chain,
@@ -121,10 +140,7 @@ func createBlockchain(
 		logger,
 		genesis,
 		0, // don't care price bottom limit when reverify.
-		kvstorage.NewLevelDBStorageBuilder(
-			logger,
-			newLevelDBBuilder(logger, filepath.Join(dataDir, "blockchain")),
-		),
+		newStorageBuilder(logger, dataDir),
 		nil,
 		executor,
 		nil,
@@ -16,25 +16,49 @@ func ReverifyChain(
 	dataDir string,
 	startHeight uint64,
 ) error {
+	_, err := reverifyChain(logger, chain, dataDir, startHeight)
+
+	return err
+}
+
+// BackfillPreimages re-executes the blocks from the start height with the preimages
+// of the state trie keys recorded, the genesis accounts are recorded as well. It
+// returns the height of the last re-executed block.
+func BackfillPreimages(
+	logger hclog.Logger,
+	chain *chain.Chain,
+	dataDir string,
+	startHeight uint64,
+) (uint64, error) {
+	return reverifyChain(logger, chain, dataDir, startHeight, itrie.WithPreimages(true))
+}
+
+func reverifyChain(
+	logger hclog.Logger,
+	chain *chain.Chain,
+	dataDir string,
+	startHeight uint64,
+	stateOpts ...itrie.StateDBOption,
+) (uint64, error) {
 	stateStorage, err := itrie.NewLevelDBStorage(
 		newLevelDBBuilder(logger, filepath.Join(dataDir, "trie")))
 	if err != nil {
 		logger.Error("failed to create state storage")
 
-		return err
+		return 0, err
 	}
 	defer stateStorage.Close()
 
 	blockchain, consensus, err := createBlockchain(
 		logger,
 		chain,
-		itrie.NewStateDB(stateStorage, hclog.NewNullLogger(), itrie.NilMetrics()),
+		itrie.NewStateDB(stateStorage, hclog.NewNullLogger(), itrie.NilMetrics(), stateOpts...),
 		dataDir,
 	)
 	if err != nil {
 		logger.Error("failed to create blockchain")
 
-		return err
+		return 0, err
 	}
 	defer blockchain.Close()
 	defer consensus.Close()
@@ -52,16 +76,16 @@ func ReverifyChain(
 	for i := startHeight; i <= currentHeight; i++ {
 		haeder, ok := blockchain.GetHeaderByNumber(i)
 		if !ok {
-			return fmt.Errorf("failed to read canonical hash, height: %d, header: %v", i, haeder)
+			return 0, fmt.Errorf("failed to read canonical hash, height: %d, header: %v", i, haeder)
 		}
 
 		block, ok := blockchain.GetBlock(haeder.Hash, i, true)
 		if !ok {
-			return fmt.Errorf("failed to read block, height: %d, header: %v", i, haeder)
+			return 0, fmt.Errorf("failed to read block, height: %d, header: %v", i, haeder)
 		}
 
 		if err := blockchain.VerifyFinalizedBlock(block); err != nil {
-			return fmt.Errorf("failed to verify block, height: %d, header: %v", i, haeder)
+			return 0, fmt.Errorf("failed to verify block, height: %d, header: %v, %w", i, haeder, err)
 		}
 
 		logger.Info("verify block success", "height", i, "hash", haeder.Hash, "txs", len(block.Transactions))
@@ -69,5 +93,5 @@ func ReverifyChain(
 
 	logger.Info(fmt.Sprintf("verify height from %d to %d \n", startHeight, currentHeight))
 
-	return nil
+	return currentHeight, nil
 }
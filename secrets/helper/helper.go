@@ -2,8 +2,11 @@ package helper
 
 import (
 	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/dogechain-lab/dogechain/crypto"
 	"github.com/dogechain-lab/dogechain/helper/common"
@@ -101,3 +104,44 @@ func InitNetworkingPrivateKey(secretsManager secrets.SecretsManager) (libp2pCryp
 
 	return libp2pKey, keyErr
 }
+
+// jsonRPCAuthSecretLength is the length of the generated json-rpc auth secret
+const jsonRPCAuthSecretLength = 32
+
+// InitJSONRPCAuthSecret generates the shared secret signing the json-rpc bearer tokens,
+// it is stored hex encoded
+func InitJSONRPCAuthSecret(secretsManager secrets.SecretsManager) ([]byte, error) {
+	secret := make([]byte, jsonRPCAuthSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	// Write the auth secret to the secrets manager storage
+	if setErr := secretsManager.SetSecret(
+		secrets.JSONRPCAuthSecret,
+		[]byte(hex.EncodeToString(secret)),
+	); setErr != nil {
+		return nil, setErr
+	}
+
+	return secret, nil
+}
+
+// ReadJSONRPCAuthSecret reads the hex encoded json-rpc auth secret
+func ReadJSONRPCAuthSecret(secretsManager secrets.SecretsManager) ([]byte, error) {
+	encoded, err := secretsManager.GetSecret(secrets.JSONRPCAuthSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(encoded)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the json-rpc auth secret, %w", err)
+	}
+
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("empty json-rpc auth secret")
+	}
+
+	return secret, nil
+}
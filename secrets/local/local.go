@@ -68,11 +68,15 @@ func SecretsManagerFactory(
 // Setup sets up the local SecretsManager
 func (l *LocalSecretsManager) Setup() error {
 	// The local SecretsManager initially handles only the
-	// validator and networking private keys
+	// validator and networking private keys, and the json-rpc auth secret
 	l.secretPathMapLock.Lock()
 	defer l.secretPathMapLock.Unlock()
 
-	subDirectories := []string{secrets.ConsensusFolderLocal, secrets.NetworkFolderLocal}
+	subDirectories := []string{
+		secrets.ConsensusFolderLocal,
+		secrets.NetworkFolderLocal,
+		secrets.JSONRPCFolderLocal,
+	}
 
 	// Set up the local directories
 	if err := common.SetupDataDir(l.path, subDirectories); err != nil {
@@ -93,6 +97,13 @@ func (l *LocalSecretsManager) Setup() error {
 		secrets.NetworkKeyLocal,
 	)
 
+	// baseDir/jsonrpc/jwt.hex
+	l.secretPathMap[secrets.JSONRPCAuthSecret] = filepath.Join(
+		l.path,
+		secrets.JSONRPCFolderLocal,
+		secrets.JSONRPCAuthSecretLocal,
+	)
+
 	return nil
 }
 
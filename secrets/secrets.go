@@ -28,18 +28,24 @@ const (
 
 	// NetworkKey is the libp2p private key secret used for networking
 	NetworkKey = "network-key"
+
+	// JSONRPCAuthSecret is the shared secret signing the bearer tokens of the JSON-RPC
+	JSONRPCAuthSecret = "jsonrpc-auth-secret"
 )
 
 // Define constant file names for the local StorageManager
 const (
 	ValidatorKeyLocal = "validator.key"
 	NetworkKeyLocal   = "libp2p.key"
+
+	JSONRPCAuthSecretLocal = "jwt.hex"
 )
 
 // Define constant folder names for the local StorageManager
 const (
 	ConsensusFolderLocal = "consensus"
 	NetworkFolderLocal   = "libp2p"
+	JSONRPCFolderLocal   = "jsonrpc"
 )
 
 var (
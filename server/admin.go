@@ -0,0 +1,484 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+const (
+	// AdminTokenMetadataKey is the gRPC metadata key of the token authenticating the
+	// admin operations of the operator service
+	AdminTokenMetadataKey = "x-admin-token"
+
+	// AdminSignatureMetadataKey is the gRPC metadata key of the operator signatures
+	// approving the admin request, one value per operator
+	AdminSignatureMetadataKey = "x-admin-signature"
+
+	// AdminExpiryMetadataKey is the gRPC metadata key of the unix time the approval
+	// of the admin request expires at, which is signed by the operators
+	AdminExpiryMetadataKey = "x-admin-expiry"
+
+	// MaxAdminApprovalTTL bounds the expiry of the approvals, so that the approved
+	// requests are not kept forever to reject their replays
+	MaxAdminApprovalTTL = time.Hour
+
+	// adminApprovalsFile is the file in the data dir keeping the used approvals until
+	// they expire, so that they are not replayed after a restart
+	adminApprovalsFile = "admin-approvals.json"
+)
+
+// adminMethods are the full gRPC methods managing the node, which require the admin
+// token and the operator approval if the approver is set
+var adminMethods = map[string]struct{}{
+	"/v1.System/PeersAdd":            {},
+	"/v1.System/WhitelistAddList":    {},
+	"/v1.System/WhitelistDeleteList": {},
+	"/v1.System/Compact":             {},
+	"/v1.System/Halt":                {},
+	"/v1.System/Resume":              {},
+}
+
+// adminRequestDomain separates the digests of the admin requests from the other
+// signed data
+var adminRequestDomain = []byte("dogechain admin request")
+
+var (
+	ErrEmptyAdminToken = errors.New("empty admin token")
+
+	errApprovalExpiryMissing = errors.New("missing approval expiry")
+	errApprovalExpiryInvalid = errors.New("invalid approval expiry")
+	errApprovalExpired       = errors.New("approval expired")
+	errApprovalExpiryTooLate = fmt.Errorf("approval expiry exceeds %s", MaxAdminApprovalTTL)
+	errApprovalMissing       = errors.New("not enough operator approvals")
+	errApprovalUsed          = errors.New("approval used already")
+)
+
+// AdminApproval is the N-of-M operator approval of the admin requests
+type AdminApproval struct {
+	Signers   []types.Address // the addresses of the operator keys
+	Threshold int             // the operator signatures required, disabled if zero
+}
+
+// AdminTarget is the node an admin request is approved for, so that the approval is
+// not replayed on the other nodes of the fleet, nor on the other chains
+type AdminTarget struct {
+	ChainID uint64
+	NodeID  string // the libp2p peer id of the node
+}
+
+// AdminRequestDigest returns the digest of the admin request the operators sign, which
+// covers the target node, the full gRPC method, the request payload and the expiry
+// of the approval
+func AdminRequestDigest(target AdminTarget, method string, req protobuf.Message, expiry int64) ([]byte, error) {
+	payload, err := protobuf.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return adminDigest(target, method, payload, expiry), nil
+}
+
+// AdminJSONRPCRequestDigest returns the digest of the admin json-rpc call the operators
+// sign, which covers the target node, the method, the compacted params and the expiry
+// of the approval
+func AdminJSONRPCRequestDigest(target AdminTarget, method string, params []byte, expiry int64) ([]byte, error) {
+	var payload bytes.Buffer
+
+	if len(params) > 0 {
+		if err := json.Compact(&payload, params); err != nil {
+			return nil, err
+		}
+	}
+
+	return adminDigest(target, method, payload.Bytes(), expiry), nil
+}
+
+func adminDigest(target AdminTarget, method string, payload []byte, expiry int64) []byte {
+	var chainIDBuf, expiryBuf [8]byte
+
+	binary.BigEndian.PutUint64(chainIDBuf[:], target.ChainID)
+	binary.BigEndian.PutUint64(expiryBuf[:], uint64(expiry))
+
+	return crypto.Keccak256(
+		adminRequestDomain,
+		chainIDBuf[:],
+		crypto.Keccak256([]byte(target.NodeID)),
+		crypto.Keccak256([]byte(method)),
+		crypto.Keccak256(payload),
+		expiryBuf[:],
+	)
+}
+
+// ReadAdminToken reads the admin token from the file, the surrounding whitespaces are trimmed
+func ReadAdminToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read admin token: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%w: %s", ErrEmptyAdminToken, path)
+	}
+
+	return token, nil
+}
+
+// adminCallerKey is the context key of the authenticated caller of the admin request
+type adminCallerKey struct{}
+
+// adminUnaryInterceptor authenticates the calls of the admin methods, the caller is
+// passed to the handler in the context
+func (s *Server) adminUnaryInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if _, ok := adminMethods[info.FullMethod]; !ok {
+		return handler(ctx, req)
+	}
+
+	msg, ok := req.(protobuf.Message)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "unexpected request of %s", info.FullMethod)
+	}
+
+	caller, err := s.authenticateAdmin(ctx, info.FullMethod, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Named("audit").Info("admin request", "method", info.FullMethod, "caller", caller)
+
+	return handler(context.WithValue(ctx, adminCallerKey{}, caller), req)
+}
+
+// adminCallerFromContext returns the authenticated caller of the admin request
+func adminCallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(adminCallerKey{}).(string)
+
+	return caller
+}
+
+// authenticateAdmin verifies the admin token and the operator approval of the request
+// of the full gRPC method, and returns the remote address of the caller for the audit
+// log, with the approving operators if approvals are required. The admin operations
+// are disabled if the node has no admin token.
+func (s *Server) authenticateAdmin(ctx context.Context, method string, req protobuf.Message) (string, error) {
+	remote := "unknown"
+	if p, ok := grpcpeer.FromContext(ctx); ok {
+		remote = p.Addr.String()
+	}
+
+	token := s.config.AdminToken
+	if token == "" {
+		return remote, status.Error(codes.PermissionDenied, "admin operations are disabled without an admin token")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(AdminTokenMetadataKey)
+	if len(values) != 1 || subtle.ConstantTimeCompare([]byte(values[0]), []byte(token)) != 1 {
+		s.logger.Warn("unauthenticated admin request", "method", method, "remote", remote)
+
+		return remote, status.Error(codes.Unauthenticated, "invalid admin token")
+	}
+
+	approvers, err := s.verifyAdminApproval(md, method, req, remote)
+	if err != nil {
+		s.logger.Warn("unapproved admin request", "method", method, "remote", remote, "err", err)
+
+		return remote, err
+	}
+
+	return adminCaller(remote, approvers), nil
+}
+
+// adminCaller describes the authenticated caller of the admin request, which is the
+// admin token holder at the remote address, or the operators approving the request
+func adminCaller(remote string, approvers []types.Address) string {
+	if len(approvers) == 0 {
+		return fmt.Sprintf("admin token from %s", remote)
+	}
+
+	operators := make([]string, len(approvers))
+	for i, approver := range approvers {
+		operators[i] = approver.String()
+	}
+
+	return fmt.Sprintf("operators %s from %s", strings.Join(operators, ", "), remote)
+}
+
+// verifyAdminApproval verifies that the request of the full gRPC method is signed by
+// enough registered operators, and that the approval is neither expired nor used
+// already. It returns the approving operators, none if approvals are not required.
+func (s *Server) verifyAdminApproval(
+	md metadata.MD,
+	method string,
+	req protobuf.Message,
+	remote string,
+) ([]types.Address, error) {
+	approver := s.adminApprover
+	if approver == nil {
+		return nil, nil
+	}
+
+	approvers, err := approver.verify(
+		method,
+		md.Get(AdminSignatureMetadataKey),
+		md.Get(AdminExpiryMetadataKey),
+		remote,
+		func(expiry int64) ([]byte, error) {
+			return AdminRequestDigest(approver.target, method, req, expiry)
+		},
+	)
+
+	switch {
+	case err == nil:
+		return approvers, nil
+	case errors.Is(err, errApprovalExpiryInvalid), errors.Is(err, errApprovalExpiryTooLate):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, errApprovalExpiryMissing), errors.Is(err, errApprovalExpired),
+		errors.Is(err, errApprovalMissing), errors.Is(err, errApprovalUsed):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+}
+
+// adminApprover verifies the N-of-M operator approvals of the admin requests, of both
+// the operator service and the json-rpc. The used approvals are kept until they
+// expire, and persisted if the path is set, so that they could not be replayed.
+type adminApprover struct {
+	approval *AdminApproval
+	target   AdminTarget
+	logger   hclog.Logger
+	now      func() time.Time
+
+	usedPath string
+	used     map[types.Hash]time.Time
+	usedLock sync.Mutex
+}
+
+// newAdminApprover returns the approver of the approval for the target node, nil if
+// approvals are not required. The used approvals are loaded from the file of the path,
+// if set.
+func newAdminApprover(
+	approval *AdminApproval,
+	target AdminTarget,
+	usedPath string,
+	logger hclog.Logger,
+) (*adminApprover, error) {
+	if approval == nil || approval.Threshold <= 0 {
+		return nil, nil
+	}
+
+	a := &adminApprover{
+		approval: approval,
+		target:   target,
+		logger:   logger.Named("audit"),
+		now:      time.Now,
+		usedPath: usedPath,
+		used:     make(map[types.Hash]time.Time),
+	}
+
+	if err := a.loadUsed(); err != nil {
+		return nil, fmt.Errorf("failed to load the used admin approvals: %w", err)
+	}
+
+	return a, nil
+}
+
+// ApproveRequest implements the jsonrpc.AdminApprover interface
+func (a *adminApprover) ApproveRequest(remote, method string, params []byte, signatures, expiry []string) error {
+	_, err := a.verify(method, signatures, expiry, remote, func(expiry int64) ([]byte, error) {
+		return AdminJSONRPCRequestDigest(a.target, method, params, expiry)
+	})
+
+	return err
+}
+
+// verify verifies that the digest of the request, covering the expiry, is signed by
+// enough registered operators, and that the approval is neither expired nor used
+// already. It returns the approving operators.
+func (a *adminApprover) verify(
+	method string,
+	signatures []string,
+	expiryValues []string,
+	remote string,
+	digestOf func(expiry int64) ([]byte, error),
+) ([]types.Address, error) {
+	if len(expiryValues) != 1 {
+		return nil, errApprovalExpiryMissing
+	}
+
+	expiry, err := strconv.ParseInt(expiryValues[0], 10, 64)
+	if err != nil {
+		return nil, errApprovalExpiryInvalid
+	}
+
+	now := a.now()
+	expiresAt := time.Unix(expiry, 0)
+
+	if !expiresAt.After(now) {
+		return nil, errApprovalExpired
+	} else if expiresAt.After(now.Add(MaxAdminApprovalTTL)) {
+		return nil, errApprovalExpiryTooLate
+	}
+
+	digest, err := digestOf(expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	approvers := make([]types.Address, 0, a.approval.Threshold)
+
+	for _, value := range signatures {
+		sig, err := hex.DecodeHex(value)
+		if err != nil {
+			continue
+		}
+
+		pub, err := crypto.RecoverPubkey(sig, digest)
+		if err != nil {
+			continue
+		}
+
+		signer := crypto.PubKeyToAddress(pub)
+
+		// the registered operators, each counted once
+		if containsAddress(a.approval.Signers, signer) && !containsAddress(approvers, signer) {
+			approvers = append(approvers, signer)
+		}
+	}
+
+	if len(approvers) < a.approval.Threshold {
+		return nil, fmt.Errorf("%w: approved by %d operators, %d required",
+			errApprovalMissing, len(approvers), a.approval.Threshold)
+	}
+
+	if err := a.use(types.BytesToHash(digest), expiresAt, now); err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("admin request approved",
+		"method", method,
+		"remote", remote,
+		"approvers", approvers,
+		"expiry", expiresAt.UTC(),
+	)
+
+	return approvers, nil
+}
+
+// use records the approval until it expires, it returns errApprovalUsed if the
+// approval is used already. The request is not approved if the approval could not
+// be persisted, since it could be replayed after a restart.
+func (a *adminApprover) use(digest types.Hash, expiresAt, now time.Time) error {
+	a.usedLock.Lock()
+	defer a.usedLock.Unlock()
+
+	// the expired ones could not be replayed anyway
+	for d, t := range a.used {
+		if !t.After(now) {
+			delete(a.used, d)
+		}
+	}
+
+	if _, ok := a.used[digest]; ok {
+		return errApprovalUsed
+	}
+
+	a.used[digest] = expiresAt
+
+	if err := a.storeUsed(); err != nil {
+		delete(a.used, digest)
+
+		return fmt.Errorf("failed to store the used admin approval: %w", err)
+	}
+
+	return nil
+}
+
+// loadUsed loads the used approvals from the file, the missing file holds none
+func (a *adminApprover) loadUsed() error {
+	if a.usedPath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(a.usedPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	used := map[types.Hash]int64{}
+	if err := json.Unmarshal(data, &used); err != nil {
+		return err
+	}
+
+	for digest, expiry := range used {
+		a.used[digest] = time.Unix(expiry, 0)
+	}
+
+	return nil
+}
+
+// storeUsed replaces the file by the used approvals, the caller holds the lock
+func (a *adminApprover) storeUsed() error {
+	if a.usedPath == "" {
+		return nil
+	}
+
+	used := make(map[types.Hash]int64, len(a.used))
+	for digest, expiresAt := range a.used {
+		used[digest] = expiresAt.Unix()
+	}
+
+	data, err := json.Marshal(used)
+	if err != nil {
+		return err
+	}
+
+	// a partial file is never read
+	tmp := a.usedPath + ".tmp"
+
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, a.usedPath)
+}
+
+func containsAddress(addrs []types.Address, addr types.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+
+	return false
+}
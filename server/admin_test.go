@@ -0,0 +1,469 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/server/proto"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testAdminMethod = "/v1.System/Halt"
+
+var testAdminTarget = AdminTarget{
+	ChainID: 2000,
+	NodeID:  "16Uiu2HAmJxxH1tScDX2rLGSU9exnuvZKNM9SoK3v315azp68DLPW",
+}
+
+// newTestAdminApprover returns the approver of the test node, the used approvals are
+// persisted in the file of the path if set
+func newTestAdminApprover(t *testing.T, signers []types.Address, threshold int, path string) *adminApprover {
+	t.Helper()
+
+	approver, err := newAdminApprover(
+		&AdminApproval{Signers: signers, Threshold: threshold},
+		testAdminTarget,
+		path,
+		hclog.NewNullLogger(),
+	)
+	assert.NoError(t, err)
+
+	return approver
+}
+
+func newTestOperators(t *testing.T, n int) ([]*ecdsa.PrivateKey, []types.Address) {
+	t.Helper()
+
+	keys := make([]*ecdsa.PrivateKey, n)
+	addrs := make([]types.Address, n)
+
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		assert.NoError(t, err)
+
+		keys[i] = key
+		addrs[i] = crypto.PubKeyToAddress(&key.PublicKey)
+	}
+
+	return keys, addrs
+}
+
+func signAdminDigest(t *testing.T, digest []byte, keys ...*ecdsa.PrivateKey) []string {
+	t.Helper()
+
+	sigs := make([]string, len(keys))
+
+	for i, key := range keys {
+		sig, err := crypto.Sign(key, digest)
+		assert.NoError(t, err)
+
+		sigs[i] = hex.EncodeToHex(sig)
+	}
+
+	return sigs
+}
+
+func TestAdminApprover_Verify(t *testing.T) {
+	t.Parallel()
+
+	keys, signers := newTestOperators(t, 3)
+	unknown, _ := newTestOperators(t, 1)
+
+	now := time.Unix(1_700_000_000, 0)
+	req := &proto.HaltRequest{Operator: "alice", Reason: "incident"}
+
+	digestOf := func(expiry int64) ([]byte, error) {
+		return AdminRequestDigest(testAdminTarget, testAdminMethod, req, expiry)
+	}
+
+	signFor := func(target AdminTarget, expiry int64, keys ...*ecdsa.PrivateKey) []string {
+		digest, err := AdminRequestDigest(target, testAdminMethod, req, expiry)
+		assert.NoError(t, err)
+
+		return signAdminDigest(t, digest, keys...)
+	}
+
+	sign := func(expiry int64, keys ...*ecdsa.PrivateKey) []string {
+		return signFor(testAdminTarget, expiry, keys...)
+	}
+
+	expiry := now.Add(time.Minute).Unix()
+
+	tests := []struct {
+		name       string
+		signatures []string
+		expiry     []string
+		err        error
+		approvers  []types.Address
+	}{
+		{
+			name:       "threshold reached",
+			signatures: sign(expiry, keys[0], keys[2]),
+			expiry:     []string{strconv.FormatInt(expiry, 10)},
+			approvers:  []types.Address{signers[0], signers[2]},
+		},
+		{
+			name:       "all the operators",
+			signatures: sign(expiry, keys...),
+			expiry:     []string{strconv.FormatInt(expiry, 10)},
+			approvers:  signers,
+		},
+		{
+			name:       "below the threshold",
+			signatures: sign(expiry, keys[1]),
+			expiry:     []string{strconv.FormatInt(expiry, 10)},
+			err:        errApprovalMissing,
+		},
+		{
+			name:       "duplicate signers counted once",
+			signatures: append(sign(expiry, keys[1]), sign(expiry, keys[1])...),
+			expiry:     []string{strconv.FormatInt(expiry, 10)},
+			err:        errApprovalMissing,
+		},
+		{
+			name:       "unknown signers not counted",
+			signatures: sign(expiry, keys[1], unknown[0]),
+			expiry:     []string{strconv.FormatInt(expiry, 10)},
+			err:        errApprovalMissing,
+		},
+		{
+			name:       "malformed signatures not counted",
+			signatures: append(sign(expiry, keys[1]), "0x01", "not hex"),
+			expiry:     []string{strconv.FormatInt(expiry, 10)},
+			err:        errApprovalMissing,
+		},
+		{
+			name:       "signed for another expiry",
+			signatures: sign(expiry+1, keys[0], keys[1]),
+			expiry:     []string{strconv.FormatInt(expiry, 10)},
+			err:        errApprovalMissing,
+		},
+		{
+			name: "signed for another node",
+			signatures: signFor(AdminTarget{ChainID: testAdminTarget.ChainID, NodeID: "another"},
+				expiry, keys[0], keys[1]),
+			expiry: []string{strconv.FormatInt(expiry, 10)},
+			err:    errApprovalMissing,
+		},
+		{
+			name: "signed for another chain",
+			signatures: signFor(AdminTarget{ChainID: 568, NodeID: testAdminTarget.NodeID},
+				expiry, keys[0], keys[1]),
+			expiry: []string{strconv.FormatInt(expiry, 10)},
+			err:    errApprovalMissing,
+		},
+		{
+			name:       "missing expiry",
+			signatures: sign(expiry, keys[0], keys[1]),
+			err:        errApprovalExpiryMissing,
+		},
+		{
+			name:       "repeated expiry",
+			signatures: sign(expiry, keys[0], keys[1]),
+			expiry:     []string{strconv.FormatInt(expiry, 10), strconv.FormatInt(expiry, 10)},
+			err:        errApprovalExpiryMissing,
+		},
+		{
+			name:       "invalid expiry",
+			signatures: sign(expiry, keys[0], keys[1]),
+			expiry:     []string{"tomorrow"},
+			err:        errApprovalExpiryInvalid,
+		},
+		{
+			name:       "expired",
+			signatures: sign(now.Unix(), keys[0], keys[1]),
+			expiry:     []string{strconv.FormatInt(now.Unix(), 10)},
+			err:        errApprovalExpired,
+		},
+		{
+			name: "expiry too late",
+			signatures: sign(now.Add(MaxAdminApprovalTTL+time.Second).Unix(),
+				keys[0], keys[1]),
+			expiry: []string{strconv.FormatInt(now.Add(MaxAdminApprovalTTL+time.Second).Unix(), 10)},
+			err:    errApprovalExpiryTooLate,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			approver := newTestAdminApprover(t, signers, 2, "")
+			approver.now = func() time.Time { return now }
+
+			approvers, err := approver.verify(testAdminMethod, test.signatures, test.expiry, "test", digestOf)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.approvers, approvers)
+		})
+	}
+}
+
+func TestAdminApprover_Replay(t *testing.T) {
+	t.Parallel()
+
+	keys, signers := newTestOperators(t, 2)
+
+	now := time.Unix(1_700_000_000, 0)
+	expiry := now.Add(time.Minute).Unix()
+
+	path := filepath.Join(t.TempDir(), adminApprovalsFile)
+
+	approver := newTestAdminApprover(t, signers, 2, path)
+	approver.now = func() time.Time { return now }
+
+	verify := func(req *proto.HaltRequest) error {
+		digestOf := func(expiry int64) ([]byte, error) {
+			return AdminRequestDigest(testAdminTarget, testAdminMethod, req, expiry)
+		}
+
+		digest, err := digestOf(expiry)
+		assert.NoError(t, err)
+
+		_, err = approver.verify(testAdminMethod, signAdminDigest(t, digest, keys...),
+			[]string{strconv.FormatInt(expiry, 10)}, "test", digestOf)
+
+		return err
+	}
+
+	halt := &proto.HaltRequest{Operator: "alice", Reason: "incident"}
+
+	assert.NoError(t, verify(halt))
+	assert.ErrorIs(t, verify(halt), errApprovalUsed)
+
+	// the used approvals are not forgotten by a restart
+	approver = newTestAdminApprover(t, signers, 2, path)
+	approver.now = func() time.Time { return now }
+
+	assert.ErrorIs(t, verify(halt), errApprovalUsed)
+
+	// another request is approved on its own
+	assert.NoError(t, verify(&proto.HaltRequest{Operator: "alice", Reason: "another incident"}))
+
+	// the used approvals are forgotten once expired, when they could not be replayed anyway
+	now = now.Add(2 * time.Minute)
+
+	assert.ErrorIs(t, verify(halt), errApprovalExpired)
+	assert.Len(t, approver.used, 2)
+
+	expiry = now.Add(time.Minute).Unix()
+
+	assert.NoError(t, verify(halt))
+	assert.Len(t, approver.used, 1)
+
+	// the expired ones are dropped from the file as well
+	approver = newTestAdminApprover(t, signers, 2, path)
+	assert.Len(t, approver.used, 1)
+}
+
+func TestAdminApprover_UnstoredApproval(t *testing.T) {
+	t.Parallel()
+
+	keys, signers := newTestOperators(t, 1)
+
+	// the file could not be written in a missing directory
+	approver := newTestAdminApprover(t, signers, 1, filepath.Join(t.TempDir(), "missing", adminApprovalsFile))
+
+	req := &proto.HaltRequest{Operator: "alice", Reason: "incident"}
+	expiry := time.Now().Add(time.Minute).Unix()
+
+	digestOf := func(expiry int64) ([]byte, error) {
+		return AdminRequestDigest(testAdminTarget, testAdminMethod, req, expiry)
+	}
+
+	digest, err := digestOf(expiry)
+	assert.NoError(t, err)
+
+	// the approval which could be replayed after a restart is not used
+	_, err = approver.verify(testAdminMethod, signAdminDigest(t, digest, keys...),
+		[]string{strconv.FormatInt(expiry, 10)}, "test", digestOf)
+	assert.Error(t, err)
+	assert.Empty(t, approver.used)
+}
+
+func TestNewAdminApprover_Disabled(t *testing.T) {
+	t.Parallel()
+
+	approver, err := newAdminApprover(nil, testAdminTarget, "", hclog.NewNullLogger())
+	assert.NoError(t, err)
+	assert.Nil(t, approver)
+
+	approver, err = newAdminApprover(&AdminApproval{Signers: []types.Address{{0x1}}},
+		testAdminTarget, "", hclog.NewNullLogger())
+	assert.NoError(t, err)
+	assert.Nil(t, approver)
+}
+
+func TestAdminApprover_JSONRPC(t *testing.T) {
+	t.Parallel()
+
+	keys, signers := newTestOperators(t, 2)
+
+	approver := newTestAdminApprover(t, signers, 2, "")
+
+	expiry := time.Now().Add(time.Minute).Unix()
+	expiryValues := []string{strconv.FormatInt(expiry, 10)}
+
+	digest, err := AdminJSONRPCRequestDigest(testAdminTarget, "admin_removePeer", []byte(`["peer1"]`), expiry)
+	assert.NoError(t, err)
+
+	sigs := signAdminDigest(t, digest, keys...)
+
+	// the params are signed for another peer
+	assert.ErrorIs(t,
+		approver.ApproveRequest("127.0.0.1", "admin_removePeer", []byte(`["peer2"]`), sigs, expiryValues),
+		errApprovalMissing,
+	)
+
+	// the formatting of the params does not matter
+	assert.NoError(t,
+		approver.ApproveRequest("127.0.0.1", "admin_removePeer", []byte(` [ "peer1" ] `), sigs, expiryValues),
+	)
+
+	assert.ErrorIs(t,
+		approver.ApproveRequest("127.0.0.1", "admin_removePeer", []byte(`["peer1"]`), sigs, expiryValues),
+		errApprovalUsed,
+	)
+}
+
+func TestServer_AdminUnaryInterceptor(t *testing.T) {
+	t.Parallel()
+
+	keys, signers := newTestOperators(t, 2)
+
+	srv := &Server{
+		logger:        hclog.NewNullLogger(),
+		config:        &Config{AdminToken: "token"},
+		adminApprover: newTestAdminApprover(t, signers, 2, ""),
+	}
+
+	var caller string
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		caller = adminCallerFromContext(ctx)
+
+		return req, nil
+	}
+
+	call := func(method string, md metadata.MD, req interface{}) error {
+		caller = ""
+
+		_, err := srv.adminUnaryInterceptor(
+			metadata.NewIncomingContext(context.Background(), md),
+			req,
+			&grpc.UnaryServerInfo{FullMethod: method},
+			handler,
+		)
+
+		return err
+	}
+
+	approval := func(method string, req *proto.PeersAddRequest) metadata.MD {
+		expiry := time.Now().Add(time.Minute).Unix()
+
+		digest, err := AdminRequestDigest(testAdminTarget, method, req, expiry)
+		assert.NoError(t, err)
+
+		md := metadata.Pairs(
+			AdminTokenMetadataKey, "token",
+			AdminExpiryMetadataKey, strconv.FormatInt(expiry, 10),
+		)
+		md.Append(AdminSignatureMetadataKey, signAdminDigest(t, digest, keys...)...)
+
+		return md
+	}
+
+	peer := &proto.PeersAddRequest{Id: "/ip4/1.2.3.4/tcp/1478/p2p/a"}
+
+	// the other methods are served as before
+	assert.NoError(t, call("/v1.System/GetStatus", metadata.MD{}, peer))
+	assert.Empty(t, caller)
+
+	// each admin method requires the token and the approval
+	for method := range adminMethods {
+		assert.Equal(t, codes.Unauthenticated, status.Code(call(method, metadata.MD{}, peer)), method)
+		assert.Equal(t, codes.PermissionDenied,
+			status.Code(call(method, metadata.Pairs(AdminTokenMetadataKey, "token"), peer)), method)
+	}
+
+	md := approval("/v1.System/PeersAdd", peer)
+
+	// the approval of a method does not approve another one
+	assert.Equal(t, codes.PermissionDenied, status.Code(call("/v1.System/Compact", md, peer)))
+
+	assert.NoError(t, call("/v1.System/PeersAdd", md, peer))
+	assert.Contains(t, caller, "operators")
+
+	// the replay is denied
+	assert.Equal(t, codes.PermissionDenied, status.Code(call("/v1.System/PeersAdd", md, peer)))
+
+	// the admin methods are disabled without a token
+	srv.config.AdminToken = ""
+
+	assert.Equal(t, codes.PermissionDenied,
+		status.Code(call("/v1.System/PeersAdd", approval("/v1.System/PeersAdd", peer), peer)))
+}
+
+func TestServer_VerifyAdminApproval(t *testing.T) {
+	t.Parallel()
+
+	keys, signers := newTestOperators(t, 2)
+
+	srv := &Server{
+		adminApprover: newTestAdminApprover(t, signers, 2, ""),
+	}
+
+	req := &proto.HaltRequest{Operator: "alice", Reason: "incident"}
+	expiry := time.Now().Add(time.Minute).Unix()
+
+	digest, err := AdminRequestDigest(testAdminTarget, testAdminMethod, req, expiry)
+	assert.NoError(t, err)
+
+	md := metadata.MD{}
+	md.Append(AdminExpiryMetadataKey, strconv.FormatInt(expiry, 10))
+	md.Append(AdminSignatureMetadataKey, signAdminDigest(t, digest, keys...)...)
+
+	approvers, err := srv.verifyAdminApproval(md, testAdminMethod, req, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, signers, approvers)
+
+	// the replay is denied
+	_, err = srv.verifyAdminApproval(md, testAdminMethod, req, "test")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// the approval signed for another method
+	_, err = srv.verifyAdminApproval(md, "/v1.System/Resume", req, "test")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// the invalid expiry
+	md.Set(AdminExpiryMetadataKey, "tomorrow")
+
+	_, err = srv.verifyAdminApproval(md, "/v1.System/Resume", req, "test")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// no approval required
+	srv.adminApprover = nil
+
+	approvers, err = srv.verifyAdminApproval(metadata.MD{}, "/v1.System/Resume", req, "test")
+	assert.NoError(t, err)
+	assert.Empty(t, approvers)
+}
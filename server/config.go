@@ -2,9 +2,17 @@ package server
 
 import (
 	"net"
+	"time"
 
+	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
 	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/consensus"
+	"github.com/dogechain-lab/dogechain/consensus/lease"
+	"github.com/dogechain-lab/dogechain/consensus/signer"
 	"github.com/dogechain-lab/dogechain/helper/gasprice"
+	"github.com/dogechain-lab/dogechain/helper/tlsutil"
+	"github.com/dogechain-lab/dogechain/jsonrpc"
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/secrets"
 	"github.com/hashicorp/go-hclog"
@@ -39,10 +47,21 @@ type Config struct {
 	RestoreFile *string
 
 	LeveldbOptions *LeveldbOptions
+	// the options of the separate database of the receipts and transaction lookups,
+	// they are kept in the blockchain database if nil
+	ReceiptsLeveldbOptions *LeveldbOptions
+	CacheOptions           *CacheOptions
+	Warmup                 *WarmupOptions
 
 	Seal           bool
 	SecretsManager *secrets.SecretsManagerConfig
 
+	// the token authenticating the admin operations of the operator service, they are
+	// disabled if empty
+	AdminToken string
+	// the operator signatures required by the admin operations, on top of the token
+	AdminApproval *AdminApproval
+
 	LogLevel    hclog.Level
 	LogFilePath string
 
@@ -51,6 +70,49 @@ type Config struct {
 
 	BlockBroadcast bool
 
+	MaxSenderTxs      uint64 // max transactions of one sender in a sealed block, 0 for unlimited
+	MaxSenderGasShare uint64 // max block gas limit percentage of one sender in a sealed block, 0 for unlimited
+
+	ExtraVanity string // vanity embedded in the extra data of the sealed blocks
+
+	// the policy on the candidate transactions of the sealed blocks, for the validators
+	// embedding the server with their own inclusion rules. Every transaction is
+	// included if nil.
+	TxPolicy consensus.TxPolicy
+
+	// the lease held by the sealing instance of the validator, the standby instances
+	// take over once it is released or expired. It seals without the lease if nil.
+	SealLease *lease.Config
+
+	// the remote signer holding the validator key, the key of the secrets manager is
+	// used if nil
+	RemoteSigner *signer.RemoteConfig
+
+	// the watchdog of the turns missed by the sealing validator, nil for disabled
+	Downtime *consensus.DowntimeConfig
+
+	MaxReorgDepth uint64
+
+	EnableLogIndex   bool
+	EnableBloomIndex bool
+	EnableChainStats bool // aggregate the hourly chain usage in background
+
+	EnableSystemTxIndex bool // index the system transaction types in background
+
+	TxLookupLimit uint64
+
+	BlockWriteQueue uint64 // max blocks flushed in background, 0 for synchronous writes
+
+	DBKeyLayout storage.KeyLayout // key layout of a new blockchain database, empty for the default
+
+	ReceiptsBackfill     bool   // regenerate the missing receipts in background
+	ReceiptsBackfillRate uint64 // max blocks re-executed per second by the backfill, 0 for unlimited
+
+	// thresholds delaying the synced block imports under resource pressure, 0 to disable
+	ImportMaxWriteQueue uint64
+	ImportMaxHeapMB     uint64
+	ImportMaxL0Tables   uint64
+
 	GasPriceOracle gasprice.Config
 }
 
@@ -62,6 +124,30 @@ type LeveldbOptions struct {
 	CompactionTableSize int
 	CompactionTotalSize int
 	NoSync              bool
+	IdealBatchSize      int           // bytes of the bulk writes queued before flushed
+	SlowOpThreshold     time.Duration // the operations slower than it are logged, disabled if zero
+}
+
+// CacheOptions holds the sizes of the in-memory caches, in bytes
+type CacheOptions struct {
+	StateSize int  // cached trie nodes
+	CodeSize  int  // cached contract codes
+	Preimages bool // record the preimages of the state trie keys
+
+	Chain blockchain.AdaptiveCacheConfig // adaptive sizing of the header and receipt caches
+}
+
+// WarmupOptions holds the data loaded into the caches in background on start, so
+// that the first requests after a restart are not slowed by the cold caches
+type WarmupOptions struct {
+	Headers    uint64 // the last headers
+	Receipts   uint64 // the bodies and receipts of the last blocks
+	StateDepth int    // the levels of the state trie nodes under the head root
+}
+
+// enabled returns whether any data is loaded
+func (o *WarmupOptions) enabled() bool {
+	return o != nil && (o.Headers > 0 || o.Receipts > 0 || o.StateDepth > 0)
 }
 
 // Telemetry holds the config details for metric services
@@ -80,7 +166,20 @@ type JSONRPC struct {
 	BlockRangeLimit          uint64
 	JSONNamespace            []string
 	EnableWS                 bool
+	WSMaxMessageSize         uint64
+	WSMessageRateLimit       uint64
+	WSSendQueueSize          uint64
+	WSDropPolicy             string
 	EnablePprof              bool
+	CacheSize                uint64 // MiB of the response cache, 0 for disabled
+	CacheRedisURL            string // redis backing the response cache, empty for disabled
+	SignerAddr               string // external signer of eth_sendTransaction, empty for disabled
+	SignerRulesPath          string // approval rules of the external signer, empty for none
+
+	RateLimit      *jsonrpc.RateLimitConfig // limits of the requests per client ip, nil if unlimited
+	TrustedProxies []*net.IPNet             // proxies whose forwarded client ip headers are honoured
+	Auth           bool                     // whether the bearer token is required
+	TLS            *tlsutil.Config          // certificate terminating TLS, nil for plain http
 }
 
 type GraphQL struct {
@@ -88,4 +187,5 @@ type GraphQL struct {
 	AccessControlAllowOrigin []string
 	BlockRangeLimit          uint64
 	EnablePprof              bool
+	TLS                      *tlsutil.Config // certificate terminating TLS, nil for plain http
 }
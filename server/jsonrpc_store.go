@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/dogechain-lab/dogechain/blockchain"
 	"github.com/dogechain-lab/dogechain/chain"
@@ -16,6 +17,7 @@ import (
 	"github.com/dogechain-lab/dogechain/state/runtime"
 	"github.com/dogechain-lab/dogechain/txpool"
 	"github.com/dogechain-lab/dogechain/types"
+	"github.com/libp2p/go-libp2p/core/peer"
 )
 
 type jsonRPCStore struct {
@@ -137,6 +139,105 @@ func (j *jsonRPCStore) GetCode(root types.Hash, addr types.Address) ([]byte, err
 	return code, nil
 }
 
+// jsonrpc.debugStore interface
+
+// GetCodeByHash returns the contract code stored under the code hash
+func (j *jsonRPCStore) GetCodeByHash(hash types.Hash) ([]byte, bool) {
+	j.metrics.GetCodeByHashInc()
+
+	return j.state.GetCode(hash)
+}
+
+// ChaindbProperty returns the named property of the blockchain database
+func (j *jsonRPCStore) ChaindbProperty(property string) (string, error) {
+	j.metrics.ChaindbPropertyInc()
+
+	return j.blockchain.DBProperty(property)
+}
+
+// GetBlockNumbersByStateRoot returns the numbers of the canonical blocks with the state root
+func (j *jsonRPCStore) GetBlockNumbersByStateRoot(root types.Hash) []uint64 {
+	j.metrics.GetBlockNumbersByStateRootInc()
+
+	return j.blockchain.GetBlockNumbersByStateRoot(root)
+}
+
+// ChaindbCompact compacts the keys within [start, limit) of the blockchain database
+func (j *jsonRPCStore) ChaindbCompact(start, limit []byte) error {
+	j.metrics.ChaindbCompactInc()
+
+	return j.blockchain.Compact(start, limit)
+}
+
+// jsonrpc.dcStore interface
+
+// GetStorageSlots returns the values of the slots within the account storage root
+func (j *jsonRPCStore) GetStorageSlots(storageRoot types.Hash, slots []types.Hash) ([]types.Hash, error) {
+	j.metrics.GetStorageSlotsInc()
+
+	return j.state.NewSnapshot().GetStorageSlots(storageRoot, slots)
+}
+
+// GetAccountProof returns the merkle proof of the account within the state root
+func (j *jsonRPCStore) GetAccountProof(root types.Hash, addr types.Address) ([][]byte, error) {
+	j.metrics.GetAccountProofInc()
+
+	return j.state.ProveAccount(root, addr)
+}
+
+// IterateAccounts walks the accounts within the state root in the order of their
+// address hashes, from the start hash (inclusive)
+func (j *jsonRPCStore) IterateAccounts(
+	root types.Hash,
+	start types.Hash,
+	fn func(hash types.Hash, account *state.Account) bool,
+) error {
+	j.metrics.IterateAccountsInc()
+
+	snap, err := j.state.NewSnapshotAt(root)
+	if err != nil {
+		return err
+	}
+
+	return snap.IterateAccounts(start, fn)
+}
+
+// GetPreimage returns the address or storage slot hashed to the trie key, if recorded
+func (j *jsonRPCStore) GetPreimage(hash types.Hash) ([]byte, bool) {
+	j.metrics.GetPreimageInc()
+
+	return j.state.GetPreimage(hash)
+}
+
+// GetBlockExecutionStats returns the execution stats of the recent verified blocks in range
+func (j *jsonRPCStore) GetBlockExecutionStats(from, to uint64) []*blockchain.BlockExecutionStats {
+	j.metrics.GetBlockExecutionStatsInc()
+
+	return j.blockchain.GetBlockExecutionStats(from, to)
+}
+
+// GetChainStats returns the usage of the canonical blocks within the time range,
+// aggregated by the interval
+func (j *jsonRPCStore) GetChainStats(from, to, interval uint64) ([]*blockchain.ChainStats, error) {
+	j.metrics.GetChainStatsInc()
+
+	return j.blockchain.GetChainStats(from, to, interval)
+}
+
+// GetChainParams returns the params of the chain
+func (j *jsonRPCStore) GetChainParams() *chain.Params {
+	j.metrics.GetChainParamsInc()
+
+	return j.blockchain.Config()
+}
+
+// GetStorageProof returns the merkle proof of the slot within the account storage root
+func (j *jsonRPCStore) GetStorageProof(storageRoot types.Hash, slot types.Hash) ([][]byte, error) {
+	j.metrics.GetStorageProofInc()
+
+	return j.state.ProveStorage(storageRoot, slot)
+}
+
 // jsonrpc.ethBlockchainStore interface
 
 // Header returns the current header of the chain (genesis if empty)
@@ -153,6 +254,13 @@ func (j *jsonRPCStore) GetHeaderByNumber(n uint64) (*types.Header, bool) {
 	return j.blockchain.GetHeaderByNumber(n)
 }
 
+// GetHeadersInRange returns the canonical headers within [from, to]
+func (j *jsonRPCStore) GetHeadersInRange(from, to uint64) []*types.Header {
+	j.metrics.GetHeadersInRangeInc()
+
+	return j.blockchain.GetHeadersInRange(from, to)
+}
+
 // GetHeaderByHash returns the header by hash
 func (j *jsonRPCStore) GetHeaderByHash(hash types.Hash) (*types.Header, bool) {
 	j.metrics.GetHeaderByHashInc()
@@ -207,6 +315,25 @@ func (j *jsonRPCStore) ApplyTxn(
 ) (result *runtime.ExecutionResult, err error) {
 	j.metrics.ApplyTxnInc()
 
+	return j.applyTxn(header, txn, nil)
+}
+
+// TraceTxn applies a transaction object to the blockchain, traced by the logger
+func (j *jsonRPCStore) TraceTxn(
+	header *types.Header,
+	txn *types.Transaction,
+	logger runtime.EVMLogger,
+) (*runtime.ExecutionResult, error) {
+	j.metrics.TraceTxnInc()
+
+	return j.applyTxn(header, txn, logger)
+}
+
+func (j *jsonRPCStore) applyTxn(
+	header *types.Header,
+	txn *types.Transaction,
+	logger runtime.EVMLogger,
+) (result *runtime.ExecutionResult, err error) {
 	blockCreator, err := j.consensus.GetBlockCreator(header)
 	if err != nil {
 		return nil, err
@@ -218,6 +345,8 @@ func (j *jsonRPCStore) ApplyTxn(
 		return
 	}
 
+	transition.SetEVMLogger(logger)
+
 	result, err = transition.Apply(txn)
 
 	return
@@ -296,6 +425,101 @@ func (j *jsonRPCStore) PeerCount() int64 {
 	return j.server.PeerCount()
 }
 
+// jsonrpc.adminStore interface
+
+var errStaticPeerRemoval = errors.New("static peer could not be removed")
+
+// GetPeers returns the connected peers, with their connections
+func (j *jsonRPCStore) GetPeers() []*jsonrpc.PeerInfo {
+	j.metrics.GetPeersInc()
+
+	conns := j.server.Peers()
+	peers := make([]*jsonrpc.PeerInfo, 0, len(conns))
+
+	for _, conn := range conns {
+		id := conn.Info.ID
+
+		info := &jsonrpc.PeerInfo{
+			ID:          id.String(),
+			Addrs:       make([]string, 0, len(conn.Info.Addrs)),
+			Static:      j.server.IsStaticPeer(id),
+			Connections: []*jsonrpc.PeerConn{},
+		}
+
+		for _, addr := range conn.Info.Addrs {
+			info.Addrs = append(info.Addrs, addr.String())
+		}
+
+		// the peer might be disconnected meanwhile, listed without the protocols
+		info.Protocols, _ = j.server.GetProtocols(id)
+
+		for _, c := range j.server.PeerConns(id) {
+			stat := c.Stat()
+
+			info.Connections = append(info.Connections, &jsonrpc.PeerConn{
+				Direction:     strings.ToLower(stat.Direction.String()),
+				LocalAddress:  c.LocalMultiaddr().String(),
+				RemoteAddress: c.RemoteMultiaddr().String(),
+				Opened:        stat.Opened,
+			})
+		}
+
+		peers = append(peers, info)
+	}
+
+	return peers
+}
+
+// GetNodeInfo returns the identity of the node
+func (j *jsonRPCStore) GetNodeInfo() (*jsonrpc.NodeInfo, error) {
+	j.metrics.GetNodeInfoInc()
+
+	rec, err := j.server.NodeRecord()
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]string, 0, len(rec.Addrs))
+	for _, addr := range rec.Addrs {
+		// full multiaddr with the peer ID, as admin_addPeer of the other nodes requires
+		addrs = append(addrs, fmt.Sprintf("%s/p2p/%s", addr.String(), rec.ID.String()))
+	}
+
+	return &jsonrpc.NodeInfo{
+		ID:        rec.ID.String(),
+		Addrs:     addrs,
+		Protocols: rec.Protocols,
+		PeerCount: j.server.PeerCount(),
+	}, nil
+}
+
+// AddPeer marks the peer of the multiaddr ready for dialing
+func (j *jsonRPCStore) AddPeer(rawPeerMultiaddr string, static bool) error {
+	j.metrics.AddPeerInc()
+
+	return j.server.JoinPeer(rawPeerMultiaddr, static)
+}
+
+// RemovePeer disconnects the peer, and returns whether it was connected
+func (j *jsonRPCStore) RemovePeer(rawPeerID string) (bool, error) {
+	j.metrics.RemovePeerInc()
+
+	peerID, err := peer.Decode(rawPeerID)
+	if err != nil {
+		return false, err
+	}
+
+	if j.server.IsStaticPeer(peerID) {
+		return false, errStaticPeerRemoval
+	}
+
+	connected := j.server.HasPeer(peerID)
+
+	j.server.DisconnectFromPeer(peerID, "removed by admin")
+
+	return connected, nil
+}
+
 // jsonrpc.txPoolStore interface
 
 // GetTxs gets tx pool transactions currently pending for inclusion and currently queued for validation
@@ -307,6 +531,29 @@ func (j *jsonRPCStore) GetTxs(inclQueued bool) (
 	return j.txpool.GetTxs(inclQueued)
 }
 
+// GetTxCounts returns the number of the pending and the queued transactions
+func (j *jsonRPCStore) GetTxCounts() (uint64, uint64) {
+	j.metrics.GetTxCountsInc()
+
+	return j.txpool.GetTxCounts()
+}
+
+// GetAccountTxs gets the pending and the queued transactions of the account
+func (j *jsonRPCStore) GetAccountTxs(addr types.Address) (
+	[]*types.Transaction, []*types.Transaction,
+) {
+	j.metrics.GetAccountTxsInc()
+
+	return j.txpool.GetAccountTxs(addr)
+}
+
+// ValidateTx runs the admission of the tx pool on the transaction without adding it
+func (j *jsonRPCStore) ValidateTx(tx *types.Transaction) []error {
+	j.metrics.ValidateTxInc()
+
+	return j.txpool.ValidateTx(tx)
+}
+
 // GetCapacity returns the current and max capacity of the pool in slots
 func (j *jsonRPCStore) GetCapacity() (uint64, uint64) {
 	j.metrics.GetCapacityInc()
@@ -316,10 +563,42 @@ func (j *jsonRPCStore) GetCapacity() (uint64, uint64) {
 
 // jsonrpc.filterManagerStore interface
 
-func (j *jsonRPCStore) SubscribeEvents() blockchain.Subscription {
-	j.metrics.SubscribeEventsInc()
+func (j *jsonRPCStore) SubscribeEventsWithFilter(filter *blockchain.EventFilter) blockchain.Subscription {
+	j.metrics.SubscribeEventsWithFilterInc()
+
+	return j.blockchain.SubscribeEventsWithFilter(filter)
+}
+
+func (j *jsonRPCStore) SubscribePendingTxs() txpool.PendingTxsSubscription {
+	j.metrics.SubscribePendingTxsInc()
+
+	return j.txpool.SubscribePendingTxs()
+}
+
+// FilterLogBlocks returns the block numbers which might contain the logs from the log index or bloom bits
+func (j *jsonRPCStore) FilterLogBlocks(
+	addresses []types.Address,
+	topics [][]types.Hash,
+	from, to uint64,
+) ([]uint64, bool) {
+	j.metrics.FilterLogBlocksInc()
+
+	return j.blockchain.FilterLogBlocks(addresses, topics, from, to)
+}
+
+// FilterSystemTxBlocks returns the block numbers which might contain the system transactions
+// of the type from the system transaction index
+func (j *jsonRPCStore) FilterSystemTxBlocks(txType blockchain.SystemTxType, from, to uint64) ([]uint64, bool) {
+	j.metrics.FilterSystemTxBlocksInc()
+
+	return j.blockchain.FilterSystemTxBlocks(txType, from, to)
+}
+
+// GetSystemTxTypes returns the system transaction type of every transaction of the block
+func (j *jsonRPCStore) GetSystemTxTypes(header *types.Header, txs []*types.Transaction) []blockchain.SystemTxType {
+	j.metrics.GetSystemTxTypesInc()
 
-	return j.blockchain.SubscribeEvents()
+	return j.blockchain.GetSystemTxTypes(header, txs)
 }
 
 func (j *jsonRPCStore) GetDDosContractList() map[string]map[types.Address]int {
@@ -44,6 +44,62 @@ func (m *JSONRPCStoreMetrics) GetStorageInc() {
 	}
 }
 
+// GetStorageSlots api calls
+func (m *JSONRPCStoreMetrics) GetStorageSlotsInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetStorageSlots"}).Inc()
+	}
+}
+
+// GetAccountProof api calls
+func (m *JSONRPCStoreMetrics) GetAccountProofInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetAccountProof"}).Inc()
+	}
+}
+
+// IterateAccounts api calls
+func (m *JSONRPCStoreMetrics) IterateAccountsInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "IterateAccounts"}).Inc()
+	}
+}
+
+// GetPreimage api calls
+func (m *JSONRPCStoreMetrics) GetPreimageInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetPreimage"}).Inc()
+	}
+}
+
+// GetStorageProof api calls
+func (m *JSONRPCStoreMetrics) GetStorageProofInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetStorageProof"}).Inc()
+	}
+}
+
+// GetBlockExecutionStats api calls
+func (m *JSONRPCStoreMetrics) GetBlockExecutionStatsInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetBlockExecutionStats"}).Inc()
+	}
+}
+
+// GetChainStats api calls
+func (m *JSONRPCStoreMetrics) GetChainStatsInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetChainStats"}).Inc()
+	}
+}
+
+// GetChainParams api calls
+func (m *JSONRPCStoreMetrics) GetChainParamsInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetChainParams"}).Inc()
+	}
+}
+
 // GetForksInTime api calls
 func (m *JSONRPCStoreMetrics) GetForksInTimeInc() {
 	if m.counter != nil {
@@ -58,6 +114,34 @@ func (m *JSONRPCStoreMetrics) GetCodeInc() {
 	}
 }
 
+// GetCodeByHash api calls
+func (m *JSONRPCStoreMetrics) GetCodeByHashInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetCodeByHash"}).Inc()
+	}
+}
+
+// ChaindbProperty api calls
+func (m *JSONRPCStoreMetrics) ChaindbPropertyInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "ChaindbProperty"}).Inc()
+	}
+}
+
+// GetBlockNumbersByStateRoot api calls
+func (m *JSONRPCStoreMetrics) GetBlockNumbersByStateRootInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetBlockNumbersByStateRoot"}).Inc()
+	}
+}
+
+// ChaindbCompact api calls
+func (m *JSONRPCStoreMetrics) ChaindbCompactInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "ChaindbCompact"}).Inc()
+	}
+}
+
 // Header api calls
 func (m *JSONRPCStoreMetrics) HeaderInc() {
 	if m.counter != nil {
@@ -72,6 +156,13 @@ func (m *JSONRPCStoreMetrics) GetHeaderByNumberInc() {
 	}
 }
 
+// GetHeadersInRange api calls
+func (m *JSONRPCStoreMetrics) GetHeadersInRangeInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetHeadersInRange"}).Inc()
+	}
+}
+
 // GetHeaderByHash api calls
 func (m *JSONRPCStoreMetrics) GetHeaderByHashInc() {
 	if m.counter != nil {
@@ -121,6 +212,13 @@ func (m *JSONRPCStoreMetrics) ApplyTxnInc() {
 	}
 }
 
+// TraceTxn api calls
+func (m *JSONRPCStoreMetrics) TraceTxnInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "TraceTxn"}).Inc()
+	}
+}
+
 // GetSyncProgression api calls
 func (m *JSONRPCStoreMetrics) GetSyncProgressionInc() {
 	if m.counter != nil {
@@ -142,6 +240,34 @@ func (m *JSONRPCStoreMetrics) PeerCountInc() {
 	}
 }
 
+// GetPeers api calls
+func (m *JSONRPCStoreMetrics) GetPeersInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetPeers"}).Inc()
+	}
+}
+
+// GetNodeInfo api calls
+func (m *JSONRPCStoreMetrics) GetNodeInfoInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetNodeInfo"}).Inc()
+	}
+}
+
+// AddPeer api calls
+func (m *JSONRPCStoreMetrics) AddPeerInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "AddPeer"}).Inc()
+	}
+}
+
+// RemovePeer api calls
+func (m *JSONRPCStoreMetrics) RemovePeerInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "RemovePeer"}).Inc()
+	}
+}
+
 // GetTxs api calls
 func (m *JSONRPCStoreMetrics) GetTxsInc() {
 	if m.counter != nil {
@@ -149,6 +275,27 @@ func (m *JSONRPCStoreMetrics) GetTxsInc() {
 	}
 }
 
+// GetTxCounts api calls
+func (m *JSONRPCStoreMetrics) GetTxCountsInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetTxCounts"}).Inc()
+	}
+}
+
+// GetAccountTxs api calls
+func (m *JSONRPCStoreMetrics) GetAccountTxsInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "GetAccountTxs"}).Inc()
+	}
+}
+
+// ValidateTx api calls
+func (m *JSONRPCStoreMetrics) ValidateTxInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "ValidateTx"}).Inc()
+	}
+}
+
 // GetCapacity api calls
 func (m *JSONRPCStoreMetrics) GetCapacityInc() {
 	if m.counter != nil {
@@ -156,10 +303,38 @@ func (m *JSONRPCStoreMetrics) GetCapacityInc() {
 	}
 }
 
-// SubscribeEvents api calls
-func (m *JSONRPCStoreMetrics) SubscribeEventsInc() {
+// SubscribeEventsWithFilter api calls
+func (m *JSONRPCStoreMetrics) SubscribeEventsWithFilterInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "SubscribeEventsWithFilter"}).Inc()
+	}
+}
+
+// SubscribePendingTxs api calls
+func (m *JSONRPCStoreMetrics) SubscribePendingTxsInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "SubscribePendingTxs"}).Inc()
+	}
+}
+
+// FilterLogBlocks api calls
+func (m *JSONRPCStoreMetrics) FilterLogBlocksInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "FilterLogBlocks"}).Inc()
+	}
+}
+
+// FilterSystemTxBlocks api calls
+func (m *JSONRPCStoreMetrics) FilterSystemTxBlocksInc() {
+	if m.counter != nil {
+		m.counter.With(prometheus.Labels{"method": "FilterSystemTxBlocks"}).Inc()
+	}
+}
+
+// GetSystemTxTypes api calls
+func (m *JSONRPCStoreMetrics) GetSystemTxTypesInc() {
 	if m.counter != nil {
-		m.counter.With(prometheus.Labels{"method": "SubscribeEvents"}).Inc()
+		m.counter.With(prometheus.Labels{"method": "GetSystemTxTypes"}).Inc()
 	}
 }
 
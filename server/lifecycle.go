@@ -0,0 +1,109 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// defaultModuleCloseTimeout bounds the closing of a module, the block write queue is
+// flushed within it
+const defaultModuleCloseTimeout = 30 * time.Second
+
+var ErrShutdownBlocked = errors.New("module blocked shutdown")
+
+// lifecycleModule is a started module of the server
+type lifecycleModule struct {
+	name    string
+	closeFn func() error
+}
+
+// lifecycle closes the modules in the reverse order of their registration, so that a
+// module is closed before the modules it depends on. The modules are registered once
+// started.
+type lifecycle struct {
+	logger  hclog.Logger
+	timeout time.Duration // bound of closing a module
+
+	lock    sync.Mutex
+	modules []*lifecycleModule
+	closed  bool
+	err     error // result of the close
+}
+
+func newLifecycle(logger hclog.Logger, timeout time.Duration) *lifecycle {
+	return &lifecycle{
+		logger:  logger.Named("lifecycle"),
+		timeout: timeout,
+	}
+}
+
+// register registers the started module, it is closed right away if the lifecycle
+// is closed already
+func (l *lifecycle) register(name string, closeFn func() error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.closed {
+		l.closeModule(&lifecycleModule{name: name, closeFn: closeFn}) //nolint:errcheck
+
+		return
+	}
+
+	l.modules = append(l.modules, &lifecycleModule{name: name, closeFn: closeFn})
+}
+
+// close closes the registered modules, it returns the result of the first call
+// afterwards. The shutdown stops at a module not closed in time, and the modules it
+// depends on are left open, as they might still be in use.
+func (l *lifecycle) close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.closed {
+		return l.err
+	}
+
+	l.closed = true
+
+	for i := len(l.modules) - 1; i >= 0; i-- {
+		if err := l.closeModule(l.modules[i]); errors.Is(err, ErrShutdownBlocked) {
+			l.err = err
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeModule closes the module within the timeout. The errors of the module are
+// logged only, the others are still closed.
+func (l *lifecycle) closeModule(m *lifecycleModule) error {
+	l.logger.Info("close module", "module", m.name)
+
+	doneCh := make(chan error, 1)
+
+	go func() {
+		doneCh <- m.closeFn()
+	}()
+
+	timer := time.NewTimer(l.timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-doneCh:
+		if err != nil {
+			l.logger.Error("failed to close module", "module", m.name, "err", err)
+		}
+
+		return err
+	case <-timer.C:
+		l.logger.Error("module blocked shutdown", "module", m.name, "timeout", l.timeout)
+
+		return fmt.Errorf("%w: %s not closed in %s", ErrShutdownBlocked, m.name, l.timeout)
+	}
+}
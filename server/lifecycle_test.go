@@ -0,0 +1,136 @@
+package server
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+// closeRecorder records the order the modules are closed in
+type closeRecorder struct {
+	lock   sync.Mutex
+	closed []string
+}
+
+func (r *closeRecorder) closeFn(name string, err error) func() error {
+	return func() error {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		r.closed = append(r.closed, name)
+
+		return err
+	}
+}
+
+func (r *closeRecorder) closedModules() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]string{}, r.closed...)
+}
+
+func TestLifecycle_CloseOrder(t *testing.T) {
+	t.Parallel()
+
+	recorder := &closeRecorder{}
+	l := newLifecycle(hclog.NewNullLogger(), time.Second)
+
+	l.register("storage", recorder.closeFn("storage", nil))
+	l.register("blockchain", recorder.closeFn("blockchain", errors.New("flush failed")))
+	l.register("network", recorder.closeFn("network", nil))
+	l.register("consensus", recorder.closeFn("consensus", nil))
+
+	// the failed modules do not stop the shutdown
+	assert.NoError(t, l.close())
+	assert.Equal(t, []string{"consensus", "network", "blockchain", "storage"}, recorder.closedModules())
+}
+
+func TestLifecycle_CloseIdempotent(t *testing.T) {
+	t.Parallel()
+
+	recorder := &closeRecorder{}
+	l := newLifecycle(hclog.NewNullLogger(), time.Second)
+
+	l.register("storage", recorder.closeFn("storage", nil))
+	l.register("network", recorder.closeFn("network", nil))
+
+	assert.NoError(t, l.close())
+	assert.NoError(t, l.close())
+	assert.Equal(t, []string{"network", "storage"}, recorder.closedModules())
+
+	// the modules started after the shutdown are closed right away
+	l.register("txpool", recorder.closeFn("txpool", nil))
+	assert.Equal(t, []string{"network", "storage", "txpool"}, recorder.closedModules())
+
+	assert.NoError(t, l.close())
+	assert.Len(t, recorder.closedModules(), 3)
+}
+
+func TestLifecycle_CloseTimeout(t *testing.T) {
+	t.Parallel()
+
+	var (
+		recorder  = &closeRecorder{}
+		l         = newLifecycle(hclog.NewNullLogger(), 50*time.Millisecond)
+		releaseCh = make(chan struct{})
+		calls     int32
+	)
+
+	defer close(releaseCh)
+
+	l.register("storage", recorder.closeFn("storage", nil))
+	l.register("blockchain", func() error {
+		atomic.AddInt32(&calls, 1)
+
+		<-releaseCh
+
+		return nil
+	})
+	l.register("network", recorder.closeFn("network", nil))
+
+	start := time.Now()
+	err := l.close()
+
+	// the timeout is per module, the shutdown is not stuck on the blocked one
+	assert.Less(t, time.Since(start), time.Second)
+
+	// the blocked module is reported
+	assert.ErrorIs(t, err, ErrShutdownBlocked)
+	assert.Contains(t, err.Error(), "blockchain")
+
+	// the modules it depends on are left open
+	assert.Equal(t, []string{"network"}, recorder.closedModules())
+
+	// the blocked shutdown is reported again, without closing the module twice
+	assert.Equal(t, err, l.close())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestLifecycle_CloseTimeoutPerModule(t *testing.T) {
+	t.Parallel()
+
+	recorder := &closeRecorder{}
+	l := newLifecycle(hclog.NewNullLogger(), 200*time.Millisecond)
+
+	slowFn := func(name string) func() error {
+		return func() error {
+			time.Sleep(100 * time.Millisecond)
+
+			return recorder.closeFn(name, nil)()
+		}
+	}
+
+	// each module is within the timeout, though all of them are not
+	l.register("storage", slowFn("storage"))
+	l.register("blockchain", slowFn("blockchain"))
+	l.register("network", slowFn("network"))
+
+	assert.NoError(t, l.close())
+	assert.Equal(t, []string{"network", "blockchain", "storage"}, recorder.closedModules())
+}
@@ -43,6 +43,16 @@ type SystemClient interface {
 	WhitelistDeleteList(ctx context.Context, in *WhitelistDeleteListRequest, opts ...grpc.CallOption) (*WhitelistDeleteListResponse, error)
 	// query ddos contract list
 	DDOSContractList(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DDOSContractListResponse, error)
+	// NodeRecord returns the signed identity record of the node
+	NodeRecord(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NodeRecordResponse, error)
+	// Compact compacts the databases to reclaim the space of the deleted pairs
+	Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error)
+	// Halt stops the block imports and sealing of the chain
+	Halt(ctx context.Context, in *HaltRequest, opts ...grpc.CallOption) (*HaltStatus, error)
+	// Resume resumes the block imports and sealing of the halted chain
+	Resume(ctx context.Context, in *HaltRequest, opts ...grpc.CallOption) (*HaltStatus, error)
+	// DryRunBlock verifies and executes the sealed block without importing it
+	DryRunBlock(ctx context.Context, in *DryRunBlockRequest, opts ...grpc.CallOption) (*DryRunBlockResponse, error)
 }
 
 type systemClient struct {
@@ -189,6 +199,51 @@ func (c *systemClient) DDOSContractList(ctx context.Context, in *emptypb.Empty,
 	return out, nil
 }
 
+func (c *systemClient) NodeRecord(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NodeRecordResponse, error) {
+	out := new(NodeRecordResponse)
+	err := c.cc.Invoke(ctx, "/v1.System/NodeRecord", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *systemClient) Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error) {
+	out := new(CompactResponse)
+	err := c.cc.Invoke(ctx, "/v1.System/Compact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *systemClient) Halt(ctx context.Context, in *HaltRequest, opts ...grpc.CallOption) (*HaltStatus, error) {
+	out := new(HaltStatus)
+	err := c.cc.Invoke(ctx, "/v1.System/Halt", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *systemClient) Resume(ctx context.Context, in *HaltRequest, opts ...grpc.CallOption) (*HaltStatus, error) {
+	out := new(HaltStatus)
+	err := c.cc.Invoke(ctx, "/v1.System/Resume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *systemClient) DryRunBlock(ctx context.Context, in *DryRunBlockRequest, opts ...grpc.CallOption) (*DryRunBlockResponse, error) {
+	out := new(DryRunBlockResponse)
+	err := c.cc.Invoke(ctx, "/v1.System/DryRunBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SystemServer is the server API for System service.
 // All implementations must embed UnimplementedSystemServer
 // for forward compatibility
@@ -213,6 +268,16 @@ type SystemServer interface {
 	WhitelistDeleteList(context.Context, *WhitelistDeleteListRequest) (*WhitelistDeleteListResponse, error)
 	// query ddos contract list
 	DDOSContractList(context.Context, *emptypb.Empty) (*DDOSContractListResponse, error)
+	// NodeRecord returns the signed identity record of the node
+	NodeRecord(context.Context, *emptypb.Empty) (*NodeRecordResponse, error)
+	// Compact compacts the databases to reclaim the space of the deleted pairs
+	Compact(context.Context, *CompactRequest) (*CompactResponse, error)
+	// Halt stops the block imports and sealing of the chain
+	Halt(context.Context, *HaltRequest) (*HaltStatus, error)
+	// Resume resumes the block imports and sealing of the halted chain
+	Resume(context.Context, *HaltRequest) (*HaltStatus, error)
+	// DryRunBlock verifies and executes the sealed block without importing it
+	DryRunBlock(context.Context, *DryRunBlockRequest) (*DryRunBlockResponse, error)
 	mustEmbedUnimplementedSystemServer()
 }
 
@@ -250,6 +315,21 @@ func (UnimplementedSystemServer) WhitelistDeleteList(context.Context, *Whitelist
 func (UnimplementedSystemServer) DDOSContractList(context.Context, *emptypb.Empty) (*DDOSContractListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DDOSContractList not implemented")
 }
+func (UnimplementedSystemServer) NodeRecord(context.Context, *emptypb.Empty) (*NodeRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NodeRecord not implemented")
+}
+func (UnimplementedSystemServer) Compact(context.Context, *CompactRequest) (*CompactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Compact not implemented")
+}
+func (UnimplementedSystemServer) Halt(context.Context, *HaltRequest) (*HaltStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Halt not implemented")
+}
+func (UnimplementedSystemServer) Resume(context.Context, *HaltRequest) (*HaltStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedSystemServer) DryRunBlock(context.Context, *DryRunBlockRequest) (*DryRunBlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DryRunBlock not implemented")
+}
 func (UnimplementedSystemServer) mustEmbedUnimplementedSystemServer() {}
 
 // UnsafeSystemServer may be embedded to opt out of forward compatibility for this service.
@@ -449,6 +529,96 @@ func _System_DDOSContractList_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _System_NodeRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemServer).NodeRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.System/NodeRecord",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemServer).NodeRecord(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _System_Compact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemServer).Compact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.System/Compact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemServer).Compact(ctx, req.(*CompactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _System_Halt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HaltRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemServer).Halt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.System/Halt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemServer).Halt(ctx, req.(*HaltRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _System_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HaltRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.System/Resume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemServer).Resume(ctx, req.(*HaltRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _System_DryRunBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DryRunBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SystemServer).DryRunBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.System/DryRunBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SystemServer).DryRunBlock(ctx, req.(*DryRunBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // System_ServiceDesc is the grpc.ServiceDesc for System service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -488,6 +658,26 @@ var System_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DDOSContractList",
 			Handler:    _System_DDOSContractList_Handler,
 		},
+		{
+			MethodName: "NodeRecord",
+			Handler:    _System_NodeRecord_Handler,
+		},
+		{
+			MethodName: "Compact",
+			Handler:    _System_Compact_Handler,
+		},
+		{
+			MethodName: "Halt",
+			Handler:    _System_Halt_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _System_Resume_Handler,
+		},
+		{
+			MethodName: "DryRunBlock",
+			Handler:    _System_DryRunBlock_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -9,23 +9,29 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/dogechain-lab/dogechain/archive"
 	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/blockchain/storage"
 	"github.com/dogechain-lab/dogechain/blockchain/storage/kvstorage"
 	"github.com/dogechain-lab/dogechain/chain"
 	"github.com/dogechain-lab/dogechain/consensus"
+	"github.com/dogechain-lab/dogechain/consensus/lease"
+	"github.com/dogechain-lab/dogechain/consensus/signer"
 	"github.com/dogechain-lab/dogechain/crypto"
 	"github.com/dogechain-lab/dogechain/graphql"
 	"github.com/dogechain-lab/dogechain/helper/common"
 	"github.com/dogechain-lab/dogechain/helper/gasprice"
 	"github.com/dogechain-lab/dogechain/helper/kvdb"
 	"github.com/dogechain-lab/dogechain/helper/progress"
+	"github.com/dogechain-lab/dogechain/helper/recovery"
 	"github.com/dogechain-lab/dogechain/helper/telemetry"
 	"github.com/dogechain-lab/dogechain/jsonrpc"
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/secrets"
+	secretsHelper "github.com/dogechain-lab/dogechain/secrets/helper"
 	"github.com/dogechain-lab/dogechain/server/proto"
 	"github.com/dogechain-lab/dogechain/state"
 	itrie "github.com/dogechain-lab/dogechain/state/immutable-trie"
@@ -49,6 +55,7 @@ type Server struct {
 	config *Config
 
 	state        state.State
+	stateDB      itrie.StateDB
 	stateStorage itrie.Storage
 
 	consensus consensus.Consensus
@@ -87,10 +94,23 @@ type Server struct {
 
 	// gas price oracle
 	gpo *gasprice.Oracle
+
+	// verifies the operator approvals of the admin requests, nil if not required
+	adminApprover *adminApprover
+
+	// closes the started modules
+	lifecycle *lifecycle
+
+	// receives the error the server halts on by itself
+	haltCh   chan error
+	haltOnce sync.Once
 }
 
 const (
 	loggerDomainName = "dogechain"
+
+	// apiShutdownTimeout bounds the in-flight requests of the api servers on shutdown
+	apiShutdownTimeout = 5 * time.Second
 )
 
 var dirPaths = []string{
@@ -143,42 +163,95 @@ func newLoggerFromConfig(config *Config) (hclog.Logger, error) {
 	return newCLILogger(config), nil
 }
 
-func newLevelDBBuilder(logger hclog.Logger, config *Config, path string) kvdb.LevelDBBuilder {
+func newLevelDBBuilder(
+	logger hclog.Logger,
+	opts *LeveldbOptions,
+	cacheSize int,
+	path string,
+	metrics *kvdb.Metrics,
+) kvdb.LevelDBBuilder {
 	leveldbBuilder := kvdb.NewLevelDBBuilder(
 		logger,
 		path,
 	)
 
-	// trie cache + blockchain cache = config.LeveldbOptions.CacheSize / 2
-	leveldbBuilder.SetCacheSize(config.LeveldbOptions.CacheSize / 2).
-		SetHandles(config.LeveldbOptions.Handles).
-		SetBloomKeyBits(config.LeveldbOptions.BloomKeyBits).
-		SetCompactionTableSize(config.LeveldbOptions.CompactionTableSize).
-		SetCompactionTotalSize(config.LeveldbOptions.CompactionTotalSize).
-		SetNoSync(config.LeveldbOptions.NoSync)
+	leveldbBuilder.SetCacheSize(cacheSize).
+		SetHandles(opts.Handles).
+		SetBloomKeyBits(opts.BloomKeyBits).
+		SetCompactionTableSize(opts.CompactionTableSize).
+		SetCompactionTotalSize(opts.CompactionTotalSize).
+		SetNoSync(opts.NoSync).
+		SetSlowOpThreshold(opts.SlowOpThreshold).
+		SetMetrics(metrics)
 
 	return leveldbBuilder
 }
 
-// NewServer creates a new Minimal server, using the passed in configuration
-func NewServer(config *Config) (*Server, error) {
+// newBlockchainStorageBuilder returns the storage builder of the blockchain, with the
+// separate receipts database if configured
+func newBlockchainStorageBuilder(logger hclog.Logger, config *Config, metrics *serverMetrics) storage.StorageBuilder {
+	// trie cache + blockchain cache = config.LeveldbOptions.CacheSize / 2
+	leveldbBuilder := newLevelDBBuilder(
+		logger,
+		config.LeveldbOptions,
+		config.LeveldbOptions.CacheSize/2,
+		filepath.Join(config.DataDir, "blockchain"),
+		metrics.blockchainDB,
+	)
+
+	builder := kvstorage.NewLevelDBStorageBuilder(logger, leveldbBuilder)
+
+	if opts := config.ReceiptsLeveldbOptions; opts != nil {
+		builder = kvstorage.NewLevelDBStorageBuilderWithReceipts(
+			logger,
+			leveldbBuilder,
+			newLevelDBBuilder(
+				logger,
+				opts,
+				opts.CacheSize,
+				filepath.Join(config.DataDir, "receipts"),
+				metrics.receiptsDB,
+			),
+		)
+	}
+
+	return builder.
+		SetIdealBatchSize(config.LeveldbOptions.IdealBatchSize).
+		SetKeyLayout(config.DBKeyLayout)
+}
+
+// NewServer creates a new Minimal server, using the passed in configuration. The
+// started modules are closed if it fails.
+func NewServer(config *Config) (_ *Server, err error) {
 	logger, err := newLoggerFromConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("could not setup new logger instance, %w", err)
 	}
 
 	m := &Server{
-		logger: logger,
-		ctx:    context.Background(),
-		config: config,
-		chain:  config.Chain,
-		grpcServer: grpc.NewServer(
-			grpc.MaxRecvMsgSize(common.MaxGrpcMsgSize),
-			grpc.MaxSendMsgSize(common.MaxGrpcMsgSize),
-		),
+		logger:             logger,
+		ctx:                context.Background(),
+		config:             config,
+		chain:              config.Chain,
 		restoreProgression: progress.NewProgressionWrapper(progress.ChainSyncRestore),
+		lifecycle:          newLifecycle(logger, defaultModuleCloseTimeout),
+		haltCh:             make(chan error, 1),
 	}
 
+	// the admin methods are authenticated before served
+	m.grpcServer = grpc.NewServer(
+		grpc.MaxRecvMsgSize(common.MaxGrpcMsgSize),
+		grpc.MaxSendMsgSize(common.MaxGrpcMsgSize),
+		grpc.UnaryInterceptor(m.adminUnaryInterceptor),
+	)
+
+	defer func() {
+		if err != nil {
+			// release the databases for the next start
+			m.lifecycle.close() //nolint:errcheck
+		}
+	}()
+
 	m.logger.Info("Data dir", "path", config.DataDir)
 
 	// Generate all the paths in the dataDir
@@ -189,10 +262,17 @@ func NewServer(config *Config) (*Server, error) {
 	if config.Telemetry.PrometheusAddr != nil {
 		m.serverMetrics = metricProvider("dogechain", config.Chain.Name, true, config.Telemetry.EnableIOMetrics)
 		m.prometheusServer = m.startPrometheusServer(config.Telemetry.PrometheusAddr)
+
+		m.lifecycle.register("prometheus", func() error {
+			return m.prometheusServer.Shutdown(context.Background())
+		})
 	} else {
 		m.serverMetrics = metricProvider("dogechain", config.Chain.Name, false, false)
 	}
 
+	// count the recovered panics of the workers
+	recovery.SetMetrics(m.serverMetrics.recovery)
+
 	if config.Telemetry.EnableJaeger {
 		var err error
 
@@ -209,6 +289,10 @@ func NewServer(config *Config) (*Server, error) {
 		m.tracerProvider = telemetry.NewNilTracerProvider(m.ctx)
 	}
 
+	m.lifecycle.register("tracer", func() error {
+		return m.tracerProvider.Shutdown(context.Background())
+	})
+
 	// Set up the secrets manager
 	if err := m.setupSecretsManager(); err != nil {
 		return nil, fmt.Errorf("failed to set up the secrets manager: %w", err)
@@ -231,12 +315,28 @@ func NewServer(config *Config) (*Server, error) {
 		m.network = network
 	}
 
+	// the operator approvals are bound to the chain and the node
+	m.adminApprover, err = newAdminApprover(
+		config.AdminApproval,
+		AdminTarget{
+			ChainID: uint64(config.Chain.Params.ChainID),
+			NodeID:  m.network.AddrInfo().ID.String(),
+		},
+		filepath.Join(config.DataDir, adminApprovalsFile),
+		logger,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	// start blockchain object
 	stateStorage, err := func() (itrie.Storage, error) {
 		leveldbBuilder := newLevelDBBuilder(
 			logger,
-			config,
+			config.LeveldbOptions,
+			config.LeveldbOptions.CacheSize/2,
 			filepath.Join(m.config.DataDir, "trie"),
+			m.serverMetrics.trieDB,
 		)
 
 		return itrie.NewLevelDBStorage(leveldbBuilder)
@@ -247,9 +347,18 @@ func NewServer(config *Config) (*Server, error) {
 	}
 
 	m.stateStorage = stateStorage
+	m.lifecycle.register("state storage", m.stateStorage.Close)
 
-	st := itrie.NewStateDB(stateStorage, logger, m.serverMetrics.trie)
+	st := itrie.NewStateDB(
+		stateStorage,
+		logger,
+		m.serverMetrics.trie,
+		itrie.WithCacheSize(config.CacheOptions.StateSize),
+		itrie.WithCodeCacheSize(config.CacheOptions.CodeSize),
+		itrie.WithPreimages(config.CacheOptions.Preimages),
+	)
 	m.state = st
+	m.stateDB = st
 
 	m.executor = state.NewExecutor(config.Chain.Params, st, logger)
 	m.executor.SetRuntime(precompiled.NewPrecompiled())
@@ -263,19 +372,12 @@ func NewServer(config *Config) (*Server, error) {
 
 	config.Chain.Genesis.StateRoot = genesisRoot
 
-	// create leveldb storageBuilder
-	leveldbBuilder := newLevelDBBuilder(
-		logger,
-		config,
-		filepath.Join(m.config.DataDir, "blockchain"),
-	)
-
 	// blockchain object
 	m.blockchain, err = blockchain.NewBlockchain(
 		logger,
 		config.Chain,
 		m.config.PriceLimit,
-		kvstorage.NewLevelDBStorageBuilder(logger, leveldbBuilder),
+		newBlockchainStorageBuilder(logger, config, m.serverMetrics),
 		nil,
 		m.executor,
 		m.serverMetrics.blockchain,
@@ -284,11 +386,34 @@ func NewServer(config *Config) (*Server, error) {
 		return nil, err
 	}
 
+	m.lifecycle.register("blockchain", m.blockchain.Close)
+
+	// the network serves the chain data, so it is closed before
+	m.lifecycle.register("network", m.network.Close)
+
+	m.blockchain.SetMaxReorgDepth(m.config.MaxReorgDepth)
+
+	// flush the block data in background
+	m.blockchain.EnableAsyncWrite(int(m.config.BlockWriteQueue))
+
+	// size the header and receipt caches by the working set
+	m.blockchain.EnableAdaptiveCaches(m.config.CacheOptions.Chain)
+
+	// slow down the sync under resource pressure, the consensus blocks are never delayed
+	m.blockchain.SetImportAdmission(blockchain.AdmissionConfig{
+		MaxWriteQueue: int(m.config.ImportMaxWriteQueue),
+		MaxHeapBytes:  m.config.ImportMaxHeapMB * 1024 * 1024,
+		MaxL0Tables:   int(m.config.ImportMaxL0Tables),
+	})
+
 	{ // gas price oracle
 		if m.config.GasPriceOracle.Default == nil {
 			m.config.GasPriceOracle.Default = big.NewInt(int64(m.config.PriceLimit))
 		}
 
+		// the oracle suggests prices from the gas price history of the blockchain
+		m.blockchain.SetGasPriceHistory(m.config.GasPriceOracle.Blocks, m.config.GasPriceOracle.IgnorePrice)
+
 		m.gpo, err = gasprice.NewOracle(m.blockchain, m.config.GasPriceOracle)
 		if err != nil {
 			return nil, err
@@ -337,6 +462,12 @@ func NewServer(config *Config) (*Server, error) {
 			return nil, err
 		}
 
+		m.lifecycle.register("txpool", func() error {
+			m.txpool.Close()
+
+			return nil
+		})
+
 		// use the eip155 signer
 		signer := crypto.NewEIP155Signer(uint64(m.config.Chain.Params.ChainID))
 		m.txpool.SetSigner(signer)
@@ -347,6 +478,9 @@ func NewServer(config *Config) (*Server, error) {
 		if err := m.setupConsensus(); err != nil {
 			return nil, err
 		}
+
+		// stops writing blocks before the chain is closed
+		m.lifecycle.register("consensus", m.consensus.Close)
 		m.blockchain.SetConsensus(m.consensus)
 	}
 
@@ -357,6 +491,38 @@ func NewServer(config *Config) (*Server, error) {
 		return nil, err
 	}
 
+	// index logs in background after the genesis is settled
+	if m.config.EnableLogIndex {
+		m.blockchain.EnableLogIndex()
+	}
+
+	if m.config.EnableBloomIndex {
+		m.blockchain.EnableBloomIndex()
+	}
+
+	// aggregate the chain usage in background
+	if m.config.EnableChainStats {
+		m.blockchain.EnableChainStats()
+	}
+
+	// index the system transactions in background
+	if m.config.EnableSystemTxIndex {
+		m.blockchain.EnableSystemTxIndex()
+	}
+
+	// delete stale tx lookups in background
+	m.blockchain.SetTxLookupLimit(m.config.TxLookupLimit)
+
+	// regenerate the missing receipts in background
+	if m.config.ReceiptsBackfill {
+		m.blockchain.EnableReceiptsBackfill(m.config.ReceiptsBackfillRate)
+	}
+
+	// warm up the caches in background
+	if m.config.Warmup.enabled() {
+		m.startWarmup()
+	}
+
 	// initialize data in consensus layer
 	if err := m.consensus.Initialize(); err != nil {
 		return nil, err
@@ -486,6 +652,50 @@ func (s *Server) setupSecretsManager() error {
 	return nil
 }
 
+// newSealLease creates the lease held while sealing, it returns nil if sealing without
+// the lease
+func (s *Server) newSealLease() (lease.Lease, error) {
+	if !s.config.Seal || s.config.SealLease == nil {
+		return nil, nil
+	}
+
+	config := *s.config.SealLease
+
+	// the instances are told apart by the host
+	if config.Holder == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+
+		config.Holder = fmt.Sprintf("%s:%d", host, os.Getpid())
+	}
+
+	l, err := lease.New(s.logger, config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the sealing lease, %w", err)
+	}
+
+	s.logger.Info("sealing in standby mode, waiting for the lease", "lease", config.URL, "holder", config.Holder)
+
+	return l, nil
+}
+
+// newRemoteSigner connects the remote signer holding the validator key, it returns nil
+// if the key is held by the secrets manager
+func (s *Server) newRemoteSigner() (signer.Signer, error) {
+	if s.config.RemoteSigner == nil {
+		return nil, nil
+	}
+
+	remote, err := signer.NewRemote(s.logger, *s.config.RemoteSigner)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect the remote signer, %w", err)
+	}
+
+	return remote, nil
+}
+
 // setupConsensus sets up the consensus mechanism
 func (s *Server) setupConsensus() error {
 	engineName := s.config.Chain.Params.GetEngine()
@@ -506,6 +716,16 @@ func (s *Server) setupConsensus() error {
 		Path:   filepath.Join(s.config.DataDir, "consensus"),
 	}
 
+	sealLease, err := s.newSealLease()
+	if err != nil {
+		return err
+	}
+
+	remoteSigner, err := s.newRemoteSigner()
+	if err != nil {
+		return err
+	}
+
 	consensus, err := engine(
 		&consensus.ConsensusParams{
 			Context:        context.Background(),
@@ -515,12 +735,24 @@ func (s *Server) setupConsensus() error {
 			Network:        s.network,
 			Blockchain:     s.blockchain,
 			Executor:       s.executor,
+			StateDB:        s.stateDB,
 			Grpc:           s.grpcServer,
 			Logger:         s.logger.Named("consensus"),
 			Metrics:        s.serverMetrics.consensus,
 			SecretsManager: s.secretsManager,
 			BlockTime:      s.config.BlockTime,
 			BlockBroadcast: s.config.BlockBroadcast,
+
+			MaxSenderTxs:      s.config.MaxSenderTxs,
+			MaxSenderGasShare: s.config.MaxSenderGasShare,
+
+			ExtraVanity: []byte(s.config.ExtraVanity),
+			TxPolicy:    s.config.TxPolicy,
+
+			SealLease:  sealLease,
+			Signer:     remoteSigner,
+			Downtime:   s.config.Downtime,
+			OnDowntime: s.handleDowntime,
 		},
 	)
 
@@ -555,6 +787,21 @@ func (s *Server) setupJSONRPC() error {
 		namespaces[i] = jsonrpc.Namespace(s)
 	}
 
+	cache, err := s.newJSONRPCResponseCache()
+	if err != nil {
+		return err
+	}
+
+	signer, err := s.newJSONRPCSigner()
+	if err != nil {
+		return err
+	}
+
+	authSecret, err := s.loadJSONRPCAuthSecret()
+	if err != nil {
+		return err
+	}
+
 	conf := &jsonrpc.Config{
 		Store:                    hub,
 		Addr:                     s.config.JSONRPC.JSONRPCAddr,
@@ -564,8 +811,19 @@ func (s *Server) setupJSONRPC() error {
 		BlockRangeLimit:          s.config.JSONRPC.BlockRangeLimit,
 		JSONNamespaces:           namespaces,
 		EnableWS:                 s.config.JSONRPC.EnableWS,
+		WSMaxMessageSize:         s.config.JSONRPC.WSMaxMessageSize,
+		WSMessageRateLimit:       s.config.JSONRPC.WSMessageRateLimit,
+		WSSendQueueSize:          s.config.JSONRPC.WSSendQueueSize,
+		WSDropPolicy:             jsonrpc.WSDropPolicy(s.config.JSONRPC.WSDropPolicy),
 		PriceLimit:               s.config.PriceLimit,
 		EnablePProf:              s.config.JSONRPC.EnablePprof,
+		ResponseCache:            cache,
+		Signer:                   signer,
+		RateLimit:                s.config.JSONRPC.RateLimit,
+		TrustedProxies:           s.config.JSONRPC.TrustedProxies,
+		AuthSecret:               authSecret,
+		AdminApprover:            s.jsonRPCAdminApprover(),
+		TLS:                      s.config.JSONRPC.TLS,
 		Metrics:                  s.serverMetrics.jsonrpc,
 	}
 
@@ -576,9 +834,99 @@ func (s *Server) setupJSONRPC() error {
 
 	s.jsonrpcServer = srv
 
+	s.lifecycle.register("jsonrpc", func() error {
+		return srv.Close(apiShutdownTimeout)
+	})
+
 	return nil
 }
 
+// loadJSONRPCAuthSecret returns the secret signing the bearer tokens, generated if not
+// present in the secrets manager. It returns nil if the token is not required.
+func (s *Server) loadJSONRPCAuthSecret() ([]byte, error) {
+	if !s.config.JSONRPC.Auth {
+		return nil, nil
+	}
+
+	if !s.secretsManager.HasSecret(secrets.JSONRPCAuthSecret) {
+		secret, err := secretsHelper.InitJSONRPCAuthSecret(s.secretsManager)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate the json-rpc auth secret, %w", err)
+		}
+
+		s.logger.Info("json-rpc auth secret generated", "name", secrets.JSONRPCAuthSecret)
+
+		return secret, nil
+	}
+
+	secret, err := secretsHelper.ReadJSONRPCAuthSecret(s.secretsManager)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the json-rpc auth secret, %w", err)
+	}
+
+	return secret, nil
+}
+
+// jsonRPCAdminApprover returns the approver of the json-rpc admin methods, nil if
+// approvals are not required
+func (s *Server) jsonRPCAdminApprover() jsonrpc.AdminApprover {
+	if s.adminApprover == nil {
+		return nil
+	}
+
+	return s.adminApprover
+}
+
+// newJSONRPCResponseCache creates the response cache of the immutable queries,
+// it returns nil if disabled
+func (s *Server) newJSONRPCResponseCache() (jsonrpc.ResponseCache, error) {
+	var redis jsonrpc.ResponseCache
+
+	if s.config.JSONRPC.CacheRedisURL != "" {
+		var err error
+
+		// the chains might share one redis
+		prefix := fmt.Sprintf("dogechain:%d:", s.config.Chain.Params.ChainID)
+
+		redis, err = jsonrpc.NewRedisCache(s.logger, s.config.JSONRPC.CacheRedisURL, prefix, jsonrpc.DefaultRedisCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect the redis cache: %w", err)
+		}
+	}
+
+	return jsonrpc.NewResponseCache(int(s.config.JSONRPC.CacheSize)*1024*1024, redis), nil
+}
+
+// newJSONRPCSigner creates the external signer of eth_sendTransaction, it returns nil
+// if disabled
+func (s *Server) newJSONRPCSigner() (jsonrpc.Signer, error) {
+	if s.config.JSONRPC.SignerAddr == "" {
+		return nil, nil
+	}
+
+	var rules *jsonrpc.SignerRules
+
+	if s.config.JSONRPC.SignerRulesPath != "" {
+		var err error
+
+		if rules, err = jsonrpc.LoadSignerRules(s.config.JSONRPC.SignerRulesPath); err != nil {
+			return nil, err
+		}
+	}
+
+	signer, err := jsonrpc.NewExternalSigner(
+		s.logger,
+		s.config.JSONRPC.SignerAddr,
+		rules,
+		crypto.NewEIP155Signer(uint64(s.config.Chain.Params.ChainID)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the external signer: %w", err)
+	}
+
+	return signer, nil
+}
+
 // setupGraphQL sets up the graphql server, using the set configuration
 func (s *Server) setupGraphQL() error {
 	if !s.config.EnableGraphQL {
@@ -605,6 +953,7 @@ func (s *Server) setupGraphQL() error {
 		AccessControlAllowOrigin: s.config.GraphQL.AccessControlAllowOrigin,
 		BlockRangeLimit:          s.config.GraphQL.BlockRangeLimit,
 		EnablePProf:              s.config.GraphQL.EnablePprof,
+		TLS:                      s.config.GraphQL.TLS,
 	}
 
 	srv, err := graphql.NewGraphQLService(s.logger, conf)
@@ -614,6 +963,10 @@ func (s *Server) setupGraphQL() error {
 
 	s.graphqlServer = srv
 
+	s.lifecycle.register("graphql", func() error {
+		return srv.Close(apiShutdownTimeout)
+	})
+
 	return nil
 }
 
@@ -632,11 +985,34 @@ func (s *Server) setupGRPC() error {
 		}
 	}()
 
+	s.lifecycle.register("grpc", s.closeGRPC)
+
 	s.logger.Info("GRPC server running", "addr", s.config.GRPCAddr.String())
 
 	return nil
 }
 
+// closeGRPC stops the grpc server, the in-flight calls are given the timeout to finish
+func (s *Server) closeGRPC() error {
+	stoppedCh := make(chan struct{})
+
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stoppedCh)
+	}()
+
+	timer := time.NewTimer(apiShutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-stoppedCh:
+	case <-timer.C:
+		s.grpcServer.Stop()
+	}
+
+	return nil
+}
+
 // Chain returns the chain object of the client
 func (s *Server) Chain() *chain.Chain {
 	return s.chain
@@ -647,63 +1023,37 @@ func (s *Server) JoinPeer(rawPeerMultiaddr string, static bool) error {
 	return s.network.JoinPeer(rawPeerMultiaddr, static)
 }
 
-// Close closes the server
-// sequence:
-//
-//	consensus:
-//		wait for consensus exit any IbftState,
-//		stop write any block to blockchain storage and
-//		stop write any state to state storage
-//
-//	txpool: stop accepting new transactions
-//	networking: stop transport
-//	stateStorage: safe close state storage
-//	blockchain: safe close state storage
-func (s *Server) Close() {
-	s.logger.Info("close consensus layer")
-
-	// Close the consensus layer
-	if err := s.consensus.Close(); err != nil {
-		s.logger.Error("failed to close consensus", "err", err.Error())
-	}
-
-	s.logger.Info("close txpool")
-
-	// close the txpool's main loop
-	s.txpool.Close()
+// ErrValidatorDowntime is the error the server halts on, once the validator missed too
+// many turns with the exit action
+var ErrValidatorDowntime = errors.New("validator downtime")
 
-	s.logger.Info("close network layer")
-
-	// Close the networking layer
-	if err := s.network.Close(); err != nil {
-		s.logger.Error("failed to close networking", "err", err.Error())
+// handleDowntime acts on the downtime of the validator, the consensus has taken its
+// action already
+func (s *Server) handleDowntime(event *consensus.DowntimeEvent) {
+	if event.Action == consensus.DowntimeActionExit {
+		s.halt(fmt.Errorf("%w: missed %d turns in a row at block %d",
+			ErrValidatorDowntime, event.MissedTurns, event.Height))
 	}
+}
 
-	s.logger.Info("close state storage")
-
-	// Close the state storage
-	if err := s.stateStorage.Close(); err != nil {
-		s.logger.Error("failed to close storage for trie", "err", err.Error())
-	}
-
-	s.logger.Info("close blockchain storage")
-
-	// Close the blockchain layer
-	if err := s.blockchain.Close(); err != nil {
-		s.logger.Error("failed to close blockchain", "err", err.Error())
-	}
+// halt stops the server with the error, the first error is kept
+func (s *Server) halt(err error) {
+	s.haltOnce.Do(func() {
+		s.haltCh <- err
+	})
+}
 
-	if s.prometheusServer != nil {
-		if err := s.prometheusServer.Shutdown(context.Background()); err != nil {
-			s.logger.Error("Prometheus server shutdown error", err)
-		}
-	}
+// Halted returns the channel receiving the error the server halts on by itself, the
+// server should be closed then
+func (s *Server) Halted() <-chan error {
+	return s.haltCh
+}
 
-	if s.tracerProvider != nil {
-		if err := s.tracerProvider.Shutdown(context.Background()); err != nil {
-			s.logger.Error("Tracer provider shutdown error", err)
-		}
-	}
+// Close closes the modules of the server in the reverse order of their start, the
+// api servers first, then the consensus which stops writing blocks, and the storages
+// at last. It is idempotent, and reports the module blocking the shutdown.
+func (s *Server) Close() error {
+	return s.lifecycle.close()
 }
 
 // Entry is a backend configuration entry
@@ -3,6 +3,8 @@ package server
 import (
 	"github.com/dogechain-lab/dogechain/blockchain"
 	"github.com/dogechain-lab/dogechain/consensus"
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
+	"github.com/dogechain-lab/dogechain/helper/recovery"
 	"github.com/dogechain-lab/dogechain/jsonrpc"
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/txpool"
@@ -19,6 +21,11 @@ type serverMetrics struct {
 	jsonrpc      *jsonrpc.Metrics
 	jsonrpcStore *JSONRPCStoreMetrics
 	trie         itrie.Metrics
+	recovery     *recovery.Metrics
+	// the storages are not metered if nil
+	trieDB       *kvdb.Metrics
+	blockchainDB *kvdb.Metrics
+	receiptsDB   *kvdb.Metrics
 }
 
 // metricProvider serverMetric instance for the given ChainID and nameSpace
@@ -32,6 +39,10 @@ func metricProvider(nameSpace string, chainID string, metricsRequired bool, trac
 			jsonrpc:      jsonrpc.GetPrometheusMetrics(nameSpace, "chain_id", chainID),
 			jsonrpcStore: NewJSONRPCStoreMetrics(nameSpace, "chain_id", chainID),
 			trie:         itrie.GetPrometheusMetrics(nameSpace, trackingIOTimer, "chain_id", chainID),
+			recovery:     recovery.GetPrometheusMetrics(nameSpace, "chain_id", chainID),
+			trieDB:       kvdb.GetPrometheusMetrics(nameSpace, "chain_id", chainID, "db", "trie"),
+			blockchainDB: kvdb.GetPrometheusMetrics(nameSpace, "chain_id", chainID, "db", "blockchain"),
+			receiptsDB:   kvdb.GetPrometheusMetrics(nameSpace, "chain_id", chainID, "db", "receipts"),
 		}
 	}
 
@@ -43,5 +54,6 @@ func metricProvider(nameSpace string, chainID string, metricsRequired bool, trac
 		jsonrpc:      jsonrpc.NilMetrics(),
 		jsonrpcStore: JSONRPCStoreNilMetrics(),
 		trie:         itrie.NilMetrics(),
+		recovery:     recovery.NilMetrics(),
 	}
 }
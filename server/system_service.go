@@ -5,12 +5,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/dogechain-lab/dogechain/blockchain"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/helper/kvdb"
 	"github.com/dogechain-lab/dogechain/network/common"
 	"github.com/dogechain-lab/dogechain/server/proto"
 	"github.com/dogechain-lab/dogechain/txpool"
 	"github.com/dogechain-lab/dogechain/types"
+	"github.com/dogechain-lab/dogechain/types/buildroot"
 	"github.com/libp2p/go-libp2p/core/peer"
 	empty "google.golang.org/protobuf/types/known/emptypb"
 )
@@ -276,6 +280,198 @@ func (s *systemService) DDOSContractList(
 	return rsp, nil
 }
 
+// NodeRecord implements the 'node-id' operator service
+func (s *systemService) NodeRecord(
+	ctx context.Context,
+	req *empty.Empty,
+) (*proto.NodeRecordResponse, error) {
+	rec, err := s.server.network.NodeRecord()
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]string, 0, len(rec.Addrs))
+	for _, addr := range rec.Addrs {
+		// full multiaddr with the peer ID, as bootnode and static peer configs require
+		addrs = append(addrs, fmt.Sprintf("%s/p2p/%s", addr.String(), rec.ID.String()))
+	}
+
+	return &proto.NodeRecordResponse{
+		Id:        rec.ID.String(),
+		Addrs:     addrs,
+		Protocols: rec.Protocols,
+		Record:    rec.Envelope,
+	}, nil
+}
+
+// the databases compacted on demand
+const (
+	trieDatabase       = "trie"
+	blockchainDatabase = "blockchain"
+)
+
+var errUnknownDatabase = errors.New("unknown database")
+
+// Compact implements the 'db compact' operator service
+func (s *systemService) Compact(
+	ctx context.Context,
+	req *proto.CompactRequest,
+) (*proto.CompactResponse, error) {
+	databases := []string{trieDatabase, blockchainDatabase}
+
+	if req.Database != "" {
+		databases = []string{req.Database}
+	}
+
+	// the empty keys stand for the boundaries of the databases
+	var start, limit []byte
+
+	if len(req.Start) > 0 {
+		start = req.Start
+	}
+
+	if len(req.Limit) > 0 {
+		limit = req.Limit
+	}
+
+	begin := time.Now()
+
+	for _, name := range databases {
+		compactBegin := time.Now()
+
+		s.server.logger.Info("compacting database", "database", name,
+			"start", hex.EncodeToHex(start), "limit", hex.EncodeToHex(limit))
+
+		if err := s.server.compactDatabase(name, start, limit); err != nil {
+			return nil, fmt.Errorf("failed to compact %s: %w", name, err)
+		}
+
+		s.server.logger.Info("compacted database", "database", name, "elapsed", time.Since(compactBegin))
+	}
+
+	return &proto.CompactResponse{
+		Databases: databases,
+		Elapsed:   time.Since(begin).Round(time.Millisecond).String(),
+	}, nil
+}
+
+// compactDatabase compacts the keys within [start, limit) of the named database
+func (s *Server) compactDatabase(name string, start, limit []byte) error {
+	switch name {
+	case trieDatabase:
+		compacter, ok := s.stateStorage.(kvdb.KVCompacter)
+		if !ok {
+			return kvdb.ErrCompactNotSupported
+		}
+
+		return compacter.Compact(start, limit)
+	case blockchainDatabase:
+		return s.blockchain.Compact(start, limit)
+	default:
+		return fmt.Errorf("%w: %s", errUnknownDatabase, name)
+	}
+}
+
+var errHaltAuditFields = errors.New("operator and reason are required for the audit log")
+
+// Halt implements the 'chain halt' operator service
+func (s *systemService) Halt(ctx context.Context, req *proto.HaltRequest) (*proto.HaltStatus, error) {
+	caller := adminCallerFromContext(ctx)
+
+	if req.Operator == "" || req.Reason == "" {
+		return nil, errHaltAuditFields
+	}
+
+	// the authenticated caller is recorded, the operator is only claimed by the request
+	halt, changed, err := s.server.blockchain.Halt(caller, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	s.server.logger.Named("audit").Warn("halt chain",
+		"operator", req.Operator,
+		"caller", caller,
+		"reason", req.Reason,
+		"halted", changed,
+	)
+
+	return toProtoHaltStatus(halt), nil
+}
+
+// Resume implements the 'chain resume' operator service
+func (s *systemService) Resume(ctx context.Context, req *proto.HaltRequest) (*proto.HaltStatus, error) {
+	caller := adminCallerFromContext(ctx)
+
+	if req.Operator == "" || req.Reason == "" {
+		return nil, errHaltAuditFields
+	}
+
+	// the authenticated caller is recorded, the operator is only claimed by the request
+	halt, changed, err := s.server.blockchain.Resume(caller, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	s.server.logger.Named("audit").Warn("resume chain",
+		"operator", req.Operator,
+		"caller", caller,
+		"reason", req.Reason,
+		"resumed", changed,
+	)
+
+	return toProtoHaltStatus(halt), nil
+}
+
+func toProtoHaltStatus(halt blockchain.HaltStatus) *proto.HaltStatus {
+	status := &proto.HaltStatus{
+		Halted:   halt.Halted,
+		Operator: halt.Operator,
+		Reason:   halt.Reason,
+	}
+
+	// never changed
+	if !halt.Since.IsZero() {
+		status.Since = halt.Since.Unix()
+	}
+
+	return status
+}
+
+// DryRunBlock implements the 'dry-run' operator service
+func (s *systemService) DryRunBlock(
+	ctx context.Context,
+	req *proto.DryRunBlockRequest,
+) (*proto.DryRunBlockResponse, error) {
+	block := &types.Block{}
+	if err := block.UnmarshalRLP(req.Block); err != nil {
+		return nil, fmt.Errorf("invalid block rlp: %w", err)
+	}
+
+	result, verifyErr := s.server.blockchain.DryRunBlock(block)
+	if result == nil {
+		// not executed at all
+		return nil, verifyErr
+	}
+
+	receipts := types.Receipts(result.Receipts)
+
+	resp := &proto.DryRunBlockResponse{
+		Number:       block.Number(),
+		Hash:         block.Hash().String(),
+		StateRoot:    result.Root.String(),
+		ReceiptsRoot: buildroot.CalculateReceiptsRoot(result.Receipts).String(),
+		GasUsed:      result.TotalGas,
+		Receipts:     receipts.MarshalRLPTo(nil),
+		Elapsed:      result.ExecutionTime.Round(time.Microsecond).String(),
+	}
+
+	if verifyErr != nil {
+		resp.Error = verifyErr.Error()
+	}
+
+	return resp, nil
+}
+
 const (
 	defaultMaxGRPCPayloadSize uint64 = 4 * 1024 * 1024 // 4MB
 )
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// startWarmup loads the recent chain data and the top of the state trie into the
+// caches in background. It is stopped on close.
+func (s *Server) startWarmup() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	doneCh := make(chan struct{})
+
+	go func() {
+		defer close(doneCh)
+
+		s.warmup(ctx, s.config.Warmup)
+	}()
+
+	s.lifecycle.register("warmup", func() error {
+		cancel()
+		<-doneCh
+
+		return nil
+	})
+}
+
+func (s *Server) warmup(ctx context.Context, opts *WarmupOptions) {
+	logger := s.logger.Named("warmup")
+	begin := time.Now()
+
+	stats, err := s.blockchain.Warmup(ctx, opts.Headers, opts.Receipts)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			logger.Warn("failed to warm up the chain data", "err", err)
+		}
+
+		return
+	}
+
+	var nodes int
+
+	if opts.StateDepth > 0 {
+		nodes, err = s.stateDB.Warmup(ctx, s.blockchain.Header().StateRoot, opts.StateDepth)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				logger.Warn("failed to warm up the state", "err", err)
+			}
+
+			return
+		}
+	}
+
+	logger.Info("caches warmed up",
+		"headers", stats.Headers,
+		"receipts", stats.Receipts,
+		"state_nodes", nodes,
+		"elapsed", time.Since(begin),
+	)
+}
@@ -0,0 +1,101 @@
+package state
+
+import (
+	"go.uber.org/atomic"
+)
+
+// AccessStats is the state access counts of an execution
+type AccessStats struct {
+	AccountReads  uint64 `json:"accountReads"`  // accounts loaded from the state
+	StorageReads  uint64 `json:"storageReads"`  // storage slots loaded from the state
+	StorageWrites uint64 `json:"storageWrites"` // storage slots committed to the state
+	NodeLoads     uint64 `json:"nodeLoads"`     // trie nodes resolved
+	NodeCacheHits uint64 `json:"nodeCacheHits"` // trie nodes resolved from the cache
+}
+
+// NodeCacheHitRatio returns the ratio of the trie nodes resolved from the cache
+func (s AccessStats) NodeCacheHitRatio() float64 {
+	if s.NodeLoads == 0 {
+		return 0
+	}
+
+	return float64(s.NodeCacheHits) / float64(s.NodeLoads)
+}
+
+// Sub returns the accesses made since the earlier stats
+func (s AccessStats) Sub(earlier AccessStats) AccessStats {
+	return AccessStats{
+		AccountReads:  s.AccountReads - earlier.AccountReads,
+		StorageReads:  s.StorageReads - earlier.StorageReads,
+		StorageWrites: s.StorageWrites - earlier.StorageWrites,
+		NodeLoads:     s.NodeLoads - earlier.NodeLoads,
+		NodeCacheHits: s.NodeCacheHits - earlier.NodeCacheHits,
+	}
+}
+
+// AccessCounter counts the state accesses of an execution. It is safe for concurrent
+// use, and a nil counter counts nothing.
+type AccessCounter struct {
+	accountReads  atomic.Uint64
+	storageReads  atomic.Uint64
+	storageWrites atomic.Uint64
+	nodeLoads     atomic.Uint64
+	nodeCacheHits atomic.Uint64
+}
+
+// accessCounterSetter is implemented by the snapshots counting their trie node loads
+type accessCounterSetter interface {
+	SetAccessCounter(counter *AccessCounter)
+}
+
+// nodeFetchSetter is implemented by the snapshots which could re-fetch their missing
+// trie nodes from the peers
+type nodeFetchSetter interface {
+	SetNodeFetch(enabled bool)
+}
+
+func (c *AccessCounter) accountRead() {
+	if c != nil {
+		c.accountReads.Inc()
+	}
+}
+
+func (c *AccessCounter) storageRead() {
+	if c != nil {
+		c.storageReads.Inc()
+	}
+}
+
+func (c *AccessCounter) storageWrite(n int) {
+	if c != nil {
+		c.storageWrites.Add(uint64(n))
+	}
+}
+
+// NodeLoad counts a resolved trie node
+func (c *AccessCounter) NodeLoad(cached bool) {
+	if c == nil {
+		return
+	}
+
+	c.nodeLoads.Inc()
+
+	if cached {
+		c.nodeCacheHits.Inc()
+	}
+}
+
+// Stats returns the current counts
+func (c *AccessCounter) Stats() AccessStats {
+	if c == nil {
+		return AccessStats{}
+	}
+
+	return AccessStats{
+		AccountReads:  c.accountReads.Load(),
+		StorageReads:  c.storageReads.Load(),
+		StorageWrites: c.storageWrites.Load(),
+		NodeLoads:     c.nodeLoads.Load(),
+		NodeCacheHits: c.nodeCacheHits.Load(),
+	}
+}
@@ -0,0 +1,45 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessCounter(t *testing.T) {
+	var nilCounter *AccessCounter
+
+	// a nil counter counts nothing
+	nilCounter.accountRead()
+	nilCounter.NodeLoad(true)
+	assert.Equal(t, AccessStats{}, nilCounter.Stats())
+
+	counter := new(AccessCounter)
+
+	counter.accountRead()
+	counter.storageRead()
+	counter.storageRead()
+	counter.storageWrite(3)
+	counter.NodeLoad(true)
+	counter.NodeLoad(false)
+	counter.NodeLoad(true)
+	counter.NodeLoad(true)
+
+	before := AccessStats{AccountReads: 1, NodeLoads: 2, NodeCacheHits: 1}
+	stats := counter.Stats()
+
+	assert.Equal(t, AccessStats{
+		AccountReads:  1,
+		StorageReads:  2,
+		StorageWrites: 3,
+		NodeLoads:     4,
+		NodeCacheHits: 3,
+	}, stats)
+	assert.InDelta(t, 0.75, stats.NodeCacheHitRatio(), 1e-9)
+	assert.Equal(t, AccessStats{
+		StorageReads:  2,
+		StorageWrites: 3,
+		NodeLoads:     2,
+		NodeCacheHits: 2,
+	}, stats.Sub(before))
+}
@@ -195,7 +195,14 @@ func (e *Executor) BeginTxn(
 		return nil, err
 	}
 
+	// count the state accesses of the block
+	counter := new(AccessCounter)
+	if setter, ok := auxSnap2.(accessCounterSetter); ok {
+		setter.SetAccessCounter(counter)
+	}
+
 	newTxn := NewTxn(auxSnap2)
+	newTxn.counter = counter
 
 	env2 := runtime.TxContext{
 		Coinbase:   coinbaseReceiver,
@@ -214,6 +221,7 @@ func (e *Executor) BeginTxn(
 		getHash:  e.GetHash(header),
 		auxState: e.state,
 		snapshot: auxSnap2,
+		counter:  counter,
 		config:   config,
 		gasPool:  uint64(env2.GasLimit),
 
@@ -226,12 +234,22 @@ func (e *Executor) BeginTxn(
 	return txn, nil
 }
 
+// FetchMissingNodes re-fetches the missing trie nodes of the parent state from the
+// peers, instead of failing the execution. It is only enabled by the block imports,
+// the other readers of the state fail at once.
+func (t *Transition) FetchMissingNodes() {
+	if setter, ok := t.snapshot.(nodeFetchSetter); ok {
+		setter.SetNodeFetch(true)
+	}
+}
+
 type Transition struct {
 	logger hclog.Logger
 
 	// dummy
 	auxState State
 	snapshot Snapshot
+	counter  *AccessCounter
 
 	r       *Executor
 	config  chain.ForksInTime
@@ -251,6 +269,11 @@ type Transition struct {
 	needDebug bool
 }
 
+// AccessStats returns the state accesses of the transition so far
+func (t *Transition) AccessStats() AccessStats {
+	return t.counter.Stats()
+}
+
 // SetEVMLogger sets a non nil tracer to it
 func (t *Transition) SetEVMLogger(logger runtime.EVMLogger) {
 	t.evmLogger = logger
@@ -439,6 +462,10 @@ func (t *Transition) handleBridgeLogs(msg *types.Transaction, logs []*types.Log)
 func (t *Transition) Commit() (Snapshot, types.Hash, error) {
 	objs := t.txn.Commit(t.config.EIP155)
 
+	for _, obj := range objs {
+		t.counter.storageWrite(len(obj.Storage))
+	}
+
 	s2, root, err := t.snapshot.Commit(objs)
 	if err != nil {
 		return nil, types.Hash{}, err
@@ -447,6 +474,16 @@ func (t *Transition) Commit() (Snapshot, types.Hash, error) {
 	return s2, types.BytesToHash(root), nil
 }
 
+// Root returns the state root of the transition, which is not committed
+func (t *Transition) Root() (types.Hash, error) {
+	root, err := t.snapshot.Root(t.txn.Commit(t.config.EIP155))
+	if err != nil {
+		return types.Hash{}, err
+	}
+
+	return types.BytesToHash(root), nil
+}
+
 func (t *Transition) subGasPool(amount uint64) error {
 	if t.gasPool < amount {
 		return ErrBlockLimitReached
@@ -676,6 +713,7 @@ func (t *Transition) apply(msg *types.Transaction) (*runtime.ExecutionResult, er
 
 	refund := txn.GetRefund()
 	result.UpdateGasUsed(msg.Gas, refund)
+	result.IntrinsicGas = intrinsicGasCost
 
 	// refund the sender
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(result.GasLeft), gasPrice)
@@ -761,23 +799,13 @@ func (t *Transition) applyCall(
 	var result *runtime.ExecutionResult
 
 	if t.needDebug {
-		if c.Depth == 0 {
-			t.evmLogger.CaptureStart(t.Txn(), c.Caller, c.Address, false, c.Input, c.Gas, c.Value)
-
-			defer func(result *runtime.ExecutionResult) {
-				if result != nil {
-					t.evmLogger.CaptureEnd(result.ReturnValue, result.GasUsed, time.Since(time.Now()), result.Err)
-				}
-			}(result)
-		} else {
-			t.evmLogger.CaptureEnter(int(evm.RuntimeType2OpCode(callType)), c.Caller, c.Address, c.Input, c.Gas, c.Value)
-
-			defer func(result *runtime.ExecutionResult) {
-				if result != nil {
-					t.evmLogger.CaptureExit(result.ReturnValue, result.GasUsed, result.Err)
-				}
-			}(result)
-		}
+		begin := time.Now()
+
+		t.captureEnter(c, callType, c.Input)
+
+		defer func() {
+			t.captureExit(c, result, begin)
+		}()
 	}
 
 	//nolint:ifshort
@@ -804,6 +832,39 @@ func (t *Transition) applyCall(
 	return result
 }
 
+// captureEnter notifies the EVM logger of the call entered, the top level call
+// starts the trace
+func (t *Transition) captureEnter(c *runtime.Contract, callType runtime.CallType, input []byte) {
+	if c.Depth == 1 {
+		t.evmLogger.CaptureStart(t.Txn(), c.Caller, c.Address, callType == runtime.Create, input, c.Gas, c.Value)
+
+		return
+	}
+
+	t.evmLogger.CaptureEnter(int(evm.RuntimeType2OpCode(callType)), c.Caller, c.Address, input, c.Gas, c.Value)
+}
+
+// captureExit notifies the EVM logger of the call exited, the top level call ends
+// the trace
+func (t *Transition) captureExit(c *runtime.Contract, result *runtime.ExecutionResult, begin time.Time) {
+	if result == nil {
+		return
+	}
+
+	var gasUsed uint64
+	if c.Gas > result.GasLeft {
+		gasUsed = c.Gas - result.GasLeft
+	}
+
+	if c.Depth == 1 {
+		t.evmLogger.CaptureEnd(result.ReturnValue, gasUsed, time.Since(begin), result.Err)
+
+		return
+	}
+
+	t.evmLogger.CaptureExit(result.ReturnValue, gasUsed, result.Err)
+}
+
 var emptyHash types.Hash
 
 func (t *Transition) hasCodeOrNonce(addr types.Address) bool {
@@ -854,23 +915,13 @@ func (t *Transition) applyCreate(c *runtime.Contract, host runtime.Host) *runtim
 	var result *runtime.ExecutionResult
 
 	if t.needDebug {
-		if c.Depth == 0 {
-			t.evmLogger.CaptureStart(t.Txn(), c.Caller, c.Address, true, c.Input, c.Gas, c.Value)
-
-			defer func(result *runtime.ExecutionResult) {
-				if result != nil {
-					t.evmLogger.CaptureEnd(result.ReturnValue, result.GasUsed, time.Since(time.Now()), result.Err)
-				}
-			}(result)
-		} else {
-			t.evmLogger.CaptureEnter(int(evm.RuntimeType2OpCode(c.Type)), c.Caller, c.Address, c.Input, c.Gas, c.Value)
-
-			defer func(result *runtime.ExecutionResult) {
-				if result != nil {
-					t.evmLogger.CaptureExit(result.ReturnValue, result.GasUsed, result.Err)
-				}
-			}(result)
-		}
+		begin := time.Now()
+
+		t.captureEnter(c, c.Type, c.Code)
+
+		defer func() {
+			t.captureExit(c, result, begin)
+		}()
 	}
 
 	// Transfer the value
@@ -895,10 +946,12 @@ func (t *Transition) applyCreate(c *runtime.Contract, host runtime.Host) *runtim
 		// Contract size exceeds 'SpuriousDragon' size limit
 		t.txn.RevertToSnapshot(snapshot)
 
-		return &runtime.ExecutionResult{
+		result = &runtime.ExecutionResult{
 			GasLeft: 0,
 			Err:     runtime.ErrMaxCodeSizeExceeded,
 		}
+
+		return result
 	}
 
 	gasCost := uint64(len(result.ReturnValue)) * 200
@@ -0,0 +1,128 @@
+package itrie
+
+import (
+	"bytes"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// NodeFetcher fetches the trie nodes by hash from the remote peers
+type NodeFetcher interface {
+	// FetchTrieNodes returns the encoded nodes of the hashes, in the same order.
+	// The missing ones are empty.
+	FetchTrieNodes(hashes []types.Hash) ([][]byte, error)
+}
+
+// nodeHealer is implemented by the storage readers which could recover the missing nodes
+type nodeHealer interface {
+	// healNode recovers the missing node referenced by the hash, it is persisted by
+	// the next transaction commit
+	healNode(hash []byte) ([]byte, bool)
+}
+
+// healedNodeStore is implemented by the state db keeping the recovered nodes until a
+// transaction commits them
+type healedNodeStore interface {
+	// healedNodes returns the recovered nodes not persisted yet
+	healedNodes() map[types.Hash][]byte
+
+	// persistHealed drops the recovered nodes once persisted
+	persistHealed(nodes map[types.Hash][]byte)
+}
+
+// SetNodeFetcher sets the fetcher of the missing trie nodes. A node referenced by
+// its parent but missing from the disk is corrupted. The snapshots of the block
+// imports re-fetch it from the peers and verify it by its hash, the other readers
+// fail with ErrMissingTrieNode at once.
+func (db *stateDBImpl) SetNodeFetcher(fetcher NodeFetcher) {
+	db.fetcherLock.Lock()
+	defer db.fetcherLock.Unlock()
+
+	db.fetcher = fetcher
+}
+
+func (db *stateDBImpl) healNode(hash []byte) ([]byte, bool) {
+	if len(hash) != types.HashLength {
+		return nil, false
+	}
+
+	db.healedLock.Lock()
+	data, ok := db.healed[types.BytesToHash(hash)]
+	db.healedLock.Unlock()
+
+	if ok {
+		return data, true
+	}
+
+	db.metrics.missingNodeInc()
+
+	db.fetcherLock.RLock()
+	fetcher := db.fetcher
+	db.fetcherLock.RUnlock()
+
+	if fetcher == nil {
+		db.logger.Error("trie node missing", "hash", types.BytesToHash(hash))
+
+		return nil, false
+	}
+
+	nodes, err := fetcher.FetchTrieNodes([]types.Hash{types.BytesToHash(hash)})
+	if err != nil || len(nodes) != 1 || len(nodes[0]) == 0 {
+		db.logger.Error("failed to re-fetch missing trie node", "hash", types.BytesToHash(hash), "err", err)
+
+		return nil, false
+	}
+
+	data = nodes[0]
+
+	// the peers are not trusted, the node is addressed by its hash
+	if !bytes.Equal(crypto.Keccak256(data), hash) {
+		db.logger.Error("re-fetched trie node mismatches the hash", "hash", types.BytesToHash(hash))
+
+		return nil, false
+	}
+
+	// the node is written with the state of the block in one batch, so a failed
+	// commit leaves nothing behind
+	db.healedLock.Lock()
+	db.healed[types.BytesToHash(hash)] = data
+	db.healedLock.Unlock()
+
+	db.metrics.healedNodeInc()
+
+	db.logger.Warn("healed missing trie node", "hash", types.BytesToHash(hash))
+
+	return data, true
+}
+
+func (tx *stateDBTxn) healNode(hash []byte) ([]byte, bool) {
+	healer, ok := tx.stateDB.(nodeHealer)
+	if !ok {
+		return nil, false
+	}
+
+	return healer.healNode(hash)
+}
+
+func (db *stateDBImpl) healedNodes() map[types.Hash][]byte {
+	db.healedLock.Lock()
+	defer db.healedLock.Unlock()
+
+	nodes := make(map[types.Hash][]byte, len(db.healed))
+	for hash, data := range db.healed {
+		nodes[hash] = data
+	}
+
+	return nodes
+}
+
+func (db *stateDBImpl) persistHealed(nodes map[types.Hash][]byte) {
+	db.healedLock.Lock()
+	defer db.healedLock.Unlock()
+
+	for hash, data := range nodes {
+		delete(db.healed, hash)
+		db.cached.Set(hash.Bytes(), data)
+	}
+}
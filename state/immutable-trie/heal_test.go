@@ -0,0 +1,261 @@
+package itrie
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/state"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockNodeFetcher func(hashes []types.Hash) ([][]byte, error)
+
+func (f mockNodeFetcher) FetchTrieNodes(hashes []types.Hash) ([][]byte, error) {
+	return f(hashes)
+}
+
+// corruptedState is a state missing a node under the root
+type corruptedState struct {
+	storage Storage
+	root    types.Hash
+	objs    []*state.Object
+	addr    types.Address // address of the account under the missing node
+	proof   [][]byte      // proof of the account before corrupted
+	node    []byte        // the missing node
+	hash    []byte        // hash of the missing node
+}
+
+func newCorruptedState(t *testing.T) *corruptedState {
+	t.Helper()
+
+	storage := NewMemoryStorage()
+	st := NewStateDB(storage, hclog.NewNullLogger(), nil)
+
+	objs := []*state.Object{}
+
+	for i := 1; i <= 64; i++ {
+		objs = append(objs, &state.Object{
+			Address: types.BytesToAddress(big.NewInt(int64(i)).Bytes()),
+			Balance: big.NewInt(int64(i)),
+			Root:    types.EmptyRootHash,
+		})
+	}
+
+	_, rootBytes, err := st.NewSnapshot().Commit(objs)
+	assert.NoError(t, err)
+
+	root := types.BytesToHash(rootBytes)
+	addr := objs[10].Address
+
+	proof, err := st.ProveAccount(root, addr)
+	assert.NoError(t, err)
+	assert.Greater(t, len(proof), 1)
+
+	// corrupt the disk by dropping a node under the root
+	node := proof[1]
+	hash := crypto.Keccak256(node)
+
+	mem, ok := storage.(*memStorage)
+	assert.True(t, ok)
+	delete(mem.db, hex.EncodeToHex(hash))
+
+	return &corruptedState{
+		storage: storage,
+		root:    root,
+		objs:    objs,
+		addr:    addr,
+		proof:   proof,
+		node:    node,
+		hash:    hash,
+	}
+}
+
+func TestStateDB_MissingNodeReads(t *testing.T) {
+	cs := newCorruptedState(t)
+	st := NewStateDB(cs.storage, hclog.NewNullLogger(), nil)
+
+	snap, err := st.NewSnapshotAt(cs.root)
+	assert.NoError(t, err)
+
+	// the missing node is never taken for a missing key
+	_, err = snap.GetAccount(cs.addr)
+	assert.ErrorIs(t, err, ErrMissingTrieNode)
+
+	trie := snap.(*Snapshot).trie
+
+	_, err = trie.Get(hashit(cs.addr.Bytes()), st)
+	assert.ErrorIs(t, err, ErrMissingTrieNode)
+
+	err = snap.IterateAccounts(types.ZeroHash, func(types.Hash, *state.Account) bool {
+		return true
+	})
+	assert.ErrorIs(t, err, ErrMissingTrieNode)
+
+	_, err = st.ProveAccount(cs.root, cs.addr)
+	assert.ErrorIs(t, err, ErrMissingTrieNode)
+
+	// the accounts of the other subtrees are still read
+	read := 0
+
+	for _, obj := range cs.objs {
+		account, err := snap.GetAccount(obj.Address)
+		if errors.Is(err, ErrMissingTrieNode) {
+			continue
+		}
+
+		assert.NoError(t, err)
+		assert.Equal(t, obj.Balance, account.Balance)
+
+		read++
+	}
+
+	assert.Greater(t, read, 0)
+	assert.Less(t, read, len(cs.objs))
+}
+
+func TestStateDB_HealMissingNode(t *testing.T) {
+	var (
+		cs                = newCorruptedState(t)
+		storage           = cs.storage
+		root, addr, objs  = cs.root, cs.addr, cs.objs
+		proof, node, hash = cs.proof, cs.node, cs.hash
+		err               error
+	)
+
+	getAccount := func(st StateDB) (*state.Account, error) {
+		snap, err := st.NewSnapshotAt(root)
+		assert.NoError(t, err)
+
+		// only the block imports re-fetch the missing nodes
+		snap.(*Snapshot).SetNodeFetch(true)
+
+		return snap.GetAccount(addr)
+	}
+
+	// a fresh state db without the cached nodes
+	newStateDB := func(fetcher NodeFetcher) StateDB {
+		st := NewStateDB(storage, hclog.NewNullLogger(), nil)
+		if fetcher != nil {
+			st.SetNodeFetcher(fetcher)
+		}
+
+		return st
+	}
+
+	// the corrupted node is not recovered without peers
+	_, err = getAccount(newStateDB(nil))
+	assert.ErrorIs(t, err, ErrMissingTrieNode)
+
+	// the forged node is rejected
+	_, err = getAccount(newStateDB(mockNodeFetcher(func(hashes []types.Hash) ([][]byte, error) {
+		return [][]byte{proof[0]}, nil
+	})))
+	assert.ErrorIs(t, err, ErrMissingTrieNode)
+
+	_, err = getAccount(newStateDB(mockNodeFetcher(func(hashes []types.Hash) ([][]byte, error) {
+		return nil, errors.New("no peers")
+	})))
+	assert.ErrorIs(t, err, ErrMissingTrieNode)
+
+	// the other readers fail at once
+	readOnly := newStateDB(mockNodeFetcher(func(hashes []types.Hash) ([][]byte, error) {
+		t.Fatal("missing node fetched for a state query")
+
+		return nil, nil
+	}))
+
+	snap, err := readOnly.NewSnapshotAt(root)
+	assert.NoError(t, err)
+
+	_, err = snap.GetAccount(addr)
+	assert.ErrorIs(t, err, ErrMissingTrieNode)
+
+	// the node is re-fetched once
+	fetched := 0
+	healed := newStateDB(mockNodeFetcher(func(hashes []types.Hash) ([][]byte, error) {
+		assert.Equal(t, []types.Hash{types.BytesToHash(hash)}, hashes)
+
+		fetched++
+
+		return [][]byte{node}, nil
+	}))
+
+	for i := 0; i < 2; i++ {
+		account, err := getAccount(healed)
+		assert.NoError(t, err)
+		assert.NotNil(t, account)
+		assert.Equal(t, objs[10].Balance, account.Balance)
+	}
+
+	assert.Equal(t, 1, fetched)
+
+	// it is persisted along the next commit only
+	isStored := func() bool {
+		_, ok, err := storage.Get(hash)
+		assert.NoError(t, err)
+
+		return ok
+	}
+
+	assert.False(t, isStored())
+
+	_, _, err = healed.NewSnapshot().Commit([]*state.Object{})
+	assert.NoError(t, err)
+	assert.True(t, isStored())
+
+	account, err := getAccount(newStateDB(nil))
+	assert.NoError(t, err)
+	assert.NotNil(t, account)
+}
+
+var errDiskFull = errors.New("disk full")
+
+// failingStorage fails committing the batches, which write nothing
+type failingStorage struct {
+	Storage
+}
+
+type failingBatch struct{}
+
+func (b *failingBatch) Set(k, v []byte) error {
+	return nil
+}
+
+func (b *failingBatch) Commit() error {
+	return errDiskFull
+}
+
+func (s *failingStorage) NewBatch() Batch {
+	return &failingBatch{}
+}
+
+func TestStateDB_HealFailedCommit(t *testing.T) {
+	cs := newCorruptedState(t)
+
+	st := NewStateDB(&failingStorage{Storage: cs.storage}, hclog.NewNullLogger(), nil)
+	st.SetNodeFetcher(mockNodeFetcher(func(hashes []types.Hash) ([][]byte, error) {
+		return [][]byte{cs.node}, nil
+	}))
+
+	snap, err := st.NewSnapshotAt(cs.root)
+	assert.NoError(t, err)
+
+	snap.(*Snapshot).SetNodeFetch(true)
+
+	// the account under the missing node is updated
+	obj := cs.objs[10]
+	obj.Balance = big.NewInt(1000)
+
+	_, _, err = snap.Commit([]*state.Object{obj})
+	assert.ErrorIs(t, err, errDiskFull)
+
+	// the healed node is not left behind by the failed commit
+	_, ok, err := cs.storage.Get(cs.hash)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
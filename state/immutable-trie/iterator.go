@@ -0,0 +1,114 @@
+package itrie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// Iterate walks the key/value pairs of the trie in key order, from the start key
+// (inclusive). The subtrees before the start key are skipped without loading them.
+// The walk stops once fn returns false.
+func (t *Trie) Iterate(reader StateDBReader, start []byte, fn func(key, value []byte) bool) error {
+	var startNibbles []byte
+	if len(start) > 0 {
+		// no terminator, a prefix is not after its extensions
+		startNibbles = bytesToHexNibbles(start)
+		startNibbles = startNibbles[:len(startNibbles)-1]
+	}
+
+	_, err := iterateNode(reader, t.root, nil, startNibbles, fn)
+
+	return err
+}
+
+// iterateNode walks the pairs of the node at the path in nibbles, it returns false
+// once the walk is stopped
+func iterateNode(
+	storage StorageReader,
+	node Node,
+	path []byte,
+	start []byte,
+	fn func(key, value []byte) bool,
+) (bool, error) {
+	// the whole subtree is before the start key
+	n := len(path)
+	if len(start) < n {
+		n = len(start)
+	}
+
+	if bytes.Compare(path[:n], start[:n]) < 0 {
+		return true, nil
+	}
+
+	switch node := node.(type) {
+	case nil:
+		return true, nil
+
+	case *ValueNode:
+		if node.hash {
+			nc, ok, err := GetNode(node.buf, storage)
+			if err != nil {
+				return false, err
+			}
+
+			if !ok {
+				return false, fmt.Errorf("%w %s", ErrMissingTrieNode, types.BytesToHash(node.buf))
+			}
+
+			return iterateNode(storage, nc, path, start, fn)
+		}
+
+		// the leaf is before the start key when the start key extends it
+		if len(path) < len(start) && bytes.Equal(path, start[:len(path)]) {
+			return true, nil
+		}
+
+		return fn(hexNibblesToBytes(path), node.buf), nil
+
+	case *ShortNode:
+		key := node.key
+		if hasTerminator(key) {
+			key = key[:len(key)-1]
+		}
+
+		return iterateNode(storage, node.child, concatNibbles(path, key), start, fn)
+
+	case *FullNode:
+		// the value ends at the node, so it goes before the children
+		if cont, err := iterateNode(storage, node.value, path, start, fn); !cont || err != nil {
+			return cont, err
+		}
+
+		for i, child := range node.children {
+			childPath := concatNibbles(path, []byte{byte(i)})
+
+			if cont, err := iterateNode(storage, child, childPath, start, fn); !cont || err != nil {
+				return cont, err
+			}
+		}
+
+		return true, nil
+
+	default:
+		panic(fmt.Sprintf("unknown node type %v", node))
+	}
+}
+
+// concatNibbles returns a new path of the nibbles, the path is shared by the siblings
+func concatNibbles(path, nibbles []byte) []byte {
+	res := make([]byte, 0, len(path)+len(nibbles))
+
+	return append(append(res, path...), nibbles...)
+}
+
+// hexNibblesToBytes packs the nibbles without terminator into bytes
+func hexNibblesToBytes(nibbles []byte) []byte {
+	res := make([]byte, len(nibbles)/2)
+	for i := range res {
+		res[i] = nibbles[2*i]<<4 | nibbles[2*i+1]
+	}
+
+	return res
+}
@@ -0,0 +1,107 @@
+package itrie
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/state"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrie_Iterate(t *testing.T) {
+	st := NewStateDB(NewMemoryStorage(), hclog.NewNullLogger(), nil)
+
+	// keys of different lengths, so that the values are stored in the full nodes too
+	keys := [][]byte{
+		{0x01},
+		{0x01, 0x02},
+		{0x01, 0x02, 0x03},
+		{0x01, 0x03},
+		{0x10},
+		{0x11, 0x00},
+		{0xff, 0xff},
+	}
+
+	txn := st.NewSnapshot().(*Snapshot).trie.Txn(st) //nolint:forcetypeassert
+	for _, key := range keys {
+		assert.NoError(t, txn.Insert(key, append([]byte{0xaa}, key...)))
+	}
+
+	trie := txn.Commit()
+
+	iterate := func(start []byte, limit int) [][]byte {
+		t.Helper()
+
+		found := [][]byte{}
+
+		err := trie.Iterate(st, start, func(key, value []byte) bool {
+			assert.Equal(t, append([]byte{0xaa}, key...), value)
+
+			found = append(found, key)
+
+			return len(found) < limit
+		})
+		assert.NoError(t, err)
+
+		return found
+	}
+
+	assert.Equal(t, keys, iterate(nil, len(keys)+1))
+	assert.Equal(t, keys[:2], iterate(nil, 2))
+	assert.Equal(t, keys[1:], iterate([]byte{0x01, 0x02}, len(keys)))
+	assert.Equal(t, keys[3:], iterate([]byte{0x01, 0x02, 0x04}, len(keys)))
+	assert.Equal(t, keys[4:], iterate([]byte{0x02}, len(keys)))
+	assert.Empty(t, iterate([]byte{0xff, 0xff, 0x00}, len(keys)))
+}
+
+func TestSnapshot_IterateAccounts(t *testing.T) {
+	storage := NewMemoryStorage()
+	st := NewStateDB(storage, hclog.NewNullLogger(), nil)
+
+	objs := []*state.Object{}
+
+	for i := 1; i <= 64; i++ {
+		objs = append(objs, &state.Object{
+			Address: types.BytesToAddress(big.NewInt(int64(i)).Bytes()),
+			Balance: big.NewInt(int64(i)),
+			Root:    types.EmptyRootHash,
+		})
+	}
+
+	_, root, err := st.NewSnapshot().Commit(objs)
+	assert.NoError(t, err)
+
+	hashes := make([]types.Hash, len(objs))
+	balances := make(map[types.Hash]*big.Int, len(objs))
+
+	for i, obj := range objs {
+		hashes[i] = types.BytesToHash(hashit(obj.Address.Bytes()))
+		balances[hashes[i]] = obj.Balance
+	}
+
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i].Bytes(), hashes[j].Bytes()) < 0
+	})
+
+	// a fresh state db, the nodes are loaded from the storage
+	st = NewStateDB(storage, hclog.NewNullLogger(), nil)
+
+	snapshot, err := st.NewSnapshotAt(types.BytesToHash(root))
+	assert.NoError(t, err)
+
+	found := []types.Hash{}
+
+	err = snapshot.IterateAccounts(hashes[10], func(hash types.Hash, account *state.Account) bool {
+		assert.Equal(t, balances[hash], account.Balance)
+
+		found = append(found, hash)
+
+		return len(found) < 20
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[10:30], found)
+}
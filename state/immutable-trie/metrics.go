@@ -51,6 +51,12 @@ type Metrics interface {
 
 	// Time consumed for each status transaction commit
 	stateCommitSecondsObserve() MetricsTimeendRecord
+
+	// Referenced trie node missing from disk
+	missingNodeInc()
+
+	// Missing trie node re-fetched from peers
+	healedNodeInc()
 }
 
 // Metrics represents the itrie metrics
@@ -76,6 +82,9 @@ type stateDBMetrics struct {
 	rootHashSeconds    prometheus.Histogram
 
 	stateCommitSeconds prometheus.Histogram
+
+	missingNode prometheus.Counter
+	healedNode  prometheus.Counter
 }
 
 func (m *stateDBMetrics) codeCacheHitInc() {
@@ -174,6 +183,14 @@ func (m *stateDBMetrics) stateCommitSecondsObserve() MetricsTimeendRecord {
 	}
 }
 
+func (m *stateDBMetrics) missingNodeInc() {
+	metrics.CounterInc(m.missingNode)
+}
+
+func (m *stateDBMetrics) healedNodeInc() {
+	metrics.CounterInc(m.healedNode)
+}
+
 // GetPrometheusMetrics return the blockchain metrics instance
 func GetPrometheusMetrics(namespace string, trackingIOTimer bool, labelsWithValues ...string) Metrics {
 	constLabels := metrics.ParseLables(labelsWithValues...)
@@ -277,6 +294,20 @@ func GetPrometheusMetrics(namespace string, trackingIOTimer bool, labelsWithValu
 			Help:        "state commit seconds",
 			ConstLabels: constLabels,
 		}),
+		missingNode: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "itrie",
+			Name:        "state_missing_node_count",
+			Help:        "referenced trie nodes missing from disk",
+			ConstLabels: constLabels,
+		}),
+		healedNode: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "itrie",
+			Name:        "state_healed_node_count",
+			Help:        "missing trie nodes re-fetched from peers",
+			ConstLabels: constLabels,
+		}),
 	}
 
 	prometheus.MustRegister(
@@ -294,6 +325,8 @@ func GetPrometheusMetrics(namespace string, trackingIOTimer bool, labelsWithValu
 		m.accountHashSeconds,
 		m.rootHashSeconds,
 		m.stateCommitSeconds,
+		m.missingNode,
+		m.healedNode,
 	)
 
 	return m
@@ -3,6 +3,8 @@ package itrie
 import (
 	"bytes"
 	"fmt"
+
+	"github.com/dogechain-lab/dogechain/types"
 )
 
 var nodePool = NewNodePool()
@@ -86,6 +88,9 @@ func (f *FullNode) getEdge(idx byte) Node {
 	}
 }
 
+// lookupNode resolves the value of the key under the node. A missing key is not an
+// error, but a node referenced by its parent and missing from the storage is, as the
+// key might be under it.
 func lookupNode(storage StorageReader, node interface{}, key []byte) (Node, []byte, error) {
 	switch n := node.(type) {
 	case nil:
@@ -99,7 +104,7 @@ func lookupNode(storage StorageReader, node interface{}, key []byte) (Node, []by
 			}
 
 			if !ok {
-				return nil, nil, nil
+				return nil, nil, fmt.Errorf("%w %s", ErrMissingTrieNode, types.BytesToHash(n.buf))
 			}
 
 			_, res, err := lookupNode(storage, nc, key)
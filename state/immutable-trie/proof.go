@@ -0,0 +1,111 @@
+package itrie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/dogechain-lab/fastrlp"
+)
+
+// ProveAccount returns the merkle proof of the account within the state root
+func (db *stateDBImpl) ProveAccount(root types.Hash, addr types.Address) ([][]byte, error) {
+	if err := db.checkSyncing(root); err != nil {
+		return nil, err
+	}
+
+	return prove(db, root, hashit(addr.Bytes()))
+}
+
+// ProveStorage returns the merkle proof of the slot within the storage root
+func (db *stateDBImpl) ProveStorage(root types.Hash, slot types.Hash) ([][]byte, error) {
+	return prove(db, root, hashit(slot.Bytes()))
+}
+
+// prove returns the RLP encoded nodes along the key path, root node first.
+//
+// Nodes embedded in their parents are not listed separately. The proof ends
+// where the path ends, so it proves absence of the key as well.
+func prove(storage StorageReader, root types.Hash, key []byte) ([][]byte, error) {
+	if root == types.EmptyRootHash {
+		return [][]byte{}, nil
+	}
+
+	var (
+		proof = [][]byte{}
+		hash  = root.Bytes()
+		path  = bytesToHexNibbles(key)
+	)
+
+	for hash != nil {
+		data, ok, err := storage.Get(hash)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, fmt.Errorf("%w %s", ErrMissingTrieNode, types.BytesToHash(hash))
+		}
+
+		proof = append(proof, data)
+
+		p := parserPool.Get()
+
+		v, err := p.Parse(data)
+		if err != nil {
+			parserPool.Put(p)
+
+			return nil, err
+		}
+
+		hash, path, err = proveNode(v, path)
+
+		parserPool.Put(p)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return proof, nil
+}
+
+// proveNode walks the RLP encoded node along the path, returns the hash
+// of the next stored node and the remaining path. Nil hash means the
+// path ends within this node.
+func proveNode(v *fastrlp.Value, path []byte) ([]byte, []byte, error) {
+	for {
+		switch v.Type() {
+		case fastrlp.TypeBytes:
+			// empty or hash reference
+			if len(v.Raw()) != 32 {
+				return nil, nil, nil
+			}
+
+			return append([]byte{}, v.Raw()...), path, nil
+
+		case fastrlp.TypeArray:
+			switch v.Elems() {
+			case 2:
+				key := decodeCompact(v.Get(0).Raw())
+				if hasTerminator(key) ||
+					len(key) > len(path) ||
+					!bytes.Equal(key, path[:len(key)]) {
+					// leaf or diverged
+					return nil, nil, nil
+				}
+
+				v, path = v.Get(1), path[len(key):]
+			case 17:
+				if len(path) == 0 || path[0] == 16 {
+					return nil, nil, nil
+				}
+
+				v, path = v.Get(int(path[0])), path[1:]
+			default:
+				return nil, nil, fmt.Errorf("node has incorrect number of leafs")
+			}
+
+		default:
+			return nil, nil, fmt.Errorf("unexpected node type %v", v.Type())
+		}
+	}
+}
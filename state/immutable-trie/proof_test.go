@@ -0,0 +1,102 @@
+package itrie
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/state"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/dogechain-lab/fastrlp"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+// verifyProof checks the proof nodes are linked by hash from the root
+func verifyProof(t *testing.T, root types.Hash, key []byte, proof [][]byte) {
+	t.Helper()
+
+	var (
+		hash = root.Bytes()
+		path = bytesToHexNibbles(key)
+		p    = &fastrlp.Parser{}
+	)
+
+	for i, node := range proof {
+		assert.Equal(t, hash, crypto.Keccak256(node), "proof node %d", i)
+
+		v, err := p.Parse(node)
+		assert.NoError(t, err)
+
+		hash, path, err = proveNode(v, path)
+		assert.NoError(t, err)
+	}
+
+	// the last node should end the path
+	assert.Nil(t, hash)
+}
+
+func TestProve(t *testing.T) {
+	st := NewStateDB(NewMemoryStorage(), hclog.NewNullLogger(), nil)
+
+	var (
+		objs  = []*state.Object{}
+		slots = []types.Hash{}
+		addr  = types.StringToAddress("1")
+	)
+
+	for i := 0; i < 32; i++ {
+		slots = append(slots, types.BytesToHash([]byte{byte(i)}))
+	}
+
+	for i := 1; i <= 64; i++ {
+		obj := &state.Object{
+			Address: types.BytesToAddress(big.NewInt(int64(i)).Bytes()),
+			Balance: big.NewInt(int64(i)),
+			Root:    types.EmptyRootHash,
+		}
+
+		if obj.Address == addr {
+			for j, slot := range slots {
+				obj.Storage = append(obj.Storage, &state.StorageObject{
+					Key: slot.Bytes(),
+					Val: []byte{byte(j + 1)},
+				})
+			}
+		}
+
+		objs = append(objs, obj)
+	}
+
+	snap, rootBytes, err := st.NewSnapshot().Commit(objs)
+	assert.NoError(t, err)
+
+	root := types.BytesToHash(rootBytes)
+
+	account, err := snap.GetAccount(addr)
+	assert.NoError(t, err)
+
+	// existing account
+	proof, err := st.ProveAccount(root, addr)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, proof)
+	verifyProof(t, root, hashit(addr.Bytes()), proof)
+
+	// absent account
+	absent := types.StringToAddress("ff")
+	proof, err = st.ProveAccount(root, absent)
+	assert.NoError(t, err)
+	verifyProof(t, root, hashit(absent.Bytes()), proof)
+
+	// storage slots
+	values, err := snap.GetStorageSlots(account.Root, slots)
+	assert.NoError(t, err)
+
+	for i, slot := range slots {
+		assert.Equal(t, types.BytesToHash([]byte{byte(i + 1)}), values[i])
+
+		proof, err := st.ProveStorage(account.Root, slot)
+		assert.NoError(t, err)
+		verifyProof(t, account.Root, hashit(slot.Bytes()), proof)
+	}
+}
@@ -13,9 +13,70 @@ import (
 type Snapshot struct {
 	state StateDB
 	trie  *Trie
+
+	counter *state.AccessCounter // trie node load counter, nil if not counted
+	fetch   bool                 // re-fetch the missing trie nodes from the peers
+}
+
+// SetAccessCounter counts the trie nodes resolved by the snapshot
+func (s *Snapshot) SetAccessCounter(counter *state.AccessCounter) {
+	s.counter = counter
+}
+
+// SetNodeFetch sets whether the missing trie nodes are re-fetched from the peers,
+// which blocks the reads for a while. Otherwise the reads fail at once.
+func (s *Snapshot) SetNodeFetch(enabled bool) {
+	s.fetch = enabled
+}
+
+// reader returns the reader resolving the trie nodes
+func (s *Snapshot) reader() StateDBReader {
+	var reader StateDBReader = s.state
+	if !s.fetch {
+		reader = &localReader{StateDBReader: s.state}
+	}
+
+	if s.counter == nil {
+		return reader
+	}
+
+	return &countingReader{StateDBReader: reader, counter: s.counter}
 }
 
 func (s *Snapshot) GetStorage(addr types.Address, root types.Hash, rawkey types.Hash) (types.Hash, error) {
+	snapshot, err := s.storageSnapshot(root)
+	if err != nil {
+		return types.Hash{}, err
+	}
+
+	return snapshot.getStorage(snapshot.trie.Txn(snapshot.reader()), rawkey)
+}
+
+// GetStorageSlots returns the values of the slots within the storage root.
+//
+// All slots are read by the same trie, so the nodes are only loaded once.
+func (s *Snapshot) GetStorageSlots(root types.Hash, rawkeys []types.Hash) ([]types.Hash, error) {
+	snapshot, err := s.storageSnapshot(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		txn    = snapshot.trie.Txn(snapshot.reader())
+		values = make([]types.Hash, len(rawkeys))
+	)
+
+	for i, rawkey := range rawkeys {
+		if values[i], err = snapshot.getStorage(txn, rawkey); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// storageSnapshot returns the snapshot of the account storage trie
+func (s *Snapshot) storageSnapshot(root types.Hash) (*Snapshot, error) {
 	var (
 		err error
 		ss  state.Snapshot
@@ -26,20 +87,27 @@ func (s *Snapshot) GetStorage(addr types.Address, root types.Hash, rawkey types.
 	} else {
 		ss, err = s.state.NewSnapshotAt(root)
 		if err != nil {
-			return types.Hash{}, err
+			return nil, err
 		}
 	}
 
 	// tricky downcast, but break out recursion
 	snapshot, ok := ss.(*Snapshot)
 	if !ok {
-		return types.Hash{}, fmt.Errorf("invalid type assertion to Snapshot at %s", root)
+		return nil, fmt.Errorf("invalid type assertion to Snapshot at %s", root)
 	}
 
+	snapshot.counter = s.counter
+	snapshot.fetch = s.fetch
+
+	return snapshot, nil
+}
+
+func (s *Snapshot) getStorage(txn *Txn, rawkey types.Hash) (types.Hash, error) {
 	// slot to hash
 	key := crypto.Keccak256(rawkey.Bytes())
 
-	val, err := snapshot.trie.Get(key, s.state)
+	val, err := txn.Lookup(key)
 	if err != nil {
 		// something bad happen, should not continue
 		return types.Hash{}, err
@@ -66,7 +134,7 @@ func (s *Snapshot) GetStorage(addr types.Address, root types.Hash, rawkey types.
 func (s *Snapshot) GetAccount(addr types.Address) (*state.Account, error) {
 	key := crypto.Keccak256(addr.Bytes())
 
-	data, err := s.trie.Get(key, s.state)
+	data, err := s.trie.Get(key, s.reader())
 	if err != nil {
 		return nil, err
 	} else if data == nil {
@@ -82,11 +150,43 @@ func (s *Snapshot) GetAccount(addr types.Address) (*state.Account, error) {
 	return &account, nil
 }
 
+// IterateAccounts walks the accounts in the order of their address hashes, from the
+// start hash (inclusive). The walk stops once fn returns false.
+func (s *Snapshot) IterateAccounts(start types.Hash, fn func(hash types.Hash, account *state.Account) bool) error {
+	var decodeErr error
+
+	err := s.trie.Iterate(s.reader(), start.Bytes(), func(key, value []byte) bool {
+		var account state.Account
+		if decodeErr = account.UnmarshalRlp(value); decodeErr != nil {
+			return false
+		}
+
+		return fn(types.BytesToHash(key), &account)
+	})
+	if err != nil {
+		return err
+	}
+
+	return decodeErr
+}
+
 func (s *Snapshot) GetCode(hash types.Hash) ([]byte, bool) {
 	return s.state.GetCode(hash)
 }
 
 func (s *Snapshot) Commit(objs []*state.Object) (state.Snapshot, []byte, error) {
+	return s.commit(objs, true)
+}
+
+// Root returns the state root the objects would be committed to, the trie nodes are
+// hashed within the rolled back transaction
+func (s *Snapshot) Root(objs []*state.Object) ([]byte, error) {
+	_, root, err := s.commit(objs, false)
+
+	return root, err
+}
+
+func (s *Snapshot) commit(objs []*state.Object, persist bool) (state.Snapshot, []byte, error) {
 	var (
 		root  []byte = nil
 		nTrie *Trie  = nil
@@ -140,6 +240,10 @@ func (s *Snapshot) Commit(objs []*state.Object) (state.Snapshot, []byte, error)
 
 					for _, entry := range obj.Storage {
 						k := hashit(entry.Key)
+						if err := st.SetPreimage(types.BytesToHash(k), entry.Key); err != nil {
+							return err
+						}
+
 						if entry.Deleted {
 							err := localTxn.Delete(k)
 							if err != nil {
@@ -184,7 +288,12 @@ func (s *Snapshot) Commit(objs []*state.Object) (state.Snapshot, []byte, error)
 				vv := account.MarshalWith(arena)
 				data := vv.MarshalTo(nil)
 
-				tt.Insert(hashit(obj.Address.Bytes()), data)
+				k := hashit(obj.Address.Bytes())
+				if err := st.SetPreimage(types.BytesToHash(k), obj.Address.Bytes()); err != nil {
+					return err
+				}
+
+				tt.Insert(k, data)
 				insertCount++
 
 				arena.Reset()
@@ -209,11 +318,15 @@ func (s *Snapshot) Commit(objs []*state.Object) (state.Snapshot, []byte, error)
 		nTrie.root = tt.root
 		nTrie.epoch = tt.epoch
 
+		if !persist {
+			return nil
+		}
+
 		// Commit all the entries to db
 		return st.Commit()
 	})
 
-	if err == nil {
+	if err == nil && persist {
 		metrics.transactionInsertObserve(insertCount)
 		metrics.transactionDeleteObserve(deleteCount)
 		metrics.transactionNewAccountObserve(newSetCodeCount)
@@ -221,3 +334,67 @@ func (s *Snapshot) Commit(objs []*state.Object) (state.Snapshot, []byte, error)
 
 	return &Snapshot{trie: nTrie, state: s.state}, root, err
 }
+
+// cachedReader is implemented by the readers telling whether a value is read from the cache
+type cachedReader interface {
+	getCached(k []byte) ([]byte, bool, bool, error)
+}
+
+// localReader resolves the trie nodes from the disk only, the missing ones are not
+// re-fetched from the peers
+type localReader struct {
+	StateDBReader
+}
+
+func (r *localReader) getCached(k []byte) ([]byte, bool, bool, error) {
+	if reader, isCached := r.StateDBReader.(cachedReader); isCached {
+		return reader.getCached(k)
+	}
+
+	v, ok, err := r.StateDBReader.Get(k)
+
+	return v, ok, false, err
+}
+
+// countingReader counts the trie nodes resolved through it
+type countingReader struct {
+	StateDBReader
+
+	counter *state.AccessCounter
+}
+
+func (r *countingReader) Get(k []byte) ([]byte, bool, error) {
+	var (
+		v      []byte
+		ok     bool
+		cached bool
+		err    error
+	)
+
+	if reader, isCached := r.StateDBReader.(cachedReader); isCached {
+		v, ok, cached, err = reader.getCached(k)
+	} else {
+		v, ok, err = r.StateDBReader.Get(k)
+	}
+
+	if ok {
+		r.counter.NodeLoad(cached)
+	}
+
+	return v, ok, err
+}
+
+// healNode recovers the missing node through the underlying reader
+func (r *countingReader) healNode(hash []byte) ([]byte, bool) {
+	healer, ok := r.StateDBReader.(nodeHealer)
+	if !ok {
+		return nil, false
+	}
+
+	data, ok := healer.healNode(hash)
+	if ok {
+		r.counter.NodeLoad(false)
+	}
+
+	return data, ok
+}
@@ -0,0 +1,133 @@
+package itrie
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/state"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot_AccessCounter(t *testing.T) {
+	storage := NewMemoryStorage()
+	st := NewStateDB(storage, hclog.NewNullLogger(), nil)
+
+	objs := []*state.Object{}
+
+	for i := 1; i <= 64; i++ {
+		objs = append(objs, &state.Object{
+			Address: types.BytesToAddress(big.NewInt(int64(i)).Bytes()),
+			Balance: big.NewInt(int64(i)),
+			Root:    types.EmptyRootHash,
+		})
+	}
+
+	_, root, err := st.NewSnapshot().Commit(objs)
+	assert.NoError(t, err)
+
+	// a fresh state db without the cached nodes
+	st = NewStateDB(storage, hclog.NewNullLogger(), nil)
+
+	ss, err := st.NewSnapshotAt(types.BytesToHash(root))
+	assert.NoError(t, err)
+
+	snapshot, ok := ss.(*Snapshot)
+	assert.True(t, ok)
+
+	counter := new(state.AccessCounter)
+	snapshot.SetAccessCounter(counter)
+
+	account, err := snapshot.GetAccount(objs[10].Address)
+	assert.NoError(t, err)
+	assert.Equal(t, objs[10].Balance, account.Balance)
+
+	first := counter.Stats()
+	assert.Greater(t, first.NodeLoads, uint64(0))
+	assert.Equal(t, uint64(0), first.NodeCacheHits)
+
+	// the nodes are cached by the first read
+	ss, err = st.NewSnapshotAt(types.BytesToHash(root))
+	assert.NoError(t, err)
+
+	snapshot, ok = ss.(*Snapshot)
+	assert.True(t, ok)
+
+	snapshot.SetAccessCounter(counter)
+
+	_, err = snapshot.GetAccount(objs[10].Address)
+	assert.NoError(t, err)
+
+	second := counter.Stats().Sub(first)
+	assert.Equal(t, first.NodeLoads, second.NodeLoads)
+	assert.Equal(t, second.NodeLoads, second.NodeCacheHits)
+}
+
+func TestSnapshot_Preimages(t *testing.T) {
+	var (
+		addr = types.StringToAddress("1")
+		slot = types.StringToHash("2")
+	)
+
+	objs := []*state.Object{
+		{
+			Address: addr,
+			Balance: big.NewInt(1),
+			Root:    types.EmptyRootHash,
+			Storage: []*state.StorageObject{
+				{Key: slot.Bytes(), Val: []byte{1}},
+			},
+		},
+	}
+
+	for _, enabled := range []bool{false, true} {
+		st := NewStateDB(NewMemoryStorage(), hclog.NewNullLogger(), nil, WithPreimages(enabled))
+
+		_, _, err := st.NewSnapshot().Commit(objs)
+		assert.NoError(t, err)
+
+		preimage, ok := st.GetPreimage(types.BytesToHash(hashit(addr.Bytes())))
+		assert.Equal(t, enabled, ok)
+
+		if enabled {
+			assert.Equal(t, addr.Bytes(), preimage)
+		}
+
+		preimage, ok = st.GetPreimage(types.BytesToHash(hashit(slot.Bytes())))
+		assert.Equal(t, enabled, ok)
+
+		if enabled {
+			assert.Equal(t, slot.Bytes(), preimage)
+		}
+	}
+}
+
+func TestSnapshot_Root(t *testing.T) {
+	objs := []*state.Object{
+		{
+			Address: types.StringToAddress("1"),
+			Balance: big.NewInt(1),
+			Root:    types.EmptyRootHash,
+			Storage: []*state.StorageObject{
+				{Key: types.StringToHash("2").Bytes(), Val: []byte{1}},
+			},
+		},
+	}
+
+	st := NewStateDB(NewMemoryStorage(), hclog.NewNullLogger(), nil)
+
+	root, err := st.NewSnapshot().Root(objs)
+	assert.NoError(t, err)
+
+	// nothing is written
+	_, err = st.NewSnapshotAt(types.BytesToHash(root))
+	assert.Error(t, err)
+
+	_, committed, err := st.NewSnapshot().Commit(objs)
+	assert.NoError(t, err)
+	assert.Equal(t, committed, root)
+
+	_, err = st.NewSnapshotAt(types.BytesToHash(root))
+	assert.NoError(t, err)
+}
@@ -1,11 +1,13 @@
 package itrie
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
 
 	"github.com/VictoriaMetrics/fastcache"
+	"github.com/dogechain-lab/dogechain/crypto"
 	"github.com/dogechain-lab/dogechain/state"
 	"github.com/dogechain-lab/dogechain/types"
 	"github.com/hashicorp/go-hclog"
@@ -16,7 +18,25 @@ var (
 	// codePrefix is the code prefix for leveldb
 	codePrefix = []byte("code")
 
+	// preimagePrefix is the prefix of the keccak preimages of the trie keys, keyed by
+	// hash. It is the same as the one of go-ethereum.
+	preimagePrefix = []byte("secure-key-")
+
+	// emptyCodeHash is the code hash of the accounts without code
+	emptyCodeHash = types.BytesToHash(crypto.Keccak256(nil))
+
 	ErrStateTransactionIsCancel = errors.New("transaction is cancel")
+
+	// ErrMissingTrieNode is returned if a node referenced by its parent is missing
+	ErrMissingTrieNode = errors.New("missing trie node")
+)
+
+const (
+	// DefaultCacheSize is the default size of the cached trie nodes in bytes
+	DefaultCacheSize = 32 * 1024 * 1024
+
+	// DefaultCodeCacheSize is the default size of the cached contract codes in bytes
+	DefaultCodeCacheSize = 16 * 1024 * 1024
 )
 
 type StateDBReader interface {
@@ -24,6 +44,10 @@ type StateDBReader interface {
 
 	GetCode(hash types.Hash) ([]byte, bool)
 
+	// GetPreimage returns the address or storage slot hashed to the trie key, if
+	// it is recorded
+	GetPreimage(hash types.Hash) ([]byte, bool)
+
 	NewSnapshot() state.Snapshot
 	NewSnapshotAt(types.Hash) (state.Snapshot, error)
 }
@@ -31,8 +55,20 @@ type StateDBReader interface {
 type StateDB interface {
 	StateDBReader
 
+	ProveAccount(root types.Hash, addr types.Address) ([][]byte, error)
+	ProveStorage(root types.Hash, slot types.Hash) ([][]byte, error)
+
 	Transaction(execute func(st StateDBTransaction) error) error
 
+	// SetNodeFetcher sets the fetcher of the missing trie nodes
+	SetNodeFetcher(fetcher NodeFetcher)
+
+	// Warmup loads the top of the state trie into the cache, down to the depth
+	Warmup(ctx context.Context, root types.Hash, depth int) (int, error)
+
+	// SyncState downloads the whole state of the root from the peers
+	SyncState(ctx context.Context, root types.Hash) error
+
 	GetMetrics() Metrics
 
 	Logger() hclog.Logger
@@ -46,17 +82,74 @@ type stateDBImpl struct {
 	cached    *fastcache.Cache
 	codeCache *fastcache.Cache
 
+	preimages bool // record the preimages of the trie keys
+
 	txnMux sync.Mutex
+
+	fetcherLock sync.RWMutex
+	fetcher     NodeFetcher // fetches the missing trie nodes, nil if not set
+
+	healedLock sync.Mutex
+	healed     map[types.Hash][]byte // re-fetched trie nodes, persisted by the next commit
+
+	syncLock    sync.RWMutex
+	syncingRoot types.Hash // root of the partial state being synced, zero if none
+}
+
+// StateDBOption configures the caches of the state database
+type StateDBOption func(*stateDBOptions)
+
+type stateDBOptions struct {
+	cacheSize     int  // bytes of the cached trie nodes
+	codeCacheSize int  // bytes of the cached contract codes
+	preimages     bool // record the preimages of the trie keys
+}
+
+// WithCacheSize sets the size of the cached trie nodes in bytes. The account reads
+// of the explorers thrash a small cache.
+func WithCacheSize(size int) StateDBOption {
+	return func(o *stateDBOptions) {
+		o.cacheSize = size
+	}
+}
+
+// WithCodeCacheSize sets the size of the cached contract codes in bytes
+func WithCodeCacheSize(size int) StateDBOption {
+	return func(o *stateDBOptions) {
+		o.codeCacheSize = size
+	}
+}
+
+// WithPreimages records the addresses and storage slots hashed to the trie keys
+// by the commits, so that the state is walked by address
+func WithPreimages(enabled bool) StateDBOption {
+	return func(o *stateDBOptions) {
+		o.preimages = enabled
+	}
 }
 
-func NewStateDB(storage Storage, logger hclog.Logger, metrics Metrics) StateDB {
-	return &stateDBImpl{
+func NewStateDB(storage Storage, logger hclog.Logger, metrics Metrics, opts ...StateDBOption) StateDB {
+	options := stateDBOptions{
+		cacheSize:     DefaultCacheSize,
+		codeCacheSize: DefaultCodeCacheSize,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	db := &stateDBImpl{
 		logger:    logger.Named("state"),
 		storage:   storage,
-		cached:    fastcache.New(32 * 1024 * 1024),
-		codeCache: fastcache.New(16 * 1024 * 1024),
+		cached:    fastcache.New(options.cacheSize),
+		codeCache: fastcache.New(options.codeCacheSize),
+		preimages: options.preimages,
 		metrics:   newDummyMetrics(metrics),
+		healed:    make(map[types.Hash][]byte),
 	}
+
+	db.loadSyncingRoot()
+
+	return db
 }
 
 func (db *stateDBImpl) newTrie() *Trie {
@@ -69,7 +162,12 @@ func (db *stateDBImpl) newTrieAt(root types.Hash) (*Trie, error) {
 		return db.newTrie(), nil
 	}
 
-	n, ok, err := GetNode(root.Bytes(), db)
+	if err := db.checkSyncing(root); err != nil {
+		return nil, err
+	}
+
+	// the root is only read from the disk, the state is not fetched as a whole
+	n, ok, err := GetNode(root.Bytes(), &localReader{StateDBReader: db})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get storage root %s: %w", root, err)
 	} else if !ok {
@@ -91,10 +189,17 @@ func (db *stateDBImpl) Logger() hclog.Logger {
 }
 
 func (db *stateDBImpl) Get(k []byte) ([]byte, bool, error) {
+	v, ok, _, err := db.getCached(k)
+
+	return v, ok, err
+}
+
+// getCached returns the stored value of the key, and whether it is read from the cache
+func (db *stateDBImpl) getCached(k []byte) ([]byte, bool, bool, error) {
 	if enc := db.cached.Get(nil, k); enc != nil {
 		db.metrics.accountCacheHitInc()
 
-		return enc, true, nil
+		return enc, true, true, nil
 	}
 
 	db.metrics.accountCacheMissInc()
@@ -118,11 +223,21 @@ func (db *stateDBImpl) Get(k []byte) ([]byte, bool, error) {
 		db.cached.Set(k, v)
 	}
 
-	return v, ok, err
+	return v, ok, false, err
+}
+
+// codeKey returns the storage key of the code blob
+func codeKey(hash types.Hash) []byte {
+	return append(append(make([]byte, 0, len(codePrefix)+types.HashLength), codePrefix...), hash.Bytes()...)
+}
+
+// isEmptyCode returns whether the code hash references no code
+func isEmptyCode(hash types.Hash) bool {
+	return hash == types.ZeroHash || hash == emptyCodeHash
 }
 
 func (db *stateDBImpl) GetCode(hash types.Hash) ([]byte, bool) {
-	perfix := append(codePrefix, hash.Bytes()...)
+	perfix := codeKey(hash)
 	if enc := db.codeCache.Get(nil, perfix); enc != nil {
 		db.metrics.codeCacheHitInc()
 
@@ -146,7 +261,7 @@ func (db *stateDBImpl) GetCode(hash types.Hash) ([]byte, bool) {
 
 	// write-back cache
 	if err == nil && ok {
-		db.cached.Set(perfix, v)
+		db.codeCache.Set(perfix, v)
 	}
 
 	if !ok {
@@ -156,6 +271,24 @@ func (db *stateDBImpl) GetCode(hash types.Hash) ([]byte, bool) {
 	return v, true
 }
 
+// preimageKey returns the storage key of the preimage of the hash
+func preimageKey(hash types.Hash) []byte {
+	return append(append(make([]byte, 0, len(preimagePrefix)+types.HashLength), preimagePrefix...), hash.Bytes()...)
+}
+
+// GetPreimage returns the address or storage slot hashed to the trie key. The
+// preimages are not cached, they are only read by the state walks.
+func (db *stateDBImpl) GetPreimage(hash types.Hash) ([]byte, bool) {
+	v, ok, err := db.storage.Get(preimageKey(hash))
+	if err != nil {
+		db.logger.Error("failed to get preimage", "err", err)
+
+		return nil, false
+	}
+
+	return v, ok
+}
+
 func (db *stateDBImpl) NewSnapshot() state.Snapshot {
 	return &Snapshot{state: db, trie: db.newTrie()}
 }
@@ -196,6 +329,7 @@ func (db *stateDBImpl) Transaction(execute func(StateDBTransaction) error) error
 	// set stateDB, storage and cancel flag
 	stateDBTxnRef.stateDB = db
 	stateDBTxnRef.storage = db.storage
+	stateDBTxnRef.preimages = db.preimages
 	stateDBTxnRef.cancel.Store(false)
 
 	observer := db.metrics.stateCommitSecondsObserve()
@@ -209,6 +343,10 @@ func (db *stateDBImpl) Transaction(execute func(StateDBTransaction) error) error
 		observer()
 
 		for _, pair := range stateDBTxnRef.db {
+			if pair.isPreimage {
+				continue
+			}
+
 			if pair.isCode {
 				db.codeCache.Set(pair.key, pair.value)
 			} else {
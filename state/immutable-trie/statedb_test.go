@@ -1,10 +1,14 @@
 package itrie
 
 import (
+	"math/big"
 	"testing"
 
+	"github.com/dogechain-lab/dogechain/crypto"
 	"github.com/dogechain-lab/dogechain/state"
+	"github.com/dogechain-lab/dogechain/types"
 	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestState(t *testing.T) {
@@ -18,3 +22,71 @@ func buildPreState(pre state.PreStates) state.Snapshot {
 
 	return snap
 }
+
+func TestStateDB_SetCodeDeduplicated(t *testing.T) {
+	st := NewStateDB(NewMemoryStorage(), hclog.NewNullLogger(), nil)
+
+	code := []byte{0x60, 0x80, 0x60, 0x40}
+	hash := types.BytesToHash(crypto.Keccak256(code))
+
+	for i := 0; i < 3; i++ {
+		err := st.Transaction(func(txn StateDBTransaction) error {
+			if err := txn.SetCode(hash, code); err != nil {
+				return err
+			}
+
+			return txn.Commit()
+		})
+
+		assert.NoError(t, err)
+	}
+
+	stored, ok := st.GetCode(hash)
+	assert.True(t, ok)
+	assert.Equal(t, code, stored)
+}
+
+func TestStateDB_ReplacedCode(t *testing.T) {
+	st := NewStateDB(NewMemoryStorage(), hclog.NewNullLogger(), nil)
+
+	var (
+		code1 = []byte{0x60, 0x80, 0x60, 0x40}
+		code2 = []byte{0x60, 0x80, 0x60, 0x41}
+		hash1 = types.BytesToHash(crypto.Keccak256(code1))
+		hash2 = types.BytesToHash(crypto.Keccak256(code2))
+		addr1 = types.StringToAddress("1")
+		addr2 = types.StringToAddress("2")
+	)
+
+	deploy := func(addr types.Address, hash types.Hash, code []byte) *state.Object {
+		return &state.Object{
+			Address:   addr,
+			CodeHash:  hash,
+			Balance:   big.NewInt(0),
+			Root:      types.EmptyRootHash,
+			DirtyCode: true,
+			Code:      code,
+		}
+	}
+
+	// the same code deployed to two accounts
+	deployed, _, err := st.NewSnapshot().Commit([]*state.Object{
+		deploy(addr1, hash1, code1),
+		deploy(addr2, hash1, code1),
+	})
+	assert.NoError(t, err)
+
+	// the code replaced and the account deleted
+	_, _, err = deployed.Commit([]*state.Object{
+		deploy(addr1, hash2, code2),
+		{Address: addr2, Deleted: true},
+	})
+	assert.NoError(t, err)
+
+	// the replaced code is kept for the former states
+	for hash, code := range map[types.Hash][]byte{hash1: code1, hash2: code2} {
+		stored, ok := st.GetCode(hash)
+		assert.True(t, ok)
+		assert.Equal(t, code, stored)
+	}
+}
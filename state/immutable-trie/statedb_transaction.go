@@ -18,15 +18,19 @@ type StateDBTransaction interface {
 	GetCode(hash types.Hash) ([]byte, bool)
 	SetCode(hash types.Hash, code []byte) error
 
+	// SetPreimage records the preimage of the trie key, if the state db records them
+	SetPreimage(hash types.Hash, preimage []byte) error
+
 	Commit() error
 	Rollback()
 }
 
 type txnKey string
 type txnPair struct {
-	key    []byte
-	value  []byte
-	isCode bool
+	key        []byte
+	value      []byte
+	isCode     bool
+	isPreimage bool
 }
 
 var txnPairPool = sync.Pool{
@@ -43,14 +47,16 @@ func (pair *txnPair) Reset() {
 	pair.key = pair.key[0:0]
 	pair.value = pair.value[0:0]
 	pair.isCode = false
+	pair.isPreimage = false
 }
 
 type stateDBTxn struct {
 	db   map[txnKey]*txnPair
 	lock sync.Mutex
 
-	stateDB StateDB
-	storage Storage
+	stateDB   StateDB
+	storage   Storage
+	preimages bool // record the preimages of the trie keys
 
 	cancel *atomic.Bool
 }
@@ -87,23 +93,68 @@ func (tx *stateDBTxn) Get(k []byte) ([]byte, bool, error) {
 	return bufValue, true, nil
 }
 
+// SetCode stores the code keyed by its hash. The same code is only written
+// once.
 func (tx *stateDBTxn) SetCode(hash types.Hash, v []byte) error {
 	tx.lock.Lock()
 	defer tx.lock.Unlock()
 
-	perfix := append(codePrefix, hash.Bytes()...)
+	if _, exists := tx.getCodeLocked(hash); exists {
+		return nil
+	}
+
+	return tx.setPairLocked(codeKey(hash), v, true)
+}
+
+// SetPreimage records the preimage of the trie key. The preimage never changes,
+// so it is only written once by the transaction.
+func (tx *stateDBTxn) SetPreimage(hash types.Hash, preimage []byte) error {
+	if !tx.preimages {
+		return nil
+	}
 
+	tx.lock.Lock()
+	defer tx.lock.Unlock()
+
+	key := txnKey(hex.EncodeToString(preimageKey(hash)))
+	if _, ok := tx.db[key]; ok {
+		return nil
+	}
+
+	if err := tx.setPairLocked(preimageKey(hash), preimage, false); err != nil {
+		return err
+	}
+
+	tx.db[key].isPreimage = true
+
+	return nil
+}
+
+// GetPreimage returns the preimage of the trie key, including uncommitted ones
+func (tx *stateDBTxn) GetPreimage(hash types.Hash) ([]byte, bool) {
+	tx.lock.Lock()
+	defer tx.lock.Unlock()
+
+	v, ok := tx.db[txnKey(hex.EncodeToString(preimageKey(hash)))]
+	if !ok {
+		return tx.stateDB.GetPreimage(hash)
+	}
+
+	return append([]byte{}, v.value...), true
+}
+
+func (tx *stateDBTxn) setPairLocked(k, v []byte, isCode bool) error {
 	pair, ok := txnPairPool.Get().(*txnPair)
 	if !ok {
 		return errors.New("invalid type assertion")
 	}
 
 	// overwrite them
-	pair.key = append(pair.key[:0], perfix...)
+	pair.key = append(pair.key[:0], k...)
 	pair.value = append(pair.value[:0], v...)
-	pair.isCode = true
+	pair.isCode = isCode
 
-	tx.db[txnKey(hex.EncodeToString(perfix))] = pair
+	tx.db[txnKey(hex.EncodeToString(k))] = pair
 
 	return nil
 }
@@ -112,9 +163,11 @@ func (tx *stateDBTxn) GetCode(hash types.Hash) ([]byte, bool) {
 	tx.lock.Lock()
 	defer tx.lock.Unlock()
 
-	perfix := append(codePrefix, hash.Bytes()...)
+	return tx.getCodeLocked(hash)
+}
 
-	v, ok := tx.db[txnKey(hex.EncodeToString(perfix))]
+func (tx *stateDBTxn) getCodeLocked(hash types.Hash) ([]byte, bool) {
+	v, ok := tx.db[txnKey(hex.EncodeToString(codeKey(hash)))]
 	if !ok {
 		return tx.stateDB.GetCode(hash)
 	}
@@ -174,12 +227,34 @@ func (tx *stateDBTxn) Commit() error {
 			return err
 		}
 
-		if !pair.isCode {
+		if !pair.isCode && !pair.isPreimage {
 			metrics.transactionWriteNodeSizeObserve(len(pair.value))
 		}
 	}
 
-	return batch.Commit()
+	// the nodes re-fetched from the peers are persisted along
+	var healed map[types.Hash][]byte
+
+	store, isStore := tx.stateDB.(healedNodeStore)
+	if isStore {
+		healed = store.healedNodes()
+
+		for hash, data := range healed {
+			if err := batch.Set(hash.Bytes(), data); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+
+	if isStore {
+		store.persistHealed(healed)
+	}
+
+	return nil
 }
 
 // clear transaction data, set cancel flag
@@ -199,6 +274,7 @@ func (tx *stateDBTxn) Rollback() {
 func (tx *stateDBTxn) clear() {
 	tx.stateDB = nil
 	tx.storage = nil
+	tx.preimages = false
 
 	for tk := range tx.db {
 		pair := tx.db[tk]
@@ -0,0 +1,350 @@
+package itrie
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/state"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+var (
+	// stateSyncKey is the key of the state root being synced from the peers. The
+	// state of the root is partial until the sync completes, so it is not served.
+	stateSyncKey = []byte("state-sync-root")
+
+	// ErrStateSyncing is returned if the state of the root is still being synced
+	ErrStateSyncing = errors.New("state is being synced")
+
+	errNoNodeFetcher = errors.New("no trie node fetcher")
+)
+
+// maxSyncRequests is the max number of the trie nodes and codes fetched at once,
+// the same as the max served by a peer
+const maxSyncRequests = 128
+
+// syncKind is the kind of an item of the state
+type syncKind int
+
+const (
+	syncAccountNode syncKind = iota // node of the account trie
+	syncStorageNode                 // node of a storage trie
+	syncCode                        // contract code
+)
+
+type syncRequest struct {
+	hash types.Hash
+	kind syncKind
+	root bool // root of a storage trie
+}
+
+// stateSyncStats counts the items of the synced state
+type stateSyncStats struct {
+	nodes   int // trie nodes walked
+	codes   int // contract codes walked
+	fetched int // items fetched from the peers
+}
+
+// loadSyncingRoot loads the root of the state sync interrupted before
+func (db *stateDBImpl) loadSyncingRoot() {
+	v, ok, err := db.storage.Get(stateSyncKey)
+	if err != nil {
+		db.logger.Error("failed to get state sync root", "err", err)
+
+		return
+	}
+
+	if ok && len(v) == types.HashLength {
+		db.syncingRoot = types.BytesToHash(v)
+	}
+}
+
+// setSyncingRoot persists the root being synced, zero once the sync completes
+func (db *stateDBImpl) setSyncingRoot(root types.Hash) error {
+	db.syncLock.Lock()
+	defer db.syncLock.Unlock()
+
+	var v []byte
+	if root != types.ZeroHash {
+		v = root.Bytes()
+	}
+
+	if err := db.storage.Set(stateSyncKey, v); err != nil {
+		return err
+	}
+
+	db.syncingRoot = root
+
+	return nil
+}
+
+// checkSyncing returns ErrStateSyncing if the state of the root is partial
+func (db *stateDBImpl) checkSyncing(root types.Hash) error {
+	db.syncLock.RLock()
+	defer db.syncLock.RUnlock()
+
+	if db.syncingRoot != types.ZeroHash && db.syncingRoot == root {
+		return fmt.Errorf("%w at hash %s", ErrStateSyncing, root)
+	}
+
+	return nil
+}
+
+// SyncState downloads the whole state of the root from the peers: the nodes of the
+// account trie and of the storage tries, and the contract codes. Each item is
+// verified by the hash its parent references it by, so the state is the one of the
+// root. The state is not served until the sync completes. An interrupted sync is
+// resumed on the next call, the items stored already are not fetched again.
+func (db *stateDBImpl) SyncState(ctx context.Context, root types.Hash) error {
+	if root == types.EmptyRootHash {
+		return nil
+	}
+
+	if db.checkSyncing(root) == nil {
+		// the root is stored once its sync is started, so the state stored without a
+		// sync in progress is complete
+		if _, ok, err := db.storage.Get(root.Bytes()); err != nil {
+			return err
+		} else if ok {
+			return nil
+		}
+
+		if err := db.setSyncingRoot(root); err != nil {
+			return err
+		}
+	}
+
+	db.logger.Info("sync state", "root", root)
+
+	stats, err := db.syncState(ctx, root)
+	if err != nil {
+		db.logger.Warn("state sync interrupted", "root", root,
+			"nodes", stats.nodes, "codes", stats.codes, "fetched", stats.fetched, "err", err)
+
+		return err
+	}
+
+	if err := db.setSyncingRoot(types.ZeroHash); err != nil {
+		return err
+	}
+
+	db.logger.Info("state synced", "root", root,
+		"nodes", stats.nodes, "codes", stats.codes, "fetched", stats.fetched)
+
+	return nil
+}
+
+// syncState walks the state of the root depth first, the missing items are fetched
+// from the peers and persisted in batches
+func (db *stateDBImpl) syncState(ctx context.Context, root types.Hash) (stateSyncStats, error) {
+	var (
+		stats   = stateSyncStats{}
+		pending = []syncRequest{{hash: root, kind: syncAccountNode}}
+		// the storage tries and the codes shared by the accounts are walked once
+		seen = map[types.Hash]struct{}{}
+	)
+
+	push := func(req syncRequest) {
+		if req.kind == syncCode || req.root {
+			if _, ok := seen[req.hash]; ok {
+				return
+			}
+
+			seen[req.hash] = struct{}{}
+		}
+
+		pending = append(pending, req)
+	}
+
+	for len(pending) > 0 {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		n := len(pending)
+		if n > maxSyncRequests {
+			n = maxSyncRequests
+		}
+
+		reqs := append([]syncRequest{}, pending[len(pending)-n:]...)
+		pending = pending[:len(pending)-n]
+
+		items, err := db.loadSyncItems(reqs, &stats)
+		if err != nil {
+			return stats, err
+		}
+
+		for i, req := range reqs {
+			if req.kind == syncCode {
+				stats.codes++
+
+				continue
+			}
+
+			stats.nodes++
+
+			if err := syncNodeChildren(items[i], req.kind, push); err != nil {
+				return stats, fmt.Errorf("invalid trie node %s: %w", req.hash, err)
+			}
+		}
+
+		if stats.nodes%(maxSyncRequests*1024) < n {
+			db.logger.Info("syncing state", "root", root,
+				"nodes", stats.nodes, "codes", stats.codes, "fetched", stats.fetched)
+		}
+	}
+
+	return stats, nil
+}
+
+// loadSyncItems returns the items of the requests, the ones missing from the disk are
+// fetched from the peers and persisted
+func (db *stateDBImpl) loadSyncItems(reqs []syncRequest, stats *stateSyncStats) ([][]byte, error) {
+	var (
+		items   = make([][]byte, len(reqs))
+		missing = make([]types.Hash, 0, len(reqs))
+		indexes = make([]int, 0, len(reqs))
+	)
+
+	for i, req := range reqs {
+		data, ok, err := db.storage.Get(syncItemKey(req))
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			items[i] = data
+		} else {
+			missing = append(missing, req.hash)
+			indexes = append(indexes, i)
+		}
+	}
+
+	if len(missing) == 0 {
+		return items, nil
+	}
+
+	db.fetcherLock.RLock()
+	fetcher := db.fetcher
+	db.fetcherLock.RUnlock()
+
+	if fetcher == nil {
+		return nil, errNoNodeFetcher
+	}
+
+	fetched, fetchErr := fetcher.FetchTrieNodes(missing)
+
+	batch := db.storage.NewBatch()
+
+	for j, i := range indexes {
+		// the peers are not trusted, the items are addressed by their hashes
+		if j >= len(fetched) || len(fetched[j]) == 0 ||
+			!bytes.Equal(crypto.Keccak256(fetched[j]), missing[j].Bytes()) {
+			continue
+		}
+
+		if err := batch.Set(syncItemKey(reqs[i]), fetched[j]); err != nil {
+			return nil, err
+		}
+
+		items[i] = fetched[j]
+		stats.fetched++
+	}
+
+	// the fetched items are kept, even if some others are not fetched
+	if err := batch.Commit(); err != nil {
+		return nil, err
+	}
+
+	for _, i := range indexes {
+		if items[i] == nil {
+			if fetchErr == nil {
+				fetchErr = ErrMissingTrieNode
+			}
+
+			return nil, fmt.Errorf("failed to fetch %s: %w", reqs[i].hash, fetchErr)
+		}
+	}
+
+	return items, nil
+}
+
+// syncItemKey returns the storage key of the item
+func syncItemKey(req syncRequest) []byte {
+	if req.kind == syncCode {
+		return codeKey(req.hash)
+	}
+
+	return req.hash.Bytes()
+}
+
+// syncNodeChildren pushes the nodes referenced by the encoded node, and the storage
+// roots and the codes of the accounts of its leaves
+func syncNodeChildren(data []byte, kind syncKind, push func(syncRequest)) error {
+	p := parserPool.Get()
+	defer parserPool.Put(p)
+
+	v, err := p.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	node, err := decodeNode(v)
+	if err != nil {
+		return err
+	}
+
+	return syncChildren(node, kind, push)
+}
+
+func syncChildren(node Node, kind syncKind, push func(syncRequest)) error {
+	switch n := node.(type) {
+	case nil:
+		return nil
+
+	case *ValueNode:
+		if n.hash {
+			push(syncRequest{hash: types.BytesToHash(n.buf), kind: kind})
+
+			return nil
+		}
+
+		// the storage values reference nothing
+		if kind != syncAccountNode {
+			return nil
+		}
+
+		var account state.Account
+		if err := account.UnmarshalRlp(n.buf); err != nil {
+			return err
+		}
+
+		if account.Root != types.EmptyRootHash && account.Root != types.ZeroHash {
+			push(syncRequest{hash: account.Root, kind: syncStorageNode, root: true})
+		}
+
+		if codeHash := types.BytesToHash(account.CodeHash); !isEmptyCode(codeHash) {
+			push(syncRequest{hash: codeHash, kind: syncCode})
+		}
+
+		return nil
+
+	case *ShortNode:
+		return syncChildren(n.child, kind, push)
+
+	case *FullNode:
+		for _, child := range n.children {
+			if err := syncChildren(child, kind, push); err != nil {
+				return err
+			}
+		}
+
+		return syncChildren(n.value, kind, push)
+
+	default:
+		return fmt.Errorf("unknown node type %T", n)
+	}
+}
@@ -0,0 +1,190 @@
+package itrie
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/state"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSyncSource returns the state db synced from, and the root of its state
+func newSyncSource(t *testing.T) (StateDB, types.Hash, []*state.Object) {
+	t.Helper()
+
+	code := []byte{0x60, 0x80, 0x60, 0x40}
+	objs := []*state.Object{}
+
+	for i := 1; i <= 64; i++ {
+		obj := &state.Object{
+			Address: types.BytesToAddress(big.NewInt(int64(i)).Bytes()),
+			Balance: big.NewInt(int64(i)),
+			Root:    types.EmptyRootHash,
+		}
+
+		// the contracts share the code and some of the storages
+		if i%8 == 0 {
+			obj.CodeHash = types.BytesToHash(crypto.Keccak256(code))
+			obj.Code = code
+			obj.DirtyCode = true
+			obj.Storage = []*state.StorageObject{
+				{Key: types.StringToHash("1").Bytes(), Val: []byte{byte(i % 16)}},
+				{Key: types.StringToHash("2").Bytes(), Val: []byte{2}},
+			}
+		}
+
+		objs = append(objs, obj)
+	}
+
+	st := NewStateDB(NewMemoryStorage(), hclog.NewNullLogger(), nil)
+
+	_, root, err := st.NewSnapshot().Commit(objs)
+	assert.NoError(t, err)
+
+	return st, types.BytesToHash(root), objs
+}
+
+// sourceFetcher serves the trie nodes and the codes of the source, the requested
+// hashes are counted
+func sourceFetcher(source StateDB, requested *int) mockNodeFetcher {
+	return func(hashes []types.Hash) ([][]byte, error) {
+		*requested += len(hashes)
+
+		nodes := make([][]byte, len(hashes))
+
+		for i, hash := range hashes {
+			if data, ok, _ := source.Get(hash.Bytes()); ok {
+				nodes[i] = data
+			} else if code, ok := source.GetCode(hash); ok {
+				nodes[i] = code
+			}
+		}
+
+		return nodes, nil
+	}
+}
+
+func TestStateDB_SyncState(t *testing.T) {
+	source, root, objs := newSyncSource(t)
+
+	st := NewStateDB(NewMemoryStorage(), hclog.NewNullLogger(), nil)
+
+	requested := 0
+	st.SetNodeFetcher(sourceFetcher(source, &requested))
+
+	assert.NoError(t, st.SyncState(context.Background(), root))
+
+	snap, err := st.NewSnapshotAt(root)
+	assert.NoError(t, err)
+
+	for _, obj := range objs {
+		account, err := snap.GetAccount(obj.Address)
+		assert.NoError(t, err)
+		assert.Equal(t, obj.Balance, account.Balance)
+
+		if !obj.DirtyCode {
+			continue
+		}
+
+		code, ok := st.GetCode(obj.CodeHash)
+		assert.True(t, ok)
+		assert.Equal(t, obj.Code, code)
+
+		value, err := snap.GetStorage(obj.Address, account.Root, types.BytesToHash(obj.Storage[1].Key))
+		assert.NoError(t, err)
+		assert.Equal(t, types.BytesToHash([]byte{2}), value)
+	}
+
+	// the complete state is not synced again
+	requested = 0
+
+	assert.NoError(t, st.SyncState(context.Background(), root))
+	assert.Equal(t, 0, requested)
+}
+
+func TestStateDB_SyncStateResume(t *testing.T) {
+	source, root, objs := newSyncSource(t)
+
+	storage := NewMemoryStorage()
+	st := NewStateDB(storage, hclog.NewNullLogger(), nil)
+
+	var (
+		errPeers  = errors.New("no peers")
+		requested = 0
+		fetcher   = sourceFetcher(source, &requested)
+	)
+
+	// the peers are gone after serving the first requests
+	st.SetNodeFetcher(mockNodeFetcher(func(hashes []types.Hash) ([][]byte, error) {
+		if requested >= 4 {
+			return make([][]byte, len(hashes)), errPeers
+		}
+
+		return fetcher(hashes)
+	}))
+
+	assert.ErrorIs(t, st.SyncState(context.Background(), root), errPeers)
+
+	// the partial state is neither served nor executed on, even after a restart
+	st = NewStateDB(storage, hclog.NewNullLogger(), nil)
+
+	_, err := st.NewSnapshotAt(root)
+	assert.ErrorIs(t, err, ErrStateSyncing)
+
+	_, err = st.ProveAccount(root, objs[0].Address)
+	assert.ErrorIs(t, err, ErrStateSyncing)
+
+	// the sync is resumed without fetching the stored items again
+	interrupted := requested
+	requested = 0
+
+	st.SetNodeFetcher(fetcher)
+	assert.NoError(t, st.SyncState(context.Background(), root))
+
+	resumed := requested
+	requested = 0
+
+	fresh := NewStateDB(NewMemoryStorage(), hclog.NewNullLogger(), nil)
+	fresh.SetNodeFetcher(fetcher)
+	assert.NoError(t, fresh.SyncState(context.Background(), root))
+	assert.Equal(t, requested, interrupted+resumed)
+
+	snap, err := st.NewSnapshotAt(root)
+	assert.NoError(t, err)
+
+	account, err := snap.GetAccount(objs[10].Address)
+	assert.NoError(t, err)
+	assert.Equal(t, objs[10].Balance, account.Balance)
+}
+
+func TestStateDB_SyncStateForged(t *testing.T) {
+	source, root, _ := newSyncSource(t)
+
+	st := NewStateDB(NewMemoryStorage(), hclog.NewNullLogger(), nil)
+
+	requested := 0
+	fetcher := sourceFetcher(source, &requested)
+
+	// a peer serving the nodes off the hashes
+	st.SetNodeFetcher(mockNodeFetcher(func(hashes []types.Hash) ([][]byte, error) {
+		nodes, err := fetcher(hashes)
+
+		for i := range nodes {
+			if len(nodes[i]) > 0 {
+				nodes[i] = append(append([]byte{}, nodes[i]...), 0x80)
+			}
+		}
+
+		return nodes, err
+	}))
+
+	assert.ErrorIs(t, st.SyncState(context.Background(), root), ErrMissingTrieNode)
+
+	_, err := st.NewSnapshotAt(root)
+	assert.ErrorIs(t, err, ErrStateSyncing)
+}
@@ -67,6 +67,15 @@ func (kv *kvStorage) NewBatch() Batch {
 	}
 }
 
+// Compact compacts the keys within [start, limit) of the storage, if it compacts on demand
+func (kv *kvStorage) Compact(start, limit []byte) error {
+	if compacter, ok := kv.db.(kvdb.KVCompacter); ok {
+		return compacter.Compact(start, limit)
+	}
+
+	return kvdb.ErrCompactNotSupported
+}
+
 func (kv *kvStorage) Close() error {
 	return kv.db.Close()
 }
@@ -130,11 +139,19 @@ func (m *memBatch) Commit() error {
 	return nil
 }
 
-// GetNode retrieves a node from storage
+// GetNode retrieves a node from storage. The missing node is recovered if
+// the storage is able to heal it.
 func GetNode(root []byte, storage StorageReader) (Node, bool, error) {
 	data, ok, _ := storage.Get(root)
 	if !ok {
-		return nil, false, nil
+		healer, isHealer := storage.(nodeHealer)
+		if !isHealer {
+			return nil, false, nil
+		}
+
+		if data, ok = healer.healNode(root); !ok {
+			return nil, false, nil
+		}
 	}
 
 	// NOTE. We dont need to make copies of the bytes because the nodes
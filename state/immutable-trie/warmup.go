@@ -0,0 +1,74 @@
+package itrie
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// Warmup loads the trie nodes of the state root into the cache, down to the depth
+// of the nodes loaded. The top of the trie is walked by every account read, which
+// is slow while the cache is cold. It returns the number of the nodes loaded.
+func (db *stateDBImpl) Warmup(ctx context.Context, root types.Hash, depth int) (int, error) {
+	if depth <= 0 || root == types.EmptyRootHash {
+		return 0, nil
+	}
+
+	n, ok, err := GetNode(root.Bytes(), db)
+	if err != nil {
+		return 0, err
+	} else if !ok {
+		return 0, fmt.Errorf("state not found at hash %s", root)
+	}
+
+	loaded := 1
+
+	err = db.warmupNode(ctx, n, 1, depth, &loaded)
+
+	return loaded, err
+}
+
+// warmupNode loads the children of the node at the level, until the depth
+func (db *stateDBImpl) warmupNode(ctx context.Context, node Node, level, depth int, loaded *int) error {
+	switch node := node.(type) {
+	case nil:
+		return nil
+
+	case *ValueNode:
+		// the leaf values are not nodes
+		if !node.hash || level >= depth {
+			return nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		nc, ok, err := GetNode(node.buf, db)
+		if err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("trie node %x not found", node.buf)
+		}
+
+		*loaded++
+
+		return db.warmupNode(ctx, nc, level+1, depth, loaded)
+
+	case *ShortNode:
+		return db.warmupNode(ctx, node.child, level, depth, loaded)
+
+	case *FullNode:
+		for _, child := range node.children {
+			if err := db.warmupNode(ctx, child, level, depth, loaded); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	default:
+		panic(fmt.Sprintf("unknown node type %v", node))
+	}
+}
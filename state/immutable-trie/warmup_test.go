@@ -0,0 +1,51 @@
+package itrie
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/state"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateDB_Warmup(t *testing.T) {
+	storage := NewMemoryStorage()
+	snap := NewStateDB(storage, hclog.NewNullLogger(), nil).NewSnapshot()
+
+	txn := state.NewTxn(snap)
+	for i := 0; i < 256; i++ {
+		txn.SetBalance(types.BytesToAddress(big.NewInt(int64(i+1)).Bytes()), big.NewInt(1))
+	}
+
+	_, root, err := snap.Commit(txn.Commit(false))
+	assert.NoError(t, err)
+
+	// a cold cache of a restarted node
+	db, ok := NewStateDB(storage, hclog.NewNullLogger(), nil).(*stateDBImpl)
+	assert.True(t, ok)
+
+	loaded, err := db.Warmup(context.Background(), types.BytesToHash(root), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, loaded)
+
+	_, _, cached, err := db.getCached(root)
+	assert.NoError(t, err)
+	assert.True(t, cached)
+
+	// the full node of the root and its children
+	loaded, err = db.Warmup(context.Background(), types.BytesToHash(root), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 17, loaded)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = db.Warmup(ctx, types.BytesToHash(root), 3)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = db.Warmup(context.Background(), types.StringToHash("1"), 1)
+	assert.Error(t, err)
+}
@@ -239,6 +239,7 @@ func (c *state) formatPanicDesc() error {
 func (c *state) Run() (ret []byte, vmerr error) {
 	var (
 		needDebug bool
+		opHook    runtime.EVMOpcodeHook // optional hook of the logger before the opcodes
 		//nolint:stylecheck
 		executedIp uint64
 		logged     bool       // deferred EVMLogger should ignore already logged steps
@@ -260,6 +261,7 @@ func (c *state) Run() (ret []byte, vmerr error) {
 			needDebug = false
 		default:
 			needDebug = true
+			opHook, _ = logger.(runtime.EVMOpcodeHook)
 		}
 	}
 
@@ -325,6 +327,14 @@ func (c *state) Run() (ret []byte, vmerr error) {
 			break
 		}
 
+		if opHook != nil {
+			opHook.CaptureOpcode(&runtime.ScopeContext{
+				Memory:          c.memory,
+				Stack:           c.stack[:c.sp],
+				ContractAddress: c.msg.Address,
+			}, uint64(c.ip), int(op), c.msg.Depth)
+		}
+
 		// execute the instruction
 		inst.inst(c)
 
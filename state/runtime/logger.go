@@ -11,6 +11,9 @@ import (
 type Txn interface {
 	GetState(addr types.Address, key types.Hash) (types.Hash, error)
 	GetRefund() uint64
+	GetBalance(addr types.Address) *big.Int
+	GetNonce(addr types.Address) uint64
+	GetCode(addr types.Address) []byte
 }
 
 // ScopeContext contains the things that are per-call, such as stack and memory,
@@ -33,3 +36,11 @@ type EVMLogger interface {
 	CaptureFault(ctx *ScopeContext, pc uint64, opCode int, gas, cost uint64, depth int, err error)
 	CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error)
 }
+
+// EVMOpcodeHook is an optional hook of the EVMLogger, called before each opcode is
+// executed, while CaptureState is called after. The tracers looking up the state
+// touched by the opcode hook it, before the opcode changes it.
+// The stack and memory of the scope are the live VM data, make copies to retain them.
+type EVMOpcodeHook interface {
+	CaptureOpcode(ctx *ScopeContext, pc uint64, opCode int, depth int)
+}
@@ -53,6 +53,14 @@ func (p *Precompiled) register(addrStr string, b contract) {
 	p.contracts[types.StringToAddress(addrStr)] = b
 }
 
+// Contains returns whether the address is of a precompiled contract, regardless of
+// the fork it is enabled in
+func (p *Precompiled) Contains(addr types.Address) bool {
+	_, ok := p.contracts[addr]
+
+	return ok
+}
+
 var (
 	five  = types.StringToAddress("5")
 	six   = types.StringToAddress("6")
@@ -81,6 +81,9 @@ type ExecutionResult struct {
 	GasLeft     uint64 // Total gas left as result of execution
 	GasUsed     uint64 // Total gas used as result of execution
 	Err         error  // Any error encountered during the execution, listed below
+
+	IntrinsicGas uint64 // Gas charged before the execution, set on the transaction result only
+	GasRefund    uint64 // Gas refunded after the execution, capped to half the gas used
 }
 
 func (r *ExecutionResult) Succeeded() bool { return r.Err == nil }
@@ -117,6 +120,13 @@ func (r *ExecutionResult) UpdateGasUsed(gasLimit uint64, refund uint64) {
 
 	r.GasLeft += refund
 	r.GasUsed -= refund
+	r.GasRefund = refund
+}
+
+// ExecutionGas returns the gas used by the execution, without the intrinsic gas and
+// before the refund
+func (r *ExecutionResult) ExecutionGas() uint64 {
+	return r.GasUsed + r.GasRefund - r.IntrinsicGas
 }
 
 var (
@@ -16,12 +16,32 @@ type State interface {
 	NewSnapshotAt(types.Hash) (Snapshot, error)
 	NewSnapshot() Snapshot
 	GetCode(hash types.Hash) ([]byte, bool)
+
+	// GetPreimage returns the address or storage slot hashed to the trie key, if
+	// the node recorded it
+	GetPreimage(hash types.Hash) ([]byte, bool)
+
+	// ProveAccount returns the merkle proof of the account within the state root
+	ProveAccount(root types.Hash, addr types.Address) ([][]byte, error)
+	// ProveStorage returns the merkle proof of the slot within the storage root
+	ProveStorage(root types.Hash, slot types.Hash) ([][]byte, error)
 }
 
 type Snapshot interface {
 	snapshotReader
 
+	// GetStorageSlots returns the values of the slots within the storage root
+	GetStorageSlots(root types.Hash, slots []types.Hash) ([]types.Hash, error)
+
+	// IterateAccounts walks the accounts in the order of their address hashes, from
+	// the start hash (inclusive). The walk stops once fn returns false.
+	IterateAccounts(start types.Hash, fn func(hash types.Hash, account *Account) bool) error
+
 	Commit(objs []*Object) (Snapshot, []byte, error)
+
+	// Root returns the state root the objects would be committed to, without writing
+	// the trie nodes
+	Root(objs []*Object) ([]byte, error)
 }
 
 // account trie
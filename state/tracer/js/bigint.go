@@ -0,0 +1,255 @@
+package js
+
+import (
+	"math/big"
+
+	"github.com/dop251/goja"
+)
+
+// bigInt is the JavaScript big integer of the tracers. It implements the methods of
+// the BigInteger.js library the go-ethereum tracers are written against, the method
+// names are uncapitalized by the field name mapper of the runtime.
+type bigInt struct {
+	t *jsTracer
+	v *big.Int
+}
+
+func (t *jsTracer) newBigInt(v *big.Int) *bigInt {
+	return &bigInt{t: t, v: v}
+}
+
+// toBig converts the value to an integer, the big integers, the numbers and the
+// decimal or 0x-prefixed hex strings are accepted
+func (t *jsTracer) toBig(v goja.Value) (*big.Int, bool) {
+	switch x := v.Export().(type) {
+	case *bigInt:
+		return x.v, true
+	case int64:
+		return big.NewInt(x), true
+	case float64:
+		f := big.NewFloat(x)
+		if !f.IsInt() {
+			return nil, false
+		}
+
+		i, _ := f.Int(nil)
+
+		return i, true
+	case string:
+		if len(x) > 2 && x[0] == '0' && (x[1] == 'x' || x[1] == 'X') {
+			return new(big.Int).SetString(x[2:], 16)
+		}
+
+		return new(big.Int).SetString(x, 10)
+	}
+
+	return nil, false
+}
+
+// mustBig converts the argument to an integer, or throws a type error
+func (b *bigInt) mustBig(v goja.Value) *big.Int {
+	x, ok := b.t.toBig(v)
+	if !ok {
+		panic(b.t.vm.NewTypeError("invalid big integer: %s", v))
+	}
+
+	return x
+}
+
+func (b *bigInt) wrap(v *big.Int) *bigInt {
+	return b.t.newBigInt(v)
+}
+
+func (b *bigInt) Add(x goja.Value) *bigInt {
+	return b.wrap(new(big.Int).Add(b.v, b.mustBig(x)))
+}
+
+func (b *bigInt) Plus(x goja.Value) *bigInt {
+	return b.Add(x)
+}
+
+func (b *bigInt) Subtract(x goja.Value) *bigInt {
+	return b.wrap(new(big.Int).Sub(b.v, b.mustBig(x)))
+}
+
+func (b *bigInt) Minus(x goja.Value) *bigInt {
+	return b.Subtract(x)
+}
+
+func (b *bigInt) Multiply(x goja.Value) *bigInt {
+	return b.wrap(new(big.Int).Mul(b.v, b.mustBig(x)))
+}
+
+func (b *bigInt) Times(x goja.Value) *bigInt {
+	return b.Multiply(x)
+}
+
+// Divide truncates the quotient toward zero, like BigInteger.js
+func (b *bigInt) Divide(x goja.Value) *bigInt {
+	d := b.mustBig(x)
+	if d.Sign() == 0 {
+		panic(b.t.vm.NewTypeError("cannot divide by zero"))
+	}
+
+	return b.wrap(new(big.Int).Quo(b.v, d))
+}
+
+func (b *bigInt) Over(x goja.Value) *bigInt {
+	return b.Divide(x)
+}
+
+// Mod keeps the sign of the dividend, like BigInteger.js
+func (b *bigInt) Mod(x goja.Value) *bigInt {
+	d := b.mustBig(x)
+	if d.Sign() == 0 {
+		panic(b.t.vm.NewTypeError("cannot divide by zero"))
+	}
+
+	return b.wrap(new(big.Int).Rem(b.v, d))
+}
+
+func (b *bigInt) Pow(x goja.Value) *bigInt {
+	e := b.mustBig(x)
+	if e.Sign() < 0 || e.BitLen() > 16 {
+		panic(b.t.vm.NewTypeError("unsupported exponent: %s", e))
+	}
+
+	return b.wrap(new(big.Int).Exp(b.v, e, nil))
+}
+
+func (b *bigInt) And(x goja.Value) *bigInt {
+	return b.wrap(new(big.Int).And(b.v, b.mustBig(x)))
+}
+
+func (b *bigInt) Or(x goja.Value) *bigInt {
+	return b.wrap(new(big.Int).Or(b.v, b.mustBig(x)))
+}
+
+func (b *bigInt) Xor(x goja.Value) *bigInt {
+	return b.wrap(new(big.Int).Xor(b.v, b.mustBig(x)))
+}
+
+func (b *bigInt) ShiftLeft(n int) *bigInt {
+	if n < 0 {
+		return b.ShiftRight(-n)
+	}
+
+	return b.wrap(new(big.Int).Lsh(b.v, uint(n)))
+}
+
+func (b *bigInt) ShiftRight(n int) *bigInt {
+	if n < 0 {
+		return b.ShiftLeft(-n)
+	}
+
+	return b.wrap(new(big.Int).Rsh(b.v, uint(n)))
+}
+
+func (b *bigInt) Abs() *bigInt {
+	return b.wrap(new(big.Int).Abs(b.v))
+}
+
+func (b *bigInt) Negate() *bigInt {
+	return b.wrap(new(big.Int).Neg(b.v))
+}
+
+func (b *bigInt) Compare(x goja.Value) int {
+	return b.v.Cmp(b.mustBig(x))
+}
+
+func (b *bigInt) CompareTo(x goja.Value) int {
+	return b.Compare(x)
+}
+
+func (b *bigInt) Equals(x goja.Value) bool {
+	return b.Compare(x) == 0
+}
+
+func (b *bigInt) Eq(x goja.Value) bool {
+	return b.Equals(x)
+}
+
+func (b *bigInt) NotEquals(x goja.Value) bool {
+	return b.Compare(x) != 0
+}
+
+func (b *bigInt) Neq(x goja.Value) bool {
+	return b.NotEquals(x)
+}
+
+func (b *bigInt) Greater(x goja.Value) bool {
+	return b.Compare(x) > 0
+}
+
+func (b *bigInt) Gt(x goja.Value) bool {
+	return b.Greater(x)
+}
+
+func (b *bigInt) GreaterOrEquals(x goja.Value) bool {
+	return b.Compare(x) >= 0
+}
+
+func (b *bigInt) Geq(x goja.Value) bool {
+	return b.GreaterOrEquals(x)
+}
+
+func (b *bigInt) Lesser(x goja.Value) bool {
+	return b.Compare(x) < 0
+}
+
+func (b *bigInt) Lt(x goja.Value) bool {
+	return b.Lesser(x)
+}
+
+func (b *bigInt) LesserOrEquals(x goja.Value) bool {
+	return b.Compare(x) <= 0
+}
+
+func (b *bigInt) Leq(x goja.Value) bool {
+	return b.LesserOrEquals(x)
+}
+
+func (b *bigInt) IsZero() bool {
+	return b.v.Sign() == 0
+}
+
+func (b *bigInt) IsNegative() bool {
+	return b.v.Sign() < 0
+}
+
+func (b *bigInt) IsPositive() bool {
+	return b.v.Sign() > 0
+}
+
+// ToString formats the integer in the radix, 10 by default
+func (b *bigInt) ToString(call goja.FunctionCall) goja.Value {
+	radix := 10
+
+	if arg := call.Argument(0); !goja.IsUndefined(arg) {
+		radix = int(arg.ToInteger())
+	}
+
+	if radix < 2 || radix > 36 {
+		panic(b.t.vm.NewTypeError("invalid radix: %d", radix))
+	}
+
+	return b.t.vm.ToValue(b.v.Text(radix))
+}
+
+// ToJSON formats the integer in decimal, the results could not hold it as a number
+func (b *bigInt) ToJSON() string {
+	return b.v.String()
+}
+
+// ToNumber converts the integer to the closest number
+func (b *bigInt) ToNumber() float64 {
+	f, _ := new(big.Float).SetInt(b.v).Float64()
+
+	return f
+}
+
+// ValueOf converts the integer to the closest number, so that the integers compare
+// and add up with the numbers
+func (b *bigInt) ValueOf() float64 {
+	return b.ToNumber()
+}
@@ -0,0 +1,709 @@
+// Package js implements the debug_trace* tracers supplied by the users in JavaScript,
+// evaluated by the goja engine. The tracers follow the API of the go-ethereum
+// JavaScript tracers: the code is an object with the step, fault and result
+// functions, optionally enter and exit for the call frames, and setup for the config.
+package js
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/state/runtime"
+	"github.com/dogechain-lab/dogechain/state/runtime/evm"
+	"github.com/dogechain-lab/dogechain/state/runtime/precompiled"
+	"github.com/dogechain-lab/dogechain/state/tracer"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/dop251/goja"
+)
+
+var (
+	errInvalidTracer = errors.New("invalid tracer")
+	errInvalidBuffer = errors.New("invalid buffer")
+)
+
+func init() {
+	// the code of the users is tried after the built-in tracers
+	tracer.RegisterLookup(true, newJsTracer)
+}
+
+// precompiles looks up the precompiled contracts for isPrecompiled
+var precompiles = precompiled.NewPrecompiled()
+
+// contractFrame is the contract running in the call frame, the log.contract of the steps
+type contractFrame struct {
+	caller types.Address
+	value  *big.Int
+	input  []byte
+}
+
+// jsTracer runs the hooks of the tracer object of the user on each step and call
+// frame. The objects passed to the hooks are created once, and read the state of
+// the current step.
+type jsTracer struct {
+	vm  *goja.Runtime
+	ctx *tracer.Context
+	txn runtime.Txn
+
+	// the tracer object of the user, and its hooks
+	obj                 *goja.Object
+	step, fault, result goja.Callable
+	enter, exit         goja.Callable
+	traceStep           bool // the step function is set
+	traceFrame          bool // the enter and exit functions are set
+
+	// the objects passed to the hooks
+	ctxValue, logValue, dbValue  *goja.Object
+	frameValue, frameResultValue *goja.Object
+	uint8Array                   goja.Value
+
+	callstack []*contractFrame
+
+	// the current step, read by the log object
+	scope   *runtime.ScopeContext
+	pc      uint64
+	opCode  int
+	gas     uint64
+	cost    uint64
+	depth   int
+	stepErr error
+
+	// the current call frame, read by the frame objects
+	frame       callFrame
+	frameResult callFrameResult
+
+	err error // the first error of the hooks, the trace fails with it
+
+	interrupt atomic.Bool
+	reason    error // the reason the trace is stopped
+}
+
+// callFrame is the call frame entered, passed to enter
+type callFrame struct {
+	typ      string
+	from, to types.Address
+	input    []byte
+	gas      uint64
+	value    *big.Int // nil for the delegate and static calls
+}
+
+// callFrameResult is the result of the call frame exited, passed to exit
+type callFrameResult struct {
+	gasUsed uint64
+	output  []byte
+	err     error
+}
+
+// newJsTracer compiles the tracer object of the code. The bare identifiers are left
+// to the other lookups, as they name the built-in tracers.
+func newJsTracer(code string, ctx *tracer.Context, cfg json.RawMessage) (tracer.Tracer, error) {
+	if isIdentifier(code) {
+		return nil, tracer.ErrTracerNotFound
+	}
+
+	vm := goja.New()
+	vm.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
+
+	t := &jsTracer{
+		vm:         vm,
+		ctx:        ctx,
+		uint8Array: vm.Get("Uint8Array"),
+	}
+
+	if err := t.setBuiltins(); err != nil {
+		return nil, err
+	}
+
+	value, err := vm.RunString("(" + code + ")")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidTracer, err)
+	}
+
+	obj, ok := value.(*goja.Object)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an object", errInvalidTracer)
+	}
+
+	t.obj = obj
+
+	if t.result, ok = goja.AssertFunction(obj.Get("result")); !ok {
+		return nil, fmt.Errorf("%w: result function missing", errInvalidTracer)
+	}
+
+	if t.fault, ok = goja.AssertFunction(obj.Get("fault")); !ok {
+		return nil, fmt.Errorf("%w: fault function missing", errInvalidTracer)
+	}
+
+	t.step, t.traceStep = goja.AssertFunction(obj.Get("step"))
+
+	var hasExit bool
+
+	t.enter, t.traceFrame = goja.AssertFunction(obj.Get("enter"))
+	t.exit, hasExit = goja.AssertFunction(obj.Get("exit"))
+
+	if t.traceFrame != hasExit {
+		return nil, fmt.Errorf("%w: enter and exit functions go together", errInvalidTracer)
+	}
+
+	if setup, ok := goja.AssertFunction(obj.Get("setup")); ok {
+		config := "{}"
+		if len(cfg) > 0 {
+			config = string(cfg)
+		}
+
+		if _, err := setup(obj, vm.ToValue(config)); err != nil {
+			return nil, fmt.Errorf("%w: setup: %v", errInvalidTracer, err)
+		}
+	}
+
+	t.ctxValue = vm.NewObject()
+	t.logValue = t.newLogObject()
+	t.dbValue = t.newDBObject()
+	t.frameValue = t.newFrameObject()
+	t.frameResultValue = t.newFrameResultObject()
+
+	if ctx != nil {
+		t.setCtx("blockHash", t.toBuf(ctx.BlockHash.Bytes()))
+		t.setCtx("block", ctx.BlockNumber)
+		t.setCtx("txIndex", ctx.TxIndex)
+		t.setCtx("txHash", t.toBuf(ctx.TxHash.Bytes()))
+
+		if ctx.GasPrice != nil {
+			t.setCtx("gasPrice", t.newBigInt(new(big.Int).Set(ctx.GasPrice)))
+		}
+	}
+
+	return t, nil
+}
+
+func (t *jsTracer) CaptureStart(txn runtime.Txn, from, to types.Address,
+	create bool, input []byte, gas uint64, value *big.Int) {
+	t.txn = txn
+
+	typ := "CALL"
+	if create {
+		typ = "CREATE"
+	}
+
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	t.setCtx("type", typ)
+	t.setCtx("from", t.toBuf(from.Bytes()))
+	t.setCtx("to", t.toBuf(to.Bytes()))
+	t.setCtx("input", t.toBuf(input))
+	t.setCtx("gas", gas)
+	t.setCtx("value", t.newBigInt(new(big.Int).Set(value)))
+
+	t.callstack = []*contractFrame{{caller: from, value: value, input: input}}
+}
+
+func (t *jsTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) {
+	t.setCtx("output", t.toBuf(output))
+	t.setCtx("gasUsed", gasUsed)
+	t.setCtx("time", d.String())
+
+	if err != nil {
+		t.setCtx("error", err.Error())
+	}
+}
+
+func (t *jsTracer) CaptureEnter(opCode int, from, to types.Address,
+	input []byte, gas uint64, value *big.Int) {
+	typ := evm.OpCode(opCode)
+
+	// the delegate and static calls transfer no value
+	if typ == evm.DELEGATECALL || typ == evm.STATICCALL {
+		value = nil
+	}
+
+	frameValue := value
+	if frameValue == nil {
+		frameValue = new(big.Int)
+	}
+
+	t.callstack = append(t.callstack, &contractFrame{caller: from, value: frameValue, input: input})
+
+	if !t.traceFrame || t.stopped() {
+		return
+	}
+
+	t.frame = callFrame{typ: typ.String(), from: from, to: to, input: input, gas: gas, value: value}
+
+	if _, err := t.enter(t.obj, t.frameValue); err != nil {
+		t.onError("enter", err)
+	}
+}
+
+func (t *jsTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if len(t.callstack) > 1 {
+		t.callstack = t.callstack[:len(t.callstack)-1]
+	}
+
+	if !t.traceFrame || t.stopped() {
+		return
+	}
+
+	t.frameResult = callFrameResult{gasUsed: gasUsed, output: output, err: err}
+
+	if _, err := t.exit(t.obj, t.frameResultValue); err != nil {
+		t.onError("exit", err)
+	}
+}
+
+func (t *jsTracer) CaptureState(ctx *runtime.ScopeContext, pc uint64, opCode int,
+	gas, cost uint64, rData []byte, depth int, err error) {
+	if !t.traceStep || t.stopped() {
+		return
+	}
+
+	t.setStep(ctx, pc, opCode, gas, cost, depth, err)
+
+	if _, err := t.step(t.obj, t.logValue, t.dbValue); err != nil {
+		t.onError("step", err)
+	}
+}
+
+func (t *jsTracer) CaptureFault(ctx *runtime.ScopeContext, pc uint64, opCode int,
+	gas, cost uint64, depth int, err error) {
+	if t.stopped() {
+		return
+	}
+
+	t.setStep(ctx, pc, opCode, gas, cost, depth, err)
+
+	if _, err := t.fault(t.obj, t.logValue, t.dbValue); err != nil {
+		t.onError("fault", err)
+	}
+}
+
+// GetResult returns the JSON of the value the result function returns
+func (t *jsTracer) GetResult() (json.RawMessage, error) {
+	if t.interrupt.Load() {
+		return nil, t.reason
+	}
+
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	result, err := t.result(t.obj, t.ctxValue, t.dbValue)
+	if err != nil {
+		return nil, wrapError("result", err)
+	}
+
+	stringify, _ := goja.AssertFunction(t.vm.Get("JSON").ToObject(t.vm).Get("stringify"))
+
+	encoded, err := stringify(goja.Undefined(), result)
+	if err != nil {
+		return nil, wrapError("result", err)
+	}
+
+	if goja.IsUndefined(encoded) {
+		return json.RawMessage("null"), nil
+	}
+
+	return json.RawMessage(encoded.String()), nil
+}
+
+// Stop terminates the trace, the running hook is interrupted and the result discarded
+func (t *jsTracer) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+	t.vm.Interrupt(err)
+}
+
+func (t *jsTracer) stopped() bool {
+	return t.err != nil || t.interrupt.Load()
+}
+
+// onError fails the trace with the first error of the hooks
+func (t *jsTracer) onError(hook string, err error) {
+	if t.err == nil {
+		t.err = wrapError(hook, err)
+	}
+}
+
+func wrapError(hook string, err error) error {
+	var exception *goja.Exception
+	if errors.As(err, &exception) {
+		return fmt.Errorf("%v in the tracer function '%s'", exception.Value(), hook)
+	}
+
+	return fmt.Errorf("%w in the tracer function '%s'", err, hook)
+}
+
+func (t *jsTracer) setCtx(name string, value interface{}) {
+	// the ctx is a plain object, setting its fields could not fail
+	_ = t.ctxValue.Set(name, value)
+}
+
+func (t *jsTracer) setStep(ctx *runtime.ScopeContext, pc uint64, opCode int,
+	gas, cost uint64, depth int, err error) {
+	t.scope = ctx
+	t.pc, t.opCode, t.gas, t.cost, t.depth, t.stepErr = pc, opCode, gas, cost, depth, err
+}
+
+// toBuf returns the Uint8Array of a copy of the bytes
+func (t *jsTracer) toBuf(b []byte) goja.Value {
+	buf := make([]byte, len(b))
+	copy(buf, b)
+
+	obj, err := t.vm.New(t.uint8Array, t.vm.ToValue(t.vm.NewArrayBuffer(buf)))
+	if err != nil {
+		panic(t.vm.NewGoError(err))
+	}
+
+	return obj
+}
+
+// fromBuf returns the bytes of the Uint8Array, or of the hex string if allowed
+func (t *jsTracer) fromBuf(v goja.Value, allowString bool) ([]byte, error) {
+	if s, ok := v.Export().(string); ok {
+		if !allowString {
+			return nil, fmt.Errorf("%w: string", errInvalidBuffer)
+		}
+
+		return hex.DecodeHex(s)
+	}
+
+	obj, ok := v.(*goja.Object)
+	if !ok || !obj.Get("constructor").SameAs(t.uint8Array) {
+		return nil, fmt.Errorf("%w: %s", errInvalidBuffer, v)
+	}
+
+	buffer, ok := obj.Get("buffer").Export().(goja.ArrayBuffer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errInvalidBuffer, v)
+	}
+
+	data := buffer.Bytes()
+	offset, length := obj.Get("byteOffset").ToInteger(), obj.Get("byteLength").ToInteger()
+
+	if offset < 0 || length < 0 || offset+length > int64(len(data)) {
+		return nil, fmt.Errorf("%w: %s", errInvalidBuffer, v)
+	}
+
+	return data[offset : offset+length], nil
+}
+
+// mustBuf converts the argument to bytes, or throws a type error
+func (t *jsTracer) mustBuf(v goja.Value, allowString bool) []byte {
+	b, err := t.fromBuf(v, allowString)
+	if err != nil {
+		panic(t.vm.NewTypeError(err.Error()))
+	}
+
+	return b
+}
+
+func (t *jsTracer) mustAddress(v goja.Value) types.Address {
+	return types.BytesToAddress(t.mustBuf(v, true))
+}
+
+// setBuiltins sets the global helpers of the go-ethereum tracers
+func (t *jsTracer) setBuiltins() error {
+	builtins := map[string]func(goja.FunctionCall) goja.Value{
+		"toHex": func(call goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(hex.EncodeToHex(t.mustBuf(call.Argument(0), false)))
+		},
+		"toWord": func(call goja.FunctionCall) goja.Value {
+			return t.toBuf(types.BytesToHash(t.mustBuf(call.Argument(0), true)).Bytes())
+		},
+		"toAddress": func(call goja.FunctionCall) goja.Value {
+			return t.toBuf(t.mustAddress(call.Argument(0)).Bytes())
+		},
+		"toContract": func(call goja.FunctionCall) goja.Value {
+			from := t.mustAddress(call.Argument(0))
+			nonce := call.Argument(1).ToInteger()
+
+			return t.toBuf(crypto.CreateAddress(from, uint64(nonce)).Bytes())
+		},
+		"toContract2": func(call goja.FunctionCall) goja.Value {
+			from := t.mustAddress(call.Argument(0))
+			salt := types.BytesToHash(t.mustBuf(call.Argument(1), true))
+			initCode := t.mustBuf(call.Argument(2), true)
+
+			return t.toBuf(crypto.CreateAddress2(from, salt, crypto.Keccak256(initCode)).Bytes())
+		},
+		"isPrecompiled": func(call goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(precompiles.Contains(t.mustAddress(call.Argument(0))))
+		},
+		"slice": func(call goja.FunctionCall) goja.Value {
+			buf := t.mustBuf(call.Argument(0), false)
+			start, end := call.Argument(1).ToInteger(), call.Argument(2).ToInteger()
+
+			if start < 0 || start > end || end > int64(len(buf)) {
+				panic(t.vm.NewTypeError("slice out of bounds: %d-%d of %d", start, end, len(buf)))
+			}
+
+			return t.toBuf(buf[start:end])
+		},
+		"bigInt": func(call goja.FunctionCall) goja.Value {
+			arg := call.Argument(0)
+			if goja.IsUndefined(arg) {
+				return t.vm.ToValue(t.newBigInt(new(big.Int)))
+			}
+
+			if base := call.Argument(1); !goja.IsUndefined(base) {
+				v, ok := new(big.Int).SetString(arg.String(), int(base.ToInteger()))
+				if !ok {
+					panic(t.vm.NewTypeError("invalid big integer: %s", arg))
+				}
+
+				return t.vm.ToValue(t.newBigInt(v))
+			}
+
+			v, ok := t.toBig(arg)
+			if !ok {
+				panic(t.vm.NewTypeError("invalid big integer: %s", arg))
+			}
+
+			return t.vm.ToValue(t.newBigInt(new(big.Int).Set(v)))
+		},
+	}
+
+	for name, fn := range builtins {
+		if err := t.vm.Set(name, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newObject returns the object of the methods
+func (t *jsTracer) newObject(methods map[string]func(goja.FunctionCall) goja.Value) *goja.Object {
+	obj := t.vm.NewObject()
+
+	for name, fn := range methods {
+		_ = obj.Set(name, fn)
+	}
+
+	return obj
+}
+
+// newLogObject returns the log of the steps, reading the current step
+func (t *jsTracer) newLogObject() *goja.Object {
+	op := t.newObject(map[string]func(goja.FunctionCall) goja.Value{
+		"toNumber": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.opCode)
+		},
+		"toString": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(evm.OpCode(t.opCode).String())
+		},
+		"isPush": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.opCode >= evm.PUSH1 && t.opCode <= evm.PUSH32)
+		},
+	})
+
+	stack := t.newObject(map[string]func(goja.FunctionCall) goja.Value{
+		"peek": func(call goja.FunctionCall) goja.Value {
+			idx := int(call.Argument(0).ToInteger())
+			size := len(t.scope.Stack)
+
+			if idx < 0 || idx >= size {
+				panic(t.vm.NewTypeError("stack peek out of bounds: %d of %d", idx, size))
+			}
+
+			return t.vm.ToValue(t.newBigInt(new(big.Int).Set(t.scope.Stack[size-1-idx])))
+		},
+		"length": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(len(t.scope.Stack))
+		},
+	})
+
+	memory := t.newObject(map[string]func(goja.FunctionCall) goja.Value{
+		"slice": func(call goja.FunctionCall) goja.Value {
+			start, end := call.Argument(0).ToInteger(), call.Argument(1).ToInteger()
+
+			return t.toBuf(t.memorySlice(start, end))
+		},
+		"getUint": func(call goja.FunctionCall) goja.Value {
+			offset := call.Argument(0).ToInteger()
+
+			return t.vm.ToValue(t.newBigInt(new(big.Int).SetBytes(t.memorySlice(offset, offset+32))))
+		},
+		"length": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(len(t.scope.Memory))
+		},
+	})
+
+	contract := t.newObject(map[string]func(goja.FunctionCall) goja.Value{
+		"getCaller": func(goja.FunctionCall) goja.Value {
+			return t.toBuf(t.currentContract().caller.Bytes())
+		},
+		"getAddress": func(goja.FunctionCall) goja.Value {
+			return t.toBuf(t.scope.ContractAddress.Bytes())
+		},
+		"getValue": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.newBigInt(new(big.Int).Set(t.currentContract().value)))
+		},
+		"getInput": func(goja.FunctionCall) goja.Value {
+			return t.toBuf(t.currentContract().input)
+		},
+	})
+
+	log := t.newObject(map[string]func(goja.FunctionCall) goja.Value{
+		"getPC": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.pc)
+		},
+		"getGas": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.gas)
+		},
+		"getCost": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.cost)
+		},
+		"getDepth": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.depth)
+		},
+		"getRefund": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.txn.GetRefund())
+		},
+		"getError": func(goja.FunctionCall) goja.Value {
+			if t.stepErr == nil {
+				return goja.Undefined()
+			}
+
+			return t.vm.ToValue(t.stepErr.Error())
+		},
+	})
+
+	_ = log.Set("op", op)
+	_ = log.Set("stack", stack)
+	_ = log.Set("memory", memory)
+	_ = log.Set("contract", contract)
+
+	return log
+}
+
+// memorySlice returns a copy of the memory range, the memory not expanded yet is zero
+func (t *jsTracer) memorySlice(start, end int64) []byte {
+	if start < 0 || start > end || end-start > maxTracedMemory {
+		panic(t.vm.NewTypeError("memory slice out of bounds: %d-%d", start, end))
+	}
+
+	data := make([]byte, end-start)
+
+	if memory := t.scope.Memory; start < int64(len(memory)) {
+		copy(data, memory[start:])
+	}
+
+	return data
+}
+
+// maxTracedMemory bounds the memory slices of the tracers
+const maxTracedMemory = 1 << 24
+
+func (t *jsTracer) currentContract() *contractFrame {
+	if len(t.callstack) == 0 {
+		return &contractFrame{value: new(big.Int)}
+	}
+
+	return t.callstack[len(t.callstack)-1]
+}
+
+// newDBObject returns the state accessors of the db
+func (t *jsTracer) newDBObject() *goja.Object {
+	return t.newObject(map[string]func(goja.FunctionCall) goja.Value{
+		"getBalance": func(call goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.newBigInt(new(big.Int).Set(t.txn.GetBalance(t.mustAddress(call.Argument(0))))))
+		},
+		"getNonce": func(call goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.txn.GetNonce(t.mustAddress(call.Argument(0))))
+		},
+		"getCode": func(call goja.FunctionCall) goja.Value {
+			return t.toBuf(t.txn.GetCode(t.mustAddress(call.Argument(0))))
+		},
+		"getState": func(call goja.FunctionCall) goja.Value {
+			addr := t.mustAddress(call.Argument(0))
+			slot := types.BytesToHash(t.mustBuf(call.Argument(1), true))
+
+			value, err := t.txn.GetState(addr, slot)
+			if err != nil {
+				panic(t.vm.NewGoError(err))
+			}
+
+			return t.toBuf(value.Bytes())
+		},
+		"exists": func(call goja.FunctionCall) goja.Value {
+			addr := t.mustAddress(call.Argument(0))
+
+			if txn, ok := t.txn.(interface{ Exist(types.Address) bool }); ok {
+				return t.vm.ToValue(txn.Exist(addr))
+			}
+
+			return t.vm.ToValue(t.txn.GetNonce(addr) > 0 || t.txn.GetBalance(addr).Sign() > 0 ||
+				len(t.txn.GetCode(addr)) > 0)
+		},
+	})
+}
+
+// newFrameObject returns the call frame passed to enter
+func (t *jsTracer) newFrameObject() *goja.Object {
+	return t.newObject(map[string]func(goja.FunctionCall) goja.Value{
+		"getType": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.frame.typ)
+		},
+		"getFrom": func(goja.FunctionCall) goja.Value {
+			return t.toBuf(t.frame.from.Bytes())
+		},
+		"getTo": func(goja.FunctionCall) goja.Value {
+			return t.toBuf(t.frame.to.Bytes())
+		},
+		"getInput": func(goja.FunctionCall) goja.Value {
+			return t.toBuf(t.frame.input)
+		},
+		"getGas": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.frame.gas)
+		},
+		"getValue": func(goja.FunctionCall) goja.Value {
+			if t.frame.value == nil {
+				return goja.Undefined()
+			}
+
+			return t.vm.ToValue(t.newBigInt(new(big.Int).Set(t.frame.value)))
+		},
+	})
+}
+
+// newFrameResultObject returns the result of the call frame passed to exit
+func (t *jsTracer) newFrameResultObject() *goja.Object {
+	return t.newObject(map[string]func(goja.FunctionCall) goja.Value{
+		"getGasUsed": func(goja.FunctionCall) goja.Value {
+			return t.vm.ToValue(t.frameResult.gasUsed)
+		},
+		"getOutput": func(goja.FunctionCall) goja.Value {
+			return t.toBuf(t.frameResult.output)
+		},
+		"getError": func(goja.FunctionCall) goja.Value {
+			if t.frameResult.err == nil {
+				return goja.Undefined()
+			}
+
+			return t.vm.ToValue(t.frameResult.err.Error())
+		},
+	})
+}
+
+// isIdentifier returns whether the code is a bare name, rather than an object
+func isIdentifier(code string) bool {
+	if code == "" {
+		return false
+	}
+
+	for i, r := range code {
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+
+	return true
+}
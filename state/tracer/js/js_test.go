@@ -0,0 +1,218 @@
+package js
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/state"
+	itrie "github.com/dogechain-lab/dogechain/state/immutable-trie"
+	"github.com/dogechain-lab/dogechain/state/runtime/evm"
+	"github.com/dogechain-lab/dogechain/state/runtime/precompiled"
+	"github.com/dogechain-lab/dogechain/state/tracer"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	sender = types.StringToAddress("1000")
+	caller = types.StringToAddress("aa")
+	callee = types.StringToAddress("bb")
+
+	// calls the callee, and pops the result
+	callerCode = []byte{
+		0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, // PUSH1 0 x5
+		0x60, 0xbb, // PUSH1 callee
+		0x5a, // GAS
+		0xf1, // CALL
+		0x50, // POP
+		0x00, // STOP
+	}
+
+	// increases the slot 0
+	calleeCode = []byte{
+		0x60, 0x00, 0x54, // SLOAD 0
+		0x60, 0x01, 0x01, // ADD 1
+		0x60, 0x00, 0x55, // SSTORE 0
+		0x00, // STOP
+	}
+)
+
+// traceCall traces the transaction calling the caller contract with the tracer code
+func traceCall(t *testing.T, code string, cfg json.RawMessage) (json.RawMessage, error) {
+	t.Helper()
+
+	executor := state.NewExecutor(&chain.Params{
+		Forks:   chain.AllForksEnabled,
+		ChainID: 100,
+	}, itrie.NewStateDB(itrie.NewMemoryStorage(), hclog.NewNullLogger(), nil), hclog.NewNullLogger())
+	executor.SetRuntime(precompiled.NewPrecompiled())
+	executor.SetRuntime(evm.NewEVM())
+	executor.GetHash = func(*types.Header) func(uint64) types.Hash {
+		return func(uint64) types.Hash { return types.ZeroHash }
+	}
+
+	root, err := executor.WriteGenesis(map[types.Address]*chain.GenesisAccount{
+		sender: {Balance: big.NewInt(1000000000)},
+		caller: {Code: callerCode},
+		callee: {Code: calleeCode, Storage: map[types.Hash]types.Hash{
+			types.ZeroHash: types.BytesToHash([]byte{5}),
+		}},
+	})
+	assert.NoError(t, err)
+
+	txn, err := executor.BeginTxn(root, &types.Header{Number: 1, GasLimit: 10000000}, types.ZeroAddress)
+	assert.NoError(t, err)
+
+	tx := &types.Transaction{
+		From:     sender,
+		To:       &caller,
+		Gas:      100000,
+		GasPrice: big.NewInt(1),
+		Value:    big.NewInt(0),
+	}
+
+	tr, err := tracer.New(code, &tracer.Context{BlockNumber: 1, GasPrice: tx.GasPrice, GasLimit: tx.Gas}, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	txn.SetEVMLogger(tr)
+
+	result, err := txn.Apply(tx)
+	assert.NoError(t, err)
+	assert.False(t, result.Failed())
+
+	return tr.GetResult()
+}
+
+func TestJsTracer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		code   string
+		cfg    string
+		result string
+		err    bool
+	}{
+		{
+			name: "counts the steps",
+			code: `{count: 0, step: function() { this.count++ }, fault: function() {},
+				result: function() { return this.count }}`,
+			result: `17`,
+		},
+		{
+			name: "collects the opcodes",
+			code: `{ops: [], step: function(log) { if (log.getDepth() == 2) this.ops.push(log.op.toString()) },
+				fault: function() {}, result: function() { return this.ops }}`,
+			result: `["PUSH1","SLOAD","PUSH1","ADD","PUSH1","SSTORE","STOP"]`,
+		},
+		{
+			name: "reads the stack",
+			code: `{sum: bigInt(0), step: function(log) {
+					if (log.op.toString() == "ADD") this.sum = log.stack.peek(0).add(log.stack.peek(1))
+				}, fault: function() {}, result: function() { return this.sum }}`,
+			result: `"6"`,
+		},
+		{
+			name: "enters and exits the frames",
+			code: `{calls: [], fault: function() {},
+				enter: function(frame) { this.calls.push(frame.getType() + " " + toHex(frame.getTo())) },
+				exit: function(res) { this.calls.push(res.getGasUsed() > 0) },
+				result: function() { return this.calls }}`,
+			result: `["CALL 0x00000000000000000000000000000000000000bb",true]`,
+		},
+		{
+			name: "reads the context and the state",
+			code: `{fault: function() {}, result: function(ctx, db) {
+					return [ctx.type, toHex(ctx.from), ctx.block, ctx.gasPrice,
+						db.getState(toAddress("0xbb"), toWord("0x00")).length, db.exists(toAddress("0xbb"))]
+				}}`,
+			result: `["CALL","0x0000000000000000000000000000000000001000",1,"1",32,true]`,
+		},
+		{
+			name: "reads the config",
+			code: `{fault: function() {}, setup: function(cfg) { this.cfg = JSON.parse(cfg) },
+				result: function() { return this.cfg.k }}`,
+			cfg:    `{"k":"v"}`,
+			result: `"v"`,
+		},
+		{
+			name: "the big integers",
+			code: `{fault: function() {}, result: function() {
+					var x = bigInt("0x10")
+					return [x.multiply(3).toString(), x.pow(2).toString(16), x.subtract(17).isNegative(), x.eq(16)]
+				}}`,
+			result: `["48","100",true,true]`,
+		},
+		{
+			name: "throws in the step",
+			code: `{step: function() { throw "oops" }, fault: function() {}, result: function() { return 1 }}`,
+			err:  true,
+		},
+		{
+			name: "misses the result",
+			code: `{fault: function() {}}`,
+			err:  true,
+		},
+		{
+			name: "the enter without the exit",
+			code: `{enter: function() {}, fault: function() {}, result: function() {}}`,
+			err:  true,
+		},
+		{
+			name: "invalid code",
+			code: `{result: function() {`,
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cfg json.RawMessage
+			if test.cfg != "" {
+				cfg = json.RawMessage(test.cfg)
+			}
+
+			res, err := traceCall(t, test.code, cfg)
+			if test.err {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.JSONEq(t, test.result, string(res))
+		})
+	}
+}
+
+func TestJsTracer_NotFound(t *testing.T) {
+	t.Parallel()
+
+	_, err := newJsTracer("unknownTracer", &tracer.Context{}, nil)
+	assert.ErrorIs(t, err, tracer.ErrTracerNotFound)
+}
+
+func TestJsTracer_Stop(t *testing.T) {
+	t.Parallel()
+
+	tr, err := newJsTracer(`{step: function() {}, fault: function() {}, result: function() { for (;;) {} }}`,
+		&tracer.Context{}, nil)
+	assert.NoError(t, err)
+
+	stopErr := errors.New("execution timeout")
+
+	tr.Stop(stopErr)
+
+	_, err = tr.GetResult()
+	assert.ErrorIs(t, err, stopErr)
+}
@@ -0,0 +1,154 @@
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/state/runtime"
+	"github.com/dogechain-lab/dogechain/state/runtime/evm"
+	"github.com/dogechain-lab/dogechain/state/runtime/precompiled"
+	"github.com/dogechain-lab/dogechain/state/tracer"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// accessTuple is the account and its storage slots of the access list
+type accessTuple struct {
+	Address     types.Address `json:"address"`
+	StorageKeys []types.Hash  `json:"storageKeys"`
+}
+
+// accessListTracer collects the accounts and the storage slots the transaction
+// touches, in the order they are first touched. The sender, the recipient and the
+// precompiled contracts are warm anyway, so they are left out of the list.
+type accessListTracer struct {
+	list     []*accessTuple
+	index    map[types.Address]*accessTuple
+	slots    map[types.Address]map[types.Hash]struct{}
+	excluded map[types.Address]struct{}
+
+	precompiled *precompiled.Precompiled
+
+	interrupt atomic.Bool
+	reason    error // the reason the trace is stopped
+}
+
+func newAccessListTracer(_ *tracer.Context, _ json.RawMessage) (tracer.Tracer, error) {
+	return &accessListTracer{
+		list:        []*accessTuple{},
+		index:       make(map[types.Address]*accessTuple),
+		slots:       make(map[types.Address]map[types.Hash]struct{}),
+		excluded:    make(map[types.Address]struct{}),
+		precompiled: precompiled.NewPrecompiled(),
+	}, nil
+}
+
+func (t *accessListTracer) CaptureStart(txn runtime.Txn, from, to types.Address,
+	create bool, input []byte, gas uint64, value *big.Int) {
+	t.excluded[from] = struct{}{}
+	t.excluded[to] = struct{}{}
+}
+
+func (t *accessListTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+}
+
+func (t *accessListTracer) CaptureEnter(opCode int, from, to types.Address,
+	input []byte, gas uint64, value *big.Int) {
+}
+
+func (t *accessListTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+}
+
+// CaptureOpcode records the account or the storage slot the opcode touches
+func (t *accessListTracer) CaptureOpcode(ctx *runtime.ScopeContext, pc uint64, opCode int, depth int) {
+	if t.interrupt.Load() {
+		return
+	}
+
+	stack := ctx.Stack
+	size := len(stack)
+
+	switch opCode {
+	case evm.SLOAD, evm.SSTORE:
+		if size >= 1 {
+			t.addSlot(ctx.ContractAddress, types.BytesToHash(stack[size-1].Bytes()))
+		}
+	case evm.BALANCE, evm.EXTCODESIZE, evm.EXTCODECOPY, evm.EXTCODEHASH, evm.SELFDESTRUCT:
+		if size >= 1 {
+			t.addAddress(types.BytesToAddress(stack[size-1].Bytes()))
+		}
+	case evm.CALL, evm.CALLCODE, evm.DELEGATECALL, evm.STATICCALL:
+		if size >= 2 {
+			t.addAddress(types.BytesToAddress(stack[size-2].Bytes()))
+		}
+	}
+}
+
+func (t *accessListTracer) CaptureState(ctx *runtime.ScopeContext, pc uint64, opCode int,
+	gas, cost uint64, rData []byte, depth int, err error) {
+}
+
+func (t *accessListTracer) CaptureFault(ctx *runtime.ScopeContext, pc uint64, opCode int,
+	gas, cost uint64, depth int, err error) {
+}
+
+// GetResult returns the access list of the transaction
+func (t *accessListTracer) GetResult() (json.RawMessage, error) {
+	if t.interrupt.Load() {
+		return nil, t.reason
+	}
+
+	return json.Marshal(t.list)
+}
+
+// Stop terminates the trace, the result is discarded
+func (t *accessListTracer) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
+
+// addAddress records the account the first time it is touched
+func (t *accessListTracer) addAddress(addr types.Address) {
+	if _, ok := t.excluded[addr]; ok || t.precompiled.Contains(addr) {
+		return
+	}
+
+	t.tuple(addr)
+}
+
+// addSlot records the storage slot the first time it is touched. The slots of the
+// excluded accounts are recorded too, as only the accounts themselves are warm.
+func (t *accessListTracer) addSlot(addr types.Address, slot types.Hash) {
+	tuple := t.tuple(addr)
+
+	slots, ok := t.slots[addr]
+	if !ok {
+		slots = make(map[types.Hash]struct{})
+		t.slots[addr] = slots
+	}
+
+	if _, ok := slots[slot]; ok {
+		return
+	}
+
+	slots[slot] = struct{}{}
+	tuple.StorageKeys = append(tuple.StorageKeys, slot)
+}
+
+// tuple returns the entry of the account, which is appended to the list if missing
+func (t *accessListTracer) tuple(addr types.Address) *accessTuple {
+	if tuple, ok := t.index[addr]; ok {
+		return tuple
+	}
+
+	tuple := &accessTuple{
+		Address:     addr,
+		StorageKeys: []types.Hash{},
+	}
+
+	t.list = append(t.list, tuple)
+	t.index[addr] = tuple
+
+	return tuple
+}
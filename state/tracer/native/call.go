@@ -0,0 +1,233 @@
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/state/runtime"
+	"github.com/dogechain-lab/dogechain/state/runtime/evm"
+	"github.com/dogechain-lab/dogechain/state/tracer"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// callLog is a log emitted within a call frame
+type callLog struct {
+	Address types.Address `json:"address"`
+	Topics  []types.Hash  `json:"topics"`
+	Data    string        `json:"data"`
+}
+
+// callFrame is a call or contract creation of the transaction, with the calls it made
+type callFrame struct {
+	Type    string        `json:"type"`
+	From    types.Address `json:"from"`
+	To      types.Address `json:"to"`
+	Value   string        `json:"value,omitempty"`
+	Gas     string        `json:"gas"`
+	GasUsed string        `json:"gasUsed"`
+	Input   string        `json:"input"`
+	Output  string        `json:"output,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Calls   []*callFrame  `json:"calls,omitempty"`
+	Logs    []*callLog    `json:"logs,omitempty"`
+}
+
+// exit sets the result of the frame
+func (f *callFrame) exit(output []byte, gasUsed uint64, err error) {
+	f.GasUsed = hex.EncodeUint64(gasUsed)
+
+	if err != nil {
+		f.Error = err.Error()
+	}
+
+	// the output of the failed frames is kept for the revert reason only
+	if len(output) > 0 && (err == nil || errors.Is(err, runtime.ErrExecutionReverted)) {
+		f.Output = hex.EncodeToHex(output)
+	}
+}
+
+// clearFailedLogs drops the logs of the failed frames, which are reverted
+func (f *callFrame) clearFailedLogs(parentFailed bool) {
+	failed := parentFailed || f.Error != ""
+	if failed {
+		f.Logs = nil
+	}
+
+	for _, call := range f.Calls {
+		call.clearFailedLogs(failed)
+	}
+}
+
+type callTracerConfig struct {
+	OnlyTopCall bool `json:"onlyTopCall"` // the top level call only, without the inner calls
+	WithLog     bool `json:"withLog"`     // the logs emitted, by the frames emitting them
+}
+
+// callTracer traces the calls of the transaction in a tree, the inner calls,
+// contract creations and self destructs included
+type callTracer struct {
+	config    callTracerConfig
+	txn       runtime.Txn
+	callstack []*callFrame
+
+	interrupt atomic.Bool
+	reason    error // the reason the trace is stopped
+}
+
+func newCallTracer(ctx *tracer.Context, cfg json.RawMessage) (tracer.Tracer, error) {
+	var config callTracerConfig
+
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, fmt.Errorf("invalid callTracer config: %w", err)
+		}
+	}
+
+	return &callTracer{config: config}, nil
+}
+
+func (t *callTracer) CaptureStart(txn runtime.Txn, from, to types.Address,
+	create bool, input []byte, gas uint64, value *big.Int) {
+	t.txn = txn
+
+	typ := evm.OpCode(evm.CALL)
+	if create {
+		typ = evm.CREATE
+	}
+
+	t.callstack = []*callFrame{newCallFrame(typ, from, to, input, gas, value)}
+}
+
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	if len(t.callstack) == 0 {
+		return
+	}
+
+	t.callstack[0].exit(output, gasUsed, err)
+}
+
+func (t *callTracer) CaptureEnter(opCode int, from, to types.Address,
+	input []byte, gas uint64, value *big.Int) {
+	if t.config.OnlyTopCall || t.interrupt.Load() {
+		return
+	}
+
+	t.callstack = append(t.callstack, newCallFrame(evm.OpCode(opCode), from, to, input, gas, value))
+}
+
+func (t *callTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if t.config.OnlyTopCall {
+		return
+	}
+
+	size := len(t.callstack)
+	if size <= 1 {
+		return
+	}
+
+	call := t.callstack[size-1]
+	t.callstack = t.callstack[:size-1]
+
+	call.exit(output, gasUsed, err)
+
+	parent := t.callstack[size-2]
+	parent.Calls = append(parent.Calls, call)
+}
+
+// CaptureOpcode captures the self destructs and the logs, which are not calls
+func (t *callTracer) CaptureOpcode(ctx *runtime.ScopeContext, pc uint64, opCode int, depth int) {
+	if t.interrupt.Load() || len(t.callstack) == 0 {
+		return
+	}
+
+	stack := ctx.Stack
+	size := len(stack)
+	frame := t.callstack[len(t.callstack)-1]
+
+	switch {
+	case opCode == evm.SELFDESTRUCT && !t.config.OnlyTopCall:
+		if size < 1 {
+			return
+		}
+
+		beneficiary := types.BytesToAddress(stack[size-1].Bytes())
+		balance := t.txn.GetBalance(ctx.ContractAddress)
+
+		call := newCallFrame(evm.SELFDESTRUCT, ctx.ContractAddress, beneficiary, nil, 0, balance)
+		call.GasUsed = hex.EncodeUint64(0)
+
+		frame.Calls = append(frame.Calls, call)
+	case opCode >= evm.LOG0 && opCode <= evm.LOG4 && t.config.WithLog:
+		topicCount := opCode - evm.LOG0
+		if size < 2+topicCount {
+			return
+		}
+
+		data, ok := memoryCopy(ctx.Memory, stack[size-1], stack[size-2])
+		if !ok {
+			return
+		}
+
+		topics := make([]types.Hash, topicCount)
+		for i := range topics {
+			topics[i] = types.BytesToHash(stack[size-3-i].Bytes())
+		}
+
+		frame.Logs = append(frame.Logs, &callLog{
+			Address: ctx.ContractAddress,
+			Topics:  topics,
+			Data:    hex.EncodeToHex(data),
+		})
+	}
+}
+
+func (t *callTracer) CaptureState(ctx *runtime.ScopeContext, pc uint64, opCode int,
+	gas, cost uint64, rData []byte, depth int, err error) {
+}
+
+func (t *callTracer) CaptureFault(ctx *runtime.ScopeContext, pc uint64, opCode int,
+	gas, cost uint64, depth int, err error) {
+}
+
+// GetResult returns the call tree of the transaction
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	if t.interrupt.Load() {
+		return nil, t.reason
+	}
+
+	if len(t.callstack) != 1 {
+		return nil, errors.New("incorrect number of top-level calls")
+	}
+
+	t.callstack[0].clearFailedLogs(false)
+
+	return json.Marshal(t.callstack[0])
+}
+
+// Stop terminates the trace, the result is discarded
+func (t *callTracer) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
+
+func newCallFrame(typ evm.OpCode, from, to types.Address, input []byte, gas uint64, value *big.Int) *callFrame {
+	frame := &callFrame{
+		Type:  typ.String(),
+		From:  from,
+		To:    to,
+		Gas:   hex.EncodeUint64(gas),
+		Input: hex.EncodeToHex(input),
+	}
+
+	// the delegate and static calls transfer no value
+	if value != nil && typ != evm.DELEGATECALL && typ != evm.STATICCALL {
+		frame.Value = hex.EncodeBig(value)
+	}
+
+	return frame
+}
@@ -0,0 +1,62 @@
+// Package native implements the built-in tracers of the debug_trace* calls in Go,
+// they are looked up by their names.
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/dogechain-lab/dogechain/state/tracer"
+)
+
+type ctorFn func(*tracer.Context, json.RawMessage) (tracer.Tracer, error)
+
+// ctors are the constructors of the built-in tracers by name
+var ctors = map[string]ctorFn{
+	"accessListTracer": newAccessListTracer,
+	"callTracer":       newCallTracer,
+	"prestateTracer":   newPrestateTracer,
+}
+
+func init() {
+	tracer.RegisterLookup(false, lookup)
+}
+
+// lookup returns the built-in tracer of the name
+func lookup(name string, ctx *tracer.Context, cfg json.RawMessage) (tracer.Tracer, error) {
+	ctor, ok := ctors[name]
+	if !ok {
+		return nil, tracer.ErrTracerNotFound
+	}
+
+	return ctor(ctx, cfg)
+}
+
+// maxTracedMemory bounds the memory read by the tracers before the opcodes expanding
+// it. The opcodes expanding the memory beyond it run out of gas in any block.
+const maxTracedMemory = 1 << 24
+
+// memoryCopy returns a copy of the memory range the opcode is about to read. The
+// memory not expanded yet is zero.
+func memoryCopy(memory []byte, offset, size *big.Int) ([]byte, bool) {
+	if !offset.IsUint64() || !size.IsUint64() {
+		return nil, false
+	}
+
+	start, length := offset.Uint64(), size.Uint64()
+	if length == 0 {
+		return []byte{}, true
+	}
+
+	if start+length < start || start+length > maxTracedMemory {
+		return nil, false
+	}
+
+	data := make([]byte, length)
+
+	if start < uint64(len(memory)) {
+		copy(data, memory[start:])
+	}
+
+	return data, true
+}
@@ -0,0 +1,157 @@
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/state"
+	itrie "github.com/dogechain-lab/dogechain/state/immutable-trie"
+	"github.com/dogechain-lab/dogechain/state/runtime/evm"
+	"github.com/dogechain-lab/dogechain/state/runtime/precompiled"
+	"github.com/dogechain-lab/dogechain/state/tracer"
+	"github.com/dogechain-lab/dogechain/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	sender = types.StringToAddress("1000")
+	caller = types.StringToAddress("aa")
+	callee = types.StringToAddress("bb")
+
+	senderBalance = big.NewInt(1000000000)
+
+	// calls the callee, and pops the result
+	callerCode = []byte{
+		0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, // PUSH1 0 x5
+		0x60, 0xbb, // PUSH1 callee
+		0x5a, // GAS
+		0xf1, // CALL
+		0x50, // POP
+		0x00, // STOP
+	}
+
+	// increases the slot 0, and emits a log of the topic 1
+	calleeCode = []byte{
+		0x60, 0x00, 0x54, // SLOAD 0
+		0x60, 0x01, 0x01, // ADD 1
+		0x60, 0x00, 0x55, // SSTORE 0
+		0x60, 0x01, 0x60, 0x00, 0x60, 0x00, // PUSH1 1, PUSH1 0, PUSH1 0
+		0xa1, // LOG1
+		0x00, // STOP
+	}
+)
+
+// traceCall traces the transaction calling the caller contract with the named tracer
+func traceCall(t *testing.T, name string, cfg json.RawMessage) json.RawMessage {
+	t.Helper()
+
+	executor := state.NewExecutor(&chain.Params{
+		Forks:   chain.AllForksEnabled,
+		ChainID: 100,
+	}, itrie.NewStateDB(itrie.NewMemoryStorage(), hclog.NewNullLogger(), nil), hclog.NewNullLogger())
+	executor.SetRuntime(precompiled.NewPrecompiled())
+	executor.SetRuntime(evm.NewEVM())
+	executor.GetHash = func(*types.Header) func(uint64) types.Hash {
+		return func(uint64) types.Hash { return types.ZeroHash }
+	}
+
+	root, err := executor.WriteGenesis(map[types.Address]*chain.GenesisAccount{
+		sender: {Balance: senderBalance},
+		caller: {Code: callerCode},
+		callee: {Code: calleeCode, Storage: map[types.Hash]types.Hash{
+			types.ZeroHash: types.BytesToHash([]byte{5}),
+		}},
+	})
+	assert.NoError(t, err)
+
+	txn, err := executor.BeginTxn(root, &types.Header{Number: 1, GasLimit: 10000000}, types.ZeroAddress)
+	assert.NoError(t, err)
+
+	tx := &types.Transaction{
+		From:     sender,
+		To:       &caller,
+		Gas:      100000,
+		GasPrice: big.NewInt(1),
+		Value:    big.NewInt(0),
+	}
+
+	tr, err := tracer.New(name, &tracer.Context{GasPrice: tx.GasPrice, GasLimit: tx.Gas}, cfg)
+	assert.NoError(t, err)
+
+	txn.SetEVMLogger(tr)
+
+	result, err := txn.Apply(tx)
+	assert.NoError(t, err)
+	assert.False(t, result.Failed())
+
+	res, err := tr.GetResult()
+	assert.NoError(t, err)
+
+	return res
+}
+
+func TestCallTracer(t *testing.T) {
+	var frame callFrame
+
+	assert.NoError(t, json.Unmarshal(traceCall(t, "callTracer", json.RawMessage(`{"withLog":true}`)), &frame))
+
+	assert.Equal(t, "CALL", frame.Type)
+	assert.Equal(t, sender, frame.From)
+	assert.Equal(t, caller, frame.To)
+	assert.Empty(t, frame.Error)
+	assert.Len(t, frame.Calls, 1)
+
+	inner := frame.Calls[0]
+	assert.Equal(t, "CALL", inner.Type)
+	assert.Equal(t, caller, inner.From)
+	assert.Equal(t, callee, inner.To)
+	assert.NotEqual(t, "0x0", inner.GasUsed)
+	assert.Len(t, inner.Logs, 1)
+	assert.Equal(t, callee, inner.Logs[0].Address)
+	assert.Equal(t, []types.Hash{types.BytesToHash([]byte{1})}, inner.Logs[0].Topics)
+
+	// the top level call only
+	frame = callFrame{}
+
+	assert.NoError(t, json.Unmarshal(traceCall(t, "callTracer", json.RawMessage(`{"onlyTopCall":true}`)), &frame))
+	assert.Equal(t, caller, frame.To)
+	assert.Empty(t, frame.Calls)
+}
+
+func TestPrestateTracer(t *testing.T) {
+	var pre map[types.Address]*prestateAccount
+
+	assert.NoError(t, json.Unmarshal(traceCall(t, "prestateTracer", nil), &pre))
+
+	// the state before the gas is bought, the nonce increased and the slot written
+	assert.Equal(t, hex.EncodeBig(senderBalance), pre[sender].Balance)
+	assert.Zero(t, pre[sender].Nonce)
+	assert.Equal(t, hex.EncodeToHex(callerCode), pre[caller].Code)
+	assert.Equal(t, map[types.Hash]types.Hash{
+		types.ZeroHash: types.BytesToHash([]byte{5}),
+	}, pre[callee].Storage)
+}
+
+func TestAccessListTracer(t *testing.T) {
+	var list []*accessTuple
+
+	assert.NoError(t, json.Unmarshal(traceCall(t, "accessListTracer", nil), &list))
+
+	// the sender and the recipient are left out
+	assert.Equal(t, []*accessTuple{
+		{Address: callee, StorageKeys: []types.Hash{types.ZeroHash}},
+	}, list)
+}
+
+func TestLookup(t *testing.T) {
+	_, err := tracer.New("unknownTracer", &tracer.Context{}, nil)
+	assert.ErrorIs(t, err, tracer.ErrTracerNotFound)
+
+	_, err = tracer.New("callTracer", &tracer.Context{}, json.RawMessage(`{"onlyTopCall":1}`))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, tracer.ErrTracerNotFound)
+}
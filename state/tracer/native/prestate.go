@@ -0,0 +1,173 @@
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/dogechain-lab/dogechain/crypto"
+	"github.com/dogechain-lab/dogechain/helper/hex"
+	"github.com/dogechain-lab/dogechain/state/runtime"
+	"github.com/dogechain-lab/dogechain/state/runtime/evm"
+	"github.com/dogechain-lab/dogechain/state/tracer"
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// prestateAccount is the state of an account before the transaction, the storage
+// slots touched by the transaction only
+type prestateAccount struct {
+	Balance string                    `json:"balance"`
+	Nonce   uint64                    `json:"nonce,omitempty"`
+	Code    string                    `json:"code,omitempty"`
+	Storage map[types.Hash]types.Hash `json:"storage,omitempty"`
+}
+
+// prestateTracer traces the state the transaction touches, as it is before the
+// transaction, so that the transaction could be replayed on it
+type prestateTracer struct {
+	ctx *tracer.Context
+	txn runtime.Txn
+	pre map[types.Address]*prestateAccount
+
+	interrupt atomic.Bool
+	reason    error // the reason the trace is stopped
+}
+
+func newPrestateTracer(ctx *tracer.Context, _ json.RawMessage) (tracer.Tracer, error) {
+	return &prestateTracer{
+		ctx: ctx,
+		pre: make(map[types.Address]*prestateAccount),
+	}, nil
+}
+
+func (t *prestateTracer) CaptureStart(txn runtime.Txn, from, to types.Address,
+	create bool, input []byte, gas uint64, value *big.Int) {
+	t.txn = txn
+
+	t.lookupAccount(from)
+	t.lookupAccount(to)
+
+	// the upfront gas is bought, and the nonce increased before the trace starts
+	sender := t.pre[from]
+
+	if t.ctx != nil && t.ctx.GasPrice != nil {
+		upfront := new(big.Int).Mul(t.ctx.GasPrice, new(big.Int).SetUint64(t.ctx.GasLimit))
+
+		sender.Balance = hex.EncodeBig(upfront.Add(upfront, t.txn.GetBalance(from)))
+	}
+
+	if sender.Nonce > 0 {
+		sender.Nonce--
+	}
+}
+
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+}
+
+// CaptureEnter looks up the callee, before the value is transferred
+func (t *prestateTracer) CaptureEnter(opCode int, from, to types.Address,
+	input []byte, gas uint64, value *big.Int) {
+	if t.interrupt.Load() {
+		return
+	}
+
+	t.lookupAccount(to)
+}
+
+func (t *prestateTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+}
+
+// CaptureOpcode looks up the state the opcode touches, before it is changed
+func (t *prestateTracer) CaptureOpcode(ctx *runtime.ScopeContext, pc uint64, opCode int, depth int) {
+	if t.interrupt.Load() || t.txn == nil {
+		return
+	}
+
+	stack := ctx.Stack
+	size := len(stack)
+	caller := ctx.ContractAddress
+
+	switch opCode {
+	case evm.SLOAD, evm.SSTORE:
+		if size >= 1 {
+			t.lookupStorage(caller, types.BytesToHash(stack[size-1].Bytes()))
+		}
+	case evm.BALANCE, evm.EXTCODESIZE, evm.EXTCODECOPY, evm.EXTCODEHASH, evm.SELFDESTRUCT:
+		if size >= 1 {
+			t.lookupAccount(types.BytesToAddress(stack[size-1].Bytes()))
+		}
+	case evm.CREATE:
+		t.lookupAccount(crypto.CreateAddress(caller, t.txn.GetNonce(caller)))
+	case evm.CREATE2:
+		if size < 4 {
+			return
+		}
+
+		code, ok := memoryCopy(ctx.Memory, stack[size-2], stack[size-3])
+		if !ok {
+			return
+		}
+
+		t.lookupAccount(crypto.CreateAddress2(caller, types.BytesToHash(stack[size-4].Bytes()), code))
+	}
+}
+
+func (t *prestateTracer) CaptureState(ctx *runtime.ScopeContext, pc uint64, opCode int,
+	gas, cost uint64, rData []byte, depth int, err error) {
+}
+
+func (t *prestateTracer) CaptureFault(ctx *runtime.ScopeContext, pc uint64, opCode int,
+	gas, cost uint64, depth int, err error) {
+}
+
+// GetResult returns the prestate of the accounts the transaction touches
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	if t.interrupt.Load() {
+		return nil, t.reason
+	}
+
+	return json.Marshal(t.pre)
+}
+
+// Stop terminates the trace, the result is discarded
+func (t *prestateTracer) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
+
+// lookupAccount records the account the first time it is touched
+func (t *prestateTracer) lookupAccount(addr types.Address) {
+	if _, ok := t.pre[addr]; ok {
+		return
+	}
+
+	account := &prestateAccount{
+		Balance: hex.EncodeBig(t.txn.GetBalance(addr)),
+		Nonce:   t.txn.GetNonce(addr),
+	}
+
+	if code := t.txn.GetCode(addr); len(code) > 0 {
+		account.Code = hex.EncodeToHex(code)
+	}
+
+	t.pre[addr] = account
+}
+
+// lookupStorage records the storage slot the first time it is touched
+func (t *prestateTracer) lookupStorage(addr types.Address, slot types.Hash) {
+	t.lookupAccount(addr)
+
+	account := t.pre[addr]
+	if account.Storage == nil {
+		account.Storage = make(map[types.Hash]types.Hash)
+	}
+
+	if _, ok := account.Storage[slot]; ok {
+		return
+	}
+
+	// the state is looked up in the trace, the error would fail the opcode as well
+	value, _ := t.txn.GetState(addr, slot)
+	account.Storage[slot] = value
+}
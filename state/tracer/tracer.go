@@ -3,6 +3,7 @@ package tracer
 import (
 	"encoding/json"
 	"errors"
+	"math/big"
 
 	"github.com/dogechain-lab/dogechain/state/runtime"
 	"github.com/dogechain-lab/dogechain/types"
@@ -11,9 +12,12 @@ import (
 // Context contains some contextual infos for a transaction execution that is not
 // available from within the EVM object.
 type Context struct {
-	BlockHash types.Hash // Hash of the block the tx is contained within (zero if dangling tx or call)
-	TxIndex   int        // Index of the transaction within a block (zero if dangling tx or call)
-	TxHash    types.Hash // Hash of the transaction being traced (zero if dangling call)
+	BlockHash   types.Hash // Hash of the block the tx is contained within (zero if dangling tx or call)
+	BlockNumber uint64     // Number of the block the tx is contained within (zero if dangling tx or call)
+	TxIndex     int        // Index of the transaction within a block (zero if dangling tx or call)
+	TxHash      types.Hash // Hash of the transaction being traced (zero if dangling call)
+	GasPrice    *big.Int   // Gas price of the transaction, the upfront gas is bought before the trace starts
+	GasLimit    uint64     // Gas limit of the transaction
 }
 
 // Tracer interface extends evm.EVMLogger and additionally
@@ -25,7 +29,7 @@ type Tracer interface {
 	Stop(err error)
 }
 
-type lookupFunc func(string, *Context) (Tracer, error)
+type lookupFunc func(string, *Context, json.RawMessage) (Tracer, error)
 
 var (
 	lookups []lookupFunc
@@ -43,14 +47,22 @@ func RegisterLookup(wildcard bool, lookup lookupFunc) {
 	}
 }
 
+var ErrTracerNotFound = errors.New("tracer not found")
+
 // New returns a new instance of a tracer, by iterating through the
-// registered lookups.
-func New(code string, ctx *Context) (Tracer, error) {
+// registered lookups. The cfg is the tracer specific config, if any. The lookups
+// return ErrTracerNotFound for the tracers they do not know.
+func New(code string, ctx *Context, cfg json.RawMessage) (Tracer, error) {
 	for _, lookup := range lookups {
-		if tracer, err := lookup(code, ctx); err == nil {
+		tracer, err := lookup(code, ctx, cfg)
+		if err == nil {
 			return tracer, nil
 		}
+
+		if !errors.Is(err, ErrTracerNotFound) {
+			return nil, err
+		}
 	}
 
-	return nil, errors.New("tracer not found")
+	return nil, ErrTracerNotFound
 }
@@ -33,6 +33,8 @@ type Txn struct {
 	snapshot  snapshotReader
 	snapshots []*iradix.Tree
 	txn       *iradix.Txn
+
+	counter *AccessCounter // state access counter, nil if not counted
 }
 
 func NewTxn(snapshot Snapshot) *Txn {
@@ -95,6 +97,8 @@ func (txn *Txn) getStateObject(addr types.Address) (*StateObject, bool) {
 		return obj.Copy(), true
 	}
 
+	txn.counter.accountRead()
+
 	account, err := txn.snapshot.GetAccount(addr)
 	if err != nil {
 		return nil, false
@@ -339,6 +343,8 @@ func (txn *Txn) GetState(addr types.Address, slot types.Hash) (types.Hash, error
 		}
 	}
 
+	txn.counter.storageRead()
+
 	// get it from storage
 	return txn.snapshot.GetStorage(addr, object.Account.Root, slot)
 }
@@ -428,6 +428,24 @@ func (a *account) promote() (
 	return
 }
 
+// allTxs returns the copies of the promoted and the enqueued transactions, sorted by
+// the nonce
+func (a *account) allTxs() (promoted, enqueued []*types.Transaction) {
+	a.promoted.lock(false)
+	defer a.promoted.unlock()
+
+	a.enqueued.lock(false)
+	defer a.enqueued.unlock()
+
+	promoted = append([]*types.Transaction{}, a.promoted.Transactions()...)
+	enqueued = append([]*types.Transaction{}, a.enqueued.Transactions()...)
+
+	sort.Stable(types.PoolTxByNonce(promoted))
+	sort.Stable(types.PoolTxByNonce(enqueued))
+
+	return
+}
+
 // updatePromoted updates promoted timestamp
 func (a *account) updatePromoted() {
 	a.lastPromoted = time.Now()
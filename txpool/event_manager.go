@@ -5,12 +5,22 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/dogechain-lab/dogechain/helper/recovery"
 	"github.com/dogechain-lab/dogechain/txpool/proto"
 	"github.com/dogechain-lab/dogechain/types"
 	"github.com/google/uuid"
 	"github.com/hashicorp/go-hclog"
 )
 
+// reasons of the dropped, demoted and pruned transactions
+const (
+	eventReasonUnexecutable   = "failed to execute when building block"
+	eventReasonAccountDropped = "dropped with an unexecutable transaction of the account"
+	eventReasonNonceReset     = "account nonce reset"
+	eventReasonNonceTooLow    = "nonce too low"
+	eventReasonOutdated       = "outdated in the enqueued queue"
+)
+
 type eventManager struct {
 	subscriptions     map[subscriptionID]*eventSubscription
 	subscriptionsLock sync.RWMutex
@@ -49,7 +59,7 @@ func (em *eventManager) subscribe(eventTypes []proto.EventType) *subscribeResult
 
 	em.subscriptions[subscriptionID(id)] = subscription
 
-	go subscription.runLoop()
+	recovery.Go(em.logger, "txpool event subscription", subscription.runLoop)
 
 	em.logger.Info(fmt.Sprintf("Added new subscription %d", id))
 	atomic.AddInt64(&em.numSubscriptions, 1)
@@ -93,15 +103,68 @@ func (em *eventManager) signalEvent(eventType proto.EventType, txHashes ...types
 		return
 	}
 
+	events := make([]*proto.TxPoolEvent, 0, len(txHashes))
+
+	for _, txHash := range txHashes {
+		events = append(events, &proto.TxPoolEvent{
+			Type:   eventType,
+			TxHash: txHash.String(),
+		})
+	}
+
+	em.pushEvents(events)
+}
+
+// signalTxEvent alerts listeners of the TxPool event of the transactions, with
+// the sender, nonce and the reason if any
+func (em *eventManager) signalTxEvent(eventType proto.EventType, reason string, txs ...*types.Transaction) {
+	if atomic.LoadInt64(&em.numSubscriptions) < 1 {
+		return
+	}
+
+	events := make([]*proto.TxPoolEvent, 0, len(txs))
+
+	for _, tx := range txs {
+		event := &proto.TxPoolEvent{
+			Type:   eventType,
+			TxHash: tx.Hash().String(),
+			Nonce:  tx.Nonce,
+			Reason: reason,
+		}
+
+		// the sender of the rejected transaction might be unknown
+		if tx.From != types.ZeroAddress {
+			event.From = tx.From.String()
+		}
+
+		events = append(events, event)
+	}
+
+	em.pushEvents(events)
+}
+
+// signalReplacedEvent alerts listeners of the transaction replaced by another one
+func (em *eventManager) signalReplacedEvent(replaced, by *types.Transaction) {
+	if atomic.LoadInt64(&em.numSubscriptions) < 1 {
+		return
+	}
+
+	em.pushEvents([]*proto.TxPoolEvent{{
+		Type:       proto.EventType_REPLACED,
+		TxHash:     replaced.Hash().String(),
+		From:       replaced.From.String(),
+		Nonce:      replaced.Nonce,
+		ReplacedBy: by.Hash().String(),
+	}})
+}
+
+func (em *eventManager) pushEvents(events []*proto.TxPoolEvent) {
 	em.subscriptionsLock.RLock()
 	defer em.subscriptionsLock.RUnlock()
 
-	for _, txHash := range txHashes {
+	for _, event := range events {
 		for _, subscription := range em.subscriptions {
-			subscription.pushEvent(&proto.TxPoolEvent{
-				Type:   eventType,
-				TxHash: txHash.String(),
-			})
+			subscription.pushEvent(event)
 		}
 	}
 }
@@ -198,3 +198,67 @@ func TestEventManager_SignalEventOrder(t *testing.T) {
 
 	assert.Equal(t, totalEvents, eventsProcessed)
 }
+
+func TestEventManager_SignalTxEvent(t *testing.T) {
+	em := newEventManager(hclog.NewNullLogger())
+	defer em.Close()
+
+	subscription := em.subscribe([]proto.EventType{
+		proto.EventType_DROPPED,
+		proto.EventType_REPLACED,
+		proto.EventType_REJECTED,
+	})
+
+	sender := types.StringToAddress("0x1")
+	replaced := &types.Transaction{Nonce: 1, From: sender, Gas: 1}
+	replacing := &types.Transaction{Nonce: 1, From: sender, Gas: 2}
+
+	em.signalTxEvent(proto.EventType_DROPPED, eventReasonUnexecutable, replaced)
+	em.signalReplacedEvent(replaced, replacing)
+	// the sender of the rejected transaction is unknown
+	em.signalTxEvent(proto.EventType_REJECTED, ErrIntrinsicGas.Error(), &types.Transaction{})
+	// not subscribed
+	em.signalTxEvent(proto.EventType_ADDED, "", replacing)
+
+	expected := []*proto.TxPoolEvent{
+		{
+			Type:   proto.EventType_DROPPED,
+			TxHash: replaced.Hash().String(),
+			From:   sender.String(),
+			Nonce:  1,
+			Reason: eventReasonUnexecutable,
+		},
+		{
+			Type:       proto.EventType_REPLACED,
+			TxHash:     replaced.Hash().String(),
+			From:       sender.String(),
+			Nonce:      1,
+			ReplacedBy: replacing.Hash().String(),
+		},
+		{
+			Type:   proto.EventType_REJECTED,
+			TxHash: (&types.Transaction{}).Hash().String(),
+			Reason: ErrIntrinsicGas.Error(),
+		},
+	}
+
+	for _, want := range expected {
+		select {
+		case event := <-subscription.subscriptionChannel:
+			assert.Equal(t, want.Type, event.Type)
+			assert.Equal(t, want.TxHash, event.TxHash)
+			assert.Equal(t, want.From, event.From)
+			assert.Equal(t, want.Nonce, event.Nonce)
+			assert.Equal(t, want.Reason, event.Reason)
+			assert.Equal(t, want.ReplacedBy, event.ReplacedBy)
+		case <-time.After(5 * time.Second):
+			t.Fatal("event not received")
+		}
+	}
+
+	select {
+	case event := <-subscription.subscriptionChannel:
+		t.Fatalf("unexpected event %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,119 @@
+package txpool
+
+import (
+	"sync"
+
+	"github.com/dogechain-lab/dogechain/types"
+)
+
+// defaultPendingTxsBufferSize is the channel size of a pending transactions subscription
+const defaultPendingTxsBufferSize = 64
+
+// PendingTxsSubscription is the subscription of the transactions promoted to the
+// pending queue, in the order of promotion
+type PendingTxsSubscription interface {
+	// GetTxs returns the channel of the promoted transactions, it is closed
+	// once unsubscribed or the pool is closed
+	GetTxs() <-chan []*types.Transaction
+
+	Unsubscribe()
+}
+
+// pendingTxsSubscription is the subscription object of the pending transactions feed
+type pendingTxsSubscription struct {
+	txsCh chan []*types.Transaction
+	feed  *pendingTxsFeed
+}
+
+// GetTxs returns the promoted transactions of the subscription (BLOCKING)
+func (s *pendingTxsSubscription) GetTxs() <-chan []*types.Transaction {
+	return s.txsCh
+}
+
+// Unsubscribe removes the subscription from the feed and closes the channel
+func (s *pendingTxsSubscription) Unsubscribe() {
+	s.feed.unsubscribe(s)
+}
+
+// pendingTxsFeed delivers the full bodies of the promoted transactions to the
+// subscribers. The transactions are dropped for the subscribers which are slow
+// to consume, the feed never blocks the promotion.
+type pendingTxsFeed struct {
+	lock   sync.RWMutex
+	subs   map[*pendingTxsSubscription]struct{}
+	closed bool
+}
+
+func newPendingTxsFeed() *pendingTxsFeed {
+	return &pendingTxsFeed{
+		subs: make(map[*pendingTxsSubscription]struct{}),
+	}
+}
+
+func (f *pendingTxsFeed) subscribe() *pendingTxsSubscription {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	sub := &pendingTxsSubscription{
+		txsCh: make(chan []*types.Transaction, defaultPendingTxsBufferSize),
+		feed:  f,
+	}
+
+	if f.closed {
+		close(sub.txsCh)
+
+		return sub
+	}
+
+	f.subs[sub] = struct{}{}
+
+	return sub
+}
+
+func (f *pendingTxsFeed) unsubscribe(sub *pendingTxsSubscription) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, ok := f.subs[sub]; !ok {
+		return
+	}
+
+	delete(f.subs, sub)
+	close(sub.txsCh)
+}
+
+// send passes the promoted transactions to the subscribers [NON-BLOCKING]
+func (f *pendingTxsFeed) send(txs []*types.Transaction) {
+	if len(txs) == 0 {
+		return
+	}
+
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	for sub := range f.subs {
+		select {
+		case sub.txsCh <- txs:
+		default:
+		}
+	}
+}
+
+// close closes all the subscriptions, no more subscription is accepted
+func (f *pendingTxsFeed) close() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.closed = true
+
+	for sub := range f.subs {
+		delete(f.subs, sub)
+		close(sub.txsCh)
+	}
+}
+
+// SubscribePendingTxs subscribes the full bodies of the transactions once they are
+// promoted to the pending queue
+func (p *TxPool) SubscribePendingTxs() PendingTxsSubscription {
+	return p.pendingFeed.subscribe()
+}
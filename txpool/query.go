@@ -49,6 +49,23 @@ func (p *TxPool) GetTxs(inclQueued bool) (
 	return
 }
 
+// GetTxCounts returns the number of the pending (promoted) and the queued (enqueued)
+// transactions, without collecting them
+func (p *TxPool) GetTxCounts() (promoted, enqueued uint64) {
+	return p.accounts.promoted(), p.accounts.enqueued()
+}
+
+// GetAccountTxs returns the pending (promoted) and the queued (enqueued) transactions
+// of the account, ordered by the nonce
+func (p *TxPool) GetAccountTxs(addr types.Address) (promoted, enqueued []*types.Transaction) {
+	account := p.accounts.get(addr)
+	if account == nil {
+		return nil, nil
+	}
+
+	return account.allTxs()
+}
+
 func (p *TxPool) Pending() map[types.Address][]*types.Transaction {
 	return p.accounts.poolPendings()
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/dogechain-lab/dogechain/blockchain"
 	"github.com/dogechain-lab/dogechain/chain"
+	"github.com/dogechain-lab/dogechain/helper/recovery"
 	"github.com/dogechain-lab/dogechain/network"
 	"github.com/dogechain-lab/dogechain/state"
 	"github.com/dogechain-lab/dogechain/txpool/proto"
@@ -188,6 +189,9 @@ type TxPool struct {
 	// Event manager for txpool events
 	eventManager *eventManager
 
+	// feed of the full bodies of the promoted transactions
+	pendingFeed *pendingTxsFeed
+
 	// indicates which txpool operator commands should be implemented
 	proto.UnimplementedTxnPoolOperatorServer
 
@@ -258,6 +262,7 @@ func NewTxPool(
 
 	// Attach the event manager
 	pool.eventManager = newEventManager(pool.logger)
+	pool.pendingFeed = newPendingTxsFeed()
 
 	if network != nil {
 		// subscribe to the gossip protocol
@@ -318,30 +323,39 @@ func (p *TxPool) Start() {
 	p.pruneAccountTicker = time.NewTicker(p.pruneTick)
 	p.ddosReductionTicker = time.NewTicker(_ddosReduceDuration)
 
-	go func() {
-		for {
-			select {
-			case <-p.shutdownCh:
-				return
-			case req, ok := <-p.enqueueReqCh:
-				if ok {
-					go p.handleEnqueueRequest(req)
-				}
-			case req, ok := <-p.promoteReqCh:
-				if ok {
-					go p.handlePromoteRequest(req)
-				}
-			case _, ok := <-p.pruneAccountTicker.C:
-				if ok { // readable
-					go p.pruneStaleAccounts()
-				}
-			case _, ok := <-p.ddosReductionTicker.C:
-				if ok {
-					// go p.reduceDDOSCounts()
-				}
+	// the loop keeps no state, so it is restarted after a panic
+	recovery.GoRestartable(p.logger, "txpool main loop", p.runMainLoop)
+}
+
+// runMainLoop dispatches the requests to their handlers until the pool is closed, a
+// panic of a handler fails its request only
+func (p *TxPool) runMainLoop() {
+	for {
+		select {
+		case <-p.shutdownCh:
+			return
+		case req, ok := <-p.enqueueReqCh:
+			if ok {
+				recovery.Go(p.logger, "txpool enqueue handler", func() {
+					p.handleEnqueueRequest(req)
+				})
+			}
+		case req, ok := <-p.promoteReqCh:
+			if ok {
+				recovery.Go(p.logger, "txpool promote handler", func() {
+					p.handlePromoteRequest(req)
+				})
+			}
+		case _, ok := <-p.pruneAccountTicker.C:
+			if ok { // readable
+				recovery.Go(p.logger, "txpool pruner", p.pruneStaleAccounts)
+			}
+		case _, ok := <-p.ddosReductionTicker.C:
+			if ok {
+				// go p.reduceDDOSCounts()
 			}
 		}
-	}()
+	}
 }
 
 // Close shuts down the pool's main loop.
@@ -357,6 +371,7 @@ func (p *TxPool) Close() {
 	p.logger.Info("txpool close pruneAccountTicker")
 	p.pruneAccountTicker.Stop()
 	p.eventManager.Close()
+	p.pendingFeed.close()
 
 	p.logger.Info("txpool close topic")
 
@@ -498,7 +513,7 @@ func (p *TxPool) DemoteAllPromoted(tx *types.Transaction, correctNonce uint64) {
 	p.metrics.AddPendingTxs(-1 * float64(len(txs)))
 	p.gauge.decrease(slotsRequired(txs...))
 	// signal events
-	p.eventManager.signalEvent(proto.EventType_DEMOTED, toHash(txs...)...)
+	p.eventManager.signalTxEvent(proto.EventType_DEMOTED, eventReasonNonceReset, txs...)
 
 	go func(txs []*types.Transaction) {
 		// retry enqueue, and broadcast
@@ -521,6 +536,9 @@ func (p *TxPool) Drop(tx *types.Transaction) {
 	// num of all txs dropped
 	droppedCount := 0
 
+	// txs dropped along with the given one
+	accountDropped := make([]*types.Transaction, 0)
+
 	// pool resource cleanup
 	clearAccountQueue := func(txs []*types.Transaction) {
 		p.index.remove(txs...)
@@ -528,6 +546,12 @@ func (p *TxPool) Drop(tx *types.Transaction) {
 
 		// increase counter
 		droppedCount += len(txs)
+
+		for _, dropped := range txs {
+			if dropped.Hash() != tx.Hash() {
+				accountDropped = append(accountDropped, dropped)
+			}
+		}
 	}
 
 	defer func() {
@@ -553,7 +577,8 @@ func (p *TxPool) Drop(tx *types.Transaction) {
 	// update metrics
 	p.metrics.AddEnqueueTxs(float64(-1 * len(dropped)))
 
-	p.eventManager.signalEvent(proto.EventType_DROPPED, tx.Hash())
+	p.eventManager.signalTxEvent(proto.EventType_DROPPED, eventReasonUnexecutable, tx)
+	p.eventManager.signalTxEvent(proto.EventType_DROPPED, eventReasonAccountDropped, accountDropped...)
 	p.logger.Debug("dropped account txs",
 		"num", droppedCount,
 		"next_nonce", nextNonce,
@@ -646,14 +671,31 @@ func (p *TxPool) processEvent(event *blockchain.Event) {
 // validateTx ensures the transaction conforms to specific
 // constraints before entering the pool.
 func (p *TxPool) validateTx(tx *types.Transaction) error {
+	if errs := p.checkTx(tx, true); len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// checkTx checks the transaction against the admission rules of the pool. It stops
+// at the first violated rule if failFast is set, otherwise returns all of them.
+func (p *TxPool) checkTx(tx *types.Transaction, failFast bool) (errs []error) {
+	// violate records the violated rule, and returns whether to stop checking
+	violate := func(err error) bool {
+		errs = append(errs, err)
+
+		return failFast
+	}
+
 	// Check the transaction size to overcome DOS Attacks
-	if uint64(len(tx.MarshalRLP())) > txMaxSize {
-		return ErrOversizedData
+	if uint64(len(tx.MarshalRLP())) > txMaxSize && violate(ErrOversizedData) {
+		return
 	}
 
 	// Check if the transaction has a strictly positive value
-	if tx.Value.Sign() < 0 {
-		return ErrNegativeValue
+	if tx.Value.Sign() < 0 && violate(ErrNegativeValue) {
+		return
 	}
 
 	// Check if the transaction is signed properly
@@ -661,18 +703,24 @@ func (p *TxPool) validateTx(tx *types.Transaction) error {
 	// Extract the sender
 	from, signerErr := p.signer.Sender(tx)
 	if signerErr != nil {
-		return ErrExtractSignature
+		// the rules of the sender could not be checked without the sender
+		if violate(ErrExtractSignature) || tx.From == types.ZeroAddress {
+			return
+		}
+
+		from = tx.From
 	}
 
-	if _, ok := p.blacklist[from]; ok {
-		return ErrBlackList
+	if _, ok := p.blacklist[from]; ok && violate(ErrBlackList) {
+		return
 	}
 
 	// If the from field is set, check that
 	// it matches the signer
 	if tx.From != types.ZeroAddress &&
-		tx.From != from {
-		return ErrInvalidSender
+		tx.From != from &&
+		violate(ErrInvalidSender) {
+		return
 	}
 
 	// If no address was set, update it
@@ -681,53 +729,92 @@ func (p *TxPool) validateTx(tx *types.Transaction) error {
 	}
 
 	// Reject underpriced transactions
-	if tx.IsUnderpriced(p.priceLimit) {
-		return ErrUnderpriced
+	if tx.IsUnderpriced(p.priceLimit) && violate(ErrUnderpriced) {
+		return
 	}
 
 	// Grab the state root for the latest block
 	stateRoot := p.store.Header().StateRoot
 
 	// Check nonce ordering
-	if p.store.GetNonce(stateRoot, tx.From) > tx.Nonce {
-		return ErrNonceTooLow
+	if p.store.GetNonce(stateRoot, tx.From) > tx.Nonce && violate(ErrNonceTooLow) {
+		return
 	}
 
 	accountBalance, balanceErr := p.store.GetBalance(stateRoot, tx.From)
 	if balanceErr != nil {
-		return ErrInvalidAccountState
-	}
-
-	// Check if the sender has enough funds to execute the transaction
-	if accountBalance.Cmp(tx.Cost()) < 0 {
-		return ErrInsufficientFunds
+		if violate(ErrInvalidAccountState) {
+			return
+		}
+	} else if accountBalance.Cmp(tx.Cost()) < 0 && violate(ErrInsufficientFunds) {
+		// Check if the sender has enough funds to execute the transaction
+		return
 	}
 
 	// Make sure the transaction has more gas than the basic transaction fee
 	intrinsicGas, err := state.TransactionGasCost(tx, p.forks.Homestead, p.forks.Istanbul)
 	if err != nil {
-		return err
-	}
-
-	if tx.Gas < intrinsicGas {
-		return ErrIntrinsicGas
+		if violate(err) {
+			return
+		}
+	} else if tx.Gas < intrinsicGas && violate(ErrIntrinsicGas) {
+		return
 	}
 
 	// Grab the block gas limit for the latest block
 	latestBlockGasLimit := p.store.Header().GasLimit
 
-	if tx.Gas > latestBlockGasLimit {
-		return ErrBlockLimitExceeded
+	if tx.Gas > latestBlockGasLimit && violate(ErrBlockLimitExceeded) {
+		return
 	}
 
-	return nil
+	return
+}
+
+// ValidateTx runs the admission of the pool on the transaction in dry run, and returns
+// all the violated rules. The transaction is neither changed nor added.
+func (p *TxPool) ValidateTx(tx *types.Transaction) []error {
+	tx = tx.Copy()
+
+	var errs []error
+
+	if p.isClosed.Load() {
+		errs = append(errs, ErrTxPoolClosed)
+	}
+
+	if p.IsDestructiveTx(tx) {
+		errs = append(errs, ErrContractDestructive)
+	}
+
+	if p.IsDDOSTx(tx) {
+		errs = append(errs, ErrContractDDOSList)
+	}
+
+	errs = append(errs, p.checkTx(tx, false)...)
+
+	if p.gauge.read()+slotsRequired(tx) > p.gauge.max {
+		errs = append(errs, ErrTxPoolOverflow)
+	}
+
+	if _, ok := p.index.get(tx.Hash()); ok {
+		errs = append(errs, ErrAlreadyKnown)
+	}
+
+	return errs
 }
 
 // addTx is the main entry point to the pool
 // for all new transactions. If the call is
 // successful, an account is created for this address
 // (only once) and an enqueueRequest is signaled.
-func (p *TxPool) addTx(origin txOrigin, tx *types.Transaction) error {
+func (p *TxPool) addTx(origin txOrigin, tx *types.Transaction) (err error) {
+	defer func() {
+		// the known transactions are gossiped again, they are not rejected
+		if err != nil && !errors.Is(err, ErrAlreadyKnown) {
+			p.eventManager.signalTxEvent(proto.EventType_REJECTED, err.Error(), tx)
+		}
+	}()
+
 	if p.IsDestructiveTx(tx) {
 		return ErrContractDestructive
 	}
@@ -768,7 +855,7 @@ func (p *TxPool) addTx(origin txOrigin, tx *types.Transaction) error {
 
 	// send request [BLOCKING]
 	p.enqueueReqCh <- enqueueRequest{tx: tx}
-	p.eventManager.signalEvent(proto.EventType_ADDED, tx.Hash())
+	p.eventManager.signalTxEvent(proto.EventType_ADDED, "", tx)
 
 	return nil
 }
@@ -813,7 +900,7 @@ func (p *TxPool) handleEnqueueRequest(req enqueueRequest) {
 		// gauge, metrics, event
 		p.gauge.decrease(slotsRequired(replacedTx))
 		p.metrics.AddEnqueueTxs(-1)
-		p.eventManager.signalEvent(proto.EventType_REPLACED, replacedTx.Hash())
+		p.eventManager.signalReplacedEvent(replacedTx, tx)
 	}
 
 	p.logger.Debug("enqueue request", "hash", tx.Hash())
@@ -848,7 +935,7 @@ func (p *TxPool) handlePromoteRequest(req promoteRequest) {
 
 	// drop lower nonce txs first, to reduce the risk of mining.
 	if len(dropped) > 0 {
-		p.pruneEnqueuedTxs(dropped)
+		p.pruneEnqueuedTxs(dropped, eventReasonNonceTooLow)
 		p.logger.Debug("dropped transactions when promoting", "dropped", dropped)
 	}
 
@@ -857,12 +944,14 @@ func (p *TxPool) handlePromoteRequest(req promoteRequest) {
 		// state
 		p.gauge.decrease(slotsRequired(replaced...))
 		// metrics and event
-		p.decreaseQueueGauge(replaced, p.metrics.AddPendingTxs, proto.EventType_REPLACED)
+		p.metrics.AddPendingTxs(-1 * float64(len(replaced)))
+		p.signalPromotedReplacements(replaced, promoted)
 		p.logger.Debug("replaced transactions when promoting", "replaced", replaced)
 	}
 
 	// metrics and event
 	p.tranferQueueGauge(promoted, p.metrics.AddEnqueueTxs, p.metrics.AddPendingTxs, proto.EventType_PROMOTED)
+	p.pendingFeed.send(promoted)
 }
 
 // pruneStaleAccounts would find out all need-to-prune transactions,
@@ -876,7 +965,7 @@ func (p *TxPool) pruneStaleAccounts() {
 		return
 	}
 
-	p.pruneEnqueuedTxs(pruned)
+	p.pruneEnqueuedTxs(pruned, eventReasonOutdated)
 	p.logger.Debug("pruned stale enqueued txs", "num", pruned)
 }
 
@@ -890,29 +979,49 @@ func (p *TxPool) tranferQueueGauge(
 	pendingGaugeAddFn(float64(len(txs)))
 
 	// event
-	p.eventManager.signalEvent(event, toHash(txs...)...)
+	p.eventManager.signalTxEvent(event, "", txs...)
 }
 
 func (p *TxPool) increaseQueueGauge(txs []*types.Transaction, gaugeAddFn func(v float64), event proto.EventType) {
 	// metrics
 	gaugeAddFn(float64(len(txs)))
 	// event
-	p.eventManager.signalEvent(event, toHash(txs...)...)
+	p.eventManager.signalTxEvent(event, "", txs...)
 }
 
-func (p *TxPool) decreaseQueueGauge(txs []*types.Transaction, gaugeAddFn func(v float64), event proto.EventType) {
+func (p *TxPool) decreaseQueueGauge(
+	txs []*types.Transaction,
+	gaugeAddFn func(v float64),
+	event proto.EventType,
+	reason string,
+) {
 	// metrics
 	gaugeAddFn(-1 * float64(len(txs)))
 	// event
-	p.eventManager.signalEvent(event, toHash(txs...)...)
+	p.eventManager.signalTxEvent(event, reason, txs...)
 }
 
-func (p *TxPool) pruneEnqueuedTxs(pruned []*types.Transaction) {
+func (p *TxPool) pruneEnqueuedTxs(pruned []*types.Transaction, reason string) {
 	p.index.remove(pruned...)
 	// state
 	p.gauge.decrease(slotsRequired(pruned...))
 	// metrics and event
-	p.decreaseQueueGauge(pruned, p.metrics.AddEnqueueTxs, proto.EventType_PRUNED_ENQUEUED)
+	p.decreaseQueueGauge(pruned, p.metrics.AddEnqueueTxs, proto.EventType_PRUNED_ENQUEUED, reason)
+}
+
+// signalPromotedReplacements signals the promoted transactions replaced by the
+// promoting ones of the same nonce
+func (p *TxPool) signalPromotedReplacements(replaced, promoted []*types.Transaction) {
+	byNonce := make(map[uint64]*types.Transaction, len(promoted))
+	for _, tx := range promoted {
+		byNonce[tx.Nonce] = tx
+	}
+
+	for _, tx := range replaced {
+		if by, ok := byNonce[tx.Nonce]; ok {
+			p.eventManager.signalReplacedEvent(tx, by)
+		}
+	}
 }
 
 // addGossipTx handles receiving transactions gossiped by the network.
@@ -993,12 +1102,14 @@ func (p *TxPool) resetAccounts(stateNonces map[types.Address]uint64) {
 	//	prune pool state
 	if len(allPrunedPromoted) > 0 {
 		cleanup(allPrunedPromoted...)
-		p.decreaseQueueGauge(allPrunedPromoted, p.metrics.AddPendingTxs, proto.EventType_PRUNED_PROMOTED)
+		p.decreaseQueueGauge(allPrunedPromoted, p.metrics.AddPendingTxs, proto.EventType_PRUNED_PROMOTED,
+			eventReasonNonceTooLow)
 	}
 
 	if len(allPrunedEnqueued) > 0 {
 		cleanup(allPrunedEnqueued...)
-		p.decreaseQueueGauge(allPrunedEnqueued, p.metrics.AddEnqueueTxs, proto.EventType_PRUNED_ENQUEUED)
+		p.decreaseQueueGauge(allPrunedEnqueued, p.metrics.AddEnqueueTxs, proto.EventType_PRUNED_ENQUEUED,
+			eventReasonNonceTooLow)
 	}
 }
 
@@ -1019,12 +1130,3 @@ func (p *TxPool) createAccountOnce(newAddr types.Address) *account {
 func (p *TxPool) Length() uint64 {
 	return p.accounts.promoted()
 }
-
-// toHash returns the hash(es) of given transaction(s)
-func toHash(txs ...*types.Transaction) (hashes []types.Hash) {
-	for _, tx := range txs {
-		hashes = append(hashes, tx.Hash())
-	}
-
-	return
-}
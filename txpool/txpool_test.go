@@ -323,6 +323,43 @@ func TestAddTxErrors(t *testing.T) {
 	})
 }
 
+func TestValidateTx(t *testing.T) {
+	poolSigner := crypto.NewEIP155Signer(100)
+
+	defaultKey, defaultAddr := tests.GenerateKeyAndAddr(t)
+
+	pool, err := newTestPool()
+	assert.NoError(t, err)
+	pool.SetSigner(poolSigner)
+
+	tx, err := poolSigner.SignTx(newTx(defaultAddr, 0, 1), defaultKey)
+	assert.NoError(t, err)
+
+	// valid and not added
+	assert.Empty(t, pool.ValidateTx(tx))
+	assert.Equal(t, uint64(0), pool.gauge.read())
+	assert.False(t, pool.accounts.exists(defaultAddr))
+
+	// all the violated rules
+	pool.priceLimit = 1000000
+	pool.store = faultyMockStore{}
+
+	tx = newTx(defaultAddr, 0, 1)
+	tx.Gas = 1
+
+	assert.Equal(t, []error{
+		ErrExtractSignature,
+		ErrUnderpriced,
+		ErrNonceTooLow,
+		ErrInvalidAccountState,
+		ErrIntrinsicGas,
+		ErrBlockLimitExceeded,
+	}, pool.ValidateTx(tx))
+
+	// the first one is returned on adding
+	assert.ErrorIs(t, pool.addTx(local, tx), ErrExtractSignature)
+}
+
 func TestAddGossipTx(t *testing.T) {
 	key, sender := tests.GenerateKeyAndAddr(t)
 	signer := crypto.NewEIP155Signer(uint64(100))
@@ -2353,6 +2390,88 @@ func TestGetTxs(t *testing.T) {
 	}
 }
 
+func TestGetAccountTxs(t *testing.T) {
+	pool, err := newTestPool()
+	assert.NoError(t, err)
+	pool.SetSigner(&mockSigner{})
+
+	// unknown account
+	promoted, enqueued := pool.GetAccountTxs(addr1)
+	assert.Empty(t, promoted)
+	assert.Empty(t, enqueued)
+
+	acc := pool.createAccountOnce(addr1)
+	acc.setNonce(0)
+
+	for _, nonce := range []uint64{2, 0, 1} {
+		acc.promoted.push(newTx(addr1, nonce, 1))
+	}
+
+	for _, nonce := range []uint64{11, 10} {
+		acc.enqueued.push(newTx(addr1, nonce, 1))
+	}
+
+	nonces := func(txs []*types.Transaction) (res []uint64) {
+		for _, tx := range txs {
+			res = append(res, tx.Nonce)
+		}
+
+		return
+	}
+
+	// sorted by the nonce
+	promoted, enqueued = pool.GetAccountTxs(addr1)
+	assert.Equal(t, []uint64{0, 1, 2}, nonces(promoted))
+	assert.Equal(t, []uint64{10, 11}, nonces(enqueued))
+
+	// the queues are not touched
+	promoted[0] = nil
+	assert.NotNil(t, acc.promoted.peek())
+
+	promotedCount, enqueuedCount := pool.GetTxCounts()
+	assert.Equal(t, uint64(3), promotedCount)
+	assert.Equal(t, uint64(2), enqueuedCount)
+}
+
+func TestSubscribePendingTxs(t *testing.T) {
+	pool, err := newTestPool()
+	assert.NoError(t, err)
+	pool.SetSigner(&mockSigner{})
+
+	sub := pool.SubscribePendingTxs()
+	unsubscribed := pool.SubscribePendingTxs()
+	unsubscribed.Unsubscribe()
+
+	// send 1 tx and promote it
+	tx := newTx(addr1, 0, 1)
+
+	go func() {
+		err := pool.addTx(local, tx)
+		assert.NoError(t, err)
+	}()
+	go pool.handleEnqueueRequest(<-pool.enqueueReqCh)
+	pool.handlePromoteRequest(<-pool.promoteReqCh)
+
+	select {
+	case txs := <-sub.GetTxs():
+		assert.Equal(t, []*types.Transaction{tx}, txs)
+	default:
+		t.Fatal("promoted transaction not delivered")
+	}
+
+	_, ok := <-unsubscribed.GetTxs()
+	assert.False(t, ok)
+
+	// the subscriptions are closed with the pool
+	pool.pendingFeed.close()
+
+	_, ok = <-sub.GetTxs()
+	assert.False(t, ok)
+
+	_, ok = <-pool.SubscribePendingTxs().GetTxs()
+	assert.False(t, ok)
+}
+
 func TestAddTx_ReplaceSameNonce(t *testing.T) {
 	var (
 		eoa  = new(eoa).create(t)
@@ -125,6 +125,32 @@ func (b *Bloom) setEncode(hasher *keccak.Keccak, h []byte) {
 	}
 }
 
+// BloomBitLength is the number of bits of the bloom filter
+const BloomBitLength = 8 * BloomByteLength
+
+// BloomBitIndexes returns the global bit locations set by the data in the bloom filter
+func BloomBitIndexes(data []byte) [3]uint {
+	hasher := keccak.DefaultKeccakPool.Get()
+	defer keccak.DefaultKeccakPool.Put(hasher)
+
+	hasher.Reset()
+	hasher.Write(data)
+	buf := hasher.Read()
+
+	var indexes [3]uint
+
+	for i := 0; i < 6; i += 2 {
+		indexes[i/2] = (uint(buf[i+1]) + (uint(buf[i]) << 8)) & (BloomBitLength - 1)
+	}
+
+	return indexes
+}
+
+// IsBitSet checks if the global bit location is set in the bloom filter
+func (b *Bloom) IsBitSet(bit uint) bool {
+	return b[BloomByteLength-1-bit/8]&(1<<(bit%8)) != 0
+}
+
 // IsLogInBloom checks if the log has a possible presence in the bloom filter
 func (b *Bloom) IsLogInBloom(log *Log) bool {
 	hasher := keccak.DefaultKeccakPool.Get()